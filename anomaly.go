@@ -0,0 +1,144 @@
+package forecaster
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/errs"
+)
+
+var (
+	ErrMismatchedAnomalyLen  = errs.NewDataError(errs.CodeLengthMismatch, "observations have different length than times", nil)
+	ErrInvalidAnomalyZscore  = errs.NewConfigError(errs.CodeInvalidOption, "anomaly z-score threshold must be non-negative", nil)
+	ErrInvalidAnomalyMinimum = errs.NewConfigError(errs.CodeInvalidOption, "anomaly minimum points must be non-negative", nil)
+)
+
+// AnomalyDirection is whether an anomaly is above or below the forecast
+type AnomalyDirection string
+
+const (
+	AnomalyAbove AnomalyDirection = "above"
+	AnomalyBelow AnomalyDirection = "below"
+)
+
+// AnomalyOptions configures batch anomaly detection. The z-score of an observation is its deviation
+// from the forecast over the standard deviation implied by the uncertainty band on the same side of the
+// forecast, the half width of the band over the residual z-score of the uncertainty options. Points with
+// an absolute z-score above Zscore are anomalous which defaults to the residual z-score so any point
+// outside the band is anomalous. Intervals with fewer than MinPoints anomalous points are dropped.
+type AnomalyOptions struct {
+	Zscore    float64 `json:"zscore,omitempty"`
+	MinPoints int     `json:"min_points,omitempty"`
+}
+
+// Anomaly is a run of consecutive anomalous observations on the same side of the forecast from the
+// first to the last anomalous point. The peak is the point with the largest absolute z-score and its
+// deviation is the observation minus the forecast.
+type Anomaly struct {
+	Start     time.Time        `json:"start"`
+	End       time.Time        `json:"end"`
+	NumPoints int              `json:"num_points"`
+	Direction AnomalyDirection `json:"direction"`
+
+	PeakTime      time.Time `json:"peak_time"`
+	PeakValue     float64   `json:"peak_value"`
+	PeakDeviation float64   `json:"peak_deviation"`
+	PeakZscore    float64   `json:"peak_zscore"`
+}
+
+// DetectAnomalies compares the observations to the forecast and uncertainty bands of the trained
+// forecaster and returns the intervals of observations outside the bands
+func (f *Forecaster) DetectAnomalies(t []time.Time, y []float64) ([]Anomaly, error) {
+	return f.DetectAnomaliesWithOptions(t, y, nil)
+}
+
+// DetectAnomaliesWithOptions compares the observations to the forecast and uncertainty bands of the
+// trained forecaster and returns the anomalous intervals in time order. NaN observations are skipped
+// without ending an interval. The default options are used if nil.
+func (f *Forecaster) DetectAnomaliesWithOptions(t []time.Time, y []float64, opt *AnomalyOptions) ([]Anomaly, error) {
+	if len(t) != len(y) {
+		return nil, fmt.Errorf("got %d times and %d values, %w", len(t), len(y), ErrMismatchedAnomalyLen)
+	}
+	if opt == nil {
+		opt = &AnomalyOptions{}
+	}
+	if opt.Zscore < 0 || math.IsNaN(opt.Zscore) {
+		return nil, fmt.Errorf("z-score of %.3f, %w", opt.Zscore, ErrInvalidAnomalyZscore)
+	}
+	if opt.MinPoints < 0 {
+		return nil, fmt.Errorf("minimum points of %d, %w", opt.MinPoints, ErrInvalidAnomalyMinimum)
+	}
+	if len(t) == 0 {
+		return nil, nil
+	}
+
+	res, err := f.Predict(t)
+	if err != nil {
+		return nil, fmt.Errorf("unable to predict observations, %w", err)
+	}
+
+	bandZ := 1.0
+	if f.opt.UncertaintyOptions != nil && f.opt.UncertaintyOptions.ResidualZscore > 0 {
+		bandZ = f.opt.UncertaintyOptions.ResidualZscore
+	}
+	threshold := opt.Zscore
+	if threshold == 0 {
+		threshold = bandZ
+	}
+
+	var anomalies []Anomaly
+	var current *Anomaly
+	closeCurrent := func() {
+		if current != nil && current.NumPoints >= opt.MinPoints {
+			anomalies = append(anomalies, *current)
+		}
+		current = nil
+	}
+	for i, v := range y {
+		if math.IsNaN(v) || math.IsNaN(res.Forecast[i]) {
+			continue
+		}
+		dev := v - res.Forecast[i]
+		halfWidth := res.Upper[i] - res.Forecast[i]
+		direction := AnomalyAbove
+		if dev < 0 {
+			halfWidth = res.Forecast[i] - res.Lower[i]
+			direction = AnomalyBelow
+		}
+		z := anomalyZscore(dev, halfWidth/bandZ)
+		if math.Abs(z) <= threshold {
+			closeCurrent()
+			continue
+		}
+
+		if current != nil && current.Direction != direction {
+			closeCurrent()
+		}
+		if current == nil {
+			current = &Anomaly{Start: t[i], Direction: direction}
+		}
+		current.End = t[i]
+		current.NumPoints++
+		if math.Abs(z) > math.Abs(current.PeakZscore) {
+			current.PeakTime = t[i]
+			current.PeakValue = v
+			current.PeakDeviation = dev
+			current.PeakZscore = z
+		}
+	}
+	closeCurrent()
+	return anomalies, nil
+}
+
+// anomalyZscore returns the deviation in standard deviations. A non-zero deviation with no spread has
+// the largest finite z-score so that it stays encodable in json.
+func anomalyZscore(dev, std float64) float64 {
+	if dev == 0 {
+		return 0
+	}
+	if z := dev / std; std > 0 && !math.IsInf(z, 0) {
+		return z
+	}
+	return math.Copysign(math.MaxFloat64, dev)
+}
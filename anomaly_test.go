@@ -0,0 +1,143 @@
+package forecaster
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/forecast/options"
+	"github.com/aouyang1/go-forecaster/timedataset"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectAnomalies(t *testing.T) {
+	n := 7 * 24 * 4
+	tWin := timedataset.GenerateT(2*n, 15*time.Minute, func() time.Time {
+		return time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC)
+	})
+	rng := rand.New(rand.NewSource(11))
+	y := make([]float64, len(tWin))
+	for i := range y {
+		y[i] = 50 + 10*math.Sin(2*math.Pi*float64(i)/96) + rng.NormFloat64()
+	}
+	// a spike of five points followed by a dip of three points with a missing observation
+	spikeStart, dipStart := n+200, n+400
+	for i := spikeStart; i < spikeStart+5; i++ {
+		y[i] += 15
+	}
+	y[spikeStart+2] += 5
+	for i := dipStart; i < dipStart+4; i++ {
+		y[i] -= 15
+	}
+	y[dipStart+1] = math.NaN()
+
+	opt := &Options{
+		SeriesOptions: &SeriesOptions{
+			ForecastOptions: &options.Options{
+				SeasonalityOptions: options.SeasonalityOptions{
+					SeasonalityConfigs: []options.SeasonalityConfig{options.NewDailySeasonalityConfig(2)},
+				},
+			},
+		},
+		UncertaintyOptions: &UncertaintyOptions{
+			ForecastOptions: &options.Options{},
+			ResidualWindow:  24,
+			ResidualZscore:  3.0,
+		},
+	}
+	f, err := New(opt)
+	require.Nil(t, err)
+	require.Nil(t, f.Fit(tWin[:n], y[:n]))
+
+	spike := Anomaly{
+		Start:     tWin[spikeStart],
+		End:       tWin[spikeStart+4],
+		NumPoints: 5,
+		Direction: AnomalyAbove,
+		PeakTime:  tWin[spikeStart+2],
+		PeakValue: y[spikeStart+2],
+	}
+	dip := Anomaly{
+		Start:     tWin[dipStart],
+		End:       tWin[dipStart+3],
+		NumPoints: 3,
+		Direction: AnomalyBelow,
+	}
+
+	testData := map[string]struct {
+		y        []float64
+		opt      *AnomalyOptions
+		expected []Anomaly
+		err      error
+	}{
+		"spike and dip": {
+			opt:      &AnomalyOptions{Zscore: 6},
+			expected: []Anomaly{spike, dip},
+		},
+		"minimum points": {
+			opt:      &AnomalyOptions{Zscore: 6, MinPoints: 4},
+			expected: []Anomaly{spike},
+		},
+		"mismatched length": {
+			y:   y[:10],
+			err: ErrMismatchedAnomalyLen,
+		},
+		"negative z-score": {
+			opt: &AnomalyOptions{Zscore: -1},
+			err: ErrInvalidAnomalyZscore,
+		},
+		"negative minimum points": {
+			opt: &AnomalyOptions{MinPoints: -1},
+			err: ErrInvalidAnomalyMinimum,
+		},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			yIn := y[n:]
+			if td.y != nil {
+				yIn = td.y
+			}
+			res, err := f.DetectAnomaliesWithOptions(tWin[n:], yIn, td.opt)
+			if td.err != nil {
+				assert.ErrorIs(t, err, td.err)
+				return
+			}
+			require.Nil(t, err)
+			require.Len(t, res, len(td.expected))
+			for i, expected := range td.expected {
+				assert.Equal(t, expected.Start, res[i].Start)
+				assert.Equal(t, expected.End, res[i].End)
+				assert.Equal(t, expected.NumPoints, res[i].NumPoints)
+				assert.Equal(t, expected.Direction, res[i].Direction)
+				if expected.Direction == AnomalyAbove {
+					assert.Equal(t, expected.PeakTime, res[i].PeakTime)
+					assert.Equal(t, expected.PeakValue, res[i].PeakValue)
+					assert.Greater(t, res[i].PeakDeviation, 15.0)
+					assert.Greater(t, res[i].PeakZscore, 6.0)
+				} else {
+					assert.Less(t, res[i].PeakDeviation, -10.0)
+					assert.Less(t, res[i].PeakZscore, -6.0)
+				}
+			}
+		})
+	}
+
+	// the default threshold flags every point outside the bands
+	res, err := f.DetectAnomalies(tWin[n:], y[n:])
+	require.Nil(t, err)
+	pred, err := f.Predict(tWin[n:])
+	require.Nil(t, err)
+	var outside, flagged int
+	for i, v := range y[n:] {
+		if v > pred.Upper[i] || v < pred.Lower[i] {
+			outside++
+		}
+	}
+	for _, a := range res {
+		flagged += a.NumPoints
+	}
+	assert.Equal(t, outside, flagged)
+}
@@ -0,0 +1,107 @@
+package forecaster
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/errs"
+	"github.com/aouyang1/go-forecaster/stats"
+	"github.com/aouyang1/go-forecaster/timedataset"
+)
+
+var ErrInvalidAROrder = errs.NewConfigError(errs.CodeInvalidOption, "autoregressive order must be positive", nil)
+
+// AutoregressionOptions models the error of the series fit, the observation minus the fitted value,
+// with an AR(Order) process estimated by Yule-Walker after the series fit. Forecasts after the end of
+// the training data are corrected by recursively predicting the error from the last Order errors so
+// short horizons follow any persistent deviation from the model which decays back to the model further
+// out. Missing errors are treated as zero and times at or before the end of training are not corrected.
+// The coefficients, last errors, and sampling interval are populated by the fit.
+type AutoregressionOptions struct {
+	Order int `json:"order"`
+
+	Coefficients []float64     `json:"coefficients,omitempty"`
+	LastErrors   []float64     `json:"last_errors,omitempty"`
+	Interval     time.Duration `json:"interval,omitempty"`
+	EndTime      time.Time     `json:"end_time,omitempty"`
+}
+
+// fit estimates the coefficients from the series residual of the training times and keeps the last
+// errors to predict from
+func (a *AutoregressionOptions) fit(t []time.Time, residual []float64, freqOpt *timedataset.FreqOptions) error {
+	if a.Order < 1 {
+		return fmt.Errorf("order of %d, %w", a.Order, ErrInvalidAROrder)
+	}
+	interval, err := inferFreq(t, freqOpt)
+	if err != nil {
+		return err
+	}
+
+	// residuals are the fitted value minus the observation
+	errSeries := make([]float64, len(residual))
+	for i, r := range residual {
+		errSeries[i] = -r
+	}
+	coef, err := stats.YuleWalker(errSeries, a.Order)
+	if errors.Is(err, stats.ErrSingularAutocorr) {
+		coef, err = make([]float64, a.Order), nil
+	}
+	if err != nil {
+		return err
+	}
+
+	last := make([]float64, a.Order)
+	for i := range last {
+		idx := len(errSeries) - a.Order + i
+		if idx >= 0 && !math.IsNaN(errSeries[idx]) {
+			last[i] = errSeries[idx]
+		}
+	}
+
+	a.Coefficients = coef
+	a.LastErrors = last
+	a.Interval = interval
+	a.EndTime = t[len(t)-1]
+	return nil
+}
+
+// correction returns the predicted error at each time which is zero at or before the end of training
+func (a *AutoregressionOptions) correction(t []time.Time) []float64 {
+	res := make([]float64, len(t))
+	if a == nil || len(a.Coefficients) == 0 || a.Interval <= 0 {
+		return res
+	}
+
+	var maxStep int
+	steps := make([]int, len(t))
+	for i, tPnt := range t {
+		steps[i] = int(math.Round(float64(tPnt.Sub(a.EndTime)) / float64(a.Interval)))
+		if steps[i] > maxStep {
+			maxStep = steps[i]
+		}
+	}
+	if maxStep == 0 {
+		return res
+	}
+
+	// history holds the last errors followed by the recursively predicted errors
+	history := make([]float64, len(a.LastErrors), len(a.LastErrors)+maxStep)
+	copy(history, a.LastErrors)
+	for step := 1; step <= maxStep; step++ {
+		var pred float64
+		for j, c := range a.Coefficients {
+			if idx := len(history) - 1 - j; idx >= 0 {
+				pred += c * history[idx]
+			}
+		}
+		history = append(history, pred)
+	}
+	for i, step := range steps {
+		if step > 0 {
+			res[i] = history[len(a.LastErrors)+step-1]
+		}
+	}
+	return res
+}
@@ -0,0 +1,98 @@
+package forecaster
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/forecast/options"
+	"github.com/aouyang1/go-forecaster/timedataset"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFitAutoregression(t *testing.T) {
+	// daily wave with AR(1) noise
+	n := 7 * 24 * 4
+	interval := 15 * time.Minute
+	tWin := timedataset.GenerateT(n, interval, func() time.Time {
+		return time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC)
+	})
+	rng := rand.New(rand.NewSource(9))
+	y := make([]float64, n)
+	var noise float64
+	for i, tPnt := range tWin {
+		noise = 0.8*noise + 0.5*rng.NormFloat64()
+		y[i] = 20 + 5*math.Sin(2*math.Pi*float64(tPnt.Hour()*60+tPnt.Minute())/1440) + noise
+	}
+	horizon := timedataset.GenerateT(8, interval, func() time.Time {
+		return tWin[n-1].Add(9 * interval)
+	})
+
+	newOpt := func(ar *AutoregressionOptions) *Options {
+		return &Options{
+			SeriesOptions: &SeriesOptions{
+				ForecastOptions: &options.Options{
+					SeasonalityOptions: options.SeasonalityOptions{
+						SeasonalityConfigs: []options.SeasonalityConfig{options.NewDailySeasonalityConfig(2)},
+					},
+				},
+			},
+			UncertaintyOptions: &UncertaintyOptions{
+				ForecastOptions: &options.Options{},
+				ResidualWindow:  24,
+				ResidualZscore:  2.0,
+			},
+			Autoregression: ar,
+		}
+	}
+
+	base, err := New(newOpt(nil))
+	require.Nil(t, err)
+	require.Nil(t, base.Fit(tWin, y))
+	baseRes, err := base.Predict(horizon)
+	require.Nil(t, err)
+	assert.Nil(t, baseRes.Autoregressive)
+
+	f, err := New(newOpt(&AutoregressionOptions{Order: 1}))
+	require.Nil(t, err)
+	require.Nil(t, f.Fit(tWin, y))
+
+	ar := f.opt.Autoregression
+	require.Len(t, ar.Coefficients, 1)
+	assert.InDelta(t, 0.8, ar.Coefficients[0], 0.1)
+	assert.Equal(t, interval, ar.Interval)
+	assert.Equal(t, tWin[n-1], ar.EndTime)
+	assert.InDelta(t, -base.Residuals()[n-1], ar.LastErrors[0], 1e-6)
+
+	// in sample forecasts are not corrected
+	for _, v := range f.FitResults().Autoregressive {
+		assert.Equal(t, 0.0, v)
+	}
+
+	res, err := f.Predict(horizon)
+	require.Nil(t, err)
+	require.Len(t, res.Autoregressive, len(horizon))
+	expected := ar.LastErrors[0]
+	for i := range horizon {
+		expected *= ar.Coefficients[0]
+		assert.InDelta(t, expected, res.Autoregressive[i], 1e-9)
+		assert.InDelta(t, baseRes.Forecast[i]+expected, res.Forecast[i], 1e-6)
+		assert.InDelta(t, baseRes.Upper[i]+expected, res.Upper[i], 1e-6)
+	}
+	assert.Less(t, math.Abs(res.Autoregressive[len(horizon)-1]), math.Abs(res.Autoregressive[0]))
+
+	// corrections survive a round trip of the model
+	m, err := f.Model()
+	require.Nil(t, err)
+	loaded, err := NewFromModel(m)
+	require.Nil(t, err)
+	loadedRes, err := loaded.Predict(horizon)
+	require.Nil(t, err)
+	assert.InDeltaSlice(t, res.Forecast, loadedRes.Forecast, 1e-9)
+
+	f, err = New(newOpt(&AutoregressionOptions{}))
+	require.Nil(t, err)
+	assert.ErrorIs(t, f.Fit(tWin, y), ErrInvalidAROrder)
+}
@@ -0,0 +1,166 @@
+package forecaster
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/errs"
+	"github.com/aouyang1/go-forecaster/models"
+	"gonum.org/v1/gonum/stat"
+)
+
+var (
+	ErrUnknownBacktestWindow = errs.NewConfigError(errs.CodeInvalidOption, "unknown backtest window", nil)
+	ErrInvalidBacktestConfig = errs.NewConfigError(errs.CodeInvalidOption, "backtest horizon, step, and train size must be non-negative", nil)
+	ErrMismatchedBacktestLen = errs.NewDataError(errs.CodeLengthMismatch, "backtest values have different length than times", nil)
+)
+
+// BacktestWindow is how the training range moves with the forecast origin
+type BacktestWindow string
+
+const (
+	// BacktestExpanding trains every fold from the first observation up to its origin. This is the
+	// default if unset.
+	BacktestExpanding BacktestWindow = "expanding"
+
+	// BacktestSliding trains every fold on the same number of observations preceding its origin
+	BacktestSliding BacktestWindow = "sliding"
+)
+
+// BacktestConfig configures a rolling origin evaluation over Folds forecast origins. Each fold forecasts
+// the Horizon observations after its origin, the last fold forecasting the final observations, and the
+// origins are Step observations apart. Horizon defaults to the number of observations over Folds plus
+// one, Step defaults to the horizon, and TrainSize of a sliding window defaults to the observations
+// preceding the first origin.
+type BacktestConfig struct {
+	Folds     int            `json:"folds"`
+	Window    BacktestWindow `json:"window,omitempty"`
+	Horizon   int            `json:"horizon,omitempty"`
+	Step      int            `json:"step,omitempty"`
+	TrainSize int            `json:"train_size,omitempty"`
+}
+
+// splits returns the training and test ranges of each fold for n observations
+func (c BacktestConfig) splits(n int) ([]models.Split, error) {
+	switch c.Window {
+	case "", BacktestExpanding, BacktestSliding:
+	default:
+		return nil, fmt.Errorf("%q, %w", c.Window, ErrUnknownBacktestWindow)
+	}
+	if c.Horizon < 0 || c.Step < 0 || c.TrainSize < 0 {
+		return nil, fmt.Errorf("horizon %d, step %d, and train size %d, %w", c.Horizon, c.Step, c.TrainSize, ErrInvalidBacktestConfig)
+	}
+
+	horizon := c.Horizon
+	if horizon == 0 && c.Folds > 0 {
+		horizon = n / (c.Folds + 1)
+	}
+	step := c.Step
+	if step == 0 {
+		step = horizon
+	}
+	var window int
+	if c.Window == BacktestSliding {
+		window = c.TrainSize
+		if window == 0 {
+			window = n - horizon - (c.Folds-1)*step
+		}
+	}
+	return models.RollingOriginSplit(n, c.Folds, horizon, step, window)
+}
+
+// BacktestFold is the evaluation of the forecast of a single origin
+type BacktestFold struct {
+	Split      models.Split `json:"split"`
+	Origin     time.Time    `json:"origin"`
+	Evaluation *Evaluation  `json:"evaluation"`
+}
+
+// BacktestSummary aggregates a score of the evaluation of every fold
+type BacktestSummary struct {
+	MSE      float64 `json:"mean_squared_error"`
+	MAPE     float64 `json:"mean_average_percent_error"`
+	R2       float64 `json:"r_squared"`
+	MAE      float64 `json:"mean_absolute_error"`
+	RMSE     float64 `json:"root_mean_squared_error"`
+	Bias     float64 `json:"bias"`
+	Coverage float64 `json:"coverage"`
+}
+
+// BacktestReport is the evaluation of every fold along with the mean and standard deviation of each
+// score across the folds
+type BacktestReport struct {
+	Folds []BacktestFold  `json:"folds"`
+	Mean  BacktestSummary `json:"mean"`
+	Std   BacktestSummary `json:"std"`
+}
+
+// Backtest evaluates the options with rolling origin evaluation. Every fold fits a fresh forecaster on a
+// copy of the options over its training range and scores the forecast and its uncertainty bands against
+// the actuals of its test range. The options are not modified.
+func Backtest(t []time.Time, y []float64, opt *Options, cfg BacktestConfig) (*BacktestReport, error) {
+	if len(t) != len(y) {
+		return nil, fmt.Errorf("got %d times and %d values, %w", len(t), len(y), ErrMismatchedBacktestLen)
+	}
+	splits, err := cfg.splits(len(t))
+	if err != nil {
+		return nil, err
+	}
+
+	report := &BacktestReport{
+		Folds: make([]BacktestFold, 0, len(splits)),
+	}
+	for i, split := range splits {
+		eval, err := backtestFold(t, y, opt, split)
+		if err != nil {
+			return nil, fmt.Errorf("unable to evaluate backtest fold %d, %w", i, err)
+		}
+		report.Folds = append(report.Folds, BacktestFold{
+			Split:      split,
+			Origin:     t[split.TestStart],
+			Evaluation: eval,
+		})
+	}
+
+	scores := func(score func(e *Evaluation) float64) (float64, float64) {
+		vals := make([]float64, len(report.Folds))
+		for i, fold := range report.Folds {
+			vals[i] = score(fold.Evaluation)
+		}
+		mean, std := stat.MeanStdDev(vals, nil)
+		if len(vals) == 1 {
+			std = 0
+		}
+		return mean, std
+	}
+	report.Mean.MSE, report.Std.MSE = scores(func(e *Evaluation) float64 { return e.Scores.MSE })
+	report.Mean.MAPE, report.Std.MAPE = scores(func(e *Evaluation) float64 { return e.Scores.MAPE })
+	report.Mean.R2, report.Std.R2 = scores(func(e *Evaluation) float64 { return e.Scores.R2 })
+	report.Mean.MAE, report.Std.MAE = scores(func(e *Evaluation) float64 { return e.MAE })
+	report.Mean.RMSE, report.Std.RMSE = scores(func(e *Evaluation) float64 { return e.RMSE })
+	report.Mean.Bias, report.Std.Bias = scores(func(e *Evaluation) float64 { return e.Bias })
+	report.Mean.Coverage, report.Std.Coverage = scores(func(e *Evaluation) float64 { return e.Coverage })
+	return report, nil
+}
+
+// backtestFold fits a copy of the options on the training range of the split and evaluates the
+// forecast of the test range
+func backtestFold(t []time.Time, y []float64, opt *Options, split models.Split) (*Evaluation, error) {
+	f, err := New(opt.Copy())
+	if err != nil {
+		return nil, err
+	}
+
+	// fitting replaces outliers in the training values so train on a copy
+	yTrain := make([]float64, split.TrainEnd-split.TrainStart)
+	copy(yTrain, y[split.TrainStart:split.TrainEnd])
+	if err := f.Fit(t[split.TrainStart:split.TrainEnd], yTrain); err != nil {
+		return nil, err
+	}
+
+	res, err := f.Predict(t[split.TestStart:split.TestEnd])
+	if err != nil {
+		return nil, err
+	}
+	return res.ScoreAgainst(y[split.TestStart:split.TestEnd])
+}
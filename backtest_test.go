@@ -0,0 +1,156 @@
+package forecaster
+
+import (
+	"encoding/json"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/forecast/options"
+	"github.com/aouyang1/go-forecaster/models"
+	"github.com/aouyang1/go-forecaster/timedataset"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBacktest(t *testing.T) {
+	n := 8 * 24
+	tWin := timedataset.GenerateT(n, time.Hour, func() time.Time {
+		return time.Date(2024, 1, 9, 0, 0, 0, 0, time.UTC)
+	})
+	y := timedataset.GenerateConstY(n, 10.0).
+		Add(timedataset.GenerateWaveY(tWin, 3.0, 86400.0, 1.0, 0.0))
+	rng := rand.New(rand.NewSource(3))
+	for i := range y {
+		y[i] += 0.3 * rng.NormFloat64()
+	}
+
+	newOptions := func() *Options {
+		opt := NewDefaultOptions()
+		opt.SeriesOptions.ForecastOptions.SeasonalityOptions.SeasonalityConfigs = []options.SeasonalityConfig{
+			options.NewDailySeasonalityConfig(2),
+		}
+		opt.SeriesOptions.ForecastOptions.ChangepointOptions.Auto = false
+		opt.UncertaintyOptions.ForecastOptions.ChangepointOptions.Auto = false
+		opt.UncertaintyOptions.ForecastOptions.SeasonalityOptions.SeasonalityConfigs = []options.SeasonalityConfig{
+			options.NewDailySeasonalityConfig(2),
+		}
+		opt.UncertaintyOptions.ResidualWindow = 24
+		return opt
+	}
+
+	testData := map[string]struct {
+		cfg      BacktestConfig
+		y        []float64
+		expected []models.Split
+		err      error
+	}{
+		"expanding": {
+			cfg: BacktestConfig{Folds: 3, Horizon: 24},
+			expected: []models.Split{
+				{TrainStart: 0, TrainEnd: 120, TestStart: 120, TestEnd: 144},
+				{TrainStart: 0, TrainEnd: 144, TestStart: 144, TestEnd: 168},
+				{TrainStart: 0, TrainEnd: 168, TestStart: 168, TestEnd: 192},
+			},
+		},
+		"sliding default train size": {
+			cfg: BacktestConfig{Folds: 2, Window: BacktestSliding, Horizon: 48, Step: 24},
+			expected: []models.Split{
+				{TrainStart: 0, TrainEnd: 120, TestStart: 120, TestEnd: 168},
+				{TrainStart: 24, TrainEnd: 144, TestStart: 144, TestEnd: 192},
+			},
+		},
+		"default horizon": {
+			cfg: BacktestConfig{Folds: 3},
+			expected: []models.Split{
+				{TrainStart: 0, TrainEnd: 48, TestStart: 48, TestEnd: 96},
+				{TrainStart: 0, TrainEnd: 96, TestStart: 96, TestEnd: 144},
+				{TrainStart: 0, TrainEnd: 144, TestStart: 144, TestEnd: 192},
+			},
+		},
+		"train size too long": {
+			cfg: BacktestConfig{Folds: 3, Window: BacktestSliding, TrainSize: 96},
+			err: models.ErrInsufficientFoldData,
+		},
+		"unknown window": {
+			cfg: BacktestConfig{Folds: 2, Window: "tumbling"},
+			err: ErrUnknownBacktestWindow,
+		},
+		"negative horizon": {
+			cfg: BacktestConfig{Folds: 2, Horizon: -1},
+			err: ErrInvalidBacktestConfig,
+		},
+		"no folds": {
+			cfg: BacktestConfig{},
+			err: models.ErrInsufficientFoldData,
+		},
+		"mismatched length": {
+			cfg: BacktestConfig{Folds: 2},
+			y:   y[:10],
+			err: ErrMismatchedBacktestLen,
+		},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			opt := newOptions()
+			before, err := json.Marshal(opt)
+			require.Nil(t, err)
+
+			yIn := y
+			if td.y != nil {
+				yIn = td.y
+			}
+			report, err := Backtest(tWin, yIn, opt, td.cfg)
+			if td.err != nil {
+				assert.ErrorIs(t, err, td.err)
+				return
+			}
+			require.Nil(t, err)
+
+			// the options are left as configured
+			after, err := json.Marshal(opt)
+			require.Nil(t, err)
+			assert.JSONEq(t, string(before), string(after))
+
+			require.Len(t, report.Folds, len(td.expected))
+			for i, fold := range report.Folds {
+				assert.Equal(t, td.expected[i], fold.Split)
+				assert.Equal(t, tWin[fold.Split.TestStart], fold.Origin)
+				assert.Equal(t, fold.Split.TestEnd-fold.Split.TestStart, fold.Evaluation.NumScored)
+			}
+			assert.Less(t, report.Mean.MAPE, 0.05)
+			assert.Greater(t, report.Mean.R2, 0.9)
+			assert.Greater(t, report.Mean.Coverage, 0.8)
+			assert.GreaterOrEqual(t, report.Std.MSE, 0.0)
+		})
+	}
+}
+
+func TestBacktestMissingActuals(t *testing.T) {
+	n := 8 * 24
+	tWin := timedataset.GenerateT(n, time.Hour, func() time.Time {
+		return time.Date(2024, 1, 9, 0, 0, 0, 0, time.UTC)
+	})
+	y := timedataset.GenerateConstY(n, 10.0).
+		Add(timedataset.GenerateWaveY(tWin, 3.0, 86400.0, 1.0, 0.0))
+	y[n-5] = math.NaN()
+
+	opt := NewDefaultOptions()
+	opt.SeriesOptions.ForecastOptions.SeasonalityOptions.SeasonalityConfigs = []options.SeasonalityConfig{
+		options.NewDailySeasonalityConfig(2),
+	}
+	report, err := Backtest(tWin, y, opt, BacktestConfig{Folds: 2, Horizon: 24})
+	require.Nil(t, err)
+
+	last := report.Folds[len(report.Folds)-1].Evaluation
+	assert.Equal(t, 23, last.NumScored)
+
+	// the report with missing actuals is json encodable
+	out, err := json.Marshal(report)
+	require.Nil(t, err)
+	var decoded BacktestReport
+	require.Nil(t, json.Unmarshal(out, &decoded))
+	assert.True(t, math.IsNaN(decoded.Folds[1].Evaluation.Errors[19]))
+}
@@ -0,0 +1,157 @@
+package forecaster
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/errs"
+)
+
+var (
+	ErrInvalidBatchOptions = errs.NewConfigError(errs.CodeInvalidOption, "batch workers and horizon must be non-negative", nil)
+	ErrBatchSeriesFailed   = errs.NewFitError(errs.CodeFitFailed, "one or more series of the batch failed", nil)
+)
+
+// BatchSeries is a single series of a batch
+type BatchSeries struct {
+	T []time.Time `json:"time"`
+	Y []float64   `json:"values"`
+}
+
+// BatchOptions configures a batch fit. Workers is the number of series fit concurrently which defaults
+// to GOMAXPROCS. If Horizon is positive the results of each series are the forecast of the Horizon
+// periods after its training data at the interval Freq, inferred from the training data if unset.
+// Otherwise the results are the fit of the training data.
+type BatchOptions struct {
+	Workers int           `json:"workers,omitempty"`
+	Horizon int           `json:"horizon,omitempty"`
+	Freq    time.Duration `json:"freq,omitempty"`
+}
+
+// Batch fits many independent series with the same options
+type Batch struct {
+	opt      *Options
+	batchOpt BatchOptions
+}
+
+// BatchResult is the model and results of every series of the batch fit successfully along with the
+// error of every series that failed keyed by series name
+type BatchResult struct {
+	Models  map[string]Model    `json:"models"`
+	Results map[string]*Results `json:"results"`
+	Errors  map[string]error    `json:"-"`
+}
+
+// Failed returns the names of the series that failed in sorted order
+func (r *BatchResult) Failed() []string {
+	var names []string
+	for name := range r.Errors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewBatch initializes a batch with the shared options of every series which default to the forecaster
+// defaults if nil. The options are copied so later changes do not affect the batch and each series is
+// fit with its own copy.
+func NewBatch(opt *Options, batchOpt BatchOptions) (*Batch, error) {
+	if batchOpt.Workers < 0 || batchOpt.Horizon < 0 {
+		return nil, fmt.Errorf("workers %d and horizon %d, %w", batchOpt.Workers, batchOpt.Horizon, ErrInvalidBatchOptions)
+	}
+	if batchOpt.Workers == 0 {
+		batchOpt.Workers = runtime.GOMAXPROCS(0)
+	}
+	if opt == nil {
+		opt = NewDefaultOptions()
+	}
+	return &Batch{opt: opt.Copy(), batchOpt: batchOpt}, nil
+}
+
+// Fit fits every series concurrently. A series that fails does not stop the others and its error is
+// reported in the result. Series not yet finished when the context is done stop early and are reported
+// with the context error which is also returned. ErrBatchSeriesFailed is returned with the result if any other
+// series fails.
+func (b *Batch) Fit(ctx context.Context, series map[string]BatchSeries) (*BatchResult, error) {
+	res := &BatchResult{
+		Models:  make(map[string]Model, len(series)),
+		Results: make(map[string]*Results, len(series)),
+		Errors:  make(map[string]error),
+	}
+
+	names := make([]string, 0, len(series))
+	for name := range series {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	jobs := make(chan string)
+	var resMu sync.Mutex
+	var wg sync.WaitGroup
+	for i := 0; i < b.batchOpt.Workers && i < len(names); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for name := range jobs {
+				model, results, err := b.fitSeries(ctx, series[name])
+
+				resMu.Lock()
+				if err != nil {
+					res.Errors[name] = err
+				} else {
+					res.Models[name] = model
+					res.Results[name] = results
+				}
+				resMu.Unlock()
+			}
+		}()
+	}
+	for _, name := range names {
+		jobs <- name
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return res, err
+	}
+	if len(res.Errors) > 0 {
+		return res, fmt.Errorf("%d of %d series, %w", len(res.Errors), len(series), ErrBatchSeriesFailed)
+	}
+	return res, nil
+}
+
+// fitSeries fits a single series with a copy of the batch options until the context is done
+func (b *Batch) fitSeries(ctx context.Context, s BatchSeries) (Model, *Results, error) {
+	if err := ctx.Err(); err != nil {
+		return Model{}, nil, err
+	}
+	f, err := New(b.opt.Copy())
+	if err != nil {
+		return Model{}, nil, err
+	}
+	if err := f.FitCtx(ctx, s.T, s.Y); err != nil {
+		return Model{}, nil, err
+	}
+	model, err := f.Model()
+	if err != nil {
+		return Model{}, nil, err
+	}
+	if b.batchOpt.Horizon == 0 {
+		return model, f.FitResults(), nil
+	}
+
+	horizon, err := f.MakeFuturePeriods(b.batchOpt.Horizon, b.batchOpt.Freq)
+	if err != nil {
+		return Model{}, nil, fmt.Errorf("unable to make future periods, %w", err)
+	}
+	results, err := f.PredictCtx(ctx, horizon)
+	if err != nil {
+		return Model{}, nil, err
+	}
+	return model, results, nil
+}
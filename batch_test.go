@@ -0,0 +1,129 @@
+package forecaster
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/forecast/options"
+	"github.com/aouyang1/go-forecaster/timedataset"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchFit(t *testing.T) {
+	n := 7 * 24
+	tWin := timedataset.GenerateT(n, time.Hour, func() time.Time {
+		return time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC)
+	})
+	rng := rand.New(rand.NewSource(7))
+	newSeries := func(level float64) BatchSeries {
+		y := make([]float64, n)
+		for i := range y {
+			y[i] = level + 3*math.Sin(2*math.Pi*float64(i)/24) + 0.1*rng.NormFloat64()
+		}
+		return BatchSeries{T: tWin, Y: y}
+	}
+	series := map[string]BatchSeries{
+		"a": newSeries(10),
+		"b": newSeries(20),
+		"c": newSeries(30),
+	}
+
+	opt := NewDefaultOptions()
+	opt.SeriesOptions.ForecastOptions.SeasonalityOptions.SeasonalityConfigs = []options.SeasonalityConfig{
+		options.NewDailySeasonalityConfig(2),
+	}
+	opt.UncertaintyOptions.ForecastOptions.SeasonalityOptions.SeasonalityConfigs = []options.SeasonalityConfig{
+		options.NewDailySeasonalityConfig(2),
+	}
+	opt.UncertaintyOptions.ResidualWindow = 24
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	testData := map[string]struct {
+		ctx      context.Context
+		batchOpt BatchOptions
+		series   map[string]BatchSeries
+		expected []string
+		failed   []string
+		err      error
+	}{
+		"fit": {
+			batchOpt: BatchOptions{Workers: 2},
+			series:   series,
+			expected: []string{"a", "b", "c"},
+		},
+		"horizon": {
+			batchOpt: BatchOptions{Horizon: 24},
+			series:   series,
+			expected: []string{"a", "b", "c"},
+		},
+		"failed series": {
+			series: map[string]BatchSeries{
+				"a":   series["a"],
+				"bad": {T: tWin, Y: series["b"].Y[:10]},
+			},
+			expected: []string{"a"},
+			failed:   []string{"bad"},
+			err:      ErrBatchSeriesFailed,
+		},
+		"canceled": {
+			ctx:    canceled,
+			series: series,
+			failed: []string{"a", "b", "c"},
+			err:    context.Canceled,
+		},
+		"invalid workers": {
+			batchOpt: BatchOptions{Workers: -1},
+			err:      ErrInvalidBatchOptions,
+		},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			b, err := NewBatch(opt, td.batchOpt)
+			if err != nil {
+				assert.ErrorIs(t, err, td.err)
+				return
+			}
+			ctx := td.ctx
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			res, err := b.Fit(ctx, td.series)
+			if td.err != nil {
+				assert.ErrorIs(t, err, td.err)
+			} else {
+				require.Nil(t, err)
+			}
+			require.NotNil(t, res)
+			assert.Equal(t, td.failed, res.Failed())
+			assert.Len(t, res.Models, len(td.expected))
+			require.Len(t, res.Results, len(td.expected))
+
+			for _, name := range td.expected {
+				results := res.Results[name]
+				require.NotNil(t, results, name)
+				if td.batchOpt.Horizon > 0 {
+					require.Len(t, results.T, td.batchOpt.Horizon)
+					assert.Equal(t, tWin[n-1].Add(time.Hour), results.T[0])
+				} else {
+					require.Len(t, results.T, n)
+				}
+
+				// each series keeps its own level and the loaded model reproduces the results
+				assert.InDelta(t, series[name].Y[0], results.Forecast[0], 1.0, name)
+
+				f, err := NewFromModel(res.Models[name])
+				require.Nil(t, err)
+				pred, err := f.Predict(results.T)
+				require.Nil(t, err)
+				assert.InDeltaSlice(t, results.Forecast, pred.Forecast, 1e-9, name)
+			}
+		})
+	}
+}
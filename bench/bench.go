@@ -0,0 +1,309 @@
+// Package bench provides reproducible end-to-end benchmarks of fitting and predicting with a
+// forecaster across data sizes and option complexity along with a performance budget checker so that
+// regressions in the fit or predict path can be caught programmatically.
+package bench
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"runtime"
+	"time"
+
+	forecaster "github.com/aouyang1/go-forecaster"
+	"github.com/aouyang1/go-forecaster/errs"
+	"github.com/aouyang1/go-forecaster/forecast"
+	"github.com/aouyang1/go-forecaster/forecast/options"
+	"github.com/aouyang1/go-forecaster/timedataset"
+)
+
+var (
+	ErrUnknownScenario = errs.NewConfigError(errs.CodeInvalidOption, "unknown benchmark scenario", nil)
+	ErrUnknownStage    = errs.NewConfigError(errs.CodeInvalidOption, "unknown benchmark stage", nil)
+	ErrNoScenarioData  = errs.NewConfigError(errs.CodeMissingOption, "benchmark scenario has no data or options", nil)
+	ErrBudgetExceeded  = errs.NewDataError(errs.CodeInvalidValue, "performance budget exceeded", nil)
+)
+
+const (
+	// DefaultRuns is the number of measured runs of a stage when none is specified
+	DefaultRuns = 5
+
+	// Seed is the seed of the noise in every scenario so that runs are reproducible
+	Seed = 42
+)
+
+// Stage is the part of the forecaster lifecycle that is measured
+type Stage string
+
+const (
+	// StageFit measures creating a forecaster and fitting the scenario series
+	StageFit Stage = "fit"
+
+	// StagePredict measures predicting the horizon with a forecaster loaded from the fitted model
+	StagePredict Stage = "predict"
+
+	// StagePredictor measures predicting the horizon of the series model with a compiled predictor
+	// reusing its destination buffer
+	StagePredictor Stage = "predictor"
+)
+
+// Scenario is a named synthetic series to fit and the horizon to predict. Options constructs a fresh
+// set of options for every run since fitting updates them in place.
+type Scenario struct {
+	Name     string
+	N        int
+	Interval time.Duration
+	Horizon  time.Duration
+	Options  func() *forecaster.Options
+}
+
+// Data generates the deterministic training times and values along with the prediction horizon of
+// the scenario. The series has a trend, a daily and weekly wave, a level shift half way through, and
+// gaussian noise.
+func (s Scenario) Data() ([]time.Time, []float64, []time.Time) {
+	end := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	t := timedataset.GenerateT(s.N, s.Interval, func() time.Time { return end })
+
+	rng := rand.New(rand.NewSource(Seed))
+	y := make([]float64, s.N)
+	for i, tPnt := range t {
+		sec := float64(tPnt.Unix())
+		y[i] = 100 + 0.001*float64(i) +
+			10*math.Sin(2*math.Pi*sec/86400) +
+			5*math.Sin(2*math.Pi*sec/(7*86400)) +
+			rng.NormFloat64()
+		if i >= s.N/2 {
+			y[i] += 20
+		}
+	}
+
+	var horizon []time.Time
+	if s.Interval > 0 {
+		numHorizon := int(s.Horizon / s.Interval)
+		horizon = timedataset.GenerateT(numHorizon, s.Interval, func() time.Time { return end.Add(s.Horizon) })
+	}
+	return t, y, horizon
+}
+
+// simpleOptions models a daily seasonality without any events or changepoints
+func simpleOptions() *forecaster.Options {
+	opt := forecaster.NewDefaultOptions()
+	opt.SeriesOptions.ForecastOptions.SeasonalityOptions.SeasonalityConfigs = []options.SeasonalityConfig{
+		options.NewDailySeasonalityConfig(2),
+	}
+	opt.UncertaintyOptions.ForecastOptions.SeasonalityOptions.SeasonalityConfigs = []options.SeasonalityConfig{
+		options.NewDailySeasonalityConfig(2),
+	}
+	return opt
+}
+
+// complexOptions models daily and weekly seasonality with auto changepoints, weekends, and a daylight
+// saving time mixture
+func complexOptions() *forecaster.Options {
+	opt := forecaster.NewDefaultOptions()
+	fOpt := opt.SeriesOptions.ForecastOptions
+	fOpt.SeasonalityOptions.SeasonalityConfigs = []options.SeasonalityConfig{
+		options.NewDailySeasonalityConfig(8),
+		options.NewWeeklySeasonalityConfig(4),
+	}
+	fOpt.ChangepointOptions.Auto = true
+	fOpt.WeekendOptions.Enabled = true
+	fOpt.DSTOptions = options.DSTOptions{
+		Enabled:           true,
+		Mixture:           true,
+		TimezoneLocations: []string{"America/Los_Angeles"},
+	}
+	return opt
+}
+
+// Scenarios returns the default benchmark scenarios spanning a day, a week, and four weeks of minutely
+// or five minutely data with simple and complex options
+func Scenarios() []Scenario {
+	return []Scenario{
+		{Name: "simple_1d", N: 24 * 60, Interval: time.Minute, Horizon: 24 * time.Hour, Options: simpleOptions},
+		{Name: "simple_1w", N: 7 * 24 * 60, Interval: time.Minute, Horizon: 24 * time.Hour, Options: simpleOptions},
+		{Name: "complex_1w", N: 7 * 24 * 12, Interval: 5 * time.Minute, Horizon: 24 * time.Hour, Options: complexOptions},
+		{Name: "complex_4w", N: 28 * 24 * 12, Interval: 5 * time.Minute, Horizon: 24 * time.Hour, Options: complexOptions},
+	}
+}
+
+// FindScenario returns the default scenario with the name
+func FindScenario(name string) (Scenario, error) {
+	for _, s := range Scenarios() {
+		if s.Name == name {
+			return s, nil
+		}
+	}
+	return Scenario{}, fmt.Errorf("%q, %w", name, ErrUnknownScenario)
+}
+
+// Fit creates a forecaster with fresh options and fits the series
+func (s Scenario) Fit(t []time.Time, y []float64) (*forecaster.Forecaster, error) {
+	if s.Options == nil {
+		return nil, fmt.Errorf("scenario %q, %w", s.Name, ErrNoScenarioData)
+	}
+	f, err := forecaster.New(s.Options())
+	if err != nil {
+		return nil, err
+	}
+	if err := f.Fit(t, y); err != nil {
+		return nil, fmt.Errorf("unable to fit scenario %q, %w", s.Name, err)
+	}
+	return f, nil
+}
+
+// Runner returns a function running a single iteration of the stage. Any setup such as fitting the
+// model to predict from is done before returning so it is excluded from the measurement.
+func (s Scenario) Runner(stage Stage) (func() error, error) {
+	t, y, horizon := s.Data()
+	if len(t) == 0 || s.Options == nil {
+		return nil, fmt.Errorf("scenario %q, %w", s.Name, ErrNoScenarioData)
+	}
+	switch stage {
+	case StageFit:
+		return func() error {
+			_, err := s.Fit(t, y)
+			return err
+		}, nil
+	case StagePredict:
+		f, err := s.Fit(t, y)
+		if err != nil {
+			return nil, err
+		}
+		m, err := f.Model()
+		if err != nil {
+			return nil, err
+		}
+		loaded, err := forecaster.NewFromModel(m)
+		if err != nil {
+			return nil, err
+		}
+		return func() error {
+			_, err := loaded.Predict(horizon)
+			return err
+		}, nil
+	case StagePredictor:
+		f, err := s.Fit(t, y)
+		if err != nil {
+			return nil, err
+		}
+		m, err := f.Model()
+		if err != nil {
+			return nil, err
+		}
+		p, err := forecast.NewPredictor(m.Series)
+		if err != nil {
+			return nil, err
+		}
+		dst := make([]float64, len(horizon))
+		return func() error {
+			_, err := p.Predict(horizon, dst)
+			return err
+		}, nil
+	}
+	return nil, fmt.Errorf("%q, %w", stage, ErrUnknownStage)
+}
+
+// Result is the average cost of a single run of a stage
+type Result struct {
+	Runs        int           `json:"runs"`
+	Duration    time.Duration `json:"duration"`
+	AllocsPerOp uint64        `json:"allocs_per_op"`
+	BytesPerOp  uint64        `json:"bytes_per_op"`
+}
+
+// Measure runs the stage of the scenario a number of times after a warm up run and returns the
+// average duration and allocations of a run. Runs defaults to DefaultRuns if not positive.
+func Measure(s Scenario, stage Stage, runs int) (Result, error) {
+	if runs <= 0 {
+		runs = DefaultRuns
+	}
+	run, err := s.Runner(stage)
+	if err != nil {
+		return Result{}, err
+	}
+	if err := run(); err != nil {
+		return Result{}, err
+	}
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	for i := 0; i < runs; i++ {
+		if err := run(); err != nil {
+			return Result{}, err
+		}
+	}
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+
+	return Result{
+		Runs:        runs,
+		Duration:    elapsed / time.Duration(runs),
+		AllocsPerOp: (after.Mallocs - before.Mallocs) / uint64(runs),
+		BytesPerOp:  (after.TotalAlloc - before.TotalAlloc) / uint64(runs),
+	}, nil
+}
+
+// Budget is the maximum average cost of a run of a stage of a default scenario. Zero limits are not
+// checked.
+type Budget struct {
+	Scenario    string        `json:"scenario"`
+	Stage       Stage         `json:"stage"`
+	MaxDuration time.Duration `json:"max_duration,omitempty"`
+	MaxAllocs   uint64        `json:"max_allocs,omitempty"`
+	MaxBytes    uint64        `json:"max_bytes,omitempty"`
+}
+
+// Violation is a budget that was exceeded along with the measured result
+type Violation struct {
+	Budget Budget `json:"budget"`
+	Result Result `json:"result"`
+}
+
+// String describes every limit of the budget that was exceeded
+func (v Violation) String() string {
+	res := fmt.Sprintf("%s %s", v.Budget.Scenario, v.Budget.Stage)
+	if v.Budget.MaxDuration > 0 && v.Result.Duration > v.Budget.MaxDuration {
+		res += fmt.Sprintf(", duration %s > %s", v.Result.Duration, v.Budget.MaxDuration)
+	}
+	if v.Budget.MaxAllocs > 0 && v.Result.AllocsPerOp > v.Budget.MaxAllocs {
+		res += fmt.Sprintf(", allocs %d > %d", v.Result.AllocsPerOp, v.Budget.MaxAllocs)
+	}
+	if v.Budget.MaxBytes > 0 && v.Result.BytesPerOp > v.Budget.MaxBytes {
+		res += fmt.Sprintf(", bytes %d > %d", v.Result.BytesPerOp, v.Budget.MaxBytes)
+	}
+	return res
+}
+
+// exceeded returns true if the result is over any limit of the budget
+func (b Budget) exceeded(r Result) bool {
+	return (b.MaxDuration > 0 && r.Duration > b.MaxDuration) ||
+		(b.MaxAllocs > 0 && r.AllocsPerOp > b.MaxAllocs) ||
+		(b.MaxBytes > 0 && r.BytesPerOp > b.MaxBytes)
+}
+
+// Check measures every budget with the number of runs and returns the violations. An error wrapping
+// ErrBudgetExceeded is returned if there are any violations so callers can fail a test or a build on
+// a performance regression.
+func Check(budgets []Budget, runs int) ([]Violation, error) {
+	var violations []Violation
+	for _, b := range budgets {
+		s, err := FindScenario(b.Scenario)
+		if err != nil {
+			return nil, err
+		}
+		res, err := Measure(s, b.Stage, runs)
+		if err != nil {
+			return nil, fmt.Errorf("unable to measure %s %s, %w", b.Scenario, b.Stage, err)
+		}
+		if b.exceeded(res) {
+			violations = append(violations, Violation{Budget: b, Result: res})
+		}
+	}
+	if len(violations) > 0 {
+		return violations, fmt.Errorf("%d of %d budgets, %w", len(violations), len(budgets), ErrBudgetExceeded)
+	}
+	return nil, nil
+}
@@ -0,0 +1,111 @@
+package bench
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func benchmarkStage(b *testing.B, stage Stage) {
+	for _, s := range Scenarios() {
+		b.Run(s.Name, func(b *testing.B) {
+			run, err := s.Runner(stage)
+			require.Nil(b, err)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := run(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkFit(b *testing.B) {
+	benchmarkStage(b, StageFit)
+}
+
+func BenchmarkPredict(b *testing.B) {
+	benchmarkStage(b, StagePredict)
+}
+
+func BenchmarkPredictor(b *testing.B) {
+	benchmarkStage(b, StagePredictor)
+}
+
+func TestScenarioData(t *testing.T) {
+	s, err := FindScenario("simple_1d")
+	require.Nil(t, err)
+
+	t1, y1, h1 := s.Data()
+	t2, y2, h2 := s.Data()
+	assert.Len(t, t1, s.N)
+	assert.Equal(t, t1, t2)
+	assert.Equal(t, y1, y2)
+	assert.Equal(t, h1, h2)
+	require.Len(t, h1, 24*60)
+	assert.Equal(t, t1[len(t1)-1].Add(time.Minute), h1[0])
+	assert.Equal(t, time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC), t1[0])
+
+	_, err = FindScenario("unknown")
+	assert.ErrorIs(t, err, ErrUnknownScenario)
+
+	_, err = s.Runner(Stage("unknown"))
+	assert.ErrorIs(t, err, ErrUnknownStage)
+}
+
+func TestCheck(t *testing.T) {
+	testData := map[string]struct {
+		budgets       []Budget
+		numViolations int
+		err           error
+	}{
+		"within budget": {
+			budgets: []Budget{
+				{Scenario: "simple_1d", Stage: StagePredict, MaxDuration: time.Minute},
+			},
+		},
+		"over duration": {
+			budgets: []Budget{
+				{Scenario: "simple_1d", Stage: StagePredict, MaxDuration: time.Nanosecond},
+				{Scenario: "simple_1d", Stage: StagePredict, MaxDuration: time.Minute},
+			},
+			numViolations: 1,
+			err:           ErrBudgetExceeded,
+		},
+		"over allocs and bytes": {
+			budgets: []Budget{
+				{Scenario: "simple_1d", Stage: StageFit, MaxAllocs: 1, MaxBytes: 1},
+			},
+			numViolations: 1,
+			err:           ErrBudgetExceeded,
+		},
+		"unknown scenario": {
+			budgets: []Budget{
+				{Scenario: "unknown", Stage: StageFit, MaxDuration: time.Minute},
+			},
+			err: ErrUnknownScenario,
+		},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			violations, err := Check(td.budgets, 1)
+			if td.err != nil {
+				assert.True(t, errors.Is(err, td.err))
+			} else {
+				require.Nil(t, err)
+			}
+			require.Len(t, violations, td.numViolations)
+			for _, v := range violations {
+				assert.True(t, v.Budget.exceeded(v.Result))
+				assert.NotEqual(t, v.Budget.Scenario+" "+string(v.Budget.Stage), v.String())
+			}
+		})
+	}
+}
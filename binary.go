@@ -0,0 +1,152 @@
+package forecaster
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aouyang1/go-forecaster/forecast"
+)
+
+// binaryVersion is the version of the binary encoding written by Model.MarshalBinary
+const binaryVersion = 1
+
+// modelBinary is the gob encoded model. The options are stored as json without the bootstrap and
+// quantile models and residuals which are stored alongside the series and uncertainty models in the
+// compact binary encoding of forecast.Model.
+type modelBinary struct {
+	Version            int
+	SchemaVersion      int
+	Options            []byte
+	Series             []byte
+	Uncertainty        []byte
+	BootstrapModels    [][]byte
+	BootstrapResiduals []float64
+	LowerQuantileModel []byte
+	UpperQuantileModel []byte
+	Scores             *forecast.Scores
+	Residuals          *ResidualSummary
+}
+
+// MarshalBinary encodes the model in a compact binary format which is smaller and faster to load
+// than json for models with many features. Load the model with UnmarshalBinary and NewFromModel.
+func (m Model) MarshalBinary() ([]byte, error) {
+	out := modelBinary{
+		Version:       binaryVersion,
+		SchemaVersion: m.SchemaVersion,
+		Scores:        m.Scores,
+		Residuals:     m.Residuals,
+	}
+
+	var err error
+	if out.Series, err = m.Series.MarshalBinary(); err != nil {
+		return nil, fmt.Errorf("unable to encode series model, %w", err)
+	}
+	if out.Uncertainty, err = m.Uncertainty.MarshalBinary(); err != nil {
+		return nil, fmt.Errorf("unable to encode uncertainty model, %w", err)
+	}
+
+	if m.Options != nil {
+		opt := *m.Options
+		if opt.UncertaintyOptions != nil {
+			uncOpt := *opt.UncertaintyOptions
+			for _, bm := range uncOpt.BootstrapModels {
+				enc, err := bm.MarshalBinary()
+				if err != nil {
+					return nil, fmt.Errorf("unable to encode bootstrap model, %w", err)
+				}
+				out.BootstrapModels = append(out.BootstrapModels, enc)
+			}
+			out.BootstrapResiduals = uncOpt.BootstrapResiduals
+			if out.LowerQuantileModel, err = marshalOptionalModel(uncOpt.LowerQuantileModel); err != nil {
+				return nil, fmt.Errorf("unable to encode lower quantile model, %w", err)
+			}
+			if out.UpperQuantileModel, err = marshalOptionalModel(uncOpt.UpperQuantileModel); err != nil {
+				return nil, fmt.Errorf("unable to encode upper quantile model, %w", err)
+			}
+			uncOpt.BootstrapModels = nil
+			uncOpt.BootstrapResiduals = nil
+			uncOpt.LowerQuantileModel = nil
+			uncOpt.UpperQuantileModel = nil
+			opt.UncertaintyOptions = &uncOpt
+		}
+		if out.Options, err = json.Marshal(&opt); err != nil {
+			return nil, fmt.Errorf("unable to encode options, %w", err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(out); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary decodes a model encoded by MarshalBinary
+func (m *Model) UnmarshalBinary(data []byte) error {
+	var in modelBinary
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&in); err != nil {
+		return fmt.Errorf("%s, %w", err.Error(), forecast.ErrInvalidBinaryModel)
+	}
+	if in.Version != binaryVersion {
+		return fmt.Errorf("binary version %d with latest %d, %w", in.Version, binaryVersion, forecast.ErrInvalidBinaryModel)
+	}
+
+	res := Model{
+		SchemaVersion: in.SchemaVersion,
+		Scores:        in.Scores,
+		Residuals:     in.Residuals,
+	}
+	if err := res.Series.UnmarshalBinary(in.Series); err != nil {
+		return fmt.Errorf("unable to decode series model, %w", err)
+	}
+	if err := res.Uncertainty.UnmarshalBinary(in.Uncertainty); err != nil {
+		return fmt.Errorf("unable to decode uncertainty model, %w", err)
+	}
+
+	if in.Options != nil {
+		res.Options = &Options{}
+		if err := json.Unmarshal(in.Options, res.Options); err != nil {
+			return fmt.Errorf("unable to decode options, %w", err)
+		}
+		if uncOpt := res.Options.UncertaintyOptions; uncOpt != nil {
+			for _, enc := range in.BootstrapModels {
+				var bm forecast.Model
+				if err := bm.UnmarshalBinary(enc); err != nil {
+					return fmt.Errorf("unable to decode bootstrap model, %w", err)
+				}
+				uncOpt.BootstrapModels = append(uncOpt.BootstrapModels, bm)
+			}
+			uncOpt.BootstrapResiduals = in.BootstrapResiduals
+
+			var err error
+			if uncOpt.LowerQuantileModel, err = unmarshalOptionalModel(in.LowerQuantileModel); err != nil {
+				return fmt.Errorf("unable to decode lower quantile model, %w", err)
+			}
+			if uncOpt.UpperQuantileModel, err = unmarshalOptionalModel(in.UpperQuantileModel); err != nil {
+				return fmt.Errorf("unable to decode upper quantile model, %w", err)
+			}
+		}
+	}
+	*m = res
+	return nil
+}
+
+func marshalOptionalModel(m *forecast.Model) ([]byte, error) {
+	if m == nil {
+		return nil, nil
+	}
+	return m.MarshalBinary()
+}
+
+func unmarshalOptionalModel(data []byte) (*forecast.Model, error) {
+	if data == nil {
+		return nil, nil
+	}
+	m := new(forecast.Model)
+	if err := m.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
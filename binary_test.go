@@ -0,0 +1,94 @@
+package forecaster
+
+import (
+	"encoding/json"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/forecast"
+	"github.com/aouyang1/go-forecaster/forecast/options"
+	"github.com/aouyang1/go-forecaster/timedataset"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModelBinary(t *testing.T) {
+	n := 4 * 24
+	tWin := timedataset.GenerateT(n, time.Hour, func() time.Time {
+		return time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC)
+	})
+	rng := rand.New(rand.NewSource(11))
+	y := make([]float64, n)
+	for i := range y {
+		y[i] = 10.0 + 0.05*float64(i) + rng.NormFloat64()
+	}
+	horizon := []time.Time{tWin[n-1].Add(time.Hour), tWin[n-1].Add(7 * 24 * time.Hour)}
+
+	testData := map[string]struct {
+		method UncertaintyMethod
+	}{
+		"rolling std": {},
+		"bootstrap":   {method: UncertaintyMethodBootstrap},
+		"quantile":    {method: UncertaintyMethodQuantile},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			opt := NewDefaultOptions()
+			opt.SeriesOptions.ForecastOptions.SeasonalityOptions.SeasonalityConfigs = []options.SeasonalityConfig{
+				options.NewDailySeasonalityConfig(2),
+			}
+			opt.UncertaintyOptions.ForecastOptions.SeasonalityOptions.SeasonalityConfigs = []options.SeasonalityConfig{
+				options.NewDailySeasonalityConfig(2),
+			}
+			opt.UncertaintyOptions.ResidualWindow = 12
+			opt.UncertaintyOptions.Method = td.method
+			opt.UncertaintyOptions.Bootstraps = 5
+			opt.UncertaintyOptions.BootstrapSeed = 1
+
+			f, err := New(opt)
+			require.Nil(t, err)
+			require.Nil(t, f.Fit(tWin, y))
+			model, err := f.Model()
+			require.Nil(t, err)
+
+			enc, err := model.MarshalBinary()
+			require.Nil(t, err)
+			jsonEnc, err := json.Marshal(model)
+			require.Nil(t, err)
+			assert.Less(t, len(enc), len(jsonEnc))
+
+			// the model decodes the same as from json and predicts the same as the fit forecaster
+			var decoded Model
+			require.Nil(t, decoded.UnmarshalBinary(enc))
+			var fromJSON Model
+			require.Nil(t, json.Unmarshal(jsonEnc, &fromJSON))
+			assert.Equal(t, fromJSON.Options.UncertaintyOptions.BootstrapResiduals, decoded.Options.UncertaintyOptions.BootstrapResiduals)
+			assert.Len(t, decoded.Options.UncertaintyOptions.BootstrapModels, len(fromJSON.Options.UncertaintyOptions.BootstrapModels))
+			assert.Equal(t, fromJSON.Options.UncertaintyOptions.LowerQuantileModel == nil, decoded.Options.UncertaintyOptions.LowerQuantileModel == nil)
+			assert.Equal(t, fromJSON.Series.Weights, decoded.Series.Weights)
+			assert.Equal(t, fromJSON.Uncertainty.Weights, decoded.Uncertainty.Weights)
+			assert.Equal(t, fromJSON.Scores, decoded.Scores)
+
+			// the bands are left to the models rather than the options
+			assert.NotNil(t, model.Options.UncertaintyOptions)
+			if td.method == UncertaintyMethodBootstrap {
+				assert.NotEmpty(t, model.Options.UncertaintyOptions.BootstrapModels)
+			}
+
+			loaded, err := NewFromModel(decoded)
+			require.Nil(t, err)
+			expected, err := f.Predict(horizon)
+			require.Nil(t, err)
+			res, err := loaded.Predict(horizon)
+			require.Nil(t, err)
+			assert.InDeltaSlice(t, expected.Forecast, res.Forecast, 1e-9)
+			assert.InDeltaSlice(t, expected.Upper, res.Upper, 1e-9)
+			assert.InDeltaSlice(t, expected.Lower, res.Lower, 1e-9)
+		})
+	}
+
+	var m Model
+	assert.ErrorIs(t, m.UnmarshalBinary([]byte("not a model")), forecast.ErrInvalidBinaryModel)
+}
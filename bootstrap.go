@@ -0,0 +1,178 @@
+package forecaster
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/errs"
+	"github.com/aouyang1/go-forecaster/forecast"
+	"gonum.org/v1/gonum/stat"
+)
+
+var (
+	ErrUnknownUncertaintyMethod = errs.NewConfigError(errs.CodeInvalidOption, "unknown uncertainty method", nil)
+	ErrNegativeBootstraps       = errs.NewConfigError(errs.CodeInvalidOption, "number of bootstraps must be non-negative", nil)
+)
+
+// UncertaintyMethod is how the prediction intervals of the forecaster are computed
+type UncertaintyMethod string
+
+const (
+	// UncertaintyMethodRollingStd predicts symmetric bands from the model of the rolling residual
+	// standard deviation
+	UncertaintyMethodRollingStd UncertaintyMethod = ""
+
+	// UncertaintyMethodBootstrap predicts empirical bands from series models refit on block resampled
+	// training residuals
+	UncertaintyMethodBootstrap UncertaintyMethod = "bootstrap"
+
+	// UncertaintyMethodQuantile predicts asymmetric bands from quantile regressions of the series
+	// residual
+	UncertaintyMethodQuantile UncertaintyMethod = "quantile"
+)
+
+// DefaultBootstraps is the number of bootstrap replicates if none is configured for the bootstrap method
+const DefaultBootstraps = 100
+
+// Validate returns an error if the uncertainty method is unknown
+func (m UncertaintyMethod) Validate() error {
+	switch m {
+	case UncertaintyMethodRollingStd, UncertaintyMethodBootstrap, UncertaintyMethodQuantile:
+		return nil
+	default:
+		return fmt.Errorf("%q, %w", m, ErrUnknownUncertaintyMethod)
+	}
+}
+
+// fitBootstrap fits a copy of the series model on the fitted values plus the resampled residual
+func (f *Forecaster) fitBootstrap(ctx context.Context, i int, t []time.Time, fitted, sampled, weights []float64, x forecast.Regressors) (*forecast.Forecast, error) {
+	y := make([]float64, len(t))
+	for j := range y {
+		y[j] = fitted[j] + sampled[j]
+	}
+
+	opt := f.opt.SeriesOptions.ForecastOptions.Copy()
+	opt.TrainingHooks = f.opt.TrainingHooks.forStage(TrainingStageBootstrap)
+	bootstrap, err := forecast.New(opt)
+	if err != nil {
+		return nil, fmt.Errorf("unable to initialize bootstrap %d, %w", i, err)
+	}
+	switch {
+	case weights != nil:
+		err = bootstrap.FitWeightedCtx(ctx, t, y, weights)
+	case x != nil:
+		err = bootstrap.FitWithRegressorsCtx(ctx, t, y, x)
+	default:
+		err = bootstrap.FitCtx(ctx, t, y)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to fit bootstrap %d, %w", i, err)
+	}
+	return bootstrap, nil
+}
+
+// fitBootstrapUncertainty refits the series on the fitted values plus block resampled residuals for
+// each replicate and persists the replicate models along with the observed training residuals so the
+// bootstrap bands can be predicted from a loaded model
+func (f *Forecaster) fitBootstrapUncertainty(ctx context.Context, t []time.Time, residual, weights []float64, x forecast.Regressors) error {
+	u := f.opt.UncertaintyOptions
+	u.BootstrapModels = nil
+	u.BootstrapResiduals = nil
+	f.bootstraps = nil
+	if err := u.Method.Validate(); err != nil {
+		return err
+	}
+	if u.Bootstraps < 0 {
+		return fmt.Errorf("got %d bootstraps, %w", u.Bootstraps, ErrNegativeBootstraps)
+	}
+	if u.Method != UncertaintyMethodBootstrap {
+		return nil
+	}
+	numBootstraps := u.Bootstraps
+	if numBootstraps == 0 {
+		numBootstraps = DefaultBootstraps
+	}
+
+	fitted, _, err := f.seriesForecast.PredictWithRegressors(t, x)
+	if err != nil {
+		return fmt.Errorf("unable to predict fitted series, %w", err)
+	}
+
+	r := rand.New(rand.NewSource(u.BootstrapSeed))
+	for i := 0; i < numBootstraps; i++ {
+		bootstrap, err := f.fitBootstrap(ctx, i, t, fitted, blockResample(r, residual, u.ResidualWindow), weights, x)
+		if err != nil {
+			return err
+		}
+		model, err := bootstrap.Model()
+		if err != nil {
+			return fmt.Errorf("unable to fetch model of bootstrap %d, %w", i, err)
+		}
+		u.BootstrapModels = append(u.BootstrapModels, model)
+		f.bootstraps = append(f.bootstraps, bootstrap)
+	}
+	for _, v := range residual {
+		if !math.IsNaN(v) {
+			u.BootstrapResiduals = append(u.BootstrapResiduals, v)
+		}
+	}
+	return nil
+}
+
+// loadBootstraps loads the persisted replicate models of the bootstrap method
+func (f *Forecaster) loadBootstraps() error {
+	f.bootstraps = nil
+	for i, model := range f.opt.UncertaintyOptions.BootstrapModels {
+		bootstrap, err := forecast.NewFromModel(model)
+		if err != nil {
+			return fmt.Errorf("unable to load bootstrap %d, %w", i, err)
+		}
+		f.bootstraps = append(f.bootstraps, bootstrap)
+	}
+	return nil
+}
+
+// bootstrapBands returns the distance of the upper and lower bootstrap bands from the forecast. Every
+// replicate is predicted and perturbed with a training residual drawn with the bootstrap seed so the
+// bands are the empirical quantiles of the simulated observations at each time.
+func (f *Forecaster) bootstrapBands(t []time.Time, seriesRes []float64, x forecast.Regressors) ([]float64, []float64, error) {
+	u := f.opt.UncertaintyOptions
+	samples := make([][]float64, len(t))
+	r := rand.New(rand.NewSource(u.BootstrapSeed))
+	for i, bootstrap := range f.bootstraps {
+		pred, _, err := bootstrap.PredictWithRegressors(t, x)
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to predict bootstrap %d, %w", i, err)
+		}
+		for j, p := range pred {
+			if len(u.BootstrapResiduals) > 0 {
+				p += u.BootstrapResiduals[r.Intn(len(u.BootstrapResiduals))]
+			}
+			if !math.IsNaN(p) {
+				samples[j] = append(samples[j], p)
+			}
+		}
+	}
+
+	lowerQ, upperQ, err := u.bandQuantiles()
+	if err != nil {
+		return nil, nil, err
+	}
+	upper := make([]float64, len(t))
+	lower := make([]float64, len(t))
+	for j, s := range samples {
+		if len(s) == 0 || math.IsNaN(seriesRes[j]) {
+			upper[j] = math.NaN()
+			lower[j] = math.NaN()
+			continue
+		}
+		sort.Float64s(s)
+		upper[j] = math.Max(stat.Quantile(upperQ, stat.Empirical, s, nil)-seriesRes[j], 0)
+		lower[j] = math.Max(seriesRes[j]-stat.Quantile(lowerQ, stat.Empirical, s, nil), 0)
+	}
+	return upper, lower, nil
+}
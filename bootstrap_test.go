@@ -0,0 +1,106 @@
+package forecaster
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/forecast/options"
+	"github.com/aouyang1/go-forecaster/timedataset"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFitBootstrapUncertainty(t *testing.T) {
+	n := 4 * 24
+	tWin := timedataset.GenerateT(n, time.Hour, func() time.Time {
+		return time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC)
+	})
+	rng := rand.New(rand.NewSource(5))
+	y := make([]float64, n)
+	for i := range y {
+		y[i] = 10.0 + 0.05*float64(i) + rng.NormFloat64()
+	}
+
+	testData := map[string]struct {
+		method     UncertaintyMethod
+		bootstraps int
+		err        error
+	}{
+		"rolling std":         {},
+		"bootstrap":           {method: UncertaintyMethodBootstrap, bootstraps: 30},
+		"unknown method":      {method: "jackknife", err: ErrUnknownUncertaintyMethod},
+		"negative bootstraps": {method: UncertaintyMethodBootstrap, bootstraps: -1, err: ErrNegativeBootstraps},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			opt := &Options{
+				SeriesOptions: &SeriesOptions{
+					ForecastOptions: &options.Options{
+						ChangepointOptions: options.ChangepointOptions{
+							Changepoints: []options.Changepoint{
+								options.NewChangepoint("trendstart", tWin[0]),
+							},
+							EnableGrowth: true,
+						},
+						Regularization: []float64{0.0},
+					},
+				},
+				UncertaintyOptions: &UncertaintyOptions{
+					ForecastOptions: &options.Options{},
+					ResidualWindow:  12,
+					ResidualZscore:  2.0,
+					Method:          td.method,
+					Bootstraps:      td.bootstraps,
+					BootstrapSeed:   1,
+				},
+			}
+			f, err := New(opt)
+			require.Nil(t, err)
+			err = f.Fit(tWin, y)
+			if td.err != nil {
+				assert.ErrorIs(t, err, td.err)
+				return
+			}
+			require.Nil(t, err)
+
+			u := opt.UncertaintyOptions
+			if td.method != UncertaintyMethodBootstrap {
+				assert.Nil(t, u.BootstrapModels)
+				assert.Nil(t, u.BootstrapResiduals)
+				return
+			}
+			require.Len(t, u.BootstrapModels, td.bootstraps)
+			assert.Len(t, u.BootstrapResiduals, n)
+
+			// the empirical bands cover about the central probability of two standard deviations
+			res := f.FitResults()
+			var covered int
+			for i := range y {
+				assert.Greater(t, res.Upper[i], res.Forecast[i])
+				assert.Less(t, res.Lower[i], res.Forecast[i])
+				if y[i] >= res.Lower[i] && y[i] <= res.Upper[i] {
+					covered++
+				}
+			}
+			assert.InDelta(t, 0.95, float64(covered)/float64(n), 0.07)
+
+			// the replicates are persisted with the model
+			model, err := f.Model()
+			require.Nil(t, err)
+			loaded, err := NewFromModel(model)
+			require.Nil(t, err)
+			horizon := []time.Time{tWin[n-1].Add(time.Hour), tWin[n-1].Add(7 * 24 * time.Hour)}
+			expected, err := f.Predict(horizon)
+			require.Nil(t, err)
+			loadedRes, err := loaded.Predict(horizon)
+			require.Nil(t, err)
+			assert.InDeltaSlice(t, expected.Upper, loadedRes.Upper, 1e-9)
+			assert.InDeltaSlice(t, expected.Lower, loadedRes.Lower, 1e-9)
+
+			// the spread of the replicate trends widens the bands with the horizon
+			assert.Less(t, expected.Upper[0]-expected.Lower[0], expected.Upper[1]-expected.Lower[1])
+		})
+	}
+}
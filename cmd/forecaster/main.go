@@ -0,0 +1,235 @@
+// Command forecaster trains a forecaster on a CSV time series and writes the model along with a
+// forecast CSV so that the package can be used without writing Go.
+//
+//	forecaster -input data.csv -options options.yaml -model model.json -horizon 24 -output forecast.csv
+//
+// The input CSV needs a header row with a time and a value column. Options are read from a JSON or
+// YAML file with the same field names as the serialized forecaster options and are merged over the
+// default options. The forecast CSV has the columns time, forecast, upper, and lower for the horizon
+// after the training data or for the training data itself if no horizon is given.
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	forecaster "github.com/aouyang1/go-forecaster"
+	"github.com/aouyang1/go-forecaster/errs"
+	"github.com/aouyang1/go-forecaster/timedataset"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	ErrNoInput            = errs.NewConfigError(errs.CodeMissingOption, "no input csv provided", nil)
+	ErrNegativeHorizon    = errs.NewConfigError(errs.CodeInvalidOption, "horizon must be non-negative", nil)
+	ErrUnknownOptionsType = errs.NewConfigError(errs.CodeInvalidOption, "options file must be .json, .yaml, or .yml", nil)
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+type config struct {
+	input    string
+	timeCol  string
+	valueCol string
+	layout   string
+	options  string
+	model    string
+	output   string
+	plot     string
+	horizon  int
+	freq     time.Duration
+}
+
+func parseFlags(args []string) (config, error) {
+	var cfg config
+	fs := flag.NewFlagSet("forecaster", flag.ContinueOnError)
+	fs.StringVar(&cfg.input, "input", "", "path of the input csv or - for stdin")
+	fs.StringVar(&cfg.timeCol, "time-col", "time", "name of the time column")
+	fs.StringVar(&cfg.valueCol, "value-col", "value", "name of the value column")
+	fs.StringVar(&cfg.layout, "layout", time.RFC3339, "time layout of the time column, unix, or unix_ms")
+	fs.StringVar(&cfg.options, "options", "", "path of a json or yaml options file")
+	fs.StringVar(&cfg.model, "model", "", "path to write the model json")
+	fs.StringVar(&cfg.output, "output", "-", "path to write the forecast csv or - for stdout")
+	fs.StringVar(&cfg.plot, "plot", "", "path to write the html plot of the fit")
+	fs.IntVar(&cfg.horizon, "horizon", 0, "number of periods to forecast after the training data")
+	fs.DurationVar(&cfg.freq, "freq", 0, "interval of the forecast periods which is inferred if unset")
+	if err := fs.Parse(args); err != nil {
+		return config{}, err
+	}
+	if cfg.input == "" {
+		return config{}, ErrNoInput
+	}
+	if cfg.horizon < 0 {
+		return config{}, fmt.Errorf("horizon %d, %w", cfg.horizon, ErrNegativeHorizon)
+	}
+	return cfg, nil
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	cfg, err := parseFlags(args)
+	if err != nil {
+		return err
+	}
+
+	opt, err := loadOptions(cfg.options)
+	if err != nil {
+		return err
+	}
+	td, err := loadInput(cfg, stdin)
+	if err != nil {
+		return err
+	}
+
+	f, err := forecaster.New(opt)
+	if err != nil {
+		return err
+	}
+	if err := f.Fit(td.T, td.Y); err != nil {
+		return fmt.Errorf("unable to fit, %w", err)
+	}
+
+	if cfg.model != "" {
+		if err := writeModel(f, cfg.model); err != nil {
+			return err
+		}
+	}
+	if cfg.plot != "" {
+		if err := writePlot(f, cfg.plot, cfg.horizon, cfg.freq); err != nil {
+			return err
+		}
+	}
+
+	res := f.FitResults()
+	if cfg.horizon > 0 {
+		horizon, err := f.MakeFuturePeriods(cfg.horizon, cfg.freq)
+		if err != nil {
+			return fmt.Errorf("unable to make future periods, %w", err)
+		}
+		if res, err = f.Predict(horizon); err != nil {
+			return fmt.Errorf("unable to predict, %w", err)
+		}
+	}
+
+	if cfg.output == "-" {
+		return writeResults(stdout, res)
+	}
+	out, err := os.Create(cfg.output)
+	if err != nil {
+		return err
+	}
+	if err := writeResults(out, res); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// loadOptions reads the json or yaml options file over the default options
+func loadOptions(path string) (*forecaster.Options, error) {
+	opt := forecaster.NewDefaultOptions()
+	if path == "" {
+		return opt, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+	case ".yaml", ".yml":
+		// yaml is converted to json so that the options keep a single set of field names
+		var doc any
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("unable to parse options yaml, %w", err)
+		}
+		if data, err = json.Marshal(doc); err != nil {
+			return nil, fmt.Errorf("unable to convert options yaml, %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("%q, %w", path, ErrUnknownOptionsType)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(opt); err != nil {
+		return nil, fmt.Errorf("unable to parse options, %w", err)
+	}
+	return opt, nil
+}
+
+func loadInput(cfg config, stdin io.Reader) (*timedataset.TimeDataset, error) {
+	r := stdin
+	if cfg.input != "-" {
+		in, err := os.Open(cfg.input)
+		if err != nil {
+			return nil, err
+		}
+		defer in.Close()
+		r = in
+	}
+	td, err := timedataset.FromCSV(r, cfg.timeCol, cfg.valueCol, cfg.layout)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load input, %w", err)
+	}
+	return td, nil
+}
+
+func writeModel(f *forecaster.Forecaster, path string) error {
+	model, err := f.Model()
+	if err != nil {
+		return err
+	}
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := model.JSONPrettyPrint(out); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// writeResults writes the results as csv leaving NaN values empty
+func writeResults(w io.Writer, res *forecaster.Results) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"time", "forecast", "upper", "lower"}); err != nil {
+		return err
+	}
+	for i, t := range res.T {
+		record := []string{
+			t.Format(time.RFC3339Nano),
+			formatValue(res.Forecast[i]),
+			formatValue(res.Upper[i]),
+			formatValue(res.Lower[i]),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func formatValue(v float64) string {
+	if math.IsNaN(v) {
+		return ""
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	forecaster "github.com/aouyang1/go-forecaster"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testOptionsYAML = `
+series_options:
+  forecast_options:
+    seasonality_options:
+      seasonality_configs:
+        - name: daily
+          orders: 2
+          period: 86400000000000
+uncertainty_options:
+  residual_window: 24
+  forecast_options:
+    seasonality_options:
+      seasonality_configs:
+        - name: daily
+          orders: 2
+          period: 86400000000000
+`
+
+func writeTestInput(t *testing.T, dir string) string {
+	var buf bytes.Buffer
+	buf.WriteString("time,value\n")
+	start := time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC)
+	rng := rand.New(rand.NewSource(4))
+	for i := 0; i < 7*24; i++ {
+		v := 10 + 3*math.Sin(2*math.Pi*float64(i)/24) + 0.1*rng.NormFloat64()
+		fmt.Fprintf(&buf, "%s,%f\n", start.Add(time.Duration(i)*time.Hour).Format(time.RFC3339), v)
+	}
+	path := filepath.Join(dir, "input.csv")
+	require.Nil(t, os.WriteFile(path, buf.Bytes(), 0o644))
+	return path
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	input := writeTestInput(t, dir)
+	optionsPath := filepath.Join(dir, "options.yaml")
+	require.Nil(t, os.WriteFile(optionsPath, []byte(testOptionsYAML), 0o644))
+	modelPath := filepath.Join(dir, "model.json")
+
+	var stdout bytes.Buffer
+	err := run([]string{
+		"-input", input,
+		"-options", optionsPath,
+		"-model", modelPath,
+		"-horizon", "24",
+	}, nil, &stdout)
+	require.Nil(t, err)
+
+	records, err := csv.NewReader(&stdout).ReadAll()
+	require.Nil(t, err)
+	require.Len(t, records, 25)
+	assert.Equal(t, []string{"time", "forecast", "upper", "lower"}, records[0])
+	assert.Equal(t, "2024-01-15T00:00:00Z", records[1][0])
+
+	// the written model loads and uses the options from the file
+	data, err := os.ReadFile(modelPath)
+	require.Nil(t, err)
+	var model forecaster.Model
+	require.Nil(t, json.Unmarshal(data, &model))
+	require.Len(t, model.Options.SeriesOptions.ForecastOptions.SeasonalityOptions.SeasonalityConfigs, 1)
+	assert.Equal(t, 24, model.Options.UncertaintyOptions.ResidualWindow)
+	f, err := forecaster.NewFromModel(model)
+	require.Nil(t, err)
+	ts, err := time.Parse(time.RFC3339, records[1][0])
+	require.Nil(t, err)
+	res, err := f.Predict([]time.Time{ts})
+	require.Nil(t, err)
+	assert.Equal(t, formatValue(res.Forecast[0]), records[1][1])
+}
+
+func TestRunErrors(t *testing.T) {
+	dir := t.TempDir()
+	input := writeTestInput(t, dir)
+	badOptions := filepath.Join(dir, "options.toml")
+	require.Nil(t, os.WriteFile(badOptions, []byte(""), 0o644))
+	unknownField := filepath.Join(dir, "options.json")
+	require.Nil(t, os.WriteFile(unknownField, []byte(`{"not_an_option": 1}`), 0o644))
+
+	testData := map[string]struct {
+		args     []string
+		err      error
+		contains string
+	}{
+		"no input": {
+			err: ErrNoInput,
+		},
+		"negative horizon": {
+			args: []string{"-input", input, "-horizon", "-1"},
+			err:  ErrNegativeHorizon,
+		},
+		"options type": {
+			args: []string{"-input", input, "-options", badOptions},
+			err:  ErrUnknownOptionsType,
+		},
+		"unknown option": {
+			args:     []string{"-input", input, "-options", unknownField},
+			contains: "not_an_option",
+		},
+		"missing column": {
+			args:     []string{"-input", input, "-value-col", "y"},
+			contains: "value column",
+		},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			err := run(td.args, strings.NewReader(""), &bytes.Buffer{})
+			require.NotNil(t, err)
+			if td.err != nil {
+				assert.ErrorIs(t, err, td.err)
+			}
+			assert.Contains(t, err.Error(), td.contains)
+		})
+	}
+}
@@ -0,0 +1,16 @@
+//go:build noplot
+
+package main
+
+import (
+	"time"
+
+	forecaster "github.com/aouyang1/go-forecaster"
+	"github.com/aouyang1/go-forecaster/errs"
+)
+
+var ErrPlotUnsupported = errs.NewConfigError(errs.CodeInvalidOption, "plotting is not supported in noplot builds", nil)
+
+func writePlot(f *forecaster.Forecaster, path string, horizon int, freq time.Duration) error {
+	return ErrPlotUnsupported
+}
@@ -0,0 +1,27 @@
+//go:build !noplot
+
+package main
+
+import (
+	"os"
+	"time"
+
+	forecaster "github.com/aouyang1/go-forecaster"
+)
+
+// writePlot writes the html plot of the fit extended over the forecast horizon
+func writePlot(f *forecaster.Forecaster, path string, horizon int, freq time.Duration) error {
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	opt := &forecaster.PlotOpts{
+		HorizonCnt:      horizon,
+		HorizonInterval: freq,
+	}
+	if err := f.PlotFit(out, opt); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
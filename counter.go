@@ -0,0 +1,29 @@
+package forecaster
+
+import (
+	"time"
+
+	"github.com/aouyang1/go-forecaster/timedataset"
+)
+
+// DefaultRateUnit is the unit of time of counter rates if none is configured
+const DefaultRateUnit = time.Second
+
+// CounterOptions converts a monotonically increasing counter, such as a raw Prometheus counter, to
+// its rate of increase per Unit before fitting. A decrease is treated as a counter reset. The model
+// is fit on the derived rate so the forecast, bands, and fit results are all rates and the
+// uncertainty models the noise of the rate between consecutive samples rather than of the counter
+// itself. Rates are never negative so the forecast and bands are clipped at zero. Any transform,
+// MinValue, and MaxValue apply to the rate.
+type CounterOptions struct {
+	Unit time.Duration `json:"unit,omitempty"`
+}
+
+// rate converts the counter of the training dataset to a rate per unit
+func (c *CounterOptions) rate(td *timedataset.TimeDataset) (*timedataset.TimeDataset, error) {
+	unit := c.Unit
+	if unit == 0 {
+		unit = DefaultRateUnit
+	}
+	return td.CounterRate(unit)
+}
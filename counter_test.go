@@ -0,0 +1,85 @@
+package forecaster
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/forecast/options"
+	"github.com/aouyang1/go-forecaster/timedataset"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFitCounter(t *testing.T) {
+	// counter increasing at a daily seasonal rate per second that resets midway through training
+	n := 7 * 24 * 4
+	interval := 15 * time.Minute
+	tWin := timedataset.GenerateT(n, interval, func() time.Time {
+		return time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC)
+	})
+	rate := func(tPnt time.Time) float64 {
+		return 5 + 2*math.Sin(2*math.Pi*float64(tPnt.Hour()*60+tPnt.Minute())/1440)
+	}
+	rng := rand.New(rand.NewSource(3))
+	y := make([]float64, n)
+	var counter float64
+	for i, tPnt := range tWin {
+		if i == n/2 {
+			counter = 0
+		}
+		counter += (rate(tPnt) + 0.1*rng.NormFloat64()) * interval.Seconds()
+		y[i] = counter
+	}
+
+	testData := map[string]struct {
+		unit  time.Duration
+		scale float64
+		err   error
+	}{
+		"default unit":  {scale: 1},
+		"per minute":    {unit: time.Minute, scale: 60},
+		"negative unit": {unit: -time.Second, err: timedataset.ErrInvalidRateUnit},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			opt := &Options{
+				SeriesOptions: &SeriesOptions{
+					ForecastOptions: &options.Options{
+						SeasonalityOptions: options.SeasonalityOptions{
+							SeasonalityConfigs: []options.SeasonalityConfig{options.NewDailySeasonalityConfig(2)},
+						},
+					},
+				},
+				UncertaintyOptions: &UncertaintyOptions{
+					ForecastOptions: &options.Options{},
+					ResidualWindow:  24,
+					ResidualZscore:  2.0,
+				},
+				Counter: &CounterOptions{Unit: td.unit},
+			}
+			f, err := New(opt)
+			require.Nil(t, err)
+			err = f.Fit(tWin, y)
+			if td.err != nil {
+				assert.ErrorIs(t, err, td.err)
+				return
+			}
+			require.Nil(t, err)
+
+			// the fit is on the rate so the counter reset does not appear as an outlier or level shift
+			horizon := timedataset.GenerateT(24*4, interval, func() time.Time {
+				return tWin[n-1].Add(interval)
+			})
+			res, err := f.Predict(horizon)
+			require.Nil(t, err)
+			for i, tPnt := range horizon {
+				assert.InDelta(t, td.scale*rate(tPnt), res.Forecast[i], td.scale*0.2)
+				assert.GreaterOrEqual(t, res.Lower[i], 0.0)
+				assert.Greater(t, res.Upper[i], res.Forecast[i])
+			}
+		})
+	}
+}
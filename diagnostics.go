@@ -0,0 +1,43 @@
+package forecaster
+
+import (
+	"github.com/aouyang1/go-forecaster/forecast"
+	"github.com/aouyang1/go-forecaster/forecast/options"
+)
+
+// Diagnostics captures auxiliary information generated while fitting the forecaster. This is
+// useful when reviewing a fit but is not required for inference.
+type Diagnostics struct {
+	// ChangepointSensitivity reports the fit quality when shifting each selected auto changepoint
+	// of the series model. Only populated if changepoint sensitivity samples are configured.
+	ChangepointSensitivity []forecast.ChangepointSensitivity `json:"changepoint_sensitivity"`
+
+	// OutliersRemoved is the number of training points removed as outliers across all passes
+	OutliersRemoved int `json:"outliers_removed"`
+
+	// OutlierPasses is the number of outlier passes that removed points
+	OutlierPasses int `json:"outlier_passes"`
+
+	// OutlierRemovalHalted is set if outlier removal stopped early since removing more points would leave
+	// less than the configured minimum fraction of the training data
+	OutlierRemovalHalted bool `json:"outlier_removal_halted"`
+
+	// OutlierIndexes are the indexes of the training points removed as outliers in ascending order
+	OutlierIndexes []int `json:"outlier_indexes"`
+
+	// MissingIndexes are the indexes of the training points missing from the input whose fitted values
+	// are imputed by the model
+	MissingIndexes []int `json:"missing_indexes"`
+
+	// ImputedIndexes are the indexes of the training points missing from the input that were filled by
+	// the imputation options before fitting
+	ImputedIndexes []int `json:"imputed_indexes,omitempty"`
+
+	// ExcludedIndexes are the indexes of the trailing training points excluded from the fit by the
+	// ExcludeRecent option
+	ExcludedIndexes []int `json:"excluded_indexes"`
+
+	// RedundantFeatures lists the constant and near duplicate features dropped from the series model
+	// before fitting. Only populated if redundancy detection is enabled.
+	RedundantFeatures []options.RedundantFeature `json:"redundant_features"`
+}
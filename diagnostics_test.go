@@ -0,0 +1,90 @@
+package forecaster
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/forecast/options"
+	"github.com/aouyang1/go-forecaster/timedataset"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFitDiagnosticsOutlierGuard(t *testing.T) {
+	n := 40
+	tWin := timedataset.GenerateT(n, time.Minute, time.Now)
+	rng := rand.New(rand.NewSource(1))
+	y := make([]float64, n)
+	for i := range y {
+		y[i] = rng.NormFloat64()
+	}
+
+	testData := map[string]struct {
+		minRemaining float64
+		halted       bool
+	}{
+		"no guard":     {0.0, false},
+		"scarce guard": {0.7, true},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			opt := NewDefaultOptions()
+			opt.SeriesOptions.ForecastOptions = &options.Options{}
+			opt.SeriesOptions.OutlierOptions = &OutlierOptions{
+				NumPasses:            3,
+				LowerPercentile:      0.1,
+				UpperPercentile:      0.9,
+				TukeyFactor:          0.0,
+				MinRemainingFraction: td.minRemaining,
+			}
+			opt.UncertaintyOptions.ForecastOptions = &options.Options{}
+
+			f, err := New(opt)
+			require.Nil(t, err)
+
+			yCopy := make([]float64, n)
+			copy(yCopy, y)
+			require.Nil(t, f.Fit(tWin, yCopy))
+
+			diag := f.FitDiagnostics()
+			assert.Equal(t, td.halted, diag.OutlierRemovalHalted)
+			assert.Greater(t, diag.OutliersRemoved, 0)
+			assert.GreaterOrEqual(t, float64(n-diag.OutliersRemoved), td.minRemaining*float64(n))
+			assert.Len(t, diag.OutlierIndexes, diag.OutliersRemoved)
+			assert.IsNonDecreasing(t, diag.OutlierIndexes)
+			if td.halted {
+				assert.Less(t, diag.OutlierPasses, 3)
+			}
+		})
+	}
+}
+
+func TestFitDiagnosticsAnnotations(t *testing.T) {
+	n := 48
+	tWin := timedataset.GenerateT(n, time.Hour, func() time.Time {
+		return time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)
+	})
+	y := timedataset.GenerateConstY(n, 5.0)
+	y[3] = math.NaN()
+	y[10] = math.NaN()
+
+	opt := NewDefaultOptions()
+	opt.SeriesOptions.ForecastOptions = &options.Options{}
+	opt.SeriesOptions.OutlierOptions = nil
+	opt.UncertaintyOptions.ForecastOptions = &options.Options{}
+	opt.ExcludeRecent = 2 * time.Hour
+
+	f, err := New(opt)
+	require.Nil(t, err)
+	require.Nil(t, f.Fit(tWin, y))
+
+	diag := f.FitDiagnostics()
+	assert.Equal(t, []int{3, 10}, diag.MissingIndexes)
+	assert.Equal(t, []int{46, 47}, diag.ExcludedIndexes)
+	assert.Empty(t, diag.OutlierIndexes)
+
+	assertPlotFitContains(t, f, "Imputed", "Excluded")
+}
@@ -0,0 +1,222 @@
+package forecaster
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/errs"
+	"github.com/aouyang1/go-forecaster/forecast"
+)
+
+var (
+	ErrNoEnsembleMembers      = errs.NewConfigError(errs.CodeMissingOption, "no ensemble members", nil)
+	ErrMismatchedMemberWeight = errs.NewConfigError(errs.CodeLengthMismatch, "number of ensemble weights does not match number of members", nil)
+	ErrInvalidEnsembleWeight  = errs.NewConfigError(errs.CodeInvalidOption, "ensemble weights must be non-negative, finite, and not all zero", nil)
+)
+
+// Ensemble blends the predictions of several forecasters with fixed weights
+type Ensemble struct {
+	members []*Forecaster
+	weights []float64
+}
+
+// EnsembleMember is a serializeable member model of an ensemble along with its blending weight
+type EnsembleMember struct {
+	Weight float64 `json:"weight"`
+	Model  Model   `json:"model"`
+}
+
+// EnsembleModel is a serializeable representation of an ensemble so that blended models can be stored
+// and loaded exactly like a single forecaster model
+type EnsembleModel struct {
+	Members []EnsembleMember `json:"members"`
+}
+
+// JSONPrettyPrint writes the ensemble model as indented JSON
+func (m EnsembleModel) JSONPrettyPrint(w io.Writer) error {
+	out, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(w, string(out))
+	return err
+}
+
+// normalizeWeights validates the blending weights and scales them to sum to 1.0
+func normalizeWeights(weights []float64) ([]float64, error) {
+	var total float64
+	for i, w := range weights {
+		if w < 0 || math.IsNaN(w) || math.IsInf(w, 0) {
+			return nil, fmt.Errorf("weight of %.3f for member %d, %w", w, i, ErrInvalidEnsembleWeight)
+		}
+		total += w
+	}
+	if total == 0 {
+		return nil, ErrInvalidEnsembleWeight
+	}
+	res := make([]float64, len(weights))
+	for i, w := range weights {
+		res[i] = w / total
+	}
+	return res, nil
+}
+
+// NewEnsemble creates an ensemble of trained forecasters blended by the input weights which are
+// normalized to sum to 1.0
+func NewEnsemble(members []*Forecaster, weights []float64) (*Ensemble, error) {
+	if len(members) == 0 {
+		return nil, ErrNoEnsembleMembers
+	}
+	if len(weights) != len(members) {
+		return nil, fmt.Errorf("got %d weights for %d members, %w", len(weights), len(members), ErrMismatchedMemberWeight)
+	}
+	normWeights, err := normalizeWeights(weights)
+	if err != nil {
+		return nil, err
+	}
+	return &Ensemble{
+		members: members,
+		weights: normWeights,
+	}, nil
+}
+
+// NewFromEnsembleModel creates a new ensemble from a pre-existing ensemble model. This should be
+// generated from a previous ensemble call to Model().
+func NewFromEnsembleModel(model EnsembleModel) (*Ensemble, error) {
+	if len(model.Members) == 0 {
+		return nil, ErrNoEnsembleMembers
+	}
+	members := make([]*Forecaster, 0, len(model.Members))
+	weights := make([]float64, 0, len(model.Members))
+	for i, member := range model.Members {
+		f, err := NewFromModel(member.Model)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load ensemble member %d, %w", i, err)
+		}
+		members = append(members, f)
+		weights = append(weights, member.Weight)
+	}
+	return NewEnsemble(members, weights)
+}
+
+// Model generates a serializeable representation of the ensemble members and their weights
+func (e *Ensemble) Model() (EnsembleModel, error) {
+	m := EnsembleModel{
+		Members: make([]EnsembleMember, 0, len(e.members)),
+	}
+	for i, f := range e.members {
+		fm, err := f.Model()
+		if err != nil {
+			return EnsembleModel{}, fmt.Errorf("unable to fetch model of ensemble member %d, %w", i, err)
+		}
+		m.Members = append(m.Members, EnsembleMember{
+			Weight: e.weights[i],
+			Model:  fm,
+		})
+	}
+	return m, nil
+}
+
+// Weights returns the normalized blending weight of each member
+func (e *Ensemble) Weights() []float64 {
+	res := make([]float64, len(e.weights))
+	copy(res, e.weights)
+	return res
+}
+
+// Predict blends the results of every member at the input times as the weighted sum of their
+// forecasts, bands, and components
+func (e *Ensemble) Predict(t []time.Time) (*Results, error) {
+	blended := &Results{
+		T:        t,
+		Forecast: make([]float64, len(t)),
+		Upper:    make([]float64, len(t)),
+		Lower:    make([]float64, len(t)),
+	}
+	nanReasons := make([][]string, len(t))
+	var hasReasons bool
+	for i, f := range e.members {
+		res, err := f.Predict(t)
+		if err != nil {
+			return nil, fmt.Errorf("unable to predict ensemble member %d, %w", i, err)
+		}
+		w := e.weights[i]
+		blendSeries(blended.Forecast, res.Forecast, w)
+		blendSeries(blended.Upper, res.Upper, w)
+		blendSeries(blended.Lower, res.Lower, w)
+		blended.SeriesComponents = blendComponents(blended.SeriesComponents, res.SeriesComponents, w, len(t))
+		blended.UncertaintyComponents = blendComponents(blended.UncertaintyComponents, res.UncertaintyComponents, w, len(t))
+
+		blended.MissingFeatures = mergeMissingFeatures(blended.MissingFeatures, res.MissingFeatures)
+
+		for j, reason := range res.NaNReasons {
+			if reason == "" {
+				continue
+			}
+			nanReasons[j] = append(nanReasons[j], fmt.Sprintf("member %d %s", i, reason))
+			hasReasons = true
+		}
+	}
+	if hasReasons {
+		blended.NaNReasons = make([]string, len(t))
+		for j, reasons := range nanReasons {
+			blended.NaNReasons[j] = strings.Join(reasons, "; ")
+		}
+	}
+	return blended, nil
+}
+
+// blendSeries adds the weighted source series to the destination
+func blendSeries(dst, src []float64, w float64) {
+	for i := range dst {
+		if i < len(src) {
+			dst[i] += w * src[i]
+		}
+	}
+}
+
+// blendComponents adds the weighted source components to the destination components allocating any
+// missing destination component
+func blendComponents(dst, src forecast.Components, w float64, n int) forecast.Components {
+	blend := func(dstComp, srcComp []float64) []float64 {
+		if srcComp == nil {
+			return dstComp
+		}
+		if dstComp == nil {
+			dstComp = make([]float64, n)
+		}
+		blendSeries(dstComp, srcComp, w)
+		return dstComp
+	}
+	dst.Trend = blend(dst.Trend, src.Trend)
+	dst.Seasonality = blend(dst.Seasonality, src.Seasonality)
+	dst.Event = blend(dst.Event, src.Event)
+	dst.Custom = blend(dst.Custom, src.Custom)
+	dst.Regressor = blend(dst.Regressor, src.Regressor)
+
+	// per feature components are matched by type and name since members may select different features
+	for _, srcFeat := range src.Features {
+		idx := slices.IndexFunc(dst.Features, func(fc forecast.FeatureComponent) bool {
+			return fc.Type == srcFeat.Type && fc.Name == srcFeat.Name
+		})
+		if idx == -1 {
+			dst.Features = append(dst.Features, forecast.FeatureComponent{Type: srcFeat.Type, Name: srcFeat.Name})
+			idx = len(dst.Features) - 1
+		}
+		dst.Features[idx].Values = blend(dst.Features[idx].Values, srcFeat.Values)
+	}
+	sort.Slice(dst.Features, func(i, j int) bool {
+		if dst.Features[i].Type != dst.Features[j].Type {
+			return dst.Features[i].Type < dst.Features[j].Type
+		}
+		return dst.Features[i].Name < dst.Features[j].Name
+	})
+	return dst
+}
@@ -0,0 +1,109 @@
+package forecaster
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/forecast/options"
+	"github.com/aouyang1/go-forecaster/timedataset"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnsemble(t *testing.T) {
+	n := 4 * 24 * 6
+	tWin := timedataset.GenerateT(n, 10*time.Minute, func() time.Time {
+		return time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC)
+	})
+	y := timedataset.GenerateConstY(n, 5.0).
+		Add(timedataset.GenerateWaveY(tWin, 2.0, 86400.0, 1.0, 0.0)).
+		Add(timedataset.GenerateWaveY(tWin, 0.5, 86400.0, 2.0, 0.0))
+
+	newMember := func(orders int) *Forecaster {
+		opt := NewDefaultOptions()
+		opt.SeriesOptions.ForecastOptions.SeasonalityOptions.SeasonalityConfigs = []options.SeasonalityConfig{
+			options.NewDailySeasonalityConfig(orders),
+		}
+		opt.SeriesOptions.ForecastOptions.ChangepointOptions.Auto = false
+		opt.UncertaintyOptions.ForecastOptions.ChangepointOptions.Auto = false
+		f, err := New(opt)
+		require.Nil(t, err)
+		require.Nil(t, f.Fit(tWin, append([]float64(nil), y...)))
+		return f
+	}
+	members := []*Forecaster{newMember(1), newMember(2)}
+
+	e, err := NewEnsemble(members, []float64{1.0, 3.0})
+	require.Nil(t, err)
+	assert.Equal(t, []float64{0.25, 0.75}, e.Weights())
+
+	tPred := tWin[:48]
+	res, err := e.Predict(tPred)
+	require.Nil(t, err)
+	for i, f := range members {
+		memberRes, err := f.Predict(tPred)
+		require.Nil(t, err)
+		for j := range tPred {
+			res.Forecast[j] -= e.weights[i] * memberRes.Forecast[j]
+			res.SeriesComponents.Seasonality[j] -= e.weights[i] * memberRes.SeriesComponents.Seasonality[j]
+		}
+		for _, fc := range memberRes.SeriesComponents.Features {
+			blended := res.SeriesComponents.Feature(fc.Type, fc.Name)
+			require.NotNil(t, blended)
+			for j := range tPred {
+				blended[j] -= e.weights[i] * fc.Values[j]
+			}
+		}
+	}
+	assert.InDeltaSlice(t, make([]float64, len(tPred)), res.Forecast, 1e-9)
+	assert.InDeltaSlice(t, make([]float64, len(tPred)), res.SeriesComponents.Seasonality, 1e-9)
+	require.NotEmpty(t, res.SeriesComponents.Features)
+	for _, fc := range res.SeriesComponents.Features {
+		assert.InDeltaSlice(t, make([]float64, len(tPred)), fc.Values, 1e-9)
+	}
+
+	// serialized ensembles load and predict exactly like the original
+	model, err := e.Model()
+	require.Nil(t, err)
+	var buf bytes.Buffer
+	require.Nil(t, model.JSONPrettyPrint(&buf))
+	var loadedModel EnsembleModel
+	require.Nil(t, json.Unmarshal(buf.Bytes(), &loadedModel))
+	loaded, err := NewFromEnsembleModel(loadedModel)
+	require.Nil(t, err)
+
+	expected, err := e.Predict(tPred)
+	require.Nil(t, err)
+	actual, err := loaded.Predict(tPred)
+	require.Nil(t, err)
+	assert.InDeltaSlice(t, expected.Forecast, actual.Forecast, 1e-9)
+	assert.InDeltaSlice(t, expected.Upper, actual.Upper, 1e-9)
+	assert.InDeltaSlice(t, expected.Lower, actual.Lower, 1e-9)
+}
+
+func TestNewEnsembleErrors(t *testing.T) {
+	f, err := New(nil)
+	require.Nil(t, err)
+
+	testData := map[string]struct {
+		members []*Forecaster
+		weights []float64
+		err     error
+	}{
+		"no members":        {err: ErrNoEnsembleMembers},
+		"mismatched weight": {members: []*Forecaster{f}, weights: []float64{1, 1}, err: ErrMismatchedMemberWeight},
+		"negative weight":   {members: []*Forecaster{f, f}, weights: []float64{1, -1}, err: ErrInvalidEnsembleWeight},
+		"zero weights":      {members: []*Forecaster{f, f}, weights: []float64{0, 0}, err: ErrInvalidEnsembleWeight},
+	}
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewEnsemble(td.members, td.weights)
+			assert.ErrorIs(t, err, td.err)
+		})
+	}
+
+	_, err = NewFromEnsembleModel(EnsembleModel{})
+	assert.ErrorIs(t, err, ErrNoEnsembleMembers)
+}
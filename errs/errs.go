@@ -0,0 +1,166 @@
+// Package errs defines the typed error taxonomy used across the forecaster packages. Every
+// error carries a machine readable Code and belongs to one of four kinds: configuration,
+// data, fit, or predict. Sentinel errors in the other packages are built from these types so
+// callers can keep using errors.Is while services can map failures with CodeOf and KindOf.
+package errs
+
+import (
+	"errors"
+)
+
+// Code is a machine readable identifier for a class of failure
+type Code string
+
+const (
+	CodeUnknown Code = "unknown"
+
+	// configuration codes
+	CodeInvalidOption  Code = "invalid_option"
+	CodeMissingOption  Code = "missing_option"
+	CodeInvalidEvent   Code = "invalid_event"
+	CodeUnknownFeature Code = "unknown_feature"
+
+	// data codes
+	CodeNoData             Code = "no_data"
+	CodeInsufficientData   Code = "insufficient_data"
+	CodeLengthMismatch     Code = "length_mismatch"
+	CodeNonMonotonic       Code = "non_monotonic"
+	CodeCannotInferFreq    Code = "cannot_infer_frequency"
+	CodeDimensionMismatch  Code = "dimension_mismatch"
+	CodeDuplicateLabel     Code = "duplicate_label"
+	CodeInvalidModel       Code = "invalid_model"
+	CodeNoModelCoefficient Code = "no_model_coefficients"
+	CodeNotFound           Code = "not_found"
+	CodeAlreadyExists      Code = "already_exists"
+	CodeInvalidValue       Code = "invalid_value"
+
+	// fit codes
+	CodeFitFailed Code = "fit_failed"
+
+	// predict codes
+	CodeUninitialized Code = "uninitialized"
+	CodeUntrained     Code = "untrained"
+	CodePredictFailed Code = "predict_failed"
+	CodeStaleModel    Code = "stale_model"
+)
+
+// Kind groups error codes by the stage of the pipeline that failed
+type Kind string
+
+const (
+	KindUnknown Kind = "unknown"
+	KindConfig  Kind = "config"
+	KindData    Kind = "data"
+	KindFit     Kind = "fit"
+	KindPredict Kind = "predict"
+)
+
+// Coder is implemented by all errors in the taxonomy
+type Coder interface {
+	error
+	ErrorCode() Code
+	ErrorKind() Kind
+}
+
+type codedError struct {
+	code Code
+	msg  string
+	err  error
+}
+
+// Error returns the message followed by the wrapped cause if present
+func (c *codedError) Error() string {
+	if c.err == nil {
+		return c.msg
+	}
+	if c.msg == "" {
+		return c.err.Error()
+	}
+	return c.msg + ", " + c.err.Error()
+}
+
+// Unwrap returns the wrapped cause
+func (c *codedError) Unwrap() error {
+	return c.err
+}
+
+// ErrorCode returns the machine readable code of the error
+func (c *codedError) ErrorCode() Code {
+	return c.code
+}
+
+// ConfigError represents invalid or missing configuration
+type ConfigError struct{ codedError }
+
+// ErrorKind returns KindConfig
+func (e *ConfigError) ErrorKind() Kind { return KindConfig }
+
+// DataError represents input data that cannot be used for fitting or inference
+type DataError struct{ codedError }
+
+// ErrorKind returns KindData
+func (e *DataError) ErrorKind() Kind { return KindData }
+
+// FitError represents a failure while training a model
+type FitError struct{ codedError }
+
+// ErrorKind returns KindFit
+func (e *FitError) ErrorKind() Kind { return KindFit }
+
+// PredictError represents a failure while running inference
+type PredictError struct{ codedError }
+
+// ErrorKind returns KindPredict
+func (e *PredictError) ErrorKind() Kind { return KindPredict }
+
+// NewConfigError creates a configuration error with an optional wrapped cause
+func NewConfigError(code Code, msg string, err error) *ConfigError {
+	return &ConfigError{codedError{code, msg, err}}
+}
+
+// NewDataError creates a data error with an optional wrapped cause
+func NewDataError(code Code, msg string, err error) *DataError {
+	return &DataError{codedError{code, msg, err}}
+}
+
+// NewFitError creates a fit error with an optional wrapped cause
+func NewFitError(code Code, msg string, err error) *FitError {
+	return &FitError{codedError{code, msg, err}}
+}
+
+// NewPredictError creates a predict error with an optional wrapped cause
+func NewPredictError(code Code, msg string, err error) *PredictError {
+	return &PredictError{codedError{code, msg, err}}
+}
+
+// CodeOf returns the most specific code found in the error chain. Stage level wrappers such as
+// a FitError around a DataError report the code of the inner DataError. Errors outside of the
+// taxonomy return CodeUnknown.
+func CodeOf(err error) Code {
+	c := deepest(err)
+	if c == nil {
+		return CodeUnknown
+	}
+	return c.ErrorCode()
+}
+
+// KindOf returns the kind of the most specific error found in the error chain. Errors outside
+// of the taxonomy return KindUnknown.
+func KindOf(err error) Kind {
+	c := deepest(err)
+	if c == nil {
+		return KindUnknown
+	}
+	return c.ErrorKind()
+}
+
+func deepest(err error) Coder {
+	var found Coder
+	for err != nil {
+		if c, ok := err.(Coder); ok {
+			found = c
+		}
+		err = errors.Unwrap(err)
+	}
+	return found
+}
@@ -0,0 +1,90 @@
+package errs
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCodeOf(t *testing.T) {
+	errNoData := NewDataError(CodeNoData, "no training data", nil)
+
+	testData := map[string]struct {
+		err          error
+		expectedCode Code
+		expectedKind Kind
+		expectedMsg  string
+	}{
+		"nil": {
+			err:          nil,
+			expectedCode: CodeUnknown,
+			expectedKind: KindUnknown,
+		},
+		"plain error": {
+			err:          errors.New("plain"),
+			expectedCode: CodeUnknown,
+			expectedKind: KindUnknown,
+			expectedMsg:  "plain",
+		},
+		"sentinel": {
+			err:          errNoData,
+			expectedCode: CodeNoData,
+			expectedKind: KindData,
+			expectedMsg:  "no training data",
+		},
+		"wrapped with fmt": {
+			err:          fmt.Errorf("unable to create dataset, %w", errNoData),
+			expectedCode: CodeNoData,
+			expectedKind: KindData,
+			expectedMsg:  "unable to create dataset, no training data",
+		},
+		"stage wrapper": {
+			err:          NewFitError(CodeFitFailed, "unable to fit", fmt.Errorf("step, %w", errNoData)),
+			expectedCode: CodeNoData,
+			expectedKind: KindData,
+			expectedMsg:  "unable to fit, step, no training data",
+		},
+		"stage wrapper with plain cause": {
+			err:          NewPredictError(CodePredictFailed, "unable to predict", errors.New("boom")),
+			expectedCode: CodePredictFailed,
+			expectedKind: KindPredict,
+			expectedMsg:  "unable to predict, boom",
+		},
+		"config": {
+			err:          NewConfigError(CodeInvalidOption, "negative lambda", nil),
+			expectedCode: CodeInvalidOption,
+			expectedKind: KindConfig,
+			expectedMsg:  "negative lambda",
+		},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, td.expectedCode, CodeOf(td.err))
+			assert.Equal(t, td.expectedKind, KindOf(td.err))
+			if td.err != nil {
+				assert.Equal(t, td.expectedMsg, td.err.Error())
+			}
+		})
+	}
+}
+
+func TestErrorsIsAs(t *testing.T) {
+	sentinel := NewDataError(CodeNoData, "no training data", nil)
+	wrapped := NewFitError(CodeFitFailed, "unable to fit", fmt.Errorf("step, %w", sentinel))
+
+	assert.True(t, errors.Is(wrapped, sentinel))
+
+	var dataErr *DataError
+	assert.True(t, errors.As(wrapped, &dataErr))
+	assert.Equal(t, CodeNoData, dataErr.ErrorCode())
+
+	var fitErr *FitError
+	assert.True(t, errors.As(wrapped, &fitErr))
+	assert.Equal(t, CodeFitFailed, fitErr.ErrorCode())
+
+	var predErr *PredictError
+	assert.False(t, errors.As(wrapped, &predErr))
+}
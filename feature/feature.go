@@ -7,10 +7,11 @@ const (
 	FeatureTypeSeasonality FeatureType = "seasonality"
 	FeatureTypeTime        FeatureType = "time"
 	FeatureTypeEvent       FeatureType = "event"
+	FeatureTypeRegressor   FeatureType = "regressor"
 )
 
 // Feature is an interface representing a type of feature e.g. changepoint,
-// seasonality, or time
+// seasonality, time, or an external regressor
 type Feature interface {
 	// String returns the string representation of the feature
 	String() string
@@ -0,0 +1,57 @@
+package feature
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Regressor feature representing a user supplied external regressor series e.g. temperature or price
+type Regressor struct {
+	Name string `json:"name"`
+}
+
+// NewRegressor creates a new regressor instance given a name
+func NewRegressor(name string) *Regressor {
+	return &Regressor{name}
+}
+
+// String returns the string representation of the regressor feature
+func (r Regressor) String() string {
+	return fmt.Sprintf("reg_%s", r.Name)
+}
+
+// Get returns the value of an arbitrary label and returns the value along with whether
+// the label exists
+func (r Regressor) Get(label string) (string, bool) {
+	switch strings.ToLower(label) {
+	case "name":
+		return r.Name, true
+	}
+	return "", false
+}
+
+// Type returns the type of this feature
+func (r Regressor) Type() FeatureType {
+	return FeatureTypeRegressor
+}
+
+// Decode converts the feature into a map of label values
+func (r Regressor) Decode() map[string]string {
+	res := make(map[string]string)
+	res["name"] = r.Name
+	return res
+}
+
+// UnmarshalJSON is the custom unmarshalling to convert a map[string]string
+// to a regressor feature
+func (r *Regressor) UnmarshalJSON(data []byte) error {
+	var labelStr struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(data, &labelStr); err != nil {
+		return err
+	}
+	r.Name = labelStr.Name
+	return nil
+}
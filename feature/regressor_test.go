@@ -0,0 +1,66 @@
+package feature
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegressorString(t *testing.T) {
+	feat := NewRegressor("blargh")
+	expected := "reg_blargh"
+	assert.Equal(t, expected, feat.String())
+}
+
+func TestRegressorGet(t *testing.T) {
+	feat := NewRegressor("blargh")
+
+	testData := map[string]struct {
+		label     string
+		expVal    string
+		expExists bool
+	}{
+		"unknown": {
+			label: "unknown",
+		},
+		"capitalized": {
+			label:     "NAME",
+			expVal:    "blargh",
+			expExists: true,
+		},
+		"exact match": {
+			label:     "name",
+			expVal:    "blargh",
+			expExists: true,
+		},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			val, exists := feat.Get(td.label)
+			assert.Equal(t, td.expExists, exists, "exists")
+			assert.Equal(t, td.expVal, val, "value")
+		})
+	}
+}
+
+func TestRegressorDecode(t *testing.T) {
+	feat := NewRegressor("blargh")
+	exp := map[string]string{
+		"name": "blargh",
+	}
+	assert.Equal(t, exp, feat.Decode())
+}
+
+func TestRegressorUnmarshalJSON(t *testing.T) {
+	feat := NewRegressor("blargh")
+	out, err := json.Marshal(feat.Decode())
+	require.NoError(t, err)
+
+	var nextFeat Regressor
+	require.NoError(t, json.Unmarshal(out, &nextFeat))
+
+	assert.Equal(t, feat, &nextFeat)
+}
@@ -3,6 +3,8 @@ package feature
 import (
 	"sort"
 
+	mat_ "github.com/aouyang1/go-forecaster/mat"
+
 	"gonum.org/v1/gonum/floats"
 	"gonum.org/v1/gonum/mat"
 )
@@ -190,6 +192,50 @@ func (s *Set) Matrix(intercept bool) *mat.Dense {
 	return mat.NewDense(m, n, obs)
 }
 
+// SparseMatrix returns a compressed sparse row representation of the FeatureSet with the same layout
+// as Matrix storing only the non zero observations. This uses far less memory than Matrix for sets
+// of mostly zero features such as event masks and changepoint biases.
+func (s *Set) SparseMatrix(intercept bool) *mat_.CSR {
+	if s == nil {
+		return nil
+	}
+
+	featureLabels := s.Labels()
+	if len(featureLabels) == 0 {
+		return nil
+	}
+
+	cols := make([][]float64, 0, len(featureLabels)+1)
+	if intercept {
+		ones := make([]float64, s.m)
+		floats.AddConst(1.0, ones)
+		cols = append(cols, ones)
+	}
+	for _, label := range featureLabels {
+		cols = append(cols, s.set[label.String()])
+	}
+
+	// every feature has an observation per row so the columns always match
+	x, _ := mat_.NewCSRFromColumns(s.m, cols)
+	return x
+}
+
+// Density returns the fraction of non zero observations across all features
+func (s *Set) Density() float64 {
+	if s == nil || s.m == 0 || len(s.set) == 0 {
+		return 0
+	}
+	var nnz int
+	for _, data := range s.set {
+		for _, v := range data {
+			if v != 0 {
+				nnz++
+			}
+		}
+	}
+	return float64(nnz) / float64(s.m*len(s.set))
+}
+
 // RemoveZeroOnlyFeatures scans through all features and removes any features with only zero values.
 // This is to prevent fitting issues.
 func (s *Set) RemoveZeroOnlyFeatures() {
@@ -201,3 +247,70 @@ func (s *Set) RemoveZeroOnlyFeatures() {
 		}
 	}
 }
+
+// filter returns a new set with a copy of the values of every feature satisfying keep
+func (s *Set) filter(keep func(f Feature) bool) *Set {
+	res := NewSet()
+	if s == nil {
+		return res
+	}
+	for _, f := range s.labels {
+		if !keep(f) {
+			continue
+		}
+		vals := make([]float64, s.m)
+		copy(vals, s.set[f.String()])
+		res.set[f.String()] = vals
+		res.labels = append(res.labels, f)
+	}
+	if res.Len() > 0 {
+		res.m = s.m
+	}
+	return res
+}
+
+// Intersect returns a new set with the features of this set that also exist in the other set. Values
+// are copied from this set.
+func (s *Set) Intersect(other *Set) *Set {
+	return s.filter(func(f Feature) bool {
+		if other == nil {
+			return false
+		}
+		_, exists := other.set[f.String()]
+		return exists
+	})
+}
+
+// Difference returns a new set with the features of this set that do not exist in the other set
+func (s *Set) Difference(other *Set) *Set {
+	return s.filter(func(f Feature) bool {
+		if other == nil {
+			return true
+		}
+		_, exists := other.set[f.String()]
+		return !exists
+	})
+}
+
+// FilterByType returns a new set with the features of this set matching any of the input feature types
+func (s *Set) FilterByType(types ...FeatureType) *Set {
+	return s.filter(func(f Feature) bool {
+		for _, ft := range types {
+			if f.Type() == ft {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// Rename replaces the feature with a new feature keeping its values. This is a no-op if the feature
+// does not exist and replaces the new feature if it already exists.
+func (s *Set) Rename(f, to Feature) *Set {
+	vals, exists := s.Get(f)
+	if !exists || f.String() == to.String() {
+		return s
+	}
+	s.Del(f)
+	return s.Set(to, vals)
+}
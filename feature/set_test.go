@@ -372,6 +372,34 @@ func TestMatrix(t *testing.T) {
 	}
 }
 
+func TestSparseMatrix(t *testing.T) {
+	s := &Set{
+		m: 4,
+		set: map[string][]float64{
+			"event_blargh": {0, 1, 1, 0},
+			"event_foo":    {0, 0, 0, 2},
+		},
+		labels: []Feature{
+			NewEvent("blargh"),
+			NewEvent("foo"),
+		},
+	}
+	assert.Equal(t, 3.0/8.0, s.Density())
+
+	for _, intercept := range []bool{true, false} {
+		t.Run(fmt.Sprintf("intercept %t", intercept), func(t *testing.T) {
+			res := s.SparseMatrix(intercept)
+			require.NotNil(t, res)
+			assert.True(t, mat.Equal(s.Matrix(intercept), res))
+		})
+	}
+
+	var nilSet *Set
+	assert.Nil(t, nilSet.SparseMatrix(true))
+	assert.Nil(t, (&Set{}).SparseMatrix(true))
+	assert.Equal(t, 0.0, nilSet.Density())
+}
+
 func TestRemoveZeroOnlyFeatures(t *testing.T) {
 	s := NewSet().Set(
 		NewTime("valid"),
@@ -410,3 +438,78 @@ func TestRemoveZeroOnlyFeatures(t *testing.T) {
 	assert.False(t, exists)
 	assert.Empty(t, vals)
 }
+
+func TestSetAlgebra(t *testing.T) {
+	newSet := func() *Set {
+		return NewSet().
+			Set(NewEvent("promo"), []float64{1, 0, 0}).
+			Set(NewSeasonality("daily", FourierCompSin, 1), []float64{0, 1, 0}).
+			Set(NewChangepoint("release", ChangepointCompBias), []float64{0, 0, 1})
+	}
+	other := NewSet().
+		Set(NewEvent("promo"), []float64{5, 5, 5}).
+		Set(NewTime("billing"), []float64{1, 1, 1})
+
+	labels := func(s *Set) []string {
+		var res []string
+		for _, f := range s.Labels() {
+			res = append(res, f.String())
+		}
+		return res
+	}
+
+	testData := map[string]struct {
+		op       func(s *Set) *Set
+		expected []string
+	}{
+		"intersect": {
+			op:       func(s *Set) *Set { return s.Intersect(other) },
+			expected: []string{"event_promo"},
+		},
+		"intersect nil": {
+			op: func(s *Set) *Set { return s.Intersect(nil) },
+		},
+		"difference": {
+			op:       func(s *Set) *Set { return s.Difference(other) },
+			expected: []string{"chpnt_release_bias", "seas_daily_01_sin"},
+		},
+		"difference nil": {
+			op:       func(s *Set) *Set { return s.Difference(nil) },
+			expected: []string{"chpnt_release_bias", "event_promo", "seas_daily_01_sin"},
+		},
+		"filter by type": {
+			op:       func(s *Set) *Set { return s.FilterByType(FeatureTypeEvent, FeatureTypeChangepoint) },
+			expected: []string{"chpnt_release_bias", "event_promo"},
+		},
+		"rename": {
+			op:       func(s *Set) *Set { return s.Rename(NewEvent("promo"), NewEvent("sale")) },
+			expected: []string{"chpnt_release_bias", "event_sale", "seas_daily_01_sin"},
+		},
+		"rename unknown": {
+			op:       func(s *Set) *Set { return s.Rename(NewEvent("sale"), NewEvent("promo")) },
+			expected: []string{"chpnt_release_bias", "event_promo", "seas_daily_01_sin"},
+		},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			res := td.op(newSet())
+			assert.Equal(t, td.expected, labels(res))
+			for _, f := range res.Labels() {
+				vals, exists := res.Get(f)
+				require.True(t, exists)
+				assert.Len(t, vals, 3)
+			}
+		})
+	}
+
+	// derived sets copy values so the source is left unchanged
+	s := newSet()
+	promo, _ := s.Intersect(other).Get(NewEvent("promo"))
+	promo[0] = 10
+	vals, _ := s.Get(NewEvent("promo"))
+	assert.Equal(t, []float64{1, 0, 0}, vals)
+
+	renamed, _ := s.Rename(NewEvent("promo"), NewEvent("sale")).Get(NewEvent("sale"))
+	assert.Equal(t, []float64{1, 0, 0}, renamed)
+}
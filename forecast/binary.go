@@ -0,0 +1,240 @@
+package forecast
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/errs"
+	"github.com/aouyang1/go-forecaster/feature"
+	"github.com/aouyang1/go-forecaster/forecast/options"
+)
+
+// binaryVersion is the version of the binary encoding written by Model.MarshalBinary
+const binaryVersion = 1
+
+var ErrInvalidBinaryModel = errs.NewDataError(errs.CodeInvalidModel, "invalid binary model", nil)
+
+// featureKind is the compact encoding of a feature type along with its component
+type featureKind uint8
+
+const (
+	featureKindChangepointBias featureKind = iota + 1
+	featureKindChangepointSlope
+	featureKindSeasonalitySin
+	featureKindSeasonalityCos
+	featureKindEvent
+	featureKindTime
+	featureKindRegressor
+)
+
+// modelBinary is the gob encoded model. The options are small and stored as json so that they are
+// read exactly as a json model would be while the weights use the compact coefficient encoding.
+type modelBinary struct {
+	Version       int
+	SchemaVersion int
+	TrainEndTime  time.Time
+	Options       []byte
+	Scores        *Scores
+	Weights       weightsBinary
+	FastPath      options.FastPath
+}
+
+// weightsBinary stores every distinct feature name once with each feature referencing its name by
+// index and the coefficient values as a contiguous slice in the same order as the features. The
+// standard errors and confidence intervals are only stored if estimated.
+type weightsBinary struct {
+	Intercept float64
+	Names     []string
+	Features  []featureBinary
+	Values    []float64
+	StdErrs   []float64
+	Lowers    []float64
+	Uppers    []float64
+}
+
+type featureBinary struct {
+	Kind  featureKind
+	Name  int
+	Order int
+}
+
+// MarshalBinary encodes the model in a compact binary format which is smaller and faster to load
+// than json for models with many features
+func (m Model) MarshalBinary() ([]byte, error) {
+	out := modelBinary{
+		Version:       binaryVersion,
+		SchemaVersion: m.SchemaVersion,
+		TrainEndTime:  m.TrainEndTime,
+		Scores:        m.Scores,
+		FastPath:      m.FastPath,
+	}
+	if m.Options != nil {
+		opt, err := json.Marshal(m.Options)
+		if err != nil {
+			return nil, fmt.Errorf("unable to encode options, %w", err)
+		}
+		out.Options = opt
+	}
+	weights, err := encodeWeights(m.Weights)
+	if err != nil {
+		return nil, err
+	}
+	out.Weights = weights
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(out); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary decodes a model encoded by MarshalBinary validating every feature as a json model is
+// validated when loaded
+func (m *Model) UnmarshalBinary(data []byte) error {
+	var in modelBinary
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&in); err != nil {
+		return fmt.Errorf("%s, %w", err.Error(), ErrInvalidBinaryModel)
+	}
+	if in.Version != binaryVersion {
+		return fmt.Errorf("binary version %d with latest %d, %w", in.Version, binaryVersion, ErrInvalidBinaryModel)
+	}
+
+	res := Model{
+		SchemaVersion: in.SchemaVersion,
+		TrainEndTime:  in.TrainEndTime,
+		Scores:        in.Scores,
+		FastPath:      in.FastPath,
+	}
+	if in.Options != nil {
+		res.Options = &options.Options{}
+		if err := json.Unmarshal(in.Options, res.Options); err != nil {
+			return fmt.Errorf("unable to decode options, %w", err)
+		}
+	}
+	weights, err := decodeWeights(in.Weights)
+	if err != nil {
+		return err
+	}
+	res.Weights = weights
+	*m = res
+	return nil
+}
+
+func encodeWeights(w Weights) (weightsBinary, error) {
+	out := weightsBinary{
+		Intercept: w.Intercept,
+		Features:  make([]featureBinary, 0, len(w.Coef)),
+		Values:    make([]float64, 0, len(w.Coef)),
+	}
+	nameIdx := make(map[string]int)
+	for _, fw := range w.Coef {
+		feat, err := fw.ToFeature()
+		if err != nil {
+			return weightsBinary{}, err
+		}
+		if err := validateFeature(feat); err != nil {
+			return weightsBinary{}, err
+		}
+
+		var fb featureBinary
+		var name string
+		switch f := feat.(type) {
+		case *feature.Changepoint:
+			name = f.Name
+			fb.Kind = featureKindChangepointBias
+			if f.ChangepointComp == feature.ChangepointCompSlope {
+				fb.Kind = featureKindChangepointSlope
+			}
+		case *feature.Seasonality:
+			name = f.Name
+			fb.Order = f.Order
+			fb.Kind = featureKindSeasonalitySin
+			if f.FourierComp == feature.FourierCompCos {
+				fb.Kind = featureKindSeasonalityCos
+			}
+		case *feature.Event:
+			name = f.Name
+			fb.Kind = featureKindEvent
+		case *feature.Time:
+			name = f.Name
+			fb.Kind = featureKindTime
+		case *feature.Regressor:
+			name = f.Name
+			fb.Kind = featureKindRegressor
+		}
+
+		idx, exists := nameIdx[name]
+		if !exists {
+			idx = len(out.Names)
+			nameIdx[name] = idx
+			out.Names = append(out.Names, name)
+		}
+		fb.Name = idx
+		out.Features = append(out.Features, fb)
+		out.Values = append(out.Values, fw.Value)
+	}
+
+	if slices.ContainsFunc(w.Coef, FeatureWeight.HasInterval) {
+		out.StdErrs = make([]float64, len(w.Coef))
+		out.Lowers = make([]float64, len(w.Coef))
+		out.Uppers = make([]float64, len(w.Coef))
+		for i, fw := range w.Coef {
+			out.StdErrs[i], out.Lowers[i], out.Uppers[i] = fw.StdErr, fw.Lower, fw.Upper
+		}
+	}
+	return out, nil
+}
+
+func decodeWeights(in weightsBinary) (Weights, error) {
+	if len(in.Features) != len(in.Values) {
+		return Weights{}, fmt.Errorf("%d features with %d values, %w", len(in.Features), len(in.Values), ErrInvalidBinaryModel)
+	}
+	withIntervals := len(in.StdErrs) > 0 || len(in.Lowers) > 0 || len(in.Uppers) > 0
+	if withIntervals && (len(in.StdErrs) != len(in.Values) || len(in.Lowers) != len(in.Values) || len(in.Uppers) != len(in.Values)) {
+		return Weights{}, fmt.Errorf("%d values with %d standard errors, %w", len(in.Values), len(in.StdErrs), ErrInvalidBinaryModel)
+	}
+
+	out := Weights{Intercept: in.Intercept}
+	if len(in.Features) > 0 {
+		out.Coef = make([]FeatureWeight, 0, len(in.Features))
+	}
+	for i, fb := range in.Features {
+		if fb.Name < 0 || fb.Name >= len(in.Names) {
+			return Weights{}, fmt.Errorf("name index %d of %d names, %w", fb.Name, len(in.Names), ErrInvalidBinaryModel)
+		}
+		name := in.Names[fb.Name]
+
+		var feat feature.Feature
+		switch fb.Kind {
+		case featureKindChangepointBias:
+			feat = feature.NewChangepoint(name, feature.ChangepointCompBias)
+		case featureKindChangepointSlope:
+			feat = feature.NewChangepoint(name, feature.ChangepointCompSlope)
+		case featureKindSeasonalitySin:
+			feat = feature.NewSeasonality(name, feature.FourierCompSin, fb.Order)
+		case featureKindSeasonalityCos:
+			feat = feature.NewSeasonality(name, feature.FourierCompCos, fb.Order)
+		case featureKindEvent:
+			feat = feature.NewEvent(name)
+		case featureKindTime:
+			feat = feature.NewTime(name)
+		case featureKindRegressor:
+			feat = feature.NewRegressor(name)
+		default:
+			return Weights{}, fmt.Errorf("feature kind %d, %w", fb.Kind, ErrUnknownFeatureType)
+		}
+		if err := validateFeature(feat); err != nil {
+			return Weights{}, err
+		}
+		fw := NewFeatureWeight(feat, in.Values[i])
+		if withIntervals {
+			fw.StdErr, fw.Lower, fw.Upper = in.StdErrs[i], in.Lowers[i], in.Uppers[i]
+		}
+		out.Coef = append(out.Coef, fw)
+	}
+	return out, nil
+}
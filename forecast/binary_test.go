@@ -0,0 +1,122 @@
+package forecast
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"testing"
+
+	"github.com/aouyang1/go-forecaster/feature"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModelBinary(t *testing.T) {
+	f, tWin, _ := testFitSignal(t)
+	model, err := f.Model()
+	require.Nil(t, err)
+	model.Weights.Coef = append(model.Weights.Coef,
+		NewFeatureWeight(feature.NewChangepoint("cp", feature.ChangepointCompBias), 0),
+		NewFeatureWeight(feature.NewChangepoint("cp", feature.ChangepointCompSlope), 0),
+		NewFeatureWeight(feature.NewEvent("holiday"), 0),
+		NewFeatureWeight(feature.NewTime("epoch"), 0),
+		NewFeatureWeight(feature.NewRegressor("x"), 0),
+	)
+
+	enc, err := model.MarshalBinary()
+	require.Nil(t, err)
+	jsonEnc, err := json.Marshal(model)
+	require.Nil(t, err)
+	assert.Less(t, len(enc), len(jsonEnc))
+
+	var decoded Model
+	require.Nil(t, decoded.UnmarshalBinary(enc))
+	var fromJSON Model
+	require.Nil(t, json.Unmarshal(jsonEnc, &fromJSON))
+	assert.Equal(t, fromJSON.Weights, decoded.Weights)
+	assert.Equal(t, fromJSON.Options, decoded.Options)
+	assert.Equal(t, fromJSON.Scores, decoded.Scores)
+	assert.True(t, fromJSON.TrainEndTime.Equal(decoded.TrainEndTime))
+
+	// drop the features added only to exercise the encoding
+	decoded.Weights.Coef = decoded.Weights.Coef[:len(decoded.Weights.Coef)-5]
+	loaded, err := NewFromModel(decoded)
+	require.Nil(t, err)
+	expected, _, err := f.Predict(tWin)
+	require.Nil(t, err)
+	res, _, err := loaded.Predict(tWin)
+	require.Nil(t, err)
+	assert.Equal(t, expected, res)
+}
+
+func TestModelUnmarshalBinaryInvalid(t *testing.T) {
+	encode := func(in modelBinary) []byte {
+		var buf bytes.Buffer
+		require.Nil(t, gob.NewEncoder(&buf).Encode(in))
+		return buf.Bytes()
+	}
+
+	testData := map[string]struct {
+		data []byte
+		err  error
+	}{
+		"corrupt": {
+			data: []byte("not a model"),
+			err:  ErrInvalidBinaryModel,
+		},
+		"unknown version": {
+			data: encode(modelBinary{Version: binaryVersion + 1}),
+			err:  ErrInvalidBinaryModel,
+		},
+		"mismatched values": {
+			data: encode(modelBinary{
+				Version: binaryVersion,
+				Weights: weightsBinary{
+					Names:    []string{"x"},
+					Features: []featureBinary{{Kind: featureKindRegressor}},
+				},
+			}),
+			err: ErrInvalidBinaryModel,
+		},
+		"name index": {
+			data: encode(modelBinary{
+				Version: binaryVersion,
+				Weights: weightsBinary{
+					Names:    []string{"x"},
+					Features: []featureBinary{{Kind: featureKindRegressor, Name: 1}},
+					Values:   []float64{1},
+				},
+			}),
+			err: ErrInvalidBinaryModel,
+		},
+		"unknown kind": {
+			data: encode(modelBinary{
+				Version: binaryVersion,
+				Weights: weightsBinary{
+					Names:    []string{"x"},
+					Features: []featureBinary{{Kind: 100}},
+					Values:   []float64{1},
+				},
+			}),
+			err: ErrUnknownFeatureType,
+		},
+		"invalid order": {
+			data: encode(modelBinary{
+				Version: binaryVersion,
+				Weights: weightsBinary{
+					Names:    []string{"daily"},
+					Features: []featureBinary{{Kind: featureKindSeasonalitySin}},
+					Values:   []float64{1},
+				},
+			}),
+			err: ErrInvalidFeatureWeight,
+		},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			var m Model
+			assert.ErrorIs(t, m.UnmarshalBinary(td.data), td.err)
+		})
+	}
+}
@@ -0,0 +1,291 @@
+// Package changepoint detects changes in the level and slope of a series by segmenting it into
+// pieces that are each well described by their own least squares line. Segmentations are scored by the
+// residual sum of squares of every segment plus a penalty per segment and searched with either PELT,
+// which finds the optimal segmentation, or binary segmentation, which greedily splits the segment with
+// the largest reduction in cost.
+package changepoint
+
+import (
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/aouyang1/go-forecaster/errs"
+)
+
+var (
+	ErrUnknownMethod     = errs.NewConfigError(errs.CodeInvalidOption, "unknown changepoint detection method", nil)
+	ErrNegativePenalty   = errs.NewConfigError(errs.CodeInvalidOption, "changepoint detection penalty must be non-negative", nil)
+	ErrInvalidMinSegment = errs.NewConfigError(errs.CodeInvalidOption, "changepoint detection minimum segment must be non-negative", nil)
+	ErrLengthMismatch    = errs.NewDataError(errs.CodeLengthMismatch, "positions have a different length than values", nil)
+)
+
+const (
+	// DefaultMinSegment is the fewest observations in a segment which is the smallest number that does
+	// not fit a line exactly
+	DefaultMinSegment = 3
+
+	// PenaltyFactor scales the noise variance times the log of the number of observations into the
+	// default penalty of a segment matching the bayesian information criterion of its intercept, slope,
+	// and changepoint location
+	PenaltyFactor = 3.0
+)
+
+// Method is the search over segmentations
+type Method string
+
+const (
+	// MethodPELT finds the segmentation with the lowest penalized cost with pruned exact linear time
+	// dynamic programming. This is the default if unset.
+	MethodPELT Method = "pelt"
+
+	// MethodBinarySegmentation repeatedly splits the segment with the largest cost reduction while the
+	// reduction exceeds the penalty
+	MethodBinarySegmentation Method = "binseg"
+)
+
+// Options configures the detection. A zero Penalty is estimated from the noise of the series as
+// PenaltyFactor times the variance of its first differences times the log of the number of
+// observations. MaxChangepoints bounds the number of changepoints if positive by dropping the
+// changepoints that reduce the cost the least.
+type Options struct {
+	Method          Method  `json:"method,omitempty"`
+	Penalty         float64 `json:"penalty,omitempty"`
+	MinSegment      int     `json:"min_segment,omitempty"`
+	MaxChangepoints int     `json:"max_changepoints,omitempty"`
+}
+
+// Validate returns an error if the method is unknown or the penalty or minimum segment is negative
+func (o Options) Validate() error {
+	switch o.Method {
+	case "", MethodPELT, MethodBinarySegmentation:
+	default:
+		return fmt.Errorf("%q, %w", o.Method, ErrUnknownMethod)
+	}
+	if o.Penalty < 0 || math.IsNaN(o.Penalty) {
+		return fmt.Errorf("penalty of %.3f, %w", o.Penalty, ErrNegativePenalty)
+	}
+	if o.MinSegment < 0 {
+		return fmt.Errorf("minimum segment of %d, %w", o.MinSegment, ErrInvalidMinSegment)
+	}
+	return nil
+}
+
+// Detect returns the indexes at which a new segment starts in ascending order. Positions are the
+// ordered locations of the values such as seconds since the first time. NaN values are ignored and
+// never start a segment.
+func Detect(x, y []float64, opt Options) ([]int, error) {
+	if err := opt.Validate(); err != nil {
+		return nil, err
+	}
+	if len(x) != len(y) {
+		return nil, fmt.Errorf("%d positions and %d values, %w", len(x), len(y), ErrLengthMismatch)
+	}
+
+	obsIdx := make([]int, 0, len(y))
+	for i, v := range y {
+		if !math.IsNaN(v) && !math.IsNaN(x[i]) {
+			obsIdx = append(obsIdx, i)
+		}
+	}
+	minSeg := opt.MinSegment
+	if minSeg < DefaultMinSegment {
+		minSeg = DefaultMinSegment
+	}
+	if len(obsIdx) < 2*minSeg {
+		return nil, nil
+	}
+
+	c := newCost(x, y, obsIdx)
+	penalty := opt.Penalty
+	if penalty == 0 {
+		penalty = c.defaultPenalty()
+	}
+
+	var bkps []int
+	switch opt.Method {
+	case MethodBinarySegmentation:
+		bkps = c.binarySegmentation(penalty, minSeg, opt.MaxChangepoints)
+	default:
+		bkps = c.pelt(penalty, minSeg)
+		bkps = c.limit(bkps, opt.MaxChangepoints)
+	}
+
+	res := make([]int, len(bkps))
+	for i, b := range bkps {
+		res[i] = obsIdx[b]
+	}
+	return res, nil
+}
+
+// cost evaluates the residual sum of squares of the least squares line of any segment of the observed
+// values in constant time from cumulative sums
+type cost struct {
+	n                     int
+	sx, sy, sxx, syy, sxy []float64
+	y                     []float64
+}
+
+func newCost(x, y []float64, obsIdx []int) *cost {
+	n := len(obsIdx)
+	// center and scale the positions and center the values so the cumulative sums do not lose precision
+	x0 := x[obsIdx[0]]
+	span := x[obsIdx[n-1]] - x0
+	if span <= 0 {
+		span = 1
+	}
+	var yMean float64
+	for _, i := range obsIdx {
+		yMean += y[i]
+	}
+	yMean /= float64(n)
+
+	c := &cost{
+		n:   n,
+		sx:  make([]float64, n+1),
+		sy:  make([]float64, n+1),
+		sxx: make([]float64, n+1),
+		syy: make([]float64, n+1),
+		sxy: make([]float64, n+1),
+		y:   make([]float64, n),
+	}
+	for j, i := range obsIdx {
+		xv := (x[i] - x0) / span * float64(n-1)
+		yv := y[i] - yMean
+		c.y[j] = yv
+		c.sx[j+1] = c.sx[j] + xv
+		c.sy[j+1] = c.sy[j] + yv
+		c.sxx[j+1] = c.sxx[j] + xv*xv
+		c.syy[j+1] = c.syy[j] + yv*yv
+		c.sxy[j+1] = c.sxy[j] + xv*yv
+	}
+	return c
+}
+
+// segment returns the residual sum of squares of the line fit to the observations in [start, end)
+func (c *cost) segment(start, end int) float64 {
+	m := float64(end - start)
+	sx := c.sx[end] - c.sx[start]
+	sy := c.sy[end] - c.sy[start]
+	sxx := c.sxx[end] - c.sxx[start] - sx*sx/m
+	syy := c.syy[end] - c.syy[start] - sy*sy/m
+	sxy := c.sxy[end] - c.sxy[start] - sx*sy/m
+	rss := syy
+	if sxx > 0 {
+		rss -= sxy * sxy / sxx
+	}
+	return math.Max(rss, 0)
+}
+
+// defaultPenalty estimates the noise variance from the median absolute first difference which is
+// robust to the changepoints themselves
+func (c *cost) defaultPenalty() float64 {
+	diffs := make([]float64, 0, c.n-1)
+	for i := 1; i < c.n; i++ {
+		diffs = append(diffs, math.Abs(c.y[i]-c.y[i-1]))
+	}
+	slices.Sort(diffs)
+	sigma := 1.4826 * diffs[len(diffs)/2] / math.Sqrt2
+	variance := sigma * sigma
+
+	// a noiseless series still needs a penalty to prefer fewer segments of equal cost
+	floor := 1e-9 * (c.segment(0, c.n) + 1)
+	return math.Max(PenaltyFactor*variance*math.Log(float64(c.n)), floor)
+}
+
+// pelt returns the segment starts of the optimal penalized segmentation
+func (c *cost) pelt(penalty float64, minSeg int) []int {
+	best := make([]float64, c.n+1)
+	prev := make([]int, c.n+1)
+	for i := range best {
+		best[i] = math.Inf(1)
+	}
+	best[0] = -penalty
+
+	candidates := []int{0}
+	for end := minSeg; end <= c.n; end++ {
+		if s := end - minSeg; s >= minSeg {
+			candidates = append(candidates, s)
+		}
+		costs := make([]float64, len(candidates))
+		for i, s := range candidates {
+			costs[i] = best[s] + c.segment(s, end) + penalty
+			if costs[i] < best[end] {
+				best[end] = costs[i]
+				prev[end] = s
+			}
+		}
+		// prune candidates that can never be optimal for a later end
+		kept := candidates[:0]
+		for i, s := range candidates {
+			if costs[i]-penalty <= best[end] {
+				kept = append(kept, s)
+			}
+		}
+		candidates = kept
+	}
+
+	var bkps []int
+	for end := c.n; prev[end] > 0; end = prev[end] {
+		bkps = append(bkps, prev[end])
+	}
+	slices.Sort(bkps)
+	return bkps
+}
+
+// binarySegmentation splits the segment with the largest cost reduction until no reduction exceeds the
+// penalty or the maximum number of changepoints is reached
+func (c *cost) binarySegmentation(penalty float64, minSeg, maxChpts int) []int {
+	type split struct {
+		at   int
+		gain float64
+	}
+	bestSplit := func(start, end int) split {
+		res := split{at: -1}
+		total := c.segment(start, end)
+		for s := start + minSeg; s <= end-minSeg; s++ {
+			if gain := total - c.segment(start, s) - c.segment(s, end); gain > res.gain {
+				res = split{at: s, gain: gain}
+			}
+		}
+		return res
+	}
+
+	bounds := []int{0, c.n}
+	for maxChpts <= 0 || len(bounds)-2 < maxChpts {
+		var chosen split
+		chosen.at = -1
+		for i := 1; i < len(bounds); i++ {
+			if s := bestSplit(bounds[i-1], bounds[i]); s.at != -1 && s.gain > chosen.gain {
+				chosen = s
+			}
+		}
+		if chosen.at == -1 || chosen.gain <= penalty {
+			break
+		}
+		bounds = append(bounds, chosen.at)
+		slices.Sort(bounds)
+	}
+	return bounds[1 : len(bounds)-1]
+}
+
+// limit drops the segment starts that reduce the cost the least until at most maxChpts remain
+func (c *cost) limit(bkps []int, maxChpts int) []int {
+	for maxChpts > 0 && len(bkps) > maxChpts {
+		minIdx, minGain := 0, math.Inf(1)
+		for i, b := range bkps {
+			start, end := 0, c.n
+			if i > 0 {
+				start = bkps[i-1]
+			}
+			if i < len(bkps)-1 {
+				end = bkps[i+1]
+			}
+			if gain := c.segment(start, end) - c.segment(start, b) - c.segment(b, end); gain < minGain {
+				minIdx, minGain = i, gain
+			}
+		}
+		bkps = slices.Delete(bkps, minIdx, minIdx+1)
+	}
+	return bkps
+}
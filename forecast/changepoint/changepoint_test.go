@@ -0,0 +1,127 @@
+package changepoint
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetect(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	n := 300
+	x := make([]float64, n)
+	piecewise := make([]float64, n)
+	for i := range x {
+		x[i] = float64(i) * 60
+		switch {
+		case i < 100:
+			piecewise[i] = 10 + 0.01*float64(i)
+		case i < 200:
+			piecewise[i] = 30 + 0.01*float64(i)
+		default:
+			piecewise[i] = 31 - 0.2*float64(i-200)
+		}
+		piecewise[i] += 0.5 * rng.NormFloat64()
+	}
+	line := make([]float64, n)
+	for i := range line {
+		line[i] = 5 + 0.3*float64(i) + 0.5*rng.NormFloat64()
+	}
+	withNaN := make([]float64, n)
+	copy(withNaN, piecewise)
+	for i := 0; i < n; i += 7 {
+		withNaN[i] = math.NaN()
+	}
+	withNaN[100] = math.NaN()
+
+	testData := map[string]struct {
+		x        []float64
+		y        []float64
+		opt      Options
+		expected []int
+		err      error
+	}{
+		"pelt": {
+			x:        x,
+			y:        piecewise,
+			expected: []int{100, 200},
+		},
+		"binary segmentation": {
+			x:        x,
+			y:        piecewise,
+			opt:      Options{Method: MethodBinarySegmentation},
+			expected: []int{100, 200},
+		},
+		"pelt max changepoints": {
+			x:        x,
+			y:        piecewise,
+			opt:      Options{MaxChangepoints: 1},
+			expected: []int{100},
+		},
+		"binary segmentation max changepoints": {
+			x:        x,
+			y:        piecewise,
+			opt:      Options{Method: MethodBinarySegmentation, MaxChangepoints: 1},
+			expected: []int{100},
+		},
+		"no change in a trend": {
+			x: x,
+			y: line,
+		},
+		"large penalty": {
+			x:   x,
+			y:   piecewise,
+			opt: Options{Penalty: 1e9},
+		},
+		"nans skipped": {
+			x:        x,
+			y:        withNaN,
+			expected: []int{101, 200},
+		},
+		"too few observations": {
+			x: x[:5],
+			y: piecewise[:5],
+		},
+		"unknown method": {
+			x:   x,
+			y:   piecewise,
+			opt: Options{Method: "window"},
+			err: ErrUnknownMethod,
+		},
+		"negative penalty": {
+			x:   x,
+			y:   piecewise,
+			opt: Options{Penalty: -1},
+			err: ErrNegativePenalty,
+		},
+		"negative min segment": {
+			x:   x,
+			y:   piecewise,
+			opt: Options{MinSegment: -1},
+			err: ErrInvalidMinSegment,
+		},
+		"length mismatch": {
+			x:   x[:10],
+			y:   piecewise,
+			err: ErrLengthMismatch,
+		},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			res, err := Detect(td.x, td.y, td.opt)
+			if td.err != nil {
+				assert.ErrorIs(t, err, td.err)
+				return
+			}
+			require.Nil(t, err)
+			require.Len(t, res, len(td.expected))
+			for i := range td.expected {
+				assert.InDelta(t, td.expected[i], res[i], 5)
+			}
+		})
+	}
+}
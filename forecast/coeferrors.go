@@ -0,0 +1,194 @@
+package forecast
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"math/rand"
+	"slices"
+
+	"github.com/aouyang1/go-forecaster/feature"
+	"github.com/aouyang1/go-forecaster/forecast/options"
+	"github.com/aouyang1/go-forecaster/models"
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// lambdaModel is a regression exposing the regularization lambda of its fit
+type lambdaModel interface {
+	Lambda() float64
+}
+
+// closedFormLambda returns the ridge penalty of the fit if the coefficient covariance has a closed form
+// which is the case for ridge and for a lasso selecting a zero lambda
+func (f *Forecast) closedFormLambda(model models.Regressor) (float64, bool) {
+	lm, ok := model.(lambdaModel)
+	if !ok {
+		return 0, false
+	}
+	switch f.opt.Regression {
+	case "", options.RegressionLasso:
+		return 0, lm.Lambda() == 0
+	case options.RegressionRidge:
+		return lm.Lambda(), true
+	}
+	return 0, false
+}
+
+// estimateCoefficientErrors sets the standard error and confidence interval of every feature weight.
+// The features and target are the regression inputs with the constant column first and rows past
+// numObs are the pseudo observations of the smooth trend penalty. The errors of standardized features
+// are unscaled to the raw features.
+func (f *Forecast) estimateCoefficientErrors(ctx context.Context, labels []feature.Feature, features, target mat.Matrix, numObs int, model models.Regressor, smoothCols []int, scale featureScale) error {
+	if len(f.featureWeights) == 0 {
+		return nil
+	}
+
+	colIdx := make(map[string]int, len(labels))
+	for i, label := range labels {
+		colIdx[label.String()] = i + 1
+	}
+	cols := make([]int, len(f.featureWeights))
+	for i, fw := range f.featureWeights {
+		feat, err := fw.ToFeature()
+		if err != nil {
+			return err
+		}
+		cols[i] = colIdx[feat.String()]
+	}
+
+	pred, err := model.Predict(features)
+	if err != nil {
+		return err
+	}
+	residual := make([]float64, numObs)
+	for i := range residual {
+		residual[i] = target.At(i, 0) - pred[i]
+	}
+
+	level := f.opt.CoefficientErrors.ConfidenceLevel()
+	if lambda, ok := f.closedFormLambda(model); ok {
+		stdErrs, err := closedFormStdErrs(features, residual, append([]int{0}, cols...), lambda)
+		if err == nil {
+			z := distuv.UnitNormal.Quantile(0.5 + level/2)
+			for i := range f.featureWeights {
+				fw := &f.featureWeights[i]
+				fw.StdErr = stdErrs[i+1] / scale.scale[cols[i]]
+				fw.Lower = fw.Value - z*fw.StdErr
+				fw.Upper = fw.Value + z*fw.StdErr
+			}
+			return nil
+		}
+		slog.Warn("unable to estimate coefficient errors in closed form, bootstrapping", "error", err.Error())
+	}
+
+	samples, err := f.bootstrapCoefficients(ctx, features, target, pred, residual, cols, smoothCols)
+	if err != nil {
+		return err
+	}
+	alpha := 1 - level
+	for i, s := range samples {
+		floats.Scale(1/scale.scale[cols[i]], s)
+		fw := &f.featureWeights[i]
+		fw.StdErr = stat.StdDev(s, nil)
+		slices.Sort(s)
+		fw.Lower = stat.Quantile(alpha/2, stat.Empirical, s, nil)
+		fw.Upper = stat.Quantile(1-alpha/2, stat.Empirical, s, nil)
+	}
+	return nil
+}
+
+// closedFormStdErrs returns the standard errors of the coefficients of the columns from the sandwich
+// covariance of the ridge estimator which is the least squares covariance with a zero lambda
+func closedFormStdErrs(features mat.Matrix, residual []float64, cols []int, lambda float64) ([]float64, error) {
+	rows, _ := features.Dims()
+	p := len(cols)
+	dof := len(residual) - p
+	if dof <= 0 {
+		return nil, ErrInsufficientTrainingData
+	}
+
+	x := mat.NewDense(rows, p, nil)
+	for j, col := range cols {
+		for i := 0; i < rows; i++ {
+			x.Set(i, j, features.At(i, col))
+		}
+	}
+	xtx := mat.NewSymDense(p, nil)
+	xtx.SymOuterK(1, x.T())
+
+	a := mat.NewSymDense(p, nil)
+	a.CopySym(xtx)
+	for j := 0; j < p; j++ {
+		a.SetSym(j, j, a.At(j, j)+lambda)
+	}
+	var chol mat.Cholesky
+	if ok := chol.Factorize(a); !ok {
+		return nil, models.ErrRidgeSolve
+	}
+	var aInv mat.SymDense
+	if err := chol.InverseTo(&aInv); err != nil {
+		return nil, err
+	}
+
+	var cov mat.Dense
+	cov.Product(&aInv, xtx, &aInv)
+
+	var rss float64
+	for _, r := range residual {
+		rss += r * r
+	}
+	variance := rss / float64(dof)
+
+	stdErrs := make([]float64, p)
+	for j := range stdErrs {
+		stdErrs[j] = math.Sqrt(math.Max(variance*cov.At(j, j), 0))
+	}
+	return stdErrs, nil
+}
+
+// bootstrapCoefficients refits the regression on the fit plus resampled residuals returning the
+// coefficients of every column across the samples. Pseudo observations keep their target.
+func (f *Forecast) bootstrapCoefficients(ctx context.Context, features, target mat.Matrix, pred, residual []float64, cols, smoothCols []int) ([][]float64, error) {
+	opt := f.opt.CoefficientErrors
+	numSamples := opt.NumBootstrapSamples()
+	rng := rand.New(rand.NewSource(opt.Seed))
+
+	// training hooks report the fit and not the bootstrap refits
+	hooks := f.opt.TrainingHooks
+	f.opt.TrainingHooks = nil
+	defer func() {
+		f.opt.TrainingHooks = hooks
+	}()
+
+	rows, numCols := features.Dims()
+	samples := make([][]float64, len(cols))
+	for i := range samples {
+		samples[i] = make([]float64, numSamples)
+	}
+	yStar := mat.NewDense(rows, 1, nil)
+	for b := 0; b < numSamples; b++ {
+		for i := 0; i < rows; i++ {
+			if i < len(residual) {
+				yStar.Set(i, 0, pred[i]+residual[rng.Intn(len(residual))])
+				continue
+			}
+			yStar.Set(i, 0, target.At(i, 0))
+		}
+
+		model, err := f.newRegressionModel(smoothCols, numCols, nil)
+		if err != nil {
+			return nil, err
+		}
+		if err := fitModel(ctx, model, features, yStar); err != nil {
+			return nil, err
+		}
+		coef := model.Coef()
+		for i, col := range cols {
+			samples[i][b] = coef[col]
+		}
+	}
+	return samples, nil
+}
@@ -0,0 +1,130 @@
+package forecast
+
+import (
+	"encoding/json"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/feature"
+	"github.com/aouyang1/go-forecaster/forecast/options"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCoefficientErrors(t *testing.T) {
+	n := 3 * 24 * 6
+	noise := 0.5
+	ct := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	r := rand.New(rand.NewSource(7))
+	tWin := make([]time.Time, 0, n)
+	y := make([]float64, 0, n)
+	for i := 0; i < n; i++ {
+		tPnt := ct.Add(time.Duration(i) * 10 * time.Minute)
+		tWin = append(tWin, tPnt)
+		y = append(y, 5.0+3.0*math.Sin(2.0*math.Pi*tPnt.Sub(ct).Seconds()/86400.0)+noise*r.NormFloat64())
+	}
+	sinLabel := feature.NewSeasonality("epoch_daily", feature.FourierCompSin, 1).String()
+
+	testData := map[string]struct {
+		regression     options.Regression
+		regularization []float64
+		maxStdErr      float64
+	}{
+		// least squares standard error of a unit amplitude sinusoid is noise * sqrt(2/n)
+		"closed form lasso": {maxStdErr: 2 * noise * math.Sqrt(2.0/float64(n))},
+		"closed form ridge": {regression: options.RegressionRidge, maxStdErr: 2 * noise * math.Sqrt(2.0/float64(n))},
+		"bootstrap lasso":   {regularization: []float64{0.01}, maxStdErr: 0.1},
+		"bootstrap huber":   {regression: options.RegressionHuber, maxStdErr: 0.1},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			opt := options.NewDefaultOptions()
+			opt.SeasonalityOptions.SeasonalityConfigs = []options.SeasonalityConfig{
+				options.NewDailySeasonalityConfig(1),
+			}
+			opt.Regression = td.regression
+			if td.regularization != nil {
+				opt.Regularization = td.regularization
+			}
+			opt.CoefficientErrors = options.CoefficientErrorOptions{
+				Enabled:          true,
+				BootstrapSamples: 50,
+				Seed:             1,
+			}
+
+			f, err := New(opt)
+			require.Nil(t, err)
+			require.Nil(t, f.Fit(tWin, y))
+
+			m, err := f.Model()
+			require.Nil(t, err)
+			require.NotEmpty(t, m.Weights.Coef)
+
+			var found bool
+			for _, fw := range m.Weights.Coef {
+				assert.True(t, fw.HasInterval())
+				assert.Greater(t, fw.StdErr, 0.0)
+				assert.LessOrEqual(t, fw.Lower, fw.Value)
+				assert.GreaterOrEqual(t, fw.Upper, fw.Value)
+
+				feat, err := fw.ToFeature()
+				require.Nil(t, err)
+				if feat.String() != sinLabel {
+					continue
+				}
+				found = true
+				assert.Less(t, fw.StdErr, td.maxStdErr)
+				assert.Less(t, fw.Lower, 3.0)
+				assert.Greater(t, fw.Upper, 3.0)
+			}
+			assert.True(t, found)
+		})
+	}
+}
+
+func TestCoefficientErrorsDisabled(t *testing.T) {
+	f, _, _ := testFitSignal(t)
+	m, err := f.Model()
+	require.Nil(t, err)
+	for _, fw := range m.Weights.Coef {
+		assert.False(t, fw.HasInterval())
+	}
+}
+
+func TestCoefficientErrorsEncoding(t *testing.T) {
+	m := Model{
+		Weights: Weights{
+			Intercept: 1.0,
+			Coef: []FeatureWeight{
+				{
+					Labels: NewFeatureWeight(feature.NewSeasonality("daily", feature.FourierCompSin, 1), 0).Labels,
+					Type:   feature.FeatureTypeSeasonality,
+					Value:  3.0,
+					StdErr: 0.1,
+					Lower:  2.8,
+					Upper:  3.2,
+				},
+				NewFeatureWeight(feature.NewEvent("e0"), 2.0),
+			},
+		},
+	}
+
+	out, err := json.Marshal(m.Weights.Coef[0])
+	require.Nil(t, err)
+	assert.Contains(t, string(out), `"std_err":0.1,"lower":2.8,"upper":3.2`)
+
+	var fromJSON Model
+	out, err = json.Marshal(m)
+	require.Nil(t, err)
+	require.Nil(t, json.Unmarshal(out, &fromJSON))
+	assert.Equal(t, m.Weights, fromJSON.Weights)
+
+	enc, err := m.MarshalBinary()
+	require.Nil(t, err)
+	var decoded Model
+	require.Nil(t, decoded.UnmarshalBinary(enc))
+	assert.Equal(t, m.Weights, decoded.Weights)
+}
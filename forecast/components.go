@@ -1,7 +1,84 @@
 package forecast
 
+import (
+	"fmt"
+	"sort"
+
+	"github.com/aouyang1/go-forecaster/feature"
+)
+
 type Components struct {
 	Trend       []float64 `json:"trend"`
 	Seasonality []float64 `json:"seasonality"`
 	Event       []float64 `json:"event"`
+	Custom      []float64 `json:"custom"`
+	Regressor   []float64 `json:"regressor,omitempty"`
+
+	// NaNReasons names the features with NaN values at each time point if the NaN policy marks them.
+	// Unaffected time points have an empty reason and this is nil if no feature contains NaN.
+	NaNReasons []string `json:"nan_reasons,omitempty"`
+
+	// MissingFeatures names the custom time features and regressors of the model that were unavailable
+	// and filled according to their missing policy or nil if none are missing
+	MissingFeatures []string `json:"missing_features,omitempty"`
+
+	// Features breaks the components down into the contribution of each individual seasonality, event,
+	// changepoint, custom feature, and regressor ordered by type and name. The intercept is only
+	// included in the trend.
+	Features []FeatureComponent `json:"features,omitempty"`
+}
+
+// FeatureComponent is the contribution of every feature sharing a type and name such as all fourier
+// terms of a seasonality or the bias and slope of a changepoint
+type FeatureComponent struct {
+	Type   feature.FeatureType `json:"type"`
+	Name   string              `json:"name"`
+	Values []float64           `json:"values"`
+}
+
+// Feature returns the contribution of the feature type and name or nil if it is not in the model
+func (c Components) Feature(featType feature.FeatureType, name string) []float64 {
+	for _, fc := range c.Features {
+		if fc.Type == featType && fc.Name == name {
+			return fc.Values
+		}
+	}
+	return nil
+}
+
+// featureComponents runs inference separately on the features of each type and name
+func (f *Forecast) featureComponents(x *feature.Set, numObs int) ([]FeatureComponent, error) {
+	type groupKey struct {
+		featType feature.FeatureType
+		name     string
+	}
+	groups := make(map[groupKey]*feature.Set)
+	for _, feat := range x.Labels() {
+		data, exists := x.Get(feat)
+		if !exists {
+			continue
+		}
+		name, _ := feat.Get("name")
+		key := groupKey{featType: feat.Type(), name: name}
+		if _, exists := groups[key]; !exists {
+			groups[key] = feature.NewSet()
+		}
+		groups[key].Set(feat, data)
+	}
+
+	res := make([]FeatureComponent, 0, len(groups))
+	for key, set := range groups {
+		vals, err := f.runInference(set, false, numObs)
+		if err != nil {
+			return nil, fmt.Errorf("unable to run inference for %s %s, %w", key.featType, key.name, err)
+		}
+		res = append(res, FeatureComponent{Type: key.featType, Name: key.name, Values: vals})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Type != res[j].Type {
+			return res[i].Type < res[j].Type
+		}
+		return res[i].Name < res[j].Name
+	})
+	return res, nil
 }
@@ -0,0 +1,83 @@
+package forecast
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/feature"
+	"github.com/aouyang1/go-forecaster/forecast/options"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPredictFeatureComponents(t *testing.T) {
+	n := 7 * 24
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	promoStart, promoEnd := t0.Add(2*24*time.Hour), t0.Add(3*24*time.Hour)
+	shift := t0.Add(4 * 24 * time.Hour)
+
+	tWin := make([]time.Time, n)
+	y := make([]float64, n)
+	for i := range tWin {
+		tWin[i] = t0.Add(time.Duration(i) * time.Hour)
+		y[i] = 10 + 2*math.Sin(2*math.Pi*float64(i)/24)
+		if !tWin[i].Before(promoStart) && tWin[i].Before(promoEnd) {
+			y[i] += 5
+		}
+		if !tWin[i].Before(shift) {
+			y[i] += 3
+		}
+	}
+
+	opt := options.NewDefaultOptions()
+	opt.SeasonalityOptions.SeasonalityConfigs = []options.SeasonalityConfig{options.NewDailySeasonalityConfig(2)}
+	opt.ChangepointOptions.Changepoints = []options.Changepoint{options.NewChangepoint("shift", shift)}
+	opt.EventOptions.Events = []options.Event{options.NewEvent("promo", promoStart, promoEnd)}
+
+	f, err := New(opt)
+	require.Nil(t, err)
+	require.Nil(t, f.Fit(tWin, y))
+
+	_, comp, err := f.Predict(tWin)
+	require.Nil(t, err)
+
+	promo := comp.Feature(feature.FeatureTypeEvent, "promo")
+	require.Len(t, promo, n)
+	chpt := comp.Feature(feature.FeatureTypeChangepoint, "shift")
+	require.Len(t, chpt, n)
+	for i, tPnt := range tWin {
+		expectedPromo := 0.0
+		if !tPnt.Before(promoStart) && tPnt.Before(promoEnd) {
+			expectedPromo = 5.0
+		}
+		assert.InDelta(t, expectedPromo, promo[i], 1e-3)
+
+		expectedChpt := 0.0
+		if !tPnt.Before(shift) {
+			expectedChpt = 3.0
+		}
+		assert.InDelta(t, expectedChpt, chpt[i], 1e-3)
+	}
+	assert.Nil(t, comp.Feature(feature.FeatureTypeEvent, "unknown"))
+
+	// the feature components of each type sum to the aggregate components
+	sums := make(map[feature.FeatureType][]float64)
+	for i, fc := range comp.Features {
+		if i > 0 {
+			prev := comp.Features[i-1]
+			assert.True(t, prev.Type < fc.Type || (prev.Type == fc.Type && prev.Name < fc.Name))
+		}
+		if sums[fc.Type] == nil {
+			sums[fc.Type] = make([]float64, n)
+		}
+		for j, v := range fc.Values {
+			sums[fc.Type][j] += v
+		}
+	}
+	for i := range tWin {
+		assert.InDelta(t, comp.Trend[i]-f.Intercept(), sums[feature.FeatureTypeChangepoint][i], 1e-9)
+		assert.InDelta(t, comp.Seasonality[i], sums[feature.FeatureTypeSeasonality][i], 1e-9)
+		assert.InDelta(t, comp.Event[i], sums[feature.FeatureTypeEvent][i], 1e-9)
+	}
+}
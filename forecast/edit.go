@@ -0,0 +1,142 @@
+package forecast
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/errs"
+	"github.com/aouyang1/go-forecaster/forecast/options"
+)
+
+var (
+	ErrNoModelOptions           = errs.NewConfigError(errs.CodeInvalidModel, "model has no options", nil)
+	ErrDuplicateEvent           = errs.NewConfigError(errs.CodeAlreadyExists, "event is already configured in the model", nil)
+	ErrUnknownChangepoint       = errs.NewConfigError(errs.CodeNotFound, "changepoint is not configured in the model", nil)
+	ErrChangepointAfterTrainEnd = errs.NewConfigError(errs.CodeInvalidOption, "changepoint is after the model training end time", nil)
+)
+
+// Events returns a copy of the events configured in the model
+func (m Model) Events() []options.Event {
+	if m.Options == nil {
+		return nil
+	}
+	events := make([]options.Event, len(m.Options.EventOptions.Events))
+	copy(events, m.Options.EventOptions.Events)
+	return events
+}
+
+// Changepoints returns a copy of the changepoints configured in the model
+func (m Model) Changepoints() []options.Changepoint {
+	if m.Options == nil {
+		return nil
+	}
+	chpts := make([]options.Changepoint, len(m.Options.ChangepointOptions.Changepoints))
+	copy(chpts, m.Options.ChangepointOptions.Changepoints)
+	return chpts
+}
+
+// AddEvent adds an event to a trained model without retraining. The coefficients stay fixed so an
+// event whose name has no weight in the model does not change predictions until the model is
+// retrained. Adding back a removed event with a modified window, e.g. an extended promotion, applies
+// the fitted weights of the original event to the new window.
+func (m *Model) AddEvent(ev options.Event) error {
+	if m.Options == nil {
+		return ErrNoModelOptions
+	}
+	if err := ev.Valid(); err != nil {
+		return fmt.Errorf("event %q, %w", ev.Name, err)
+	}
+	for _, existing := range m.Options.EventOptions.Events {
+		if existing.Name == ev.Name {
+			return fmt.Errorf("%q, %w", ev.Name, ErrDuplicateEvent)
+		}
+	}
+
+	opt := m.editableOptions()
+	opt.EventOptions.Events = append(opt.EventOptions.Events, ev)
+	return m.commitOptions(opt)
+}
+
+// RemoveEvent removes the named event from a trained model without retraining. The weights of the
+// event are kept so that the event can be added back with a modified window. Removing an event that
+// a changepoint is anchored to is rejected.
+func (m *Model) RemoveEvent(name string) error {
+	if m.Options == nil {
+		return ErrNoModelOptions
+	}
+
+	opt := m.editableOptions()
+	events := make([]options.Event, 0, len(opt.EventOptions.Events))
+	for _, ev := range opt.EventOptions.Events {
+		if ev.Name != name {
+			events = append(events, ev)
+		}
+	}
+	if len(events) == len(opt.EventOptions.Events) {
+		return fmt.Errorf("%q, %w", name, ErrUnknownEvent)
+	}
+	opt.EventOptions.Events = events
+	return m.commitOptions(opt)
+}
+
+// ShiftChangepoint moves the named changepoint by the input duration without retraining. Event
+// anchored changepoints have their offset shifted instead of their time. The changepoint may not move
+// past the training end time of the model since its growth feature is relative to that time.
+func (m *Model) ShiftChangepoint(name string, shift time.Duration) error {
+	if m.Options == nil {
+		return ErrNoModelOptions
+	}
+
+	opt := m.editableOptions()
+	idx := -1
+	for i, chpt := range opt.ChangepointOptions.Changepoints {
+		if chpt.Name == name {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return fmt.Errorf("%q, %w", name, ErrUnknownChangepoint)
+	}
+
+	chpt := &opt.ChangepointOptions.Changepoints[idx]
+	if chpt.AnchorEvent != "" {
+		chpt.Offset += shift
+	} else {
+		chpt.T = chpt.T.Add(shift)
+	}
+	return m.commitOptions(opt)
+}
+
+// editableOptions returns a copy of the model options with its own event and changepoint slices so
+// that edits do not modify a forecast sharing the options
+func (m *Model) editableOptions() *options.Options {
+	opt := *m.Options
+	opt.EventOptions.Events = m.Events()
+	opt.ChangepointOptions.Changepoints = m.Changepoints()
+	return &opt
+}
+
+// commitOptions revalidates the edited events and changepoints and replaces the model options only if
+// they are valid. Only changepoints moved by the edit are checked against the training end time.
+func (m *Model) commitOptions(opt *options.Options) error {
+	for _, ev := range opt.EventOptions.Events {
+		if err := ev.Valid(); err != nil {
+			return fmt.Errorf("event %q, %w", ev.Name, err)
+		}
+	}
+	if err := opt.ChangepointOptions.ResolveAnchors(opt.EventOptions.Events); err != nil {
+		return err
+	}
+	prev := m.Options.ChangepointOptions.Changepoints
+	for i, chpt := range opt.ChangepointOptions.Changepoints {
+		if chpt.T.Equal(prev[i].T) {
+			continue
+		}
+		if !m.TrainEndTime.IsZero() && chpt.T.After(m.TrainEndTime) {
+			return fmt.Errorf("changepoint %q at %s, %w", chpt.Name, chpt.T, ErrChangepointAfterTrainEnd)
+		}
+	}
+	m.Options = opt
+	return nil
+}
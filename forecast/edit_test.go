@@ -0,0 +1,147 @@
+package forecast
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/forecast/options"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testEditModel(t *testing.T) (*Forecast, []time.Time, time.Time) {
+	n := 6 * 24
+	tWin := make([]time.Time, 0, n)
+	ct := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < n; i++ {
+		tWin = append(tWin, ct.Add(time.Duration(i)*time.Hour))
+	}
+
+	promoStart := ct.Add(2 * 24 * time.Hour)
+	opt := options.NewDefaultOptions()
+	opt.SeasonalityOptions.SeasonalityConfigs = nil
+	opt.EventOptions.Events = []options.Event{
+		options.NewEvent("promo", promoStart, promoStart.Add(12*time.Hour)),
+		options.NewEvent("migration", ct.Add(4*24*time.Hour), ct.Add(4*24*time.Hour+6*time.Hour)),
+	}
+	opt.ChangepointOptions.Changepoints = []options.Changepoint{
+		options.NewChangepoint("release", ct.Add(24*time.Hour)),
+		options.NewEventChangepoint("after_migration", "migration", options.AnchorEdgeEnd, 0),
+	}
+
+	y := make([]float64, n)
+	for i, tPnt := range tWin {
+		y[i] = 2.0
+		if !tPnt.Before(promoStart) && tPnt.Before(promoStart.Add(12*time.Hour)) {
+			y[i] += 3.0
+		}
+	}
+
+	f, err := New(opt)
+	require.Nil(t, err)
+	require.Nil(t, f.Fit(tWin, y))
+	return f, tWin, promoStart
+}
+
+func TestModelExtendEvent(t *testing.T) {
+	f, tWin, promoStart := testEditModel(t)
+
+	model, err := f.Model()
+	require.Nil(t, err)
+	require.Nil(t, model.RemoveEvent("promo"))
+	assert.Len(t, model.Events(), 1)
+	require.Nil(t, model.AddEvent(options.NewEvent("promo", promoStart, promoStart.Add(24*time.Hour))))
+	assert.Len(t, model.Events(), 2)
+
+	// the forecast sharing the options is not modified
+	assert.Equal(t, promoStart.Add(12*time.Hour), f.opt.EventOptions.Events[0].End)
+
+	edited, err := NewFromModel(model)
+	require.Nil(t, err)
+	predicted, _, err := edited.Predict(tWin)
+	require.Nil(t, err)
+	for i, tPnt := range tWin {
+		expected := 2.0
+		if !tPnt.Before(promoStart) && tPnt.Before(promoStart.Add(24*time.Hour)) {
+			expected += 3.0
+		}
+		assert.InDelta(t, expected, predicted[i], 0.05, "time %s", tPnt)
+	}
+}
+
+func TestModelEditErrors(t *testing.T) {
+	f, _, promoStart := testEditModel(t)
+
+	testData := map[string]struct {
+		edit func(m *Model) error
+		err  error
+	}{
+		"duplicate event": {
+			edit: func(m *Model) error {
+				return m.AddEvent(options.NewEvent("promo", promoStart, promoStart.Add(time.Hour)))
+			},
+			err: ErrDuplicateEvent,
+		},
+		"invalid event": {
+			edit: func(m *Model) error {
+				return m.AddEvent(options.NewEvent("sale", promoStart.Add(time.Hour), promoStart))
+			},
+			err: options.ErrStartAfterEnd,
+		},
+		"unknown event": {
+			edit: func(m *Model) error { return m.RemoveEvent("sale") },
+			err:  ErrUnknownEvent,
+		},
+		"anchored event": {
+			edit: func(m *Model) error { return m.RemoveEvent("migration") },
+			err:  options.ErrUnknownAnchorEvent,
+		},
+		"unknown changepoint": {
+			edit: func(m *Model) error { return m.ShiftChangepoint("rollback", time.Hour) },
+			err:  ErrUnknownChangepoint,
+		},
+		"changepoint after train end": {
+			edit: func(m *Model) error { return m.ShiftChangepoint("release", 30*24*time.Hour) },
+			err:  ErrChangepointAfterTrainEnd,
+		},
+		"shift changepoint": {
+			edit: func(m *Model) error { return m.ShiftChangepoint("release", time.Hour) },
+		},
+		"shift anchored changepoint": {
+			edit: func(m *Model) error { return m.ShiftChangepoint("after_migration", -time.Hour) },
+		},
+		"no options": {
+			edit: func(m *Model) error {
+				m.Options = nil
+				return m.RemoveEvent("promo")
+			},
+			err: ErrNoModelOptions,
+		},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			model, err := f.Model()
+			require.Nil(t, err)
+			orig := model.Changepoints()
+
+			err = td.edit(&model)
+			if td.err != nil {
+				assert.ErrorIs(t, err, td.err)
+				if model.Options != nil {
+					assert.Equal(t, orig, model.Changepoints())
+				}
+				return
+			}
+			require.Nil(t, err)
+			chpts := model.Changepoints()
+			require.Len(t, chpts, len(orig))
+			for i := range chpts {
+				if chpts[i].T.Equal(orig[i].T) {
+					continue
+				}
+				assert.Equal(t, time.Hour, orig[i].T.Sub(chpts[i].T).Abs())
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package forecast
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/errs"
+	"github.com/aouyang1/go-forecaster/feature"
+	"github.com/aouyang1/go-forecaster/forecast/options"
+	"gonum.org/v1/gonum/stat"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+var (
+	ErrUnknownEvent          = errs.NewConfigError(errs.CodeUnknownFeature, "event is not configured in the forecast", nil)
+	ErrInsufficientEventData = errs.NewDataError(errs.CodeInsufficientData, "need at least 2 observed points during the event", nil)
+)
+
+// EventEffect compares the realized lift of an event in newly observed actuals against the effect
+// predicted by the model. The expected effect is the mean contribution of the event, its event
+// seasonality features, and its growth and changepoint interactions over the observed event points and
+// the realized effect is the mean of the actuals minus the prediction without the event. The difference
+// is tested against zero with a two sided t-test on the per point errors during the event.
+type EventEffect struct {
+	Event          string  `json:"event"`
+	NumPoints      int     `json:"num_points"`
+	ExpectedEffect float64 `json:"expected_effect"`
+	RealizedEffect float64 `json:"realized_effect"`
+	Difference     float64 `json:"difference"`
+	StdErr         float64 `json:"std_err"`
+	TStat          float64 `json:"t_stat"`
+	PValue         float64 `json:"p_value"`
+}
+
+// Significant returns true if the realized effect differs from the expected effect at the significance
+// level alpha
+func (e EventEffect) Significant(alpha float64) bool {
+	return e.PValue < alpha
+}
+
+// EventEffect reports the realized against expected effect of the named event or lagged event over the
+// input actuals. Only points where the event mask is non-zero and the actual is observed are used.
+func (f *Forecast) EventEffect(name string, t []time.Time, y []float64) (*EventEffect, error) {
+	if f == nil {
+		return nil, ErrUninitializedForecast
+	}
+	if !f.trained {
+		return nil, ErrUntrainedForecast
+	}
+	if len(t) != len(y) {
+		return nil, fmt.Errorf("got %d times and %d values, %w", len(t), len(y), ErrMismatchedDataLen)
+	}
+	if !f.hasEvent(name) {
+		return nil, fmt.Errorf("%q, %w", name, ErrUnknownEvent)
+	}
+	if len(t) < 2 {
+		return nil, fmt.Errorf("%q, %w", name, ErrInsufficientEventData)
+	}
+
+	eventName := strings.ReplaceAll(name, " ", "_")
+	eFeat := f.opt.GenerateEventFeatures(f.opt.DSTOptions.AdjustTime(t))
+	mask, _ := eFeat.Get(feature.NewEvent(eventName))
+
+	predicted, _, err := f.Predict(t)
+	if err != nil {
+		return nil, err
+	}
+
+	// event feature and the event seasonality features of every seasonality config
+	eventLabels := map[string]struct{}{
+		feature.NewEvent(eventName).String(): {},
+	}
+	for _, seasCfg := range f.opt.SeasonalityOptions.SeasonalityConfigs {
+		for _, fcomp := range []feature.FourierComp{feature.FourierCompSin, feature.FourierCompCos} {
+			for order := 1; order <= seasCfg.Orders; order++ {
+				eventLabels[feature.NewSeasonality(options.EventSeasonalityFeatureName(name, seasCfg.Name), fcomp, order).String()] = struct{}{}
+			}
+		}
+	}
+	eventLabels[feature.NewEvent(options.EventGrowthFeatureName(eventName)).String()] = struct{}{}
+	for _, chpt := range f.opt.ChangepointOptions.Changepoints {
+		eventLabels[feature.NewEvent(options.EventChangepointFeatureName(eventName, chpt.Name)).String()] = struct{}{}
+	}
+	x, err := f.generateFeatures(t, nil)
+	if err != nil {
+		return nil, err
+	}
+	eventFeatureSet := feature.NewSet()
+	for _, label := range x.Labels() {
+		if _, exists := eventLabels[label.String()]; !exists {
+			continue
+		}
+		data, _ := x.Get(label)
+		eventFeatureSet.Set(label, data)
+	}
+	expected, err := f.runInference(eventFeatureSet, false, len(t))
+	if err != nil {
+		return nil, fmt.Errorf("unable to run inference for event, %w", err)
+	}
+
+	var expectedSum, realizedSum float64
+	diffs := make([]float64, 0, len(t))
+	for i := range t {
+		if len(mask) == 0 || mask[i] == 0 || math.IsNaN(y[i]) {
+			continue
+		}
+		expectedSum += expected[i]
+		realizedSum += y[i] - (predicted[i] - expected[i])
+		diffs = append(diffs, y[i]-predicted[i])
+	}
+	n := len(diffs)
+	if n < 2 {
+		return nil, fmt.Errorf("%q has %d observed points, %w", name, n, ErrInsufficientEventData)
+	}
+
+	mean, std := stat.MeanStdDev(diffs, nil)
+	res := &EventEffect{
+		Event:          name,
+		NumPoints:      n,
+		ExpectedEffect: expectedSum / float64(n),
+		RealizedEffect: realizedSum / float64(n),
+		Difference:     mean,
+		StdErr:         std / math.Sqrt(float64(n)),
+		PValue:         1.0,
+	}
+	switch {
+	case res.StdErr > 0:
+		res.TStat = mean / res.StdErr
+		dist := distuv.StudentsT{Mu: 0, Sigma: 1, Nu: float64(n - 1)}
+		res.PValue = 2.0 * dist.Survival(math.Abs(res.TStat))
+	case mean != 0:
+		// every point deviates identically from the expectation
+		res.TStat = math.Copysign(math.Inf(1), mean)
+		res.PValue = 0
+	}
+	return res, nil
+}
+
+func (f *Forecast) hasEvent(name string) bool {
+	if f.opt == nil {
+		return false
+	}
+	for _, ev := range f.opt.EventOptions.Events {
+		if ev.Name == name {
+			return true
+		}
+		for _, lag := range ev.Lags {
+			if lag != 0 && options.LaggedEventName(ev.Name, lag) == name {
+				return true
+			}
+		}
+	}
+	return false
+}
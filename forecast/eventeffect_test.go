@@ -0,0 +1,75 @@
+package forecast
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/forecast/options"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventEffect(t *testing.T) {
+	ct := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	genT := func(start time.Time, n int) []time.Time {
+		tWin := make([]time.Time, 0, n)
+		for i := 0; i < n; i++ {
+			tWin = append(tWin, start.Add(time.Duration(i)*time.Hour))
+		}
+		return tWin
+	}
+	genY := func(tWin []time.Time, lift float64) []float64 {
+		y := make([]float64, len(tWin))
+		for i, tPnt := range tWin {
+			y[i] = 1.0
+			if tPnt.Hour() >= 2 && tPnt.Hour() < 6 {
+				// alternate around the lift so the errors have spread
+				y[i] += lift + 0.1*float64(2*(i%2)-1)
+			}
+		}
+		return y
+	}
+	tTrain := genT(ct, 5*24)
+
+	opt := options.NewDefaultOptions()
+	opt.SeasonalityOptions.SeasonalityConfigs = nil
+	opt.EventOptions.AutoExpand = true
+	opt.EventOptions.Events = []options.Event{
+		options.NewRecurringEvent("maint", ct.Add(2*time.Hour), ct.Add(6*time.Hour), 24*time.Hour, tTrain[len(tTrain)-1]),
+	}
+	f, err := New(opt)
+	require.Nil(t, err)
+	require.Nil(t, f.Fit(tTrain, genY(tTrain, 4.0)))
+
+	tNew := genT(ct.Add(5*24*time.Hour), 2*24)
+
+	testData := map[string]struct {
+		name        string
+		lift        float64
+		significant bool
+		err         error
+	}{
+		"as expected":   {name: "maint", lift: 4.0},
+		"larger lift":   {name: "maint", lift: 8.0, significant: true},
+		"unknown event": {name: "unknown", err: ErrUnknownEvent},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			effect, err := f.EventEffect(td.name, tNew, genY(tNew, td.lift))
+			if td.err != nil {
+				assert.ErrorIs(t, err, td.err)
+				return
+			}
+			require.Nil(t, err)
+			assert.Equal(t, 8, effect.NumPoints)
+			assert.InDelta(t, 4.0, effect.ExpectedEffect, 1e-2)
+			assert.InDelta(t, td.lift, effect.RealizedEffect, 1e-2)
+			assert.InDelta(t, td.lift-4.0, effect.Difference, 1e-2)
+			assert.Equal(t, td.significant, effect.Significant(0.05))
+		})
+	}
+
+	_, err = f.EventEffect("maint", tNew[6:20], genY(tNew[6:20], 4.0))
+	assert.ErrorIs(t, err, ErrInsufficientEventData)
+}
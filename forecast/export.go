@@ -0,0 +1,135 @@
+package forecast
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/errs"
+	"github.com/aouyang1/go-forecaster/feature"
+)
+
+var (
+	ErrUnknownExportFormat = errs.NewConfigError(errs.CodeInvalidOption, "unknown training matrix export format", nil)
+	ErrNoTrainingData      = errs.NewDataError(errs.CodeNoData, "no training matrix retained by the forecast, enable RetainTrainingMatrix in the options", nil)
+)
+
+// ExportFormat is the file format of an exported training matrix
+type ExportFormat string
+
+const (
+	// ExportFormatCSV writes a header row of time, intercept, feature labels, and target followed by one
+	// row per observation
+	ExportFormatCSV ExportFormat = "csv"
+
+	// ExportFormatLibSVM writes one sparse row per observation as "<target> <index>:<value>..." where
+	// index 1 is the intercept and the following indexes are the feature labels in sorted order. The
+	// name of every index is written by ExportTrainingFeatureMap.
+	ExportFormatLibSVM ExportFormat = "libsvm"
+)
+
+// trainingMatrix is the design matrix and target of the observed training data of a fit
+type trainingMatrix struct {
+	t       []time.Time
+	y       []float64
+	labels  []feature.Feature
+	columns [][]float64
+}
+
+func newTrainingMatrix(t []time.Time, y []float64, x *feature.Set) *trainingMatrix {
+	labels := x.Labels()
+	columns := make([][]float64, len(labels))
+	for j, label := range labels {
+		columns[j], _ = x.Get(label)
+	}
+	return &trainingMatrix{t: t, y: y, labels: labels, columns: columns}
+}
+
+// retainedTrainingMatrix returns the training matrix of the fit or an error if it was not retained
+func (f *Forecast) retainedTrainingMatrix() (*trainingMatrix, error) {
+	if f == nil {
+		return nil, ErrUninitializedForecast
+	}
+	if !f.trained {
+		return nil, ErrUntrainedForecast
+	}
+	if f.trainingMatrix == nil || len(f.trainingMatrix.t) == 0 {
+		return nil, ErrNoTrainingData
+	}
+	return f.trainingMatrix, nil
+}
+
+// ExportTrainingMatrix writes the design matrix and target of the observed training data so the
+// identical features can be inspected or used to train models with external tooling. The columns are
+// the intercept and every feature generated for the fit including those the regression dropped.
+// Features removed as redundant are reported by RedundantFeatures instead. The matrix is only retained
+// if RetainTrainingMatrix is set in the options, and forecasts fit from a stream or loaded from a model
+// never retain it.
+func (f *Forecast) ExportTrainingMatrix(w io.Writer, format ExportFormat) error {
+	if format != ExportFormatCSV && format != ExportFormatLibSVM {
+		return fmt.Errorf("%q, %w", format, ErrUnknownExportFormat)
+	}
+	m, err := f.retainedTrainingMatrix()
+	if err != nil {
+		return err
+	}
+
+	if format == ExportFormatCSV {
+		cw := csv.NewWriter(w)
+		header := make([]string, 0, len(m.labels)+3)
+		header = append(header, "time", "intercept")
+		for _, label := range m.labels {
+			header = append(header, label.String())
+		}
+		header = append(header, "y")
+		if err := cw.Write(header); err != nil {
+			return err
+		}
+
+		row := make([]string, len(header))
+		for i := range m.t {
+			row[0] = m.t[i].Format(time.RFC3339Nano)
+			row[1] = "1"
+			for j, col := range m.columns {
+				row[j+2] = strconv.FormatFloat(col[i], 'g', -1, 64)
+			}
+			row[len(row)-1] = strconv.FormatFloat(m.y[i], 'g', -1, 64)
+			if err := cw.Write(row); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+
+	bw := bufio.NewWriter(w)
+	for i := range m.t {
+		fmt.Fprintf(bw, "%s 1:1", strconv.FormatFloat(m.y[i], 'g', -1, 64))
+		for j, col := range m.columns {
+			if col[i] == 0 {
+				continue
+			}
+			fmt.Fprintf(bw, " %d:%s", j+2, strconv.FormatFloat(col[i], 'g', -1, 64))
+		}
+		fmt.Fprintln(bw)
+	}
+	return bw.Flush()
+}
+
+// ExportTrainingFeatureMap writes the libsvm index and name of every column of the exported training
+// matrix one per line as "<index>\t<name>\tq" which is the feature map format of XGBoost
+func (f *Forecast) ExportTrainingFeatureMap(w io.Writer) error {
+	m, err := f.retainedTrainingMatrix()
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "1\tintercept\tq\n")
+	for j, label := range m.labels {
+		fmt.Fprintf(bw, "%d\t%s\tq\n", j+2, label.String())
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,145 @@
+package forecast
+
+import (
+	"bytes"
+	"encoding/csv"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/forecast/options"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testFitRetainedSignal(t *testing.T) (*Forecast, []time.Time, []float64) {
+	minutes := 7 * 24 * 60
+	ct := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tWin := make([]time.Time, minutes)
+	y := make([]float64, minutes)
+	for i := range tWin {
+		tWin[i] = ct.Add(time.Duration(i) * time.Minute)
+		y[i] = 7.9 + 4.3*math.Sin(2.0*math.Pi/86400.0*float64(tWin[i].Unix()))
+	}
+
+	opt := &options.Options{
+		SeasonalityOptions: options.SeasonalityOptions{
+			SeasonalityConfigs: []options.SeasonalityConfig{
+				options.NewDailySeasonalityConfig(3),
+			},
+		},
+		RetainTrainingMatrix: true,
+	}
+	f, err := New(opt)
+	require.Nil(t, err)
+	require.Nil(t, f.Fit(tWin, y))
+	return f, tWin, y
+}
+
+func TestExportTrainingMatrix(t *testing.T) {
+	f, tWin, y := testFitRetainedSignal(t)
+
+	modelLabels, err := f.FeatureLabels()
+	require.Nil(t, err)
+	coef, err := f.Coefficients()
+	require.Nil(t, err)
+
+	labels := f.trainingMatrix.labels
+	labelNames := make([]string, len(labels))
+	for i, label := range labels {
+		labelNames[i] = label.String()
+	}
+
+	// every generated feature is exported including those dropped by the fit
+	require.Greater(t, len(labels), len(modelLabels))
+	for _, label := range modelLabels {
+		assert.Contains(t, labelNames, label.String())
+	}
+
+	t.Run("csv", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.Nil(t, f.ExportTrainingMatrix(&buf, ExportFormatCSV))
+
+		records, err := csv.NewReader(&buf).ReadAll()
+		require.Nil(t, err)
+		require.Len(t, records, len(tWin)+1)
+
+		header := records[0]
+		require.Len(t, header, len(labels)+3)
+		assert.Equal(t, "time", header[0])
+		assert.Equal(t, "intercept", header[1])
+		assert.Equal(t, "y", header[len(header)-1])
+
+		// the exported features reproduce the model fit
+		for _, row := range records[1:10] {
+			predicted := f.Intercept()
+			for j, name := range labelNames {
+				assert.Equal(t, name, header[j+2])
+				val, err := strconv.ParseFloat(row[j+2], 64)
+				require.Nil(t, err)
+				predicted += coef[name] * val
+			}
+			actual, err := strconv.ParseFloat(row[len(row)-1], 64)
+			require.Nil(t, err)
+			assert.InDelta(t, actual, predicted, 1e-2)
+		}
+	})
+
+	t.Run("libsvm", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.Nil(t, f.ExportTrainingMatrix(&buf, ExportFormatLibSVM))
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		require.Len(t, lines, len(tWin))
+		fields := strings.Fields(lines[0])
+		target, err := strconv.ParseFloat(fields[0], 64)
+		require.Nil(t, err)
+		assert.Equal(t, y[0], target)
+		assert.Equal(t, "1:1", fields[1])
+		assert.LessOrEqual(t, len(fields), len(labels)+2)
+	})
+
+	t.Run("feature map", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.Nil(t, f.ExportTrainingFeatureMap(&buf))
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		require.Len(t, lines, len(labels)+1)
+		assert.Equal(t, "1\tintercept\tq", lines[0])
+		for j, name := range labelNames {
+			assert.Equal(t, strconv.Itoa(j+2)+"\t"+name+"\tq", lines[j+1])
+		}
+	})
+
+	t.Run("not retained", func(t *testing.T) {
+		fNotRetained, _, _ := testFitSignal(t)
+		var buf bytes.Buffer
+		assert.ErrorIs(t, fNotRetained.ExportTrainingMatrix(&buf, ExportFormatCSV), ErrNoTrainingData)
+		assert.ErrorIs(t, fNotRetained.ExportTrainingFeatureMap(&buf), ErrNoTrainingData)
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.ErrorIs(t, f.ExportTrainingMatrix(&buf, "parquet"), ErrUnknownExportFormat)
+	})
+
+	t.Run("from model", func(t *testing.T) {
+		model, err := f.Model()
+		require.Nil(t, err)
+		fNew, err := NewFromModel(model)
+		require.Nil(t, err)
+
+		var buf bytes.Buffer
+		assert.ErrorIs(t, fNew.ExportTrainingMatrix(&buf, ExportFormatCSV), ErrNoTrainingData)
+	})
+
+	t.Run("untrained", func(t *testing.T) {
+		fNew, err := New(nil)
+		require.Nil(t, err)
+
+		var buf bytes.Buffer
+		assert.ErrorIs(t, fNew.ExportTrainingMatrix(&buf, ExportFormatCSV), ErrUntrainedForecast)
+	})
+}
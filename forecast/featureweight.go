@@ -0,0 +1,209 @@
+package forecast
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aouyang1/go-forecaster/errs"
+	"github.com/aouyang1/go-forecaster/feature"
+)
+
+var ErrInvalidFeatureWeight = errs.NewDataError(errs.CodeInvalidModel, "invalid feature weight", nil)
+
+// featureWeightJSON is the serialized feature weight as a tagged union where only the field named by
+// the type is set and holds the labels of the feature e.g.
+//
+//	{"type": "seasonality", "seasonality": {"name": "daily", "fourier_component": "sin", "order": 1}, "value": 1.2}
+type featureWeightJSON struct {
+	Type        feature.FeatureType `json:"type"`
+	Changepoint *changepointJSON    `json:"changepoint,omitempty"`
+	Seasonality *seasonalityJSON    `json:"seasonality,omitempty"`
+	Event       *namedFeatureJSON   `json:"event,omitempty"`
+	Time        *namedFeatureJSON   `json:"time,omitempty"`
+	Regressor   *namedFeatureJSON   `json:"regressor,omitempty"`
+	Value       float64             `json:"value"`
+	StdErr      float64             `json:"std_err,omitempty"`
+	Lower       float64             `json:"lower,omitempty"`
+	Upper       float64             `json:"upper,omitempty"`
+}
+
+type changepointJSON struct {
+	Name            string                  `json:"name"`
+	ChangepointComp feature.ChangepointComp `json:"changepoint_component"`
+}
+
+type seasonalityJSON struct {
+	Name        string              `json:"name"`
+	FourierComp feature.FourierComp `json:"fourier_component"`
+	Order       int                 `json:"order"`
+}
+
+type namedFeatureJSON struct {
+	Name string `json:"name"`
+}
+
+// legacyFeatureWeightJSON is the untyped labels format of feature weights stored by earlier models.
+// It is still accepted when loading a model and is rewritten as a tagged union when saved again.
+type legacyFeatureWeightJSON struct {
+	Labels map[string]string   `json:"labels"`
+	Type   feature.FeatureType `json:"type"`
+	Value  float64             `json:"value"`
+}
+
+// MarshalJSON writes the feature weight as a tagged union of the feature type
+func (fw FeatureWeight) MarshalJSON() ([]byte, error) {
+	feat, err := fw.ToFeature()
+	if err != nil {
+		return nil, err
+	}
+	if err := validateFeature(feat); err != nil {
+		return nil, err
+	}
+
+	out := featureWeightJSON{
+		Type:   fw.Type,
+		Value:  fw.Value,
+		StdErr: fw.StdErr,
+		Lower:  fw.Lower,
+		Upper:  fw.Upper,
+	}
+	switch f := feat.(type) {
+	case *feature.Changepoint:
+		out.Changepoint = &changepointJSON{Name: f.Name, ChangepointComp: f.ChangepointComp}
+	case *feature.Seasonality:
+		out.Seasonality = &seasonalityJSON{Name: f.Name, FourierComp: f.FourierComp, Order: f.Order}
+	case *feature.Event:
+		out.Event = &namedFeatureJSON{Name: f.Name}
+	case *feature.Time:
+		out.Time = &namedFeatureJSON{Name: f.Name}
+	case *feature.Regressor:
+		out.Regressor = &namedFeatureJSON{Name: f.Name}
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON strictly parses a tagged union feature weight rejecting unknown fields, payloads not
+// matching the type, and invalid labels so that corrupt models fail at load time. Feature weights in
+// the legacy labels format are migrated if every label is recognized.
+func (fw *FeatureWeight) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	var feat feature.Feature
+	var val float64
+	var interval featureWeightJSON
+	var err error
+	if _, legacy := fields["labels"]; legacy {
+		feat, val, err = decodeLegacyFeatureWeight(data)
+	} else {
+		feat, interval, err = decodeFeatureWeight(data)
+		val = interval.Value
+	}
+	if err != nil {
+		return err
+	}
+	if err := validateFeature(feat); err != nil {
+		return err
+	}
+	*fw = NewFeatureWeight(feat, val)
+	fw.StdErr, fw.Lower, fw.Upper = interval.StdErr, interval.Lower, interval.Upper
+	return nil
+}
+
+// decodeStrict unmarshals the data into v failing on any unknown field
+func decodeStrict(data []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("%s, %w", err.Error(), ErrInvalidFeatureWeight)
+	}
+	return nil
+}
+
+// decodeFeatureWeight returns the feature along with the parsed json holding its value and interval
+func decodeFeatureWeight(data []byte) (feature.Feature, featureWeightJSON, error) {
+	var in featureWeightJSON
+	if err := decodeStrict(data, &in); err != nil {
+		return nil, featureWeightJSON{}, err
+	}
+
+	var numPayloads int
+	for _, set := range []bool{in.Changepoint != nil, in.Seasonality != nil, in.Event != nil, in.Time != nil, in.Regressor != nil} {
+		if set {
+			numPayloads++
+		}
+	}
+	if numPayloads != 1 {
+		return nil, featureWeightJSON{}, fmt.Errorf("%d feature payloads for type %q, %w", numPayloads, in.Type, ErrInvalidFeatureWeight)
+	}
+
+	var feat feature.Feature
+	switch {
+	case in.Type == feature.FeatureTypeChangepoint && in.Changepoint != nil:
+		feat = feature.NewChangepoint(in.Changepoint.Name, in.Changepoint.ChangepointComp)
+	case in.Type == feature.FeatureTypeSeasonality && in.Seasonality != nil:
+		feat = feature.NewSeasonality(in.Seasonality.Name, in.Seasonality.FourierComp, in.Seasonality.Order)
+	case in.Type == feature.FeatureTypeEvent && in.Event != nil:
+		feat = feature.NewEvent(in.Event.Name)
+	case in.Type == feature.FeatureTypeTime && in.Time != nil:
+		feat = feature.NewTime(in.Time.Name)
+	case in.Type == feature.FeatureTypeRegressor && in.Regressor != nil:
+		feat = feature.NewRegressor(in.Regressor.Name)
+	default:
+		return nil, featureWeightJSON{}, fmt.Errorf("feature payload does not match type %q, %w", in.Type, ErrInvalidFeatureWeight)
+	}
+	return feat, in, nil
+}
+
+func decodeLegacyFeatureWeight(data []byte) (feature.Feature, float64, error) {
+	var in legacyFeatureWeightJSON
+	if err := decodeStrict(data, &in); err != nil {
+		return nil, 0, err
+	}
+	legacy := FeatureWeight{Labels: in.Labels, Type: in.Type}
+	feat, err := legacy.ToFeature()
+	if err != nil {
+		return nil, 0, fmt.Errorf("legacy feature weight of type %q, %w", in.Type, err)
+	}
+	for label := range in.Labels {
+		if _, exists := feat.Get(label); !exists {
+			return nil, 0, fmt.Errorf("unknown label %q for legacy feature weight of type %q, %w", label, in.Type, ErrInvalidFeatureWeight)
+		}
+	}
+	return feat, in.Value, nil
+}
+
+// validateFeature checks the labels of a feature are complete and within their allowed values
+func validateFeature(feat feature.Feature) error {
+	var name string
+	switch f := feat.(type) {
+	case *feature.Changepoint:
+		name = f.Name
+		if f.ChangepointComp != feature.ChangepointCompBias && f.ChangepointComp != feature.ChangepointCompSlope {
+			return fmt.Errorf("changepoint component %q of %q, %w", f.ChangepointComp, f.Name, ErrInvalidFeatureWeight)
+		}
+	case *feature.Seasonality:
+		name = f.Name
+		if f.FourierComp != feature.FourierCompSin && f.FourierComp != feature.FourierCompCos {
+			return fmt.Errorf("fourier component %q of %q, %w", f.FourierComp, f.Name, ErrInvalidFeatureWeight)
+		}
+		if f.Order < 1 {
+			return fmt.Errorf("fourier order %d of %q, %w", f.Order, f.Name, ErrInvalidFeatureWeight)
+		}
+	case *feature.Event:
+		name = f.Name
+	case *feature.Time:
+		name = f.Name
+	case *feature.Regressor:
+		name = f.Name
+	default:
+		return ErrUnknownFeatureType
+	}
+	if name == "" {
+		return fmt.Errorf("empty name for feature of type %q, %w", feat.Type(), ErrInvalidFeatureWeight)
+	}
+	return nil
+}
@@ -1,10 +1,15 @@
 package forecast
 
 import (
-	"errors"
+	"context"
 	"fmt"
+	"log/slog"
+	"math"
+	"slices"
+	"strings"
 	"time"
 
+	"github.com/aouyang1/go-forecaster/errs"
 	"github.com/aouyang1/go-forecaster/feature"
 	"github.com/aouyang1/go-forecaster/forecast/options"
 	"github.com/aouyang1/go-forecaster/models"
@@ -15,13 +20,13 @@ import (
 )
 
 var (
-	ErrUninitializedForecast    = errors.New("uninitialized forecast")
-	ErrInsufficientTrainingData = errors.New("insufficient training data after removing Nans")
-	ErrLabelExists              = errors.New("label already exists in TimeDataset")
-	ErrMismatchedDataLen        = errors.New("input data has different length than time")
-	ErrFeatureLabelsInitialized = errors.New("feature labels already initialized")
-	ErrNoModelCoefficients      = errors.New("no model coefficients from fit")
-	ErrUntrainedForecast        = errors.New("forecast has not been trained yet")
+	ErrUninitializedForecast    = errs.NewPredictError(errs.CodeUninitialized, "uninitialized forecast", nil)
+	ErrInsufficientTrainingData = errs.NewDataError(errs.CodeInsufficientData, "insufficient training data after removing Nans", nil)
+	ErrLabelExists              = errs.NewDataError(errs.CodeDuplicateLabel, "label already exists in TimeDataset", nil)
+	ErrMismatchedDataLen        = errs.NewDataError(errs.CodeLengthMismatch, "input data has different length than time", nil)
+	ErrFeatureLabelsInitialized = errs.NewFitError(errs.CodeFitFailed, "feature labels already initialized", nil)
+	ErrNoModelCoefficients      = errs.NewPredictError(errs.CodeNoModelCoefficient, "no model coefficients from fit", nil)
+	ErrUntrainedForecast        = errs.NewPredictError(errs.CodeUntrained, "forecast has not been trained yet", nil)
 )
 
 // Forecast represents a single forecast model of a time series. This is a linear model using
@@ -39,6 +44,21 @@ type Forecast struct {
 	featureWeights []FeatureWeight
 	intercept      float64
 	trained        bool
+
+	chptSensitivity   []ChangepointSensitivity
+	fastPath          options.FastPath
+	redundantFeatures []options.RedundantFeature
+	coefPath          *CoefficientPath
+
+	// design matrix and target of the fit retained only if exporting is enabled in the options
+	trainingMatrix     *trainingMatrix
+	trainingRegressors Regressors
+
+	// inverse variance weights of the observed training data normalized to a mean of 1.0, nil if unweighted
+	weights []float64
+
+	// primes the lasso with the coefficients of the current fit
+	warmStart bool
 }
 
 // New creates a new forecast instance withh thhe given options. If none are provided, a default
@@ -52,50 +72,47 @@ func New(opt *options.Options) (*Forecast, error) {
 }
 
 // NewFromModel creates a new forecast instance given a forecast Model to initialize. This
-// instance can be used for inference immediately and does not need to be trained again.
+// instance can be used for inference immediately and does not need to be trained again. Models of an
+// older schema version are migrated to the current version first.
 func NewFromModel(model Model) (*Forecast, error) {
+	if err := model.Migrate(); err != nil {
+		return nil, err
+	}
 	f := &Forecast{
 		opt:            model.Options,
 		trainEndTime:   model.TrainEndTime,
 		intercept:      model.Weights.Intercept,
 		featureWeights: model.Weights.Coef,
 		scores:         model.Scores,
+		fastPath:       model.FastPath,
 		trained:        true,
 	}
 	return f, nil
 }
 
-func (f *Forecast) generateFeatures(t []time.Time) (*feature.Set, error) {
+func (f *Forecast) generateFeatures(t []time.Time, r Regressors) (*feature.Set, error) {
 	if f == nil {
 		return nil, ErrUninitializedForecast
 	}
 
 	t = f.opt.DSTOptions.AdjustTime(t)
 
-	tFeat, eFeat := f.opt.GenerateTimeFeatures(t)
-
-	feat, err := f.opt.GenerateFourierFeatures(tFeat)
-	if err != nil {
-		return nil, err
-	}
-	feat.Update(eFeat)
-
-	// do not include weekly fourier features if time range is less than 1 week
-	if !f.trained && t[len(t)-1].Sub(t[0]) < time.Duration(7*24*time.Hour) {
-		for _, f := range feat.Labels() {
-			if val, _ := f.Get("name"); val == options.LabelSeasWeekly {
-				feat.Del(f)
-			}
-		}
+	var dropSeas []string
+	if !f.trained {
+		dropSeas = droppedSeasonalities(t[0], t[len(t)-1])
 	}
 
-	// generate changepoint features
 	if !f.trained {
 		f.opt.ChangepointOptions.GenerateAutoChangepoints(t)
+		f.opt.ChangepointOptions.GenerateSmoothChangepoints(t)
 	}
-	chptFeat := f.opt.ChangepointOptions.GenerateFeatures(t, f.trainEndTime)
-	feat.Update(chptFeat)
 
+	feat, err := f.generateUnprunedFeatures(t, dropSeas)
+	if err != nil {
+		return nil, err
+	}
+
+	addRegressorFeatures(feat, r)
 	feat.RemoveZeroOnlyFeatures()
 
 	if !f.trained {
@@ -121,17 +138,127 @@ func (f *Forecast) generateFeatures(t []time.Time) (*feature.Set, error) {
 	return feat, nil
 }
 
+// droppedSeasonalities returns the names of the seasonalities that cannot be fit over the training
+// range. Weekly fourier features are dropped if the range is less than 1 week, monthly fourier
+// features if less than 1 calendar month, and yearly fourier features if less than 1 calendar year.
+func droppedSeasonalities(start, end time.Time) []string {
+	var names []string
+	if end.Sub(start) < time.Duration(7*24*time.Hour) {
+		names = append(names, options.LabelSeasWeekly)
+	}
+	if end.Before(start.AddDate(0, 1, 0)) {
+		names = append(names, options.LabelSeasMonthly)
+	}
+	if end.Before(start.AddDate(1, 0, 0)) {
+		names = append(names, options.LabelSeasYearly)
+	}
+	return names
+}
+
+// generateUnprunedFeatures builds the seasonal, event, and changepoint features for DST adjusted
+// times without removing zero only features so that every chunk of a streamed fit has the same columns.
+func (f *Forecast) generateUnprunedFeatures(t []time.Time, dropSeas []string) (*feature.Set, error) {
+	tFeat, eFeat := f.opt.GenerateTimeFeatures(t)
+
+	feat, err := f.opt.GenerateFourierFeatures(tFeat)
+	if err != nil {
+		return nil, err
+	}
+	feat.Update(f.opt.SeasonalityOptions.GenerateTrendInteractions(t, feat, f.trainEndTime))
+	feat.Update(eFeat)
+
+	for _, name := range dropSeas {
+		// seasonality features are named by the time feature and may be suffixed by the trend interaction
+		seasName := options.SeasonalityFeatureName(name)
+		for _, f := range feat.Labels() {
+			if val, _ := f.Get("name"); val == seasName || strings.HasPrefix(val, seasName+"_") {
+				feat.Del(f)
+			}
+		}
+	}
+
+	var customFeat *feature.Set
+	if f.trained {
+		// missing custom features are filled by the prediction according to their missing policy
+		customFeat, _, err = f.opt.GenerateAvailableCustomFeatures(t)
+	} else {
+		customFeat, err = f.opt.GenerateCustomFeatures(t)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := options.CheckFeatureCollisions(feat, customFeat); err != nil {
+		return nil, err
+	}
+	feat.Update(customFeat)
+
+	// generate changepoint features
+	chptFeat := f.opt.ChangepointOptions.GenerateFeatures(t, f.trainEndTime)
+	if err := options.CheckFeatureCollisions(chptFeat, customFeat); err != nil {
+		return nil, err
+	}
+	feat.Update(chptFeat)
+	feat.Update(f.opt.EventOptions.Interactions.Generate(t, eFeat, chptFeat, f.opt.ChangepointOptions.Changepoints, f.trainEndTime))
+	return feat, nil
+}
+
 // Fit takes the input training data and fits a forecast model for possible changepoints,
 // seasonal components, and intercept
 func (f *Forecast) Fit(t []time.Time, y []float64) error {
+	return f.FitCtx(context.Background(), t, y)
+}
+
+// FitCtx fits the forecast model like Fit returning the context error if the context is done before
+// the fit completes
+func (f *Forecast) FitCtx(ctx context.Context, t []time.Time, y []float64) error {
+	return f.fit(ctx, t, y, nil, nil)
+}
+
+func (f *Forecast) fit(ctx context.Context, t []time.Time, y, weights []float64, r Regressors) error {
 	if f == nil {
 		return ErrUninitializedForecast
 	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 
 	trainingData, err := timedataset.NewUnivariateDataset(t, y)
 	if err != nil {
 		return err
 	}
+	// predictions lag the regressors themselves so the training predictions take the input regressors
+	inputRegressors := r
+	if r, err = r.withLags(t, f.opt.RegressorLags); err != nil {
+		return err
+	}
+
+	if err := f.opt.ChangepointOptions.ResolveAnchors(f.opt.EventOptions.Events); err != nil {
+		return err
+	}
+	if err := f.opt.EventOptions.Holidays.Validate(); err != nil {
+		return err
+	}
+	if err := f.opt.EventOptions.CheckEventNameCollisions(); err != nil {
+		return err
+	}
+
+	// the event masks of every prediction are padded at the sampling interval of the training data so
+	// predictions of a single time keep their weekend and event features
+	f.opt.SamplingInterval = 0
+	if freq, err := timedataset.TimeSlice(t).EstimateFreq(); err == nil {
+		f.opt.SamplingInterval = freq
+	}
+
+	// structural zeros are excluded from the fit as if they were never observed
+	f.opt.DetectStructuralZeros(t, y)
+	if mask := f.opt.StructuralZeroMask(t); mask != nil {
+		y = maskStructuralZeros(y, mask)
+		copy(trainingData.Y, y)
+	}
+	if len(r) > 0 {
+		y = r.maskMissing(y)
+		copy(trainingData.Y, y)
+	}
 
 	// remove any NaNs from training set
 	trainingDataFiltered := trainingData.DropNan()
@@ -141,26 +268,157 @@ func (f *Forecast) Fit(t []time.Time, y []float64) error {
 	}
 
 	f.trainEndTime = timedataset.TimeSlice(trainingT).EndTime()
+	trainingY := trainingDataFiltered.Y
+	f.trainingMatrix = nil
+	f.trainingRegressors = r.observed(y)
+	f.weights = observedWeights(y, weights)
+
+	if err := f.opt.SeasonalityOptions.DetectSeasonality(trainingT, trainingY); err != nil {
+		return err
+	}
+	if err := f.opt.SelectSeasonalityOrders(trainingT, trainingY); err != nil {
+		return err
+	}
+	if !f.trained {
+		if err := f.opt.ChangepointOptions.DetectChangepoints(f.opt.DSTOptions.AdjustTime(trainingT), trainingY); err != nil {
+			return err
+		}
+	}
+
+	f.redundantFeatures = nil
+	f.coefPath = nil
+	// fast path detection is unweighted and has no regressors so those fits always regress on the features
+	fastPath, intercept, slope := options.FastPathNone, 0.0, 0.0
+	if f.weights == nil && len(r) == 0 {
+		fastPath, intercept, slope = f.opt.FastPathOptions.Detect(trainingT, trainingY)
+	}
+	if fastPath == options.FastPathNone {
+		if err := f.fitLasso(ctx, trainingT, trainingY); err != nil {
+			return err
+		}
+	} else {
+		f.fitFastPath(fastPath, trainingT[0], intercept, slope)
+		if f.opt.RetainTrainingMatrix {
+			x, err := f.generateFeatures(trainingT, f.trainingRegressors)
+			if err != nil {
+				return err
+			}
+			f.opt.RedundancyOptions.RemoveRedundant(x)
+			f.trainingMatrix = newTrainingMatrix(trainingT, trainingY, x)
+		}
+	}
+	f.fastPath = fastPath
+
+	// use input training to include NaNs
+	predicted, comp, err := f.predict(trainingData.T, inputRegressors)
+	if err != nil {
+		return err
+	}
+	f.trainComponents = comp
+
+	scores, err := NewScoresWithBaseline(predicted, trainingData.Y, ScoreBaseline{
+		SeasonalLag: f.SeasonalLag(trainingData.T),
+	})
+	if err != nil {
+		return err
+	}
+	f.scores = scores
+
+	f.chptSensitivity = nil
+	if fastPath == options.FastPathNone {
+		f.chptSensitivity = f.changepointSensitivity(trainingData.T, trainingData.Y, predicted)
+	}
+
+	residual := make([]float64, len(trainingData.T))
+	floats.Add(residual, trainingData.Y)
+	floats.Sub(residual, predicted)
+	floats.Scale(-1.0, residual)
+	f.residual = residual
+
+	return nil
+}
+
+// SeasonalLag returns the number of points in the shortest configured seasonality period at the
+// sampling interval of the times which is the lag of the seasonal naive baseline of the fit scores. This
+// is 1 if there is no seasonality or the interval cannot be inferred.
+func (f *Forecast) SeasonalLag(t []time.Time) int {
+	var period time.Duration
+	for _, seasCfg := range f.opt.SeasonalityOptions.SeasonalityConfigs {
+		if seasCfg.Orders > 0 && seasCfg.Period > 0 && (period == 0 || seasCfg.Period < period) {
+			period = seasCfg.Period
+		}
+	}
+	if period == 0 {
+		return 1
+	}
+	freq, err := timedataset.TimeSlice(t).EstimateFreq()
+	if err != nil || freq <= 0 || period < freq {
+		return 1
+	}
+	return int(math.Round(float64(period) / float64(freq)))
+}
+
+// fitLasso generates the features of the training data and fits the coefficients with coordinate descent
+func (f *Forecast) fitLasso(ctx context.Context, trainingT []time.Time, trainingY []float64) error {
 	// generate features
-	x, err := f.generateFeatures(trainingT)
+	x, err := f.generateFeatures(trainingT, f.trainingRegressors)
 	if err != nil {
 		return err
 	}
+	f.redundantFeatures = f.opt.RedundancyOptions.RemoveRedundant(x)
+	if f.opt.RetainTrainingMatrix {
+		f.trainingMatrix = newTrainingMatrix(trainingT, trainingY, x)
+	}
 
-	trainingY := trainingDataFiltered.Y
-	features := x.Matrix(true)
-	target := mat.NewDense(len(trainingY), 1, trainingY)
+	// no features to regress on so only an intercept can be fit
+	if x.Len() == 0 {
+		f.fitFastPath(options.FastPathIntercept, trainingT[0], f.interceptOnly(trainingY), 0)
+		return nil
+	}
+
+	sparse, err := f.opt.Sparse(x.Density())
+	if err != nil {
+		return err
+	}
+	var features mat.Matrix
+	if sparse {
+		features = x.SparseMatrix(true)
+	} else {
+		features = x.Matrix(true)
+	}
+	var target mat.Matrix = mat.NewDense(len(trainingY), 1, trainingY)
+
+	// standardized features are fit and the coefficients are unscaled to the raw features
+	smoothCols := f.smoothTrendColumns(x.Labels())
+	scale := identityScale(x.Len() + 1)
+	if f.opt.StandardizeFeatures {
+		scale = newFeatureScale(features, smoothCols)
+		features = scale.transform(features)
+	}
+	if f.weights != nil {
+		features, target = weightRows(features, target, f.weights)
+	}
 
-	// run coordinate descent
-	lassoOpt := f.opt.NewLassoAutoOptions()
-	model, err := models.NewLassoAutoRegression(lassoOpt)
+	// smooth trend steps are ridge penalized through pseudo observations
+	if len(smoothCols) > 0 {
+		penalty, err := f.opt.ChangepointOptions.SmoothTrendPenalty(len(trainingY))
+		if err != nil {
+			return err
+		}
+		features, target = appendRidgeRows(features, target, smoothCols, penalty)
+	}
+
+	// run the penalized regression
+	_, numCols := features.Dims()
+	model, err := f.newRegressionModel(smoothCols, numCols, scale.rescale(f.warmStartBeta(x.Labels())))
 	if err != nil {
 		return err
 	}
-	if err := model.Fit(features, target); err != nil {
+	if err := fitModel(ctx, model, features, target); err != nil {
 		return err
 	}
-	coef := model.Coef()
+	f.coefPath = newCoefficientPath(x.Labels(), scale.unscalePath(regressionPath(model)))
+	coef := scale.unscale(model.Coef())
 	intercept := 0.0
 	if len(coef) > 0 {
 		intercept = coef[0]
@@ -179,26 +437,68 @@ func (f *Forecast) Fit(t []time.Time, y []float64) error {
 	f.featureWeights = relevantFws
 	f.opt.ChangepointOptions.Changepoints = relevantChpts
 
-	// use input training to include NaNs
-	predicted, comp, err := f.Predict(trainingData.T)
-	if err != nil {
-		return err
+	if f.opt.CoefficientErrors.Enabled {
+		if err := f.estimateCoefficientErrors(ctx, x.Labels(), features, target, len(trainingY), model, smoothCols, scale); err != nil {
+			return fmt.Errorf("unable to estimate coefficient errors, %w", err)
+		}
 	}
-	f.trainComponents = comp
+	return nil
+}
 
-	scores, err := NewScores(predicted, trainingData.Y)
-	if err != nil {
+// fitModel fits the regression model stopping early once the context is done if the model supports it
+func fitModel(ctx context.Context, model models.Model, x, y mat.Matrix) error {
+	if ctxModel, ok := model.(models.ContextModel); ok {
+		return ctxModel.FitCtx(ctx, x, y)
+	}
+	if err := model.Fit(x, y); err != nil {
 		return err
 	}
-	f.scores = scores
+	return ctx.Err()
+}
 
-	residual := make([]float64, len(trainingData.T))
-	floats.Add(residual, trainingData.Y)
-	floats.Sub(residual, predicted)
-	floats.Scale(-1.0, residual)
-	f.residual = residual
+// interceptOnly returns the intercept of a fit without features which is the weighted mean or the
+// weighted quantile of the quantile regression
+func (f *Forecast) interceptOnly(y []float64) float64 {
+	if f.opt.Regression != options.RegressionQuantile {
+		return stat.Mean(y, f.weights)
+	}
+	sorted := make([]float64, len(y))
+	copy(sorted, y)
+	var weights []float64
+	if f.weights != nil {
+		weights = make([]float64, len(f.weights))
+		copy(weights, f.weights)
+		stat.SortWeighted(sorted, weights)
+	} else {
+		slices.Sort(sorted)
+	}
+	return stat.Quantile(f.opt.NewQuantileOptions().Quantile, stat.Empirical, sorted, weights)
+}
 
-	return nil
+// fitFastPath sets the model to an intercept with an optional linear trend without generating features.
+// The trend is represented as the slope of a single changepoint at the start of the training data so
+// inference follows the regular changepoint path.
+func (f *Forecast) fitFastPath(fastPath options.FastPath, start time.Time, intercept, slope float64) {
+	f.intercept = intercept
+	f.featureWeights = make([]FeatureWeight, 0, 1)
+	f.opt.ChangepointOptions.Changepoints = nil
+
+	if fastPath == options.FastPathTrend {
+		f.opt.ChangepointOptions.EnableGrowth = true
+		f.opt.ChangepointOptions.Changepoints = []options.Changepoint{
+			options.NewChangepoint(options.LabelChptTrend, start),
+		}
+
+		// slope feature scales from 0 at the changepoint to 1 at the end of training
+		f.featureWeights = append(
+			f.featureWeights,
+			NewFeatureWeight(
+				feature.NewChangepoint(options.LabelChptTrend, feature.ChangepointCompSlope),
+				slope*f.trainEndTime.Sub(start).Seconds(),
+			),
+		)
+	}
+	f.trained = true
 }
 
 // pruneDegenerateFeatures removes any feature weights that are exactly equal to 0. This can happen if the LASSO
@@ -240,7 +540,7 @@ func (f *Forecast) pruneDegenerateFeatures(labels []feature.Feature, coef []floa
 	relevantChpts := make([]options.Changepoint, 0, len(f.opt.ChangepointOptions.Changepoints))
 	for _, chpt := range f.opt.ChangepointOptions.Changepoints {
 		_, exists := relevantChptMap[chpt.Name]
-		if !exists {
+		if !exists && !hasChangepointInteraction(relevantFws, chpt.Name) {
 			continue
 		}
 		relevantChpts = append(relevantChpts, chpt)
@@ -249,19 +549,68 @@ func (f *Forecast) pruneDegenerateFeatures(labels []feature.Feature, coef []floa
 	return relevantFws, relevantChpts, nil
 }
 
+// hasChangepointInteraction returns true if any event changepoint interaction of the changepoint is
+// weighted so the changepoint is kept to regenerate the interaction at prediction
+func hasChangepointInteraction(fws []FeatureWeight, chptName string) bool {
+	for _, fw := range fws {
+		if fw.Type != feature.FeatureTypeEvent {
+			continue
+		}
+		if options.IsEventChangepointFeatureName(fw.Labels["name"], chptName) {
+			return true
+		}
+	}
+	return false
+}
+
 // Predict takes a slice of times in any order and produces the predicted value for those
 // times given a pre-trained model.
 func (f *Forecast) Predict(t []time.Time) ([]float64, Components, error) {
 	if f == nil {
 		return nil, Components{}, ErrUninitializedForecast
 	}
+	return f.predict(t, nil)
+}
 
+// PredictCtx predicts the input times like Predict returning the context error without predicting if the
+// context is already done
+func (f *Forecast) PredictCtx(ctx context.Context, t []time.Time) ([]float64, Components, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, Components{}, err
+	}
+	return f.Predict(t)
+}
+
+func (f *Forecast) predict(t []time.Time, r Regressors) ([]float64, Components, error) {
 	if !f.trained {
 		return nil, Components{}, ErrUntrainedForecast
 	}
+	r, missing, err := f.resolveMissingRegressors(len(t), r)
+	if err != nil {
+		return nil, Components{}, err
+	}
+	if r, err = r.withLags(t, f.opt.RegressorLags); err != nil {
+		return nil, Components{}, err
+	}
+	missingCustom, customVals, err := f.resolveMissingCustomFeatures()
+	if err != nil {
+		return nil, Components{}, err
+	}
+	missing = append(missing, missingCustom...)
+
+	if err := f.ValidateEventHorizon(t); err != nil {
+		slog.Warn("predicting without recurring event occurrences", "error", err.Error())
+	}
 
 	// generate features
-	x, err := f.generateFeatures(t)
+	x, err := f.generateFeatures(t, r)
+	if err != nil {
+		return nil, Components{}, err
+	}
+	if err := f.fillMissingCustomFeatures(x, customVals, len(t)); err != nil {
+		return nil, Components{}, err
+	}
+	nanReasons, err := f.applyNaNPolicy(t, x)
 	if err != nil {
 		return nil, Components{}, err
 	}
@@ -269,6 +618,8 @@ func (f *Forecast) Predict(t []time.Time) ([]float64, Components, error) {
 	changepointFeatureSet := feature.NewSet()
 	seasonalityFeatureSet := feature.NewSet()
 	eventFeatureSet := feature.NewSet()
+	customFeatureSet := feature.NewSet()
+	regressorFeatureSet := feature.NewSet()
 	for _, feat := range x.Labels() {
 		data, exists := x.Get(feat)
 		if !exists {
@@ -281,6 +632,10 @@ func (f *Forecast) Predict(t []time.Time) ([]float64, Components, error) {
 			seasonalityFeatureSet.Set(feat, data)
 		case feature.FeatureTypeEvent:
 			eventFeatureSet.Set(feat, data)
+		case feature.FeatureTypeTime:
+			customFeatureSet.Set(feat, data)
+		case feature.FeatureTypeRegressor:
+			regressorFeatureSet.Set(feat, data)
 		}
 	}
 
@@ -296,15 +651,95 @@ func (f *Forecast) Predict(t []time.Time) ([]float64, Components, error) {
 	if err != nil {
 		return nil, Components{}, fmt.Errorf("unable to run inference for event, %w", err)
 	}
+	customComp, err := f.runInference(customFeatureSet, false, len(t))
+	if err != nil {
+		return nil, Components{}, fmt.Errorf("unable to run inference for custom time features, %w", err)
+	}
+	var regressorComp []float64
+	if regressorFeatureSet.Len() > 0 {
+		regressorComp, err = f.runInference(regressorFeatureSet, false, len(t))
+		if err != nil {
+			return nil, Components{}, fmt.Errorf("unable to run inference for regressors, %w", err)
+		}
+	}
+
+	featureComps, err := f.featureComponents(x, len(t))
+	if err != nil {
+		return nil, Components{}, err
+	}
 
 	comp := Components{
 		Trend:       trendComp,
 		Seasonality: seasonalityComp,
 		Event:       eventComp,
+		Custom:      customComp,
+		Regressor:   regressorComp,
+		NaNReasons:  nanReasons,
+
+		MissingFeatures: warnMissingFeatures(missing),
+		Features:        featureComps,
 	}
 
 	res, err := f.runInference(x, true, len(t))
-	return res, comp, err
+	if err != nil {
+		return nil, Components{}, err
+	}
+	for i, reason := range nanReasons {
+		if reason != "" {
+			res[i] = math.NaN()
+		}
+	}
+	forceStructuralZeros(res, &comp, f.opt.StructuralZeroMask(t))
+	return res, comp, nil
+}
+
+// ValidateEventHorizon returns an error if any of the input times fall in occurrences of recurring events
+// past their configured until time which are not modeled unless recurring events are auto expanded
+func (f *Forecast) ValidateEventHorizon(t []time.Time) error {
+	if f == nil {
+		return ErrUninitializedForecast
+	}
+	if f.opt == nil {
+		return nil
+	}
+	if names := f.opt.EventOptions.UnexpandedEvents(t); len(names) > 0 {
+		return fmt.Errorf("%s, %w", strings.Join(names, ", "), options.ErrUnexpandedEvent)
+	}
+	return nil
+}
+
+// SeasonalProfile evaluates only the seasonal and weekend components of a trained model at the input
+// times excluding the intercept, trend, and any other events.
+func (f *Forecast) SeasonalProfile(t []time.Time) ([]float64, error) {
+	if f == nil {
+		return nil, ErrUninitializedForecast
+	}
+	if !f.trained {
+		return nil, ErrUntrainedForecast
+	}
+
+	x, err := f.generateFeatures(t, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	weekend := feature.NewEvent(options.LabelEventWeekend).String()
+	profileFeatureSet := feature.NewSet()
+	for _, feat := range x.Labels() {
+		data, exists := x.Get(feat)
+		if !exists {
+			continue
+		}
+		switch feat.Type() {
+		case feature.FeatureTypeSeasonality:
+			profileFeatureSet.Set(feat, data)
+		case feature.FeatureTypeEvent:
+			if feat.String() == weekend {
+				profileFeatureSet.Set(feat, data)
+			}
+		}
+	}
+	return f.runInference(profileFeatureSet, false, len(t))
 }
 
 func (f *Forecast) runInference(x *feature.Set, withIntercept bool, numObs int) ([]float64, error) {
@@ -443,13 +878,15 @@ func (f *Forecast) Model() (Model, error) {
 	}
 
 	m := Model{
-		TrainEndTime: f.trainEndTime,
-		Options:      f.opt,
+		SchemaVersion: SchemaVersion,
+		TrainEndTime:  f.trainEndTime,
+		Options:       f.opt,
 		Weights: Weights{
 			Intercept: f.intercept,
 			Coef:      f.featureWeights,
 		},
-		Scores: f.scores,
+		Scores:   f.scores,
+		FastPath: f.fastPath,
 	}
 	return m, nil
 }
@@ -500,6 +937,14 @@ func (f *Forecast) Residuals() []float64 {
 	return res
 }
 
+// TrainEndTime returns the last observed time of the training data
+func (f *Forecast) TrainEndTime() time.Time {
+	if f == nil {
+		return time.Time{}
+	}
+	return f.trainEndTime
+}
+
 // TrendComponent represents the overall trend component of the model which is determined
 // by the changepoints.
 func (f *Forecast) TrendComponent() []float64 {
@@ -530,3 +975,88 @@ func (f *Forecast) EventComponent() []float64 {
 	copy(res, f.trainComponents.Event)
 	return res
 }
+
+// CustomComponent represents the overall contribution of custom time features in the model
+func (f *Forecast) CustomComponent() []float64 {
+	if f == nil {
+		return nil
+	}
+	res := make([]float64, len(f.trainComponents.Custom))
+	copy(res, f.trainComponents.Custom)
+	return res
+}
+
+// FastPath returns the shortcut taken when fitting a degenerate series or FastPathNone if the full
+// model was fit
+func (f *Forecast) FastPath() options.FastPath {
+	if f == nil {
+		return options.FastPathNone
+	}
+	return f.fastPath
+}
+
+// ChangepointSensitivity returns how the fit quality changes when shifting each selected auto
+// changepoint. This is only populated if changepoint sensitivity samples are configured.
+func (f *Forecast) ChangepointSensitivity() []ChangepointSensitivity {
+	if f == nil {
+		return nil
+	}
+	return f.chptSensitivity
+}
+
+// RedundantFeatures returns the constant and near duplicate features dropped before fitting. This is
+// only populated if redundancy detection is enabled.
+func (f *Forecast) RedundantFeatures() []options.RedundantFeature {
+	if f == nil {
+		return nil
+	}
+	return f.redundantFeatures
+}
+
+// TimezoneMixtureWeights returns the share of the daily seasonal amplitude attributed to each timezone
+// location when modeling a timezone mixture. The amplitude of a location is the root sum of squares of
+// its daily fourier coefficients and the shares sum to one. This is nil if the mixture is not enabled or
+// no daily components were selected by the fit.
+func (f *Forecast) TimezoneMixtureWeights() map[string]float64 {
+	if f == nil || f.opt == nil || !f.opt.DSTOptions.Enabled || !f.opt.DSTOptions.Mixture {
+		return nil
+	}
+
+	names := make(map[string]string, len(f.opt.DSTOptions.TimezoneLocations))
+	for _, loc := range f.opt.DSTOptions.TimezoneLocations {
+		names[options.SeasonalityFeatureName(options.MixtureSeasonalityName(loc))] = loc
+	}
+
+	amplitudes := make(map[string]float64)
+	var total float64
+	for _, fw := range f.featureWeights {
+		if fw.Type != feature.FeatureTypeSeasonality {
+			continue
+		}
+		loc, exists := names[fw.Labels["name"]]
+		if !exists {
+			continue
+		}
+		amplitudes[loc] += fw.Value * fw.Value
+	}
+	for loc, sumSq := range amplitudes {
+		amplitudes[loc] = math.Sqrt(sumSq)
+		total += amplitudes[loc]
+	}
+	if total == 0 {
+		return nil
+	}
+	for loc := range amplitudes {
+		amplitudes[loc] /= total
+	}
+	return amplitudes
+}
+
+// CoefficientPath returns the coefficients of every feature across the lambdas of the Lasso fit. This is
+// only populated if the coefficient path is retained.
+func (f *Forecast) CoefficientPath() *CoefficientPath {
+	if f == nil {
+		return nil
+	}
+	return f.coefPath
+}
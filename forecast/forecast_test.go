@@ -1,13 +1,20 @@
 package forecast
 
 import (
+	"context"
+	"encoding/json"
 	"math"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/aouyang1/go-forecaster/feature"
 	"github.com/aouyang1/go-forecaster/forecast/options"
+	"github.com/aouyang1/go-forecaster/models"
+	"github.com/aouyang1/go-forecaster/timedataset"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"gonum.org/v1/gonum/floats"
 )
 
 func testFitSignal(t *testing.T) (*Forecast, []time.Time, []float64) {
@@ -109,3 +116,1002 @@ func TestFitFromModel(t *testing.T) {
 	assert.Less(t, scores.MSE, 0.0001)
 	assert.Less(t, scores.MAPE, 0.0001)
 }
+
+func TestFitFastPath(t *testing.T) {
+	n := 2 * 24 * 60
+	tWin := make([]time.Time, 0, n)
+	ct := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < n; i++ {
+		tWin = append(tWin, ct.Add(time.Duration(i)*time.Minute))
+	}
+
+	testData := map[string]struct {
+		y        func(i int) float64
+		expected options.FastPath
+	}{
+		"constant": {
+			y:        func(i int) float64 { return 3.0 + 1e-4*float64(i%2) },
+			expected: options.FastPathIntercept,
+		},
+		"trend": {
+			y:        func(i int) float64 { return 3.0 + 0.01*float64(i) },
+			expected: options.FastPathTrend,
+		},
+		"seasonal": {
+			y:        func(i int) float64 { return 3.0 + math.Sin(2.0*math.Pi*float64(i)/1440.0) },
+			expected: options.FastPathNone,
+		},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			y := make([]float64, n)
+			for i := range y {
+				y[i] = td.y(i)
+			}
+
+			opt := options.NewDefaultOptions()
+			opt.FastPathOptions = options.FastPathOptions{
+				Enabled:           true,
+				InterceptVariance: 1e-6,
+				TrendVariance:     1e-6,
+			}
+			f, err := New(opt)
+			require.Nil(t, err)
+			require.Nil(t, f.Fit(tWin, y))
+			assert.Equal(t, td.expected, f.FastPath())
+
+			// forecast beyond the training window from a reloaded model
+			model, err := f.Model()
+			require.Nil(t, err)
+			assert.Equal(t, td.expected, model.FastPath)
+			fNew, err := NewFromModel(model)
+			require.Nil(t, err)
+
+			future := []time.Time{tWin[n-1].Add(time.Minute), tWin[n-1].Add(time.Hour)}
+			predicted, _, err := fNew.Predict(future)
+			require.Nil(t, err)
+			assert.InDelta(t, td.y(n), predicted[0], 0.01)
+			assert.InDelta(t, td.y(n+59), predicted[1], 0.01)
+		})
+	}
+}
+
+func TestFitCustomFeature(t *testing.T) {
+	// billing cycle spike on the first hour of every third day
+	billing := func(t []time.Time) (feature.Feature, []float64) {
+		res := make([]float64, len(t))
+		for i, tPnt := range t {
+			if tPnt.YearDay()%3 == 0 && tPnt.Hour() == 0 {
+				res[i] = 1.0
+			}
+		}
+		return feature.NewTime("billing"), res
+	}
+	require.Nil(t, options.RegisterTimeFeature("test_billing", billing))
+	defer options.UnregisterTimeFeature("test_billing")
+
+	n := 9 * 24
+	tWin := make([]time.Time, 0, n)
+	ct := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < n; i++ {
+		tWin = append(tWin, ct.Add(time.Duration(i)*time.Hour))
+	}
+	_, mask := billing(tWin)
+	y := make([]float64, n)
+	for i := range y {
+		y[i] = 2.0 + 5.0*mask[i]
+	}
+
+	opt := options.NewDefaultOptions()
+	opt.SeasonalityOptions.SeasonalityConfigs = nil
+	opt.CustomFeatures = []string{"test_billing"}
+	f, err := New(opt)
+	require.Nil(t, err)
+	require.Nil(t, f.Fit(tWin, y))
+
+	coef, err := f.Coefficients()
+	require.Nil(t, err)
+	assert.InDelta(t, 5.0, coef[feature.NewTime("billing").String()], 1e-3)
+	assert.InDeltaSlice(t, floats.ScaleTo(make([]float64, n), 5.0, mask), f.CustomComponent(), 1e-3)
+
+	// reloaded models regenerate the registered feature at inference
+	model, err := f.Model()
+	require.Nil(t, err)
+	fNew, err := NewFromModel(model)
+	require.Nil(t, err)
+	predicted, _, err := fNew.Predict(tWin)
+	require.Nil(t, err)
+	assert.InDeltaSlice(t, y, predicted, 1e-3)
+}
+
+// maintenanceSeries is an event series of maintenance windows starting every period from the start
+type maintenanceSeries struct {
+	Start    time.Time     `json:"start"`
+	Period   time.Duration `json:"period"`
+	Duration time.Duration `json:"duration"`
+}
+
+func (m *maintenanceSeries) Name() string {
+	return "maintenance"
+}
+
+func (m *maintenanceSeries) Occurrences(start, end time.Time) ([]options.Event, error) {
+	var occs []options.Event
+	for s := m.Start; !s.After(end); s = s.Add(m.Period) {
+		if s.Add(m.Duration).Before(start) {
+			continue
+		}
+		occs = append(occs, options.NewEvent(m.Name(), s, s.Add(m.Duration)))
+	}
+	return occs, nil
+}
+
+func TestFitCustomEventSeries(t *testing.T) {
+	require.Nil(t, options.RegisterEventSeries("test_maintenance", options.NewJSONEventSeriesCodec(func() options.EventSeries {
+		return &maintenanceSeries{}
+	})))
+	defer options.UnregisterEventSeries("test_maintenance")
+
+	n := 9 * 24
+	ct := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tWin := timedataset.GenerateT(n, time.Hour, func() time.Time { return ct })
+	series := &maintenanceSeries{Start: tWin[5], Period: 2 * 24 * time.Hour, Duration: 3 * time.Hour}
+	occs, err := series.Occurrences(tWin[0], tWin[n-1])
+	require.Nil(t, err)
+	y := make([]float64, n)
+	for i, tPnt := range tWin {
+		y[i] = 2.0
+		for _, occ := range occs {
+			if !tPnt.Before(occ.Start) && tPnt.Before(occ.End) {
+				y[i] -= 5.0
+			}
+		}
+	}
+
+	opt := options.NewDefaultOptions()
+	opt.SeasonalityOptions.SeasonalityConfigs = nil
+	opt.EventOptions.Custom = []options.CustomEventSeries{
+		options.NewCustomEventSeries("test_maintenance", series),
+	}
+	f, err := New(opt)
+	require.Nil(t, err)
+	require.Nil(t, f.Fit(tWin, y))
+
+	coef, err := f.Coefficients()
+	require.Nil(t, err)
+	assert.InDelta(t, -5.0, coef[feature.NewEvent("maintenance").String()], 1e-3)
+
+	// the series is serialized with the model so a reloaded model predicts the events without any setup
+	model, err := f.Model()
+	require.Nil(t, err)
+	out, err := json.Marshal(model)
+	require.Nil(t, err)
+	var decoded Model
+	require.Nil(t, json.Unmarshal(out, &decoded))
+	fNew, err := NewFromModel(decoded)
+	require.Nil(t, err)
+	predicted, _, err := fNew.Predict(tWin)
+	require.Nil(t, err)
+	assert.InDeltaSlice(t, y, predicted, 1e-3)
+}
+
+func TestFitEventInteractions(t *testing.T) {
+	n := 9 * 24
+	ct := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tWin := timedataset.GenerateT(n, time.Hour, func() time.Time { return ct })
+	promo := options.NewEvent("promo", tWin[2*24], tWin[4*24])
+	maint := options.NewRecurringEvent("maint", tWin[3], tWin[6], 24*time.Hour, time.Time{})
+	launch := options.NewChangepoint("launch", tWin[5*24])
+
+	y := make([]float64, n)
+	for i, tPnt := range tWin {
+		y[i] = 2.0
+		if !tPnt.Before(promo.Start) && tPnt.Before(promo.End) {
+			// lift grows by half a unit per day of the promotion
+			y[i] += 1.0 + 0.5*tPnt.Sub(promo.Start).Hours()/24
+		}
+		if h := tPnt.Hour(); h >= 3 && h < 6 {
+			// maintenance impact triples after the launch
+			y[i] -= 1.0
+			if !tPnt.Before(launch.T) {
+				y[i] -= 2.0
+			}
+		}
+	}
+
+	opt := options.NewDefaultOptions()
+	opt.SeasonalityOptions.SeasonalityConfigs = nil
+	opt.ChangepointOptions.Changepoints = []options.Changepoint{launch}
+	opt.EventOptions.Events = []options.Event{promo, maint}
+	opt.EventOptions.Interactions = options.EventInteractionOptions{Growth: true, ChangepointBias: true}
+	f, err := New(opt)
+	require.Nil(t, err)
+	require.Nil(t, f.Fit(tWin, y))
+
+	coef, err := f.Coefficients()
+	require.Nil(t, err)
+	assert.InDelta(t, 0.5, coef[feature.NewEvent(options.EventGrowthFeatureName("promo")).String()], 1e-2)
+	assert.InDelta(t, -2.0, coef[feature.NewEvent(options.EventChangepointFeatureName("maint", "launch")).String()], 1e-2)
+
+	predicted, _, err := f.Predict(tWin)
+	require.Nil(t, err)
+	assert.InDeltaSlice(t, y, predicted, 1e-2)
+}
+
+func TestFitCustomFeatureCollision(t *testing.T) {
+	// custom feature shadowing the built in daily seasonality
+	shadow := func(t []time.Time) (feature.Feature, []float64) {
+		res := make([]float64, len(t))
+		for i, tPnt := range t {
+			res[i] = float64(tPnt.Hour())
+		}
+		return feature.NewSeasonality(options.SeasonalityFeatureName(options.LabelSeasDaily), feature.FourierCompSin, 1), res
+	}
+	require.Nil(t, options.RegisterTimeFeature("test_shadow", shadow))
+	defer options.UnregisterTimeFeature("test_shadow")
+
+	n := 3 * 24
+	tWin := make([]time.Time, 0, n)
+	ct := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < n; i++ {
+		tWin = append(tWin, ct.Add(time.Duration(i)*time.Hour))
+	}
+	y := make([]float64, n)
+	for i := range y {
+		y[i] = float64(i % 24)
+	}
+
+	testData := map[string]struct {
+		namespace string
+		err       error
+	}{
+		"collision":  {err: options.ErrFeatureNameCollision},
+		"namespaced": {namespace: "user"},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			opt := options.NewDefaultOptions()
+			opt.CustomFeatures = []string{"test_shadow"}
+			opt.CustomFeatureNamespace = td.namespace
+			f, err := New(opt)
+			require.Nil(t, err)
+			err = f.Fit(tWin, y)
+			if td.err != nil {
+				assert.ErrorIs(t, err, td.err)
+				return
+			}
+			require.Nil(t, err)
+		})
+	}
+}
+
+func TestFitEventChangepoint(t *testing.T) {
+	n := 6 * 24
+	tWin := make([]time.Time, 0, n)
+	ct := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < n; i++ {
+		tWin = append(tWin, ct.Add(time.Duration(i)*time.Hour))
+	}
+
+	opt := options.NewDefaultOptions()
+	opt.SeasonalityOptions.SeasonalityConfigs = nil
+	opt.ChangepointOptions.Changepoints = []options.Changepoint{
+		options.NewEventChangepoint("after_migration", "migration", options.AnchorEdgeEnd, 0),
+	}
+
+	// the same options are retrained after the migration is rescheduled
+	for _, migrationEnd := range []time.Time{ct.Add(3 * 24 * time.Hour), ct.Add(4 * 24 * time.Hour)} {
+		opt.EventOptions.Events = []options.Event{
+			options.NewEvent("migration", migrationEnd.Add(-12*time.Hour), migrationEnd),
+		}
+		y := make([]float64, n)
+		for i, tPnt := range tWin {
+			y[i] = 2.0
+			if !tPnt.Before(migrationEnd) {
+				y[i] = 6.0
+			}
+		}
+
+		f, err := New(opt)
+		require.Nil(t, err)
+		require.Nil(t, f.Fit(tWin, y))
+
+		require.Len(t, opt.ChangepointOptions.Changepoints, 1)
+		assert.Equal(t, migrationEnd, opt.ChangepointOptions.Changepoints[0].T)
+
+		coef, err := f.Coefficients()
+		require.Nil(t, err)
+		chptBias := feature.NewChangepoint("after_migration", feature.ChangepointCompBias)
+		assert.InDelta(t, 4.0, coef[chptBias.String()], 1e-3)
+	}
+}
+
+func TestFitRedundantFeatures(t *testing.T) {
+	n := 9 * 24
+	tWin := make([]time.Time, 0, n)
+	ct := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < n; i++ {
+		tWin = append(tWin, ct.Add(time.Duration(i)*time.Hour))
+	}
+	y := make([]float64, n)
+	for i, tPnt := range tWin {
+		y[i] = 2.0
+		if tPnt.Weekday() == time.Saturday || tPnt.Weekday() == time.Sunday {
+			y[i] = 5.0
+		}
+	}
+
+	opt := options.NewDefaultOptions()
+	opt.SeasonalityOptions.SeasonalityConfigs = nil
+	opt.WeekendOptions.Enabled = true
+	opt.EventOptions.Events = []options.Event{
+		options.NewEvent("promo", time.Date(2024, 1, 6, 0, 0, 0, 0, time.UTC), time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC)),
+	}
+	opt.RedundancyOptions.Enabled = true
+
+	f, err := New(opt)
+	require.Nil(t, err)
+	require.Nil(t, f.Fit(tWin, y))
+
+	redundant := f.RedundantFeatures()
+	require.Len(t, redundant, 1)
+	assert.Equal(t, feature.NewEvent(options.LabelEventWeekend).String(), redundant[0].Feature)
+	assert.Equal(t, feature.NewEvent("promo").String(), redundant[0].DuplicateOf)
+
+	// the weight is not split across the duplicate masks
+	coef, err := f.Coefficients()
+	require.Nil(t, err)
+	assert.InDelta(t, 3.0, coef[feature.NewEvent("promo").String()], 1e-3)
+	_, exists := coef[feature.NewEvent(options.LabelEventWeekend).String()]
+	assert.False(t, exists)
+}
+
+func TestPredictRecurringEventHorizon(t *testing.T) {
+	ct := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	genT := func(start time.Time, n int) []time.Time {
+		tWin := make([]time.Time, 0, n)
+		for i := 0; i < n; i++ {
+			tWin = append(tWin, start.Add(time.Duration(i)*time.Hour))
+		}
+		return tWin
+	}
+	inWindow := func(tPnt time.Time) bool {
+		return tPnt.Hour() >= 2 && tPnt.Hour() < 4
+	}
+	tTrain := genT(ct, 5*24)
+	yTrain := make([]float64, len(tTrain))
+	for i, tPnt := range tTrain {
+		yTrain[i] = 1.0
+		if inWindow(tPnt) {
+			yTrain[i] = 5.0
+		}
+	}
+	tHorizon := genT(ct.Add(5*24*time.Hour), 2*24)
+
+	testData := map[string]struct {
+		autoExpand bool
+		err        error
+	}{
+		"unexpanded":  {err: options.ErrUnexpandedEvent},
+		"auto expand": {autoExpand: true},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			opt := options.NewDefaultOptions()
+			opt.SeasonalityOptions.SeasonalityConfigs = nil
+			opt.EventOptions.AutoExpand = td.autoExpand
+			opt.EventOptions.Events = []options.Event{
+				options.NewRecurringEvent(
+					"maint", ct.Add(2*time.Hour), ct.Add(4*time.Hour), 24*time.Hour, tTrain[len(tTrain)-1],
+				),
+			}
+			f, err := New(opt)
+			require.Nil(t, err)
+			require.Nil(t, f.Fit(tTrain, yTrain))
+			assert.Less(t, f.Scores().MSE, 1e-4)
+
+			assert.ErrorIs(t, f.ValidateEventHorizon(tHorizon), td.err)
+
+			predicted, _, err := f.Predict(tHorizon)
+			require.Nil(t, err)
+			for i, tPnt := range tHorizon {
+				expected := 1.0
+				if td.autoExpand && inWindow(tPnt) {
+					expected = 5.0
+				}
+				assert.InDelta(t, expected, predicted[i], 1e-3)
+			}
+		})
+	}
+}
+
+func TestFitSeasonalityTrendInteraction(t *testing.T) {
+	// daily seasonality whose amplitude grows by 0.5 every day
+	n := 14 * 24
+	tWin := make([]time.Time, 0, n)
+	ct := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < n; i++ {
+		tWin = append(tWin, ct.Add(time.Duration(i)*time.Hour))
+	}
+	signal := func(tPnt time.Time) float64 {
+		days := tPnt.Sub(ct).Hours() / 24.0
+		return 10.0 + (1.0+0.5*days)*math.Sin(2.0*math.Pi*days)
+	}
+	y := make([]float64, n)
+	for i, tPnt := range tWin {
+		y[i] = signal(tPnt)
+	}
+	tHorizon := make([]time.Time, 0, 24)
+	for i := 0; i < 24; i++ {
+		tHorizon = append(tHorizon, tWin[n-1].Add(time.Duration(i+1)*time.Hour))
+	}
+
+	testData := map[string]struct {
+		interaction bool
+	}{
+		"static amplitude":    {false},
+		"modulated amplitude": {true},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			opt := options.NewDefaultOptions()
+			opt.SeasonalityOptions = options.SeasonalityOptions{
+				SeasonalityConfigs: []options.SeasonalityConfig{
+					options.NewDailySeasonalityConfig(1),
+				},
+				TrendInteraction: td.interaction,
+			}
+			f, err := New(opt)
+			require.Nil(t, err)
+			require.Nil(t, f.Fit(tWin, y))
+
+			if !td.interaction {
+				assert.Greater(t, f.Scores().MSE, 1.0)
+				return
+			}
+			assert.Less(t, f.Scores().MSE, 1e-4)
+			predicted, _, err := f.Predict(tHorizon)
+			require.Nil(t, err)
+			for i, tPnt := range tHorizon {
+				assert.InDelta(t, signal(tPnt), predicted[i], 1e-2)
+			}
+		})
+	}
+}
+
+func TestFitTimezoneMixture(t *testing.T) {
+	// daily peak at noon local time in Los Angeles and London weighted 3 to 1 across both spring DST
+	// transitions of 2024
+	n := 6 * 7 * 24
+	tWin := make([]time.Time, 0, n)
+	ct := time.Date(2024, 2, 26, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < n; i++ {
+		tWin = append(tWin, ct.Add(time.Duration(i)*time.Hour))
+	}
+	la, err := time.LoadLocation("America/Los_Angeles")
+	require.Nil(t, err)
+	london, err := time.LoadLocation("Europe/London")
+	require.Nil(t, err)
+	localDaily := func(tPnt time.Time, loc *time.Location) float64 {
+		lt := tPnt.In(loc)
+		hours := float64(lt.Hour()) + float64(lt.Minute())/60.0
+		return math.Cos(2.0 * math.Pi * (hours - 12.0) / 24.0)
+	}
+	y := make([]float64, n)
+	for i, tPnt := range tWin {
+		y[i] = 10.0 + 3.0*localDaily(tPnt, la) + localDaily(tPnt, london)
+	}
+
+	testData := map[string]struct {
+		mixture bool
+	}{
+		"averaged shift": {false},
+		"mixture":        {true},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			opt := options.NewDefaultOptions()
+			opt.SeasonalityOptions = options.SeasonalityOptions{
+				SeasonalityConfigs: []options.SeasonalityConfig{
+					options.NewDailySeasonalityConfig(1),
+				},
+			}
+			opt.DSTOptions = options.DSTOptions{
+				Enabled:           true,
+				TimezoneLocations: []string{"America/Los_Angeles", "Europe/London"},
+				Mixture:           td.mixture,
+			}
+			f, err := New(opt)
+			require.Nil(t, err)
+			require.Nil(t, f.Fit(tWin, y))
+
+			weights := f.TimezoneMixtureWeights()
+			if !td.mixture {
+				assert.Greater(t, f.Scores().MSE, 1e-2)
+				assert.Nil(t, weights)
+				return
+			}
+			assert.Less(t, f.Scores().MSE, 1e-4)
+			require.Len(t, weights, 2)
+			assert.InDelta(t, 0.75, weights["America/Los_Angeles"], 1e-2)
+			assert.InDelta(t, 0.25, weights["Europe/London"], 1e-2)
+		})
+	}
+}
+
+func TestFitCoefficientPath(t *testing.T) {
+	n := 3 * 24
+	tWin := make([]time.Time, 0, n)
+	ct := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < n; i++ {
+		tWin = append(tWin, ct.Add(time.Duration(i)*time.Hour))
+	}
+	y := make([]float64, n)
+	for i, tPnt := range tWin {
+		y[i] = 5.0 + 2.0*math.Sin(2.0*math.Pi*float64(tPnt.Hour())/24.0)
+	}
+
+	for _, retain := range []bool{false, true} {
+		opt := options.NewDefaultOptions()
+		opt.SeasonalityOptions.SeasonalityConfigs = []options.SeasonalityConfig{
+			options.NewDailySeasonalityConfig(2),
+		}
+		opt.Regularization = []float64{100.0, 0.0, 1.0}
+		opt.RetainCoefficientPath = retain
+		f, err := New(opt)
+		require.Nil(t, err)
+		require.Nil(t, f.Fit(tWin, y))
+
+		path := f.CoefficientPath()
+		if !retain {
+			assert.Nil(t, path)
+			continue
+		}
+		require.NotNil(t, path)
+		assert.Equal(t, []float64{0.0, 1.0, 100.0}, path.Lambdas)
+		require.Len(t, path.Features, 4)
+		for _, feat := range path.Features {
+			assert.Equal(t, feature.FeatureTypeSeasonality, feat.Type)
+			require.Len(t, feat.Coef, 3)
+			assert.GreaterOrEqual(t, math.Abs(feat.Coef[0]), math.Abs(feat.Coef[2]))
+		}
+		assert.Greater(t, path.Scores[0], path.Scores[2])
+	}
+}
+
+func TestFitDetectSeasonality(t *testing.T) {
+	n := 4 * 24 * 6
+	ct := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tWin := make([]time.Time, 0, n)
+	for i := 0; i < n; i++ {
+		tWin = append(tWin, ct.Add(time.Duration(i)*10*time.Minute))
+	}
+	y := make([]float64, n)
+	for i, tPnt := range tWin {
+		sec := tPnt.Sub(ct).Seconds()
+		y[i] = 5.0 + 3.0*math.Sin(2.0*math.Pi*sec/86400.0) + 1.5*math.Sin(2.0*math.Pi*sec/5400.0)
+	}
+
+	testData := map[string]struct {
+		detect   bool
+		auto     bool
+		maxMSE   float64
+		minMSE   float64
+		detected int
+	}{
+		"configured only": {detect: false, minMSE: 0.5, maxMSE: math.Inf(1)},
+		"detected":        {detect: true, maxMSE: 0.05, detected: 1},
+		"auto":            {auto: true, maxMSE: 0.05, detected: 1},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			opt := &options.Options{
+				SeasonalityOptions: options.SeasonalityOptions{
+					SeasonalityConfigs: []options.SeasonalityConfig{
+						options.NewDailySeasonalityConfig(2),
+					},
+					Detect: options.SeasonalityDetectOptions{
+						Enabled: td.detect,
+						TopK:    1,
+					},
+					Auto: td.auto,
+				},
+			}
+			f, err := New(opt)
+			require.Nil(t, err)
+			require.Nil(t, f.Fit(tWin, y))
+
+			var detected int
+			for _, seasCfg := range opt.SeasonalityOptions.SeasonalityConfigs {
+				if seasCfg.Name != options.LabelSeasDaily {
+					detected++
+				}
+			}
+			assert.Equal(t, td.detected, detected)
+
+			m, err := f.Model()
+			require.Nil(t, err)
+			assert.Len(t, m.Options.SeasonalityOptions.Detected, td.detected)
+			assert.Less(t, f.Scores().MSE, td.maxMSE)
+			assert.GreaterOrEqual(t, f.Scores().MSE, td.minMSE)
+		})
+	}
+}
+
+func TestFitSelectSeasonalityOrders(t *testing.T) {
+	n := 4 * 24 * 6
+	ct := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tWin := make([]time.Time, 0, n)
+	for i := 0; i < n; i++ {
+		tWin = append(tWin, ct.Add(time.Duration(i)*10*time.Minute))
+	}
+	y := make([]float64, n)
+	for i, tPnt := range tWin {
+		sec := tPnt.Sub(ct).Seconds()
+		y[i] = 5.0 + 3.0*math.Sin(2.0*math.Pi*sec/86400.0) + 1.5*math.Cos(4.0*math.Pi*sec/86400.0)
+	}
+
+	opt := &options.Options{
+		SeasonalityOptions: options.SeasonalityOptions{
+			SeasonalityConfigs: []options.SeasonalityConfig{
+				{Name: options.LabelSeasDaily, Period: 24 * time.Hour, OrderCriterion: options.OrderCriterionBIC},
+			},
+		},
+	}
+	f, err := New(opt)
+	require.Nil(t, err)
+	require.Nil(t, f.Fit(tWin, y))
+	assert.Less(t, f.Scores().MSE, 0.01)
+
+	m, err := f.Model()
+	require.Nil(t, err)
+	seasCfg := m.Options.SeasonalityOptions.SeasonalityConfigs[0]
+	assert.GreaterOrEqual(t, seasCfg.Orders, 2)
+	assert.Equal(t, options.OrderCriterionBIC, seasCfg.OrderCriterion)
+
+	loaded, err := NewFromModel(m)
+	require.Nil(t, err)
+	res, _, err := loaded.Predict(tWin)
+	require.Nil(t, err)
+	predicted, _, err := f.Predict(tWin)
+	require.Nil(t, err)
+	assert.InDeltaSlice(t, predicted, res, 1e-9)
+}
+
+func TestFitRegressionBackend(t *testing.T) {
+	n := 3 * 24 * 6
+	ct := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tWin := make([]time.Time, 0, n)
+	for i := 0; i < n; i++ {
+		tWin = append(tWin, ct.Add(time.Duration(i)*10*time.Minute))
+	}
+	y := make([]float64, n)
+	for i, tPnt := range tWin {
+		y[i] = 5.0 + 3.0*math.Sin(2.0*math.Pi*tPnt.Sub(ct).Seconds()/86400.0)
+	}
+
+	testData := map[string]struct {
+		regression options.Regression
+		l1Ratios   []float64
+		err        error
+	}{
+		"default":     {},
+		"lasso":       {regression: options.RegressionLasso},
+		"ridge":       {regression: options.RegressionRidge},
+		"elastic net": {regression: options.RegressionElasticNet, l1Ratios: []float64{0.1, 0.9}},
+		"quantile":    {regression: options.RegressionQuantile},
+		"huber":       {regression: options.RegressionHuber},
+		"unknown":     {regression: "bayesian", err: options.ErrUnknownRegression},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			opt := options.NewDefaultOptions()
+			opt.SeasonalityOptions.SeasonalityConfigs = []options.SeasonalityConfig{
+				options.NewDailySeasonalityConfig(2),
+			}
+			opt.Regression = td.regression
+			opt.L1Ratios = td.l1Ratios
+
+			f, err := New(opt)
+			require.Nil(t, err)
+			err = f.Fit(tWin, y)
+			if td.err != nil {
+				assert.ErrorIs(t, err, td.err)
+				return
+			}
+			require.Nil(t, err)
+			assert.Less(t, f.Scores().MSE, 1e-3)
+		})
+	}
+}
+
+func TestFitRegisteredRegressor(t *testing.T) {
+	newOLS := func(o *options.Options) (models.Regressor, error) {
+		return models.NewOLSRegression(&models.OLSOptions{FitIntercept: false})
+	}
+	require.Nil(t, options.RegisterRegressor("test_ols", newOLS))
+	defer options.UnregisterRegressor("test_ols")
+
+	assert.ErrorIs(t, options.RegisterRegressor("test_ols", newOLS), options.ErrRegressorRegistered)
+	assert.ErrorIs(t, options.RegisterRegressor("ridge", newOLS), options.ErrBuiltinRegressionName)
+	assert.ErrorIs(t, options.RegisterRegressor("", newOLS), options.ErrEmptyRegressorName)
+	assert.ErrorIs(t, options.RegisterRegressor("test_nil", nil), options.ErrNilRegressorFactory)
+
+	n := 3 * 24 * 6
+	ct := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tWin := make([]time.Time, 0, n)
+	for i := 0; i < n; i++ {
+		tWin = append(tWin, ct.Add(time.Duration(i)*10*time.Minute))
+	}
+	y := make([]float64, n)
+	for i, tPnt := range tWin {
+		y[i] = 5.0 + 3.0*math.Sin(2.0*math.Pi*tPnt.Sub(ct).Seconds()/86400.0)
+	}
+
+	opt := options.NewDefaultOptions()
+	opt.SeasonalityOptions.SeasonalityConfigs = []options.SeasonalityConfig{
+		options.NewDailySeasonalityConfig(2),
+	}
+	opt.ChangepointOptions.Changepoints = nil
+	opt.Regression = "test_ols"
+
+	f, err := New(opt)
+	require.Nil(t, err)
+	require.Nil(t, f.Fit(tWin, y))
+	assert.InDelta(t, 5.0, f.Intercept(), 1e-6)
+	assert.Less(t, f.Scores().MSE, 1e-9)
+
+	// ordinary least squares cannot be fit from the streamed sufficient statistics
+	src := func() (timedataset.ChunkReader, error) {
+		return timedataset.NewSliceChunkReader(&timedataset.TimeDataset{T: tWin, Y: y}, 100), nil
+	}
+	assert.ErrorIs(t, f.FitStream(src), ErrRegressorNoGram)
+}
+
+func TestFitSmoothTrend(t *testing.T) {
+	n := 20 * 24
+	ct := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tWin := make([]time.Time, 0, n)
+	for i := 0; i < n; i++ {
+		tWin = append(tWin, ct.Add(time.Duration(i)*time.Hour))
+	}
+
+	// slow level drift over the whole window
+	y := make([]float64, n)
+	for i := range y {
+		y[i] = 10.0 + 2.0*math.Sin(2.0*math.Pi*float64(i)/float64(n))
+	}
+
+	testData := map[string]struct {
+		stream bool
+	}{
+		"in memory": {},
+		"stream":    {stream: true},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			opt := options.NewDefaultOptions()
+			opt.SeasonalityOptions.SeasonalityConfigs = nil
+			opt.ChangepointOptions.TrendMode = options.TrendModeSmooth
+			opt.ChangepointOptions.SmoothNumChangepoints = 40
+			opt.ChangepointOptions.SmoothPenalty = 1.0
+			opt.ChangepointOptions.EnableGrowth = true
+
+			f, err := New(opt)
+			require.Nil(t, err)
+			if td.stream {
+				src := func() (timedataset.ChunkReader, error) {
+					return timedataset.NewSliceChunkReader(&timedataset.TimeDataset{T: tWin, Y: y}, 48), nil
+				}
+				require.Nil(t, f.FitStream(src))
+			} else {
+				require.Nil(t, f.Fit(tWin, y))
+			}
+
+			var numSmooth int
+			for _, chpt := range f.opt.ChangepointOptions.Changepoints {
+				if chpt.Smooth {
+					numSmooth++
+				}
+			}
+			assert.Equal(t, 40, numSmooth)
+			for _, fw := range f.featureWeights {
+				assert.Equal(t, feature.ChangepointCompBias, fw.Labels["changepoint_component"])
+			}
+
+			res, _, err := f.Predict(tWin)
+			require.Nil(t, err)
+			for i := range y {
+				assert.InDelta(t, y[i], res[i], 0.25, "index %d", i)
+			}
+		})
+	}
+
+	opt := options.NewDefaultOptions()
+	opt.ChangepointOptions.TrendMode = options.TrendModeSmooth
+	opt.ChangepointOptions.SmoothPenalty = -1.0
+	f, err := New(opt)
+	require.Nil(t, err)
+	assert.ErrorIs(t, f.Fit(tWin, y), options.ErrNegativeSmoothPenalty)
+}
+
+func TestFitYearlySeasonality(t *testing.T) {
+	// daily observations of a yearly cycle starting on a leap year
+	ct := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	signal := func(tPnt time.Time) float64 {
+		years := tPnt.Sub(ct).Seconds() / options.YearlyPeriod.Seconds()
+		return 10.0 + 3.0*math.Sin(2.0*math.Pi*years)
+	}
+
+	testData := map[string]struct {
+		days   int
+		dst    bool
+		yearly bool
+		maxMSE float64
+	}{
+		"shorter than a year":        {days: 200, yearly: false, maxMSE: math.Inf(1)},
+		"multiple years":             {days: 3 * 365, yearly: true, maxMSE: 1e-3},
+		"multiple years dst enabled": {days: 3 * 365, dst: true, yearly: true, maxMSE: 1e-3},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			tWin := make([]time.Time, 0, td.days)
+			y := make([]float64, 0, td.days)
+			for i := 0; i < td.days; i++ {
+				tPnt := ct.Add(time.Duration(i) * 24 * time.Hour)
+				tWin = append(tWin, tPnt)
+				y = append(y, signal(tPnt))
+			}
+
+			opt := &options.Options{
+				SeasonalityOptions: options.SeasonalityOptions{
+					SeasonalityConfigs: []options.SeasonalityConfig{
+						options.NewYearlySeasonalityConfig(2),
+					},
+				},
+				DSTOptions: options.DSTOptions{
+					Enabled:           td.dst,
+					TimezoneLocations: []string{"America/Los_Angeles"},
+				},
+			}
+			f, err := New(opt)
+			require.Nil(t, err)
+			require.Nil(t, f.Fit(tWin, y))
+
+			var yearly bool
+			for _, fw := range f.featureWeights {
+				feat, err := fw.ToFeature()
+				require.Nil(t, err)
+				if val, _ := feat.Get("name"); strings.HasSuffix(val, options.LabelSeasYearly) {
+					yearly = true
+				}
+			}
+			assert.Equal(t, td.yearly, yearly)
+			assert.Less(t, f.Scores().MSE, td.maxMSE)
+		})
+	}
+}
+
+func TestFitMonthlySeasonality(t *testing.T) {
+	// daily observations of a month end spike that a fixed period drifts away from
+	ct := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	n := 2 * 365
+	tWin := make([]time.Time, 0, n)
+	y := make([]float64, 0, n)
+	for i := 0; i < n; i++ {
+		tPnt := ct.AddDate(0, 0, i)
+		tWin = append(tWin, tPnt)
+		val := 10.0
+		if tPnt.AddDate(0, 0, 1).Day() == 1 {
+			val += 5.0
+		}
+		y = append(y, val)
+	}
+
+	testData := map[string]struct {
+		seasOpt options.SeasonalityOptions
+		maxMSE  float64
+		minMSE  float64
+	}{
+		"fixed period": {
+			seasOpt: options.SeasonalityOptions{
+				SeasonalityConfigs: []options.SeasonalityConfig{
+					{Name: "fixed_monthly", Orders: 15, Period: time.Duration(30.44 * 24 * float64(time.Hour))},
+				},
+			},
+			minMSE: 0.1,
+			maxMSE: math.Inf(1),
+		},
+		"calendar aware": {
+			seasOpt: options.SeasonalityOptions{
+				Monthly: options.MonthlySeasonalityOptions{Orders: 15},
+			},
+			maxMSE: 0.05,
+		},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			opt := &options.Options{
+				SeasonalityOptions: td.seasOpt,
+			}
+			f, err := New(opt)
+			require.Nil(t, err)
+			require.Nil(t, f.Fit(tWin, y))
+			assert.Less(t, f.Scores().MSE, td.maxMSE)
+			assert.GreaterOrEqual(t, f.Scores().MSE, td.minMSE)
+		})
+	}
+}
+
+func TestFitCtx(t *testing.T) {
+	expected, tWin, y := testFitSignal(t)
+	expectedRes, _, err := expected.Predict(tWin)
+	require.Nil(t, err)
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	weights := make([]float64, len(y))
+	for i := range weights {
+		weights[i] = 1.0
+	}
+
+	testData := map[string]struct {
+		fit func(f *Forecast) error
+		err error
+	}{
+		"background": {
+			fit: func(f *Forecast) error { return f.FitCtx(context.Background(), tWin, y) },
+		},
+		"canceled": {
+			fit: func(f *Forecast) error { return f.FitCtx(canceled, tWin, y) },
+			err: context.Canceled,
+		},
+		"canceled weighted": {
+			fit: func(f *Forecast) error { return f.FitWeightedCtx(canceled, tWin, y, weights) },
+			err: context.Canceled,
+		},
+		"canceled regressors": {
+			fit: func(f *Forecast) error {
+				return f.FitWithRegressorsCtx(canceled, tWin, y, Regressors{"x": weights})
+			},
+			err: context.Canceled,
+		},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			f, err := New(&options.Options{
+				SeasonalityOptions: options.SeasonalityOptions{
+					SeasonalityConfigs: []options.SeasonalityConfig{
+						options.NewDailySeasonalityConfig(3),
+					},
+				},
+			})
+			require.Nil(t, err)
+
+			err = td.fit(f)
+			if td.err != nil {
+				assert.ErrorIs(t, err, td.err)
+				return
+			}
+			require.Nil(t, err)
+
+			res, _, err := f.PredictCtx(context.Background(), tWin)
+			require.Nil(t, err)
+			assert.InDeltaSlice(t, expectedRes, res, 1e-9)
+
+			_, _, err = f.PredictCtx(canceled, tWin)
+			assert.ErrorIs(t, err, context.Canceled)
+		})
+	}
+}
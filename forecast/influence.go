@@ -0,0 +1,106 @@
+package forecast
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/errs"
+	"gonum.org/v1/gonum/mat"
+)
+
+var ErrInfluenceDecomposition = errs.NewFitError(errs.CodeFitFailed, "unable to decompose gram matrix of active features", nil)
+
+// Influence reports the leverage of each training point on the fit and the effective degrees of
+// freedom of the model. Leverage is NaN for points that were not used in training.
+type Influence struct {
+	T               []time.Time `json:"time"`
+	Leverage        []float64   `json:"leverage"`
+	EffectiveDOF    float64     `json:"effective_degrees_of_freedom"`
+	NumObservations int         `json:"num_observations"`
+}
+
+// Influence approximates the leverage of each observed training point as the diagonal of the hat
+// matrix of an ordinary least squares fit over the intercept and the features selected by the Lasso
+// fit. The effective degrees of freedom is the trace of the hat matrix which for the Lasso is the
+// number of linearly independent active features. A model with effective degrees of freedom close to
+// the number of observations is overparameterized for its data.
+func (f *Forecast) Influence(t []time.Time, y []float64) (*Influence, error) {
+	if f == nil {
+		return nil, ErrUninitializedForecast
+	}
+	if !f.trained {
+		return nil, ErrUntrainedForecast
+	}
+	if len(t) != len(y) {
+		return nil, fmt.Errorf("got %d times and %d values, %w", len(t), len(y), ErrMismatchedDataLen)
+	}
+
+	inf := &Influence{
+		T:        t,
+		Leverage: make([]float64, len(t)),
+	}
+	obsT := make([]time.Time, 0, len(t))
+	obsIdx := make([]int, 0, len(t))
+	for i := range t {
+		inf.Leverage[i] = math.NaN()
+		if math.IsNaN(y[i]) {
+			continue
+		}
+		obsT = append(obsT, t[i])
+		obsIdx = append(obsIdx, i)
+	}
+	inf.NumObservations = len(obsT)
+	if len(obsT) == 0 {
+		return inf, nil
+	}
+
+	x, err := f.generateFeatures(obsT, nil)
+	if err != nil {
+		return nil, err
+	}
+	features := x.Matrix(true)
+	if features == nil {
+		// intercept only model where every point has the same leverage
+		for _, idx := range obsIdx {
+			inf.Leverage[idx] = 1.0 / float64(len(obsT))
+		}
+		inf.EffectiveDOF = 1.0
+		return inf, nil
+	}
+
+	// pseudo-inverse of the gram matrix through its eigen decomposition handles collinear features
+	var gram mat.SymDense
+	gram.SymOuterK(1.0, features.T())
+	var eig mat.EigenSym
+	if ok := eig.Factorize(&gram, true); !ok {
+		return nil, ErrInfluenceDecomposition
+	}
+	vals := eig.Values(nil)
+	var vecs mat.Dense
+	eig.VectorsTo(&vecs)
+
+	maxVal := 0.0
+	for _, v := range vals {
+		maxVal = math.Max(maxVal, v)
+	}
+	_, n := features.Dims()
+	cutoff := maxVal * float64(n) * 1e-12
+
+	// project each row onto the eigenvectors and scale by the inverse eigenvalue
+	var proj mat.Dense
+	proj.Mul(features, &vecs)
+	for i, idx := range obsIdx {
+		var h float64
+		for j, v := range vals {
+			if v <= cutoff {
+				continue
+			}
+			p := proj.At(i, j)
+			h += p * p / v
+		}
+		inf.Leverage[idx] = h
+		inf.EffectiveDOF += h
+	}
+	return inf, nil
+}
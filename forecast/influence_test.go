@@ -0,0 +1,54 @@
+package forecast
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/forecast/options"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInfluence(t *testing.T) {
+	f, tWin, y := testFitSignal(t)
+
+	yMissing := make([]float64, len(y))
+	copy(yMissing, y)
+	yMissing[0] = math.NaN()
+
+	inf, err := f.Influence(tWin, yMissing)
+	require.Nil(t, err)
+
+	labels, err := f.FeatureLabels()
+	require.Nil(t, err)
+	assert.InDelta(t, float64(len(labels)+1), inf.EffectiveDOF, 1e-6)
+	assert.Equal(t, len(tWin)-1, inf.NumObservations)
+	assert.True(t, math.IsNaN(inf.Leverage[0]))
+	for _, h := range inf.Leverage[1:] {
+		assert.GreaterOrEqual(t, h, 0.0)
+		assert.LessOrEqual(t, h, 1.0+1e-9)
+	}
+
+	_, err = f.Influence(tWin, y[1:])
+	assert.ErrorIs(t, err, ErrMismatchedDataLen)
+}
+
+func TestInfluenceInterceptOnly(t *testing.T) {
+	tWin := []time.Time{
+		time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(1970, 1, 1, 0, 1, 0, 0, time.UTC),
+		time.Date(1970, 1, 1, 0, 2, 0, 0, time.UTC),
+		time.Date(1970, 1, 1, 0, 3, 0, 0, time.UTC),
+	}
+	y := []float64{1, 1, 1, 1}
+
+	f, err := New(&options.Options{})
+	require.Nil(t, err)
+	require.Nil(t, f.Fit(tWin, y))
+
+	inf, err := f.Influence(tWin, y)
+	require.Nil(t, err)
+	assert.Equal(t, 1.0, inf.EffectiveDOF)
+	assert.Equal(t, []float64{0.25, 0.25, 0.25, 0.25}, inf.Leverage)
+}
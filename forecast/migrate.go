@@ -0,0 +1,79 @@
+package forecast
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/errs"
+	"github.com/aouyang1/go-forecaster/feature"
+	"github.com/aouyang1/go-forecaster/forecast/options"
+)
+
+// SchemaVersion is the version of the serialized forecast model written by Model. Models serialized
+// before versioning have a schema version of 0.
+const SchemaVersion = 1
+
+var ErrUnsupportedSchemaVersion = errs.NewConfigError(errs.CodeInvalidModel, "model schema version is not supported", nil)
+
+// migrations upgrade a model from the schema version of its index to the next version
+var migrations = []func(m *Model) error{
+	migrateInferredOptions,
+}
+
+// Migrate upgrades a model serialized by an older version of this package to the current schema
+// version in place so that it predicts the same as when it was saved. Models newer than the current
+// schema version cannot be loaded since their options may not be representable.
+func (m *Model) Migrate() error {
+	if m.SchemaVersion < 0 || m.SchemaVersion > SchemaVersion {
+		return fmt.Errorf("schema version %d with latest %d, %w", m.SchemaVersion, SchemaVersion, ErrUnsupportedSchemaVersion)
+	}
+	if m.Options == nil {
+		return ErrNoModelOptions
+	}
+	for version := m.SchemaVersion; version < SchemaVersion; version++ {
+		if err := migrations[version](m); err != nil {
+			return fmt.Errorf("unable to migrate from schema version %d, %w", version, err)
+		}
+		m.SchemaVersion = version + 1
+	}
+	return nil
+}
+
+// migrateInferredOptions upgrades unversioned models whose options predate the flags that now gate
+// feature generation. Changepoint slopes were generated before EnableGrowth and timezone mixture
+// components before DSTOptions.Mixture, so those options are inferred from the coefficients that
+// would otherwise never be generated at predict time.
+func migrateInferredOptions(m *Model) error {
+	mixturePrefix := options.SeasonalityFeatureName(options.MixtureSeasonalityName(""))
+	var locations []string
+	for _, fw := range m.Weights.Coef {
+		feat, err := fw.ToFeature()
+		if err != nil {
+			return err
+		}
+		switch f := feat.(type) {
+		case *feature.Changepoint:
+			if f.ChangepointComp == feature.ChangepointCompSlope {
+				m.Options.ChangepointOptions.EnableGrowth = true
+			}
+		case *feature.Seasonality:
+			loc, isMixture := strings.CutPrefix(f.Name, mixturePrefix)
+			if !isMixture || loc == "" || slices.Contains(locations, loc) {
+				continue
+			}
+			// trend interactions share the prefix but are not a location
+			if _, err := time.LoadLocation(loc); err != nil {
+				continue
+			}
+			locations = append(locations, loc)
+		}
+	}
+	if len(locations) > 0 && !m.Options.DSTOptions.Mixture {
+		m.Options.DSTOptions.Enabled = true
+		m.Options.DSTOptions.Mixture = true
+		m.Options.DSTOptions.TimezoneLocations = locations
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package forecast
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/feature"
+	"github.com/aouyang1/go-forecaster/forecast/options"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModelMigrate(t *testing.T) {
+	mixtureWeight := func(loc string) FeatureWeight {
+		feat := feature.NewSeasonality(options.SeasonalityFeatureName(options.MixtureSeasonalityName(loc)), feature.FourierCompSin, 1)
+		return FeatureWeight{Labels: feat.Decode(), Type: feat.Type(), Value: 1.0}
+	}
+	slopeFeat := feature.NewChangepoint("trendstart", feature.ChangepointCompSlope)
+	trendFeat := feature.NewSeasonality(options.TrendInteractionFeatureName(options.LabelSeasDaily), feature.FourierCompSin, 1)
+
+	testData := map[string]struct {
+		model  Model
+		growth bool
+		dst    options.DSTOptions
+		err    error
+	}{
+		"current version is unchanged": {
+			model: Model{
+				SchemaVersion: SchemaVersion,
+				Options:       &options.Options{},
+				Weights: Weights{Coef: []FeatureWeight{
+					{Labels: slopeFeat.Decode(), Type: slopeFeat.Type(), Value: 1.0},
+				}},
+			},
+		},
+		"unversioned changepoint slope enables growth": {
+			model: Model{
+				Options: &options.Options{},
+				Weights: Weights{Coef: []FeatureWeight{
+					{Labels: slopeFeat.Decode(), Type: slopeFeat.Type(), Value: 1.0},
+				}},
+			},
+			growth: true,
+		},
+		"unversioned mixture components enable dst mixture": {
+			model: Model{
+				Options: &options.Options{},
+				Weights: Weights{Coef: []FeatureWeight{
+					mixtureWeight("America/Los_Angeles"),
+					mixtureWeight("Europe/London"),
+					mixtureWeight("America/Los_Angeles"),
+					{Labels: trendFeat.Decode(), Type: trendFeat.Type(), Value: 1.0},
+				}},
+			},
+			dst: options.DSTOptions{
+				Enabled:           true,
+				Mixture:           true,
+				TimezoneLocations: []string{"America/Los_Angeles", "Europe/London"},
+			},
+		},
+		"newer version": {
+			model: Model{SchemaVersion: SchemaVersion + 1, Options: &options.Options{}},
+			err:   ErrUnsupportedSchemaVersion,
+		},
+		"no options": {
+			model: Model{},
+			err:   ErrNoModelOptions,
+		},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			err := td.model.Migrate()
+			if td.err != nil {
+				assert.ErrorIs(t, err, td.err)
+				return
+			}
+			require.Nil(t, err)
+			assert.Equal(t, SchemaVersion, td.model.SchemaVersion)
+			assert.Equal(t, td.growth, td.model.Options.ChangepointOptions.EnableGrowth)
+			assert.Equal(t, td.dst, td.model.Options.DSTOptions)
+		})
+	}
+}
+
+func TestNewFromModelLegacyGrowth(t *testing.T) {
+	n := 7 * 24
+	ct := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tWin := make([]time.Time, 0, n)
+	y := make([]float64, 0, n)
+	for i := 0; i < n; i++ {
+		tWin = append(tWin, ct.Add(time.Duration(i)*time.Hour))
+		y = append(y, 5.0+0.1*float64(i))
+	}
+	opt := options.NewDefaultOptions()
+	opt.ChangepointOptions.Changepoints = []options.Changepoint{options.NewChangepoint("trendstart", ct)}
+	opt.ChangepointOptions.EnableGrowth = true
+	f, err := New(opt)
+	require.Nil(t, err)
+	require.Nil(t, f.Fit(tWin, y))
+	expected, _, err := f.Predict(tWin)
+	require.Nil(t, err)
+
+	// strip the schema version and growth flag as serialized before either existed
+	model, err := f.Model()
+	require.Nil(t, err)
+	out, err := json.Marshal(model)
+	require.Nil(t, err)
+	var raw map[string]any
+	require.Nil(t, json.Unmarshal(out, &raw))
+	delete(raw, "schema_version")
+	delete(raw["options"].(map[string]any)["changepoint_options"].(map[string]any), "enable_growth")
+	out, err = json.Marshal(raw)
+	require.Nil(t, err)
+
+	var legacy Model
+	require.Nil(t, json.Unmarshal(out, &legacy))
+	require.False(t, legacy.Options.ChangepointOptions.EnableGrowth)
+
+	loaded, err := NewFromModel(legacy)
+	require.Nil(t, err)
+	res, _, err := loaded.Predict(tWin)
+	require.Nil(t, err)
+	assert.InDeltaSlice(t, expected, res, 1e-9)
+}
@@ -0,0 +1,140 @@
+package forecast
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"github.com/aouyang1/go-forecaster/errs"
+	"github.com/aouyang1/go-forecaster/feature"
+	"github.com/aouyang1/go-forecaster/forecast/options"
+)
+
+var ErrUnknownMissingPolicy = errs.NewConfigError(errs.CodeInvalidOption, "unknown missing feature policy", nil)
+
+// missingValue returns the value filling the missing feature by name according to its policy or an
+// error if the policy fails the prediction
+func (f *Forecast) missingValue(name string, missingErr error) (float64, error) {
+	m := f.opt.MissingFeature(name)
+	switch m.Policy {
+	case options.MissingPolicyError:
+		return 0, fmt.Errorf("%q, %w", name, missingErr)
+	case options.MissingPolicyZero:
+		return 0, nil
+	case options.MissingPolicyDefault:
+		return m.Default, nil
+	default:
+		return 0, fmt.Errorf("%q for %q, %w", m.Policy, name, ErrUnknownMissingPolicy)
+	}
+}
+
+// resolveMissingRegressors fills every regressor with a coefficient in the model that is not provided
+// according to its missing policy. Lagged regressors require the regressor they lag. It returns the
+// provided regressors along with the filled ones and the names of the filled regressors.
+func (f *Forecast) resolveMissingRegressors(numObs int, r Regressors) (Regressors, []string, error) {
+	lagged := make(map[string]string)
+	for name, lags := range f.opt.RegressorLags {
+		for _, lag := range lags {
+			lagged[options.LaggedRegressorName(name, lag)] = name
+		}
+	}
+
+	var missing []string
+	var res Regressors
+	for _, fw := range f.featureWeights {
+		if fw.Type != feature.FeatureTypeRegressor {
+			continue
+		}
+		feat, err := fw.ToFeature()
+		if err != nil {
+			return nil, nil, err
+		}
+		name := feat.(*feature.Regressor).Name
+		if base, exists := lagged[name]; exists {
+			name = base
+		}
+		if _, exists := r[name]; exists {
+			continue
+		}
+		if _, exists := res[name]; exists {
+			continue
+		}
+		val, err := f.missingValue(name, ErrMissingRegressor)
+		if err != nil {
+			return nil, nil, err
+		}
+		if res == nil {
+			res = make(Regressors, len(r)+1)
+			for k, v := range r {
+				res[k] = v
+			}
+		}
+		filled := make([]float64, numObs)
+		for i := range filled {
+			filled[i] = val
+		}
+		res[name] = filled
+		missing = append(missing, name)
+	}
+	if res == nil {
+		return r, nil, nil
+	}
+	return res, missing, nil
+}
+
+// resolveMissingCustomFeatures returns the names of the custom time features that are not registered
+// along with the values filling them according to their missing policy
+func (f *Forecast) resolveMissingCustomFeatures() ([]string, map[string]float64, error) {
+	var missing []string
+	var vals map[string]float64
+	for _, name := range f.opt.UnregisteredCustomFeatures() {
+		val, err := f.missingValue(name, options.ErrUnknownTimeFeature)
+		if err != nil {
+			return nil, nil, err
+		}
+		if vals == nil {
+			vals = make(map[string]float64)
+		}
+		vals[name] = val
+		missing = append(missing, name)
+	}
+	return missing, vals, nil
+}
+
+// fillMissingCustomFeatures sets the column of every missing custom time feature in the model to its
+// fill value. Zero fills are skipped since the feature has no contribution.
+func (f *Forecast) fillMissingCustomFeatures(x *feature.Set, vals map[string]float64, numObs int) error {
+	for name, val := range vals {
+		label, exists := f.opt.CustomFeatureLabels[name]
+		if !exists || val == 0 {
+			continue
+		}
+		for _, fw := range f.featureWeights {
+			feat, err := fw.ToFeature()
+			if err != nil {
+				return err
+			}
+			if feat.String() != label {
+				continue
+			}
+			data := make([]float64, numObs)
+			for i := range data {
+				data[i] = val
+			}
+			x.Set(feat, data)
+		}
+	}
+	return nil
+}
+
+// warnMissingFeatures logs the names of the features filled by their missing policy and returns them
+// sorted or nil if none are missing
+func warnMissingFeatures(missing []string) []string {
+	if len(missing) == 0 {
+		return nil
+	}
+	sort.Strings(missing)
+	slog.Warn("predicting with missing features filled by their missing policy", "features", strings.Join(missing, ", "))
+	return missing
+}
@@ -0,0 +1,123 @@
+package forecast
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/feature"
+	"github.com/aouyang1/go-forecaster/forecast/options"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPredictMissingFeatures(t *testing.T) {
+	holiday := func(t []time.Time) (feature.Feature, []float64) {
+		res := make([]float64, len(t))
+		for i, tPnt := range t {
+			if tPnt.Day() == 2 {
+				res[i] = 1.0
+			}
+		}
+		return feature.NewTime("holiday"), res
+	}
+	require.Nil(t, options.RegisterTimeFeature("test_missing_holiday", holiday))
+
+	n := 3 * 24
+	ct := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tWin := make([]time.Time, 0, n)
+	y := make([]float64, n)
+	temp := make([]float64, n)
+	for i := 0; i < n; i++ {
+		tWin = append(tWin, ct.Add(time.Duration(i)*time.Hour))
+		temp[i] = math.Mod(float64(i)*0.37, 5.0)
+	}
+	_, mask := holiday(tWin)
+	for i := range y {
+		y[i] = 2.0 + 3.0*temp[i] + 4.0*mask[i]
+	}
+
+	opt := options.NewDefaultOptions()
+	opt.SeasonalityOptions.SeasonalityConfigs = nil
+	opt.CustomFeatures = []string{"test_missing_holiday"}
+	f, err := New(opt)
+	require.Nil(t, err)
+	require.Nil(t, f.FitWithRegressors(tWin, y, Regressors{"temp": temp}))
+	assert.Equal(t, map[string]string{"test_missing_holiday": feature.NewTime("holiday").String()}, opt.CustomFeatureLabels)
+	model, err := f.Model()
+	require.Nil(t, err)
+
+	// the custom feature is not registered in the process loading the model
+	options.UnregisterTimeFeature("test_missing_holiday")
+	tPred := []time.Time{ct.Add(3 * 24 * time.Hour)}
+
+	testData := map[string]struct {
+		missing  map[string]options.MissingFeatureOptions
+		x        Regressors
+		expected float64
+		flagged  []string
+		err      error
+	}{
+		"error on unregistered custom feature": {
+			x:   Regressors{"temp": {1.0}},
+			err: options.ErrUnknownTimeFeature,
+		},
+		"error on missing regressor": {
+			missing: map[string]options.MissingFeatureOptions{
+				"test_missing_holiday": {Policy: options.MissingPolicyZero},
+			},
+			err: ErrMissingRegressor,
+		},
+		"zero fill": {
+			missing: map[string]options.MissingFeatureOptions{
+				"test_missing_holiday": {Policy: options.MissingPolicyZero},
+				"temp":                 {Policy: options.MissingPolicyZero},
+			},
+			expected: 2.0,
+			flagged:  []string{"temp", "test_missing_holiday"},
+		},
+		"default fill": {
+			missing: map[string]options.MissingFeatureOptions{
+				"test_missing_holiday": {Policy: options.MissingPolicyDefault, Default: 1.0},
+				"temp":                 {Policy: options.MissingPolicyDefault, Default: 2.0},
+			},
+			expected: 12.0,
+			flagged:  []string{"temp", "test_missing_holiday"},
+		},
+		"provided regressor": {
+			missing: map[string]options.MissingFeatureOptions{
+				"test_missing_holiday": {Policy: options.MissingPolicyZero},
+			},
+			x:        Regressors{"temp": {1.0}},
+			expected: 5.0,
+			flagged:  []string{"test_missing_holiday"},
+		},
+		"unknown policy": {
+			missing: map[string]options.MissingFeatureOptions{
+				"test_missing_holiday": {Policy: "mean"},
+			},
+			x:   Regressors{"temp": {1.0}},
+			err: ErrUnknownMissingPolicy,
+		},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			m := model
+			loadedOpt := *model.Options
+			loadedOpt.MissingFeatures = td.missing
+			m.Options = &loadedOpt
+			loaded, err := NewFromModel(m)
+			require.Nil(t, err)
+
+			res, comp, err := loaded.PredictWithRegressors(tPred, td.x)
+			if td.err != nil {
+				assert.ErrorIs(t, err, td.err)
+				return
+			}
+			require.Nil(t, err)
+			assert.InDelta(t, td.expected, res[0], 1e-2)
+			assert.Equal(t, td.flagged, comp.MissingFeatures)
+		})
+	}
+}
@@ -2,32 +2,39 @@ package forecast
 
 import (
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
+	"slices"
+	"strings"
 	"text/tabwriter"
 	"time"
 
+	"github.com/aouyang1/go-forecaster/errs"
 	"github.com/aouyang1/go-forecaster/feature"
 	"github.com/aouyang1/go-forecaster/forecast/options"
 	"github.com/aouyang1/go-forecaster/forecast/util"
 )
 
-var ErrUnknownFeatureType = errors.New("unknown feature type")
+var ErrUnknownFeatureType = errs.NewDataError(errs.CodeInvalidModel, "unknown feature type", nil)
 
 // Model represents a serializeable format of a forecast storing the forecast options, fit scores,
 // and coefficients
 type Model struct {
-	TrainEndTime time.Time        `json:"train_end_time"`
-	Options      *options.Options `json:"options"`
-	Scores       *Scores          `json:"scores"`
-	Weights      Weights          `json:"weights"`
+	SchemaVersion int              `json:"schema_version"`
+	TrainEndTime  time.Time        `json:"train_end_time"`
+	Options       *options.Options `json:"options"`
+	Scores        *Scores          `json:"scores"`
+	Weights       Weights          `json:"weights"`
+	FastPath      options.FastPath `json:"fast_path,omitempty"`
 }
 
 func (m Model) TablePrint(w io.Writer, prefix, indent string) error {
 	fmt.Fprintf(w, "%s%sForecast:\n", prefix, util.IndentExpand(indent, 0))
 
 	fmt.Fprintf(w, "%s%sTraining End Time: %s\n", prefix, util.IndentExpand(indent, 1), m.TrainEndTime)
+	if m.FastPath != options.FastPathNone {
+		fmt.Fprintf(w, "%s%sFast Path: %s\n", prefix, util.IndentExpand(indent, 1), m.FastPath)
+	}
 
 	if m.Options != nil {
 		fmt.Fprintf(w, "%s%sRegularization: %.3f\n", prefix, util.IndentExpand(indent, 1), m.Options.Regularization)
@@ -47,6 +54,17 @@ func (m Model) TablePrint(w io.Writer, prefix, indent string) error {
 			fmt.Fprintf(w, "%s%sBefore: %s, After: %s\n",
 				prefix, util.IndentExpand(indent, 2),
 				-m.Options.WeekendOptions.DurBefore, m.Options.WeekendOptions.DurAfter)
+			if days := m.Options.WeekendOptions.Days; len(days) > 0 {
+				names := make([]string, 0, len(days))
+				for _, d := range days {
+					name := d.Day.String()
+					if d.StartOffset > 0 {
+						name += " from " + d.StartOffset.String()
+					}
+					names = append(names, name)
+				}
+				fmt.Fprintf(w, "%s%sDays: %s\n", prefix, util.IndentExpand(indent, 2), strings.Join(names, ", "))
+			}
 		}
 
 		if err := m.Options.EventOptions.TablePrint(w, prefix, indent, 1); err != nil {
@@ -62,6 +80,12 @@ func (m Model) TablePrint(w io.Writer, prefix, indent string) error {
 			m.Scores.MSE,
 			m.Scores.R2,
 		)
+		fmt.Fprintf(w, "%s%sMAE: %.3f    sMAPE: %.3f    MASE: %.3f\n",
+			prefix, util.IndentExpand(indent, 1),
+			m.Scores.MAE,
+			m.Scores.SMAPE,
+			m.Scores.MASE,
+		)
 	}
 
 	return m.Weights.tablePrint(w, prefix, indent, 0)
@@ -98,8 +122,16 @@ func (w *Weights) Coefficients() []float64 {
 func (w Weights) tablePrint(wr io.Writer, prefix, indent string, indentGrowth int) error {
 	fmt.Fprintf(wr, "%s%sWeights:\n", prefix, util.IndentExpand(indent, indentGrowth))
 	tbl := tabwriter.NewWriter(wr, 0, 0, 1, ' ', tabwriter.AlignRight)
-	fmt.Fprintf(tbl, "%s%sType\tLabels\tValue\t\n", prefix, util.IndentExpand(indent, indentGrowth+1))
-	fmt.Fprintf(tbl, "%s%sIntercept\t\t%.3f\t\n", prefix, util.IndentExpand(indent, indentGrowth+1), w.Intercept)
+
+	// standard errors and confidence intervals are only printed if estimated
+	withIntervals := slices.ContainsFunc(w.Coef, FeatureWeight.HasInterval)
+	if withIntervals {
+		fmt.Fprintf(tbl, "%s%sType\tLabels\tValue\tStdErr\tLower\tUpper\t\n", prefix, util.IndentExpand(indent, indentGrowth+1))
+		fmt.Fprintf(tbl, "%s%sIntercept\t\t%.3f\t\t\t\t\n", prefix, util.IndentExpand(indent, indentGrowth+1), w.Intercept)
+	} else {
+		fmt.Fprintf(tbl, "%s%sType\tLabels\tValue\t\n", prefix, util.IndentExpand(indent, indentGrowth+1))
+		fmt.Fprintf(tbl, "%s%sIntercept\t\t%.3f\t\n", prefix, util.IndentExpand(indent, indentGrowth+1), w.Intercept)
+	}
 	for _, fw := range w.Coef {
 		labelOut, err := json.Marshal(fw.Labels)
 		if err != nil {
@@ -109,18 +141,34 @@ func (w Weights) tablePrint(wr io.Writer, prefix, indent string, indentGrowth in
 		if fw.Value == 0 {
 			val = "..."
 		}
-		fmt.Fprintf(tbl, "%s%s%s\t%s\t%s\t\n",
+		if !withIntervals {
+			fmt.Fprintf(tbl, "%s%s%s\t%s\t%s\t\n",
+				prefix, util.IndentExpand(indent, 1),
+				fw.Type, string(labelOut), val)
+			continue
+		}
+		fmt.Fprintf(tbl, "%s%s%s\t%s\t%s\t%.3f\t%.3f\t%.3f\t\n",
 			prefix, util.IndentExpand(indent, 1),
-			fw.Type, string(labelOut), val)
+			fw.Type, string(labelOut), val, fw.StdErr, fw.Lower, fw.Upper)
 	}
 	return tbl.Flush()
 }
 
-// FeatureWeight represents a feature described with a type e.g. changepoint, labels and the value
+// FeatureWeight represents a feature described with a type e.g. changepoint, labels and the value.
+// StdErr is the standard error of the value and Lower and Upper bound its confidence interval if
+// coefficient errors are estimated, otherwise they are all zero.
 type FeatureWeight struct {
 	Labels map[string]string   `json:"labels"`
 	Type   feature.FeatureType `json:"type"`
 	Value  float64             `json:"value"`
+	StdErr float64             `json:"std_err,omitempty"`
+	Lower  float64             `json:"lower,omitempty"`
+	Upper  float64             `json:"upper,omitempty"`
+}
+
+// HasInterval returns true if the standard error and confidence interval of the value are estimated
+func (fw FeatureWeight) HasInterval() bool {
+	return fw.StdErr != 0 || fw.Lower != 0 || fw.Upper != 0
 }
 
 func NewFeatureWeight(f feature.Feature, val float64) FeatureWeight {
@@ -167,7 +215,79 @@ func (fw *FeatureWeight) ToFeature() (feature.Feature, error) {
 		}
 		return feat, nil
 
+	case feature.FeatureTypeTime:
+		bytes, err := json.Marshal(fw.Labels)
+		if err != nil {
+			return nil, err
+		}
+		feat := new(feature.Time)
+		if err := json.Unmarshal(bytes, feat); err != nil {
+			return nil, err
+		}
+		return feat, nil
+
+	case feature.FeatureTypeRegressor:
+		bytes, err := json.Marshal(fw.Labels)
+		if err != nil {
+			return nil, err
+		}
+		feat := new(feature.Regressor)
+		if err := json.Unmarshal(bytes, feat); err != nil {
+			return nil, err
+		}
+		return feat, nil
+
 	}
 
 	return nil, ErrUnknownFeatureType
 }
+
+// estimated floating point operations per observation to generate and apply each feature type. A
+// seasonality feature evaluates a single sine or cosine which is approximated as 20 operations.
+const (
+	flopsPerWeight      = 2 // multiply and accumulate
+	flopsPerSeasonality = 22
+	flopsPerChangepoint = 3
+	flopsPerEvent       = 1
+)
+
+// ModelStats summarizes the size and inference cost of a forecast model
+type ModelStats struct {
+	ActiveFeatures  map[feature.FeatureType]int `json:"active_features"`
+	NumFeatures     int                         `json:"num_features"`
+	SerializedBytes int                         `json:"serialized_bytes"`
+	FLOPsPerPoint   int                         `json:"flops_per_point"`
+}
+
+// Stats reports the number of non-zero features by type, the size of the JSON serialized model, and an
+// estimate of the floating point operations needed to predict a single point.
+func (m Model) Stats() (ModelStats, error) {
+	out, err := json.Marshal(m)
+	if err != nil {
+		return ModelStats{}, fmt.Errorf("unable to serialize model for stats, %w", err)
+	}
+
+	stats := ModelStats{
+		ActiveFeatures:  make(map[feature.FeatureType]int),
+		SerializedBytes: len(out),
+		FLOPsPerPoint:   1, // intercept
+	}
+	for _, fw := range m.Weights.Coef {
+		if fw.Value == 0 {
+			continue
+		}
+		stats.ActiveFeatures[fw.Type]++
+		stats.NumFeatures++
+		stats.FLOPsPerPoint += flopsPerWeight
+
+		switch fw.Type {
+		case feature.FeatureTypeSeasonality:
+			stats.FLOPsPerPoint += flopsPerSeasonality
+		case feature.FeatureTypeChangepoint:
+			stats.FLOPsPerPoint += flopsPerChangepoint
+		case feature.FeatureTypeEvent:
+			stats.FLOPsPerPoint += flopsPerEvent
+		}
+	}
+	return stats, nil
+}
@@ -2,6 +2,7 @@ package forecast
 
 import (
 	"bytes"
+	"encoding/json"
 	"testing"
 	"time"
 
@@ -41,6 +42,7 @@ Weights:
 --**Training End Time: 1970-01-01 00:00:00 +0000 UTC
 --Scores:
 --**MAPE: 0.123    MSE: 1.234    R2: 0.012
+--**MAE: 0.000    sMAPE: 0.000    MASE: 0.000
 --Weights:
       --**Type Labels Value
  --**Intercept        0.000
@@ -70,6 +72,10 @@ Weights:
 						Enabled:   true,
 						DurBefore: 1 * time.Hour,
 						DurAfter:  2 * time.Hour,
+						Days: []options.WeekendDay{
+							{Day: time.Friday, StartOffset: 12 * time.Hour},
+							{Day: time.Saturday},
+						},
 					},
 				},
 				Scores: &Scores{
@@ -120,11 +126,13 @@ Weights:
          c0 1970-01-02 00:00:00 +0000 UTC
     Weekends:
       Before: -1h0m0s, After: 2h0m0s
+      Days: Friday from 12h0m0s, Saturday
     Events:
        Name                         Start                           End
          e0 1970-01-01 00:00:00 +0000 UTC 1970-01-02 00:00:00 +0000 UTC
   Scores:
     MAPE: 0.123    MSE: 1.234    R2: 0.012
+    MAE: 0.000    sMAPE: 0.000    MASE: 0.000
   Weights:
             Type                                              Labels Value
        Intercept                                                     1.100
@@ -159,9 +167,35 @@ Weights:
     Events: None
   Scores:
     MAPE: 0.123    MSE: 1.234    R2: 0.012
+    MAE: 0.000    sMAPE: 0.000    MASE: 0.000
   Weights:
           Type Labels Value
      Intercept        1.100
+`,
+		},
+		"weights with intervals": {
+			m: Model{
+				TrainEndTime: time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC),
+				Weights: Weights{
+					Intercept: 1.0,
+					Coef: []FeatureWeight{
+						{
+							Labels: map[string]string{"name": "e0"},
+							Type:   feature.FeatureTypeEvent,
+							Value:  2.0,
+							StdErr: 0.1,
+							Lower:  1.804,
+							Upper:  2.196,
+						},
+					},
+				},
+			},
+			expected: `Forecast:
+Training End Time: 1970-01-01 00:00:00 +0000 UTC
+Weights:
+      Type        Labels Value StdErr Lower Upper
+ Intercept               1.000                   
+     event {"name":"e0"} 2.000  0.100 1.804 2.196
 `,
 		},
 	}
@@ -175,3 +209,121 @@ Weights:
 		})
 	}
 }
+
+func TestModelStats(t *testing.T) {
+	m := Model{
+		Weights: Weights{
+			Intercept: 1.0,
+			Coef: []FeatureWeight{
+				NewFeatureWeight(feature.NewSeasonality("daily", feature.FourierCompSin, 1), 1.0),
+				NewFeatureWeight(feature.NewSeasonality("daily", feature.FourierCompCos, 1), 0.0),
+				NewFeatureWeight(feature.NewChangepoint("c0", feature.ChangepointCompBias), 2.0),
+				NewFeatureWeight(feature.NewEvent("e0"), 3.0),
+			},
+		},
+	}
+
+	stats, err := m.Stats()
+	require.Nil(t, err)
+
+	expected := map[feature.FeatureType]int{
+		feature.FeatureTypeSeasonality: 1,
+		feature.FeatureTypeChangepoint: 1,
+		feature.FeatureTypeEvent:       1,
+	}
+	assert.Equal(t, expected, stats.ActiveFeatures)
+	assert.Equal(t, 3, stats.NumFeatures)
+	assert.Equal(t, 1+3*flopsPerWeight+flopsPerSeasonality+flopsPerChangepoint+flopsPerEvent, stats.FLOPsPerPoint)
+	assert.Greater(t, stats.SerializedBytes, 0)
+}
+
+func TestFeatureWeightJSON(t *testing.T) {
+	fws := []FeatureWeight{
+		NewFeatureWeight(feature.NewSeasonality("daily", feature.FourierCompSin, 2), 1.5),
+		NewFeatureWeight(feature.NewChangepoint("c0", feature.ChangepointCompSlope), -2.0),
+		NewFeatureWeight(feature.NewEvent("e0"), 3.0),
+		NewFeatureWeight(feature.NewTime("epoch"), 0.5),
+		NewFeatureWeight(feature.NewRegressor("temp"), 0.25),
+	}
+	out, err := json.Marshal(fws)
+	require.Nil(t, err)
+	assert.Contains(t, string(out), `{"type":"seasonality","seasonality":{"name":"daily","fourier_component":"sin","order":2},"value":1.5}`)
+
+	var loaded []FeatureWeight
+	require.Nil(t, json.Unmarshal(out, &loaded))
+	assert.Equal(t, fws, loaded)
+}
+
+func TestFeatureWeightUnmarshalJSON(t *testing.T) {
+	testData := map[string]struct {
+		in       string
+		expected FeatureWeight
+		err      error
+	}{
+		"changepoint": {
+			in:       `{"type":"changepoint","changepoint":{"name":"c0","changepoint_component":"bias"},"value":1}`,
+			expected: NewFeatureWeight(feature.NewChangepoint("c0", feature.ChangepointCompBias), 1.0),
+		},
+		"regressor": {
+			in:       `{"type":"regressor","regressor":{"name":"temp"},"value":1}`,
+			expected: NewFeatureWeight(feature.NewRegressor("temp"), 1.0),
+		},
+		"legacy labels": {
+			in:       `{"labels":{"name":"daily","fourier_component":"cos","order":"3"},"type":"seasonality","value":2}`,
+			expected: NewFeatureWeight(feature.NewSeasonality("daily", feature.FourierCompCos, 3), 2.0),
+		},
+		"legacy unknown label": {
+			in:  `{"labels":{"name":"e0","start":"1970-01-01"},"type":"event","value":2}`,
+			err: ErrInvalidFeatureWeight,
+		},
+		"legacy unknown type": {
+			in:  `{"labels":{"name":"e0"},"type":"holiday","value":2}`,
+			err: ErrUnknownFeatureType,
+		},
+		"unknown field": {
+			in:  `{"type":"event","event":{"name":"e0"},"weight":1}`,
+			err: ErrInvalidFeatureWeight,
+		},
+		"unknown label": {
+			in:  `{"type":"event","event":{"name":"e0","start":"1970-01-01"},"value":1}`,
+			err: ErrInvalidFeatureWeight,
+		},
+		"mismatched payload": {
+			in:  `{"type":"time","event":{"name":"e0"},"value":1}`,
+			err: ErrInvalidFeatureWeight,
+		},
+		"multiple payloads": {
+			in:  `{"type":"event","event":{"name":"e0"},"time":{"name":"e0"},"value":1}`,
+			err: ErrInvalidFeatureWeight,
+		},
+		"missing payload": {
+			in:  `{"type":"event","value":1}`,
+			err: ErrInvalidFeatureWeight,
+		},
+		"invalid changepoint component": {
+			in:  `{"type":"changepoint","changepoint":{"name":"c0","changepoint_component":"jump"},"value":1}`,
+			err: ErrInvalidFeatureWeight,
+		},
+		"invalid fourier order": {
+			in:  `{"type":"seasonality","seasonality":{"name":"daily","fourier_component":"sin","order":0},"value":1}`,
+			err: ErrInvalidFeatureWeight,
+		},
+		"empty name": {
+			in:  `{"type":"time","time":{"name":""},"value":1}`,
+			err: ErrInvalidFeatureWeight,
+		},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			var fw FeatureWeight
+			err := json.Unmarshal([]byte(td.in), &fw)
+			if td.err != nil {
+				assert.ErrorIs(t, err, td.err)
+				return
+			}
+			require.Nil(t, err)
+			assert.Equal(t, td.expected, fw)
+		})
+	}
+}
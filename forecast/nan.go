@@ -0,0 +1,61 @@
+package forecast
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/errs"
+	"github.com/aouyang1/go-forecaster/feature"
+	"github.com/aouyang1/go-forecaster/forecast/options"
+)
+
+var (
+	ErrNaNFeature       = errs.NewPredictError(errs.CodePredictFailed, "generated feature contains NaN", nil)
+	ErrUnknownNaNPolicy = errs.NewConfigError(errs.CodeInvalidOption, "unknown NaN policy", nil)
+)
+
+// applyNaNPolicy validates the generated features for NaN values according to the configured policy.
+// For the mark policy it returns the reason per time point naming the NaN features which is empty for
+// unaffected time points, or nil if no feature contains NaN.
+func (f *Forecast) applyNaNPolicy(t []time.Time, x *feature.Set) ([]string, error) {
+	policy := f.opt.NaNPolicy
+	switch policy {
+	case "", options.NaNPolicyMark, options.NaNPolicyError, options.NaNPolicyZero:
+	default:
+		return nil, fmt.Errorf("%q, %w", policy, ErrUnknownNaNPolicy)
+	}
+
+	var reasons [][]string
+	for _, label := range x.Labels() {
+		data, _ := x.Get(label)
+		for i, v := range data {
+			if !math.IsNaN(v) {
+				continue
+			}
+			switch policy {
+			case options.NaNPolicyError:
+				return nil, fmt.Errorf("feature %s at %s, %w", label, t[i], ErrNaNFeature)
+			case options.NaNPolicyZero:
+				data[i] = 0.0
+			default:
+				if reasons == nil {
+					reasons = make([][]string, len(t))
+				}
+				reasons[i] = append(reasons[i], label.String())
+			}
+		}
+	}
+	if reasons == nil {
+		return nil, nil
+	}
+
+	res := make([]string, len(t))
+	for i, feats := range reasons {
+		if len(feats) > 0 {
+			res[i] = "nan feature " + strings.Join(feats, ", ")
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,107 @@
+package forecast
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/feature"
+	"github.com/aouyang1/go-forecaster/forecast/options"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPredictNaNPolicy(t *testing.T) {
+	ct := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cutoff := ct.Add(4 * 24 * time.Hour)
+
+	// even hour spike whose feature is undefined after the cutoff
+	evenHour := func(t []time.Time) (feature.Feature, []float64) {
+		res := make([]float64, len(t))
+		for i, tPnt := range t {
+			switch {
+			case !tPnt.Before(cutoff):
+				res[i] = math.NaN()
+			case tPnt.Hour()%2 == 0:
+				res[i] = 1.0
+			}
+		}
+		return feature.NewTime("even_hour"), res
+	}
+	require.Nil(t, options.RegisterTimeFeature("test_nan_even_hour", evenHour))
+	defer options.UnregisterTimeFeature("test_nan_even_hour")
+
+	n := 4 * 24
+	tWin := make([]time.Time, 0, n)
+	for i := 0; i < n; i++ {
+		tWin = append(tWin, ct.Add(time.Duration(i)*time.Hour))
+	}
+	_, mask := evenHour(tWin)
+	y := make([]float64, n)
+	for i := range y {
+		y[i] = 2.0 + 3.0*mask[i]
+	}
+
+	tPred := make([]time.Time, 0, 6)
+	for i := -3; i < 3; i++ {
+		tPred = append(tPred, cutoff.Add(time.Duration(i)*time.Hour))
+	}
+	reason := "nan feature " + feature.NewTime("even_hour").String()
+
+	testData := map[string]struct {
+		policy   options.NaNPolicy
+		expected []float64
+		reasons  []string
+		err      error
+	}{
+		"default": {
+			expected: []float64{2.0, 5.0, 2.0, math.NaN(), math.NaN(), math.NaN()},
+			reasons:  []string{"", "", "", reason, reason, reason},
+		},
+		"mark": {
+			policy:   options.NaNPolicyMark,
+			expected: []float64{2.0, 5.0, 2.0, math.NaN(), math.NaN(), math.NaN()},
+			reasons:  []string{"", "", "", reason, reason, reason},
+		},
+		"zero": {
+			policy:   options.NaNPolicyZero,
+			expected: []float64{2.0, 5.0, 2.0, 2.0, 2.0, 2.0},
+		},
+		"error": {
+			policy: options.NaNPolicyError,
+			err:    ErrNaNFeature,
+		},
+		"unknown": {
+			policy: "drop",
+			err:    ErrUnknownNaNPolicy,
+		},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			opt := options.NewDefaultOptions()
+			opt.SeasonalityOptions.SeasonalityConfigs = nil
+			opt.CustomFeatures = []string{"test_nan_even_hour"}
+			f, err := New(opt)
+			require.Nil(t, err)
+			require.Nil(t, f.Fit(tWin, y))
+
+			opt.NaNPolicy = td.policy
+			predicted, comp, err := f.Predict(tPred)
+			if td.err != nil {
+				assert.ErrorIs(t, err, td.err)
+				return
+			}
+			require.Nil(t, err)
+			assert.Equal(t, td.reasons, comp.NaNReasons)
+			require.Len(t, predicted, len(td.expected))
+			for i, v := range td.expected {
+				if math.IsNaN(v) {
+					assert.True(t, math.IsNaN(predicted[i]), "index %d", i)
+					continue
+				}
+				assert.InDelta(t, v, predicted[i], 1e-3, "index %d", i)
+			}
+		})
+	}
+}
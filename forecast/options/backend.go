@@ -0,0 +1,50 @@
+package options
+
+import (
+	"fmt"
+
+	"github.com/aouyang1/go-forecaster/errs"
+)
+
+var ErrUnknownMatrixBackend = errs.NewConfigError(errs.CodeInvalidOption, "unknown matrix backend", nil)
+
+// DefaultSparseDensity is the largest fraction of non zero feature values the auto matrix backend fits
+// with a sparse design matrix
+const DefaultSparseDensity = 0.3
+
+// MatrixBackend selects the representation of the design matrix of the lasso fit
+type MatrixBackend string
+
+const (
+	// MatrixBackendDense stores every value of the design matrix. This is the default if unset.
+	MatrixBackendDense MatrixBackend = "dense"
+
+	// MatrixBackendSparse stores only the non zero values of the design matrix in compressed sparse rows
+	// so event masks and changepoint biases spanning a fraction of long training windows take less
+	// memory and coordinate descent skips their zeros
+	MatrixBackendSparse MatrixBackend = "sparse"
+
+	// MatrixBackendAuto fits with a sparse design matrix if at most DefaultSparseDensity of the feature
+	// values are non zero
+	MatrixBackendAuto MatrixBackend = "auto"
+)
+
+// Sparse returns true if the design matrix of the given density of non zero values is fit in sparse
+// form. Only the lasso regression without standardized features has a sparse fit since centering
+// features fills in their zeros.
+func (o *Options) Sparse(density float64) (bool, error) {
+	var sparse bool
+	switch o.MatrixBackend {
+	case "", MatrixBackendDense:
+	case MatrixBackendSparse:
+		sparse = true
+	case MatrixBackendAuto:
+		sparse = density <= DefaultSparseDensity
+	default:
+		return false, fmt.Errorf("%q, %w", o.MatrixBackend, ErrUnknownMatrixBackend)
+	}
+	if o.StandardizeFeatures || (o.Regression != "" && o.Regression != RegressionLasso) {
+		return false, nil
+	}
+	return sparse, nil
+}
@@ -4,24 +4,79 @@ import (
 	"fmt"
 	"io"
 	"strconv"
+	"strings"
 	"text/tabwriter"
 	"time"
 
+	"github.com/aouyang1/go-forecaster/errs"
 	"github.com/aouyang1/go-forecaster/feature"
+	"github.com/aouyang1/go-forecaster/forecast/changepoint"
 	"github.com/aouyang1/go-forecaster/forecast/util"
 )
 
 var DefaultAutoNumChangepoints int = 100
 
+var DefaultSmoothNumChangepoints int = 50
+
+// LabelChptDetected prefixes the names of changepoints added by detection
+const LabelChptDetected = "detected"
+
+const DefaultSensitivityTolerance = 0.01
+
+var ErrUnknownAnchorEvent = errs.NewConfigError(errs.CodeInvalidEvent, "changepoint anchor event not found", nil)
+
+var ErrNegativeSmoothPenalty = errs.NewConfigError(errs.CodeInvalidOption, "negative smooth trend penalty", nil)
+
+// TrendMode selects how the trend changepoints capture level changes
+type TrendMode string
+
+const (
+	// TrendModeSparse fits a few abrupt level and slope changes by selecting changepoints with the
+	// lasso. This is the default if unset.
+	TrendModeSparse TrendMode = "sparse"
+
+	// TrendModeSmooth adds a dense grid of level changepoints with a ridge penalty so slow level drift
+	// is fit as a random walk of many small steps
+	TrendModeSmooth TrendMode = "smooth"
+)
+
+// AnchorEdge selects which boundary of an event a changepoint is anchored to
+type AnchorEdge string
+
+const (
+	AnchorEdgeStart AnchorEdge = "start"
+	AnchorEdgeEnd   AnchorEdge = "end"
+)
+
 // Changepoint describes a point in time that will change the ongoing trend. This will
-// include both a bias a growth feature.
+// include both a bias a growth feature. A changepoint with an AnchorEvent has its time
+// resolved from the start or end of the named event plus the offset on every fit instead
+// of using a literal timestamp.
 type Changepoint struct {
 	T    time.Time `json:"time"`
 	Name string    `json:"name"`
+
+	AnchorEvent string        `json:"anchor_event,omitempty"`
+	AnchorEdge  AnchorEdge    `json:"anchor_edge,omitempty"`
+	Offset      time.Duration `json:"offset,omitempty"`
+
+	// Smooth marks a level changepoint of the smooth trend which only has a bias feature
+	Smooth bool `json:"smooth,omitempty"`
 }
 
 func NewChangepoint(name string, t time.Time) Changepoint {
-	return Changepoint{t, name}
+	return Changepoint{T: t, Name: name}
+}
+
+// NewEventChangepoint creates a changepoint anchored to the start or end of the named event
+// shifted by the offset
+func NewEventChangepoint(name, event string, edge AnchorEdge, offset time.Duration) Changepoint {
+	return Changepoint{
+		Name:        name,
+		AnchorEvent: event,
+		AnchorEdge:  edge,
+		Offset:      offset,
+	}
 }
 
 // ChangepointOptions configures the changepoint fit to either use auto-detection
@@ -34,6 +89,68 @@ type ChangepointOptions struct {
 	EnableGrowth        bool          `json:"enable_growth"`
 	Auto                bool          `json:"auto"`
 	AutoNumChangepoints int           `json:"auto_num_changepoints"`
+
+	// SensitivitySamples enables reporting how the fit quality changes when each selected auto
+	// changepoint is shifted by up to +/- this many samples. Disabled when 0.
+	SensitivitySamples int `json:"sensitivity_samples"`
+
+	// SensitivityTolerance is the relative increase in mean squared error allowed for a shifted
+	// placement to still fall in the changepoint confidence window. Defaults to 0.01 if unset.
+	SensitivityTolerance float64 `json:"sensitivity_tolerance"`
+
+	// TrendMode set to smooth adds SmoothNumChangepoints evenly spaced level changepoints on top of any
+	// configured changepoints. Their steps are ridge penalized by SmoothPenalty instead of selected by
+	// the lasso. The penalty is in units of observations so a step is shrunk by half if supported by
+	// SmoothPenalty observations and defaults to the number of observations between smooth changepoints.
+	TrendMode             TrendMode `json:"trend_mode,omitempty"`
+	SmoothNumChangepoints int       `json:"smooth_num_changepoints,omitempty"`
+	SmoothPenalty         float64   `json:"smooth_penalty,omitempty"`
+
+	// Detect replaces the evenly spaced auto changepoints with changepoints detected in the level and
+	// slope of the training series
+	Detect ChangepointDetectOptions `json:"detect"`
+}
+
+// ChangepointDetectOptions detects changepoints in the training series on every untrained fit with
+// PELT or binary segmentation of piecewise linear segments. Detected changepoints are named with the
+// detected prefix and replace any previously detected changepoints while configured changepoints are
+// kept. Detection takes precedence over evenly spaced auto changepoints and is not supported when
+// fitting from a stream of chunks.
+type ChangepointDetectOptions struct {
+	Enabled bool `json:"enabled"`
+	changepoint.Options
+}
+
+// DetectChangepoints replaces any previously detected changepoints with the changepoints detected in
+// the input training data. Nothing is changed if detection is disabled.
+func (c *ChangepointOptions) DetectChangepoints(t []time.Time, y []float64) error {
+	if !c.Detect.Enabled {
+		return nil
+	}
+
+	configured := make([]Changepoint, 0, len(c.Changepoints))
+	for _, chpt := range c.Changepoints {
+		if !strings.HasPrefix(chpt.Name, LabelChptDetected+"_") {
+			configured = append(configured, chpt)
+		}
+	}
+	c.Changepoints = configured
+	if len(t) == 0 {
+		return nil
+	}
+
+	x := make([]float64, len(t))
+	for i, tPnt := range t {
+		x[i] = tPnt.Sub(t[0]).Seconds()
+	}
+	idxs, err := changepoint.Detect(x, y, c.Detect.Options)
+	if err != nil {
+		return fmt.Errorf("unable to detect changepoints, %w", err)
+	}
+	for i, idx := range idxs {
+		c.Changepoints = append(c.Changepoints, NewChangepoint(LabelChptDetected+"_"+strconv.Itoa(i), t[idx]))
+	}
+	return nil
 }
 
 func (c ChangepointOptions) TablePrint(w io.Writer, prefix, indent string, indentGrowth int) error {
@@ -61,8 +178,35 @@ func NewDefaultChangepointOptions() ChangepointOptions {
 	}
 }
 
+// ResolveAnchors sets the time of every event anchored changepoint from the matching event. Anchors
+// default to the start of the event.
+func (c *ChangepointOptions) ResolveAnchors(events []Event) error {
+	for i, chpt := range c.Changepoints {
+		if chpt.AnchorEvent == "" {
+			continue
+		}
+		var found bool
+		for _, ev := range events {
+			if ev.Name != chpt.AnchorEvent {
+				continue
+			}
+			anchor, evEnd := ev.bounds()
+			if chpt.AnchorEdge == AnchorEdgeEnd {
+				anchor = evEnd
+			}
+			c.Changepoints[i].T = anchor.Add(chpt.Offset)
+			found = true
+			break
+		}
+		if !found {
+			return fmt.Errorf("changepoint %q anchored to %q, %w", chpt.Name, chpt.AnchorEvent, ErrUnknownAnchorEvent)
+		}
+	}
+	return nil
+}
+
 func (c *ChangepointOptions) GenerateAutoChangepoints(t []time.Time) []Changepoint {
-	if !c.Auto {
+	if !c.Auto || c.Detect.Enabled {
 		return nil
 	}
 
@@ -98,6 +242,63 @@ func (c *ChangepointOptions) GenerateAutoChangepoints(t []time.Time) []Changepoi
 	return chpts
 }
 
+// GenerateSmoothChangepoints replaces any smooth changepoints with SmoothNumChangepoints evenly spaced
+// within the input times excluding the first time which is covered by the intercept. No changepoints are
+// generated unless the trend mode is smooth.
+func (c *ChangepointOptions) GenerateSmoothChangepoints(t []time.Time) []Changepoint {
+	chpts := make([]Changepoint, 0, len(c.Changepoints))
+	for _, chpt := range c.Changepoints {
+		if !chpt.Smooth {
+			chpts = append(chpts, chpt)
+		}
+	}
+	c.Changepoints = chpts
+	if c.TrendMode != TrendModeSmooth || len(t) == 0 {
+		return nil
+	}
+
+	if c.SmoothNumChangepoints == 0 {
+		c.SmoothNumChangepoints = DefaultSmoothNumChangepoints
+	}
+	n := c.SmoothNumChangepoints
+
+	var minTime, maxTime time.Time
+	for _, tPnt := range t {
+		if minTime.IsZero() || tPnt.Before(minTime) {
+			minTime = tPnt
+		}
+		if maxTime.IsZero() || tPnt.After(maxTime) {
+			maxTime = tPnt
+		}
+	}
+
+	window := maxTime.Sub(minTime)
+	smooth := make([]Changepoint, 0, n)
+	for i := 1; i <= n; i++ {
+		chpt := NewChangepoint("smooth_"+strconv.Itoa(i), minTime.Add(window*time.Duration(i)/time.Duration(n+1)))
+		chpt.Smooth = true
+		smooth = append(smooth, chpt)
+	}
+	c.Changepoints = append(c.Changepoints, smooth...)
+	return smooth
+}
+
+// SmoothTrendPenalty returns the ridge penalty of each smooth changepoint step given the number of
+// training observations
+func (c ChangepointOptions) SmoothTrendPenalty(numObs int) (float64, error) {
+	if c.SmoothPenalty < 0 {
+		return 0, ErrNegativeSmoothPenalty
+	}
+	if c.SmoothPenalty > 0 {
+		return c.SmoothPenalty, nil
+	}
+	n := c.SmoothNumChangepoints
+	if n <= 0 {
+		n = DefaultSmoothNumChangepoints
+	}
+	return float64(numObs) / float64(n+1), nil
+}
+
 func (c ChangepointOptions) GenerateFeatures(t []time.Time, trainingEndTime time.Time) *feature.Set {
 	chpts := c.Changepoints
 	filteredChpts := make([]Changepoint, 0, len(chpts))
@@ -157,7 +358,7 @@ func (c ChangepointOptions) GenerateFeatures(t []time.Time, trainingEndTime time
 		chpntBias := feature.NewChangepoint(chpntName, feature.ChangepointCompBias)
 		feat.Set(chpntBias, chptBiasFeatures[i])
 
-		if c.EnableGrowth {
+		if c.EnableGrowth && !filteredChpts[i].Smooth {
 			chpntGrowth := feature.NewChangepoint(chpntName, feature.ChangepointCompSlope)
 			feat.Set(chpntGrowth, chptGrowthFeatures[i])
 		}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/aouyang1/go-forecaster/feature"
+	"github.com/aouyang1/go-forecaster/forecast/changepoint"
 	"github.com/aouyang1/go-forecaster/timedataset"
 	"github.com/stretchr/testify/assert"
 )
@@ -113,6 +114,64 @@ func TestGenerateAutoChangepoints(t *testing.T) {
 	}
 }
 
+func TestDetectChangepoints(t *testing.T) {
+	t0 := time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC)
+	tWin := make([]time.Time, 60)
+	y := make([]float64, 60)
+	for i := range tWin {
+		tWin[i] = t0.Add(time.Duration(i) * time.Hour)
+		y[i] = float64((i*7)%5) / 5
+		if i >= 30 {
+			y[i] += 20
+		}
+	}
+	configured := NewChangepoint("launch", t0.Add(10*time.Hour))
+
+	testData := map[string]struct {
+		opt      *ChangepointOptions
+		expected []Changepoint
+		err      error
+	}{
+		"disabled": {
+			opt:      &ChangepointOptions{Changepoints: []Changepoint{configured}},
+			expected: []Changepoint{configured},
+		},
+		"detected": {
+			opt: &ChangepointOptions{
+				Changepoints: []Changepoint{configured, NewChangepoint("detected_0", t0)},
+				Detect:       ChangepointDetectOptions{Enabled: true},
+			},
+			expected: []Changepoint{configured, NewChangepoint("detected_0", tWin[30])},
+		},
+		"auto not generated": {
+			opt: &ChangepointOptions{
+				Auto:   true,
+				Detect: ChangepointDetectOptions{Enabled: true},
+			},
+			expected: []Changepoint{NewChangepoint("detected_0", tWin[30])},
+		},
+		"invalid options": {
+			opt: &ChangepointOptions{
+				Detect: ChangepointDetectOptions{Enabled: true, Options: changepoint.Options{Method: "window"}},
+			},
+			err: changepoint.ErrUnknownMethod,
+		},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			err := td.opt.DetectChangepoints(tWin, y)
+			if td.err != nil {
+				assert.ErrorIs(t, err, td.err)
+				return
+			}
+			assert.Nil(t, err)
+			td.opt.GenerateAutoChangepoints(tWin)
+			assert.Equal(t, td.expected, td.opt.Changepoints)
+		})
+	}
+}
+
 func TestGenerateFeatures(t *testing.T) {
 	endTime := time.Date(1970, 1, 8, 0, 0, 0, 0, time.UTC)
 	nowFunc := func() time.Time {
@@ -225,3 +284,73 @@ func TestGenerateFeatures(t *testing.T) {
 		})
 	}
 }
+
+func TestResolveAnchors(t *testing.T) {
+	events := []Event{
+		NewEvent(
+			"migration",
+			time.Date(1970, 1, 2, 0, 0, 0, 0, time.UTC),
+			time.Date(1970, 1, 3, 0, 0, 0, 0, time.UTC),
+		),
+	}
+
+	testData := map[string]struct {
+		chpts    []Changepoint
+		expected []Changepoint
+		err      error
+	}{
+		"literal": {
+			chpts: []Changepoint{
+				NewChangepoint("literal", time.Date(1970, 1, 4, 0, 0, 0, 0, time.UTC)),
+			},
+			expected: []Changepoint{
+				NewChangepoint("literal", time.Date(1970, 1, 4, 0, 0, 0, 0, time.UTC)),
+			},
+		},
+		"default start": {
+			chpts: []Changepoint{
+				NewEventChangepoint("migration_start", "migration", "", 0),
+			},
+			expected: []Changepoint{
+				{
+					Name:        "migration_start",
+					T:           time.Date(1970, 1, 2, 0, 0, 0, 0, time.UTC),
+					AnchorEvent: "migration",
+				},
+			},
+		},
+		"end with offset": {
+			chpts: []Changepoint{
+				NewEventChangepoint("migration_end", "migration", AnchorEdgeEnd, 2*time.Hour),
+			},
+			expected: []Changepoint{
+				{
+					Name:        "migration_end",
+					T:           time.Date(1970, 1, 3, 2, 0, 0, 0, time.UTC),
+					AnchorEvent: "migration",
+					AnchorEdge:  AnchorEdgeEnd,
+					Offset:      2 * time.Hour,
+				},
+			},
+		},
+		"unknown event": {
+			chpts: []Changepoint{
+				NewEventChangepoint("outage", "outage", AnchorEdgeStart, 0),
+			},
+			err: ErrUnknownAnchorEvent,
+		},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			opt := &ChangepointOptions{Changepoints: td.chpts}
+			err := opt.ResolveAnchors(events)
+			if td.err != nil {
+				assert.ErrorIs(t, err, td.err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, td.expected, opt.Changepoints)
+		})
+	}
+}
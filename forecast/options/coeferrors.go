@@ -0,0 +1,34 @@
+package options
+
+const (
+	DefaultCoefficientLevel            = 0.95
+	DefaultCoefficientBootstrapSamples = 100
+)
+
+// CoefficientErrorOptions estimates the standard error and confidence interval of every coefficient
+// after the fit. Least squares fits, i.e. ridge or a lasso selecting a zero lambda, use the covariance
+// of the coefficients in closed form. Any other regression refits BootstrapSamples resamples of the
+// residuals and takes the percentile interval of the refit coefficients. Level is the coverage of the
+// confidence intervals defaulting to DefaultCoefficientLevel.
+type CoefficientErrorOptions struct {
+	Enabled          bool    `json:"enabled"`
+	Level            float64 `json:"level,omitempty"`
+	BootstrapSamples int     `json:"bootstrap_samples,omitempty"`
+	Seed             int64   `json:"seed,omitempty"`
+}
+
+// ConfidenceLevel returns the configured level or the default if it is not between 0 and 1 exclusive
+func (c CoefficientErrorOptions) ConfidenceLevel() float64 {
+	if c.Level <= 0 || c.Level >= 1 {
+		return DefaultCoefficientLevel
+	}
+	return c.Level
+}
+
+// NumBootstrapSamples returns the configured number of bootstrap samples or the default if unset
+func (c CoefficientErrorOptions) NumBootstrapSamples() int {
+	if c.BootstrapSamples <= 0 {
+		return DefaultCoefficientBootstrapSamples
+	}
+	return c.BootstrapSamples
+}
@@ -0,0 +1,131 @@
+package options
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/errs"
+	"github.com/aouyang1/go-forecaster/feature"
+)
+
+var (
+	ErrEmptyCustomFeatureName  = errs.NewConfigError(errs.CodeMissingOption, "custom time feature name cannot be empty", nil)
+	ErrNilCustomFeature        = errs.NewConfigError(errs.CodeMissingOption, "custom time feature function cannot be nil", nil)
+	ErrCustomFeatureRegistered = errs.NewConfigError(errs.CodeDuplicateLabel, "custom time feature already registered", nil)
+	ErrCustomFeatureLen        = errs.NewDataError(errs.CodeLengthMismatch, "custom time feature has a different length than time", nil)
+	ErrCustomFeatureType       = errs.NewConfigError(errs.CodeUnknownFeature, "custom time feature must be a time, event, seasonality, or changepoint feature", nil)
+)
+
+// TimeFeatureFunc generates a user defined feature, e.g. day of billing cycle, for the input times
+type TimeFeatureFunc func(t []time.Time) (feature.Feature, []float64)
+
+var (
+	timeFeaturesMu sync.RWMutex
+	timeFeatures   = make(map[string]TimeFeatureFunc)
+)
+
+// RegisterTimeFeature registers a custom time feature by name so it can be referenced from
+// Options.CustomFeatures. Models only store the name so the same feature must be registered in any
+// process that loads the model for inference.
+func RegisterTimeFeature(name string, fn TimeFeatureFunc) error {
+	if name == "" {
+		return ErrEmptyCustomFeatureName
+	}
+	if fn == nil {
+		return fmt.Errorf("%q, %w", name, ErrNilCustomFeature)
+	}
+
+	timeFeaturesMu.Lock()
+	defer timeFeaturesMu.Unlock()
+	if _, exists := timeFeatures[name]; exists {
+		return fmt.Errorf("%q, %w", name, ErrCustomFeatureRegistered)
+	}
+	timeFeatures[name] = fn
+	return nil
+}
+
+// UnregisterTimeFeature removes a custom time feature from the registry
+func UnregisterTimeFeature(name string) {
+	timeFeaturesMu.Lock()
+	defer timeFeaturesMu.Unlock()
+	delete(timeFeatures, name)
+}
+
+// GenerateCustomFeatures evaluates each registered custom time feature listed in the options and
+// records the label of each generated feature
+func (o *Options) GenerateCustomFeatures(t []time.Time) (*feature.Set, error) {
+	feat, _, err := o.generateCustomFeatures(t, false)
+	return feat, err
+}
+
+// GenerateAvailableCustomFeatures evaluates each registered custom time feature listed in the options
+// and returns the names of the unregistered ones whose missing policy allows predicting without them
+func (o *Options) GenerateAvailableCustomFeatures(t []time.Time) (*feature.Set, []string, error) {
+	return o.generateCustomFeatures(t, true)
+}
+
+func (o *Options) generateCustomFeatures(t []time.Time, allowMissing bool) (*feature.Set, []string, error) {
+	feat := feature.NewSet()
+	if o == nil {
+		return feat, nil, nil
+	}
+
+	timeFeaturesMu.RLock()
+	defer timeFeaturesMu.RUnlock()
+	var missing []string
+	labels := make(map[string]string)
+	for _, name := range o.CustomFeatures {
+		fn, exists := timeFeatures[name]
+		if !exists {
+			if allowMissing && o.MissingFeature(name).Policy != MissingPolicyError {
+				missing = append(missing, name)
+				continue
+			}
+			return nil, nil, fmt.Errorf("custom feature %q is not registered, %w", name, ErrUnknownTimeFeature)
+		}
+		f, data := fn(t)
+		if f == nil {
+			return nil, nil, fmt.Errorf("custom feature %q returned no feature, %w", name, ErrNilCustomFeature)
+		}
+		switch f.Type() {
+		case feature.FeatureTypeTime, feature.FeatureTypeEvent, feature.FeatureTypeSeasonality, feature.FeatureTypeChangepoint:
+		default:
+			return nil, nil, fmt.Errorf("custom feature %q has type %q, %w", name, f.Type(), ErrCustomFeatureType)
+		}
+		if len(data) != len(t) {
+			return nil, nil, fmt.Errorf("custom feature %q has %d values for %d times, %w", name, len(data), len(t), ErrCustomFeatureLen)
+		}
+		f = NamespacedFeature(f, o.CustomFeatureNamespace)
+		if _, exists := feat.Get(f); exists {
+			return nil, nil, fmt.Errorf("custom feature %q generated %q, %w", name, f.String(), ErrFeatureNameCollision)
+		}
+		feat.Set(f, data)
+		labels[name] = f.String()
+	}
+	// only record labels when fitting since prediction must not modify the options
+	if !allowMissing {
+		o.CustomFeatureLabels = nil
+		if len(labels) > 0 {
+			o.CustomFeatureLabels = labels
+		}
+	}
+	return feat, missing, nil
+}
+
+// UnregisteredCustomFeatures returns the names of the custom time features listed in the options
+// that are not registered
+func (o *Options) UnregisteredCustomFeatures() []string {
+	if o == nil {
+		return nil
+	}
+	timeFeaturesMu.RLock()
+	defer timeFeaturesMu.RUnlock()
+	var res []string
+	for _, name := range o.CustomFeatures {
+		if _, exists := timeFeatures[name]; !exists {
+			res = append(res, name)
+		}
+	}
+	return res
+}
@@ -0,0 +1,87 @@
+package options
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/feature"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateCustomFeatures(t *testing.T) {
+	evenHour := func(t []time.Time) (feature.Feature, []float64) {
+		res := make([]float64, len(t))
+		for i, tPnt := range t {
+			if tPnt.Hour()%2 == 0 {
+				res[i] = 1.0
+			}
+		}
+		return feature.NewTime("even_hour"), res
+	}
+	require.Nil(t, RegisterTimeFeature("test_even_hour", evenHour))
+	defer UnregisterTimeFeature("test_even_hour")
+
+	require.Nil(t, RegisterTimeFeature("test_short", func(t []time.Time) (feature.Feature, []float64) {
+		return feature.NewTime("short"), nil
+	}))
+	defer UnregisterTimeFeature("test_short")
+
+	assert.ErrorIs(t, RegisterTimeFeature("test_even_hour", evenHour), ErrCustomFeatureRegistered)
+	assert.ErrorIs(t, RegisterTimeFeature("", evenHour), ErrEmptyCustomFeatureName)
+	assert.ErrorIs(t, RegisterTimeFeature("test_nil", nil), ErrNilCustomFeature)
+
+	tWin := []time.Time{
+		time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(1970, 1, 1, 1, 0, 0, 0, time.UTC),
+		time.Date(1970, 1, 1, 2, 0, 0, 0, time.UTC),
+	}
+
+	require.Nil(t, RegisterTimeFeature("test_even_hour_copy", evenHour))
+	defer UnregisterTimeFeature("test_even_hour_copy")
+
+	testData := map[string]struct {
+		names     []string
+		namespace string
+		expected  *feature.Set
+		err       error
+	}{
+		"none": {
+			expected: feature.NewSet(),
+		},
+		"registered": {
+			names:    []string{"test_even_hour"},
+			expected: feature.NewSet().Set(feature.NewTime("even_hour"), []float64{1, 0, 1}),
+		},
+		"namespaced": {
+			names:     []string{"test_even_hour"},
+			namespace: "user",
+			expected:  feature.NewSet().Set(feature.NewTime("user_even_hour"), []float64{1, 0, 1}),
+		},
+		"duplicate generated feature": {
+			names: []string{"test_even_hour", "test_even_hour_copy"},
+			err:   ErrFeatureNameCollision,
+		},
+		"unregistered": {
+			names: []string{"test_missing"},
+			err:   ErrUnknownTimeFeature,
+		},
+		"length mismatch": {
+			names: []string{"test_short"},
+			err:   ErrCustomFeatureLen,
+		},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			opt := &Options{CustomFeatures: td.names, CustomFeatureNamespace: td.namespace}
+			res, err := opt.GenerateCustomFeatures(tWin)
+			if td.err != nil {
+				assert.ErrorIs(t, err, td.err)
+				return
+			}
+			require.Nil(t, err)
+			compareFeatureSet(t, td.expected, res, 1e-9)
+		})
+	}
+}
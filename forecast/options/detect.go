@@ -0,0 +1,358 @@
+package options
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/timedataset"
+	"gonum.org/v1/gonum/dsp/fourier"
+	"gonum.org/v1/gonum/stat"
+)
+
+const (
+	// LabelSeasDetected prefixes the names of seasonality configs added from detected frequencies
+	LabelSeasDetected = "detected"
+
+	DefaultDetectTopK      = 3
+	DefaultDetectThreshold = 10.0
+
+	// DefaultAutoMaxOrders is the most fourier orders of a seasonality added by auto seasonality
+	DefaultAutoMaxOrders = 10
+)
+
+// SeasonalityDetectOptions adds seasonality configs at the strongest periodicities of the training data
+// so that cycles which are not harmonics of the configured seasonalities, e.g. a 90 minute batch cycle,
+// are modeled automatically. The linearly detrended training data is resampled onto a regular grid and
+// the peaks of its periodogram with a power of at least Threshold times the median power are selected,
+// up to TopK of them, ignoring peaks already explained by a harmonic of a configured seasonality.
+// Periods are limited to MinPeriod and MaxPeriod defaulting to twice the sampling interval and half of
+// the training range. Each detected period is modeled with Orders fourier orders defaulting to 1. Auto
+// seasonality treats Orders as the most orders of a period defaulting to DefaultAutoMaxOrders.
+type SeasonalityDetectOptions struct {
+	Enabled   bool          `json:"enabled"`
+	TopK      int           `json:"top_k"`
+	Threshold float64       `json:"threshold"`
+	MinPeriod time.Duration `json:"min_period"`
+	MaxPeriod time.Duration `json:"max_period"`
+	Orders    int           `json:"orders"`
+}
+
+// DetectedSeasonality reports a seasonality config added from a peak of the periodogram of the training
+// data. Power is the power of the peak over the median power of the periodogram.
+type DetectedSeasonality struct {
+	Name   string        `json:"name"`
+	Period time.Duration `json:"period"`
+	Orders int           `json:"orders"`
+	Power  float64       `json:"power"`
+}
+
+// DetectSeasonality replaces any previously detected seasonality configs with the periodicities detected
+// in the input training data and reports them in Detected. Nothing is changed if neither detection nor
+// auto seasonality is enabled.
+func (s *SeasonalityOptions) DetectSeasonality(t []time.Time, y []float64) error {
+	if !s.Detect.Enabled && !s.Auto {
+		return nil
+	}
+
+	previous := make(map[string]bool, len(s.Detected))
+	for _, d := range s.Detected {
+		previous[d.Name] = true
+	}
+	configured := make([]SeasonalityConfig, 0, len(s.SeasonalityConfigs))
+	for _, seasCfg := range s.SeasonalityConfigs {
+		if !IsDetectedSeasonalityName(seasCfg.Name) && !previous[seasCfg.Name] {
+			configured = append(configured, seasCfg)
+		}
+	}
+	s.SeasonalityConfigs = configured
+	s.Detected = nil
+
+	// auto seasonality tapers the training data so the leakage of periods which do not fit a whole
+	// number of times in the training range is not mistaken for harmonics or long periods
+	p, err := newPeriodogram(t, y, s.Auto)
+	if err != nil || p == nil {
+		return err
+	}
+	if s.Auto {
+		s.Detected = s.Detect.autoSeasonality(p, configured)
+	} else {
+		s.Detected = s.Detect.detectSeasonality(p, configured)
+	}
+	for _, d := range s.Detected {
+		s.SeasonalityConfigs = append(s.SeasonalityConfigs, SeasonalityConfig{
+			Name:   d.Name,
+			Orders: d.Orders,
+			Period: d.Period,
+		})
+	}
+	return nil
+}
+
+// periodogram is the power of every frequency bin of the linearly detrended training data resampled
+// onto a regular grid of n samples of the sampling interval, optionally tapered by a Hann window. The
+// floor is the median power excluding the zero frequency.
+type periodogram struct {
+	power []float64
+	floor float64
+	n     int
+	freq  time.Duration
+}
+
+// newPeriodogram computes the periodogram of the training data returning nil if there are too few
+// points
+func newPeriodogram(t []time.Time, y []float64, taper bool) (*periodogram, error) {
+	if len(t) < 4 {
+		return nil, nil
+	}
+	freq, err := timedataset.TimeSlice(t).EstimateFreq()
+	if err != nil {
+		return nil, fmt.Errorf("unable to estimate sampling interval for seasonality detection, %w", err)
+	}
+	if freq <= 0 {
+		return nil, nil
+	}
+
+	grid := resampleDetrended(t, y, freq)
+	n := len(grid)
+	if n < 4 {
+		return nil, nil
+	}
+
+	if taper {
+		for i := range grid {
+			grid[i] *= 0.5 - 0.5*math.Cos(2.0*math.Pi*float64(i)/float64(n-1))
+		}
+	}
+
+	fft := fourier.NewFFT(n)
+	coef := fft.Coefficients(nil, grid)
+	power := make([]float64, len(coef))
+	for i, c := range coef {
+		power[i] = real(c)*real(c) + imag(c)*imag(c)
+	}
+
+	// median power excluding the zero frequency is the noise floor
+	sorted := make([]float64, len(power)-1)
+	copy(sorted, power[1:])
+	sort.Float64s(sorted)
+	return &periodogram{
+		power: power,
+		floor: stat.Quantile(0.5, stat.Empirical, sorted, nil),
+		n:     n,
+		freq:  freq,
+	}, nil
+}
+
+// peak is a local maximum of the periodogram at a fractional frequency bin
+type peak struct {
+	period time.Duration
+	bin    float64
+	power  float64
+}
+
+// peaks returns the periodogram peaks with at least the threshold power within the period limits in
+// descending power
+func (d SeasonalityDetectOptions) peaks(p *periodogram) []peak {
+	minPeriod := d.MinPeriod
+	if minPeriod <= 0 {
+		minPeriod = 2 * p.freq
+	}
+	maxPeriod := d.MaxPeriod
+	if maxPeriod <= 0 {
+		maxPeriod = time.Duration(p.n) * p.freq / 2
+	}
+
+	var peaks []peak
+	power := p.power
+	for i := 1; i < len(power)-1; i++ {
+		if power[i] <= power[i-1] || power[i] < power[i+1] || !d.significant(p, power[i]) {
+			continue
+		}
+
+		// refine the peak location between bins with a parabola through the neighboring log powers
+		offset := 0.0
+		l, c, r := math.Log(power[i-1]), math.Log(power[i]), math.Log(power[i+1])
+		if denom := l - 2*c + r; denom < 0 && !math.IsInf(l, 0) && !math.IsInf(r, 0) {
+			offset = 0.5 * (l - r) / denom
+		}
+		bin := float64(i) + offset
+		period := time.Duration(float64(p.freq) * float64(p.n) / bin).Round(time.Second)
+		if period < minPeriod || period > maxPeriod {
+			continue
+		}
+		peaks = append(peaks, peak{period: period, bin: bin, power: power[i]})
+	}
+	sort.Slice(peaks, func(i, j int) bool {
+		return peaks[i].power > peaks[j].power
+	})
+	return peaks
+}
+
+// significant returns true if the power is at least the threshold times the noise floor
+func (d SeasonalityDetectOptions) significant(p *periodogram, power float64) bool {
+	threshold := d.Threshold
+	if threshold <= 0 {
+		threshold = DefaultDetectThreshold
+	}
+	return power >= threshold*p.floor
+}
+
+// topK returns the maximum number of detected seasonalities
+func (d SeasonalityDetectOptions) topK() int {
+	if d.TopK <= 0 {
+		return DefaultDetectTopK
+	}
+	return d.TopK
+}
+
+// detectSeasonality returns the strongest periodogram peaks which are not harmonics of a configured
+// seasonality each with Orders fourier orders
+func (d SeasonalityDetectOptions) detectSeasonality(p *periodogram, configured []SeasonalityConfig) []DetectedSeasonality {
+	orders := d.Orders
+	if orders <= 0 {
+		orders = 1
+	}
+	var detected []DetectedSeasonality
+	for _, pk := range d.peaks(p) {
+		if len(detected) == d.topK() {
+			break
+		}
+		if harmonicOf(pk.period, configured, p.n, p.freq) {
+			continue
+		}
+		detected = append(detected, DetectedSeasonality{
+			Name:   DetectedSeasonalityName(pk.period),
+			Period: pk.period,
+			Orders: orders,
+			Power:  pk.power / p.floor,
+		})
+	}
+	return detected
+}
+
+// autoSeasonality returns the strongest periodogram peaks in descending power skipping harmonics of the
+// configured and previously selected seasonalities. Periods within a frequency bin of a daily, weekly,
+// or yearly cycle are snapped to that cycle and take its name. The orders of each period are the
+// highest of its harmonics up to Orders, defaulting to DefaultAutoMaxOrders, with a significant peak
+// that is not a harmonic of a previously selected seasonality.
+func (d SeasonalityDetectOptions) autoSeasonality(p *periodogram, configured []SeasonalityConfig) []DetectedSeasonality {
+	maxOrders := d.Orders
+	if maxOrders <= 0 {
+		maxOrders = DefaultAutoMaxOrders
+	}
+	canonical := []SeasonalityConfig{
+		NewDailySeasonalityConfig(1),
+		NewWeeklySeasonalityConfig(1),
+		NewYearlySeasonalityConfig(1),
+	}
+
+	selected := make([]SeasonalityConfig, len(configured))
+	copy(selected, configured)
+	var detected []DetectedSeasonality
+	for _, pk := range d.peaks(p) {
+		if len(detected) == d.topK() {
+			break
+		}
+		if harmonicOf(pk.period, selected, p.n, p.freq) {
+			continue
+		}
+
+		name := DetectedSeasonalityName(pk.period)
+		period := pk.period
+		for _, c := range canonical {
+			if harmonicOf(pk.period, []SeasonalityConfig{c}, p.n, p.freq) {
+				name, period = c.Name, c.Period
+				break
+			}
+		}
+
+		orders := 1
+		for k := 2; k <= maxOrders; k++ {
+			bin := pk.bin * float64(k)
+			if bin >= float64(len(p.power)-1) {
+				break
+			}
+			if harmonicOf(period/time.Duration(k), selected, p.n, p.freq) {
+				continue
+			}
+			if power, ok := p.peakNear(bin); ok && d.significant(p, power) {
+				orders = k
+			}
+		}
+
+		seasCfg := SeasonalityConfig{Name: name, Orders: orders, Period: period}
+		selected = append(selected, seasCfg)
+		detected = append(detected, DetectedSeasonality{
+			Name:   name,
+			Period: period,
+			Orders: orders,
+			Power:  pk.power / p.floor,
+		})
+	}
+	return detected
+}
+
+// peakNear returns the power of the local maximum of the periodogram within a bin of the fractional
+// bin. False is returned if the nearest bins only hold the smooth leakage of another peak.
+func (p *periodogram) peakNear(bin float64) (float64, bool) {
+	i := int(math.Round(bin))
+	for _, j := range []int{i, i - 1, i + 1} {
+		if j < 1 || j >= len(p.power)-1 {
+			continue
+		}
+		if p.power[j] >= p.power[j-1] && p.power[j] >= p.power[j+1] {
+			return p.power[j], true
+		}
+	}
+	return 0, false
+}
+
+// harmonicOf returns true if the period is within one frequency bin of a harmonic of any configured
+// seasonality
+func harmonicOf(period time.Duration, configured []SeasonalityConfig, n int, freq time.Duration) bool {
+	binWidth := 1.0 / (float64(n) * freq.Seconds())
+	f := 1.0 / period.Seconds()
+	for _, seasCfg := range configured {
+		if seasCfg.Period <= 0 {
+			continue
+		}
+		base := 1.0 / seasCfg.Period.Seconds()
+		for order := 1; order <= seasCfg.Orders; order++ {
+			if math.Abs(f-base*float64(order)) <= binWidth {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// resampleDetrended places the linearly detrended values on a regular grid of the sampling interval
+// filling missing points with zero
+func resampleDetrended(t []time.Time, y []float64, freq time.Duration) []float64 {
+	xs := make([]float64, 0, len(t))
+	ys := make([]float64, 0, len(t))
+	for i, tPnt := range t {
+		if math.IsNaN(y[i]) {
+			continue
+		}
+		xs = append(xs, tPnt.Sub(t[0]).Seconds())
+		ys = append(ys, y[i])
+	}
+	if len(xs) < 2 {
+		return nil
+	}
+	intercept, slope := stat.LinearRegression(xs, ys, nil, false)
+
+	n := int(t[len(t)-1].Sub(t[0])/freq) + 1
+	grid := make([]float64, n)
+	for i, x := range xs {
+		idx := int(math.Round(x / freq.Seconds()))
+		if idx < 0 || idx >= n {
+			continue
+		}
+		grid[idx] = ys[i] - (intercept + slope*x)
+	}
+	return grid
+}
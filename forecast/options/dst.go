@@ -2,20 +2,30 @@ package options
 
 import (
 	"log/slog"
+	"math"
 	"time"
+
+	"github.com/aouyang1/go-forecaster/feature"
 )
 
 // DSTOptions lets us adjust the time to account for Daylight Saving Time behavior changes
 // by timezone. In the presence of multiple timezones this will average out the effect evenly
 // across the input timezones. e.g America/Los_Angeles + Europe/London will shift the time by 30min
 // 2024-03-10 (America) to 2024-03-31 (Europe) and then by 60min on or after 2024-03-31.
+//
+// Setting Mixture instead models daily seasonality as a weighted mixture of daily components in the
+// local time of each timezone where the weights are fit from the data. This suits signals aggregating
+// regions of unequal size where the average shift misplaces the daily peak. The time is not adjusted
+// in this mode. The local components only differ across DST transitions so the training data should
+// span at least one transition for the weights to be identifiable.
 type DSTOptions struct {
 	Enabled           bool     `json:"enabled"`
 	TimezoneLocations []string `json:"timezone_locations"`
+	Mixture           bool     `json:"mixture"`
 }
 
 func (d DSTOptions) AdjustTime(t []time.Time) []time.Time {
-	if !d.Enabled {
+	if !d.Enabled || d.Mixture {
 		return t
 	}
 
@@ -28,14 +38,58 @@ func (d DSTOptions) AdjustTime(t []time.Time) []time.Time {
 	return newT
 }
 
-func loadLocationOffsets(names []string) []locDstOffset {
-	var offsets []locDstOffset
+func (d DSTOptions) mixtureEnabled() bool {
+	return d.Enabled && d.Mixture && len(d.TimezoneLocations) > 0
+}
+
+// generateMixtureFourierOrders generates the fourier features of the daily seasonality in the local
+// time of each loadable timezone location
+func (d DSTOptions) generateMixtureFourierOrders(tFeatures *feature.Set, orders []int, periodDur time.Duration) (*feature.Set, error) {
+	if tFeatures == nil {
+		return nil, ErrUnknownTimeFeature
+	}
+	tFeat, exists := tFeatures.Get(feature.NewTime(LabelTimeEpoch))
+	if !exists {
+		return nil, ErrUnknownTimeFeature
+	}
+
+	period := periodDur.Seconds()
+
+	x := feature.NewSet()
+	for _, loc := range loadLocations(d.TimezoneLocations) {
+		local := make([]float64, len(tFeat))
+		for i, epoch := range tFeat {
+			sec := math.Floor(epoch)
+			_, offset := time.Unix(int64(sec), int64((epoch-sec)*1e9)).In(loc).Zone()
+			local[i] = epoch + float64(offset)
+		}
+
+		name := SeasonalityFeatureName(MixtureSeasonalityName(loc.String()))
+		for _, order := range orders {
+			sinFeat, cosFeat := generateFourierComponent(local, order, period)
+			x.Set(feature.NewSeasonality(name, feature.FourierCompSin, order), sinFeat)
+			x.Set(feature.NewSeasonality(name, feature.FourierCompCos, order), cosFeat)
+		}
+	}
+	return x, nil
+}
+
+func loadLocations(names []string) []*time.Location {
+	var locs []*time.Location
 	for _, name := range names {
 		loc, err := time.LoadLocation(name)
 		if err != nil {
 			slog.Info("unable to load location, skipping", "location", name)
 			continue
 		}
+		locs = append(locs, loc)
+	}
+	return locs
+}
+
+func loadLocationOffsets(names []string) []locDstOffset {
+	var offsets []locDstOffset
+	for _, loc := range loadLocations(names) {
 		offset := getLocationDSTOffset(loc)
 		offsets = append(offsets, locDstOffset{
 			loc:    loc,
@@ -72,6 +126,18 @@ type locDstOffset struct {
 // adjustTime checks a time against all dst offsets ranges and adjusts it by checking if the time is
 // in a dst location offset range and finally averaging the cumulative offsets.
 func adjustTime(t time.Time, offsets []locDstOffset) time.Time {
+	return t.Add(dstShift(t, offsets))
+}
+
+// unadjustTime reverses adjustTime by finding the shift of the original time. Adjusted times within
+// the shift after leaving dst are ambiguous and are mapped to the original time after the transition.
+func unadjustTime(t time.Time, offsets []locDstOffset) time.Time {
+	orig := t.Add(-dstShift(t, offsets))
+	return t.Add(-dstShift(orig, offsets))
+}
+
+// dstShift averages the dst offsets of every location in dst at the input time
+func dstShift(t time.Time, offsets []locDstOffset) time.Duration {
 	var offsetSum int
 	for _, offset := range offsets {
 		locT := t.In(offset.loc)
@@ -80,7 +146,34 @@ func adjustTime(t time.Time, offsets []locDstOffset) time.Time {
 		}
 	}
 	if len(offsets) == 0 {
-		return t
+		return 0
 	}
-	return t.Add(time.Duration(offsetSum/len(offsets)) * time.Second)
+	return time.Duration(offsetSum/len(offsets)) * time.Second
+}
+
+// unadjustTimeFeatures returns time features with the dst adjustment removed from the epoch feature so
+// that seasonalities much longer than a day are not shifted by the daily dst correction
+func (d DSTOptions) unadjustTimeFeatures(tFeatures *feature.Set) (*feature.Set, error) {
+	if !d.Enabled || d.Mixture {
+		return tFeatures, nil
+	}
+	if tFeatures == nil {
+		return nil, ErrUnknownTimeFeature
+	}
+	tFeat, exists := tFeatures.Get(feature.NewTime(LabelTimeEpoch))
+	if !exists {
+		return nil, ErrUnknownTimeFeature
+	}
+
+	offsets := loadLocationOffsets(d.TimezoneLocations)
+	epoch := make([]float64, len(tFeat))
+	for i, adjEpoch := range tFeat {
+		sec := math.Floor(adjEpoch)
+		adjT := time.Unix(int64(sec), int64((adjEpoch-sec)*1e9))
+		epoch[i] = adjEpoch - adjT.Sub(unadjustTime(adjT, offsets)).Seconds()
+	}
+
+	x := feature.NewSet()
+	x.Set(feature.NewTime(LabelTimeEpoch), epoch)
+	return x, nil
 }
@@ -84,6 +84,15 @@ func TestAdjustTime(t *testing.T) {
 				time.Date(2024, 11, 3, 10, 0, 0, 0, time.UTC),
 			},
 		},
+		"mixture": {
+			opt: DSTOptions{
+				Enabled:           true,
+				TimezoneLocations: []string{TZAmericaLosAngeles, TZEuropeLondon},
+				Mixture:           true,
+			},
+			t:        losAngelesTransitionTimes,
+			expected: losAngelesTransitionTimes,
+		},
 	}
 
 	for name, td := range testData {
@@ -187,3 +196,64 @@ func TestAdjustTimeSingle(t *testing.T) {
 		})
 	}
 }
+
+func TestUnadjustTime(t *testing.T) {
+	testData := map[string]struct {
+		input    time.Time
+		zoneLoc  []string
+		expected time.Time
+	}{
+		"america std pre-dst spring": {
+			time.Date(2025, time.March, 9, 9, 59, 59, 0, time.UTC),
+			[]string{TZAmericaLosAngeles},
+			time.Date(2025, time.March, 9, 9, 59, 59, 0, time.UTC),
+		},
+		"america to dst spring": {
+			time.Date(2025, time.March, 9, 11, 0, 0, 0, time.UTC),
+			[]string{TZAmericaLosAngeles},
+			time.Date(2025, time.March, 9, 10, 0, 0, 0, time.UTC),
+		},
+		"america dst summer": {
+			time.Date(2024, time.July, 1, 12, 0, 0, 0, time.UTC),
+			[]string{TZAmericaLosAngeles},
+			time.Date(2024, time.July, 1, 11, 0, 0, 0, time.UTC),
+		},
+		"america ambiguous fall": {
+			time.Date(2024, time.November, 3, 9, 30, 0, 0, time.UTC),
+			[]string{TZAmericaLosAngeles},
+			time.Date(2024, time.November, 3, 9, 30, 0, 0, time.UTC),
+		},
+		"europe to std america dst fall": {
+			time.Date(2024, time.October, 27, 1, 30, 0, 0, time.UTC),
+			[]string{TZEuropeLondon, TZAmericaLosAngeles},
+			time.Date(2024, time.October, 27, 1, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			offsets := loadLocationOffsets(td.zoneLoc)
+			res := unadjustTime(td.input, offsets)
+			assert.Equal(t, td.expected, res)
+		})
+	}
+}
+
+func TestLocalLocation(t *testing.T) {
+	testData := map[string]struct {
+		opt      *Options
+		expected string
+	}{
+		"nil options":   {nil, "UTC"},
+		"no timezone":   {&Options{}, "UTC"},
+		"dst disabled":  {&Options{DSTOptions: DSTOptions{TimezoneLocations: []string{TZEuropeLondon}}}, "UTC"},
+		"dst enabled":   {&Options{DSTOptions: DSTOptions{Enabled: true, TimezoneLocations: []string{"Invalid/Zone", TZEuropeLondon}}}, TZEuropeLondon},
+		"weekend first": {&Options{DSTOptions: DSTOptions{Enabled: true, TimezoneLocations: []string{TZEuropeLondon}}, WeekendOptions: WeekendOptions{TimezoneOverride: TZAmericaLosAngeles}}, TZAmericaLosAngeles},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, td.expected, td.opt.LocalLocation().String())
+		})
+	}
+}
@@ -1,14 +1,15 @@
 package options
 
 import (
-	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"slices"
 	"strings"
 	"text/tabwriter"
 	"time"
 
+	"github.com/aouyang1/go-forecaster/errs"
 	"github.com/aouyang1/go-forecaster/feature"
 	"github.com/aouyang1/go-forecaster/forecast/util"
 	"github.com/aouyang1/go-forecaster/timedataset"
@@ -17,17 +18,214 @@ import (
 )
 
 var (
-	ErrStartAfterEnd = errors.New("event start time is after end time")
-	ErrUnsetTime     = errors.New("unset event start or end time")
-	ErrNoEventName   = errors.New("no event name")
+	ErrStartAfterEnd     = errs.NewConfigError(errs.CodeInvalidEvent, "event start time is after end time", nil)
+	ErrUnsetTime         = errs.NewConfigError(errs.CodeInvalidEvent, "unset event start or end time", nil)
+	ErrNoEventName       = errs.NewConfigError(errs.CodeInvalidEvent, "no event name", nil)
+	ErrInvalidRecurrence = errs.NewConfigError(errs.CodeInvalidEvent, "event recurrence must be at least the event duration", nil)
+	ErrUnexpandedEvent   = errs.NewConfigError(errs.CodeInvalidEvent, "times fall in occurrences of recurring events past their until time", nil)
+	ErrUnknownTimezone   = errs.NewConfigError(errs.CodeInvalidEvent, "unknown event timezone", nil)
+	ErrNegativeRamp      = errs.NewConfigError(errs.CodeInvalidEvent, "event ramp up and ramp down must be non-negative", nil)
+	ErrInvalidIntensity  = errs.NewConfigError(errs.CodeInvalidEvent, "event intensity must be non-negative and sorted by time", nil)
 )
 
 // Event represents a time span to model separately for bias and for seasonality
-// changes.
+// changes. Lags optionally add a bias feature per lag for the same span shifted later
+// in time to capture delayed effects of the event e.g. traffic hours after a marketing push.
+// A non-zero Recurrence repeats the span every Recurrence after Start sharing a single feature
+// across all occurrences, e.g. a weekly maintenance window. Occurrences starting after a
+// non-zero Until are not modeled unless the event options auto expand recurring events.
+// Setting Timezone interprets Start, End, and Until as wall clock times in that location ignoring
+// their own location, e.g. store hours of 09:00 to 17:00 stay at 09:00 to 17:00 local time in every
+// occurrence across DST transitions.
+//
+// A non-empty Schedule instead repeats the span at every start time of an RFC 5545 RRULE such as
+// "FREQ=MONTHLY;BYDAY=1MO;BYHOUR=9" or a five field cron expression such as "0 9 * * MON-FRI", e.g.
+// the first Monday of every month for 6 hours. The schedule is evaluated in the location of Start, or
+// Timezone if set, with Start as the RRULE DTSTART, and every occurrence lasts End minus Start. A
+// Schedule cannot be combined with a Recurrence.
+//
+// MaskWindow overrides the mask window function of the options for this event. RampUp and RampDown
+// linearly ramp the mask from zero to one over the duration before the start of every occurrence and
+// from one back to zero over the duration after its end, e.g. a promotion with a sharp start and a
+// slow decay has no ramp up and a long ramp down.
+//
+// Magnitude scales the mask of every occurrence, e.g. the size of a discount, so that a single
+// coefficient learns an effect proportional to it. A zero magnitude is unset and scales by one.
+// Intensity is a step series scaling the mask over time, e.g. the marketing spend of each day of a
+// promotion, where each value holds from its time until the next point. Times before the first point
+// are scaled by the magnitude.
 type Event struct {
-	Name  string
-	Start time.Time
-	End   time.Time
+	Name       string
+	Start      time.Time
+	End        time.Time
+	Lags       []time.Duration
+	Recurrence time.Duration
+	Until      time.Time
+	Timezone   string
+	Schedule   string           `json:",omitempty"`
+	MaskWindow string           `json:",omitempty"`
+	RampUp     time.Duration    `json:",omitempty"`
+	RampDown   time.Duration    `json:",omitempty"`
+	Magnitude  float64          `json:",omitempty"`
+	Intensity  []IntensityPoint `json:",omitempty"`
+}
+
+// IntensityPoint is the intensity of an event starting at the time
+type IntensityPoint struct {
+	Time  time.Time `json:"time"`
+	Value float64   `json:"value"`
+}
+
+// intensityAt returns the scale of the event mask at the time
+func (e Event) intensityAt(tPnt time.Time) float64 {
+	// index of the first point after the time
+	idx, _ := slices.BinarySearchFunc(e.Intensity, tPnt, func(p IntensityPoint, t time.Time) int {
+		if p.Time.After(t) {
+			return 1
+		}
+		return -1
+	})
+	if idx > 0 {
+		return e.Intensity[idx-1].Value
+	}
+	if e.Magnitude == 0 {
+		return 1
+	}
+	return e.Magnitude
+}
+
+// scaled returns true if the event scales its mask by a magnitude or intensity
+func (e Event) scaled() bool {
+	return e.Magnitude != 0 || len(e.Intensity) > 0
+}
+
+// location returns the location of the wall clock boundaries or nil if the boundaries are absolute
+func (e Event) location() *time.Location {
+	if e.Timezone == "" {
+		return nil
+	}
+	loc, err := time.LoadLocation(e.Timezone)
+	if err != nil {
+		return nil
+	}
+	return loc
+}
+
+// wallClock returns the time with the same wall clock reading in the input location
+func wallClock(t time.Time, loc *time.Location) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc)
+}
+
+// bounds returns the absolute start and end time of the first occurrence of the event
+func (e Event) bounds() (time.Time, time.Time) {
+	loc := e.location()
+	if loc == nil {
+		return e.Start, e.End
+	}
+	return wallClock(e.Start, loc), wallClock(e.End, loc)
+}
+
+// until returns the absolute until time of the event
+func (e Event) until() time.Time {
+	loc := e.location()
+	if loc == nil || e.Until.IsZero() {
+		return e.Until
+	}
+	return wallClock(e.Until, loc)
+}
+
+// NewRecurringEvent creates an event repeating every recurrence after the first occurrence
+func NewRecurringEvent(name string, start, end time.Time, recurrence time.Duration, until time.Time) Event {
+	return Event{
+		Name:       name,
+		Start:      start,
+		End:        end,
+		Recurrence: recurrence,
+		Until:      until,
+	}
+}
+
+// occurrences returns the occurrences of the event overlapping the input time range. Occurrences
+// starting after Until are only included if expand is set.
+func (e Event) occurrences(start, end time.Time, expand bool) []Event {
+	if e.Schedule != "" {
+		return e.scheduledOccurrences(start, end, expand)
+	}
+
+	loc := e.location()
+	if e.Recurrence <= 0 {
+		if loc == nil {
+			return []Event{e}
+		}
+		evStart, evEnd := e.bounds()
+		return []Event{{Name: e.Name, Start: evStart, End: evEnd, Magnitude: e.Magnitude, Intensity: e.Intensity}}
+	}
+
+	// occurrences are shifted in wall clock time when a timezone is set so that the boundaries keep
+	// their local time across DST transitions
+	shifted := func(t time.Time, shift time.Duration) time.Time {
+		if loc == nil {
+			return t.Add(shift)
+		}
+		return wallClock(wallClock(t, time.UTC).Add(shift), loc)
+	}
+
+	// first occurrence ending after the range start allowing for the largest utc offset of a wall clock
+	// end time
+	var k int64
+	if start.After(e.End) {
+		diff := start.Sub(e.End)
+		if loc != nil {
+			diff -= 24 * time.Hour
+		}
+		if diff > 0 {
+			k = int64(diff / e.Recurrence)
+		}
+	}
+
+	until := e.until()
+	var occs []Event
+	for ; ; k++ {
+		shift := time.Duration(k) * e.Recurrence
+		occStart := shifted(e.Start, shift)
+		if occStart.After(end) {
+			break
+		}
+		if !expand && !until.IsZero() && occStart.After(until) {
+			break
+		}
+		occEnd := shifted(e.End, shift)
+		if occEnd.Before(start) {
+			continue
+		}
+		occs = append(occs, Event{Name: e.Name, Start: occStart, End: occEnd, Magnitude: e.Magnitude, Intensity: e.Intensity})
+	}
+	return occs
+}
+
+// recurs returns true if the event repeats on a fixed interval or a schedule
+func (e Event) recurs() bool {
+	return e.Recurrence > 0 || e.Schedule != ""
+}
+
+// shiftOccurrences returns the occurrences moved later in time by the lag along with their intensity
+func shiftOccurrences(occs []Event, lag time.Duration) []Event {
+	shifted := make([]Event, len(occs))
+	for i, occ := range occs {
+		shifted[i] = Event{
+			Name:      occ.Name,
+			Start:     occ.Start.Add(lag),
+			End:       occ.End.Add(lag),
+			Magnitude: occ.Magnitude,
+		}
+		if len(occ.Intensity) > 0 {
+			shifted[i].Intensity = make([]IntensityPoint, len(occ.Intensity))
+			for j, p := range occ.Intensity {
+				shifted[i].Intensity[j] = IntensityPoint{Time: p.Time.Add(lag), Value: p.Value}
+			}
+		}
+	}
+	return shifted
 }
 
 func NewEvent(name string, start, end time.Time) Event {
@@ -48,9 +246,43 @@ func (e *Event) Valid() error {
 	if e.Name == "" {
 		return ErrNoEventName
 	}
+	if e.Recurrence < 0 || (e.Recurrence > 0 && e.Recurrence < e.End.Sub(e.Start)) {
+		return ErrInvalidRecurrence
+	}
+	if e.Timezone != "" && e.location() == nil {
+		return fmt.Errorf("%q, %w", e.Timezone, ErrUnknownTimezone)
+	}
+	if e.Schedule != "" {
+		if err := e.validSchedule(); err != nil {
+			return err
+		}
+	}
+	if e.RampUp < 0 || e.RampDown < 0 {
+		return ErrNegativeRamp
+	}
+	if e.Magnitude < 0 {
+		return fmt.Errorf("magnitude %f, %w", e.Magnitude, ErrInvalidIntensity)
+	}
+	for i, p := range e.Intensity {
+		if p.Value < 0 {
+			return fmt.Errorf("value %f at %s, %w", p.Value, p.Time, ErrInvalidIntensity)
+		}
+		if i > 0 && !p.Time.After(e.Intensity[i-1].Time) {
+			return fmt.Errorf("point at %s, %w", p.Time, ErrInvalidIntensity)
+		}
+	}
 	return nil
 }
 
+// maskShape returns the shape of the event mask using the window function of the options unless the
+// event overrides it
+func (e Event) maskShape(winFunc func([]float64) []float64) maskShape {
+	if e.MaskWindow != "" {
+		winFunc = WindowFunc(e.MaskWindow)
+	}
+	return maskShape{window: winFunc, rampUp: e.RampUp, rampDown: e.RampDown}
+}
+
 func Christmas(start, end time.Time, durBefore, durAfter time.Duration) []Event {
 	return Holiday(us.ChristmasDay, start, end, durBefore, durAfter)
 }
@@ -81,71 +313,182 @@ func Holiday(hol *cal.Holiday, start, end time.Time, durBefore, durAfter time.Du
 	return events
 }
 
+// EventOptions configures the events to model. Setting AutoExpand models every occurrence of recurring
+// events through the input time range ignoring their Until time so that predictions past the configured
+// occurrences keep the recurrence. Holidays adds a recurring event per holiday of the configured country
+// calendars. Custom event series are user implementations serialized with the codec registered for
+// their type. Interactions multiply every event by the growth or changepoint biases.
 type EventOptions struct {
-	Events []Event `json:"events"`
+	Events       []Event                 `json:"events"`
+	AutoExpand   bool                    `json:"auto_expand"`
+	Holidays     HolidayOptions          `json:"holidays"`
+	Custom       []CustomEventSeries     `json:"custom,omitempty"`
+	Interactions EventInteractionOptions `json:"interactions"`
 }
 
-func (e EventOptions) generateEventMask(t []time.Time, eFeat *feature.Set, winFunc func([]float64) []float64) {
-	if len(t) < 2 {
-		return
+// UnexpandedEvents returns the names of recurring events with occurrences past their Until time that
+// contain any of the input times. These occurrences are not modeled without AutoExpand which silently
+// assumes the event does not recur.
+func (e EventOptions) UnexpandedEvents(t []time.Time) []string {
+	if e.AutoExpand || len(t) == 0 {
+		return nil
 	}
-
 	ts := timedataset.TimeSlice(t)
-	freq, err := ts.EstimateFreq()
-	if err != nil {
-		panic(err)
-	}
 	start := ts.StartTime()
 	end := ts.EndTime()
-	tOrig := t
+
+	var names []string
 	for _, ev := range e.Events {
-		if err := ev.Valid(); err != nil {
-			slog.Warn("not separately modelling invalid event", "name", ev.Name, "error", err.Error())
+		if !ev.recurs() || ev.Until.IsZero() || ev.Valid() != nil {
 			continue
 		}
+		var unexpanded []Event
+		for _, occ := range ev.occurrences(start, end, true) {
+			if occ.Start.After(ev.until()) {
+				unexpanded = append(unexpanded, occ)
+			}
+		}
+		if len(unexpanded) == 0 {
+			continue
+		}
+		for _, tPnt := range t {
+			if inEvents(tPnt, unexpanded) {
+				names = append(names, ev.Name)
+				break
+			}
+		}
+	}
+	return names
+}
 
-		t := tOrig
-		// pad beginning
-		var startIdx int
-		if ev.Start.Before(start) {
-			diff := start.Sub(ev.Start)
-			numElem := int(diff/freq) + 1
-			startIdx = numElem
-
-			prefix := make([]time.Time, numElem)
-			for i := 0; i < numElem; i++ {
-				prefix[i] = start.Add(-time.Duration(numElem-i) * freq)
+// inEvents returns true if the time is within any of the event spans
+// occurrenceIntensity returns the scale of the mask of the occurrence containing each time or nil if
+// none of the occurrences are scaled
+func occurrenceIntensity(occs []Event) func(tPnt time.Time) float64 {
+	if !slices.ContainsFunc(occs, Event.scaled) {
+		return nil
+	}
+	return func(tPnt time.Time) float64 {
+		for _, ev := range occs {
+			if (tPnt.After(ev.Start) || tPnt.Equal(ev.Start)) && tPnt.Before(ev.End) {
+				return ev.intensityAt(tPnt)
 			}
-			t = append(prefix, t...)
 		}
+		return 1
+	}
+}
 
-		// pad end
-		endIdx := len(t)
-		if ev.End.After(end) {
-			diff := ev.End.Sub(end)
-			numElem := int(diff/freq) + 1
+func inEvents(tPnt time.Time, events []Event) bool {
+	for _, ev := range events {
+		if (tPnt.After(ev.Start) || tPnt.Equal(ev.Start)) && tPnt.Before(ev.End) {
+			return true
+		}
+	}
+	return false
+}
 
-			suffix := make([]time.Time, numElem)
-			for i := 0; i < numElem; i++ {
-				suffix[i] = end.Add(time.Duration(i+1) * freq)
-			}
-			t = append(t, suffix...)
+func (e EventOptions) generateEventMask(t []time.Time, freq time.Duration, eFeat *feature.Set, winFunc func([]float64) []float64) {
+	ts := timedataset.TimeSlice(t)
+	for _, ev := range e.Events {
+		if err := ev.Valid(); err != nil {
+			slog.Warn("not separately modelling invalid event", "name", ev.Name, "error", err.Error())
+			continue
 		}
 
-		feat := feature.NewEvent(strings.ReplaceAll(ev.Name, " ", "_"))
-		if _, exists := eFeat.Get(feat); exists {
-			slog.Warn("event feature already exists", "event_name", ev.Name)
+		generateSingleEventMask(t, freq, ev, 0, e.AutoExpand, eFeat, winFunc)
+		for _, lag := range ev.Lags {
+			if lag == 0 {
+				continue
+			}
+			generateSingleEventMask(t, freq, ev, lag, e.AutoExpand, eFeat, winFunc)
+		}
+	}
+	for _, c := range e.Custom {
+		if err := c.Valid(); err != nil {
+			slog.Warn("not separately modelling invalid custom event series", "type", c.Type, "error", err.Error())
 			continue
 		}
+		occs, err := c.Series.Occurrences(ts.StartTime(), ts.EndTime())
+		if err != nil {
+			slog.Warn("not separately modelling custom event series", "name", c.Series.Name(), "error", err.Error())
+			continue
+		}
+		generateOccurrencesMask(t, freq, c.Series.Name(), occs, eFeat, maskShape{window: winFunc})
+	}
+	e.Holidays.generateEventMask(t, freq, eFeat, winFunc)
+}
 
-		eventMask := generateEventMaskWithFunc(t, func(tPnt time.Time) bool {
-			return (tPnt.After(ev.Start) || tPnt.Equal(ev.Start)) && tPnt.Before(ev.End)
-		}, winFunc)
+// generateSingleEventMask sets the event feature of the occurrences of the event shifted later in time
+// by the lag where a non-zero lag is modeled as a separate feature
+func generateSingleEventMask(t []time.Time, freq time.Duration, ev Event, lag time.Duration, expand bool, eFeat *feature.Set, winFunc func([]float64) []float64) {
+	ts := timedataset.TimeSlice(t)
+	shape := ev.maskShape(winFunc)
 
-		// truncate result to start/end
-		eventMask = eventMask[startIdx:endIdx]
-		eFeat.Set(feat, eventMask)
+	// occurrences just outside of the time range still ramp into it
+	occs := ev.occurrences(ts.StartTime().Add(-shape.rampDown-lag), ts.EndTime().Add(shape.rampUp-lag), expand)
+	name := ev.Name
+	if lag != 0 {
+		name = LaggedEventName(ev.Name, lag)
+		occs = shiftOccurrences(occs, lag)
 	}
+	generateOccurrencesMask(t, freq, name, occs, eFeat, shape)
+}
+
+// generateOccurrencesMask sets a single event feature of the name masking every occurrence which must
+// be sorted by start time
+func generateOccurrencesMask(t []time.Time, freq time.Duration, name string, occs []Event, eFeat *feature.Set, shape maskShape) {
+	ts := timedataset.TimeSlice(t)
+	start := ts.StartTime()
+	end := ts.EndTime()
+
+	feat := feature.NewEvent(strings.ReplaceAll(name, " ", "_"))
+	if _, exists := eFeat.Get(feat); exists {
+		slog.Warn("event feature already exists", "event_name", name)
+		return
+	}
+
+	if len(occs) == 0 {
+		eFeat.Set(feat, make([]float64, len(t)))
+		return
+	}
+	spanStart := occs[0].Start.Add(-shape.rampUp)
+	spanEnd := occs[len(occs)-1].End.Add(shape.rampDown)
+
+	// pad beginning
+	var startIdx int
+	if spanStart.Before(start) {
+		diff := start.Sub(spanStart)
+		numElem := int(diff/freq) + 1
+		startIdx = numElem
+
+		prefix := make([]time.Time, numElem)
+		for i := 0; i < numElem; i++ {
+			prefix[i] = start.Add(-time.Duration(numElem-i) * freq)
+		}
+		t = append(prefix, t...)
+	}
+
+	// pad end
+	endIdx := len(t)
+	if spanEnd.After(end) {
+		diff := spanEnd.Sub(end)
+		numElem := int(diff/freq) + 1
+
+		suffix := make([]time.Time, numElem)
+		for i := 0; i < numElem; i++ {
+			suffix[i] = end.Add(time.Duration(i+1) * freq)
+		}
+		t = append(t, suffix...)
+	}
+
+	shape.intensity = occurrenceIntensity(occs)
+	eventMask := generateShapedMask(t, func(tPnt time.Time) bool {
+		return inEvents(tPnt, occs)
+	}, shape)
+
+	// truncate result to start/end
+	eventMask = eventMask[startIdx:endIdx]
+	eFeat.Set(feat, eventMask)
 }
 
 func (e EventOptions) TablePrint(w io.Writer, prefix, indent string, indentGrowth int) error {
@@ -161,10 +504,55 @@ func (e EventOptions) TablePrint(w io.Writer, prefix, indent string, indentGrowt
 			prefix, util.IndentExpand(indent, indentGrowth+1),
 			ev.Name, ev.Start, ev.End)
 	}
+	if err := tbl.Flush(); err != nil {
+		return err
+	}
+	if e.Interactions.Enabled() {
+		var interactions []string
+		if e.Interactions.Growth {
+			interactions = append(interactions, LabelEventGrowth)
+		}
+		if e.Interactions.ChangepointBias {
+			interactions = append(interactions, "changepoint bias")
+		}
+		fmt.Fprintf(w, "%s%sEvent Interactions: %s\n", prefix, util.IndentExpand(indent, indentGrowth), strings.Join(interactions, ", "))
+	}
+	if len(e.Custom) == 0 {
+		return nil
+	}
+
+	fmt.Fprintf(w, "%s%sCustom Event Series:\n", prefix, util.IndentExpand(indent, indentGrowth))
+	fmt.Fprintf(tbl, "%s%sName\tType\t\n", prefix, util.IndentExpand(indent, indentGrowth+1))
+	for _, c := range e.Custom {
+		var name string
+		if c.Series != nil {
+			name = c.Series.Name()
+		}
+		fmt.Fprintf(tbl, "%s%s%s\t%s\t\n",
+			prefix, util.IndentExpand(indent, indentGrowth+1),
+			name, c.Type)
+	}
 	return tbl.Flush()
 }
 
+// maskShape is the window function applied to every masked span along with the durations of the
+// linear ramps before and after each span. A non-nil intensity scales each masked time and the ramps
+// by the intensity at the boundary of their span.
+type maskShape struct {
+	window    func(seq []float64) []float64
+	rampUp    time.Duration
+	rampDown  time.Duration
+	intensity func(tPnt time.Time) float64
+}
+
 func generateEventMaskWithFunc(t []time.Time, maskCond func(tPnt time.Time) bool, windowFunc func(seq []float64) []float64) []float64 {
+	return generateShapedMask(t, maskCond, maskShape{window: windowFunc})
+}
+
+// generateShapedMask masks the times meeting the condition applying the window function to each
+// contiguous span and ramping linearly into and out of the span. Overlapping ramps and spans take the
+// larger value.
+func generateShapedMask(t []time.Time, maskCond func(tPnt time.Time) bool, shape maskShape) []float64 {
 	mask := make([]float64, len(t))
 	var maskSpans [][2]int
 	var inMask bool
@@ -190,7 +578,41 @@ func generateEventMaskWithFunc(t []time.Time, maskCond func(tPnt time.Time) bool
 	}
 
 	for _, maskSpan := range maskSpans {
-		windowFunc(mask[maskSpan[0]:maskSpan[1]])
+		shape.window(mask[maskSpan[0]:maskSpan[1]])
+	}
+	intensity := shape.intensity
+	if intensity != nil {
+		for _, maskSpan := range maskSpans {
+			for i := maskSpan[0]; i < maskSpan[1]; i++ {
+				mask[i] *= intensity(t[i])
+			}
+		}
+	} else {
+		intensity = func(time.Time) float64 { return 1 }
+	}
+	if shape.rampUp <= 0 && shape.rampDown <= 0 {
+		return mask
+	}
+
+	ramped := make([]float64, len(mask))
+	copy(ramped, mask)
+	for _, maskSpan := range maskSpans {
+		first, last := t[maskSpan[0]], t[maskSpan[1]-1]
+		firstScale, lastScale := intensity(first), intensity(last)
+		for i := maskSpan[0] - 1; i >= 0 && shape.rampUp > 0; i-- {
+			dist := first.Sub(t[i])
+			if dist >= shape.rampUp {
+				break
+			}
+			ramped[i] = max(ramped[i], firstScale*(1-float64(dist)/float64(shape.rampUp)))
+		}
+		for i := maskSpan[1]; i < len(t) && shape.rampDown > 0; i++ {
+			dist := t[i].Sub(last)
+			if dist >= shape.rampDown {
+				break
+			}
+			ramped[i] = max(ramped[i], lastScale*(1-float64(dist)/float64(shape.rampDown)))
+		}
 	}
-	return mask
+	return ramped
 }
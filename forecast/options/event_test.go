@@ -5,9 +5,11 @@ import (
 	"testing"
 	"time"
 
+	"github.com/aouyang1/go-forecaster/feature"
 	"github.com/rickar/cal/v2"
 	"github.com/rickar/cal/v2/us"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestHoliday(t *testing.T) {
@@ -35,14 +37,14 @@ func TestHoliday(t *testing.T) {
 			durAfter:  0,
 			expected: []Event{
 				{
-					"Christmas_Day_2024",
-					time.Date(2024, 12, 25, 0, 0, 0, 0, time.UTC),
-					time.Date(2024, 12, 26, 0, 0, 0, 0, time.UTC),
+					Name:  "Christmas_Day_2024",
+					Start: time.Date(2024, 12, 25, 0, 0, 0, 0, time.UTC),
+					End:   time.Date(2024, 12, 26, 0, 0, 0, 0, time.UTC),
 				},
 				{
-					"Christmas_Day_2025",
-					time.Date(2025, 12, 25, 0, 0, 0, 0, time.UTC),
-					time.Date(2025, 12, 26, 0, 0, 0, 0, time.UTC),
+					Name:  "Christmas_Day_2025",
+					Start: time.Date(2025, 12, 25, 0, 0, 0, 0, time.UTC),
+					End:   time.Date(2025, 12, 26, 0, 0, 0, 0, time.UTC),
 				},
 			},
 		},
@@ -54,14 +56,14 @@ func TestHoliday(t *testing.T) {
 			durAfter:  0,
 			expected: []Event{
 				{
-					"Christmas_Day_2024",
-					time.Date(2024, 12, 25, 0, 0, 0, 0, time.FixedZone("UTC-8", -8*60*60)),
-					time.Date(2024, 12, 26, 0, 0, 0, 0, time.FixedZone("UTC-8", -8*60*60)),
+					Name:  "Christmas_Day_2024",
+					Start: time.Date(2024, 12, 25, 0, 0, 0, 0, time.FixedZone("UTC-8", -8*60*60)),
+					End:   time.Date(2024, 12, 26, 0, 0, 0, 0, time.FixedZone("UTC-8", -8*60*60)),
 				},
 				{
-					"Christmas_Day_2025",
-					time.Date(2025, 12, 25, 0, 0, 0, 0, time.FixedZone("UTC-8", -8*60*60)),
-					time.Date(2025, 12, 26, 0, 0, 0, 0, time.FixedZone("UTC-8", -8*60*60)),
+					Name:  "Christmas_Day_2025",
+					Start: time.Date(2025, 12, 25, 0, 0, 0, 0, time.FixedZone("UTC-8", -8*60*60)),
+					End:   time.Date(2025, 12, 26, 0, 0, 0, 0, time.FixedZone("UTC-8", -8*60*60)),
 				},
 			},
 		},
@@ -73,14 +75,14 @@ func TestHoliday(t *testing.T) {
 			durAfter:  time.Duration(2 * 24 * time.Hour),
 			expected: []Event{
 				{
-					"Christmas_Day_2024",
-					time.Date(2024, 12, 24, 0, 0, 0, 0, time.UTC),
-					time.Date(2024, 12, 28, 0, 0, 0, 0, time.UTC),
+					Name:  "Christmas_Day_2024",
+					Start: time.Date(2024, 12, 24, 0, 0, 0, 0, time.UTC),
+					End:   time.Date(2024, 12, 28, 0, 0, 0, 0, time.UTC),
 				},
 				{
-					"Christmas_Day_2025",
-					time.Date(2025, 12, 24, 0, 0, 0, 0, time.UTC),
-					time.Date(2025, 12, 28, 0, 0, 0, 0, time.UTC),
+					Name:  "Christmas_Day_2025",
+					Start: time.Date(2025, 12, 24, 0, 0, 0, 0, time.UTC),
+					End:   time.Date(2025, 12, 28, 0, 0, 0, 0, time.UTC),
 				},
 			},
 		},
@@ -96,10 +98,14 @@ func TestHoliday(t *testing.T) {
 
 func TestValid(t *testing.T) {
 	testData := map[string]struct {
-		name  string
-		start time.Time
-		end   time.Time
-		err   error
+		name       string
+		start      time.Time
+		end        time.Time
+		recurrence time.Duration
+		rampDown   time.Duration
+		magnitude  float64
+		intensity  []IntensityPoint
+		err        error
 	}{
 		"unset start time": {
 			end:  time.Now(),
@@ -122,19 +128,59 @@ func TestValid(t *testing.T) {
 			end:   time.Now(),
 			err:   ErrNoEventName,
 		},
+		"recurrence shorter than event": {
+			start:      time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC),
+			end:        time.Date(1970, 1, 1, 1, 0, 0, 0, time.UTC),
+			name:       "blargh",
+			recurrence: time.Minute,
+			err:        ErrInvalidRecurrence,
+		},
+		"negative ramp": {
+			start:    time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC),
+			end:      time.Date(1970, 1, 1, 1, 0, 0, 0, time.UTC),
+			name:     "blargh",
+			rampDown: -time.Hour,
+			err:      ErrNegativeRamp,
+		},
+		"negative magnitude": {
+			start:     time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC),
+			end:       time.Date(1970, 1, 1, 1, 0, 0, 0, time.UTC),
+			name:      "blargh",
+			magnitude: -1,
+			err:       ErrInvalidIntensity,
+		},
+		"unsorted intensity": {
+			start: time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC),
+			end:   time.Date(1970, 1, 1, 1, 0, 0, 0, time.UTC),
+			name:  "blargh",
+			intensity: []IntensityPoint{
+				{Time: time.Date(1970, 1, 1, 1, 0, 0, 0, time.UTC), Value: 1},
+				{Time: time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC), Value: 2},
+			},
+			err: ErrInvalidIntensity,
+		},
 		"valid": {
 			start: time.Now().Add(-time.Hour),
 			end:   time.Now(),
 			name:  "blargh",
 		},
+		"valid recurrence": {
+			start:      time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC),
+			end:        time.Date(1970, 1, 1, 1, 0, 0, 0, time.UTC),
+			name:       "blargh",
+			recurrence: time.Hour,
+		},
 	}
 
 	for name, td := range testData {
 		t.Run(name, func(t *testing.T) {
-			e := NewEvent(td.name, td.start, td.end)
+			e := NewRecurringEvent(td.name, td.start, td.end, td.recurrence, time.Time{})
+			e.RampDown = td.rampDown
+			e.Magnitude = td.magnitude
+			e.Intensity = td.intensity
 			err := e.Valid()
 			if td.err != nil {
-				assert.EqualError(t, err, td.err.Error())
+				assert.ErrorIs(t, err, td.err)
 				return
 			}
 			assert.NoError(t, err)
@@ -187,3 +233,218 @@ func TestEventTablePrint(t *testing.T) {
 		})
 	}
 }
+
+func TestEventLags(t *testing.T) {
+	start := time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC)
+	tSeries := make([]time.Time, 8)
+	for i := range tSeries {
+		tSeries[i] = start.Add(time.Duration(i) * time.Hour)
+	}
+
+	opt := EventOptions{
+		Events: []Event{
+			{
+				Name:  "promo",
+				Start: start.Add(time.Hour),
+				End:   start.Add(3 * time.Hour),
+				Lags:  []time.Duration{0, time.Hour, 3 * time.Hour},
+			},
+		},
+	}
+	eFeat := feature.NewSet()
+	opt.generateEventMask(tSeries, time.Hour, eFeat, WindowFunc(""))
+
+	expected := map[string][]float64{
+		"event_promo":        {0, 1, 1, 0, 0, 0, 0, 0},
+		"event_promo_lag_1h": {0, 0, 1, 1, 0, 0, 0, 0},
+		"event_promo_lag_3h": {0, 0, 0, 0, 1, 1, 0, 0},
+	}
+	assert.Equal(t, len(expected), eFeat.Len())
+	for _, f := range eFeat.Labels() {
+		vals, _ := eFeat.Get(f)
+		assert.Equal(t, expected[f.String()], vals, f.String())
+	}
+}
+
+func TestEventIntensity(t *testing.T) {
+	start := time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC)
+	tSeries := make([]time.Time, 12)
+	for i := range tSeries {
+		tSeries[i] = start.Add(time.Duration(i) * time.Hour)
+	}
+
+	opt := EventOptions{
+		Events: []Event{
+			{
+				Name:       "discount",
+				Start:      start.Add(time.Hour),
+				End:        start.Add(3 * time.Hour),
+				Recurrence: 6 * time.Hour,
+				Magnitude:  0.5,
+				RampDown:   2 * time.Hour,
+			},
+			{
+				Name:  "spend",
+				Start: start.Add(2 * time.Hour),
+				End:   start.Add(8 * time.Hour),
+				Lags:  []time.Duration{2 * time.Hour},
+				Intensity: []IntensityPoint{
+					{Time: start.Add(4 * time.Hour), Value: 3},
+					{Time: start.Add(6 * time.Hour), Value: 0},
+				},
+			},
+		},
+	}
+	eFeat := feature.NewSet()
+	opt.generateEventMask(tSeries, time.Hour, eFeat, WindowFunc(""))
+
+	expected := map[string][]float64{
+		"event_discount":     {0, 0.5, 0.5, 0.25, 0, 0, 0, 0.5, 0.5, 0.25, 0, 0},
+		"event_spend":        {0, 0, 1, 1, 3, 3, 0, 0, 0, 0, 0, 0},
+		"event_spend_lag_2h": {0, 0, 0, 0, 1, 1, 3, 3, 0, 0, 0, 0},
+	}
+	assert.Equal(t, len(expected), eFeat.Len())
+	for _, f := range eFeat.Labels() {
+		vals, _ := eFeat.Get(f)
+		assert.InDeltaSlice(t, expected[f.String()], vals, 1e-9, f.String())
+	}
+}
+
+func TestRecurringEvents(t *testing.T) {
+	start := time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC)
+	tSeries := make([]time.Time, 12)
+	for i := range tSeries {
+		tSeries[i] = start.Add(time.Duration(i) * time.Hour)
+	}
+
+	// two hour event every four hours starting at the first hour until the fifth hour
+	ev := NewRecurringEvent("maint", start.Add(time.Hour), start.Add(3*time.Hour), 4*time.Hour, start.Add(5*time.Hour))
+	ev.Lags = []time.Duration{time.Hour}
+
+	testData := map[string]struct {
+		autoExpand bool
+		t          []time.Time
+		expected   map[string][]float64
+		unexpanded []string
+	}{
+		"until": {
+			t: tSeries,
+			expected: map[string][]float64{
+				"event_maint":        {0, 1, 1, 0, 0, 1, 1, 0, 0, 0, 0, 0},
+				"event_maint_lag_1h": {0, 0, 1, 1, 0, 0, 1, 1, 0, 0, 0, 0},
+			},
+			unexpanded: []string{"maint"},
+		},
+		"until before window": {
+			t: tSeries[7:],
+			expected: map[string][]float64{
+				"event_maint":        {0, 0, 0, 0, 0},
+				"event_maint_lag_1h": {1, 0, 0, 0, 0},
+			},
+			unexpanded: []string{"maint"},
+		},
+		"auto expand": {
+			autoExpand: true,
+			t:          tSeries,
+			expected: map[string][]float64{
+				"event_maint":        {0, 1, 1, 0, 0, 1, 1, 0, 0, 1, 1, 0},
+				"event_maint_lag_1h": {0, 0, 1, 1, 0, 0, 1, 1, 0, 0, 1, 1},
+			},
+		},
+		"within until": {
+			t: tSeries[:8],
+			expected: map[string][]float64{
+				"event_maint":        {0, 1, 1, 0, 0, 1, 1, 0},
+				"event_maint_lag_1h": {0, 0, 1, 1, 0, 0, 1, 1},
+			},
+		},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			opt := EventOptions{
+				Events:     []Event{ev},
+				AutoExpand: td.autoExpand,
+			}
+			eFeat := feature.NewSet()
+			opt.generateEventMask(td.t, time.Hour, eFeat, WindowFunc(""))
+
+			assert.Equal(t, len(td.expected), eFeat.Len())
+			for _, f := range eFeat.Labels() {
+				vals, _ := eFeat.Get(f)
+				assert.Equal(t, td.expected[f.String()], vals, f.String())
+			}
+			assert.Equal(t, td.unexpanded, opt.UnexpandedEvents(td.t))
+		})
+	}
+}
+
+func TestEventTimezone(t *testing.T) {
+	// daily store hours defined in new york wall clock time across the spring dst transition
+	start := time.Date(2024, 3, 8, 9, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 3, 8, 17, 0, 0, 0, time.UTC)
+
+	testData := map[string]struct {
+		ev       Event
+		expected []Event
+		err      error
+	}{
+		"absolute": {
+			ev: NewRecurringEvent("store_hours", start, end, 24*time.Hour, time.Time{}),
+			expected: []Event{
+				{Name: "store_hours", Start: start, End: end},
+				{Name: "store_hours", Start: start.Add(24 * time.Hour), End: end.Add(24 * time.Hour)},
+				{Name: "store_hours", Start: start.Add(48 * time.Hour), End: end.Add(48 * time.Hour)},
+			},
+		},
+		"wall clock": {
+			ev: Event{
+				Name:       "store_hours",
+				Start:      start,
+				End:        end,
+				Recurrence: 24 * time.Hour,
+				Timezone:   "America/New_York",
+			},
+			expected: []Event{
+				{Name: "store_hours", Start: time.Date(2024, 3, 8, 14, 0, 0, 0, time.UTC), End: time.Date(2024, 3, 8, 22, 0, 0, 0, time.UTC)},
+				{Name: "store_hours", Start: time.Date(2024, 3, 9, 14, 0, 0, 0, time.UTC), End: time.Date(2024, 3, 9, 22, 0, 0, 0, time.UTC)},
+				{Name: "store_hours", Start: time.Date(2024, 3, 10, 13, 0, 0, 0, time.UTC), End: time.Date(2024, 3, 10, 21, 0, 0, 0, time.UTC)},
+			},
+		},
+		"wall clock single": {
+			ev: Event{
+				Name:     "black_friday",
+				Start:    time.Date(2024, 11, 29, 6, 0, 0, 0, time.UTC),
+				End:      time.Date(2024, 11, 29, 22, 0, 0, 0, time.UTC),
+				Timezone: "America/Los_Angeles",
+			},
+			expected: []Event{
+				{Name: "black_friday", Start: time.Date(2024, 11, 29, 14, 0, 0, 0, time.UTC), End: time.Date(2024, 11, 30, 6, 0, 0, 0, time.UTC)},
+			},
+		},
+		"unknown timezone": {
+			ev:  Event{Name: "store_hours", Start: start, End: end, Timezone: "Mars/Olympus"},
+			err: ErrUnknownTimezone,
+		},
+	}
+
+	rangeStart := time.Date(2024, 3, 8, 0, 0, 0, 0, time.UTC)
+	rangeEnd := time.Date(2024, 3, 10, 23, 0, 0, 0, time.UTC)
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			err := td.ev.Valid()
+			if td.err != nil {
+				assert.ErrorIs(t, err, td.err)
+				return
+			}
+			require.Nil(t, err)
+
+			occs := td.ev.occurrences(rangeStart, rangeEnd, false)
+			require.Equal(t, len(td.expected), len(occs))
+			for i, occ := range occs {
+				assert.True(t, td.expected[i].Start.Equal(occ.Start), "occurrence %d start %s", i, occ.Start)
+				assert.True(t, td.expected[i].End.Equal(occ.End), "occurrence %d end %s", i, occ.End)
+			}
+		})
+	}
+}
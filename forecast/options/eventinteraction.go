@@ -0,0 +1,92 @@
+package options
+
+import (
+	"time"
+
+	"github.com/aouyang1/go-forecaster/feature"
+	"gonum.org/v1/gonum/floats"
+)
+
+const (
+	LabelEventGrowth = "growth"
+	LabelEventChpt   = "chpt"
+)
+
+// EventInteractionOptions adds features of every event mask multiplied by a linear growth and by the
+// bias of every changepoint. Growth lets the trend change slope only during an event window, e.g. a
+// promotion whose lift builds over its duration, and the changepoint bias lets the effect of an event
+// differ before and after a changepoint. Growth is measured in days from the end of training. Smooth
+// trend changepoints are not interacted.
+type EventInteractionOptions struct {
+	Growth          bool `json:"growth"`
+	ChangepointBias bool `json:"changepoint_bias"`
+}
+
+// Enabled returns true if any interaction is generated
+func (e EventInteractionOptions) Enabled() bool {
+	return e.Growth || e.ChangepointBias
+}
+
+// Generate multiplies every event feature by the linear growth from the anchor time and by every
+// changepoint bias feature of the non smooth changepoints
+func (e EventInteractionOptions) Generate(t []time.Time, eFeat, chptFeat *feature.Set, chpts []Changepoint, anchor time.Time) *feature.Set {
+	x := feature.NewSet()
+	if !e.Enabled() || eFeat == nil {
+		return x
+	}
+
+	var growth []float64
+	if e.Growth {
+		growth = make([]float64, len(t))
+		for i, tPnt := range t {
+			growth[i] = tPnt.Sub(anchor).Hours() / 24
+		}
+	}
+
+	smooth := make(map[string]struct{})
+	for _, chpt := range chpts {
+		if chpt.Smooth {
+			smooth[chpt.Name] = struct{}{}
+		}
+	}
+	var biasLabels []feature.Feature
+	if e.ChangepointBias && chptFeat != nil {
+		for _, label := range chptFeat.Labels() {
+			comp, _ := label.Get("changepoint_component")
+			if feature.ChangepointComp(comp) != feature.ChangepointCompBias {
+				continue
+			}
+			if name, _ := label.Get("name"); name != "" {
+				if _, exists := smooth[name]; exists {
+					continue
+				}
+			}
+			biasLabels = append(biasLabels, label)
+		}
+	}
+
+	for _, label := range eFeat.Labels() {
+		mask, exists := eFeat.Get(label)
+		if !exists {
+			continue
+		}
+		eventName, _ := label.Get("name")
+
+		if growth != nil {
+			data := make([]float64, len(mask))
+			floats.MulTo(data, mask, growth)
+			x.Set(feature.NewEvent(EventGrowthFeatureName(eventName)), data)
+		}
+		for _, biasLabel := range biasLabels {
+			bias, exists := chptFeat.Get(biasLabel)
+			if !exists {
+				continue
+			}
+			chptName, _ := biasLabel.Get("name")
+			data := make([]float64, len(mask))
+			floats.MulTo(data, mask, bias)
+			x.Set(feature.NewEvent(EventChangepointFeatureName(eventName, chptName)), data)
+		}
+	}
+	return x
+}
@@ -0,0 +1,172 @@
+package options
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/errs"
+)
+
+var (
+	ErrEmptyEventSeriesType     = errs.NewConfigError(errs.CodeMissingOption, "event series type name cannot be empty", nil)
+	ErrNilEventSeriesCodec      = errs.NewConfigError(errs.CodeMissingOption, "event series codec must encode and decode", nil)
+	ErrEventSeriesRegistered    = errs.NewConfigError(errs.CodeDuplicateLabel, "event series type already registered", nil)
+	ErrEventSeriesNotRegistered = errs.NewConfigError(errs.CodeUnknownFeature, "event series type not registered", nil)
+	ErrNilEventSeries           = errs.NewConfigError(errs.CodeMissingOption, "custom event series has no implementation", nil)
+	ErrInvalidCustomEventSeries = errs.NewDataError(errs.CodeInvalidModel, "invalid custom event series", nil)
+)
+
+// EventSeries is a user defined source of event occurrences such as the dates of a product launch
+// calendar. Every occurrence shares a single event feature of the series name.
+type EventSeries interface {
+	// Name returns the name of the event feature
+	Name() string
+
+	// Occurrences returns every occurrence overlapping the input time range in order of start time
+	Occurrences(start, end time.Time) ([]Event, error)
+}
+
+// EventSeriesCodec serializes an event series implementation. Encode writes the configuration of the
+// series as json and Decode rebuilds the series from it.
+type EventSeriesCodec struct {
+	Encode func(EventSeries) ([]byte, error)
+	Decode func([]byte) (EventSeries, error)
+}
+
+// NewJSONEventSeriesCodec returns a codec serializing the series as json. The new function returns an
+// empty series, usually a pointer, which the json is decoded into.
+func NewJSONEventSeriesCodec(newSeries func() EventSeries) EventSeriesCodec {
+	return EventSeriesCodec{
+		Encode: func(s EventSeries) ([]byte, error) {
+			return json.Marshal(s)
+		},
+		Decode: func(data []byte) (EventSeries, error) {
+			s := newSeries()
+			if err := json.Unmarshal(data, s); err != nil {
+				return nil, err
+			}
+			return s, nil
+		},
+	}
+}
+
+var (
+	eventSeriesMu     sync.RWMutex
+	eventSeriesCodecs = make(map[string]EventSeriesCodec)
+)
+
+// RegisterEventSeries registers the codec of an event series implementation under a type name so that
+// custom event series of the type are serialized with the model. The same type must be registered in
+// any process that loads the model.
+func RegisterEventSeries(typeName string, codec EventSeriesCodec) error {
+	if typeName == "" {
+		return ErrEmptyEventSeriesType
+	}
+	if codec.Encode == nil || codec.Decode == nil {
+		return fmt.Errorf("%q, %w", typeName, ErrNilEventSeriesCodec)
+	}
+
+	eventSeriesMu.Lock()
+	defer eventSeriesMu.Unlock()
+	if _, exists := eventSeriesCodecs[typeName]; exists {
+		return fmt.Errorf("%q, %w", typeName, ErrEventSeriesRegistered)
+	}
+	eventSeriesCodecs[typeName] = codec
+	return nil
+}
+
+// UnregisterEventSeries removes an event series type from the registry
+func UnregisterEventSeries(typeName string) {
+	eventSeriesMu.Lock()
+	defer eventSeriesMu.Unlock()
+	delete(eventSeriesCodecs, typeName)
+}
+
+func eventSeriesCodec(typeName string) (EventSeriesCodec, error) {
+	eventSeriesMu.RLock()
+	defer eventSeriesMu.RUnlock()
+	codec, exists := eventSeriesCodecs[typeName]
+	if !exists {
+		return EventSeriesCodec{}, fmt.Errorf("%q, %w", typeName, ErrEventSeriesNotRegistered)
+	}
+	return codec, nil
+}
+
+// CustomEventSeries is an event series implementation along with the registered type name used to
+// serialize it
+type CustomEventSeries struct {
+	Type   string
+	Series EventSeries
+}
+
+// NewCustomEventSeries wraps an event series of a registered type
+func NewCustomEventSeries(typeName string, series EventSeries) CustomEventSeries {
+	return CustomEventSeries{
+		Type:   typeName,
+		Series: series,
+	}
+}
+
+type customEventSeriesJSON struct {
+	Type   string          `json:"type"`
+	Config json.RawMessage `json:"config"`
+}
+
+// MarshalJSON writes the type name and the configuration encoded by the registered codec
+func (c CustomEventSeries) MarshalJSON() ([]byte, error) {
+	if c.Series == nil {
+		return nil, fmt.Errorf("%q, %w", c.Type, ErrNilEventSeries)
+	}
+	codec, err := eventSeriesCodec(c.Type)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := codec.Encode(c.Series)
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode event series of type %q, %w", c.Type, err)
+	}
+	if !json.Valid(cfg) {
+		return nil, fmt.Errorf("event series of type %q encoded as invalid json, %w", c.Type, ErrInvalidCustomEventSeries)
+	}
+	return json.Marshal(customEventSeriesJSON{Type: c.Type, Config: cfg})
+}
+
+// UnmarshalJSON rebuilds the event series with the codec registered for its type failing if the type
+// is not registered so that models do not silently lose events
+func (c *CustomEventSeries) UnmarshalJSON(data []byte) error {
+	var in customEventSeriesJSON
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&in); err != nil {
+		return fmt.Errorf("%s, %w", err.Error(), ErrInvalidCustomEventSeries)
+	}
+	codec, err := eventSeriesCodec(in.Type)
+	if err != nil {
+		return err
+	}
+
+	series, err := codec.Decode(in.Config)
+	if err != nil {
+		return fmt.Errorf("unable to decode event series of type %q, %w", in.Type, err)
+	}
+	if series == nil {
+		return fmt.Errorf("%q, %w", in.Type, ErrNilEventSeries)
+	}
+	*c = CustomEventSeries{Type: in.Type, Series: series}
+	return nil
+}
+
+// Valid returns an error if the custom event series has no implementation or an unregistered type
+func (c CustomEventSeries) Valid() error {
+	if c.Series == nil {
+		return fmt.Errorf("%q, %w", c.Type, ErrNilEventSeries)
+	}
+	if c.Series.Name() == "" {
+		return ErrNoEventName
+	}
+	_, err := eventSeriesCodec(c.Type)
+	return err
+}
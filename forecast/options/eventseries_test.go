@@ -0,0 +1,99 @@
+package options
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/feature"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// launchSeries is an event series of launch dates each lasting the duration
+type launchSeries struct {
+	Label    string        `json:"label"`
+	Launches []time.Time   `json:"launches"`
+	Duration time.Duration `json:"duration"`
+}
+
+func (l *launchSeries) Name() string {
+	return l.Label
+}
+
+func (l *launchSeries) Occurrences(start, end time.Time) ([]Event, error) {
+	var occs []Event
+	for _, launch := range l.Launches {
+		occ := NewEvent(l.Label, launch, launch.Add(l.Duration))
+		if occ.End.Before(start) || occ.Start.After(end) {
+			continue
+		}
+		occs = append(occs, occ)
+	}
+	return occs, nil
+}
+
+func TestCustomEventSeries(t *testing.T) {
+	codec := NewJSONEventSeriesCodec(func() EventSeries { return &launchSeries{} })
+	require.Nil(t, RegisterEventSeries("test_launch", codec))
+	defer UnregisterEventSeries("test_launch")
+
+	assert.ErrorIs(t, RegisterEventSeries("test_launch", codec), ErrEventSeriesRegistered)
+	assert.ErrorIs(t, RegisterEventSeries("", codec), ErrEmptyEventSeriesType)
+	assert.ErrorIs(t, RegisterEventSeries("test_nil", EventSeriesCodec{}), ErrNilEventSeriesCodec)
+
+	t0 := time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC)
+	series := &launchSeries{
+		Label:    "launch",
+		Launches: []time.Time{t0.Add(time.Hour), t0.Add(4 * time.Hour)},
+		Duration: time.Hour,
+	}
+	opt := &EventOptions{
+		Custom: []CustomEventSeries{NewCustomEventSeries("test_launch", series)},
+	}
+
+	// the series round trips through json with its registered type
+	out, err := json.Marshal(opt)
+	require.Nil(t, err)
+	var decoded EventOptions
+	require.Nil(t, json.Unmarshal(out, &decoded))
+	require.Len(t, decoded.Custom, 1)
+	assert.Equal(t, "test_launch", decoded.Custom[0].Type)
+	assert.Equal(t, series.Label, decoded.Custom[0].Series.Name())
+	assert.Equal(t, series.Duration, decoded.Custom[0].Series.(*launchSeries).Duration)
+
+	tWin := make([]time.Time, 6)
+	for i := range tWin {
+		tWin[i] = t0.Add(time.Duration(i) * time.Hour)
+	}
+	o := &Options{EventOptions: decoded}
+	feat := o.GenerateEventFeatures(tWin)
+	mask, exists := feat.Get(feature.NewEvent("launch"))
+	require.True(t, exists)
+	assert.Equal(t, []float64{0, 1, 0, 0, 1, 0}, mask)
+
+	testData := map[string]struct {
+		data string
+		err  error
+	}{
+		"unregistered": {
+			data: `{"type": "test_unknown", "config": {}}`,
+			err:  ErrEventSeriesNotRegistered,
+		},
+		"unknown field": {
+			data: `{"type": "test_launch", "config": {}, "name": "launch"}`,
+			err:  ErrInvalidCustomEventSeries,
+		},
+	}
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			var c CustomEventSeries
+			assert.ErrorIs(t, json.Unmarshal([]byte(td.data), &c), td.err)
+		})
+	}
+
+	_, err = json.Marshal(NewCustomEventSeries("test_unknown", series))
+	assert.ErrorIs(t, err, ErrEventSeriesNotRegistered)
+	_, err = json.Marshal(NewCustomEventSeries("test_launch", nil))
+	assert.ErrorIs(t, err, ErrNilEventSeries)
+}
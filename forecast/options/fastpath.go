@@ -0,0 +1,62 @@
+package options
+
+import (
+	"math"
+	"time"
+
+	"gonum.org/v1/gonum/stat"
+)
+
+// LabelChptTrend is the changepoint name used to represent the trend of a trend only fast path fit
+const LabelChptTrend = "trend"
+
+// FastPath identifies a shortcut taken when fitting a degenerate series
+type FastPath string
+
+const (
+	FastPathNone      FastPath = ""
+	FastPathIntercept FastPath = "intercept"
+	FastPathTrend     FastPath = "trend"
+)
+
+// FastPathOptions lets nearly constant or nearly linear series skip feature generation and the Lasso
+// fit entirely. A series with a variance at most InterceptVariance is fit with only an intercept and a
+// series with a residual variance at most TrendVariance after removing a linear trend is fit with an
+// intercept and a single trend.
+type FastPathOptions struct {
+	Enabled           bool    `json:"enabled"`
+	InterceptVariance float64 `json:"intercept_variance"`
+	TrendVariance     float64 `json:"trend_variance"`
+}
+
+// Detect determines which fast path applies to the training data if any. The returned intercept is the
+// value at the first time point and the slope is the change per second.
+func (f FastPathOptions) Detect(t []time.Time, y []float64) (FastPath, float64, float64) {
+	if !f.Enabled || len(t) < 2 || len(t) != len(y) {
+		return FastPathNone, 0, 0
+	}
+
+	mean, variance := stat.PopMeanVariance(y, nil)
+	if variance <= f.InterceptVariance {
+		return FastPathIntercept, mean, 0
+	}
+
+	x := make([]float64, len(t))
+	for i, tPnt := range t {
+		x[i] = tPnt.Sub(t[0]).Seconds()
+	}
+	alpha, beta := stat.LinearRegression(x, y, nil, false)
+	if math.IsNaN(alpha) || math.IsNaN(beta) {
+		return FastPathNone, 0, 0
+	}
+
+	var sse float64
+	for i := range x {
+		r := y[i] - alpha - beta*x[i]
+		sse += r * r
+	}
+	if sse/float64(len(y)) <= f.TrendVariance {
+		return FastPathTrend, alpha, beta
+	}
+	return FastPathNone, 0, 0
+}
@@ -0,0 +1,152 @@
+package options
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/errs"
+	"github.com/aouyang1/go-forecaster/feature"
+	"github.com/aouyang1/go-forecaster/timedataset"
+	"github.com/rickar/cal/v2"
+	"github.com/rickar/cal/v2/de"
+	"github.com/rickar/cal/v2/gb"
+	"github.com/rickar/cal/v2/us"
+)
+
+// LabelHoliday prefixes the event names of holidays
+const LabelHoliday = "holiday"
+
+var ErrUnknownHolidayCountry = errs.NewConfigError(errs.CodeInvalidOption, "no built in holiday calendar for country", nil)
+
+// holidayCalendars are the built in national holiday calendars keyed by ISO 3166 country code with UK
+// accepted as an alias of GB
+var holidayCalendars = map[string][]*cal.Holiday{
+	"US": us.Holidays,
+	"GB": gb.Holidays,
+	"UK": gb.Holidays,
+	"DE": de.Holidays,
+}
+
+// HolidayCountries returns the country codes of the built in holiday calendars in sorted order
+func HolidayCountries() []string {
+	countries := make([]string, 0, len(holidayCalendars))
+	for country := range holidayCalendars {
+		countries = append(countries, country)
+	}
+	sort.Strings(countries)
+	return countries
+}
+
+// HolidayOptions models each holiday of the national calendars of the country codes as a recurring
+// event feature shared across years, e.g. holiday_us_christmas_day. Holidays span the local day they
+// fall on in the timezone override or dataset timezone, shifted to the day they are observed if
+// Observed is set e.g. a Saturday holiday observed on the Friday before. DurBefore and DurAfter extend
+// each holiday like the weekend buffers. Only the options are stored with a model so predictions
+// regenerate the same holidays from the calendars.
+type HolidayOptions struct {
+	Countries        []string      `json:"countries"`
+	Observed         bool          `json:"observed"`
+	TimezoneOverride string        `json:"timezone_override"`
+	DurBefore        time.Duration `json:"duration_before"`
+	DurAfter         time.Duration `json:"duration_after"`
+}
+
+// Validate returns an error if any country has no built in holiday calendar
+func (h HolidayOptions) Validate() error {
+	for _, country := range h.Countries {
+		if _, exists := holidayCalendars[strings.ToUpper(country)]; !exists {
+			return fmt.Errorf("%q, %w", country, ErrUnknownHolidayCountry)
+		}
+	}
+	return nil
+}
+
+// eventNames returns the event names of every holiday of the configured countries
+func (h HolidayOptions) eventNames() map[string]bool {
+	names := make(map[string]bool)
+	for _, country := range h.Countries {
+		country = strings.ToUpper(country)
+		for _, hol := range holidayCalendars[country] {
+			names[HolidayEventName(country, hol.Name)] = true
+		}
+	}
+	return names
+}
+
+// Events returns the occurrences of every holiday overlapping the input time range grouped by event
+// name in the location of the holiday options. Holidays sharing a name in a calendar share an event.
+func (h HolidayOptions) Events(start, end time.Time, loc *time.Location) map[string][]Event {
+	if loc == nil {
+		loc = start.Location()
+	}
+	res := make(map[string][]Event)
+	for _, country := range h.Countries {
+		country = strings.ToUpper(country)
+		for _, hol := range holidayCalendars[country] {
+			name := HolidayEventName(country, hol.Name)
+			for year := start.Year() - 1; year <= end.Year()+1; year++ {
+				actual, observed := hol.Calc(year)
+				day := actual
+				if h.Observed {
+					day = observed
+				}
+				if day.IsZero() {
+					continue
+				}
+				occStart := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, loc)
+				ev := Event{
+					Name:  name,
+					Start: occStart.Add(-h.DurBefore),
+					End:   occStart.AddDate(0, 0, 1).Add(h.DurAfter),
+				}
+				if ev.End.Before(start) || ev.Start.After(end) {
+					continue
+				}
+				res[name] = append(res[name], ev)
+			}
+		}
+	}
+	for _, occs := range res {
+		sort.Slice(occs, func(i, j int) bool {
+			return occs[i].Start.Before(occs[j].Start)
+		})
+	}
+	return res
+}
+
+// location returns the timezone override or nil to use the dataset timezone
+func (h HolidayOptions) location() *time.Location {
+	if h.TimezoneOverride == "" {
+		return nil
+	}
+	loc, err := time.LoadLocation(h.TimezoneOverride)
+	if err != nil {
+		slog.Warn("invalid timezone location override for holiday options, using dataset timezone", "timezone_override", h.TimezoneOverride)
+		return nil
+	}
+	return loc
+}
+
+func (h HolidayOptions) generateEventMask(t []time.Time, freq time.Duration, eFeat *feature.Set, winFunc func([]float64) []float64) {
+	if len(h.Countries) == 0 {
+		return
+	}
+	if err := h.Validate(); err != nil {
+		slog.Warn("not modelling holidays of unknown country", "error", err.Error())
+		return
+	}
+	ts := timedataset.TimeSlice(t)
+	events := h.Events(ts.StartTime(), ts.EndTime(), h.location())
+
+	names := make([]string, 0, len(events))
+	for name := range events {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		generateOccurrencesMask(t, freq, name, events[name], eFeat, maskShape{window: winFunc})
+	}
+}
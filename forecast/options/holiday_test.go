@@ -0,0 +1,93 @@
+package options
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/feature"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHolidayOptionsValidate(t *testing.T) {
+	assert.Nil(t, HolidayOptions{Countries: []string{"US", "uk", "De"}}.Validate())
+	assert.ErrorIs(t, HolidayOptions{Countries: []string{"US", "XX"}}.Validate(), ErrUnknownHolidayCountry)
+}
+
+func TestHolidayOptionsEvents(t *testing.T) {
+	// christmas 2021 fell on a saturday and was observed on friday the 24th
+	start := time.Date(2021, 12, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2022, 1, 31, 0, 0, 0, 0, time.UTC)
+
+	testData := map[string]struct {
+		opt      HolidayOptions
+		expected []Event
+	}{
+		"actual": {
+			opt: HolidayOptions{Countries: []string{"US"}},
+			expected: []Event{
+				{
+					Name:  "holiday_us_christmas_day",
+					Start: time.Date(2021, 12, 25, 0, 0, 0, 0, time.UTC),
+					End:   time.Date(2021, 12, 26, 0, 0, 0, 0, time.UTC),
+				},
+			},
+		},
+		"observed with buffer": {
+			opt: HolidayOptions{Countries: []string{"US"}, Observed: true, DurBefore: time.Hour, DurAfter: 2 * time.Hour},
+			expected: []Event{
+				{
+					Name:  "holiday_us_christmas_day",
+					Start: time.Date(2021, 12, 23, 23, 0, 0, 0, time.UTC),
+					End:   time.Date(2021, 12, 25, 2, 0, 0, 0, time.UTC),
+				},
+			},
+		},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			events := td.opt.Events(start, end, time.UTC)
+			assert.Equal(t, td.expected, events["holiday_us_christmas_day"])
+			assert.Contains(t, events, "holiday_us_new_year_s_day")
+		})
+	}
+}
+
+func TestHolidayOptionsEventMask(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	require.Nil(t, err)
+
+	tStart := time.Date(2021, 12, 20, 0, 0, 0, 0, time.UTC)
+	tWin := make([]time.Time, 10*24)
+	for i := range tWin {
+		tWin[i] = tStart.Add(time.Duration(i) * time.Hour)
+	}
+
+	opt := EventOptions{
+		Holidays: HolidayOptions{Countries: []string{"US"}, Observed: true, TimezoneOverride: "America/New_York"},
+	}
+
+	// the holidays reproduce from the serialized options
+	out, err := json.Marshal(opt)
+	require.Nil(t, err)
+	var loaded EventOptions
+	require.Nil(t, json.Unmarshal(out, &loaded))
+	require.Equal(t, opt, loaded)
+
+	eFeat := feature.NewSet()
+	loaded.generateEventMask(tWin, time.Hour, eFeat, WindowFunc(""))
+
+	mask, exists := eFeat.Get(feature.NewEvent("holiday_us_christmas_day"))
+	require.True(t, exists)
+	holStart := time.Date(2021, 12, 24, 0, 0, 0, 0, loc)
+	holEnd := time.Date(2021, 12, 25, 0, 0, 0, 0, loc)
+	for i, tPnt := range tWin {
+		var expected float64
+		if !tPnt.Before(holStart) && tPnt.Before(holEnd) {
+			expected = 1.0
+		}
+		assert.Equal(t, expected, mask[i], "time %s", tPnt)
+	}
+}
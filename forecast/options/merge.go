@@ -0,0 +1,123 @@
+package options
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/aouyang1/go-forecaster/errs"
+	"github.com/aouyang1/go-forecaster/forecast/util"
+)
+
+var ErrUnknownOptionsField = errs.NewConfigError(errs.CodeInvalidOption, "unknown options field", nil)
+
+// Merge returns a new set of options layering the override on top of the base so that configuration
+// can be built from global defaults, team defaults, and per series tweaks. Nested option structs are
+// merged field by field while any other field set in the override replaces the base value. A field is
+// set if it is not its zero value, so a nil slice keeps the base value while an empty non-nil slice
+// explicitly clears it. Use MergeFields to override booleans and numbers with zero values. A nil base
+// or override returns a copy of the other and both nil returns nil. Neither input is modified.
+func Merge(base, override *Options) *Options {
+	res, _ := MergeFields(base, override)
+	return res
+}
+
+// MergeFields merges like Merge and also replaces the base value of every listed field with the value
+// of the override even if it is the zero value, so a layer can disable a feature or set a number to 0.
+// Fields are paths of Go field names separated by dots e.g. "DSTOptions.Enabled" and listing a nested
+// options struct replaces it as a whole. Returns ErrUnknownOptionsField if a field does not exist.
+func MergeFields(base, override *Options, fields ...string) (*Options, error) {
+	explicit := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		if err := markExplicit(reflect.TypeOf(Options{}), field, explicit); err != nil {
+			return nil, err
+		}
+	}
+	if base == nil && override == nil {
+		return nil, nil
+	}
+	res := &Options{}
+	if base != nil {
+		res = base.Copy()
+	}
+	if override != nil {
+		mergeValue(reflect.ValueOf(res).Elem(), reflect.ValueOf(override).Elem(), "", explicit)
+	}
+	return res, nil
+}
+
+// markExplicit sets the field path as explicit and records each of its parent paths as false so the
+// merge descends into them even if they are zero in the override
+func markExplicit(t reflect.Type, field string, explicit map[string]bool) error {
+	var path string
+	for _, name := range strings.Split(field, ".") {
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			return fmt.Errorf("%q, %w", field, ErrUnknownOptionsField)
+		}
+		sf, exists := t.FieldByName(name)
+		if !exists || !sf.IsExported() || len(sf.Index) != 1 {
+			return fmt.Errorf("%q, %w", field, ErrUnknownOptionsField)
+		}
+		if path != "" {
+			path += "."
+		}
+		path += name
+		if _, exists := explicit[path]; !exists {
+			explicit[path] = false
+		}
+		t = sf.Type
+	}
+	explicit[path] = true
+	return nil
+}
+
+// Copy returns a deep copy of the options including the training hooks which are not serialized. Nil
+// options return nil.
+func (o *Options) Copy() *Options {
+	return util.DeepCopy(o)
+}
+
+// mergeValue merges the set and explicit fields of the source struct into the addressable destination
+// struct where prefix is the path of the struct
+func mergeValue(dst, src reflect.Value, prefix string, explicit map[string]bool) {
+	for i := 0; i < src.NumField(); i++ {
+		srcField := src.Field(i)
+		dstField := dst.Field(i)
+		path := src.Type().Field(i).Name
+		if prefix != "" {
+			path = prefix + "." + path
+		}
+		listed, nested := explicit[path]
+		if listed {
+			dstField.Set(util.DeepCopyValue(srcField))
+			continue
+		}
+		if srcField.IsZero() && !nested {
+			continue
+		}
+		switch {
+		case srcField.Kind() == reflect.Struct && !util.OpaqueStruct(srcField.Type()):
+			mergeValue(dstField, srcField, path, explicit)
+		case srcField.Kind() == reflect.Pointer && srcField.Type().Elem().Kind() == reflect.Struct &&
+			!util.OpaqueStruct(srcField.Type().Elem()):
+			if srcField.IsNil() && dstField.IsNil() {
+				continue
+			}
+			merged := reflect.New(srcField.Type().Elem())
+			if !dstField.IsNil() {
+				merged = util.DeepCopyValue(dstField)
+			}
+			srcElem := reflect.New(srcField.Type().Elem()).Elem()
+			if !srcField.IsNil() {
+				srcElem = srcField.Elem()
+			}
+			mergeValue(merged.Elem(), srcElem, path, explicit)
+			dstField.Set(merged)
+		default:
+			dstField.Set(util.DeepCopyValue(srcField))
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package options
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMerge(t *testing.T) {
+	ct := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	newBase := func() *Options {
+		opt := NewDefaultOptions()
+		opt.Iterations = 500
+		opt.WeekendOptions.Enabled = true
+		opt.EventOptions.Events = []Event{NewEvent("launch", ct, ct.Add(time.Hour))}
+		return opt
+	}
+
+	testData := map[string]struct {
+		base     *Options
+		override *Options
+		expected func() *Options
+	}{
+		"both nil": {
+			expected: func() *Options { return nil },
+		},
+		"nil override": {
+			base:     newBase(),
+			expected: newBase,
+		},
+		"nil base": {
+			override: newBase(),
+			expected: newBase,
+		},
+		"nested fields": {
+			base: newBase(),
+			override: &Options{
+				Tolerance: 1e-6,
+				WeekendOptions: WeekendOptions{
+					TimezoneOverride: "America/Los_Angeles",
+				},
+				SeasonalityOptions: SeasonalityOptions{
+					Detect: SeasonalityDetectOptions{Enabled: true},
+				},
+			},
+			expected: func() *Options {
+				opt := newBase()
+				opt.Tolerance = 1e-6
+				opt.WeekendOptions.TimezoneOverride = "America/Los_Angeles"
+				opt.SeasonalityOptions.Detect.Enabled = true
+				return opt
+			},
+		},
+		"slice replaced": {
+			base: newBase(),
+			override: &Options{
+				EventOptions: EventOptions{
+					Events: []Event{NewEvent("migration", ct.Add(time.Hour), ct.Add(2*time.Hour))},
+				},
+			},
+			expected: func() *Options {
+				opt := newBase()
+				opt.EventOptions.Events = []Event{NewEvent("migration", ct.Add(time.Hour), ct.Add(2*time.Hour))}
+				return opt
+			},
+		},
+		"empty slice clears": {
+			base: newBase(),
+			override: &Options{
+				SeasonalityOptions: SeasonalityOptions{
+					SeasonalityConfigs: []SeasonalityConfig{},
+				},
+			},
+			expected: func() *Options {
+				opt := newBase()
+				opt.SeasonalityOptions.SeasonalityConfigs = []SeasonalityConfig{}
+				return opt
+			},
+		},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			res := Merge(td.base, td.override)
+			assert.Equal(t, td.expected(), res)
+		})
+	}
+}
+
+func TestMergeDoesNotShare(t *testing.T) {
+	ct := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	base := NewDefaultOptions()
+	override := &Options{
+		EventOptions: EventOptions{
+			Events: []Event{NewEvent("launch", ct, ct.Add(time.Hour))},
+		},
+	}
+
+	res := Merge(base, override)
+	require.NotNil(t, res)
+	res.SeasonalityOptions.SeasonalityConfigs[0].Orders = 99
+	res.EventOptions.Events[0].Name = "renamed"
+	res.Regularization[0] = 10.0
+
+	assert.Equal(t, NewDefaultOptions(), base)
+	assert.Equal(t, "launch", override.EventOptions.Events[0].Name)
+}
+
+func TestMergeFields(t *testing.T) {
+	newBase := func() *Options {
+		opt := NewDefaultOptions()
+		opt.Iterations = 500
+		opt.DSTOptions.Enabled = true
+		opt.WeekendOptions.Enabled = true
+		opt.WeekendOptions.TimezoneOverride = "America/New_York"
+		return opt
+	}
+
+	testData := map[string]struct {
+		override *Options
+		fields   []string
+		expected func() *Options
+		err      error
+	}{
+		"zero values ignored": {
+			override: &Options{},
+			expected: newBase,
+		},
+		"disable features": {
+			override: &Options{},
+			fields:   []string{"DSTOptions.Enabled", "WeekendOptions.Enabled", "Iterations"},
+			expected: func() *Options {
+				opt := newBase()
+				opt.Iterations = 0
+				opt.DSTOptions.Enabled = false
+				opt.WeekendOptions.Enabled = false
+				return opt
+			},
+		},
+		"explicit and set fields": {
+			override: &Options{Tolerance: 1e-6, WeekendOptions: WeekendOptions{TimezoneOverride: "UTC"}},
+			fields:   []string{"WeekendOptions.Enabled"},
+			expected: func() *Options {
+				opt := newBase()
+				opt.Tolerance = 1e-6
+				opt.WeekendOptions.Enabled = false
+				opt.WeekendOptions.TimezoneOverride = "UTC"
+				return opt
+			},
+		},
+		"replace nested struct": {
+			override: &Options{WeekendOptions: WeekendOptions{DurBefore: time.Hour}},
+			fields:   []string{"WeekendOptions"},
+			expected: func() *Options {
+				opt := newBase()
+				opt.WeekendOptions = WeekendOptions{DurBefore: time.Hour}
+				return opt
+			},
+		},
+		"unknown field": {
+			override: &Options{},
+			fields:   []string{"WeekendOptions.Disabled"},
+			err:      ErrUnknownOptionsField,
+		},
+		"field of non struct": {
+			override: &Options{},
+			fields:   []string{"Iterations.Value"},
+			err:      ErrUnknownOptionsField,
+		},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			res, err := MergeFields(newBase(), td.override, td.fields...)
+			if td.err != nil {
+				assert.ErrorIs(t, err, td.err)
+				return
+			}
+			require.Nil(t, err)
+			assert.Equal(t, td.expected(), res)
+		})
+	}
+}
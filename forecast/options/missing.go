@@ -0,0 +1,37 @@
+package options
+
+// MissingPolicy determines how predictions handle a custom time feature that is not registered or a
+// regressor that is not provided although the model was fit with it
+type MissingPolicy string
+
+const (
+	// MissingPolicyError fails the prediction. This is the default if unset.
+	MissingPolicyError MissingPolicy = "error"
+
+	// MissingPolicyZero fills the missing feature with zero removing its contribution to the
+	// prediction and flags it in the prediction components
+	MissingPolicyZero MissingPolicy = "zero"
+
+	// MissingPolicyDefault fills the missing feature with the configured default value and flags it in
+	// the prediction components
+	MissingPolicyDefault MissingPolicy = "default"
+)
+
+// MissingFeatureOptions configures the policy for a single missing custom time feature or regressor
+type MissingFeatureOptions struct {
+	Policy  MissingPolicy `json:"policy"`
+	Default float64       `json:"default,omitempty"`
+}
+
+// MissingFeature returns the missing policy of the custom time feature or regressor by name which
+// defaults to erroring
+func (o *Options) MissingFeature(name string) MissingFeatureOptions {
+	if o == nil {
+		return MissingFeatureOptions{Policy: MissingPolicyError}
+	}
+	m, exists := o.MissingFeatures[name]
+	if !exists || m.Policy == "" {
+		m.Policy = MissingPolicyError
+	}
+	return m
+}
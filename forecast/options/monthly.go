@@ -0,0 +1,74 @@
+package options
+
+import (
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/feature"
+)
+
+// MonthlySeasonalityOptions configures a calendar aware monthly seasonality. Rather than a fixed
+// period, the phase is the fraction of the calendar month elapsed so that day of month effects such as
+// billing cycles and month ends align across months of 28 to 31 days. The month boundaries are taken in
+// the Timezone location which defaults to UTC.
+type MonthlySeasonalityOptions struct {
+	Orders   int    `json:"orders"`
+	Timezone string `json:"timezone"`
+}
+
+// Enabled returns true if any monthly fourier orders are configured
+func (m MonthlySeasonalityOptions) Enabled() bool {
+	return m.Orders > 0
+}
+
+func (m MonthlySeasonalityOptions) location() *time.Location {
+	if m.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(m.Timezone)
+	if err != nil {
+		slog.Warn("unable to load monthly seasonality timezone, using UTC", "timezone", m.Timezone)
+		return time.UTC
+	}
+	return loc
+}
+
+// monthFraction returns the fraction of the calendar month elapsed at the input time
+func monthFraction(t time.Time) float64 {
+	year, month, _ := t.Date()
+	monthStart := time.Date(year, month, 1, 0, 0, 0, 0, t.Location())
+	monthEnd := monthStart.AddDate(0, 1, 0)
+	return t.Sub(monthStart).Seconds() / monthEnd.Sub(monthStart).Seconds()
+}
+
+// generateFourierFeatures generates the fourier features of the fraction of the calendar month
+// elapsed for each order
+func (m MonthlySeasonalityOptions) generateFourierFeatures(tFeatures *feature.Set) (*feature.Set, error) {
+	x := feature.NewSet()
+	if !m.Enabled() {
+		return x, nil
+	}
+	if tFeatures == nil {
+		return nil, ErrUnknownTimeFeature
+	}
+	tFeat, exists := tFeatures.Get(feature.NewTime(LabelTimeEpoch))
+	if !exists {
+		return nil, ErrUnknownTimeFeature
+	}
+
+	loc := m.location()
+	frac := make([]float64, len(tFeat))
+	for i, epoch := range tFeat {
+		sec := math.Floor(epoch)
+		frac[i] = monthFraction(time.Unix(int64(sec), int64((epoch-sec)*1e9)).In(loc))
+	}
+
+	name := SeasonalityFeatureName(LabelSeasMonthly)
+	for i := 1; i <= m.Orders; i++ {
+		sinFeat, cosFeat := generateFourierComponent(frac, i, 1.0)
+		x.Set(feature.NewSeasonality(name, feature.FourierCompSin, i), sinFeat)
+		x.Set(feature.NewSeasonality(name, feature.FourierCompCos, i), cosFeat)
+	}
+	return x, nil
+}
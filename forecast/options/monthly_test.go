@@ -0,0 +1,85 @@
+package options
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/feature"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMonthFraction(t *testing.T) {
+	testData := map[string]struct {
+		t        time.Time
+		expected float64
+	}{
+		"month start":         {time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), 0.0},
+		"mid leap february":   {time.Date(2024, 2, 15, 12, 0, 0, 0, time.UTC), 0.5},
+		"mid february":        {time.Date(2023, 2, 15, 0, 0, 0, 0, time.UTC), 0.5},
+		"last day of january": {time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC), 30.0 / 31.0},
+		"last day of april":   {time.Date(2024, 4, 30, 0, 0, 0, 0, time.UTC), 29.0 / 30.0},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			assert.InDelta(t, td.expected, monthFraction(td.t), 1e-9)
+		})
+	}
+}
+
+func TestMonthlyGenerateFourierFeatures(t *testing.T) {
+	// the first of every month has the same phase regardless of the month length
+	ts := []time.Time{
+		time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 3, 16, 12, 0, 0, 0, time.UTC),
+	}
+	opt := &Options{}
+	tFeat, _ := opt.GenerateTimeFeatures(ts)
+
+	testData := map[string]struct {
+		opt      MonthlySeasonalityOptions
+		expected int
+		err      error
+	}{
+		"disabled":           {opt: MonthlySeasonalityOptions{}, expected: 0},
+		"two orders":         {opt: MonthlySeasonalityOptions{Orders: 2}, expected: 4},
+		"unknown timezone":   {opt: MonthlySeasonalityOptions{Orders: 1, Timezone: "Mars/Olympus"}, expected: 2},
+		"shifted timezone":   {opt: MonthlySeasonalityOptions{Orders: 1, Timezone: "America/New_York"}, expected: 2},
+		"missing time epoch": {opt: MonthlySeasonalityOptions{Orders: 1}, err: ErrUnknownTimeFeature},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			input := tFeat
+			if td.err != nil {
+				input = feature.NewSet()
+			}
+			x, err := td.opt.generateFourierFeatures(input)
+			if td.err != nil {
+				assert.ErrorIs(t, err, td.err)
+				return
+			}
+			require.Nil(t, err)
+			require.Equal(t, td.expected, x.Len())
+			if td.expected == 0 {
+				return
+			}
+
+			cos, exists := x.Get(feature.NewSeasonality(LabelTimeEpoch+"_"+LabelSeasMonthly, feature.FourierCompCos, 1))
+			require.True(t, exists)
+			if td.opt.Timezone == "America/New_York" {
+				// midnight UTC is the evening of the last day of the previous month in New York
+				assert.Less(t, cos[0], 1.0)
+				return
+			}
+			assert.InDelta(t, 1.0, cos[0], 1e-9)
+			assert.InDelta(t, 1.0, cos[1], 1e-9)
+			assert.InDelta(t, 1.0, cos[2], 1e-9)
+			assert.InDelta(t, math.Cos(2.0*math.Pi*(15.5/31.0)), cos[3], 1e-9)
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package options
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/aouyang1/go-forecaster/errs"
+	"github.com/aouyang1/go-forecaster/feature"
+)
+
+var ErrFeatureNameCollision = errs.NewConfigError(errs.CodeDuplicateLabel, "feature name collides with another feature", nil)
+
+const LabelLag = "lag"
+
+// Built in feature names are formed by joining name parts with an underscore:
+//
+//   - seasonality: epoch_<seasonality name> e.g. epoch_daily
+//   - trend interaction: epoch_<seasonality name>_trend e.g. epoch_daily_trend
+//   - event seasonality: <event name>_<seasonality name> e.g. weekend_daily
+//   - timezone mixture: epoch_daily_<location> e.g. epoch_daily_America/New_York
+//   - lagged event: <event name>_lag_<lag> e.g. launch_lag_1h
+//   - lagged regressor: <regressor name>_lag_<lag> e.g. spend_lag_90m
+//   - event growth interaction: <event name>_growth e.g. promo_growth
+//   - event changepoint interaction: <event name>_chpt_<changepoint name> e.g. promo_chpt_launch
+//   - holiday event: holiday_<country>_<holiday name> e.g. holiday_us_christmas_day
+//   - detected seasonality: detected_<period> e.g. detected_12h0m0s
+//
+// Lags are written as a whole number of their largest exact unit of d, h, m, s, ms, us, or ns.
+// Seasonality names with the detected prefix are reserved and replaced on every detection. These
+// names are persisted in models so the scheme must stay stable. Custom features are prefixed by
+// the CustomFeatureNamespace of the options if set so they cannot collide with built in features.
+
+// SeasonalityFeatureName returns the name of the fourier features of a seasonality
+func SeasonalityFeatureName(seasName string) string {
+	return LabelTimeEpoch + "_" + seasName
+}
+
+// TrendInteractionFeatureName returns the name of the fourier features of a seasonality modulated by
+// the trend
+func TrendInteractionFeatureName(seasName string) string {
+	return SeasonalityFeatureName(seasName) + "_" + LabelSeasTrend
+}
+
+// EventSeasonalityFeatureName returns the name of the fourier features of a seasonality masked by an
+// event
+func EventSeasonalityFeatureName(eventName, seasName string) string {
+	return eventName + "_" + seasName
+}
+
+// EventGrowthFeatureName returns the name of an event mask multiplied by the linear growth
+func EventGrowthFeatureName(eventName string) string {
+	return eventName + "_" + LabelEventGrowth
+}
+
+// EventChangepointFeatureName returns the name of an event mask multiplied by the bias of a changepoint
+func EventChangepointFeatureName(eventName, chptName string) string {
+	return eventName + "_" + LabelEventChpt + "_" + chptName
+}
+
+// MixtureSeasonalityName returns the seasonality name of the daily component of a timezone location
+// when modeling a timezone mixture
+func MixtureSeasonalityName(location string) string {
+	return LabelSeasDaily + "_" + location
+}
+
+// IsEventChangepointFeatureName returns true if the feature name is the changepoint interaction of any
+// event with the changepoint
+func IsEventChangepointFeatureName(featName, chptName string) bool {
+	return strings.HasSuffix(featName, EventChangepointFeatureName("", chptName))
+}
+
+// HolidayEventName returns the event name of a holiday of the country in lower snake case
+func HolidayEventName(country, holiday string) string {
+	var b strings.Builder
+	b.WriteString(LabelHoliday + "_" + strings.ToLower(country) + "_")
+	lastUnderscore := true
+	for _, r := range strings.ToLower(holiday) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			lastUnderscore = false
+			continue
+		}
+		if !lastUnderscore {
+			b.WriteRune('_')
+			lastUnderscore = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "_")
+}
+
+// DetectedSeasonalityName returns the seasonality name of a period detected in the training data
+func DetectedSeasonalityName(period time.Duration) string {
+	return LabelSeasDetected + "_" + period.String()
+}
+
+// IsDetectedSeasonalityName returns true if the seasonality name is reserved for detected periods
+func IsDetectedSeasonalityName(seasName string) bool {
+	return strings.HasPrefix(seasName, LabelSeasDetected+"_")
+}
+
+// LaggedEventName returns the feature name of an event shifted by the input lag
+func LaggedEventName(name string, lag time.Duration) string {
+	return name + "_" + LabelLag + "_" + compactDuration(lag)
+}
+
+// LaggedRegressorName returns the feature name of a regressor holding its value the input lag earlier
+func LaggedRegressorName(name string, lag time.Duration) string {
+	return name + "_" + LabelLag + "_" + compactDuration(lag)
+}
+
+// compactDuration writes the duration as a whole number of its largest exact unit e.g. 90m rather
+// than 1h30m0s
+func compactDuration(d time.Duration) string {
+	units := []struct {
+		unit   time.Duration
+		suffix string
+	}{
+		{24 * time.Hour, "d"},
+		{time.Hour, "h"},
+		{time.Minute, "m"},
+		{time.Second, "s"},
+		{time.Millisecond, "ms"},
+		{time.Microsecond, "us"},
+	}
+	for _, u := range units {
+		if d%u.unit == 0 {
+			return strconv.FormatInt(int64(d/u.unit), 10) + u.suffix
+		}
+	}
+	return strconv.FormatInt(int64(d), 10) + "ns"
+}
+
+// NamespacedFeature returns the feature with the namespace prepended to its name. The feature is
+// returned as is if the namespace is empty or the feature type is unknown.
+func NamespacedFeature(f feature.Feature, namespace string) feature.Feature {
+	if namespace == "" || f == nil {
+		return f
+	}
+	name, _ := f.Get("name")
+	name = namespace + "_" + name
+	switch f.Type() {
+	case feature.FeatureTypeTime:
+		return feature.NewTime(name)
+	case feature.FeatureTypeEvent:
+		return feature.NewEvent(name)
+	case feature.FeatureTypeSeasonality:
+		fcomp, _ := f.Get("fourier_component")
+		orderStr, _ := f.Get("order")
+		order, _ := strconv.Atoi(orderStr)
+		return feature.NewSeasonality(name, feature.FourierComp(fcomp), order)
+	case feature.FeatureTypeChangepoint:
+		comp, _ := f.Get("changepoint_component")
+		return feature.NewChangepoint(name, feature.ChangepointComp(comp))
+	}
+	return f
+}
+
+// CheckFeatureCollisions returns ErrFeatureNameCollision if any custom feature has the same name as a
+// built in feature
+func CheckFeatureCollisions(builtin, custom *feature.Set) error {
+	if builtin == nil || custom == nil {
+		return nil
+	}
+	for _, f := range custom.Labels() {
+		if _, exists := builtin.Get(f); exists {
+			return fmt.Errorf("custom feature %q, %w", f.String(), ErrFeatureNameCollision)
+		}
+	}
+	return nil
+}
+
+// CheckEventNameCollisions returns ErrFeatureNameCollision if a configured event, lag of an event, or
+// custom event series has the same feature name as a holiday of the holiday options
+func (e EventOptions) CheckEventNameCollisions() error {
+	holidays := e.Holidays.eventNames()
+	if len(holidays) == 0 {
+		return nil
+	}
+	check := func(name string) error {
+		if holidays[strings.ReplaceAll(name, " ", "_")] {
+			return fmt.Errorf("event %q, %w", name, ErrFeatureNameCollision)
+		}
+		return nil
+	}
+	for _, ev := range e.Events {
+		if err := check(ev.Name); err != nil {
+			return err
+		}
+		for _, lag := range ev.Lags {
+			if lag == 0 {
+				continue
+			}
+			if err := check(LaggedEventName(ev.Name, lag)); err != nil {
+				return err
+			}
+		}
+	}
+	for _, c := range e.Custom {
+		if c.Series == nil {
+			continue
+		}
+		if err := check(c.Series.Name()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package options
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/feature"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeatureNames(t *testing.T) {
+	assert.Equal(t, "epoch_daily", SeasonalityFeatureName(LabelSeasDaily))
+	assert.Equal(t, "epoch_weekly_trend", TrendInteractionFeatureName(LabelSeasWeekly))
+	assert.Equal(t, "weekend_daily", EventSeasonalityFeatureName(LabelEventWeekend, LabelSeasDaily))
+	assert.Equal(t, "epoch_daily_America/New_York", SeasonalityFeatureName(MixtureSeasonalityName("America/New_York")))
+	assert.Equal(t, "holiday_us_christmas_day", HolidayEventName("US", "Christmas Day"))
+	assert.Equal(t, "holiday_gb_new_year_s_day", HolidayEventName("GB", "New Year's Day"))
+	assert.Equal(t, "detected_12h0m0s", DetectedSeasonalityName(12*time.Hour))
+	assert.True(t, IsDetectedSeasonalityName(DetectedSeasonalityName(90*time.Minute)))
+	assert.Equal(t, "launch_lag_1h", LaggedEventName("launch", time.Hour))
+	assert.Equal(t, "launch_lag_2d", LaggedEventName("launch", 48*time.Hour))
+	assert.Equal(t, "spend_lag_90m", LaggedRegressorName("spend", 90*time.Minute))
+	assert.Equal(t, "spend_lag_1500ms", LaggedRegressorName("spend", 1500*time.Millisecond))
+	assert.Equal(t, "spend_lag_-30s", LaggedRegressorName("spend", -30*time.Second))
+	assert.False(t, IsDetectedSeasonalityName(LabelSeasDetected))
+	assert.True(t, IsEventChangepointFeatureName(EventChangepointFeatureName("promo", "launch"), "launch"))
+	assert.False(t, IsEventChangepointFeatureName(EventChangepointFeatureName("promo", "launch"), "unch"))
+	assert.False(t, IsEventChangepointFeatureName(EventGrowthFeatureName("promo_chpt_launch"), "launch"))
+}
+
+func TestNamespacedFeature(t *testing.T) {
+	testData := map[string]struct {
+		f         feature.Feature
+		namespace string
+		expected  feature.Feature
+	}{
+		"no namespace": {
+			f:        feature.NewTime("even_hour"),
+			expected: feature.NewTime("even_hour"),
+		},
+		"time": {
+			f:         feature.NewTime("even_hour"),
+			namespace: "user",
+			expected:  feature.NewTime("user_even_hour"),
+		},
+		"event": {
+			f:         feature.NewEvent("promo"),
+			namespace: "user",
+			expected:  feature.NewEvent("user_promo"),
+		},
+		"seasonality": {
+			f:         feature.NewSeasonality("billing", feature.FourierCompCos, 2),
+			namespace: "user",
+			expected:  feature.NewSeasonality("user_billing", feature.FourierCompCos, 2),
+		},
+		"changepoint": {
+			f:         feature.NewChangepoint("launch", feature.ChangepointCompSlope),
+			namespace: "user",
+			expected:  feature.NewChangepoint("user_launch", feature.ChangepointCompSlope),
+		},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, td.expected, NamespacedFeature(td.f, td.namespace))
+		})
+	}
+}
+
+func TestCheckFeatureCollisions(t *testing.T) {
+	builtin := feature.NewSet().Set(feature.NewSeasonality(SeasonalityFeatureName(LabelSeasDaily), feature.FourierCompSin, 1), []float64{0})
+
+	testData := map[string]struct {
+		custom *feature.Set
+		err    error
+	}{
+		"no custom": {},
+		"distinct": {
+			custom: feature.NewSet().Set(feature.NewSeasonality("user_epoch_daily", feature.FourierCompSin, 1), []float64{0}),
+		},
+		"collision": {
+			custom: feature.NewSet().Set(feature.NewSeasonality("epoch_daily", feature.FourierCompSin, 1), []float64{0}),
+			err:    ErrFeatureNameCollision,
+		},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			err := CheckFeatureCollisions(builtin, td.custom)
+			if td.err != nil {
+				assert.ErrorIs(t, err, td.err)
+				return
+			}
+			assert.Nil(t, err)
+		})
+	}
+}
+
+func TestCheckEventNameCollisions(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	christmas := HolidayEventName("US", "Christmas Day")
+
+	testData := map[string]struct {
+		opt EventOptions
+		err error
+	}{
+		"no holidays": {
+			opt: EventOptions{Events: []Event{NewEvent(christmas, start, start.Add(time.Hour))}},
+		},
+		"distinct": {
+			opt: EventOptions{
+				Events:   []Event{NewEvent("promo", start, start.Add(time.Hour))},
+				Holidays: HolidayOptions{Countries: []string{"us"}},
+			},
+		},
+		"event": {
+			opt: EventOptions{
+				Events:   []Event{NewEvent(christmas, start, start.Add(time.Hour))},
+				Holidays: HolidayOptions{Countries: []string{"us"}},
+			},
+			err: ErrFeatureNameCollision,
+		},
+		"event with spaces": {
+			opt: EventOptions{
+				Events:   []Event{NewEvent("holiday us christmas day", start, start.Add(time.Hour))},
+				Holidays: HolidayOptions{Countries: []string{"us"}},
+			},
+			err: ErrFeatureNameCollision,
+		},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			assert.ErrorIs(t, td.opt.CheckEventNameCollisions(), td.err)
+		})
+	}
+}
@@ -0,0 +1,17 @@
+package options
+
+// NaNPolicy determines how predictions handle generated features containing NaN values e.g. from a
+// degenerate growth feature
+type NaNPolicy string
+
+const (
+	// NaNPolicyMark predicts NaN at the affected times and reports the offending features as the reason.
+	// This is the default if unset.
+	NaNPolicyMark NaNPolicy = "mark"
+
+	// NaNPolicyError fails the prediction on the first NaN feature value
+	NaNPolicyError NaNPolicy = "error"
+
+	// NaNPolicyZero replaces NaN feature values with zero removing their contribution to the prediction
+	NaNPolicyZero NaNPolicy = "zero"
+)
@@ -1,7 +1,6 @@
 package options
 
 import (
-	"errors"
 	"fmt"
 	"log/slog"
 	"math"
@@ -9,8 +8,10 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/aouyang1/go-forecaster/errs"
 	"github.com/aouyang1/go-forecaster/feature"
 	"github.com/aouyang1/go-forecaster/models"
+	"github.com/aouyang1/go-forecaster/timedataset"
 	"gonum.org/v1/gonum/dsp/window"
 	"gonum.org/v1/gonum/floats"
 )
@@ -20,6 +21,9 @@ const (
 
 	LabelSeasDaily  = "daily"
 	LabelSeasWeekly = "weekly"
+	LabelSeasYearly = "yearly"
+
+	LabelSeasMonthly = "monthly"
 
 	LabelEventWeekend = "weekend"
 
@@ -37,7 +41,7 @@ const (
 	WindowTriangular      = "triangular"
 )
 
-var ErrUnknownTimeFeature = errors.New("unknown time feature")
+var ErrUnknownTimeFeature = errs.NewConfigError(errs.CodeUnknownFeature, "unknown time feature", nil)
 
 func WindowFunc(name string) func(seq []float64) []float64 {
 	var winFunc func(seq []float64) []float64
@@ -84,12 +88,91 @@ type Options struct {
 	Tolerance       float64   `json:"tolerance"`
 	Parallelization int       `json:"parallelization"`
 
+	// AdaptiveLasso weights the penalty of each feature by the inverse magnitude of its coefficient from
+	// an initial ridge fit which selects more consistently among many correlated fourier features
+	AdaptiveLasso bool `json:"adaptive_lasso"`
+
+	// StandardizeFeatures centers and scales every feature column to unit variance before the fit so the
+	// regularization penalizes every feature on the same scale. The coefficients are unscaled to the raw
+	// features after the fit so predictions are unaffected. Smooth trend changepoints keep their scale.
+	StandardizeFeatures bool `json:"standardize_features,omitempty"`
+
+	// MatrixBackend selects a dense or sparse design matrix for the lasso fit defaulting to dense
+	MatrixBackend MatrixBackend `json:"matrix_backend,omitempty"`
+
+	// RetainCoefficientPath keeps the coefficients of every regularization lambda for inspecting the
+	// Lasso coefficient paths
+	RetainCoefficientPath bool `json:"retain_coefficient_path,omitempty"`
+
+	// RetainTrainingMatrix keeps the design matrix and target of the fit for exporting with
+	// ExportTrainingMatrix
+	RetainTrainingMatrix bool `json:"retain_training_matrix,omitempty"`
+
+	// CVFolds selects the lasso regularization lambda by time series cross validation with this many
+	// folds scored by CVScoring instead of by the in-sample fit
+	CVFolds   int              `json:"cv_folds,omitempty"`
+	CVScoring models.CVScoring `json:"cv_scoring,omitempty"`
+
+	// Regression selects the regression backend defaulting to RegressionLasso. L1Ratios are the mixes of
+	// L1 and L2 penalties swept by the elastic net. Quantile is the conditional quantile fit by the
+	// quantile regression defaulting to the median. HuberDelta is the residual threshold in robust
+	// standard deviations of the huber regression defaulting to models.DefaultHuberDelta.
+	Regression Regression `json:"regression,omitempty"`
+	L1Ratios   []float64  `json:"l1_ratios,omitempty"`
+	Quantile   float64    `json:"quantile,omitempty"`
+	HuberDelta float64    `json:"huber_delta,omitempty"`
+
+	// CoefficientErrors estimates the standard error and confidence interval of every coefficient
+	CoefficientErrors CoefficientErrorOptions `json:"coefficient_errors,omitempty"`
+
 	SeasonalityOptions SeasonalityOptions `json:"seasonality_options"`
 
 	DSTOptions     DSTOptions     `json:"dst_options"`
 	WeekendOptions WeekendOptions `json:"weekend_options"`
 	EventOptions   EventOptions   `json:"event_options"`
 	MaskWindow     string         `json:"mask_window"`
+
+	FastPathOptions       FastPathOptions       `json:"fast_path_options"`
+	RedundancyOptions     RedundancyOptions     `json:"redundancy_options"`
+	StructuralZeroOptions StructuralZeroOptions `json:"structural_zero_options"`
+
+	// CustomFeatures lists the names of custom time features registered with RegisterTimeFeature.
+	// CustomFeatureNamespace prefixes the name of every generated custom feature so that it cannot
+	// collide with a built in feature.
+	CustomFeatures         []string `json:"custom_features"`
+	CustomFeatureNamespace string   `json:"custom_feature_namespace,omitempty"`
+
+	// CustomFeatureLabels is the label of the feature generated by each custom time feature which is
+	// set by the fit so a missing custom time feature can be filled at prediction
+	CustomFeatureLabels map[string]string `json:"custom_feature_labels,omitempty"`
+
+	// MissingFeatures sets the policy per custom time feature or regressor name for predicting when it
+	// is unavailable. Unlisted features fail the prediction.
+	MissingFeatures map[string]MissingFeatureOptions `json:"missing_features,omitempty"`
+
+	// RegressorLags adds a feature per non-zero lag of the named regressor holding its value the lag
+	// earlier to capture delayed effects e.g. traffic hours after a marketing push. Lagged values at times
+	// the regressor is not provided for are zero which suits binary regressors.
+	RegressorLags map[string][]time.Duration `json:"regressor_lags,omitempty"`
+
+	// SamplingInterval is the sampling interval of the training data which is set by the fit so the
+	// event masks of a single time can be generated at prediction. The interval is estimated from the
+	// times if unset.
+	SamplingInterval time.Duration `json:"sampling_interval,omitempty"`
+
+	// NaNPolicy handles NaN values in features generated for prediction defaulting to NaNPolicyMark
+	NaNPolicy NaNPolicy `json:"nan_policy,omitempty"`
+
+	// TrainingHooks reports the progress of the lasso fit and is not serialized
+	TrainingHooks *TrainingHooks `json:"-"`
+}
+
+// TrainingHooks are optional callbacks reporting the progress of the lasso regression.
+// OnIterationComplete is called after every coordinate descent iteration and OnLambdaComplete after
+// the fit of every regularization lambda. Lambdas fit in parallel call these concurrently.
+type TrainingHooks struct {
+	OnIterationComplete func(models.IterationEvent)
+	OnLambdaComplete    func(models.LambdaEvent)
 }
 
 // NewDefaultOptions returns a set of default forecast options
@@ -122,6 +205,14 @@ func (o *Options) NewLassoAutoOptions() *models.LassoAutoOptions {
 	}
 
 	lassoOpt.Parallelization = o.Parallelization
+	lassoOpt.Adaptive = o.AdaptiveLasso
+	lassoOpt.RetainPath = o.RetainCoefficientPath
+	lassoOpt.CVFolds = o.CVFolds
+	lassoOpt.CVScoring = o.CVScoring
+	if o.TrainingHooks != nil {
+		lassoOpt.OnIteration = o.TrainingHooks.OnIterationComplete
+		lassoOpt.OnLambda = o.TrainingHooks.OnLambdaComplete
+	}
 	return lassoOpt
 }
 
@@ -155,11 +246,35 @@ func (o *Options) GenerateEventFeatures(t []time.Time) *feature.Set {
 
 	eFeat := feature.NewSet()
 
-	o.WeekendOptions.generateEventMask(t, eFeat, winFunc)
-	o.EventOptions.generateEventMask(t, eFeat, winFunc)
+	freq, ok := o.maskInterval(t)
+	if !ok {
+		return eFeat
+	}
+	o.WeekendOptions.generateEventMask(t, freq, eFeat, winFunc)
+	o.EventOptions.generateEventMask(t, freq, eFeat, winFunc)
 	return eFeat
 }
 
+// maskInterval returns the interval the event masks of the times are padded at which is the
+// SamplingInterval set by the fit or else estimated from the times. False is returned if there are no
+// times or too few to estimate the interval.
+func (o *Options) maskInterval(t []time.Time) (time.Duration, bool) {
+	if len(t) == 0 {
+		return 0, false
+	}
+	if o.SamplingInterval > 0 {
+		return o.SamplingInterval, true
+	}
+	if len(t) < 2 {
+		return 0, false
+	}
+	freq, err := timedataset.TimeSlice(t).EstimateFreq()
+	if err != nil {
+		panic(err)
+	}
+	return freq, true
+}
+
 func (o *Options) GenerateFourierFeatures(feat *feature.Set) (*feature.Set, error) {
 	if o == nil {
 		o = NewDefaultOptions()
@@ -191,11 +306,33 @@ func (o *Options) GenerateFourierFeatures(feat *feature.Set) (*feature.Set, erro
 			}
 			orders = append(orders, i)
 		}
-		seasFeatures, err := generateFourierOrders(feat, orders, seasCfg.Period, seasCfg.Name)
+		var err error
+		seasTimeFeat := feat
+		if seasCfg.Name == LabelSeasYearly {
+			// the dst shift only moves the daily phase so the yearly phase is taken from the original time
+			seasTimeFeat, err = o.DSTOptions.unadjustTimeFeatures(feat)
+			if err != nil {
+				return nil, fmt.Errorf("unable to remove dst adjustment for %q, %w", seasCfg.Name, err)
+			}
+		}
+		seasFeatures, err := generateFourierOrders(seasTimeFeat, orders, seasCfg.Period, seasCfg.Name)
 		if err != nil {
 			return nil, fmt.Errorf("unable to generate seasonality features for %q, %w", seasCfg.Name, err)
 		}
-		x.Update(seasFeatures)
+
+		// replace the daily seasonality with the local daily seasonality of each timezone
+		var mixFeatures *feature.Set
+		if seasCfg.Name == LabelSeasDaily && o.DSTOptions.mixtureEnabled() {
+			mixFeatures, err = o.DSTOptions.generateMixtureFourierOrders(feat, orders, seasCfg.Period)
+			if err != nil {
+				return nil, fmt.Errorf("unable to generate timezone mixture features, %w", err)
+			}
+		}
+		if mixFeatures != nil && mixFeatures.Len() > 0 {
+			x.Update(mixFeatures)
+		} else {
+			x.Update(seasFeatures)
+		}
 
 		switch seasCfg.Name {
 		case LabelSeasDaily:
@@ -222,6 +359,12 @@ func (o *Options) GenerateFourierFeatures(feat *feature.Set) (*feature.Set, erro
 		}
 
 	}
+
+	monthlyFeatures, err := o.SeasonalityOptions.Monthly.generateFourierFeatures(feat)
+	if err != nil {
+		return nil, fmt.Errorf("unable to generate monthly seasonality features, %w", err)
+	}
+	x.Update(monthlyFeatures)
 	return x, nil
 }
 
@@ -241,8 +384,8 @@ func generateFourierOrders(tFeatures *feature.Set, orders []int, periodDur time.
 	x := feature.NewSet()
 	for _, order := range orders {
 		sinFeat, cosFeat := generateFourierComponent(tFeat, order, period)
-		sinFeatCol := feature.NewSeasonality(col+"_"+label, feature.FourierCompSin, order)
-		cosFeatCol := feature.NewSeasonality(col+"_"+label, feature.FourierCompCos, order)
+		sinFeatCol := feature.NewSeasonality(SeasonalityFeatureName(label), feature.FourierCompSin, order)
+		cosFeatCol := feature.NewSeasonality(SeasonalityFeatureName(label), feature.FourierCompCos, order)
 		x.Set(sinFeatCol, sinFeat)
 		x.Set(cosFeatCol, cosFeat)
 	}
@@ -282,7 +425,7 @@ func generateEventSeasonality(feat, sFeat *feature.Set, eCol, sLabel string) (*f
 
 		orderStr, _ := label.Get("order")
 		order, _ := strconv.Atoi(orderStr)
-		featCol := feature.NewSeasonality(eCol+"_"+sLabel, fcomp, order)
+		featCol := feature.NewSeasonality(EventSeasonalityFeatureName(eCol, sLabel), fcomp, order)
 		eventSeasonalityFeatures.Set(featCol, maskedData)
 	}
 	return eventSeasonalityFeatures, nil
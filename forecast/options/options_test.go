@@ -163,6 +163,87 @@ func TestGenerateTimeFeatures(t *testing.T) {
 				},
 			),
 		},
+		"friday saturday weekend": {
+			t: timedataset.GenerateT(4*7, 6*time.Hour, nowFunc),
+			opt: &Options{
+				WeekendOptions: WeekendOptions{
+					Enabled: true,
+					Days: []WeekendDay{
+						{Day: time.Friday, StartOffset: 12 * time.Hour},
+						{Day: time.Saturday},
+					},
+				},
+			},
+			expected: feature.NewSet().Set(
+				feature.NewTime("epoch"),
+				epoch7DaysAt6Hr,
+			).Set(
+				feature.NewEvent("weekend"),
+				[]float64{
+					0, 0, 0, 0, // Thursday
+					0, 0, 1, 1, // Friday
+					1, 1, 1, 1, // Saturday
+					0, 0, 0, 0, // Sunday
+					0, 0, 0, 0, // Monday
+					0, 0, 0, 0, // Tuesday
+					0, 0, 0, 0, // Wednesday
+				},
+			),
+		},
+		"friday saturday weekend with buffers": {
+			t: timedataset.GenerateT(4*7, 6*time.Hour, nowFunc),
+			opt: &Options{
+				WeekendOptions: WeekendOptions{
+					Enabled:   true,
+					DurBefore: 6 * time.Hour,
+					DurAfter:  6 * time.Hour,
+					Days: []WeekendDay{
+						{Day: time.Friday, StartOffset: 12 * time.Hour},
+						{Day: time.Saturday},
+					},
+				},
+			},
+			expected: feature.NewSet().Set(
+				feature.NewTime("epoch"),
+				epoch7DaysAt6Hr,
+			).Set(
+				feature.NewEvent("weekend"),
+				[]float64{
+					0, 0, 0, 0, // Thursday
+					0, 1, 1, 1, // Friday
+					1, 1, 1, 1, // Saturday
+					1, 0, 0, 0, // Sunday
+					0, 0, 0, 0, // Monday
+					0, 0, 0, 0, // Tuesday
+					0, 0, 0, 0, // Wednesday
+				},
+			),
+		},
+		"weekend with ramps": {
+			t: timedataset.GenerateT(4*7, 6*time.Hour, nowFunc),
+			opt: &Options{
+				WeekendOptions: WeekendOptions{
+					Enabled:  true,
+					RampUp:   12 * time.Hour,
+					RampDown: 24 * time.Hour,
+				},
+			},
+			expected: feature.NewSet().Set(
+				feature.NewTime("epoch"),
+				epoch7DaysAt6Hr,
+			).Set(
+				feature.NewEvent("weekend"),
+				[]float64{
+					0, 0, 0, 0, // Thursday
+					0, 0, 0, 0.5, // Friday
+					1, 1, 1, 1, // Saturday
+					1, 1, 1, 1, // Sunday
+					0.75, 0.5, 0.25, 0, // Monday
+					0, 0, 0, 0, // Tuesday
+					0, 0, 0, 0, // Wednesday
+				},
+			),
+		},
 		"weekend with buffers": {
 			t: timedataset.GenerateT(4*7, 6*time.Hour, nowFunc),
 			opt: &Options{
@@ -556,6 +637,55 @@ func TestGenerateTimeFeatures(t *testing.T) {
 				},
 			),
 		},
+		"event overriding window with ramps": {
+			t: timedataset.GenerateT(4*7, 6*time.Hour, nowFunc),
+			opt: &Options{
+				MaskWindow: "hann",
+				EventOptions: EventOptions{
+					Events: []Event{
+						{
+							Name:       "promo",
+							Start:      time.Date(1970, 1, 2, 12, 0, 0, 0, time.UTC),
+							End:        time.Date(1970, 1, 3, 12, 0, 0, 0, time.UTC),
+							MaskWindow: WindowRectangular,
+							RampDown:   48 * time.Hour,
+						},
+						{
+							Name:   "ramped_before_start",
+							Start:  time.Date(1970, 1, 8, 12, 0, 0, 0, time.UTC),
+							End:    time.Date(1970, 1, 9, 0, 0, 0, 0, time.UTC),
+							RampUp: 24 * time.Hour,
+						},
+					},
+				},
+			},
+			expected: feature.NewSet().Set(
+				feature.NewTime("epoch"),
+				epoch7DaysAt6Hr,
+			).Set(
+				feature.NewEvent("promo"),
+				[]float64{
+					0, 0, 0, 0, // Thursday
+					0, 0, 1, 1, // Friday
+					1, 1, 0.875, 0.75, // Saturday
+					0.625, 0.5, 0.375, 0.25, // Sunday
+					0.125, 0, 0, 0, // Monday
+					0, 0, 0, 0, // Tuesday
+					0, 0, 0, 0, // Wednesday
+				},
+			).Set(
+				feature.NewEvent("ramped_before_start"),
+				[]float64{
+					0, 0, 0, 0, // Thursday
+					0, 0, 0, 0, // Friday
+					0, 0, 0, 0, // Saturday
+					0, 0, 0, 0, // Sunday
+					0, 0, 0, 0, // Monday
+					0, 0, 0, 0, // Tuesday
+					0, 0, 0, 0.25, // Wednesday
+				},
+			),
+		},
 		"event with hamm window overlapping start and end": {
 			t: timedataset.GenerateT(4*7, 6*time.Hour, nowFunc),
 			opt: &Options{
@@ -0,0 +1,182 @@
+package options
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/errs"
+	"github.com/aouyang1/go-forecaster/models"
+	"github.com/aouyang1/go-forecaster/timedataset"
+	"gonum.org/v1/gonum/mat"
+)
+
+var ErrUnknownOrderCriterion = errs.NewConfigError(errs.CodeInvalidOption, "unknown fourier order selection criterion", nil)
+
+// DefaultMaxSelectedOrders is the most fourier orders tried by order selection if MaxOrders is unset
+const DefaultMaxSelectedOrders = 20
+
+// OrderCriterion is the information criterion minimized when selecting the fourier orders of a
+// seasonality
+type OrderCriterion string
+
+const (
+	// OrderCriterionNone keeps the configured orders. This is the default if unset.
+	OrderCriterionNone OrderCriterion = "none"
+
+	// OrderCriterionAIC selects orders by the Akaike information criterion which penalizes every
+	// coefficient by 2
+	OrderCriterionAIC OrderCriterion = "aic"
+
+	// OrderCriterionBIC selects orders by the Bayesian information criterion which penalizes every
+	// coefficient by the log of the number of observations and picks fewer orders on long series
+	OrderCriterionBIC OrderCriterion = "bic"
+)
+
+// score returns the information criterion of a least squares fit of n observations with p
+// coefficients and a residual sum of squares of rss
+func (c OrderCriterion) score(n, p int, rss float64) float64 {
+	fit := float64(n) * math.Log(rss/float64(n))
+	if c == OrderCriterionBIC {
+		return fit + float64(p)*math.Log(float64(n))
+	}
+	return fit + 2.0*float64(p)
+}
+
+// selectsOrders returns true if the seasonality config selects its orders from the training data
+func (s SeasonalityConfig) selectsOrders() (bool, error) {
+	switch s.OrderCriterion {
+	case "", OrderCriterionNone:
+		return false, nil
+	case OrderCriterionAIC, OrderCriterionBIC:
+		return true, nil
+	}
+	return false, fmt.Errorf("%q for seasonality %q, %w", s.OrderCriterion, s.Name, ErrUnknownOrderCriterion)
+}
+
+// SelectSeasonalityOrders sets the orders of every seasonality config with an OrderCriterion from the
+// training data. Starting from a single order, orders are added while the criterion of an ordinary
+// least squares fit of a linear trend, the other seasonalities, and the selected orders improves, up
+// to MaxOrders or the highest order below the Nyquist frequency of the sampling interval. Shorter
+// periods are selected first and orders coinciding with the period of another seasonality are not
+// counted. The selected orders are stored in Orders so they are serialized with the options.
+func (o *Options) SelectSeasonalityOrders(t []time.Time, y []float64) error {
+	configs := o.SeasonalityOptions.SeasonalityConfigs
+	var selecting []int
+	for i, seasCfg := range configs {
+		selects, err := seasCfg.selectsOrders()
+		if err != nil {
+			return err
+		}
+		if selects && seasCfg.Period > 0 {
+			selecting = append(selecting, i)
+		}
+	}
+	if len(selecting) == 0 || len(t) < 4 {
+		return nil
+	}
+	freq, err := timedataset.TimeSlice(t).EstimateFreq()
+	if err != nil {
+		return fmt.Errorf("unable to estimate sampling interval for seasonality order selection, %w", err)
+	}
+	sort.SliceStable(selecting, func(i, j int) bool {
+		return configs[selecting[i]].Period < configs[selecting[j]].Period
+	})
+
+	epoch := make([]float64, len(t))
+	for i, tPnt := range o.DSTOptions.AdjustTime(t) {
+		epoch[i] = float64(tPnt.UnixNano()) / 1e9
+	}
+	trend := make([]float64, len(epoch))
+	if span := epoch[len(epoch)-1] - epoch[0]; span > 0 {
+		for i, e := range epoch {
+			trend[i] = (e - epoch[0]) / span
+		}
+	}
+	target := mat.NewDense(len(y), 1, y)
+
+	pending := make(map[int]bool, len(selecting))
+	for _, i := range selecting {
+		pending[i] = true
+	}
+	for _, i := range selecting {
+		delete(pending, i)
+
+		// fourier columns of the trend and every other seasonality which is not waiting on selection
+		periods := make(map[time.Duration]bool)
+		cols := [][]float64{trend}
+		for j, seasCfg := range configs {
+			if j == i || pending[j] {
+				continue
+			}
+			for k := 1; k <= seasCfg.Orders; k++ {
+				cols = appendFourierOrder(cols, periods, epoch, seasCfg.Period, k)
+			}
+		}
+
+		seasCfg := configs[i]
+		maxOrders := seasCfg.MaxOrders
+		if maxOrders <= 0 {
+			maxOrders = DefaultMaxSelectedOrders
+		}
+		selected, best := 0, math.Inf(1)
+		for k := 1; k <= maxOrders && seasCfg.Period/time.Duration(k) >= 2*freq; k++ {
+			numCols := len(cols)
+			cols = appendFourierOrder(cols, periods, epoch, seasCfg.Period, k)
+			if len(cols) == numCols {
+				// the order is already modeled by another seasonality
+				continue
+			}
+			score := seasCfg.OrderCriterion.score(len(y), len(cols)+1, olsRSS(cols, target))
+			if math.IsNaN(score) || (selected > 0 && score >= best) {
+				break
+			}
+			selected, best = k, score
+		}
+		if selected == 0 {
+			selected = 1
+		}
+		configs[i].Orders = selected
+	}
+	return nil
+}
+
+// appendFourierOrder appends the sine and cosine columns of an order of a period unless the period of
+// the order is already modeled
+func appendFourierOrder(cols [][]float64, periods map[time.Duration]bool, epoch []float64, period time.Duration, order int) [][]float64 {
+	orderPeriod := period / time.Duration(order)
+	if periods[orderPeriod] {
+		return cols
+	}
+	periods[orderPeriod] = true
+	sinFeat, cosFeat := generateFourierComponent(epoch, order, period.Seconds())
+	return append(cols, sinFeat, cosFeat)
+}
+
+// olsRSS returns the residual sum of squares of an ordinary least squares fit of the columns with an
+// intercept
+func olsRSS(cols [][]float64, target *mat.Dense) float64 {
+	n, _ := target.Dims()
+	x := mat.NewDense(n, len(cols), nil)
+	for j, col := range cols {
+		x.SetCol(j, col)
+	}
+	ols, err := models.NewOLSRegression(models.NewDefaultOLSOptions())
+	if err != nil {
+		return math.NaN()
+	}
+	if err := ols.Fit(x, target); err != nil {
+		return math.NaN()
+	}
+	pred, err := ols.Predict(x)
+	if err != nil {
+		return math.NaN()
+	}
+	var rss float64
+	for i, p := range pred {
+		r := target.At(i, 0) - p
+		rss += r * r
+	}
+	return rss
+}
@@ -0,0 +1,92 @@
+package options
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectSeasonalityOrders(t *testing.T) {
+	n := 2 * 7 * 24 * 4
+	ct := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tWin := make([]time.Time, 0, n)
+	for i := 0; i < n; i++ {
+		tWin = append(tWin, ct.Add(time.Duration(i)*15*time.Minute))
+	}
+	rng := rand.New(rand.NewSource(7))
+	y := make([]float64, n)
+	for i, tPnt := range tWin {
+		sec := tPnt.Sub(ct).Seconds()
+		y[i] = 5.0 + 0.001*float64(i) +
+			3.0*math.Sin(2.0*math.Pi*sec/86400.0) +
+			2.0*math.Cos(4.0*math.Pi*sec/86400.0) +
+			1.0*math.Sin(6.0*math.Pi*sec/86400.0) +
+			1.5*math.Sin(2.0*math.Pi*sec/(7*86400.0)) +
+			0.3*rng.NormFloat64()
+	}
+
+	testData := map[string]struct {
+		configs  []SeasonalityConfig
+		expected []int
+		err      error
+	}{
+		"fixed orders": {
+			configs:  []SeasonalityConfig{NewDailySeasonalityConfig(12)},
+			expected: []int{12},
+		},
+		"bic": {
+			configs: []SeasonalityConfig{
+				{Name: LabelSeasDaily, Period: 24 * time.Hour, OrderCriterion: OrderCriterionBIC},
+			},
+			expected: []int{3},
+		},
+		"aic with weekly": {
+			configs: []SeasonalityConfig{
+				{Name: LabelSeasWeekly, Period: 7 * 24 * time.Hour, OrderCriterion: OrderCriterionAIC, MaxOrders: 3},
+				{Name: LabelSeasDaily, Period: 24 * time.Hour, OrderCriterion: OrderCriterionAIC},
+			},
+			expected: []int{1, 3},
+		},
+		"max orders": {
+			configs: []SeasonalityConfig{
+				{Name: LabelSeasDaily, Period: 24 * time.Hour, OrderCriterion: OrderCriterionBIC, MaxOrders: 2},
+			},
+			expected: []int{2},
+		},
+		"no signal keeps one order": {
+			configs: []SeasonalityConfig{
+				{Name: "hourly", Period: time.Hour, OrderCriterion: OrderCriterionBIC},
+			},
+			expected: []int{1},
+		},
+		"unknown criterion": {
+			configs: []SeasonalityConfig{
+				{Name: LabelSeasDaily, Period: 24 * time.Hour, OrderCriterion: "cv"},
+			},
+			err: ErrUnknownOrderCriterion,
+		},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			opt := NewDefaultOptions()
+			opt.SeasonalityOptions.SeasonalityConfigs = td.configs
+			err := opt.SelectSeasonalityOrders(tWin, y)
+			if td.err != nil {
+				assert.ErrorIs(t, err, td.err)
+				return
+			}
+			require.Nil(t, err)
+
+			var orders []int
+			for _, seasCfg := range opt.SeasonalityOptions.SeasonalityConfigs {
+				orders = append(orders, seasCfg.Orders)
+			}
+			assert.Equal(t, td.expected, orders)
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package options
+
+import (
+	"math"
+
+	"github.com/aouyang1/go-forecaster/feature"
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat"
+)
+
+// DefaultCorrelationThreshold is the absolute correlation above which two features are considered duplicates
+const DefaultCorrelationThreshold = 0.999
+
+// LabelIntercept is reported as the duplicate of constant features since they are collinear with the intercept
+const LabelIntercept = "intercept"
+
+// RedundancyOptions removes constant features and near duplicate features before fitting. Identical
+// columns such as a weekend mask and an event covering every weekend otherwise split their weight
+// arbitrarily between each other. A feature is dropped in favor of an earlier feature in label order if
+// the absolute correlation between them is at least CorrelationThreshold, defaulting to
+// DefaultCorrelationThreshold if unset.
+type RedundancyOptions struct {
+	Enabled              bool    `json:"enabled"`
+	CorrelationThreshold float64 `json:"correlation_threshold"`
+}
+
+// RedundantFeature reports a feature dropped before fitting and the retained feature it duplicates
+type RedundantFeature struct {
+	Feature     string  `json:"feature"`
+	DuplicateOf string  `json:"duplicate_of"`
+	Correlation float64 `json:"correlation"`
+}
+
+// RemoveRedundant deletes constant and near duplicate features from the feature set returning a report
+// of every dropped feature
+func (r RedundancyOptions) RemoveRedundant(x *feature.Set) []RedundantFeature {
+	if !r.Enabled || x == nil || x.Len() == 0 {
+		return nil
+	}
+	threshold := r.CorrelationThreshold
+	if threshold <= 0 {
+		threshold = DefaultCorrelationThreshold
+	}
+
+	var removed []RedundantFeature
+	var labels []feature.Feature
+	var cols [][]float64
+	for _, label := range x.Labels() {
+		data, _ := x.Get(label)
+		if floats.Max(data) == floats.Min(data) {
+			removed = append(removed, RedundantFeature{
+				Feature:     label.String(),
+				DuplicateOf: LabelIntercept,
+				Correlation: 1.0,
+			})
+			x.Del(label)
+			continue
+		}
+		labels = append(labels, label)
+		cols = append(cols, data)
+	}
+	if len(labels) < 2 {
+		return removed
+	}
+
+	// standardize each column so that the gram matrix is the correlation matrix
+	m := len(cols[0])
+	z := mat.NewDense(m, len(cols), nil)
+	for j, col := range cols {
+		mean, std := stat.PopMeanStdDev(col, nil)
+		scale := 1.0 / (std * math.Sqrt(float64(m)))
+		for i, v := range col {
+			z.Set(i, j, (v-mean)*scale)
+		}
+	}
+	var corr mat.SymDense
+	corr.SymOuterK(1.0, z.T())
+
+	kept := make([]int, 0, len(labels))
+	for j, label := range labels {
+		duplicate := -1
+		for _, k := range kept {
+			if math.Abs(corr.At(j, k)) >= threshold {
+				duplicate = k
+				break
+			}
+		}
+		if duplicate < 0 {
+			kept = append(kept, j)
+			continue
+		}
+		removed = append(removed, RedundantFeature{
+			Feature:     label.String(),
+			DuplicateOf: labels[duplicate].String(),
+			Correlation: corr.At(j, duplicate),
+		})
+		x.Del(label)
+	}
+	return removed
+}
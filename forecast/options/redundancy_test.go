@@ -0,0 +1,63 @@
+package options
+
+import (
+	"testing"
+
+	"github.com/aouyang1/go-forecaster/feature"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRemoveRedundant(t *testing.T) {
+	newSet := func() *feature.Set {
+		x := feature.NewSet()
+		x.Set(feature.NewEvent("a_weekend"), []float64{0, 0, 1, 1, 0, 0, 1, 1})
+		x.Set(feature.NewEvent("b_all_weekends"), []float64{0, 0, 1, 1, 0, 0, 1, 1})
+		x.Set(feature.NewEvent("c_weekday"), []float64{1, 1, 0, 0, 1, 1, 0, 0})
+		x.Set(feature.NewEvent("d_constant"), []float64{1, 1, 1, 1, 1, 1, 1, 1})
+		x.Set(feature.NewEvent("e_partial"), []float64{0, 0, 1, 1, 0, 0, 0, 0})
+		return x
+	}
+
+	testData := map[string]struct {
+		opt      RedundancyOptions
+		remain   []string
+		expected []RedundantFeature
+	}{
+		"disabled": {
+			opt: RedundancyOptions{},
+			remain: []string{
+				"event_a_weekend", "event_b_all_weekends", "event_c_weekday",
+				"event_d_constant", "event_e_partial",
+			},
+		},
+		"enabled": {
+			opt:    RedundancyOptions{Enabled: true},
+			remain: []string{"event_a_weekend", "event_e_partial"},
+			expected: []RedundantFeature{
+				{Feature: "event_d_constant", DuplicateOf: LabelIntercept, Correlation: 1.0},
+				{Feature: "event_b_all_weekends", DuplicateOf: "event_a_weekend", Correlation: 1.0},
+				{Feature: "event_c_weekday", DuplicateOf: "event_a_weekend", Correlation: -1.0},
+			},
+		},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			x := newSet()
+			res := td.opt.RemoveRedundant(x)
+
+			remain := make([]string, 0, x.Len())
+			for _, label := range x.Labels() {
+				remain = append(remain, label.String())
+			}
+			assert.Equal(t, td.remain, remain)
+
+			assert.Len(t, res, len(td.expected))
+			for i, r := range res {
+				assert.Equal(t, td.expected[i].Feature, r.Feature)
+				assert.Equal(t, td.expected[i].DuplicateOf, r.DuplicateOf)
+				assert.InDelta(t, td.expected[i].Correlation, r.Correlation, 1e-9)
+			}
+		})
+	}
+}
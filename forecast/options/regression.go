@@ -0,0 +1,176 @@
+package options
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/aouyang1/go-forecaster/errs"
+	"github.com/aouyang1/go-forecaster/models"
+)
+
+var (
+	ErrUnknownRegression     = errs.NewConfigError(errs.CodeInvalidOption, "unknown regression backend", nil)
+	ErrEmptyRegressorName    = errs.NewConfigError(errs.CodeMissingOption, "regressor name cannot be empty", nil)
+	ErrNilRegressorFactory   = errs.NewConfigError(errs.CodeMissingOption, "regressor factory cannot be nil", nil)
+	ErrRegressorRegistered   = errs.NewConfigError(errs.CodeDuplicateLabel, "regressor already registered", nil)
+	ErrBuiltinRegressionName = errs.NewConfigError(errs.CodeDuplicateLabel, "regressor name is reserved for a built in regression", nil)
+)
+
+// RegressorFactory initializes a user defined regressor from the forecast options. The training
+// matrix passed to Fit has the constant intercept column first, so the first coefficient is taken as
+// the intercept and FitIntercept should not be set on the regressor.
+type RegressorFactory func(o *Options) (models.Regressor, error)
+
+var (
+	regressorsMu sync.RWMutex
+	regressors   = make(map[string]RegressorFactory)
+)
+
+// RegisterRegressor registers a user defined regressor by name so it can be selected with
+// Options.Regression. Models only store the name so the same regressor must be registered in any
+// process that refits the model.
+func RegisterRegressor(name string, factory RegressorFactory) error {
+	if name == "" {
+		return ErrEmptyRegressorName
+	}
+	if factory == nil {
+		return fmt.Errorf("%q, %w", name, ErrNilRegressorFactory)
+	}
+	switch Regression(name) {
+	case RegressionLasso, RegressionRidge, RegressionElasticNet, RegressionQuantile, RegressionHuber:
+		return fmt.Errorf("%q, %w", name, ErrBuiltinRegressionName)
+	}
+
+	regressorsMu.Lock()
+	defer regressorsMu.Unlock()
+	if _, exists := regressors[name]; exists {
+		return fmt.Errorf("%q, %w", name, ErrRegressorRegistered)
+	}
+	regressors[name] = factory
+	return nil
+}
+
+// UnregisterRegressor removes a user defined regressor from the registry
+func UnregisterRegressor(name string) {
+	regressorsMu.Lock()
+	defer regressorsMu.Unlock()
+	delete(regressors, name)
+}
+
+// Regression selects the linear regression used to fit the feature coefficients. Each built in backend
+// sweeps the Regularization lambdas and keeps the best fit. Any other value names a regressor
+// registered with RegisterRegressor.
+type Regression string
+
+const (
+	// RegressionLasso fits with L1 coordinate descent. This is the default if unset.
+	RegressionLasso Regression = "lasso"
+
+	// RegressionRidge fits with an L2 penalty in closed form which keeps every feature
+	RegressionRidge Regression = "ridge"
+
+	// RegressionElasticNet fits with a mix of L1 and L2 penalties sweeping every L1Ratios value
+	RegressionElasticNet Regression = "elastic_net"
+
+	// RegressionQuantile fits the conditional Quantile by minimizing the unpenalized pinball loss so
+	// the Regularization lambdas are ignored
+	RegressionQuantile Regression = "quantile"
+
+	// RegressionHuber fits by minimizing the unpenalized huber loss so heavy tailed noise and outliers
+	// are downweighted without discarding samples. The Regularization lambdas are ignored.
+	RegressionHuber Regression = "huber"
+)
+
+// NewRidgeAutoOptions returns the ridge options of the configured regularization. The intercept is
+// expected as the first feature column.
+func (o *Options) NewRidgeAutoOptions() *models.RidgeAutoOptions {
+	ridgeOpt := models.NewDefaultRidgeAutoOptions()
+	if len(o.Regularization) > 0 {
+		ridgeOpt.Lambdas = o.Regularization
+	} else {
+		o.Regularization = ridgeOpt.Lambdas
+	}
+	ridgeOpt.FitIntercept = false
+	return ridgeOpt
+}
+
+// NewElasticNetAutoOptions returns the elastic net options of the configured regularization and l1
+// ratios. The intercept is expected as the first feature column.
+func (o *Options) NewElasticNetAutoOptions() *models.ElasticNetAutoOptions {
+	lassoOpt := o.NewLassoAutoOptions()
+	enetOpt := models.NewDefaultElasticNetAutoOptions()
+	enetOpt.Lambdas = lassoOpt.Lambdas
+	if len(o.L1Ratios) > 0 {
+		enetOpt.L1Ratios = o.L1Ratios
+	}
+	enetOpt.Iterations = lassoOpt.Iterations
+	enetOpt.Tolerance = lassoOpt.Tolerance
+	enetOpt.FitIntercept = false
+	return enetOpt
+}
+
+// NewQuantileOptions returns the quantile regression options of the configured quantile. The intercept
+// is expected as the first feature column.
+func (o *Options) NewQuantileOptions() *models.QuantileOptions {
+	quantileOpt := models.NewDefaultQuantileOptions()
+	if o.Quantile != 0 {
+		quantileOpt.Quantile = o.Quantile
+	}
+	if o.Iterations != 0 {
+		quantileOpt.Iterations = o.Iterations
+	}
+	if o.Tolerance != 0 {
+		quantileOpt.Tolerance = o.Tolerance
+	}
+	quantileOpt.FitIntercept = false
+	return quantileOpt
+}
+
+// NewHuberOptions returns the huber regression options of the configured delta. The intercept is
+// expected as the first feature column.
+func (o *Options) NewHuberOptions() *models.HuberOptions {
+	huberOpt := models.NewDefaultHuberOptions()
+	if o.HuberDelta != 0 {
+		huberOpt.Delta = o.HuberDelta
+	}
+	if o.Iterations != 0 {
+		huberOpt.Iterations = o.Iterations
+	}
+	if o.Tolerance != 0 {
+		huberOpt.Tolerance = o.Tolerance
+	}
+	huberOpt.FitIntercept = false
+	return huberOpt
+}
+
+// NewRegressionModel initializes the configured built in or registered regression backend ready for
+// fitting
+func (o *Options) NewRegressionModel() (models.Regressor, error) {
+	switch o.Regression {
+	case "", RegressionLasso:
+		return models.NewLassoAutoRegression(o.NewLassoAutoOptions())
+	case RegressionRidge:
+		return models.NewRidgeAutoRegression(o.NewRidgeAutoOptions())
+	case RegressionElasticNet:
+		return models.NewElasticNetAutoRegression(o.NewElasticNetAutoOptions())
+	case RegressionQuantile:
+		return models.NewQuantileRegression(o.NewQuantileOptions())
+	case RegressionHuber:
+		return models.NewHuberRegression(o.NewHuberOptions())
+	}
+
+	regressorsMu.RLock()
+	factory, exists := regressors[string(o.Regression)]
+	regressorsMu.RUnlock()
+	if !exists {
+		return nil, fmt.Errorf("%q, %w", o.Regression, ErrUnknownRegression)
+	}
+	model, err := factory(o)
+	if err != nil {
+		return nil, fmt.Errorf("unable to initialize regressor %q, %w", o.Regression, err)
+	}
+	if model == nil {
+		return nil, fmt.Errorf("%q, %w", o.Regression, ErrNilRegressorFactory)
+	}
+	return model, nil
+}
@@ -0,0 +1,519 @@
+package options
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/errs"
+)
+
+var (
+	ErrInvalidRecurrenceSpec  = errs.NewConfigError(errs.CodeInvalidEvent, "invalid recurring event spec", nil)
+	ErrNonPositiveEventLength = errs.NewConfigError(errs.CodeInvalidEvent, "scheduled event duration must be positive", nil)
+	ErrScheduleWithRecurrence = errs.NewConfigError(errs.CodeInvalidEvent, "event cannot set both a schedule and a recurrence", nil)
+)
+
+// NewScheduledEvent creates an event lasting the duration at every occurrence of the RRULE or cron
+// spec starting from the start time
+func NewScheduledEvent(name, spec string, start time.Time, duration time.Duration) Event {
+	return Event{
+		Name:     name,
+		Start:    start,
+		End:      start.Add(duration),
+		Schedule: spec,
+	}
+}
+
+// validSchedule returns an error if the scheduled event has no duration, also sets a recurrence, or
+// the spec cannot be parsed
+func (e Event) validSchedule() error {
+	if !e.End.After(e.Start) {
+		return ErrNonPositiveEventLength
+	}
+	if e.Recurrence != 0 {
+		return ErrScheduleWithRecurrence
+	}
+	evStart, _ := e.bounds()
+	_, err := parseSchedule(e.Schedule, evStart)
+	return err
+}
+
+// scheduledOccurrences returns the occurrences of the event at every start time of its schedule
+// overlapping the input time range. Occurrences starting after Until are only included if expand is
+// set.
+func (e Event) scheduledOccurrences(start, end time.Time, expand bool) []Event {
+	evStart, evEnd := e.bounds()
+	sched, err := parseSchedule(e.Schedule, evStart)
+	if err != nil {
+		return nil
+	}
+	duration := evEnd.Sub(evStart)
+
+	until := e.until()
+	var occs []Event
+	for _, s := range sched.starts(evStart, start.Add(-duration), end) {
+		if !expand && !until.IsZero() && s.After(until) {
+			break
+		}
+		occEnd := s.Add(duration)
+		if occEnd.Before(start) {
+			continue
+		}
+		occs = append(occs, Event{Name: e.Name, Start: s, End: occEnd, Magnitude: e.Magnitude, Intensity: e.Intensity})
+	}
+	return occs
+}
+
+// schedule generates the occurrence start times of a recurrence spec
+type schedule interface {
+	// starts returns the occurrence start times within [from, to] in order that are not before the
+	// anchor time
+	starts(anchor, from, to time.Time) []time.Time
+}
+
+// parseSchedule parses the spec as an RRULE if it sets FREQ and as a cron expression otherwise
+func parseSchedule(spec string, anchor time.Time) (schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, fmt.Errorf("empty spec, %w", ErrInvalidRecurrenceSpec)
+	}
+	upper := strings.ToUpper(spec)
+	if strings.HasPrefix(upper, "RRULE:") || strings.Contains(upper, "FREQ=") {
+		return parseRRule(strings.TrimPrefix(upper, "RRULE:"), anchor)
+	}
+	return parseCron(upper)
+}
+
+// daySchedule generates start times by matching each calendar day from the anchor and emitting the
+// configured times of day on matching days
+type daySchedule struct {
+	matchDay func(day time.Time) bool
+	hours    []int
+	minutes  []int
+	second   int
+
+	// count stops after the first count occurrences from the anchor if non-zero
+	count int
+	until time.Time
+}
+
+func (d daySchedule) starts(anchor, from, to time.Time) []time.Time {
+	loc := anchor.Location()
+	if !d.until.IsZero() && d.until.Before(to) {
+		to = d.until
+	}
+
+	first := anchor
+	if d.count == 0 && from.After(first) {
+		first = from
+	}
+	first = first.In(loc)
+
+	var res []time.Time
+	var numOccs int
+	for day := time.Date(first.Year(), first.Month(), first.Day(), 0, 0, 0, 0, loc); !day.After(to); day = day.AddDate(0, 0, 1) {
+		if !d.matchDay(day) {
+			continue
+		}
+		for _, h := range d.hours {
+			for _, m := range d.minutes {
+				s := time.Date(day.Year(), day.Month(), day.Day(), h, m, d.second, 0, loc)
+				if s.Before(anchor) {
+					continue
+				}
+				if s.After(to) {
+					return res
+				}
+				numOccs++
+				if d.count > 0 && numOccs > d.count {
+					return res
+				}
+				if !s.Before(from) {
+					res = append(res, s)
+				}
+			}
+		}
+	}
+	return res
+}
+
+// weekday ordinals of RRULE BYDAY parts e.g. 1MO is the first Monday and -1FR is the last Friday
+type byDay struct {
+	ordinal int
+	weekday time.Weekday
+}
+
+var rruleWeekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+func parseRRule(spec string, anchor time.Time) (schedule, error) {
+	var freq string
+	interval := 1
+	wkst := time.Monday
+	sched := daySchedule{second: anchor.Second()}
+	var months, monthDays []int
+	var days []byDay
+
+	for _, part := range strings.Split(strings.Trim(spec, ";"), ";") {
+		key, val, found := strings.Cut(part, "=")
+		if !found || val == "" {
+			return nil, fmt.Errorf("rule part %q, %w", part, ErrInvalidRecurrenceSpec)
+		}
+		var err error
+		switch key {
+		case "FREQ":
+			freq = val
+		case "INTERVAL":
+			interval, err = strconv.Atoi(val)
+			if err == nil && interval < 1 {
+				err = fmt.Errorf("interval of %d", interval)
+			}
+		case "COUNT":
+			sched.count, err = strconv.Atoi(val)
+			if err == nil && sched.count < 1 {
+				err = fmt.Errorf("count of %d", sched.count)
+			}
+		case "UNTIL":
+			sched.until, err = parseRRuleTime(val, anchor.Location())
+		case "WKST":
+			var exists bool
+			if wkst, exists = rruleWeekdays[val]; !exists {
+				err = fmt.Errorf("week start %q", val)
+			}
+		case "BYMONTH":
+			months, err = parseIntList(val, 1, 12, false)
+		case "BYMONTHDAY":
+			monthDays, err = parseIntList(val, 1, 31, true)
+		case "BYHOUR":
+			sched.hours, err = parseIntList(val, 0, 23, false)
+		case "BYMINUTE":
+			sched.minutes, err = parseIntList(val, 0, 59, false)
+		case "BYDAY":
+			days, err = parseByDay(val)
+		default:
+			err = fmt.Errorf("unsupported rule part %q", key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s, %w", err.Error(), ErrInvalidRecurrenceSpec)
+		}
+	}
+
+	if sched.hours == nil {
+		sched.hours = []int{anchor.Hour()}
+	}
+	if sched.minutes == nil {
+		sched.minutes = []int{anchor.Minute()}
+	}
+
+	anchorDay := civilDay(anchor)
+	var period func(day time.Time) int
+	switch freq {
+	case "DAILY":
+		period = func(day time.Time) int { return daysBetween(anchorDay, day) }
+	case "WEEKLY":
+		weekStart := func(day time.Time) time.Time {
+			return day.AddDate(0, 0, -((int(day.Weekday()) - int(wkst) + 7) % 7))
+		}
+		anchorWeek := weekStart(anchorDay)
+		period = func(day time.Time) int { return daysBetween(anchorWeek, weekStart(day)) / 7 }
+		if days == nil {
+			days = []byDay{{weekday: anchor.Weekday()}}
+		}
+	case "MONTHLY":
+		period = func(day time.Time) int {
+			return (day.Year()-anchor.Year())*12 + int(day.Month()) - int(anchor.Month())
+		}
+		if days == nil && monthDays == nil {
+			monthDays = []int{anchor.Day()}
+		}
+	case "YEARLY":
+		period = func(day time.Time) int { return day.Year() - anchor.Year() }
+		if monthDays == nil && days == nil {
+			monthDays = []int{anchor.Day()}
+		}
+		if months == nil && monthDays != nil {
+			months = []int{int(anchor.Month())}
+		}
+	default:
+		return nil, fmt.Errorf("frequency %q, %w", freq, ErrInvalidRecurrenceSpec)
+	}
+
+	ordinalInYear := freq == "YEARLY" && months == nil
+	for _, bd := range days {
+		if bd.ordinal != 0 && (freq == "DAILY" || freq == "WEEKLY") {
+			return nil, fmt.Errorf("ordinal weekday for %s frequency, %w", freq, ErrInvalidRecurrenceSpec)
+		}
+	}
+
+	sched.matchDay = func(day time.Time) bool {
+		if period(day)%interval != 0 {
+			return false
+		}
+		if months != nil && !containsInt(months, int(day.Month())) {
+			return false
+		}
+		if monthDays != nil && !matchMonthDay(day, monthDays) {
+			return false
+		}
+		if days != nil && !matchByDay(day, days, ordinalInYear) {
+			return false
+		}
+		return true
+	}
+	return sched, nil
+}
+
+// parseRRuleTime parses an RRULE UNTIL date or date time which is UTC if suffixed with Z and in the
+// input location otherwise
+func parseRRuleTime(val string, loc *time.Location) (time.Time, error) {
+	if strings.HasSuffix(val, "Z") {
+		return time.Parse("20060102T150405Z", val)
+	}
+	if strings.Contains(val, "T") {
+		return time.ParseInLocation("20060102T150405", val, loc)
+	}
+	day, err := time.ParseInLocation("20060102", val, loc)
+	if err != nil {
+		return day, err
+	}
+	// a date bounds the whole day
+	return day.AddDate(0, 0, 1).Add(-time.Nanosecond), nil
+}
+
+func parseByDay(val string) ([]byDay, error) {
+	var res []byDay
+	for _, item := range strings.Split(val, ",") {
+		if len(item) < 2 {
+			return nil, fmt.Errorf("weekday %q", item)
+		}
+		wd, exists := rruleWeekdays[item[len(item)-2:]]
+		if !exists {
+			return nil, fmt.Errorf("weekday %q", item)
+		}
+		bd := byDay{weekday: wd}
+		if ord := item[:len(item)-2]; ord != "" {
+			n, err := strconv.Atoi(ord)
+			if err != nil || n == 0 || n < -53 || n > 53 {
+				return nil, fmt.Errorf("weekday ordinal %q", item)
+			}
+			bd.ordinal = n
+		}
+		res = append(res, bd)
+	}
+	return res, nil
+}
+
+// parseIntList parses a comma separated list of integers within [lo, hi] also allowing the negation of
+// the range if negative is set
+func parseIntList(val string, lo, hi int, negative bool) ([]int, error) {
+	var res []int
+	for _, item := range strings.Split(val, ",") {
+		n, err := strconv.Atoi(item)
+		if err != nil {
+			return nil, err
+		}
+		abs := n
+		if negative && n < 0 {
+			abs = -n
+		}
+		if abs < lo || abs > hi {
+			return nil, fmt.Errorf("value %d out of range [%d, %d]", n, lo, hi)
+		}
+		res = append(res, n)
+	}
+	sort.Ints(res)
+	return res, nil
+}
+
+func containsInt(vals []int, v int) bool {
+	for _, val := range vals {
+		if val == v {
+			return true
+		}
+	}
+	return false
+}
+
+// civilDay returns midnight UTC of the calendar date of the time to count days without DST shifts
+func civilDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func daysBetween(from, to time.Time) int {
+	return int(civilDay(to).Sub(civilDay(from)) / (24 * time.Hour))
+}
+
+func daysInMonth(day time.Time) int {
+	return time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// matchMonthDay returns true if the day of month matches any of the month days where negative values
+// count back from the end of the month
+func matchMonthDay(day time.Time, monthDays []int) bool {
+	numDays := daysInMonth(day)
+	for _, md := range monthDays {
+		if md == day.Day() || (md < 0 && numDays+md+1 == day.Day()) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchByDay returns true if the weekday of the day matches any of the weekdays and their ordinal
+// within the month or within the year if ordinalInYear is set
+func matchByDay(day time.Time, days []byDay, ordinalInYear bool) bool {
+	pos, numDays := day.Day(), daysInMonth(day)
+	if ordinalInYear {
+		pos = day.YearDay()
+		numDays = time.Date(day.Year(), 12, 31, 0, 0, 0, 0, time.UTC).YearDay()
+	}
+	for _, bd := range days {
+		if bd.weekday != day.Weekday() {
+			continue
+		}
+		switch {
+		case bd.ordinal == 0:
+			return true
+		case bd.ordinal > 0 && (pos-1)/7+1 == bd.ordinal:
+			return true
+		case bd.ordinal < 0 && (numDays-pos)/7+1 == -bd.ordinal:
+			return true
+		}
+	}
+	return false
+}
+
+var cronMacros = map[string]string{
+	"@HOURLY":   "0 * * * *",
+	"@DAILY":    "0 0 * * *",
+	"@WEEKLY":   "0 0 * * 0",
+	"@MONTHLY":  "0 0 1 * *",
+	"@YEARLY":   "0 0 1 1 *",
+	"@ANNUALLY": "0 0 1 1 *",
+}
+
+var (
+	cronMonths   = []string{"JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}
+	cronWeekdays = []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}
+)
+
+func parseCron(spec string) (schedule, error) {
+	if macro, exists := cronMacros[spec]; exists {
+		spec = macro
+	}
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron spec %q has %d fields instead of 5, %w", spec, len(fields), ErrInvalidRecurrenceSpec)
+	}
+
+	minutes, _, err := parseCronField(fields[0], 0, 59, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	hours, _, err := parseCronField(fields[1], 0, 23, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	monthDays, domAny, err := parseCronField(fields[2], 1, 31, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	months, _, err := parseCronField(fields[3], 1, 12, cronMonths, 1)
+	if err != nil {
+		return nil, err
+	}
+	weekdays, dowAny, err := parseCronField(fields[4], 0, 7, cronWeekdays, 0)
+	if err != nil {
+		return nil, err
+	}
+	// 7 is an alias of Sunday
+	if containsInt(weekdays, 7) && !containsInt(weekdays, 0) {
+		weekdays = append(weekdays, 0)
+	}
+
+	return daySchedule{
+		matchDay: func(day time.Time) bool {
+			if !containsInt(months, int(day.Month())) {
+				return false
+			}
+			domMatch := containsInt(monthDays, day.Day())
+			dowMatch := containsInt(weekdays, int(day.Weekday()))
+			if !domAny && !dowAny {
+				return domMatch || dowMatch
+			}
+			return domMatch && dowMatch
+		},
+		hours:   hours,
+		minutes: minutes,
+	}, nil
+}
+
+// parseCronField parses a cron field of lists, ranges, and steps into the sorted matching values and
+// whether the field is unrestricted. Names are matched case insensitively starting from nameBase.
+func parseCronField(field string, lo, hi int, names []string, nameBase int) ([]int, bool, error) {
+	parseVal := func(s string) (int, error) {
+		for i, name := range names {
+			if s == name {
+				return i + nameBase, nil
+			}
+		}
+		v, err := strconv.Atoi(s)
+		if err != nil || v < lo || v > hi {
+			return 0, fmt.Errorf("cron value %q out of range [%d, %d], %w", s, lo, hi, ErrInvalidRecurrenceSpec)
+		}
+		return v, nil
+	}
+
+	set := make(map[int]struct{})
+	for _, item := range strings.Split(field, ",") {
+		rng, stepStr, hasStep := strings.Cut(item, "/")
+		step := 1
+		if hasStep {
+			var err error
+			step, err = strconv.Atoi(stepStr)
+			if err != nil || step < 1 {
+				return nil, false, fmt.Errorf("cron step %q, %w", stepStr, ErrInvalidRecurrenceSpec)
+			}
+		}
+
+		first, last := lo, hi
+		if rng != "*" {
+			startStr, endStr, isRange := strings.Cut(rng, "-")
+			var err error
+			if first, err = parseVal(startStr); err != nil {
+				return nil, false, err
+			}
+			last = first
+			if isRange {
+				if last, err = parseVal(endStr); err != nil {
+					return nil, false, err
+				}
+			} else if hasStep {
+				last = hi
+			}
+			if first > last {
+				return nil, false, fmt.Errorf("cron range %q, %w", rng, ErrInvalidRecurrenceSpec)
+			}
+		}
+		for v := first; v <= last; v += step {
+			set[v] = struct{}{}
+		}
+	}
+
+	res := make([]int, 0, len(set))
+	for v := range set {
+		res = append(res, v)
+	}
+	sort.Ints(res)
+	return res, field == "*", nil
+}
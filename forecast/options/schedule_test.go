@@ -0,0 +1,252 @@
+package options
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/feature"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduledEventValid(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	testData := map[string]struct {
+		ev  Event
+		err error
+	}{
+		"rrule":           {NewScheduledEvent("ev", "RRULE:FREQ=MONTHLY;BYDAY=1MO;BYHOUR=9", start, time.Hour), nil},
+		"cron":            {NewScheduledEvent("ev", "30 9 * * mon-fri", start, time.Hour), nil},
+		"cron macro":      {NewScheduledEvent("ev", "@weekly", start, time.Hour), nil},
+		"no name":         {NewScheduledEvent("", "@daily", start, time.Hour), ErrNoEventName},
+		"no start":        {NewScheduledEvent("ev", "@daily", time.Time{}, time.Hour), ErrUnsetTime},
+		"no duration":     {NewScheduledEvent("ev", "@daily", start, 0), ErrNonPositiveEventLength},
+		"blank spec":      {NewScheduledEvent("ev", " ", start, time.Hour), ErrInvalidRecurrenceSpec},
+		"unknown freq":    {NewScheduledEvent("ev", "FREQ=SECONDLY", start, time.Hour), ErrInvalidRecurrenceSpec},
+		"unknown part":    {NewScheduledEvent("ev", "FREQ=DAILY;BYSETPOS=1", start, time.Hour), ErrInvalidRecurrenceSpec},
+		"weekly ordinal":  {NewScheduledEvent("ev", "FREQ=WEEKLY;BYDAY=1MO", start, time.Hour), ErrInvalidRecurrenceSpec},
+		"zero interval":   {NewScheduledEvent("ev", "FREQ=DAILY;INTERVAL=0", start, time.Hour), ErrInvalidRecurrenceSpec},
+		"cron fields":     {NewScheduledEvent("ev", "0 9 * *", start, time.Hour), ErrInvalidRecurrenceSpec},
+		"cron range":      {NewScheduledEvent("ev", "0 25 * * *", start, time.Hour), ErrInvalidRecurrenceSpec},
+		"cron name range": {NewScheduledEvent("ev", "0 9 * * FRI-MON", start, time.Hour), ErrInvalidRecurrenceSpec},
+		"with recurrence": {
+			Event{Name: "ev", Start: start, End: start.Add(time.Hour), Recurrence: 24 * time.Hour, Schedule: "@daily"},
+			ErrScheduleWithRecurrence,
+		},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			assert.ErrorIs(t, td.ev.Valid(), td.err)
+		})
+	}
+}
+
+func TestScheduledEventOccurrences(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	require.Nil(t, err)
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, loc)
+	testData := map[string]struct {
+		spec       string
+		duration   time.Duration
+		rangeStart time.Time
+		rangeEnd   time.Time
+		expected   []time.Time
+	}{
+		"first monday of every month": {
+			spec:       "FREQ=MONTHLY;BYDAY=1MO;BYHOUR=9",
+			duration:   6 * time.Hour,
+			rangeStart: start,
+			rangeEnd:   time.Date(2024, 4, 30, 0, 0, 0, 0, loc),
+			expected: []time.Time{
+				time.Date(2024, 1, 1, 9, 0, 0, 0, loc),
+				time.Date(2024, 2, 5, 9, 0, 0, 0, loc),
+				time.Date(2024, 3, 4, 9, 0, 0, 0, loc),
+				time.Date(2024, 4, 1, 9, 0, 0, 0, loc),
+			},
+		},
+		"last friday of the quarter": {
+			spec:       "FREQ=MONTHLY;INTERVAL=3;BYDAY=-1FR;BYHOUR=17;BYMINUTE=30",
+			duration:   time.Hour,
+			rangeStart: start,
+			rangeEnd:   time.Date(2024, 12, 31, 0, 0, 0, 0, loc),
+			expected: []time.Time{
+				time.Date(2024, 1, 26, 17, 30, 0, 0, loc),
+				time.Date(2024, 4, 26, 17, 30, 0, 0, loc),
+				time.Date(2024, 7, 26, 17, 30, 0, 0, loc),
+				time.Date(2024, 10, 25, 17, 30, 0, 0, loc),
+			},
+		},
+		"biweekly with count": {
+			spec:       "FREQ=WEEKLY;INTERVAL=2;BYDAY=TU,TH;COUNT=3",
+			duration:   time.Hour,
+			rangeStart: time.Date(2024, 1, 4, 0, 0, 0, 0, loc),
+			rangeEnd:   time.Date(2024, 3, 1, 0, 0, 0, 0, loc),
+			expected: []time.Time{
+				time.Date(2024, 1, 4, 0, 0, 0, 0, loc),
+				time.Date(2024, 1, 16, 0, 0, 0, 0, loc),
+			},
+		},
+		"last day of month until": {
+			spec:       "FREQ=MONTHLY;BYMONTHDAY=-1;UNTIL=20240331",
+			duration:   24 * time.Hour,
+			rangeStart: start,
+			rangeEnd:   time.Date(2024, 12, 31, 0, 0, 0, 0, loc),
+			expected: []time.Time{
+				time.Date(2024, 1, 31, 0, 0, 0, 0, loc),
+				time.Date(2024, 2, 29, 0, 0, 0, 0, loc),
+				time.Date(2024, 3, 31, 0, 0, 0, 0, loc),
+			},
+		},
+		"yearly thanksgiving": {
+			spec:       "FREQ=YEARLY;BYMONTH=11;BYDAY=4TH",
+			duration:   24 * time.Hour,
+			rangeStart: start,
+			rangeEnd:   time.Date(2025, 12, 31, 0, 0, 0, 0, loc),
+			expected: []time.Time{
+				time.Date(2024, 11, 28, 0, 0, 0, 0, loc),
+				time.Date(2025, 11, 27, 0, 0, 0, 0, loc),
+			},
+		},
+		"overlapping range start": {
+			spec:       "FREQ=DAILY;BYHOUR=22",
+			duration:   4 * time.Hour,
+			rangeStart: time.Date(2024, 3, 10, 1, 0, 0, 0, loc),
+			rangeEnd:   time.Date(2024, 3, 11, 12, 0, 0, 0, loc),
+			expected: []time.Time{
+				time.Date(2024, 3, 9, 22, 0, 0, 0, loc),
+				time.Date(2024, 3, 10, 22, 0, 0, 0, loc),
+			},
+		},
+		"cron weekdays": {
+			spec:       "0 9,17 * * MON-FRI",
+			duration:   time.Hour,
+			rangeStart: time.Date(2024, 1, 5, 0, 0, 0, 0, loc),
+			rangeEnd:   time.Date(2024, 1, 8, 12, 0, 0, 0, loc),
+			expected: []time.Time{
+				time.Date(2024, 1, 5, 9, 0, 0, 0, loc),
+				time.Date(2024, 1, 5, 17, 0, 0, 0, loc),
+				time.Date(2024, 1, 8, 9, 0, 0, 0, loc),
+			},
+		},
+		"cron day of month or weekday": {
+			spec:       "0 0 1,15 * SUN",
+			duration:   time.Hour,
+			rangeStart: start,
+			rangeEnd:   time.Date(2024, 1, 16, 0, 0, 0, 0, loc),
+			expected: []time.Time{
+				time.Date(2024, 1, 1, 0, 0, 0, 0, loc),
+				time.Date(2024, 1, 7, 0, 0, 0, 0, loc),
+				time.Date(2024, 1, 14, 0, 0, 0, 0, loc),
+				time.Date(2024, 1, 15, 0, 0, 0, 0, loc),
+			},
+		},
+		"cron step": {
+			spec:       "*/20 6 * * *",
+			duration:   10 * time.Minute,
+			rangeStart: time.Date(2024, 2, 1, 0, 0, 0, 0, loc),
+			rangeEnd:   time.Date(2024, 2, 1, 12, 0, 0, 0, loc),
+			expected: []time.Time{
+				time.Date(2024, 2, 1, 6, 0, 0, 0, loc),
+				time.Date(2024, 2, 1, 6, 20, 0, 0, loc),
+				time.Date(2024, 2, 1, 6, 40, 0, 0, loc),
+			},
+		},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			ev := NewScheduledEvent("ev", td.spec, start, td.duration)
+			require.Nil(t, ev.Valid())
+			occs := ev.occurrences(td.rangeStart, td.rangeEnd, false)
+
+			starts := make([]time.Time, 0, len(occs))
+			for _, occ := range occs {
+				assert.Equal(t, td.duration, occ.End.Sub(occ.Start))
+				starts = append(starts, occ.Start)
+			}
+			assert.Equal(t, td.expected, starts)
+		})
+	}
+}
+
+func TestScheduledEventMask(t *testing.T) {
+	tStart := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tWin := make([]time.Time, 60*24)
+	for i := range tWin {
+		tWin[i] = tStart.Add(time.Duration(i) * time.Hour)
+	}
+
+	opt := EventOptions{
+		Events: []Event{
+			NewScheduledEvent("monthly_report", "FREQ=MONTHLY;BYDAY=1MO;BYHOUR=9", tStart, 6*time.Hour),
+			NewScheduledEvent("invalid", "FREQ=HOURLY", tStart, time.Hour),
+		},
+	}
+	eFeat := feature.NewSet()
+	opt.generateEventMask(tWin, time.Hour, eFeat, WindowFunc(""))
+
+	_, exists := eFeat.Get(feature.NewEvent("invalid"))
+	assert.False(t, exists)
+
+	mask, exists := eFeat.Get(feature.NewEvent("monthly_report"))
+	require.True(t, exists)
+	var total float64
+	for i, tPnt := range tWin {
+		expected := 0.0
+		if tPnt.Weekday() == time.Monday && tPnt.Day() <= 7 && tPnt.Hour() >= 9 && tPnt.Hour() < 15 {
+			expected = 1.0
+		}
+		assert.Equal(t, expected, mask[i], "time %s", tPnt)
+		total += mask[i]
+	}
+	assert.Equal(t, 12.0, total)
+}
+
+func TestScheduledEventLagUntil(t *testing.T) {
+	tStart := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tWin := make([]time.Time, 28*24)
+	for i := range tWin {
+		tWin[i] = tStart.Add(time.Duration(i) * time.Hour)
+	}
+
+	ev := NewScheduledEvent("standup", "0 9 * * MON", tStart, time.Hour)
+	ev.Lags = []time.Duration{2 * time.Hour}
+	ev.Until = tStart.Add(14 * 24 * time.Hour)
+
+	testData := map[string]struct {
+		autoExpand bool
+		expected   float64
+	}{
+		"until":       {false, 2},
+		"auto expand": {true, 4},
+	}
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			opt := EventOptions{Events: []Event{ev}, AutoExpand: td.autoExpand}
+			eFeat := feature.NewSet()
+			opt.generateEventMask(tWin, time.Hour, eFeat, WindowFunc(""))
+
+			mask, exists := eFeat.Get(feature.NewEvent("standup"))
+			require.True(t, exists)
+			lagMask, exists := eFeat.Get(feature.NewEvent(LaggedEventName("standup", 2*time.Hour)))
+			require.True(t, exists)
+
+			var total, lagTotal float64
+			for i, tPnt := range tWin {
+				if mask[i] > 0 {
+					assert.Equal(t, 9, tPnt.Hour())
+				}
+				if lagMask[i] > 0 {
+					assert.Equal(t, 11, tPnt.Hour())
+				}
+				total += mask[i]
+				lagTotal += lagMask[i]
+			}
+			assert.Equal(t, td.expected, total)
+			assert.Equal(t, td.expected, lagTotal)
+		})
+	}
+	assert.Equal(t, []string{"standup"}, EventOptions{Events: []Event{ev}}.UnexpandedEvents(tWin))
+}
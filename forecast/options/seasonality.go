@@ -4,22 +4,84 @@ import (
 	"fmt"
 	"io"
 	"sort"
+	"strconv"
 	"text/tabwriter"
 	"time"
 
+	"github.com/aouyang1/go-forecaster/feature"
 	"github.com/aouyang1/go-forecaster/forecast/util"
+	"gonum.org/v1/gonum/floats"
 )
 
-// Seasonality options configures the number of seasonality components to fit for.
+// LabelSeasTrend is appended to the name of seasonality features modulated by the trend
+const LabelSeasTrend = "trend"
+
+// Seasonality options configures the number of seasonality components to fit for. Setting
+// TrendInteraction adds the product of every fourier feature with a linear trend so the seasonal
+// amplitude can grow or shrink with the level without a fully multiplicative model. Detect adds
+// seasonality configs at periodicities detected in the training data. Auto also detects the dominant
+// periods including daily, weekly, and yearly cycles and picks the fourier orders of each from its
+// significant harmonics using the tuning of Detect, so SeasonalityConfigs can be left empty. The periods
+// added by Detect or Auto are reported in Detected. Monthly adds a calendar aware monthly seasonality
+// keyed on the fraction of the month elapsed.
 type SeasonalityOptions struct {
-	SeasonalityConfigs []SeasonalityConfig `json:"seasonality_configs"`
+	SeasonalityConfigs []SeasonalityConfig       `json:"seasonality_configs"`
+	TrendInteraction   bool                      `json:"trend_interaction"`
+	Detect             SeasonalityDetectOptions  `json:"detect"`
+	Auto               bool                      `json:"auto,omitempty"`
+	Detected           []DetectedSeasonality     `json:"detected,omitempty"`
+	Monthly            MonthlySeasonalityOptions `json:"monthly"`
+}
+
+// GenerateTrendInteractions multiplies the fourier features of each seasonality config by a linear
+// trend measured in periods of that seasonality from the anchor time. The coefficients of these
+// features are the change in seasonal amplitude per period and the amplitude at the anchor is given by
+// the unmodulated fourier features.
+func (s SeasonalityOptions) GenerateTrendInteractions(t []time.Time, seasFeat *feature.Set, anchor time.Time) *feature.Set {
+	x := feature.NewSet()
+	if !s.TrendInteraction || seasFeat == nil {
+		return x
+	}
+
+	for _, seasCfg := range s.SeasonalityConfigs {
+		period := seasCfg.Period.Seconds()
+		if period <= 0 {
+			continue
+		}
+		trend := make([]float64, len(t))
+		for i, tPnt := range t {
+			trend[i] = tPnt.Sub(anchor).Seconds() / period
+		}
+
+		name := SeasonalityFeatureName(seasCfg.Name)
+		for _, label := range seasFeat.Labels() {
+			if val, _ := label.Get("name"); val != name {
+				continue
+			}
+			data, exists := seasFeat.Get(label)
+			if !exists {
+				continue
+			}
+			modulated := make([]float64, len(data))
+			floats.MulTo(modulated, trend, data)
+
+			fcompStr, _ := label.Get("fourier_component")
+			orderStr, _ := label.Get("order")
+			order, _ := strconv.Atoi(orderStr)
+			featCol := feature.NewSeasonality(TrendInteractionFeatureName(seasCfg.Name), feature.FourierComp(fcompStr), order)
+			x.Set(featCol, modulated)
+		}
+	}
+	return x
 }
 
 func (s SeasonalityOptions) TablePrint(w io.Writer, prefix, indent string, indentGrowth int) error {
 	tbl := tabwriter.NewWriter(w, 0, 0, 1, ' ', tabwriter.AlignRight)
 	noCfg := " None"
-	if len(s.SeasonalityConfigs) > 0 {
+	if len(s.SeasonalityConfigs) > 0 || s.Monthly.Enabled() {
 		noCfg = ""
+	}
+	if len(s.SeasonalityConfigs) > 0 {
 		fmt.Fprintf(tbl, "%s%sName\tPeriod\tOrders\t\n", prefix, util.IndentExpand(indent, indentGrowth+1))
 	}
 	fmt.Fprintf(w, "%s%sSeasonality:%s\n", prefix, util.IndentExpand(indent, indentGrowth), noCfg)
@@ -28,7 +90,29 @@ func (s SeasonalityOptions) TablePrint(w io.Writer, prefix, indent string, inden
 			prefix, util.IndentExpand(indent, indentGrowth+1),
 			seasCfg.Name, seasCfg.Period, seasCfg.Orders)
 	}
-	return tbl.Flush()
+	if err := tbl.Flush(); err != nil {
+		return err
+	}
+	if len(s.Detected) > 0 {
+		fmt.Fprintf(w, "%s%sDetected:\n", prefix, util.IndentExpand(indent, indentGrowth+1))
+		fmt.Fprintf(tbl, "%s%sName\tPeriod\tOrders\tPower\t\n", prefix, util.IndentExpand(indent, indentGrowth+2))
+		for _, d := range s.Detected {
+			fmt.Fprintf(tbl, "%s%s%s\t%s\t%d\t%.1f\t\n",
+				prefix, util.IndentExpand(indent, indentGrowth+2),
+				d.Name, d.Period, d.Orders, d.Power)
+		}
+		if err := tbl.Flush(); err != nil {
+			return err
+		}
+	}
+	if s.Monthly.Enabled() {
+		tz := s.Monthly.Timezone
+		if tz == "" {
+			tz = time.UTC.String()
+		}
+		fmt.Fprintf(w, "%s%sMonthly: %d orders in %s\n", prefix, util.IndentExpand(indent, indentGrowth+1), s.Monthly.Orders, tz)
+	}
+	return nil
 }
 
 // NewDefaultSeasonalityOptions generates a default seasonality config with weekly and daily
@@ -82,11 +166,15 @@ func (s *SeasonalityOptions) removeDuplicates() {
 // SeasonalityConfig represents a single seasonality configuration to model. This will generate
 // Fourier series of the specified period and number of orders. E.g. a period of 24*time.Hour
 // with 3 orders will create 6 Fourier series of order 1, 2, 3 and for the sine/cosine components
-// where order 1 will have a period of 1 day and order 2 will have a period of 12 hours.
+// where order 1 will have a period of 1 day and order 2 will have a period of 12 hours. Setting
+// OrderCriterion selects the orders from the training data on every fit, trying up to MaxOrders, and
+// stores the selection in Orders.
 type SeasonalityConfig struct {
-	Name   string        `json:"name"`
-	Orders int           `json:"orders"`
-	Period time.Duration `json:"period"`
+	Name           string         `json:"name"`
+	Orders         int            `json:"orders"`
+	Period         time.Duration  `json:"period"`
+	OrderCriterion OrderCriterion `json:"order_criterion,omitempty"`
+	MaxOrders      int            `json:"max_orders,omitempty"`
 }
 
 // NewDailySeasonalityConfig creates a daily seasonality config given a specified number of orders
@@ -114,3 +202,21 @@ func NewWeeklySeasonalityConfig(orders int) SeasonalityConfig {
 		Period: 7 * 24 * time.Hour,
 	}
 }
+
+// YearlyPeriod is the mean length of a year in the Gregorian calendar. Using 365.25 days keeps the
+// yearly phase aligned across leap years and avoids the 365th order coinciding with daily seasonality.
+const YearlyPeriod = time.Duration(36525 * 24 * time.Hour / 100)
+
+// NewYearlySeasonalityConfig creates a yearly seasonality config given a specified number of orders.
+// The yearly phase is computed from the time before any DST adjustment.
+func NewYearlySeasonalityConfig(orders int) SeasonalityConfig {
+	if orders < 0 {
+		orders = 0
+	}
+
+	return SeasonalityConfig{
+		Name:   LabelSeasYearly,
+		Orders: orders,
+		Period: YearlyPeriod,
+	}
+}
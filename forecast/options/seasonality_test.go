@@ -2,10 +2,15 @@ package options
 
 import (
 	"bytes"
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestSeasonalityTablePrint(t *testing.T) {
@@ -41,6 +46,32 @@ func TestSeasonalityTablePrint(t *testing.T) {
 			expected: `    Seasonality:
        Name  Period Orders
          s0 12h0m0s      1
+`,
+		},
+		"detected": {
+			opt: &SeasonalityOptions{
+				SeasonalityConfigs: []SeasonalityConfig{
+					{Name: "daily", Period: 24 * time.Hour, Orders: 2},
+				},
+				Auto: true,
+				Detected: []DetectedSeasonality{
+					{Name: "daily", Period: 24 * time.Hour, Orders: 2, Power: 1234.56},
+				},
+			},
+			expected: `Seasonality:
+  Name  Period Orders
+ daily 24h0m0s      2
+Detected:
+  Name  Period Orders  Power
+ daily 24h0m0s      2 1234.6
+`,
+		},
+		"monthly only": {
+			opt: &SeasonalityOptions{
+				Monthly: MonthlySeasonalityOptions{Orders: 3},
+			},
+			expected: `Seasonality:
+Monthly: 3 orders in UTC
 `,
 		},
 	}
@@ -116,3 +147,164 @@ func TestRemoveDuplicates(t *testing.T) {
 		})
 	}
 }
+
+func TestDetectSeasonality(t *testing.T) {
+	n := 3 * 24 * 6
+	ct := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tWin := make([]time.Time, 0, n)
+	for i := 0; i < n; i++ {
+		tWin = append(tWin, ct.Add(time.Duration(i)*10*time.Minute))
+	}
+	y := make([]float64, n)
+	for i, tPnt := range tWin {
+		sec := tPnt.Sub(ct).Seconds()
+		y[i] = 5.0 + 0.001*float64(i) +
+			3.0*math.Sin(2.0*math.Pi*sec/86400.0) +
+			1.0*math.Sin(2.0*math.Pi*sec/5400.0)
+	}
+
+	testData := map[string]struct {
+		opt        SeasonalityOptions
+		configured []string
+		detected   []time.Duration
+	}{
+		"disabled": {
+			opt: SeasonalityOptions{
+				SeasonalityConfigs: []SeasonalityConfig{NewDailySeasonalityConfig(2)},
+			},
+			configured: []string{LabelSeasDaily},
+		},
+		"daily configured": {
+			opt: SeasonalityOptions{
+				SeasonalityConfigs: []SeasonalityConfig{NewDailySeasonalityConfig(2)},
+				Detect:             SeasonalityDetectOptions{Enabled: true},
+			},
+			configured: []string{LabelSeasDaily},
+			detected:   []time.Duration{90 * time.Minute},
+		},
+		"nothing configured": {
+			opt: SeasonalityOptions{
+				Detect: SeasonalityDetectOptions{Enabled: true, TopK: 1},
+			},
+			detected: []time.Duration{24 * time.Hour},
+		},
+		"replaces previous detection": {
+			opt: SeasonalityOptions{
+				SeasonalityConfigs: []SeasonalityConfig{
+					NewDailySeasonalityConfig(2),
+					{Name: "detected_2h0m0s", Orders: 1, Period: 2 * time.Hour},
+				},
+				Detect: SeasonalityDetectOptions{Enabled: true, MaxPeriod: time.Hour},
+			},
+			configured: []string{LabelSeasDaily},
+		},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			opt := td.opt
+			require.Nil(t, opt.DetectSeasonality(tWin, y))
+
+			var configured []string
+			var detected []time.Duration
+			for _, seasCfg := range opt.SeasonalityConfigs {
+				if strings.HasPrefix(seasCfg.Name, LabelSeasDetected+"_") {
+					detected = append(detected, seasCfg.Period)
+					continue
+				}
+				configured = append(configured, seasCfg.Name)
+			}
+			assert.Equal(t, td.configured, configured)
+			require.Len(t, detected, len(td.detected))
+			for i, period := range td.detected {
+				assert.InEpsilon(t, period.Seconds(), detected[i].Seconds(), 0.05)
+			}
+		})
+	}
+}
+
+func TestAutoSeasonality(t *testing.T) {
+	n := 2 * 7 * 24 * 6
+	ct := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tWin := make([]time.Time, 0, n)
+	for i := 0; i < n; i++ {
+		tWin = append(tWin, ct.Add(time.Duration(i)*10*time.Minute))
+	}
+	rng := rand.New(rand.NewSource(5))
+	y := make([]float64, n)
+	for i, tPnt := range tWin {
+		sec := tPnt.Sub(ct).Seconds()
+		y[i] = 5.0 + 0.001*float64(i) +
+			3.0*math.Sin(2.0*math.Pi*sec/86400.0) +
+			1.5*math.Cos(4.0*math.Pi*sec/86400.0) +
+			1.0*math.Sin(2.0*math.Pi*sec/18000.0) +
+			1.0*math.Sin(2.0*math.Pi*sec/5400.0) +
+			0.5*rng.NormFloat64()
+	}
+
+	testData := map[string]struct {
+		opt        SeasonalityOptions
+		configured []string
+		detected   []DetectedSeasonality
+	}{
+		"nothing configured": {
+			opt: SeasonalityOptions{Auto: true},
+			detected: []DetectedSeasonality{
+				{Name: LabelSeasDaily, Period: 24 * time.Hour, Orders: 2},
+				{Name: LabelSeasDetected, Period: 90 * time.Minute, Orders: 1},
+				{Name: LabelSeasDetected, Period: 5 * time.Hour, Orders: 1},
+			},
+		},
+		"daily configured": {
+			opt: SeasonalityOptions{
+				SeasonalityConfigs: []SeasonalityConfig{NewDailySeasonalityConfig(2)},
+				Auto:               true,
+				Detect:             SeasonalityDetectOptions{Threshold: 20},
+			},
+			configured: []string{LabelSeasDaily},
+			detected: []DetectedSeasonality{
+				{Name: LabelSeasDetected, Period: 90 * time.Minute, Orders: 1},
+				{Name: LabelSeasDetected, Period: 5 * time.Hour, Orders: 1},
+			},
+		},
+		"max orders and top k": {
+			opt: SeasonalityOptions{
+				Auto:   true,
+				Detect: SeasonalityDetectOptions{TopK: 1, Orders: 1},
+			},
+			detected: []DetectedSeasonality{
+				{Name: LabelSeasDaily, Period: 24 * time.Hour, Orders: 1},
+			},
+		},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			opt := td.opt
+			// detecting again replaces the previously detected seasonality
+			for i := 0; i < 2; i++ {
+				require.Nil(t, opt.DetectSeasonality(tWin, y))
+			}
+
+			require.Len(t, opt.Detected, len(td.detected))
+			for i, d := range opt.Detected {
+				expected := td.detected[i]
+				assert.Greater(t, d.Power, DefaultDetectThreshold)
+				assert.InDelta(t, expected.Period, d.Period, float64(time.Minute))
+				assert.Equal(t, expected.Orders, d.Orders)
+				if expected.Name == LabelSeasDetected {
+					assert.Equal(t, fmt.Sprintf("%s_%s", LabelSeasDetected, d.Period), d.Name)
+				} else {
+					assert.Equal(t, expected, DetectedSeasonality{Name: d.Name, Period: d.Period, Orders: d.Orders})
+				}
+			}
+
+			var configured []string
+			for _, seasCfg := range opt.SeasonalityConfigs[:len(td.configured)] {
+				configured = append(configured, seasCfg.Name)
+			}
+			assert.Equal(t, td.configured, configured)
+			assert.Len(t, opt.SeasonalityConfigs, len(td.configured)+len(td.detected))
+		})
+	}
+}
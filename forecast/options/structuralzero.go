@@ -0,0 +1,126 @@
+package options
+
+import (
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/timedataset"
+)
+
+// StructuralZeroOptions declares windows where the series is structurally zero e.g. batch jobs that
+// never run on weekends. Points in these windows are excluded from fitting and always predicted as
+// exactly zero instead of fighting with additive weekend or event coefficients. Weekends follow the
+// timezone and buffers of the weekend options even if weekend features are disabled. Events lists
+// the names of configured events whose occurrences are structurally zero. DetectWeekends declares
+// weekends structurally zero on fit if every observed weekend training value is exactly zero.
+type StructuralZeroOptions struct {
+	Weekends       bool     `json:"weekends"`
+	Events         []string `json:"events"`
+	DetectWeekends bool     `json:"detect_weekends"`
+}
+
+// enabled returns true if any structural zero window may be declared
+func (s StructuralZeroOptions) enabled() bool {
+	return s.Weekends || len(s.Events) > 0 || s.DetectWeekends
+}
+
+// isWeekend reports whether the time falls on a weekend in the weekend options timezone
+func (o *Options) isWeekend(tPnt time.Time, loc *time.Location) bool {
+	if loc != nil {
+		tPnt = tPnt.In(loc)
+	}
+	return o.WeekendOptions.isWeekend(tPnt)
+}
+
+// weekendLocation returns the timezone override of the weekend options or nil to use the dataset
+// timezone
+func (o *Options) weekendLocation() *time.Location {
+	if o.WeekendOptions.TimezoneOverride == "" {
+		return nil
+	}
+	loc, err := time.LoadLocation(o.WeekendOptions.TimezoneOverride)
+	if err != nil {
+		slog.Warn("invalid timezone location override for structural zero weekends, using dataset timezone", "timezone_override", o.WeekendOptions.TimezoneOverride)
+		return nil
+	}
+	return loc
+}
+
+// LocalLocation returns the timezone local hours of day are taken in. This is the weekend timezone
+// override if set, otherwise the first loadable DST timezone location if DST is enabled, otherwise UTC.
+func (o *Options) LocalLocation() *time.Location {
+	if o == nil {
+		return time.UTC
+	}
+	if loc := o.weekendLocation(); loc != nil {
+		return loc
+	}
+	if o.DSTOptions.Enabled {
+		if locs := loadLocations(o.DSTOptions.TimezoneLocations); len(locs) > 0 {
+			return locs[0]
+		}
+	}
+	return time.UTC
+}
+
+// DetectStructuralZeros declares weekends structurally zero if detection is enabled and every observed
+// weekend training value is exactly zero
+func (o *Options) DetectStructuralZeros(t []time.Time, y []float64) {
+	if !o.StructuralZeroOptions.DetectWeekends || o.StructuralZeroOptions.Weekends {
+		return
+	}
+	loc := o.weekendLocation()
+	var numWeekend int
+	for i, tPnt := range t {
+		if math.IsNaN(y[i]) || !o.isWeekend(tPnt, loc) {
+			continue
+		}
+		if y[i] != 0 {
+			return
+		}
+		numWeekend++
+	}
+	o.StructuralZeroOptions.Weekends = numWeekend > 0
+}
+
+// StructuralZeroMask returns true for every time in a declared structural zero window or nil if no
+// time is structurally zero
+func (o *Options) StructuralZeroMask(t []time.Time) []bool {
+	if !o.StructuralZeroOptions.enabled() || len(t) == 0 {
+		return nil
+	}
+
+	var events []Event
+	if len(o.StructuralZeroOptions.Events) > 0 {
+		ts := timedataset.TimeSlice(t)
+		start := ts.StartTime()
+		end := ts.EndTime()
+		for _, name := range o.StructuralZeroOptions.Events {
+			for _, ev := range o.EventOptions.Events {
+				if ev.Name != name || ev.Valid() != nil {
+					continue
+				}
+				events = append(events, ev.occurrences(start, end, o.EventOptions.AutoExpand)...)
+			}
+		}
+	}
+
+	var loc *time.Location
+	if o.StructuralZeroOptions.Weekends {
+		loc = o.weekendLocation()
+	}
+
+	var mask []bool
+	for i, tPnt := range t {
+		zero := (o.StructuralZeroOptions.Weekends && o.isWeekend(tPnt, loc)) || inEvents(tPnt, events)
+		if !zero {
+			continue
+		}
+		if mask == nil {
+			mask = make([]bool, len(t))
+		}
+		mask[i] = true
+	}
+	return mask
+}
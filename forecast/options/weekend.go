@@ -2,23 +2,44 @@ package options
 
 import (
 	"log/slog"
+	"slices"
 	"time"
 
 	"github.com/aouyang1/go-forecaster/feature"
 	"github.com/aouyang1/go-forecaster/timedataset"
 )
 
-// MaxWeekendDurBuffer sets a limit of 1 day before or after the weekend begins at 00:00 Saturday
-// or 00:00 Monday, respectively. Timezone is based on weekend option timezone override or dataset
-// timezone
+// MaxWeekendDurBuffer sets a limit of 1 day before or after the weekend begins, e.g. 00:00 Saturday,
+// or ends, e.g. 00:00 Monday, respectively. Timezone is based on weekend option timezone override or
+// dataset timezone
 const MaxWeekendDurBuffer = 24 * time.Hour
 
-// WeekendOptions lets us model weekends separately from weekdays.
+// DefaultWeekendDays are Saturday and Sunday which are the weekend if no days are configured
+var DefaultWeekendDays = []WeekendDay{
+	{Day: time.Saturday},
+	{Day: time.Sunday},
+}
+
+// WeekendDay is a day of the weekend which begins StartOffset after midnight, e.g. noon on a Friday,
+// and lasts through the end of the day
+type WeekendDay struct {
+	Day         time.Weekday  `json:"day"`
+	StartOffset time.Duration `json:"start_offset,omitempty"`
+}
+
+// WeekendOptions lets us model weekends separately from weekdays. Days sets the weekend days, e.g.
+// Friday and Saturday for many Middle East locales, and defaults to DefaultWeekendDays. MaskWindow
+// overrides the mask window function of the options and RampUp and RampDown linearly ramp the mask
+// into and out of each weekend.
 type WeekendOptions struct {
 	Enabled          bool          `json:"enabled"`
 	TimezoneOverride string        `json:"timezone_override"`
 	DurBefore        time.Duration `json:"duration_before"`
 	DurAfter         time.Duration `json:"duration_after"`
+	Days             []WeekendDay  `json:"days,omitempty"`
+	MaskWindow       string        `json:"mask_window,omitempty"`
+	RampUp           time.Duration `json:"ramp_up,omitempty"`
+	RampDown         time.Duration `json:"ramp_down,omitempty"`
 }
 
 func (w *WeekendOptions) Validate() {
@@ -33,19 +54,52 @@ func (w *WeekendOptions) Validate() {
 	} else if w.DurAfter < -MaxWeekendDurBuffer {
 		w.DurAfter = -MaxWeekendDurBuffer
 	}
+
+	w.RampUp = min(max(w.RampUp, 0), MaxWeekendDurBuffer)
+	w.RampDown = min(max(w.RampDown, 0), MaxWeekendDurBuffer)
+
+	// start offsets stay within their day
+	w.Days = slices.Clone(w.Days)
+	for i, d := range w.Days {
+		if d.StartOffset < 0 {
+			w.Days[i].StartOffset = 0
+		} else if d.StartOffset >= 24*time.Hour {
+			w.Days[i].StartOffset = 24*time.Hour - time.Nanosecond
+		}
+	}
+}
+
+// weekendDays returns the configured weekend days or the default Saturday and Sunday
+func (w WeekendOptions) weekendDays() []WeekendDay {
+	if len(w.Days) == 0 {
+		return DefaultWeekendDays
+	}
+	return w.Days
+}
+
+// onWeekendDay returns true if the time is on a weekend day at or after the start offset of the day
+func (w WeekendOptions) onWeekendDay(tPnt time.Time) bool {
+	wkday := tPnt.Weekday()
+	for _, d := range w.weekendDays() {
+		if d.Day != wkday {
+			continue
+		}
+		if d.StartOffset == 0 {
+			return true
+		}
+		midnight := time.Date(tPnt.Year(), tPnt.Month(), tPnt.Day(), 0, 0, 0, 0, tPnt.Location())
+		return tPnt.Sub(midnight) >= d.StartOffset
+	}
+	return false
 }
 
 func (w WeekendOptions) isWeekend(tPnt time.Time) bool {
 	if w.DurBefore == 0 && w.DurAfter == 0 {
-		wkday := tPnt.Weekday()
-		return wkday == time.Saturday || wkday == time.Sunday
+		return w.onWeekendDay(tPnt)
 	}
 
-	wkdayBefore := tPnt.Add(w.DurBefore).Weekday()
-	wkdayAfter := tPnt.Add(-w.DurAfter).Weekday()
-
-	wkdayBeforeValid := wkdayBefore == time.Saturday || wkdayBefore == time.Sunday
-	wkdayAfterValid := wkdayAfter == time.Saturday || wkdayAfter == time.Sunday
+	wkdayBeforeValid := w.onWeekendDay(tPnt.Add(w.DurBefore))
+	wkdayAfterValid := w.onWeekendDay(tPnt.Add(-w.DurAfter))
 
 	if w.DurBefore > 0 && w.DurAfter > 0 {
 		return wkdayBeforeValid || wkdayAfterValid
@@ -54,8 +108,8 @@ func (w WeekendOptions) isWeekend(tPnt time.Time) bool {
 	return wkdayBeforeValid && wkdayAfterValid
 }
 
-func (w WeekendOptions) generateEventMask(t []time.Time, eFeat *feature.Set, winFunc func([]float64) []float64) {
-	if !w.Enabled || len(t) < 2 {
+func (w WeekendOptions) generateEventMask(t []time.Time, freq time.Duration, eFeat *feature.Set, winFunc func([]float64) []float64) {
+	if !w.Enabled {
 		return
 	}
 	if w.TimezoneOverride != "" {
@@ -74,17 +128,12 @@ func (w WeekendOptions) generateEventMask(t []time.Time, eFeat *feature.Set, win
 	w.Validate()
 
 	ts := timedataset.TimeSlice(t)
-	freq, err := ts.EstimateFreq()
-	if err != nil {
-		panic(err)
-	}
-
 	start := ts.StartTime()
 	end := ts.EndTime()
-	window := 2 * 24 * time.Hour
+	window := time.Duration(max(2, len(w.weekendDays()))) * 24 * time.Hour
 
 	// pad beginning
-	numElem := int((window+w.DurBefore)/freq) + 1
+	numElem := int((window+w.DurBefore+w.RampUp)/freq) + 1
 	startIdx := numElem
 	prefix := make([]time.Time, numElem)
 	for i := 0; i < numElem; i++ {
@@ -93,7 +142,7 @@ func (w WeekendOptions) generateEventMask(t []time.Time, eFeat *feature.Set, win
 	t = append(prefix, t...)
 
 	// pad end
-	numElem = int((window+w.DurAfter)/freq) + 1
+	numElem = int((window+w.DurAfter+w.RampDown)/freq) + 1
 	endIdx := len(t)
 	suffix := make([]time.Time, numElem)
 	for i := 0; i < numElem; i++ {
@@ -101,7 +150,10 @@ func (w WeekendOptions) generateEventMask(t []time.Time, eFeat *feature.Set, win
 	}
 	t = append(t, suffix...)
 
-	weekendMask := generateEventMaskWithFunc(t, w.isWeekend, winFunc)
+	if w.MaskWindow != "" {
+		winFunc = WindowFunc(w.MaskWindow)
+	}
+	weekendMask := generateShapedMask(t, w.isWeekend, maskShape{window: winFunc, rampUp: w.RampUp, rampDown: w.RampDown})
 
 	// truncate result to start/end
 	weekendMask = weekendMask[startIdx:endIdx]
@@ -0,0 +1,59 @@
+package forecast
+
+import (
+	"github.com/aouyang1/go-forecaster/feature"
+	"github.com/aouyang1/go-forecaster/models"
+)
+
+// CoefficientPath is the regularization path of the Lasso fit across the configured lambdas in ascending
+// order. Scores are the in-sample coefficient of determination of each lambda.
+type CoefficientPath struct {
+	Lambdas  []float64     `json:"lambdas"`
+	Scores   []float64     `json:"scores"`
+	Features []PathFeature `json:"features"`
+}
+
+// PathFeature is the coefficient of a single feature for every lambda of the path
+type PathFeature struct {
+	Label string              `json:"label"`
+	Type  feature.FeatureType `json:"type"`
+	Coef  []float64           `json:"coefficients"`
+}
+
+// newCoefficientPath maps the path of a fit with a leading intercept column onto the feature labels
+func newCoefficientPath(labels []feature.Feature, path []models.PathPoint) *CoefficientPath {
+	if len(path) == 0 {
+		return nil
+	}
+	cp := &CoefficientPath{
+		Lambdas:  make([]float64, len(path)),
+		Scores:   make([]float64, len(path)),
+		Features: make([]PathFeature, len(labels)),
+	}
+	for j, label := range labels {
+		cp.Features[j] = PathFeature{
+			Label: label.String(),
+			Type:  label.Type(),
+			Coef:  make([]float64, len(path)),
+		}
+	}
+	for i, pnt := range path {
+		cp.Lambdas[i] = pnt.Lambda
+		cp.Scores[i] = pnt.Score
+		for j := range labels {
+			if j+1 < len(pnt.Coef) {
+				cp.Features[j].Coef[i] = pnt.Coef[j+1]
+			}
+		}
+	}
+	return cp
+}
+
+// regressionPath returns the regularization path of the fit model or nil if the backend does not retain
+// one
+func regressionPath(model models.Model) []models.PathPoint {
+	if p, ok := model.(interface{ Path() []models.PathPoint }); ok {
+		return p.Path()
+	}
+	return nil
+}
@@ -0,0 +1,325 @@
+package forecast
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/feature"
+	"github.com/aouyang1/go-forecaster/forecast/options"
+)
+
+// fourierTerm is the weighted sine and cosine of a fourier order evaluated from the epoch
+type fourierTerm struct {
+	omega      float64
+	sinW, cosW float64
+}
+
+func (f fourierTerm) eval(epoch float64) float64 {
+	sin, cos := math.Sincos(f.omega * epoch)
+	return f.sinW*sin + f.cosW*cos
+}
+
+// localFourierTerms are the fourier terms of a seasonality in the local time of a timezone location
+type localFourierTerms struct {
+	loc     *time.Location
+	fourier []fourierTerm
+}
+
+// changepointTerm is the weighted bias and slope of a changepoint where the slope is zero at the
+// changepoint and one at the end of training
+type changepointTerm struct {
+	t      time.Time
+	deltaT float64
+	biasW  float64
+	slopeW float64
+}
+
+// eventTerm is the weighted mask of an event and the weighted fourier terms of its event seasonalities
+type eventTerm struct {
+	label   feature.Feature
+	weight  float64
+	fourier []fourierTerm
+}
+
+// Predictor is a compiled form of a trained forecast for repeated point predictions such as high QPS
+// serving. The fourier seasonality and changepoint terms of the model are resolved once and evaluated
+// directly from the input times into the destination buffer without generating feature sets or a
+// design matrix. Event masks are generated once per prediction if the model has event features.
+// Models with features that cannot be compiled, i.e. DST adjusted or monthly seasonality, trend or
+// event interactions, custom time features, and regressors, predict through Forecast.Predict. The
+// local daily seasonality of a DST mixture is compiled.
+// The predictions are the point values of the forecast model only. A series model fit by a forecaster
+// with a transform or logistic growth predicts in the transformed space without uncertainty bands, so
+// forecaster.NewPredictor should be used to serve forecaster models.
+// A Predictor does not modify its model and is safe for concurrent use.
+type Predictor struct {
+	f *Forecast
+
+	intercept float64
+	fourier   []fourierTerm
+	local     []localFourierTerms
+	chpts     []changepointTerm
+	events    []eventTerm
+
+	// compiled is false if any feature of the model is predicted by Forecast.Predict
+	compiled bool
+}
+
+// NewPredictor compiles a trained model into a predictor
+func NewPredictor(m Model) (*Predictor, error) {
+	f, err := NewFromModel(m)
+	if err != nil {
+		return nil, err
+	}
+	p := &Predictor{
+		f:         f,
+		intercept: f.intercept,
+	}
+	compiled, err := p.compile()
+	if err != nil {
+		return nil, err
+	}
+	if !compiled {
+		p.fourier, p.local, p.chpts, p.events = nil, nil, nil, nil
+	}
+	p.compiled = compiled
+	return p, nil
+}
+
+// Compiled returns true if every feature of the model is evaluated directly by the predictor
+func (p *Predictor) Compiled() bool {
+	if p == nil {
+		return false
+	}
+	return p.compiled
+}
+
+// seasonalityOmega returns the angular frequency per second of every fourier order feature name
+// which is generated directly from the epoch of the input times
+func seasonalityOmega(opt *options.Options) map[string]float64 {
+	periods := make(map[string]float64)
+	for _, cfg := range opt.SeasonalityOptions.SeasonalityConfigs {
+		periods[options.SeasonalityFeatureName(cfg.Name)] = cfg.Period.Seconds()
+	}
+	if opt.SeasonalityOptions.Monthly.Enabled() {
+		// calendar aware monthly fourier features do not have a fixed period
+		delete(periods, options.SeasonalityFeatureName(options.LabelSeasMonthly))
+	}
+	return periods
+}
+
+// compile resolves every feature weight into a term returning false if any feature can only be
+// predicted through the feature sets of Forecast.Predict
+func (p *Predictor) compile() (bool, error) {
+	opt := p.f.opt
+	if opt == nil || (opt.DSTOptions.Enabled && !opt.DSTOptions.Mixture) || opt.EventOptions.Interactions.Enabled() {
+		return false, nil
+	}
+
+	periods := seasonalityOmega(opt)
+
+	// the daily seasonality of a dst mixture is in the local time of each timezone location
+	localPeriods := make(map[string]int)
+	if opt.DSTOptions.Enabled {
+		period, exists := periods[options.SeasonalityFeatureName(options.LabelSeasDaily)]
+		for _, name := range opt.DSTOptions.TimezoneLocations {
+			loc, err := time.LoadLocation(name)
+			if !exists || err != nil {
+				continue
+			}
+			localName := options.SeasonalityFeatureName(options.MixtureSeasonalityName(loc.String()))
+			localPeriods[localName] = len(p.local)
+			p.local = append(p.local, localFourierTerms{loc: loc})
+			periods[localName] = period
+		}
+	}
+	eventSeasPeriods := make(map[string]map[string]float64)
+	addEventSeas := func(eventName, seasName string) {
+		period, exists := periods[options.SeasonalityFeatureName(seasName)]
+		if !exists {
+			return
+		}
+		name := options.EventSeasonalityFeatureName(eventName, seasName)
+		if eventSeasPeriods[name] == nil {
+			eventSeasPeriods[name] = make(map[string]float64)
+		}
+		eventSeasPeriods[name][eventName] = period
+	}
+	for _, cfg := range opt.SeasonalityOptions.SeasonalityConfigs {
+		if cfg.Name == options.LabelSeasDaily && opt.WeekendOptions.Enabled {
+			addEventSeas(options.LabelEventWeekend, cfg.Name)
+		}
+		for _, e := range opt.EventOptions.Events {
+			addEventSeas(e.Name, cfg.Name)
+		}
+	}
+
+	chpts := make(map[string]int)
+	for _, chpt := range opt.ChangepointOptions.Changepoints {
+		// changepoints after the end of training are not modeled
+		if chpt.T.After(p.f.trainEndTime) {
+			continue
+		}
+		name := chpt.Name
+		if name == "" {
+			name = strconv.Itoa(len(p.chpts))
+		}
+		chpts[name] = len(p.chpts)
+		p.chpts = append(p.chpts, changepointTerm{
+			t:      chpt.T,
+			deltaT: p.f.trainEndTime.Sub(chpt.T).Seconds(),
+		})
+	}
+
+	fourier := make(map[string]int)
+	addFourier := func(terms *[]fourierTerm, key string, period float64, order int, comp feature.FourierComp, w float64) {
+		i, exists := fourier[key]
+		if !exists {
+			i = len(*terms)
+			fourier[key] = i
+			*terms = append(*terms, fourierTerm{omega: 2.0 * math.Pi * float64(order) / period})
+		}
+		if comp == feature.FourierCompSin {
+			(*terms)[i].sinW += w
+		} else {
+			(*terms)[i].cosW += w
+		}
+	}
+	events := make(map[string]int)
+	eventIdx := func(name string) int {
+		i, exists := events[name]
+		if !exists {
+			i = len(p.events)
+			events[name] = i
+			p.events = append(p.events, eventTerm{label: feature.NewEvent(name)})
+		}
+		return i
+	}
+
+	for _, fw := range p.f.featureWeights {
+		feat, err := fw.ToFeature()
+		if err != nil {
+			return false, fmt.Errorf("unable to convert to feature for compiling predictor, %v, %w", fw, err)
+		}
+		switch f := feat.(type) {
+		case *feature.Seasonality:
+			name := f.Name
+			key := fmt.Sprintf("%s_%d", name, f.Order)
+			if i, exists := localPeriods[name]; exists {
+				addFourier(&p.local[i].fourier, key, periods[name], f.Order, f.FourierComp, fw.Value)
+				continue
+			}
+			if period, exists := periods[name]; exists {
+				addFourier(&p.fourier, key, period, f.Order, f.FourierComp, fw.Value)
+				continue
+			}
+			eventPeriods, exists := eventSeasPeriods[name]
+			if !exists || len(eventPeriods) != 1 {
+				return false, nil
+			}
+			for eventName, period := range eventPeriods {
+				e := &p.events[eventIdx(eventName)]
+				addFourier(&e.fourier, eventName+"/"+key, period, f.Order, f.FourierComp, fw.Value)
+			}
+		case *feature.Changepoint:
+			i, exists := chpts[f.Name]
+			if !exists {
+				return false, nil
+			}
+			switch f.ChangepointComp {
+			case feature.ChangepointCompBias:
+				p.chpts[i].biasW += fw.Value
+			case feature.ChangepointCompSlope:
+				p.chpts[i].slopeW += fw.Value
+			default:
+				return false, nil
+			}
+		case *feature.Event:
+			p.events[eventIdx(f.Name)].weight += fw.Value
+		default:
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// Predict predicts the input times into dst returning the predictions. dst is reused if it has the
+// capacity for every input time and otherwise a new slice is allocated.
+func (p *Predictor) Predict(t []time.Time, dst []float64) ([]float64, error) {
+	if p == nil || p.f == nil {
+		return nil, ErrUninitializedForecast
+	}
+	if cap(dst) < len(t) {
+		dst = make([]float64, len(t))
+	}
+	dst = dst[:len(t)]
+
+	if !p.compiled {
+		res, _, err := p.f.Predict(t)
+		if err != nil {
+			return nil, err
+		}
+		copy(dst, res)
+		return dst, nil
+	}
+
+	for i, tPnt := range t {
+		epoch := float64(tPnt.UnixNano()) / 1e9
+		val := p.intercept
+		for _, term := range p.fourier {
+			val += term.eval(epoch)
+		}
+		for _, local := range p.local {
+			_, offset := tPnt.In(local.loc).Zone()
+			for _, term := range local.fourier {
+				val += term.eval(epoch + float64(offset))
+			}
+		}
+		for _, term := range p.chpts {
+			if tPnt.Before(term.t) {
+				continue
+			}
+			val += term.biasW
+			if term.slopeW != 0 {
+				val += term.slopeW * tPnt.Sub(term.t).Seconds() / term.deltaT
+			}
+		}
+		dst[i] = val
+	}
+
+	if len(p.events) > 0 {
+		if err := p.f.ValidateEventHorizon(t); err != nil {
+			slog.Warn("predicting without recurring event occurrences", "error", err.Error())
+		}
+		eFeat := p.f.opt.GenerateEventFeatures(t)
+		for _, term := range p.events {
+			mask, exists := eFeat.Get(term.label)
+			if !exists {
+				continue
+			}
+			for i, m := range mask {
+				if m == 0 {
+					continue
+				}
+				val := term.weight
+				if len(term.fourier) > 0 {
+					epoch := float64(t[i].UnixNano()) / 1e9
+					for _, fourier := range term.fourier {
+						val += fourier.eval(epoch)
+					}
+				}
+				dst[i] += m * val
+			}
+		}
+	}
+
+	for i, zero := range p.f.opt.StructuralZeroMask(t) {
+		if zero {
+			dst[i] = 0
+		}
+	}
+	return dst, nil
+}
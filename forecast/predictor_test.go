@@ -0,0 +1,147 @@
+package forecast
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/forecast/options"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPredictor(t *testing.T) {
+	// two weeks hourly with a daily and weekly wave, a level shift, a weekend dip, and a promotion
+	n := 14 * 24
+	ct := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	promoStart := ct.Add(9 * 24 * time.Hour)
+	promoEnd := promoStart.Add(12 * time.Hour)
+	tWin := make([]time.Time, 0, n)
+	y := make([]float64, 0, n)
+	for i := 0; i < n; i++ {
+		tPnt := ct.Add(time.Duration(i) * time.Hour)
+		tWin = append(tWin, tPnt)
+		sec := tPnt.Sub(ct).Seconds()
+		val := 10.0 + 3.0*math.Sin(2.0*math.Pi*sec/86400.0) + math.Cos(2.0*math.Pi*sec/(7*86400.0))
+		if i >= n/2 {
+			val += 2.0 + 0.01*float64(i-n/2)
+		}
+		if wd := tPnt.Weekday(); wd == time.Saturday || wd == time.Sunday {
+			val -= 4.0
+		}
+		if !tPnt.Before(promoStart) && tPnt.Before(promoEnd) {
+			val += 5.0
+		}
+		y = append(y, val)
+	}
+	horizon := make([]time.Time, 0, 7*24)
+	for i := 0; i < 7*24; i++ {
+		horizon = append(horizon, tWin[n/2].Add(time.Duration(i)*time.Hour))
+	}
+
+	testData := map[string]struct {
+		opt      func(*options.Options)
+		compiled bool
+	}{
+		"seasonality and changepoints": {
+			compiled: true,
+		},
+		"weekends and events": {
+			opt: func(opt *options.Options) {
+				opt.WeekendOptions.Enabled = true
+				opt.EventOptions.Events = []options.Event{options.NewEvent("promo", promoStart, promoEnd)}
+			},
+			compiled: true,
+		},
+		"trend interactions": {
+			opt: func(opt *options.Options) {
+				opt.SeasonalityOptions.TrendInteraction = true
+			},
+		},
+		"dst mixture": {
+			opt: func(opt *options.Options) {
+				opt.WeekendOptions.Enabled = true
+				opt.DSTOptions.Enabled = true
+				opt.DSTOptions.Mixture = true
+				opt.DSTOptions.TimezoneLocations = []string{"America/Los_Angeles", "Europe/London"}
+			},
+			compiled: true,
+		},
+		"dst": {
+			opt: func(opt *options.Options) {
+				opt.DSTOptions.Enabled = true
+				opt.DSTOptions.TimezoneLocations = []string{"America/Los_Angeles"}
+			},
+		},
+		"event interactions": {
+			opt: func(opt *options.Options) {
+				opt.EventOptions.Events = []options.Event{options.NewEvent("promo", promoStart, promoEnd)}
+				opt.EventOptions.Interactions.Growth = true
+			},
+		},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			opt := options.NewDefaultOptions()
+			opt.SeasonalityOptions.SeasonalityConfigs = []options.SeasonalityConfig{
+				options.NewDailySeasonalityConfig(3),
+				options.NewWeeklySeasonalityConfig(2),
+			}
+			opt.ChangepointOptions.Auto = true
+			opt.ChangepointOptions.AutoNumChangepoints = 4
+			opt.ChangepointOptions.EnableGrowth = true
+			if td.opt != nil {
+				td.opt(opt)
+			}
+
+			f, err := New(opt)
+			require.Nil(t, err)
+			require.Nil(t, f.Fit(tWin, y))
+			m, err := f.Model()
+			require.Nil(t, err)
+
+			p, err := NewPredictor(m)
+			require.Nil(t, err)
+			assert.Equal(t, td.compiled, p.Compiled())
+
+			for _, tPred := range [][]time.Time{tWin, horizon} {
+				expected, _, err := f.Predict(tPred)
+				require.Nil(t, err)
+
+				res, err := p.Predict(tPred, nil)
+				require.Nil(t, err)
+				assert.InDeltaSlice(t, expected, res, 1e-9)
+
+				// the destination is reused if it has the capacity
+				dst := make([]float64, 0, len(tPred))
+				res, err = p.Predict(tPred, dst)
+				require.Nil(t, err)
+				assert.Equal(t, &dst[:1][0], &res[0])
+				assert.InDeltaSlice(t, expected, res, 1e-9)
+			}
+		})
+	}
+}
+
+func TestPredictorAllocs(t *testing.T) {
+	f, tWin, _ := testFitSignal(t)
+	m, err := f.Model()
+	require.Nil(t, err)
+	p, err := NewPredictor(m)
+	require.Nil(t, err)
+	require.True(t, p.Compiled())
+
+	dst := make([]float64, len(tWin))
+	allocs := testing.AllocsPerRun(10, func() {
+		_, err := p.Predict(tWin, dst)
+		require.Nil(t, err)
+	})
+	assert.Zero(t, allocs)
+
+	_, err = NewPredictor(Model{})
+	assert.NotNil(t, err)
+	var nilPredictor *Predictor
+	_, err = nilPredictor.Predict(tWin, nil)
+	assert.ErrorIs(t, err, ErrUninitializedForecast)
+}
@@ -0,0 +1,173 @@
+package forecast
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/errs"
+	"github.com/aouyang1/go-forecaster/feature"
+	"github.com/aouyang1/go-forecaster/forecast/options"
+)
+
+var (
+	ErrEmptyRegressorName = errs.NewDataError(errs.CodeInvalidValue, "regressor name cannot be empty", nil)
+	ErrRegressorLen       = errs.NewDataError(errs.CodeLengthMismatch, "regressor has different length than time", nil)
+	ErrMissingRegressor   = errs.NewPredictError(errs.CodeMissingOption, "regressor in the model was not provided", nil)
+)
+
+// Regressors are external series keyed by name that are aligned with the input times. Each regressor
+// becomes a feature column with its own coefficient in the model.
+type Regressors map[string][]float64
+
+// validate returns an error if any regressor is unnamed or not aligned with the input number of times
+func (r Regressors) validate(n int) error {
+	for name, vals := range r {
+		if name == "" {
+			return ErrEmptyRegressorName
+		}
+		if len(vals) != n {
+			return fmt.Errorf("regressor %q has %d values for %d times, %w", name, len(vals), n, ErrRegressorLen)
+		}
+	}
+	return nil
+}
+
+// names returns the sorted regressor names so features are generated in a deterministic order
+func (r Regressors) names() []string {
+	names := make([]string, 0, len(r))
+	for name := range r {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// maskMissing returns a copy of y with NaNs wherever any regressor is not finite so those points are
+// excluded from the fit as if they were never observed
+func (r Regressors) maskMissing(y []float64) []float64 {
+	if len(r) == 0 {
+		return y
+	}
+	res := make([]float64, len(y))
+	copy(res, y)
+	for _, vals := range r {
+		for i, v := range vals {
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				res[i] = math.NaN()
+			}
+		}
+	}
+	return res
+}
+
+// observed returns the regressor values at every non-NaN point of y
+func (r Regressors) observed(y []float64) Regressors {
+	if len(r) == 0 {
+		return nil
+	}
+	res := make(Regressors, len(r))
+	for name, vals := range r {
+		obs := make([]float64, 0, len(vals))
+		for i, v := range vals {
+			if math.IsNaN(y[i]) {
+				continue
+			}
+			obs = append(obs, v)
+		}
+		res[name] = obs
+	}
+	return res
+}
+
+// withLags returns the regressors along with a column per non-zero lag of each provided regressor
+// holding its value the lag earlier or zero if it is not provided at that time. An error is returned if
+// the name of a lagged regressor is also provided.
+func (r Regressors) withLags(t []time.Time, lags map[string][]time.Duration) (Regressors, error) {
+	var res Regressors
+	var idx map[int64]int
+	for _, name := range r.names() {
+		for _, lag := range lags[name] {
+			if lag == 0 {
+				continue
+			}
+			lagName := options.LaggedRegressorName(name, lag)
+			if _, exists := r[lagName]; exists {
+				return nil, fmt.Errorf("regressor %q, %w", lagName, options.ErrFeatureNameCollision)
+			}
+			if res == nil {
+				res = make(Regressors, len(r))
+				maps.Copy(res, r)
+				idx = make(map[int64]int, len(t))
+				for i, tPnt := range t {
+					idx[tPnt.UnixNano()] = i
+				}
+			}
+			lagged := make([]float64, len(t))
+			for i, tPnt := range t {
+				if j, exists := idx[tPnt.Add(-lag).UnixNano()]; exists {
+					lagged[i] = r[name][j]
+				}
+			}
+			res[lagName] = lagged
+		}
+	}
+	if res == nil {
+		return r, nil
+	}
+	return res, nil
+}
+
+// addRegressorFeatures adds a feature column for every regressor to the feature set
+func addRegressorFeatures(feat *feature.Set, r Regressors) {
+	for _, name := range r.names() {
+		data := make([]float64, len(r[name]))
+		copy(data, r[name])
+		feat.Set(feature.NewRegressor(name), data)
+	}
+}
+
+// FitWithRegressors fits the forecast model along with a coefficient for each of the external
+// regressors. Regressors must be aligned with the training times and any point where a regressor is
+// NaN or infinite is excluded from the fit. The fast path detection is skipped.
+func (f *Forecast) FitWithRegressors(t []time.Time, y []float64, r Regressors) error {
+	return f.FitWithRegressorsCtx(context.Background(), t, y, r)
+}
+
+// FitWithRegressorsCtx fits the forecast model with the external regressors like FitWithRegressors
+// returning the context error if the context is done before the fit completes
+func (f *Forecast) FitWithRegressorsCtx(ctx context.Context, t []time.Time, y []float64, r Regressors) error {
+	if f == nil {
+		return ErrUninitializedForecast
+	}
+	if err := r.validate(len(t)); err != nil {
+		return err
+	}
+	return f.fit(ctx, t, y, nil, r)
+}
+
+// PredictWithRegressors predicts the input times with the values of the external regressors aligned
+// with the times. Every regressor with a coefficient in the model must be provided unless its missing
+// policy fills it.
+func (f *Forecast) PredictWithRegressors(t []time.Time, r Regressors) ([]float64, Components, error) {
+	if f == nil {
+		return nil, Components{}, ErrUninitializedForecast
+	}
+	if err := r.validate(len(t)); err != nil {
+		return nil, Components{}, err
+	}
+	return f.predict(t, r)
+}
+
+// RegressorComponent represents the overall contribution of the external regressors in the model
+func (f *Forecast) RegressorComponent() []float64 {
+	if f == nil {
+		return nil
+	}
+	res := make([]float64, len(f.trainComponents.Regressor))
+	copy(res, f.trainComponents.Regressor)
+	return res
+}
@@ -0,0 +1,122 @@
+package forecast
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/forecast/options"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFitWithRegressors(t *testing.T) {
+	n := 2 * 24 * 12
+	ct := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tWin := make([]time.Time, 0, n)
+	y := make([]float64, n)
+	temp := make([]float64, n)
+	for i := 0; i < n; i++ {
+		tWin = append(tWin, ct.Add(time.Duration(i)*5*time.Minute))
+		temp[i] = math.Mod(float64(i)*0.37, 5.0)
+		y[i] = 2.0 + 3.0*temp[i]
+	}
+	// a missing regressor value excludes the point from the fit
+	temp[10] = math.NaN()
+	y[10] = 1000.0
+
+	opt := options.NewDefaultOptions()
+	opt.SeasonalityOptions.SeasonalityConfigs = nil
+	opt.Regularization = []float64{0.0}
+	f, err := New(opt)
+	require.Nil(t, err)
+	require.Nil(t, f.FitWithRegressors(tWin, y, Regressors{"temp": temp}))
+
+	coef, err := f.Coefficients()
+	require.Nil(t, err)
+	assert.InDelta(t, 2.0, f.Intercept(), 1e-3)
+	assert.InDelta(t, 3.0, coef["reg_temp"], 1e-3)
+
+	model, err := f.Model()
+	require.Nil(t, err)
+	loaded, err := NewFromModel(model)
+	require.Nil(t, err)
+
+	tPred := []time.Time{ct.Add(-time.Hour), ct.Add(time.Hour)}
+	pred, comp, err := loaded.PredictWithRegressors(tPred, Regressors{"temp": {1.0, 4.0}})
+	require.Nil(t, err)
+	assert.InDeltaSlice(t, []float64{5.0, 14.0}, pred, 1e-3)
+	assert.InDeltaSlice(t, []float64{3.0, 12.0}, comp.Regressor, 1e-3)
+
+	_, _, err = loaded.Predict(tPred)
+	assert.ErrorIs(t, err, ErrMissingRegressor)
+	_, _, err = loaded.PredictWithRegressors(tPred, Regressors{"temp": {1.0}})
+	assert.ErrorIs(t, err, ErrRegressorLen)
+	assert.ErrorIs(t, f.FitWithRegressors(tWin, y, Regressors{"": temp}), ErrEmptyRegressorName)
+}
+
+func TestFitWithRegressorLags(t *testing.T) {
+	n := 3 * 24
+	ct := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// a marketing push lifts traffic two hours later
+	tWin := make([]time.Time, 0, n)
+	y := make([]float64, n)
+	push := make([]float64, n)
+	for i := 0; i < n; i++ {
+		tWin = append(tWin, ct.Add(time.Duration(i)*time.Hour))
+		if i%7 == 0 || i%11 == 0 {
+			push[i] = 1.0
+		}
+	}
+	for i := range y {
+		y[i] = 2.0
+		if i >= 2 && push[i-2] == 1.0 {
+			y[i] += 4.0
+		}
+	}
+
+	opt := options.NewDefaultOptions()
+	opt.SeasonalityOptions.SeasonalityConfigs = nil
+	opt.Regularization = []float64{0.0}
+	opt.RegressorLags = map[string][]time.Duration{"push": {2 * time.Hour}}
+	f, err := New(opt)
+	require.Nil(t, err)
+	require.Nil(t, f.FitWithRegressors(tWin, y, Regressors{"push": push}))
+
+	coef, err := f.Coefficients()
+	require.Nil(t, err)
+	assert.InDelta(t, 2.0, f.Intercept(), 1e-3)
+	assert.InDelta(t, 0.0, coef["reg_push"], 1e-3)
+	assert.InDelta(t, 4.0, coef["reg_push_lag_2h"], 1e-3)
+
+	model, err := f.Model()
+	require.Nil(t, err)
+	loaded, err := NewFromModel(model)
+	require.Nil(t, err)
+
+	// the lag of the first times is zero without an earlier push
+	tPred := make([]time.Time, 4)
+	for i := range tPred {
+		tPred[i] = ct.Add(time.Duration(n+i) * time.Hour)
+	}
+	pred, _, err := loaded.PredictWithRegressors(tPred, Regressors{"push": {1.0, 0.0, 0.0, 0.0}})
+	require.Nil(t, err)
+	assert.InDeltaSlice(t, []float64{2.0, 2.0, 6.0, 2.0}, pred, 1e-3)
+
+	_, _, err = loaded.Predict(tPred)
+	assert.ErrorIs(t, err, ErrMissingRegressor)
+
+	// a missing regressor fills its lags by its missing policy
+	loaded.opt.MissingFeatures = map[string]options.MissingFeatureOptions{
+		"push": {Policy: options.MissingPolicyDefault, Default: 1.0},
+	}
+	pred, comp, err := loaded.Predict(tPred)
+	require.Nil(t, err)
+	assert.InDeltaSlice(t, []float64{2.0, 2.0, 6.0, 6.0}, pred, 1e-3)
+	assert.Equal(t, []string{"push"}, comp.MissingFeatures)
+
+	err = f.FitWithRegressors(tWin, y, Regressors{"push": push, "push_lag_2h": push})
+	assert.ErrorIs(t, err, options.ErrFeatureNameCollision)
+}
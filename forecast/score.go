@@ -1,20 +1,40 @@
 package forecast
 
 import (
-	"errors"
 	"fmt"
 	"math"
 
+	"github.com/aouyang1/go-forecaster/errs"
 	"gonum.org/v1/gonum/stat"
 )
 
-var ErrResLenMismatch = errors.New("predicted and actual have different lengths")
+var ErrResLenMismatch = errs.NewDataError(errs.CodeLengthMismatch, "predicted and actual have different lengths", nil)
 
-// Scores tracks the fit scores
+// Scores tracks the fit scores. The mean absolute scaled error, coverage, and pinball loss are only
+// computed by NewScoresWithBaseline. Undefined scores such as the scaled error of a series without
+// variation are left as 0 so scores can always be serialized.
 type Scores struct {
-	MSE  float64 `json:"mean_squared_error"`
-	MAPE float64 `json:"mean_average_percent_error"`
-	R2   float64 `json:"r_squared"`
+	MSE      float64 `json:"mean_squared_error"`
+	MAPE     float64 `json:"mean_average_percent_error"`
+	R2       float64 `json:"r_squared"`
+	MAE      float64 `json:"mean_absolute_error"`
+	SMAPE    float64 `json:"symmetric_mean_absolute_percent_error"`
+	MASE     float64 `json:"mean_absolute_scaled_error,omitempty"`
+	Coverage float64 `json:"coverage,omitempty"`
+	Pinball  float64 `json:"pinball_loss,omitempty"`
+}
+
+// ScoreBaseline configures the scores relative to a baseline. The absolute error is scaled by the in
+// sample error of a seasonal naive forecast repeating the actual value SeasonalLag points earlier which
+// defaults to the previous point if unset or too long for the series. Coverage is the fraction of
+// actuals within the Lower and Upper bands if provided. The pinball loss averages the quantile loss of
+// the lower and upper bands as the (1-IntervalLevel)/2 and (1+IntervalLevel)/2 quantiles and is only
+// computed if the interval level is between 0 and 1.
+type ScoreBaseline struct {
+	SeasonalLag   int       `json:"seasonal_lag,omitempty"`
+	Upper         []float64 `json:"upper,omitempty"`
+	Lower         []float64 `json:"lower,omitempty"`
+	IntervalLevel float64   `json:"interval_level,omitempty"`
 }
 
 // NewScores calculates the fit scores given the predicted and actual input slice values
@@ -31,14 +51,57 @@ func NewScores(predicted, actual []float64) (*Scores, error) {
 	if err != nil {
 		return nil, fmt.Errorf("unable to compute r-squared, %w", err)
 	}
+	mae, err := MAE(predicted, actual)
+	if err != nil {
+		return nil, fmt.Errorf("unable to compute mean absolute error, %w", err)
+	}
+	smape, err := SMAPE(predicted, actual)
+	if err != nil {
+		return nil, fmt.Errorf("unable to compute symmetric mean absolute percent error, %w", err)
+	}
 
 	return &Scores{
-		MSE:  mse,
-		MAPE: mape,
-		R2:   rs,
+		MSE:   mse,
+		MAPE:  mape,
+		R2:    rs,
+		MAE:   mae,
+		SMAPE: smape,
 	}, nil
 }
 
+// NewScoresWithBaseline calculates the fit scores along with the mean absolute scaled error against a
+// seasonal naive forecast and the coverage and pinball loss of any bands of the baseline. These remain
+// defined for series with zero values unlike the mean average percent error.
+func NewScoresWithBaseline(predicted, actual []float64, baseline ScoreBaseline) (*Scores, error) {
+	scores, err := NewScores(predicted, actual)
+	if err != nil {
+		return nil, err
+	}
+	scores.MASE, err = MASE(predicted, actual, baseline.SeasonalLag)
+	if err != nil {
+		return nil, fmt.Errorf("unable to compute mean absolute scaled error, %w", err)
+	}
+	if baseline.Upper == nil && baseline.Lower == nil {
+		return scores, nil
+	}
+	scores.Coverage, err = Coverage(baseline.Upper, baseline.Lower, actual)
+	if err != nil {
+		return nil, fmt.Errorf("unable to compute coverage, %w", err)
+	}
+	if baseline.IntervalLevel > 0 && baseline.IntervalLevel < 1 {
+		lower, err := Pinball(baseline.Lower, actual, (1-baseline.IntervalLevel)/2)
+		if err != nil {
+			return nil, fmt.Errorf("unable to compute lower pinball loss, %w", err)
+		}
+		upper, err := Pinball(baseline.Upper, actual, (1+baseline.IntervalLevel)/2)
+		if err != nil {
+			return nil, fmt.Errorf("unable to compute upper pinball loss, %w", err)
+		}
+		scores.Pinball = (lower + upper) / 2
+	}
+	return scores, nil
+}
+
 // MSE computes the mean squared error. This is the same as sum((y-yhat)^2).
 // A score of 0 means a perfect match with no errors.
 func MSE(predicted, actual []float64) (float64, error) {
@@ -97,3 +160,130 @@ func RSquared(predicted, actual []float64) (float64, error) {
 	}
 	return r2, nil
 }
+
+// MAE computes the mean absolute error over the points with both a predicted and actual value. A score
+// of 0 means a perfect match with no errors.
+func MAE(predicted, actual []float64) (float64, error) {
+	if len(predicted) != len(actual) {
+		return 0, ErrResLenMismatch
+	}
+
+	var mae float64
+	var n int
+	for i := 0; i < len(actual); i++ {
+		if math.IsNaN(actual[i]) || math.IsNaN(predicted[i]) {
+			continue
+		}
+		mae += math.Abs(actual[i] - predicted[i])
+		n++
+	}
+	if n == 0 {
+		return 0, nil
+	}
+	return mae / float64(n), nil
+}
+
+// SMAPE calculates the symmetric mean absolute percent error. This is the mean of
+// 2*abs(y-yhat)/(abs(y)+abs(yhat)) ranging from 0 to 2 where points with a zero actual and prediction
+// have no error.
+func SMAPE(predicted, actual []float64) (float64, error) {
+	if len(predicted) != len(actual) {
+		return 0, ErrResLenMismatch
+	}
+
+	var smape float64
+	var n int
+	for i := 0; i < len(actual); i++ {
+		if math.IsNaN(actual[i]) || math.IsNaN(predicted[i]) {
+			continue
+		}
+		n++
+		denom := math.Abs(actual[i]) + math.Abs(predicted[i])
+		if denom == 0 {
+			continue
+		}
+		smape += 2 * math.Abs(actual[i]-predicted[i]) / denom
+	}
+	if n == 0 {
+		return 0, nil
+	}
+	return smape / float64(n), nil
+}
+
+// MASE computes the mean absolute scaled error which is the mean absolute error over the mean absolute
+// error of a seasonal naive forecast of the actuals lagged by the seasonal lag. A score below 1 beats
+// the naive forecast. The lag defaults to 1 if it is not positive or leaves no pairs of actuals to
+// compare. The score is 0 if the naive forecast has no error.
+func MASE(predicted, actual []float64, lag int) (float64, error) {
+	mae, err := MAE(predicted, actual)
+	if err != nil {
+		return 0, err
+	}
+	if lag <= 0 || lag >= len(actual) {
+		lag = 1
+	}
+
+	var scale float64
+	var n int
+	for i := lag; i < len(actual); i++ {
+		if math.IsNaN(actual[i]) || math.IsNaN(actual[i-lag]) {
+			continue
+		}
+		scale += math.Abs(actual[i] - actual[i-lag])
+		n++
+	}
+	if n == 0 || scale == 0 {
+		return 0, nil
+	}
+	return mae / (scale / float64(n)), nil
+}
+
+// Coverage computes the fraction of actuals within the lower and upper bounds inclusive
+func Coverage(upper, lower, actual []float64) (float64, error) {
+	if len(upper) != len(actual) || len(lower) != len(actual) {
+		return 0, ErrResLenMismatch
+	}
+
+	var inBand, n int
+	for i := 0; i < len(actual); i++ {
+		if math.IsNaN(actual[i]) || math.IsNaN(upper[i]) || math.IsNaN(lower[i]) {
+			continue
+		}
+		n++
+		if actual[i] >= lower[i] && actual[i] <= upper[i] {
+			inBand++
+		}
+	}
+	if n == 0 {
+		return 0, nil
+	}
+	return float64(inBand) / float64(n), nil
+}
+
+// Pinball computes the mean pinball loss of the predicted values as the quantile of the actuals. Actuals
+// above the prediction are penalized by the quantile and actuals below by one minus the quantile. A
+// score of 0 means a perfect match with no errors.
+func Pinball(predicted, actual []float64, quantile float64) (float64, error) {
+	if len(predicted) != len(actual) {
+		return 0, ErrResLenMismatch
+	}
+
+	var loss float64
+	var n int
+	for i := 0; i < len(actual); i++ {
+		if math.IsNaN(actual[i]) || math.IsNaN(predicted[i]) {
+			continue
+		}
+		diff := actual[i] - predicted[i]
+		if diff >= 0 {
+			loss += quantile * diff
+		} else {
+			loss -= (1 - quantile) * diff
+		}
+		n++
+	}
+	if n == 0 {
+		return 0, nil
+	}
+	return loss / float64(n), nil
+}
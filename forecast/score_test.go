@@ -0,0 +1,130 @@
+package forecast
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewScoresWithBaseline(t *testing.T) {
+	nan := math.NaN()
+
+	testData := map[string]struct {
+		predicted []float64
+		actual    []float64
+		baseline  ScoreBaseline
+		expected  *Scores
+		err       error
+	}{
+		"exact": {
+			predicted: []float64{1, 2, 3, 4},
+			actual:    []float64{1, 2, 3, 4},
+			expected:  &Scores{R2: 1},
+		},
+		"naive lag": {
+			predicted: []float64{1, 3, 3, 5},
+			actual:    []float64{2, 2, 4, 4},
+			expected: &Scores{
+				MSE:   1,
+				MAPE:  (0.5 + 0.5 + 0.25 + 0.25) / 4,
+				R2:    0,
+				MAE:   1,
+				SMAPE: (2.0/3.0 + 2.0/5.0 + 2.0/7.0 + 2.0/9.0) / 4,
+				MASE:  1.5,
+			},
+		},
+		"seasonal lag": {
+			predicted: []float64{1, 3, 3, 5},
+			actual:    []float64{2, 2, 4, 4},
+			baseline:  ScoreBaseline{SeasonalLag: 2},
+			expected: &Scores{
+				MSE:   1,
+				MAPE:  (0.5 + 0.5 + 0.25 + 0.25) / 4,
+				R2:    0,
+				MAE:   1,
+				SMAPE: (2.0/3.0 + 2.0/5.0 + 2.0/7.0 + 2.0/9.0) / 4,
+				MASE:  0.5,
+			},
+		},
+		"lag too long": {
+			predicted: []float64{1, 3, 3, 5},
+			actual:    []float64{2, 2, 4, 4},
+			baseline:  ScoreBaseline{SeasonalLag: 4},
+			expected: &Scores{
+				MSE:   1,
+				MAPE:  (0.5 + 0.5 + 0.25 + 0.25) / 4,
+				R2:    0,
+				MAE:   1,
+				SMAPE: (2.0/3.0 + 2.0/5.0 + 2.0/7.0 + 2.0/9.0) / 4,
+				MASE:  1.5,
+			},
+		},
+		"zero series": {
+			predicted: []float64{0, 1, 0, nan},
+			actual:    []float64{0, 0, 0, 0},
+			expected: &Scores{
+				MSE:   0.25,
+				R2:    math.Inf(-1),
+				MAE:   1.0 / 3.0,
+				SMAPE: 2.0 / 3.0,
+			},
+		},
+		"bands": {
+			predicted: []float64{1, 2, 3, 4},
+			actual:    []float64{1, 4, 3, 0},
+			baseline: ScoreBaseline{
+				Upper:         []float64{2, 3, 4, 5},
+				Lower:         []float64{0, 1, 2, 3},
+				IntervalLevel: 0.5,
+			},
+			expected: &Scores{
+				MSE:      5,
+				MAPE:     0.5 / 4,
+				R2:       -1,
+				MAE:      1.5,
+				SMAPE:    (4.0/6.0 + 2.0) / 4,
+				MASE:     1.5 / (7.0 / 3.0),
+				Coverage: 0.5,
+				// lower at the 0.25 quantile: 0.25, 0.75, 0.25, 2.25 and upper at the 0.75 quantile:
+				// 0.25, 0.75, 0.25, 1.25
+				Pinball: (3.5/4 + 2.5/4) / 2,
+			},
+		},
+		"mismatched predicted": {
+			predicted: []float64{1},
+			actual:    []float64{1, 2},
+			err:       ErrResLenMismatch,
+		},
+		"mismatched bands": {
+			predicted: []float64{1, 2},
+			actual:    []float64{1, 2},
+			baseline:  ScoreBaseline{Upper: []float64{2}},
+			err:       ErrResLenMismatch,
+		},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			scores, err := NewScoresWithBaseline(td.predicted, td.actual, td.baseline)
+			if td.err != nil {
+				assert.ErrorIs(t, err, td.err)
+				return
+			}
+			require.Nil(t, err)
+			assert.InDelta(t, td.expected.MSE, scores.MSE, 1e-9, "mse")
+			assert.InDelta(t, td.expected.MAPE, scores.MAPE, 1e-9, "mape")
+			if math.IsInf(td.expected.R2, 0) {
+				assert.Equal(t, td.expected.R2, scores.R2, "r2")
+			} else {
+				assert.InDelta(t, td.expected.R2, scores.R2, 1e-9, "r2")
+			}
+			assert.InDelta(t, td.expected.MAE, scores.MAE, 1e-9, "mae")
+			assert.InDelta(t, td.expected.SMAPE, scores.SMAPE, 1e-9, "smape")
+			assert.InDelta(t, td.expected.MASE, scores.MASE, 1e-9, "mase")
+			assert.InDelta(t, td.expected.Coverage, scores.Coverage, 1e-9, "coverage")
+			assert.InDelta(t, td.expected.Pinball, scores.Pinball, 1e-9, "pinball")
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package forecast
+
+import (
+	"math"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/feature"
+	"github.com/aouyang1/go-forecaster/forecast/options"
+	"github.com/aouyang1/go-forecaster/timedataset"
+	"gonum.org/v1/gonum/floats"
+)
+
+// ChangepointOffset is the fit quality of a changepoint when shifted by a number of samples
+type ChangepointOffset struct {
+	Offset int       `json:"offset"`
+	T      time.Time `json:"time"`
+	MSE    float64   `json:"mean_squared_error"`
+}
+
+// ChangepointSensitivity reports how the fit quality changes when a selected changepoint is moved
+// from its fitted placement. The window spans the contiguous shifted placements around the best
+// placement whose mean squared error stays within the configured tolerance, giving an approximate
+// confidence window on the changepoint timing.
+type ChangepointSensitivity struct {
+	Name        string              `json:"name"`
+	T           time.Time           `json:"time"`
+	BestT       time.Time           `json:"best_time"`
+	WindowStart time.Time           `json:"window_start"`
+	WindowEnd   time.Time           `json:"window_end"`
+	Offsets     []ChangepointOffset `json:"offsets"`
+}
+
+// changepointSensitivity shifts each selected changepoint by up to +/- SensitivitySamples and
+// re-estimates only that changepoint's coefficients against the partial residual while every
+// other coefficient stays fixed. This avoids refitting the full model for every placement.
+func (f *Forecast) changepointSensitivity(t []time.Time, y, predicted []float64) []ChangepointSensitivity {
+	chptOpt := f.opt.ChangepointOptions
+	k := chptOpt.SensitivitySamples
+	if !(chptOpt.Auto || chptOpt.Detect.Enabled) || k <= 0 || len(chptOpt.Changepoints) == 0 {
+		return nil
+	}
+	tol := chptOpt.SensitivityTolerance
+	if tol <= 0 {
+		tol = options.DefaultSensitivityTolerance
+	}
+
+	// only evaluate over observed points
+	tObs := make([]time.Time, 0, len(t))
+	residual := make([]float64, 0, len(t))
+	for i := 0; i < len(t); i++ {
+		if math.IsNaN(y[i]) || math.IsNaN(predicted[i]) {
+			continue
+		}
+		tObs = append(tObs, t[i])
+		residual = append(residual, y[i]-predicted[i])
+	}
+	freq, err := timedataset.TimeSlice(tObs).EstimateFreq()
+	if err != nil {
+		return nil
+	}
+
+	weights := make(map[string]float64)
+	for _, fw := range f.featureWeights {
+		feat, err := fw.ToFeature()
+		if err != nil {
+			continue
+		}
+		weights[feat.String()] = fw.Value
+	}
+
+	startTime := tObs[0]
+	res := make([]ChangepointSensitivity, 0, len(chptOpt.Changepoints))
+	for _, chpt := range chptOpt.Changepoints {
+		xb, xs := f.changepointColumns(tObs, chpt)
+
+		// partial residual with this changepoint's contribution added back in
+		partial := make([]float64, len(residual))
+		copy(partial, residual)
+		floats.AddScaled(partial, weights[feature.NewChangepoint(chpt.Name, feature.ChangepointCompBias).String()], xb)
+		if xs != nil {
+			floats.AddScaled(partial, weights[feature.NewChangepoint(chpt.Name, feature.ChangepointCompSlope).String()], xs)
+		}
+
+		sens := ChangepointSensitivity{
+			Name: chpt.Name,
+			T:    chpt.T,
+		}
+		bestIdx := -1
+		for o := -k; o <= k; o++ {
+			shifted := options.NewChangepoint(chpt.Name, chpt.T.Add(time.Duration(o)*freq))
+			if shifted.T.Before(startTime) || shifted.T.After(f.trainEndTime) {
+				continue
+			}
+			xb, xs := f.changepointColumns(tObs, shifted)
+			sens.Offsets = append(sens.Offsets, ChangepointOffset{
+				Offset: o,
+				T:      shifted.T,
+				MSE:    partialFitMSE(partial, xb, xs),
+			})
+			last := len(sens.Offsets) - 1
+			if bestIdx < 0 || sens.Offsets[last].MSE < sens.Offsets[bestIdx].MSE {
+				bestIdx = last
+			}
+		}
+		if bestIdx < 0 {
+			continue
+		}
+
+		threshold := sens.Offsets[bestIdx].MSE * (1.0 + tol)
+		lo, hi := bestIdx, bestIdx
+		for lo > 0 && sens.Offsets[lo-1].MSE <= threshold {
+			lo--
+		}
+		for hi < len(sens.Offsets)-1 && sens.Offsets[hi+1].MSE <= threshold {
+			hi++
+		}
+		sens.BestT = sens.Offsets[bestIdx].T
+		sens.WindowStart = sens.Offsets[lo].T
+		sens.WindowEnd = sens.Offsets[hi].T
+		res = append(res, sens)
+	}
+	return res
+}
+
+// changepointColumns generates the bias and, if growth is enabled, slope columns for a single
+// changepoint. The slope column is nil when growth is disabled.
+func (f *Forecast) changepointColumns(t []time.Time, chpt options.Changepoint) ([]float64, []float64) {
+	single := options.ChangepointOptions{
+		Changepoints: []options.Changepoint{chpt},
+		EnableGrowth: f.opt.ChangepointOptions.EnableGrowth,
+	}
+	feat := single.GenerateFeatures(t, f.trainEndTime)
+	xb, exists := feat.Get(feature.NewChangepoint(chpt.Name, feature.ChangepointCompBias))
+	if !exists {
+		xb = make([]float64, len(t))
+	}
+	if !single.EnableGrowth {
+		return xb, nil
+	}
+	xs, exists := feat.Get(feature.NewChangepoint(chpt.Name, feature.ChangepointCompSlope))
+	if !exists {
+		xs = make([]float64, len(t))
+	}
+	return xb, xs
+}
+
+// partialFitMSE computes the mean squared error after regressing the target on the bias and optional
+// slope columns with ordinary least squares.
+func partialFitMSE(target, xb, xs []float64) float64 {
+	var wb, ws float64
+	bb := floats.Dot(xb, xb)
+	if xs == nil {
+		if bb > 0 {
+			wb = floats.Dot(xb, target) / bb
+		}
+	} else {
+		ss := floats.Dot(xs, xs)
+		bs := floats.Dot(xb, xs)
+		det := bb*ss - bs*bs
+		yb := floats.Dot(xb, target)
+		ys := floats.Dot(xs, target)
+		switch {
+		case math.Abs(det) > 1e-12*math.Max(bb*ss, 1):
+			wb = (ss*yb - bs*ys) / det
+			ws = (bb*ys - bs*yb) / det
+		case bb > 0:
+			wb = yb / bb
+		}
+	}
+
+	var sse float64
+	for i := 0; i < len(target); i++ {
+		r := target[i] - wb*xb[i]
+		if xs != nil {
+			r -= ws * xs[i]
+		}
+		sse += r * r
+	}
+	return sse / float64(len(target))
+}
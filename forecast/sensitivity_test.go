@@ -0,0 +1,68 @@
+package forecast
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/forecast/options"
+	"github.com/aouyang1/go-forecaster/timedataset"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChangepointSensitivity(t *testing.T) {
+	n := 201
+	tWin := timedataset.GenerateT(n, time.Minute, time.Now)
+	y := timedataset.GenerateConstY(n, 5.0).
+		Add(timedataset.GenerateChange(tWin, tWin[100], 3.0, 0.0))
+
+	testData := map[string]struct {
+		samples  int
+		expected int
+	}{
+		"disabled":        {samples: 0, expected: 0},
+		"shift 3 samples": {samples: 3, expected: 7},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			opt := &options.Options{
+				ChangepointOptions: options.ChangepointOptions{
+					Auto:                true,
+					AutoNumChangepoints: 4,
+					SensitivitySamples:  td.samples,
+				},
+			}
+			f, err := New(opt)
+			require.Nil(t, err)
+			require.Nil(t, f.Fit(tWin, y))
+
+			sens := f.ChangepointSensitivity()
+			if td.expected == 0 {
+				assert.Empty(t, sens)
+				return
+			}
+
+			var found bool
+			for _, s := range sens {
+				if s.Name != "auto_2" {
+					continue
+				}
+				found = true
+				assert.Equal(t, tWin[100], s.T)
+				assert.Equal(t, tWin[100], s.BestT)
+				assert.Equal(t, tWin[100], s.WindowStart)
+				assert.Equal(t, tWin[100], s.WindowEnd)
+				assert.Len(t, s.Offsets, td.expected)
+				for _, o := range s.Offsets {
+					if o.Offset == 0 {
+						assert.InDelta(t, 0.0, o.MSE, 1e-6)
+						continue
+					}
+					assert.Greater(t, o.MSE, 0.01)
+				}
+			}
+			assert.True(t, found)
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package forecast
+
+import (
+	"math"
+
+	"github.com/aouyang1/go-forecaster/feature"
+	"github.com/aouyang1/go-forecaster/forecast/options"
+	mat_ "github.com/aouyang1/go-forecaster/mat"
+	"github.com/aouyang1/go-forecaster/models"
+	"gonum.org/v1/gonum/mat"
+)
+
+// smoothTrendColumns returns the column of every smooth changepoint feature in the feature matrix
+// where the intercept is the first column
+func (f *Forecast) smoothTrendColumns(labels []feature.Feature) []int {
+	smooth := make(map[string]struct{})
+	for _, chpt := range f.opt.ChangepointOptions.Changepoints {
+		if chpt.Smooth {
+			smooth[chpt.Name] = struct{}{}
+		}
+	}
+	if len(smooth) == 0 {
+		return nil
+	}
+
+	var cols []int
+	for i, label := range labels {
+		if label.Type() != feature.FeatureTypeChangepoint {
+			continue
+		}
+		name, _ := label.Get("name")
+		if _, exists := smooth[name]; exists {
+			cols = append(cols, i+1)
+		}
+	}
+	return cols
+}
+
+// appendRidgeRows appends a pseudo observation per column with a value of sqrt(penalty) in that column
+// and a target of 0 which adds a ridge penalty of 0.5*penalty*b^2 on the coefficient to any least
+// squares objective
+func appendRidgeRows(features, target mat.Matrix, cols []int, penalty float64) (mat.Matrix, mat.Matrix) {
+	m, n := features.Dims()
+	if csr, ok := features.(*mat_.CSR); ok {
+		return appendSparseRidgeRows(csr, target, cols, penalty)
+	}
+	augFeatures := mat.NewDense(m+len(cols), n, nil)
+	augFeatures.Slice(0, m, 0, n).(*mat.Dense).Copy(features)
+	augTarget := mat.NewDense(m+len(cols), 1, nil)
+	augTarget.Slice(0, m, 0, 1).(*mat.Dense).Copy(target)
+
+	scale := math.Sqrt(penalty)
+	for i, col := range cols {
+		augFeatures.Set(m+i, col, scale)
+	}
+	return augFeatures, augTarget
+}
+
+// appendSparseRidgeRows appends the pseudo observations of appendRidgeRows to a sparse design matrix
+// with a single non zero value per row
+func appendSparseRidgeRows(features *mat_.CSR, target mat.Matrix, cols []int, penalty float64) (mat.Matrix, mat.Matrix) {
+	m, n := features.Dims()
+	indptr := make([]int, len(cols)+1)
+	data := make([]float64, len(cols))
+	scale := math.Sqrt(penalty)
+	for i := range cols {
+		indptr[i+1] = i + 1
+		data[i] = scale
+	}
+	// every pseudo observation has a single value in an existing column so the structure is valid
+	ridge, _ := mat_.NewCSR(len(cols), n, indptr, cols, data)
+	augFeatures, _ := features.Stack(ridge)
+
+	augTarget := mat.NewDense(m+len(cols), 1, nil)
+	augTarget.Slice(0, m, 0, 1).(*mat.Dense).Copy(target)
+	return augFeatures, augTarget
+}
+
+// addRidgeDiagonal adds the ridge penalty of each column to the diagonal of the gram matrix which is
+// equivalent to appending the pseudo observations of appendRidgeRows
+func addRidgeDiagonal(g *models.Gram, cols []int, penalty float64) {
+	for _, col := range cols {
+		g.XTX.SetSym(col, col, g.XTX.At(col, col)+penalty)
+	}
+}
+
+// newRegressionModel initializes the configured regression backend. The smooth changepoint columns are
+// excluded from the L1 penalty of the lasso so they are only ridge penalized.
+func (f *Forecast) newRegressionModel(smoothCols []int, numCols int, warmStart []float64) (models.Regressor, error) {
+	if (len(smoothCols) == 0 && warmStart == nil) || (f.opt.Regression != "" && f.opt.Regression != options.RegressionLasso) {
+		return f.opt.NewRegressionModel()
+	}
+	lassoOpt := f.opt.NewLassoAutoOptions()
+	lassoOpt.WarmStartBeta = warmStart
+	if len(smoothCols) > 0 {
+		lassoOpt.PenaltyWeights = make([]float64, numCols)
+		for i := range lassoOpt.PenaltyWeights {
+			lassoOpt.PenaltyWeights[i] = 1.0
+		}
+		for _, col := range smoothCols {
+			lassoOpt.PenaltyWeights[col] = 0.0
+		}
+	}
+	return models.NewLassoAutoRegression(lassoOpt)
+}
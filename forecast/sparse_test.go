@@ -0,0 +1,127 @@
+package forecast
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/forecast/options"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFitMatrixBackend(t *testing.T) {
+	n := 7 * 24 * 6
+	ct := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tWin := make([]time.Time, 0, n)
+	y := make([]float64, 0, n)
+	weights := make([]float64, 0, n)
+	for i := 0; i < n; i++ {
+		tPnt := ct.Add(time.Duration(i) * 10 * time.Minute)
+		tWin = append(tWin, tPnt)
+		val := 5.0 + 3.0*math.Sin(2.0*math.Pi*tPnt.Sub(ct).Seconds()/86400.0)
+		if tPnt.After(ct.Add(3*24*time.Hour)) && tPnt.Before(ct.Add(3*24*time.Hour+4*time.Hour)) {
+			val += 10.0
+		}
+		if tPnt.After(ct.Add(5 * 24 * time.Hour)) {
+			val -= 2.0
+		}
+		y = append(y, val)
+		weights = append(weights, 1.0+float64(i%3))
+	}
+
+	newOpt := func(backend options.MatrixBackend, trendMode options.TrendMode) *options.Options {
+		opt := options.NewDefaultOptions()
+		opt.SeasonalityOptions.SeasonalityConfigs = []options.SeasonalityConfig{
+			options.NewDailySeasonalityConfig(2),
+		}
+		opt.EventOptions.Events = []options.Event{
+			options.NewEvent("outage", ct.Add(3*24*time.Hour), ct.Add(3*24*time.Hour+4*time.Hour)),
+		}
+		opt.ChangepointOptions.Changepoints = []options.Changepoint{
+			options.NewChangepoint("drop", ct.Add(5*24*time.Hour)),
+		}
+		opt.ChangepointOptions.TrendMode = trendMode
+		opt.MatrixBackend = backend
+		return opt
+	}
+
+	testData := map[string]struct {
+		backend   options.MatrixBackend
+		trendMode options.TrendMode
+		weights   []float64
+		err       error
+	}{
+		"sparse":          {backend: options.MatrixBackendSparse},
+		"auto":            {backend: options.MatrixBackendAuto},
+		"sparse weighted": {backend: options.MatrixBackendSparse, weights: weights},
+		"sparse smooth":   {backend: options.MatrixBackendSparse, trendMode: options.TrendModeSmooth},
+		"unknown":         {backend: "csc", err: options.ErrUnknownMatrixBackend},
+	}
+
+	fit := func(f *Forecast, weights []float64) error {
+		if weights == nil {
+			return f.Fit(tWin, y)
+		}
+		return f.FitWeighted(tWin, y, weights)
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			f, err := New(newOpt(options.MatrixBackendDense, td.trendMode))
+			require.Nil(t, err)
+			require.Nil(t, fit(f, td.weights))
+
+			fSparse, err := New(newOpt(td.backend, td.trendMode))
+			require.Nil(t, err)
+			err = fit(fSparse, td.weights)
+			if td.err != nil {
+				assert.ErrorIs(t, err, td.err)
+				return
+			}
+			require.Nil(t, err)
+
+			assert.InDelta(t, f.Intercept(), fSparse.Intercept(), 1e-6)
+			expected, err := f.Coefficients()
+			require.Nil(t, err)
+			coef, err := fSparse.Coefficients()
+			require.Nil(t, err)
+			assert.Equal(t, len(expected), len(coef))
+			for label, val := range expected {
+				assert.InDelta(t, val, coef[label], 1e-6, label)
+			}
+		})
+	}
+}
+
+func TestOptionsSparse(t *testing.T) {
+	testData := map[string]struct {
+		backend     options.MatrixBackend
+		regression  options.Regression
+		standardize bool
+		density     float64
+		expected    bool
+	}{
+		"default":          {density: 0.01},
+		"dense":            {backend: options.MatrixBackendDense, density: 0.01},
+		"sparse":           {backend: options.MatrixBackendSparse, density: 0.9, expected: true},
+		"auto sparse":      {backend: options.MatrixBackendAuto, density: 0.1, expected: true},
+		"auto dense":       {backend: options.MatrixBackendAuto, density: 0.5},
+		"lasso":            {backend: options.MatrixBackendSparse, regression: options.RegressionLasso, expected: true},
+		"ridge":            {backend: options.MatrixBackendSparse, regression: options.RegressionRidge},
+		"standardize":      {backend: options.MatrixBackendSparse, standardize: true},
+		"auto standardize": {backend: options.MatrixBackendAuto, standardize: true},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			opt := options.NewDefaultOptions()
+			opt.MatrixBackend = td.backend
+			opt.Regression = td.regression
+			opt.StandardizeFeatures = td.standardize
+			sparse, err := opt.Sparse(td.density)
+			require.Nil(t, err)
+			assert.Equal(t, td.expected, sparse)
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package forecast
+
+import (
+	"math"
+
+	"github.com/aouyang1/go-forecaster/models"
+	"gonum.org/v1/gonum/mat"
+)
+
+// featureScale is the center and scale of every column of a design matrix with the constant column
+// first. Standardized columns are the raw columns minus the center times the constant column divided
+// by the scale. Unstandardized columns including the constant have a zero center and unit scale.
+type featureScale struct {
+	center []float64
+	scale  []float64
+}
+
+// newFeatureScale computes the mean and standard deviation of every column of the observations
+// skipping the constant column, the skipped columns and columns without variance
+func newFeatureScale(features mat.Matrix, skip []int) featureScale {
+	m, n := features.Dims()
+	s := identityScale(n)
+	for j := 1; j < n; j++ {
+		var sum, sqSum float64
+		for i := 0; i < m; i++ {
+			v := features.At(i, j)
+			sum += v
+			sqSum += v * v
+		}
+		s.set(j, sum, sqSum, float64(m))
+	}
+	s.skip(skip)
+	return s
+}
+
+// newGramFeatureScale computes the mean and standard deviation of every column from the sufficient
+// statistics of the observations where the first column is the constant
+func newGramFeatureScale(g *models.Gram, skip []int) featureScale {
+	n := g.Features()
+	s := identityScale(n)
+	count := g.XTX.At(0, 0)
+	for j := 1; j < n; j++ {
+		s.set(j, g.XTX.At(0, j), g.XTX.At(j, j), count)
+	}
+	s.skip(skip)
+	return s
+}
+
+func identityScale(n int) featureScale {
+	s := featureScale{
+		center: make([]float64, n),
+		scale:  make([]float64, n),
+	}
+	for j := range s.scale {
+		s.scale[j] = 1.0
+	}
+	return s
+}
+
+// set standardizes the column by the mean and standard deviation of its sum and sum of squares if
+// it has variance
+func (s featureScale) set(j int, sum, sqSum, count float64) {
+	if count == 0 {
+		return
+	}
+	mean := sum / count
+	std := math.Sqrt(math.Max(sqSum/count-mean*mean, 0))
+	if std < 1e-12 {
+		return
+	}
+	s.center[j] = mean
+	s.scale[j] = std
+}
+
+func (s featureScale) skip(cols []int) {
+	for _, j := range cols {
+		s.center[j] = 0
+		s.scale[j] = 1.0
+	}
+}
+
+// transform returns the standardized columns of the features
+func (s featureScale) transform(features mat.Matrix) *mat.Dense {
+	var out mat.Dense
+	out.Apply(func(i, j int, v float64) float64 {
+		return (v - s.center[j]*features.At(i, 0)) / s.scale[j]
+	}, features)
+	return &out
+}
+
+// transformGram returns the sufficient statistics of the standardized columns
+func (s featureScale) transformGram(g *models.Gram) *models.Gram {
+	n := g.Features()
+	a := s.matrix()
+	var xtx mat.Dense
+	xtx.Product(a.T(), g.XTX, a)
+	out := &models.Gram{
+		XTX:  mat.NewSymDense(n, nil),
+		XTy:  make([]float64, n),
+		YTy:  g.YTy,
+		YSum: g.YSum,
+		N:    g.N,
+	}
+	for j := 0; j < n; j++ {
+		for k := j; k < n; k++ {
+			out.XTX.SetSym(j, k, xtx.At(j, k))
+		}
+	}
+	mat.NewVecDense(n, out.XTy).MulVec(a.T(), mat.NewVecDense(n, g.XTy))
+	return out
+}
+
+// matrix returns the linear map from the raw to the standardized columns
+func (s featureScale) matrix() *mat.Dense {
+	n := len(s.scale)
+	a := mat.NewDense(n, n, nil)
+	a.Set(0, 0, 1.0)
+	for j := 1; j < n; j++ {
+		a.Set(j, j, 1/s.scale[j])
+		a.Set(0, j, -s.center[j]/s.scale[j])
+	}
+	return a
+}
+
+// unscale converts coefficients of the standardized columns to coefficients of the raw columns
+func (s featureScale) unscale(coef []float64) []float64 {
+	raw := make([]float64, len(coef))
+	copy(raw, coef)
+	for j := 1; j < len(coef) && j < len(s.scale); j++ {
+		raw[j] = coef[j] / s.scale[j]
+		raw[0] -= s.center[j] * raw[j]
+	}
+	return raw
+}
+
+// rescale converts coefficients of the raw columns to coefficients of the standardized columns
+func (s featureScale) rescale(coef []float64) []float64 {
+	if coef == nil {
+		return nil
+	}
+	std := make([]float64, len(coef))
+	copy(std, coef)
+	for j := 1; j < len(coef) && j < len(s.scale); j++ {
+		std[j] = coef[j] * s.scale[j]
+		std[0] += s.center[j] * coef[j]
+	}
+	return std
+}
+
+// unscalePath converts the coefficients of every point of a regularization path to coefficients of the
+// raw columns
+func (s featureScale) unscalePath(path []models.PathPoint) []models.PathPoint {
+	if path == nil {
+		return nil
+	}
+	raw := make([]models.PathPoint, len(path))
+	for i, pnt := range path {
+		raw[i] = pnt
+		raw[i].Coef = s.unscale(pnt.Coef)
+	}
+	return raw
+}
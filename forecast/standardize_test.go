@@ -0,0 +1,116 @@
+package forecast
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/forecast/options"
+	"github.com/aouyang1/go-forecaster/models"
+	"github.com/aouyang1/go-forecaster/timedataset"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestFeatureScale(t *testing.T) {
+	features := mat.NewDense(4, 4, []float64{
+		1, 1, 10, 3,
+		1, 2, 20, 3,
+		1, 3, 40, 3,
+		1, 4, 50, 3,
+	})
+	target := mat.NewDense(4, 1, []float64{1, 2, 4, 3})
+
+	// the constant column is never scaled and neither are skipped or constant columns
+	scale := newFeatureScale(features, []int{2})
+	assert.Equal(t, []float64{0, 2.5, 0, 0}, scale.center)
+	assert.InDelta(t, math.Sqrt(1.25), scale.scale[1], 1e-12)
+	assert.Equal(t, []float64{1, 1}, scale.scale[2:])
+
+	gram := models.NewGram(4)
+	require.Nil(t, gram.Add(features, target))
+	assert.Equal(t, scale, newGramFeatureScale(gram, []int{2}))
+
+	scale = newFeatureScale(features, nil)
+	std := scale.transform(features)
+	for j := 1; j < 3; j++ {
+		col := mat.Col(nil, j, std)
+		assert.InDelta(t, 0.0, floats.Sum(col), 1e-9)
+		assert.InDelta(t, 4.0, floats.Dot(col, col), 1e-9)
+	}
+
+	// predictions of the standardized coefficients match the unscaled coefficients on the raw features
+	coef := []float64{0.5, -1.0, 2.0, 0.25}
+	raw := scale.unscale(coef)
+	var stdPred, rawPred mat.VecDense
+	stdPred.MulVec(std, mat.NewVecDense(4, coef))
+	rawPred.MulVec(features, mat.NewVecDense(4, raw))
+	assert.InDeltaSlice(t, stdPred.RawVector().Data, rawPred.RawVector().Data, 1e-9)
+	assert.InDeltaSlice(t, coef, scale.rescale(raw), 1e-9)
+
+	stdGram := models.NewGram(4)
+	require.Nil(t, stdGram.Add(std, target))
+	transformed := scale.transformGram(gram)
+	assert.True(t, mat.EqualApprox(stdGram.XTX, transformed.XTX, 1e-9))
+	assert.InDeltaSlice(t, stdGram.XTy, transformed.XTy, 1e-9)
+}
+
+func TestFitStandardizeFeatures(t *testing.T) {
+	n := 3 * 24 * 6
+	ct := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tWin := make([]time.Time, 0, n)
+	y := make([]float64, 0, n)
+	for i := 0; i < n; i++ {
+		tPnt := ct.Add(time.Duration(i) * 10 * time.Minute)
+		tWin = append(tWin, tPnt)
+		y = append(y, 5.0+3.0*math.Sin(2.0*math.Pi*tPnt.Sub(ct).Seconds()/86400.0))
+	}
+
+	newOpt := func(standardize bool) *options.Options {
+		opt := options.NewDefaultOptions()
+		opt.SeasonalityOptions.SeasonalityConfigs = []options.SeasonalityConfig{
+			options.NewDailySeasonalityConfig(2),
+		}
+		opt.StandardizeFeatures = standardize
+		return opt
+	}
+
+	f, err := New(newOpt(false))
+	require.Nil(t, err)
+	require.Nil(t, f.Fit(tWin, y))
+
+	fStd, err := New(newOpt(true))
+	require.Nil(t, err)
+	require.Nil(t, fStd.Fit(tWin, y))
+
+	fStream, err := New(newOpt(true))
+	require.Nil(t, err)
+	src := func() (timedataset.ChunkReader, error) {
+		return timedataset.NewSliceChunkReader(&timedataset.TimeDataset{T: tWin, Y: y}, 100), nil
+	}
+	require.Nil(t, fStream.FitStream(src))
+
+	// an unregularized fit is unaffected by standardization
+	expected, err := f.Coefficients()
+	require.Nil(t, err)
+	for _, res := range []*Forecast{fStd, fStream} {
+		assert.InDelta(t, f.Intercept(), res.Intercept(), 1e-3)
+		coef, err := res.Coefficients()
+		require.Nil(t, err)
+		for label, val := range expected {
+			assert.InDelta(t, val, coef[label], 1e-3, label)
+		}
+	}
+
+	// the coefficients are stored unscaled so a loaded model predicts the same
+	m, err := fStd.Model()
+	require.Nil(t, err)
+	assert.True(t, m.Options.StandardizeFeatures)
+	loaded, err := NewFromModel(m)
+	require.Nil(t, err)
+	pred, _, err := loaded.Predict(tWin)
+	require.Nil(t, err)
+	assert.InDeltaSlice(t, y, pred, 1e-3)
+}
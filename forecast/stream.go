@@ -0,0 +1,285 @@
+package forecast
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/errs"
+	"github.com/aouyang1/go-forecaster/feature"
+	"github.com/aouyang1/go-forecaster/forecast/options"
+	"github.com/aouyang1/go-forecaster/models"
+	"github.com/aouyang1/go-forecaster/timedataset"
+	"gonum.org/v1/gonum/mat"
+)
+
+var (
+	ErrUnexpectedChunkFeature = errs.NewDataError(errs.CodeDimensionMismatch, "chunk generated a feature not present in the first chunk", nil)
+	ErrRegressorNoGram        = errs.NewConfigError(errs.CodeInvalidOption, "regressor cannot be fit from sufficient statistics for streaming", nil)
+)
+
+// FitStream fits a forecast model from a chunked data source without materializing the full design
+// matrix. The source is read three times: once to find the training time range, once to accumulate
+// the gram matrix of the features, and once to compute the fit scores. Memory usage is bounded by the
+// chunk size and the number of features squared. Residuals, training components, changepoint
+// sensitivity, and the training data are not retained since they are as long as the training data.
+// Seasonality detection is not run since it requires the full training series.
+func (f *Forecast) FitStream(src timedataset.ChunkSource) error {
+	if f == nil {
+		return ErrUninitializedForecast
+	}
+
+	if err := f.opt.ChangepointOptions.ResolveAnchors(f.opt.EventOptions.Events); err != nil {
+		return err
+	}
+	if err := f.opt.EventOptions.Holidays.Validate(); err != nil {
+		return err
+	}
+
+	// first pass finds the training time range to place changepoints and seasonality pruning
+	var startTime, endTime, lastTime time.Time
+	var numObs int
+	err := forEachChunk(src, func(chunk *timedataset.TimeDataset) error {
+		if chunk.Len() != len(chunk.Y) {
+			return ErrMismatchedDataLen
+		}
+		for i, tPnt := range chunk.T {
+			if !lastTime.IsZero() && !tPnt.After(lastTime) {
+				return timedataset.ErrNonMontonic
+			}
+			lastTime = tPnt
+			if math.IsNaN(chunk.Y[i]) {
+				continue
+			}
+			if startTime.IsZero() {
+				startTime = tPnt
+			}
+			endTime = tPnt
+			numObs++
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if numObs <= 1 {
+		return ErrInsufficientTrainingData
+	}
+
+	f.trainEndTime = endTime
+	bounds := f.opt.DSTOptions.AdjustTime([]time.Time{startTime, endTime})
+	dropSeas := droppedSeasonalities(bounds[0], bounds[1])
+	f.opt.ChangepointOptions.GenerateAutoChangepoints(bounds)
+	f.opt.ChangepointOptions.GenerateSmoothChangepoints(bounds)
+
+	// second pass accumulates the sufficient statistics using the feature columns of the first chunk
+	var labels []feature.Feature
+	var gram *models.Gram
+	err = forEachChunk(src, func(chunk *timedataset.TimeDataset) error {
+		chunk = chunk.DropNan()
+		if chunk.Len() == 0 {
+			return nil
+		}
+		x, err := f.generateUnprunedFeatures(f.opt.DSTOptions.AdjustTime(chunk.T), dropSeas)
+		if err != nil {
+			return err
+		}
+		if gram == nil {
+			labels = x.Labels()
+			gram = models.NewGram(len(labels) + 1)
+		}
+		features, err := alignedMatrix(x, labels, chunk.Len())
+		if err != nil {
+			return err
+		}
+		return gram.Add(features, mat.NewDense(chunk.Len(), 1, chunk.Y))
+	})
+	if err != nil {
+		return err
+	}
+
+	// drop zero only features which have no variance in the gram matrix keeping the intercept
+	idx := []int{0}
+	nonZeroLabels := make([]feature.Feature, 0, len(labels))
+	for i, label := range labels {
+		if gram.XTX.At(i+1, i+1) == 0 {
+			continue
+		}
+		idx = append(idx, i+1)
+		nonZeroLabels = append(nonZeroLabels, label)
+	}
+
+	// standardized features are fit and the coefficients are unscaled to the raw features
+	subset := gram.Subset(idx)
+	smoothCols := f.smoothTrendColumns(nonZeroLabels)
+	scale := identityScale(len(idx))
+	if f.opt.StandardizeFeatures {
+		scale = newGramFeatureScale(subset, smoothCols)
+		subset = scale.transformGram(subset)
+	}
+
+	// smooth trend steps are ridge penalized on the diagonal of the gram matrix
+	if len(smoothCols) > 0 {
+		penalty, err := f.opt.ChangepointOptions.SmoothTrendPenalty(numObs)
+		if err != nil {
+			return err
+		}
+		addRidgeDiagonal(subset, smoothCols, penalty)
+	}
+
+	// run the penalized regression
+	model, err := f.newRegressionModel(smoothCols, subset.Features(), scale.rescale(f.warmStartBeta(nonZeroLabels)))
+	if err != nil {
+		return err
+	}
+	gramModel, ok := model.(models.GramModel)
+	if !ok {
+		return fmt.Errorf("%q, %w", f.opt.Regression, ErrRegressorNoGram)
+	}
+	if err := gramModel.FitGram(subset); err != nil {
+		return err
+	}
+	coefPath := newCoefficientPath(nonZeroLabels, scale.unscalePath(regressionPath(model)))
+	coef := scale.unscale(model.Coef())
+	intercept := 0.0
+	if len(coef) > 0 {
+		intercept = coef[0]
+	}
+	if len(coef) > 1 {
+		coef = coef[1:]
+	}
+	f.trained = true
+
+	f.intercept = intercept
+
+	relevantFws, relevantChpts, err := f.pruneDegenerateFeatures(nonZeroLabels, coef)
+	if err != nil {
+		return err
+	}
+	f.featureWeights = relevantFws
+	f.opt.ChangepointOptions.Changepoints = relevantChpts
+
+	// third pass computes the fit scores over the training data including NaNs. The seasonal naive
+	// baseline of the scaled error is lagged by the seasonal lag inferred from the first chunk.
+	var total int
+	var sse, ape, ae, sape, naiveAE, ySum, ySqSum float64
+	var scored, naiveScored int
+	var lagged []float64
+	err = forEachChunk(src, func(chunk *timedataset.TimeDataset) error {
+		predicted, _, err := f.Predict(chunk.T)
+		if err != nil {
+			return err
+		}
+		if lagged == nil {
+			lagged = make([]float64, 0, f.SeasonalLag(chunk.T))
+		}
+		total += chunk.Len()
+		for i, actual := range chunk.Y {
+			if len(lagged) == cap(lagged) {
+				if prev := lagged[0]; !math.IsNaN(actual) && !math.IsNaN(prev) {
+					naiveAE += math.Abs(actual - prev)
+					naiveScored++
+				}
+				lagged = append(lagged[:0], lagged[1:]...)
+			}
+			lagged = append(lagged, actual)
+
+			if math.IsNaN(actual) || math.IsNaN(predicted[i]) {
+				continue
+			}
+			diff := actual - predicted[i]
+			sse += diff * diff
+			ae += math.Abs(diff)
+			if actual != 0 {
+				ape += math.Abs(diff / actual)
+			}
+			if denom := math.Abs(actual) + math.Abs(predicted[i]); denom != 0 {
+				sape += 2 * math.Abs(diff) / denom
+			}
+			ySum += actual
+			ySqSum += actual * actual
+			scored++
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	r2 := 1.0
+	if sst := ySqSum - ySum*ySum/float64(scored); sst > 0 {
+		r2 = 1.0 - sse/sst
+	}
+	f.scores = &Scores{
+		MSE:   sse / float64(total),
+		MAPE:  ape / float64(total),
+		R2:    r2,
+		MAE:   ae / float64(scored),
+		SMAPE: sape / float64(scored),
+	}
+	if naiveAE > 0 {
+		f.scores.MASE = f.scores.MAE / (naiveAE / float64(naiveScored))
+	}
+	f.residual = nil
+	f.trainComponents = Components{}
+	f.chptSensitivity = nil
+	f.fastPath = options.FastPathNone
+	f.trainingMatrix = nil
+	f.weights = nil
+	f.redundantFeatures = nil
+	f.coefPath = coefPath
+
+	return nil
+}
+
+// forEachChunk opens a new reader from the source and calls fn on every chunk until exhausted
+func forEachChunk(src timedataset.ChunkSource, fn func(*timedataset.TimeDataset) error) error {
+	r, err := src()
+	if err != nil {
+		return fmt.Errorf("unable to open chunk reader, %w", err)
+	}
+	for {
+		chunk, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("unable to read chunk, %w", err)
+		}
+		if err := fn(chunk); err != nil {
+			return err
+		}
+	}
+}
+
+// alignedMatrix builds the design matrix with an intercept column using the input label ordering.
+// Labels missing from the feature set are filled with zeroes.
+func alignedMatrix(x *feature.Set, labels []feature.Feature, m int) (*mat.Dense, error) {
+	known := make(map[string]struct{}, len(labels))
+	for _, label := range labels {
+		known[label.String()] = struct{}{}
+	}
+	for _, label := range x.Labels() {
+		if _, exists := known[label.String()]; !exists {
+			return nil, fmt.Errorf("%q, %w", label.String(), ErrUnexpectedChunkFeature)
+		}
+	}
+
+	n := len(labels) + 1
+	obs := make([]float64, m*n)
+	for i := 0; i < m; i++ {
+		obs[n*i] = 1.0
+	}
+	for j, label := range labels {
+		data, exists := x.Get(label)
+		if !exists {
+			continue
+		}
+		for i, pnt := range data {
+			obs[n*i+j+1] = pnt
+		}
+	}
+	return mat.NewDense(m, n, obs), nil
+}
@@ -0,0 +1,83 @@
+package forecast
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/forecast/options"
+	"github.com/aouyang1/go-forecaster/timedataset"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFitStream(t *testing.T) {
+	// daily sine wave at minutely over two days with a level shift and missing points
+	minutes := 2 * 24 * 60
+	tWin := make([]time.Time, 0, minutes)
+	ct := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	y := make([]float64, 0, minutes)
+	for i := 0; i < minutes; i++ {
+		tWin = append(tWin, ct.Add(time.Duration(i)*time.Minute))
+		val := 7.9 + 4.3*math.Sin(2.0*math.Pi/86400.0*float64(tWin[i].Unix()))
+		if i >= minutes/2 {
+			val += 3.0
+		}
+		if i%97 == 0 {
+			val = math.NaN()
+		}
+		y = append(y, val)
+	}
+
+	newOpt := func() *options.Options {
+		opt := options.NewDefaultOptions()
+		opt.SeasonalityOptions.SeasonalityConfigs = []options.SeasonalityConfig{
+			options.NewDailySeasonalityConfig(2),
+		}
+		opt.ChangepointOptions.Auto = true
+		opt.ChangepointOptions.AutoNumChangepoints = 4
+		return opt
+	}
+
+	f, err := New(newOpt())
+	require.Nil(t, err)
+	require.Nil(t, f.Fit(tWin, y))
+
+	fStream, err := New(newOpt())
+	require.Nil(t, err)
+	src := func() (timedataset.ChunkReader, error) {
+		return timedataset.NewSliceChunkReader(&timedataset.TimeDataset{T: tWin, Y: y}, 500), nil
+	}
+	require.Nil(t, fStream.FitStream(src))
+
+	expected, err := f.Coefficients()
+	require.Nil(t, err)
+	res, err := fStream.Coefficients()
+	require.Nil(t, err)
+
+	assert.InDelta(t, f.Intercept(), fStream.Intercept(), 1e-3)
+	for label, val := range expected {
+		assert.InDelta(t, val, res[label], 1e-3, label)
+	}
+	assert.InDelta(t, f.Scores().MSE, fStream.Scores().MSE, 1e-6)
+	assert.InDelta(t, f.Scores().MAPE, fStream.Scores().MAPE, 1e-6)
+	assert.InDelta(t, f.Scores().R2, fStream.Scores().R2, 1e-6)
+	assert.InDelta(t, f.Scores().MAE, fStream.Scores().MAE, 1e-6)
+	assert.InDelta(t, f.Scores().SMAPE, fStream.Scores().SMAPE, 1e-6)
+	assert.InDelta(t, f.Scores().MASE, fStream.Scores().MASE, 1e-6)
+	assert.Empty(t, fStream.Residuals())
+}
+
+func TestFitStreamInsufficientData(t *testing.T) {
+	f, err := New(nil)
+	require.Nil(t, err)
+
+	src := func() (timedataset.ChunkReader, error) {
+		td := &timedataset.TimeDataset{
+			T: []time.Time{time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+			Y: []float64{1},
+		}
+		return timedataset.NewSliceChunkReader(td, 1), nil
+	}
+	assert.ErrorIs(t, f.FitStream(src), ErrInsufficientTrainingData)
+}
@@ -0,0 +1,51 @@
+package forecast
+
+import (
+	"math"
+	"time"
+)
+
+// maskStructuralZeros returns a copy of the values with every structural zero replaced with NaN so
+// that they are excluded from fitting, scoring, and residuals
+func maskStructuralZeros(y []float64, mask []bool) []float64 {
+	res := make([]float64, len(y))
+	copy(res, y)
+	for i, zero := range mask {
+		if zero {
+			res[i] = math.NaN()
+		}
+	}
+	return res
+}
+
+// forceStructuralZeros sets the prediction and every component to exactly zero at each structural zero
+func forceStructuralZeros(res []float64, comp *Components, mask []bool) {
+	for i, zero := range mask {
+		if !zero {
+			continue
+		}
+		res[i] = 0
+		for _, c := range [][]float64{comp.Trend, comp.Seasonality, comp.Event, comp.Custom, comp.Regressor} {
+			if i < len(c) {
+				c[i] = 0
+			}
+		}
+		for _, fc := range comp.Features {
+			if i < len(fc.Values) {
+				fc.Values[i] = 0
+			}
+		}
+		if i < len(comp.NaNReasons) {
+			comp.NaNReasons[i] = ""
+		}
+	}
+}
+
+// StructuralZeros returns true for every input time falling in a declared or detected structural zero
+// window or nil if there are none
+func (f *Forecast) StructuralZeros(t []time.Time) []bool {
+	if f == nil || f.opt == nil {
+		return nil
+	}
+	return f.opt.StructuralZeroMask(t)
+}
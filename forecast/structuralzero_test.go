@@ -0,0 +1,100 @@
+package forecast
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/forecast/options"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFitStructuralZeros(t *testing.T) {
+	// two weeks of an hourly batch series starting on a monday which never runs on weekends
+	ct := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	n := 14 * 24
+	tWin := make([]time.Time, 0, n)
+	for i := 0; i < n; i++ {
+		tWin = append(tWin, ct.Add(time.Duration(i)*time.Hour))
+	}
+	outageStart := ct.Add(2 * 24 * time.Hour)
+	outageEnd := outageStart.Add(6 * time.Hour)
+
+	isWeekend := func(tPnt time.Time) bool {
+		return tPnt.Weekday() == time.Saturday || tPnt.Weekday() == time.Sunday
+	}
+	inOutage := func(tPnt time.Time) bool {
+		return !tPnt.Before(outageStart) && tPnt.Before(outageEnd)
+	}
+	series := func(zero func(time.Time) bool) []float64 {
+		y := make([]float64, n)
+		for i, tPnt := range tWin {
+			if zero(tPnt) {
+				continue
+			}
+			y[i] = 10.0 + 2.0*math.Sin(2.0*math.Pi*float64(tPnt.Hour())/24.0)
+		}
+		return y
+	}
+
+	testData := map[string]struct {
+		szOpt    options.StructuralZeroOptions
+		y        []float64
+		expected func(time.Time) bool
+	}{
+		"declared weekends": {
+			szOpt:    options.StructuralZeroOptions{Weekends: true},
+			y:        series(isWeekend),
+			expected: isWeekend,
+		},
+		"detected weekends": {
+			szOpt:    options.StructuralZeroOptions{DetectWeekends: true},
+			y:        series(isWeekend),
+			expected: isWeekend,
+		},
+		"weekends not detected": {
+			szOpt:    options.StructuralZeroOptions{DetectWeekends: true},
+			y:        series(func(time.Time) bool { return false }),
+			expected: func(time.Time) bool { return false },
+		},
+		"declared event": {
+			szOpt:    options.StructuralZeroOptions{Events: []string{"outage"}},
+			y:        series(inOutage),
+			expected: inOutage,
+		},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			opt := options.NewDefaultOptions()
+			opt.SeasonalityOptions.SeasonalityConfigs = []options.SeasonalityConfig{
+				options.NewDailySeasonalityConfig(2),
+			}
+			opt.EventOptions.Events = []options.Event{
+				options.NewEvent("outage", outageStart, outageEnd),
+			}
+			opt.StructuralZeroOptions = td.szOpt
+
+			f, err := New(opt)
+			require.Nil(t, err)
+			require.Nil(t, f.Fit(tWin, td.y))
+
+			mask := f.StructuralZeros(tWin)
+			predicted, comp, err := f.Predict(tWin)
+			require.Nil(t, err)
+			residual := f.Residuals()
+			for i, tPnt := range tWin {
+				zero := td.expected(tPnt)
+				assert.Equal(t, zero, mask != nil && mask[i], "time %s", tPnt)
+				if zero {
+					assert.Equal(t, 0.0, predicted[i], "time %s", tPnt)
+					assert.Equal(t, 0.0, comp.Trend[i], "time %s", tPnt)
+					assert.True(t, math.IsNaN(residual[i]), "time %s", tPnt)
+					continue
+				}
+				assert.InDelta(t, td.y[i], predicted[i], 0.1, "time %s", tPnt)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package util
+
+import "reflect"
+
+// DeepCopy returns a copy of the value sharing no pointers, slices, or maps with the input. Unlike a
+// json round trip, fields that are not serialized such as callbacks are kept. Functions, channels, and
+// interface values are shared, as are structs with unexported fields like time.Time which are copied
+// as a whole value.
+func DeepCopy[T any](v T) T {
+	return DeepCopyValue(reflect.ValueOf(&v)).Elem().Interface().(T)
+}
+
+// DeepCopyValue returns a deep copy of the reflected value like DeepCopy
+func DeepCopyValue(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		res := reflect.New(v.Elem().Type())
+		res.Elem().Set(DeepCopyValue(v.Elem()))
+		return res
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		res := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			res.Index(i).Set(DeepCopyValue(v.Index(i)))
+		}
+		return res
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		res := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			res.SetMapIndex(DeepCopyValue(iter.Key()), DeepCopyValue(iter.Value()))
+		}
+		return res
+	case reflect.Struct:
+		if OpaqueStruct(v.Type()) {
+			return v
+		}
+		res := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.NumField(); i++ {
+			res.Field(i).Set(DeepCopyValue(v.Field(i)))
+		}
+		return res
+	}
+	return v
+}
+
+// OpaqueStruct returns true if the struct has unexported fields such as time.Time and must be copied
+// as a whole value
+func OpaqueStruct(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		if !t.Field(i).IsExported() {
+			return true
+		}
+	}
+	return false
+}
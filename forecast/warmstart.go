@@ -0,0 +1,38 @@
+package forecast
+
+import (
+	"github.com/aouyang1/go-forecaster/feature"
+)
+
+// SetWarmStart primes the lasso coordinate descent of every following fit with the intercept and
+// feature coefficients of the current fit so that refitting on a slightly longer history converges in
+// fewer iterations. Features without a coefficient in the current fit start from zero. This has no
+// effect on an untrained forecast or on the other regression backends.
+func (f *Forecast) SetWarmStart(enabled bool) {
+	if f == nil {
+		return
+	}
+	f.warmStart = enabled
+}
+
+// warmStartBeta returns the intercept followed by the current coefficient of each feature label or
+// nil if warm starts are disabled
+func (f *Forecast) warmStartBeta(labels []feature.Feature) []float64 {
+	if !f.warmStart || !f.trained {
+		return nil
+	}
+	coef := make(map[string]float64, len(f.featureWeights))
+	for _, fw := range f.featureWeights {
+		feat, err := fw.ToFeature()
+		if err != nil {
+			continue
+		}
+		coef[feat.String()] = fw.Value
+	}
+	beta := make([]float64, len(labels)+1)
+	beta[0] = f.intercept
+	for i, label := range labels {
+		beta[i+1] = coef[label.String()]
+	}
+	return beta
+}
@@ -0,0 +1,32 @@
+package forecast
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetWarmStart(t *testing.T) {
+	f, tWin, y := testFitSignal(t)
+	expected, err := f.Coefficients()
+	require.Nil(t, err)
+	expectedIntercept := f.Intercept()
+
+	// a warm started refit on the same data converges to the same coefficients
+	f.SetWarmStart(true)
+	require.Nil(t, f.Fit(tWin, y))
+	coef, err := f.Coefficients()
+	require.Nil(t, err)
+	require.Len(t, coef, len(expected))
+	for label, v := range expected {
+		assert.InDelta(t, v, coef[label], 1e-3, label)
+	}
+	assert.InDelta(t, expectedIntercept, f.Intercept(), 1e-3)
+
+	// untrained forecasts have nothing to warm start from
+	untrained, err := New(nil)
+	require.Nil(t, err)
+	untrained.SetWarmStart(true)
+	assert.Nil(t, untrained.warmStartBeta(nil))
+}
@@ -0,0 +1,90 @@
+package forecast
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/errs"
+	mat_ "github.com/aouyang1/go-forecaster/mat"
+	"gonum.org/v1/gonum/mat"
+)
+
+var ErrInvalidWeight = errs.NewDataError(errs.CodeInvalidValue, "weight of an observed point must be positive and finite", nil)
+
+// FitWeighted fits the forecast model minimizing the weighted squared error of the training data. Weights
+// are typically the inverse of the known measurement variance of each point e.g. the sample count of
+// pre-aggregated data. Weights of NaN training values are ignored while weights of observed values
+// must be positive and finite. The weights are normalized to a mean of 1.0 so the regularization has
+// the same scale as an unweighted fit. The unweighted fast path detection is skipped.
+func (f *Forecast) FitWeighted(t []time.Time, y, weights []float64) error {
+	return f.FitWeightedCtx(context.Background(), t, y, weights)
+}
+
+// FitWeightedCtx fits the weighted forecast model like FitWeighted returning the context error if the
+// context is done before the fit completes
+func (f *Forecast) FitWeightedCtx(ctx context.Context, t []time.Time, y, weights []float64) error {
+	if f == nil {
+		return ErrUninitializedForecast
+	}
+	if len(weights) != len(y) {
+		return fmt.Errorf("got %d weights and %d values, %w", len(weights), len(y), ErrMismatchedDataLen)
+	}
+	for i, w := range weights {
+		if math.IsNaN(y[i]) {
+			continue
+		}
+		if !(w > 0) || math.IsInf(w, 1) {
+			return fmt.Errorf("weight of %.3f at index %d, %w", w, i, ErrInvalidWeight)
+		}
+	}
+	return f.fit(ctx, t, y, weights, nil)
+}
+
+// observedWeights returns the weights of the non-NaN values normalized to a mean of 1.0 or nil if
+// unweighted
+func observedWeights(y, weights []float64) []float64 {
+	if weights == nil {
+		return nil
+	}
+	res := make([]float64, 0, len(y))
+	var total float64
+	for i, v := range y {
+		if math.IsNaN(v) {
+			continue
+		}
+		res = append(res, weights[i])
+		total += weights[i]
+	}
+	if total == 0 {
+		return res
+	}
+	scale := float64(len(res)) / total
+	for i := range res {
+		res[i] *= scale
+	}
+	return res
+}
+
+// weightRows scales each row of the features and target by the square root of its weight so that the
+// least squares fit of the scaled rows minimizes the weighted squared error
+func weightRows(features, target mat.Matrix, weights []float64) (mat.Matrix, mat.Matrix) {
+	sqrtW := make([]float64, len(weights))
+	for i, w := range weights {
+		sqrtW[i] = math.Sqrt(w)
+	}
+	scale := func(i, j int, v float64) float64 {
+		return v * sqrtW[i]
+	}
+	var scaledTarget mat.Dense
+	scaledTarget.Apply(scale, target)
+	if csr, ok := features.(*mat_.CSR); ok {
+		// weights have a value for every observation
+		scaledFeatures, _ := csr.ScaleRows(sqrtW)
+		return scaledFeatures, &scaledTarget
+	}
+	var scaledFeatures mat.Dense
+	scaledFeatures.Apply(scale, features)
+	return &scaledFeatures, &scaledTarget
+}
@@ -0,0 +1,103 @@
+package forecast
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/forecast/options"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFitWeighted(t *testing.T) {
+	n := 3 * 24 * 12
+	ct := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	r := rand.New(rand.NewSource(3))
+
+	// every other point is aggregated from far fewer samples and is much noisier
+	tWin := make([]time.Time, 0, n)
+	y := make([]float64, n)
+	variance := make([]float64, n)
+	for i := 0; i < n; i++ {
+		tPnt := ct.Add(time.Duration(i) * 5 * time.Minute)
+		tWin = append(tWin, tPnt)
+		variance[i] = 0.01
+		if i%2 == 1 {
+			variance[i] = 25.0
+		}
+		y[i] = 2.0 + 3.0*math.Sin(2.0*math.Pi*float64(tPnt.Sub(ct))/float64(24*time.Hour)) +
+			math.Sqrt(variance[i])*r.NormFloat64()
+	}
+	weights := make([]float64, n)
+	for i, v := range variance {
+		weights[i] = 1.0 / v
+	}
+
+	sinLabel := "seas_epoch_daily_01_sin"
+	fitErr := func(weights []float64) float64 {
+		opt := options.NewDefaultOptions()
+		opt.SeasonalityOptions.SeasonalityConfigs = []options.SeasonalityConfig{
+			options.NewDailySeasonalityConfig(1),
+		}
+		f, err := New(opt)
+		require.Nil(t, err)
+		if weights == nil {
+			require.Nil(t, f.Fit(tWin, y))
+		} else {
+			require.Nil(t, f.FitWeighted(tWin, y, weights))
+		}
+		coef, err := f.Coefficients()
+		require.Nil(t, err)
+		return math.Abs(f.Intercept()-2.0) + math.Abs(coef[sinLabel]-3.0)
+	}
+
+	weightedErr := fitErr(weights)
+	assert.Less(t, weightedErr, 0.05)
+	assert.Less(t, weightedErr, fitErr(nil)/4)
+}
+
+func TestFitWeightedErrors(t *testing.T) {
+	ct := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tWin := []time.Time{ct, ct.Add(time.Hour), ct.Add(2 * time.Hour)}
+
+	testData := map[string]struct {
+		y       []float64
+		weights []float64
+		err     error
+	}{
+		"mismatched length": {
+			y:       []float64{1, 2, 3},
+			weights: []float64{1, 1},
+			err:     ErrMismatchedDataLen,
+		},
+		"zero weight": {
+			y:       []float64{1, 2, 3},
+			weights: []float64{1, 0, 1},
+			err:     ErrInvalidWeight,
+		},
+		"infinite weight": {
+			y:       []float64{1, 2, 3},
+			weights: []float64{1, math.Inf(1), 1},
+			err:     ErrInvalidWeight,
+		},
+		"ignored weight of missing value": {
+			y:       []float64{1, math.NaN(), 3},
+			weights: []float64{1, math.NaN(), 1},
+		},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			f, err := New(nil)
+			require.Nil(t, err)
+			err = f.FitWeighted(tWin, td.y, td.weights)
+			if td.err != nil {
+				assert.ErrorIs(t, err, td.err)
+				return
+			}
+			require.Nil(t, err)
+		})
+	}
+}
@@ -1,26 +1,44 @@
 package forecaster
 
 import (
-	"errors"
+	"context"
 	"fmt"
 	"io"
+	"log/slog"
 	"math"
+	"slices"
+	"sort"
+	"strings"
 	"time"
 
+	"github.com/aouyang1/go-forecaster/errs"
 	"github.com/aouyang1/go-forecaster/forecast"
 	"github.com/aouyang1/go-forecaster/models"
 	"github.com/aouyang1/go-forecaster/stats"
 	"github.com/aouyang1/go-forecaster/timedataset"
-	"github.com/go-echarts/go-echarts/v2/components"
 	"gonum.org/v1/gonum/floats"
 	"gonum.org/v1/gonum/stat"
 )
 
 var (
-	ErrInsufficientResidual = errors.New("insufficient samples from residual after outlier removal")
-	ErrEmptyTimeDataset     = errors.New("no timedataset or uninitialized")
-	ErrNoOptionsInModel     = errors.New("no options set in model")
-	ErrCannotInferInterval  = errors.New("cannot infer interval from training data time")
+	ErrInsufficientResidual  = errs.NewFitError(errs.CodeInsufficientData, "insufficient samples from residual after outlier removal", nil)
+	ErrEmptyTimeDataset      = errs.NewDataError(errs.CodeNoData, "no timedataset or uninitialized", nil)
+	ErrNoOptionsInModel      = errs.NewConfigError(errs.CodeMissingOption, "no options set in model", nil)
+	ErrMismatchedVarianceLen = errs.NewDataError(errs.CodeLengthMismatch, "variance has different length than values", nil)
+	ErrInvalidVariance       = errs.NewDataError(errs.CodeInvalidValue, "variance of an observed point must be positive and finite", nil)
+	ErrCannotInferInterval   = errs.NewDataError(errs.CodeCannotInferFreq, "cannot infer interval from training data time", nil)
+	ErrEmptyResults          = errs.NewDataError(errs.CodeNoData, "no forecast results to score", nil)
+	ErrInvalidResolution     = errs.NewConfigError(errs.CodeInvalidOption, "resolution must be positive and evenly divide a day", nil)
+	ErrUnknownResidualFilter = errs.NewConfigError(errs.CodeInvalidOption, "unknown residual filter", nil)
+	ErrInvalidFilterWindow   = errs.NewConfigError(errs.CodeInvalidOption, "residual median filter window must be at least 2", nil)
+	ErrInvalidSeasonalLag    = errs.NewConfigError(errs.CodeInvalidOption, "residual seasonal lag must be positive and less than the number of residuals", nil)
+	ErrInvalidClipQuantiles  = errs.NewConfigError(errs.CodeInvalidOption, "contextual clip quantiles must satisfy 0 <= lower < upper <= 1", nil)
+	ErrInvalidClipMultiplier = errs.NewConfigError(errs.CodeInvalidOption, "contextual clip uncertainty multiplier must be non-negative", nil)
+	ErrNoCoefficientPath     = errs.NewConfigError(errs.CodeMissingOption, "series coefficient path was not retained during fit", nil)
+	ErrStaleModel            = errs.NewPredictError(errs.CodeStaleModel, "prediction time is past the maximum age of the model", nil)
+	ErrResampleUnaligned     = errs.NewConfigError(errs.CodeInvalidOption, "resampling is not supported with per point weights or regressors", nil)
+
+	ErrInvalidCalibrationQuantile = errs.NewConfigError(errs.CodeInvalidOption, "calibration quantile must be between 0 and 1 exclusive", nil)
 )
 
 const (
@@ -38,8 +56,44 @@ type Forecaster struct {
 
 	fitTrainingData *timedataset.TimeDataset
 	fitResults      *Results
+	scores          *forecast.Scores
 	residual        []float64
 	uncertainty     []float64
+	residualSummary *ResidualSummary
+	diagnostics     *Diagnostics
+
+	// input training data of the last unweighted fit without regressors retained for Update
+	updateData *timedataset.TimeDataset
+
+	// series models refit on resampled residuals for the bootstrap uncertainty method
+	bootstraps []*forecast.Forecast
+
+	// lower and upper residual quantile models for the quantile uncertainty method
+	lowerQuantile *forecast.Forecast
+	upperQuantile *forecast.Forecast
+
+	maxAge    time.Duration
+	staleWarn bool
+	stale     bool
+}
+
+// ModelOption configures a forecaster loaded from a pre-existing model
+type ModelOption func(f *Forecaster)
+
+// WithMaxAge guards against predicting with an outdated model. Predict returns ErrStaleModel if any
+// prediction time is more than the maximum age after the training end time of the model.
+func WithMaxAge(d time.Duration) ModelOption {
+	return func(f *Forecaster) {
+		f.maxAge = d
+	}
+}
+
+// WithStaleWarning logs a warning and flags the forecaster as stale instead of returning
+// ErrStaleModel when predicting past the maximum age of the model
+func WithStaleWarning() ModelOption {
+	return func(f *Forecaster) {
+		f.staleWarn = true
+	}
 }
 
 // New creates a new instance of a Forecaster using thhe provided options. If no options are provided
@@ -55,23 +109,24 @@ func New(opt *Options) (*Forecaster, error) {
 
 	seriesForecast, err := forecast.New(f.opt.SeriesOptions.ForecastOptions)
 	if err != nil {
-		return nil, fmt.Errorf("unable to initialize series forecast, %w", err)
+		return nil, errs.NewConfigError(errs.CodeInvalidOption, "unable to initialize series forecast", err)
 	}
 	f.seriesForecast = seriesForecast
 
 	uncertaintyForecast, err := forecast.New(f.opt.UncertaintyOptions.ForecastOptions)
 	if err != nil {
-		return nil, fmt.Errorf("unable to initialize uncertainty forecast, %w", err)
+		return nil, errs.NewConfigError(errs.CodeInvalidOption, "unable to initialize uncertainty forecast", err)
 	}
 	f.uncertaintyForecast = uncertaintyForecast
 	return f, nil
 }
 
 // NewFromModel creates a new instance of Forecaster from a pre-existing model. This should be generated from
-// from a previous forecaster call to Model().
-func NewFromModel(model Model) (*Forecaster, error) {
-	if model.Options == nil {
-		return nil, ErrNoOptionsInModel
+// from a previous forecaster call to Model(). Models of an older schema version are migrated to the
+// current version first.
+func NewFromModel(model Model, modelOpts ...ModelOption) (*Forecaster, error) {
+	if err := model.Migrate(); err != nil {
+		return nil, err
 	}
 	opt := model.Options
 	opt.SeriesOptions.ForecastOptions = model.Series.Options
@@ -79,32 +134,193 @@ func NewFromModel(model Model) (*Forecaster, error) {
 
 	seriesForecast, err := forecast.NewFromModel(model.Series)
 	if err != nil {
-		return nil, fmt.Errorf("unable to load from series model, %w", err)
+		return nil, errs.NewConfigError(errs.CodeInvalidModel, "unable to load from series model", err)
 	}
 	uncertaintyForecast, err := forecast.NewFromModel(model.Uncertainty)
 	if err != nil {
-		return nil, fmt.Errorf("unable to load from uncertainty model, %w", err)
+		return nil, errs.NewConfigError(errs.CodeInvalidModel, "unable to load from uncertainty model", err)
 	}
 	f := &Forecaster{
 		opt:                 opt,
 		seriesForecast:      seriesForecast,
 		uncertaintyForecast: uncertaintyForecast,
+		scores:              model.Scores,
+	}
+	if err := f.loadBootstraps(); err != nil {
+		return nil, errs.NewConfigError(errs.CodeInvalidModel, "unable to load bootstrap models", err)
+	}
+	if err := f.loadQuantiles(); err != nil {
+		return nil, errs.NewConfigError(errs.CodeInvalidModel, "unable to load quantile models", err)
+	}
+	if err := f.loadResidualSummary(model.Residuals); err != nil {
+		return nil, errs.NewConfigError(errs.CodeInvalidModel, "unable to load residual summary", err)
+	}
+	for _, modelOpt := range modelOpts {
+		modelOpt(f)
 	}
 	return f, nil
 }
 
+// Stale returns true if a prediction was requested past the maximum age of the model while only
+// warning on stale models
+func (f *Forecaster) Stale() bool {
+	return f.stale
+}
+
+// checkStaleness returns ErrStaleModel if any of the times are past the maximum age of the model. The
+// forecaster is flagged as stale instead if only warning.
+func (f *Forecaster) checkStaleness(t []time.Time) error {
+	if f.maxAge <= 0 || len(t) == 0 {
+		return nil
+	}
+	trainEnd := f.seriesForecast.TrainEndTime()
+	latest := t[0]
+	for _, tPnt := range t[1:] {
+		if tPnt.After(latest) {
+			latest = tPnt
+		}
+	}
+	age := latest.Sub(trainEnd)
+	if age <= f.maxAge {
+		return nil
+	}
+	if f.staleWarn {
+		slog.Warn("predicting past the maximum age of the model", "train_end_time", trainEnd, "prediction_time", latest, "max_age", f.maxAge)
+		f.stale = true
+		return nil
+	}
+	return fmt.Errorf("model trained until %s is %s old at %s exceeding %s, %w", trainEnd, age, latest, f.maxAge, ErrStaleModel)
+}
+
 // Fit uses the input time dataset and fits the forecast model
 func (f *Forecaster) Fit(t []time.Time, y []float64) error {
+	return f.FitCtx(context.Background(), t, y)
+}
+
+// FitCtx fits the forecast model like Fit stopping the series, uncertainty, bootstrap, and quantile fits
+// once the context is done. The context error is returned wrapped in a fit error and the forecaster
+// must be refit before predicting.
+func (f *Forecaster) FitCtx(ctx context.Context, t []time.Time, y []float64) error {
+	return f.fit(ctx, t, y, nil, nil)
+}
+
+// FitWithRegressors fits the forecast model with external regressors aligned with the input times.
+// Each regressor becomes a feature of the series model with its own coefficient and must be provided
+// again on prediction with PredictWithRegressors. Points where a regressor is NaN or infinite are
+// excluded from the series fit.
+func (f *Forecaster) FitWithRegressors(t []time.Time, y []float64, x forecast.Regressors) error {
+	return f.fit(context.Background(), t, y, nil, x)
+}
+
+// FitWithVariance fits the forecast model with the known measurement variance of each point e.g. from
+// the sample counts of pre-aggregated data. The series regression is weighted by the inverse variance,
+// outliers are detected on the residuals standardized by the variance, and the rolling residual
+// standard deviation of the uncertainty series is inverse variance weighted. Variances of NaN values
+// are ignored while variances of observed values must be positive and finite.
+func (f *Forecaster) FitWithVariance(t []time.Time, y, variance []float64) error {
+	if len(variance) != len(y) {
+		return fmt.Errorf("got %d variances and %d values, %w", len(variance), len(y), ErrMismatchedVarianceLen)
+	}
+	weights := make([]float64, len(y))
+	var total float64
+	var numObs int
+	for i, v := range variance {
+		weights[i] = math.NaN()
+		if math.IsNaN(y[i]) {
+			continue
+		}
+		if !(v > 0) || math.IsInf(v, 1) {
+			return fmt.Errorf("variance of %.3f at index %d, %w", v, i, ErrInvalidVariance)
+		}
+		weights[i] = 1.0 / v
+		total += weights[i]
+		numObs++
+	}
+
+	// normalize to a mean weight of 1.0 over the observed points
+	for i := range weights {
+		if math.IsNaN(weights[i]) {
+			weights[i] = 1.0
+			continue
+		}
+		weights[i] *= float64(numObs) / total
+	}
+	return f.fit(context.Background(), t, y, weights, nil)
+}
+
+func (f *Forecaster) fit(ctx context.Context, t []time.Time, y, weights []float64, x forecast.Regressors) error {
+	if f.opt.TrainingHooks != nil {
+		f.opt.SeriesOptions.ForecastOptions.TrainingHooks = f.opt.TrainingHooks.forStage(TrainingStageSeries)
+		f.opt.UncertaintyOptions.ForecastOptions.TrainingHooks = f.opt.TrainingHooks.forStage(TrainingStageUncertainty)
+	}
 	td, err := timedataset.NewUnivariateDataset(t, y)
 	if err != nil {
-		return fmt.Errorf("unable to create training dataset, %w", err)
+		return errs.NewFitError(errs.CodeFitFailed, "unable to create training dataset", err)
+	}
+	f.updateData = nil
+	if weights == nil && len(x) == 0 {
+		f.updateData = td.Copy()
+	}
+	if f.opt.Resample != nil {
+		if weights != nil || len(x) > 0 {
+			return ErrResampleUnaligned
+		}
+		td, err = td.Resample(*f.opt.Resample)
+		if err != nil {
+			return errs.NewFitError(errs.CodeFitFailed, "unable to resample training data", err)
+		}
+		t = td.T
+	}
+	if f.opt.Counter != nil {
+		td, err = f.opt.Counter.rate(td)
+		if err != nil {
+			return errs.NewFitError(errs.CodeFitFailed, "unable to convert counter to rate", err)
+		}
 	}
 	f.fitTrainingData = td.Copy()
+	var imputed []int
+	if f.opt.SeriesOptions.ImputationOptions.enabled() {
+		imputed, err = f.opt.SeriesOptions.ImputationOptions.impute(td.T, td.Y)
+		if err != nil {
+			return errs.NewFitError(errs.CodeFitFailed, "unable to impute missing values", err)
+		}
+	}
+	if f.opt.Growth != nil {
+		if err := f.opt.Growth.Validate(); err != nil {
+			return errs.NewFitError(errs.CodeFitFailed, "invalid growth options", err)
+		}
+		if f.opt.Growth.logistic() && f.opt.Transform.enabled() {
+			return ErrGrowthWithTransform
+		}
+	}
+	if f.opt.Transform.enabled() {
+		if err := f.opt.Transform.fit(td.Y); err != nil {
+			return errs.NewFitError(errs.CodeFitFailed, "unable to fit transform", err)
+		}
+		td.Y = f.opt.Transform.forward(td.Y)
+	}
+	if f.opt.Growth.logistic() {
+		td.Y, err = f.opt.Growth.forward(td.T, td.Y)
+		if err != nil {
+			return errs.NewFitError(errs.CodeFitFailed, "unable to scale to growth capacity", err)
+		}
+	}
+	f.diagnostics = &Diagnostics{
+		ImputedIndexes: imputed,
+	}
+	for i, v := range td.Y {
+		if math.IsNaN(v) {
+			f.diagnostics.MissingIndexes = append(f.diagnostics.MissingIndexes, i)
+		}
+	}
+	f.diagnostics.ExcludedIndexes = f.opt.excludeRecent(td.T, td.Y)
 
-	residual, err := f.fitSeriesWithOutliers(td.T, td.Y, f.seriesForecast)
+	residual, err := f.fitSeriesWithOutliers(ctx, td.T, td.Y, weights, x, f.seriesForecast)
 	if err != nil {
-		return err
+		return errs.NewFitError(errs.CodeFitFailed, "unable to fit series", err)
 	}
+	f.diagnostics.ChangepointSensitivity = f.seriesForecast.ChangepointSensitivity()
+	f.diagnostics.RedundantFeatures = f.seriesForecast.RedundantFeatures()
 
 	// create residual to align with original time window since td.T may have changed
 	// after outlier removal
@@ -119,42 +335,93 @@ func (f *Forecaster) Fit(t []time.Time, y []float64) error {
 		}
 	}
 
-	uncertaintySeries, err := f.generateUncertaintySeries(residual)
+	if f.opt.Autoregression != nil {
+		if err := f.opt.Autoregression.fit(t, f.residual, f.opt.FreqOptions); err != nil {
+			return errs.NewFitError(errs.CodeFitFailed, "unable to fit autoregressive residual", err)
+		}
+	}
+
+	if err := f.opt.UncertaintyOptions.windowFromDuration(td.T, f.opt.FreqOptions); err != nil {
+		return errs.NewFitError(errs.CodeFitFailed, "unable to set residual window", err)
+	}
+
+	uncertaintyT, uncertaintySeries, err := f.generateUncertaintySeries(td.T, residual, weights)
 	if err != nil {
-		return fmt.Errorf("unable to generate uncertainty series, %w", err)
+		return errs.NewFitError(errs.CodeFitFailed, "unable to generate uncertainty series", err)
 	}
 
-	// shifting time by half the residual window since computing the uncertainty series is similar to a
-	// finite impulse response filtering having a group delay of window/2.
-	start := f.opt.UncertaintyOptions.ResidualWindow / 2
-	end := len(t) - f.opt.UncertaintyOptions.ResidualWindow/2 - f.opt.UncertaintyOptions.ResidualWindow%2 + 1
+	// align uncertainty with the original time window by interpolating between window centers since
+	// centers need not land on a training timestamp
+	f.uncertainty = alignWindowCenters(t, uncertaintyT, uncertaintySeries)
 
-	// create uncertainty to align with original time window since td.T may have changed
-	// after outlier removal
-	f.uncertainty = make([]float64, len(t))
-	var k int
-	for i := 0; i < len(t); i++ {
-		if t[i].Equal(td.T[k+start]) && k < len(uncertaintySeries) {
-			f.uncertainty[i] = uncertaintySeries[k]
-			k += 1
-		} else {
-			f.uncertainty[i] = math.NaN()
+	if err := f.fitUncertainty(ctx, uncertaintyT, uncertaintySeries, f.uncertaintyForecast); err != nil {
+		return errs.NewFitError(errs.CodeFitFailed, "unable to fit uncertainty", err)
+	}
+
+	if err := f.fitTrendUncertainty(ctx, td.T, residual, weights, x); err != nil {
+		return errs.NewFitError(errs.CodeFitFailed, "unable to fit trend uncertainty", err)
+	}
+
+	if err := f.fitBootstrapUncertainty(ctx, td.T, residual, weights, x); err != nil {
+		return errs.NewFitError(errs.CodeFitFailed, "unable to fit bootstrap uncertainty", err)
+	}
+
+	if err := f.fitQuantileUncertainty(ctx, td.T, residual, weights); err != nil {
+		return errs.NewFitError(errs.CodeFitFailed, "unable to fit quantile uncertainty", err)
+	}
+
+	// calibrate against the residual of the observed training points using the uncalibrated uncertainty
+	if f.opt.UncertaintyOptions.HourlyCalibration {
+		uncertaintyRes, _, err := f.uncertaintyForecast.Predict(t)
+		if err != nil {
+			return errs.NewFitError(errs.CodeFitFailed, "unable to predict uncertainty for calibration", err)
+		}
+		if err := f.opt.UncertaintyOptions.calibrateHourly(t, f.residual, uncertaintyRes, f.opt.SeriesOptions.ForecastOptions.LocalLocation()); err != nil {
+			return errs.NewFitError(errs.CodeFitFailed, "unable to calibrate hourly uncertainty", err)
 		}
+	} else {
+		f.opt.UncertaintyOptions.HourlyMultipliers = nil
 	}
 
-	if err := f.fitUncertainty(td.T[start:end], uncertaintySeries, f.uncertaintyForecast); err != nil {
-		return err
+	if f.opt.ContextualClip != nil {
+		if err := f.opt.ContextualClip.setBounds(td.Y, f.uncertainty); err != nil {
+			return errs.NewFitError(errs.CodeFitFailed, "unable to set contextual clip bounds", err)
+		}
+		if f.opt.Transform.enabled() {
+			bounds := []float64{f.opt.ContextualClip.Min, f.opt.ContextualClip.Max}
+			f.opt.Transform.inverse(bounds)
+			f.opt.ContextualClip.Min, f.opt.ContextualClip.Max = bounds[0], bounds[1]
+		}
+		if f.opt.Growth.logistic() {
+			// the bounds are mapped to the original space with the capacity at the end of training
+			bounds := []float64{f.opt.ContextualClip.Min, f.opt.ContextualClip.Max}
+			f.opt.Growth.inverse([]time.Time{td.T[len(td.T)-1], td.T[len(td.T)-1]}, bounds)
+			f.opt.ContextualClip.Min, f.opt.ContextualClip.Max = bounds[0], bounds[1]
+		}
+	}
+
+	f.fitResults, err = f.predict(ctx, t, x)
+	if err != nil {
+		return errs.NewFitError(errs.CodeFitFailed, "unable to get predicted values from training set", err)
 	}
 
-	f.fitResults, err = f.Predict(t)
+	f.scores, err = forecast.NewScoresWithBaseline(f.fitResults.Forecast, f.fitTrainingData.Y, forecast.ScoreBaseline{
+		SeasonalLag:   f.seriesForecast.SeasonalLag(t),
+		Upper:         f.fitResults.Upper,
+		Lower:         f.fitResults.Lower,
+		IntervalLevel: f.intervalLevel(),
+	})
 	if err != nil {
-		return fmt.Errorf("unable to get predicted values from training set, %w", err)
+		return errs.NewFitError(errs.CodeFitFailed, "unable to score fit against training set", err)
 	}
 
 	return nil
 }
 
-func (f *Forecaster) fitSeriesWithOutliers(t []time.Time, y []float64, seriesForecast *forecast.Forecast) ([]float64, error) {
+// fitSeriesWithOutliers fits the series forecast iteratively removing outliers of the residual. If
+// weights are provided the fit is weighted and outliers are detected on the residual scaled by the
+// square root of the weights. Any regressors are aligned with the input times.
+func (f *Forecaster) fitSeriesWithOutliers(ctx context.Context, t []time.Time, y, weights []float64, x forecast.Regressors, seriesForecast *forecast.Forecast) ([]float64, error) {
 	outlierOpts := f.opt.SeriesOptions.OutlierOptions
 
 	// iterate to remove outliers
@@ -163,34 +430,88 @@ func (f *Forecaster) fitSeriesWithOutliers(t []time.Time, y []float64, seriesFor
 		numPasses = outlierOpts.NumPasses
 	}
 
+	if f.diagnostics == nil {
+		f.diagnostics = &Diagnostics{}
+	}
+	initialObs := floats.Count(func(v float64) bool { return !math.IsNaN(v) }, y)
+	remainingObs := initialObs
+
 	var residual []float64
 	for i := 0; i <= numPasses; i++ {
-		if err := seriesForecast.Fit(t, y); err != nil {
+		var err error
+		switch {
+		case weights != nil:
+			err = seriesForecast.FitWeightedCtx(ctx, t, y, weights)
+		case x != nil:
+			err = seriesForecast.FitWithRegressorsCtx(ctx, t, y, x)
+		default:
+			err = seriesForecast.FitCtx(ctx, t, y)
+		}
+		if err != nil {
 			return nil, fmt.Errorf("unable to forecast series, %w", err)
 		}
 
 		residual = seriesForecast.Residuals()
+		pass := OutlierPassEvent{
+			Pass:         i + 1,
+			RemainingObs: remainingObs,
+			Scores:       seriesForecast.Scores(),
+		}
 
 		// break out if no outlier options provided
 		if f.opt.SeriesOptions.OutlierOptions == nil {
+			f.opt.TrainingHooks.outlierPass(pass)
 			break
 		}
 
-		outlierIdxs := stats.DetectOutliers(
-			residual,
-			outlierOpts.LowerPercentile,
-			outlierOpts.UpperPercentile,
-			outlierOpts.TukeyFactor,
-		)
+		detectResidual := residual
+		if weights != nil {
+			detectResidual = make([]float64, len(residual))
+			for i, r := range residual {
+				detectResidual[i] = r * math.Sqrt(weights[i])
+			}
+		}
+
+		var outlierIdxs []int
+		if buckets := outlierOpts.seasonalBuckets(t); buckets != nil {
+			outlierIdxs, err = stats.DetectSeasonalOutliers(
+				detectResidual,
+				buckets,
+				outlierOpts.LowerPercentile,
+				outlierOpts.UpperPercentile,
+				outlierOpts.TukeyFactor,
+			)
+			if err != nil {
+				return nil, fmt.Errorf("unable to detect seasonal outliers, %w", err)
+			}
+		} else {
+			outlierIdxs = stats.DetectOutliers(
+				detectResidual,
+				outlierOpts.LowerPercentile,
+				outlierOpts.UpperPercentile,
+				outlierOpts.TukeyFactor,
+			)
+		}
 		outlierSet := make(map[int]struct{})
 		for _, idx := range outlierIdxs {
 			outlierSet[idx] = struct{}{}
 		}
 
 		// no more outliers detected with outlier options so break early
+		pass.Outliers = len(outlierIdxs)
 		if len(outlierIdxs) == 0 {
+			f.opt.TrainingHooks.outlierPass(pass)
+			break
+		}
+
+		// stop removing outliers if too little of the training data would remain
+		if float64(remainingObs-len(outlierIdxs)) < outlierOpts.MinRemainingFraction*float64(initialObs) {
+			f.diagnostics.OutlierRemovalHalted = true
+			pass.Halted = true
+			f.opt.TrainingHooks.outlierPass(pass)
 			break
 		}
+		f.opt.TrainingHooks.outlierPass(pass)
 
 		for i := 0; i < len(t); i++ {
 			if _, exists := outlierSet[i]; exists {
@@ -198,16 +519,32 @@ func (f *Forecaster) fitSeriesWithOutliers(t []time.Time, y []float64, seriesFor
 				continue
 			}
 		}
+		remainingObs -= len(outlierIdxs)
+		f.diagnostics.OutliersRemoved += len(outlierIdxs)
+		f.diagnostics.OutlierIndexes = append(f.diagnostics.OutlierIndexes, outlierIdxs...)
+		f.diagnostics.OutlierPasses++
 	}
+	sort.Ints(f.diagnostics.OutlierIndexes)
 	return residual, nil
 }
 
 // generateUncertaintySeries creates the uncertainty series by computing the rolling standard deviation
-// of the residual scaled by the configured z-score.
-func (f *Forecaster) generateUncertaintySeries(residual []float64) ([]float64, error) {
+// of the optionally filtered residual scaled by the configured z-score. Each value is timestamped at the
+// center of its window which is the mean time of the observed residuals in the window. Computing the
+// uncertainty series is similar to finite impulse response filtering so using the observed center
+// rather than an index offset of half the window avoids a lag for even windows and for windows
+// spanning gaps or removed outliers. Windows without observed residuals are skipped as are windows
+// sharing the center of the previous window. Optional weights aligned with the residual weight the
+// standard deviation of each window.
+func (f *Forecaster) generateUncertaintySeries(t []time.Time, residual, weights []float64) ([]time.Time, []float64, error) {
 	if len(residual) < MinResidualSize {
-		return nil, ErrInsufficientResidual
+		return nil, nil, ErrInsufficientResidual
 	}
+	residual, err := f.opt.UncertaintyOptions.filterResidual(residual)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	// compute rolling window standard deviation of residual for uncertaninty bands
 	// the window is not necessarily a block of continuous time but could jump across
 	// outlier points
@@ -222,35 +559,110 @@ func (f *Forecaster) generateUncertaintySeries(residual []float64) ([]float64, e
 	}
 	f.opt.UncertaintyOptions.ResidualWindow = resWindow
 
-	stddevSeries := make([]float64, len(residual)-resWindow+1)
 	numWindows := len(residual) - resWindow + 1
+	centers := make([]time.Time, 0, numWindows)
+	stddevSeries := make([]float64, 0, numWindows)
+	obs := make([]float64, 0, resWindow)
+	var obsWeights []float64
+	if weights != nil {
+		obsWeights = make([]float64, 0, resWindow)
+	}
 
 	for i := 0; i < numWindows; i++ {
-		resWindow := residual[i : i+resWindow]
-
-		// move all nans to the front so we only compute standard deviation off of non-nan values
-		var ptr int
-		for j := 0; j < len(resWindow); j++ {
-			if math.IsNaN(resWindow[j]) {
-				val := resWindow[ptr]
-				resWindow[ptr] = resWindow[j]
-				resWindow[j] = val
-				ptr += 1
+		// only compute standard deviation and center off of non-nan values
+		obs = obs[:0]
+		if weights != nil {
+			obsWeights = obsWeights[:0]
+		}
+		var offset float64
+		for j := i; j < i+resWindow; j++ {
+			if math.IsNaN(residual[j]) {
+				continue
+			}
+			obs = append(obs, residual[j])
+			if weights != nil {
+				obsWeights = append(obsWeights, weights[j])
 			}
+			offset += float64(t[j].Sub(t[i]))
+		}
+		if len(obs) == 0 {
+			continue
+		}
+		center := t[i].Add(time.Duration(math.Round(offset / float64(len(obs)))))
+		if len(centers) > 0 && !center.After(centers[len(centers)-1]) {
+			continue
+		}
+		_, stddev := stat.MeanStdDev(obs, obsWeights)
+		centers = append(centers, center)
+		stddevSeries = append(stddevSeries, f.opt.UncertaintyOptions.ResidualZscore*stddev)
+	}
+	return centers, stddevSeries, nil
+}
+
+// alignWindowCenters linearly interpolates the values at the window centers onto the input times.
+// Times outside of the range of centers are NaN.
+func alignWindowCenters(t, centers []time.Time, vals []float64) []float64 {
+	res := make([]float64, len(t))
+	var k int
+	for i, tPnt := range t {
+		res[i] = math.NaN()
+		for k < len(centers) && centers[k].Before(tPnt) {
+			k++
+		}
+		if k == len(centers) {
+			continue
 		}
-		_, stddev := stat.MeanStdDev(resWindow[ptr:], nil)
-		stddevSeries[i] = f.opt.UncertaintyOptions.ResidualZscore * stddev
+		if centers[k].Equal(tPnt) {
+			res[i] = vals[k]
+			continue
+		}
+		if k == 0 {
+			continue
+		}
+		frac := float64(tPnt.Sub(centers[k-1])) / float64(centers[k].Sub(centers[k-1]))
+		res[i] = vals[k-1] + frac*(vals[k]-vals[k-1])
+	}
+	return res
+}
+
+// mergeMissingFeatures returns the sorted unique names of the missing features of the series and
+// uncertainty models or nil if none are missing
+func mergeMissingFeatures(series, uncertainty []string) []string {
+	if len(series) == 0 && len(uncertainty) == 0 {
+		return nil
 	}
-	return stddevSeries, nil
+	res := append(append([]string(nil), series...), uncertainty...)
+	sort.Strings(res)
+	return slices.Compact(res)
 }
 
-func (f *Forecaster) fitUncertainty(t []time.Time, uncertaintySeries []float64, uncertaintyForecast *forecast.Forecast) error {
+// mergeNaNReasons combines the per time point NaN reasons of the series and uncertainty models
+// returning nil if neither model marked a time point
+func mergeNaNReasons(n int, series, uncertainty []string) []string {
+	if series == nil && uncertainty == nil {
+		return nil
+	}
+	res := make([]string, n)
+	for i := range res {
+		var reasons []string
+		if series != nil && series[i] != "" {
+			reasons = append(reasons, "series "+series[i])
+		}
+		if uncertainty != nil && uncertainty[i] != "" {
+			reasons = append(reasons, "uncertainty "+uncertainty[i])
+		}
+		res[i] = strings.Join(reasons, "; ")
+	}
+	return res
+}
+
+func (f *Forecaster) fitUncertainty(ctx context.Context, t []time.Time, uncertaintySeries []float64, uncertaintyForecast *forecast.Forecast) error {
 	uncertaintyData, err := timedataset.NewUnivariateDataset(t, uncertaintySeries)
 	if err != nil {
 		return fmt.Errorf("unable to create univariate dataset for uncertainty, %w", err)
 	}
 
-	if err := uncertaintyForecast.Fit(uncertaintyData.T, uncertaintyData.Y); err != nil {
+	if err := uncertaintyForecast.FitCtx(ctx, uncertaintyData.T, uncertaintyData.Y); err != nil {
 		return fmt.Errorf("unable to forecast uncertainty, %w", err)
 	}
 
@@ -259,36 +671,143 @@ func (f *Forecaster) fitUncertainty(t []time.Time, uncertaintySeries []float64,
 
 // Predict takes in any set of time samples and generates a forecast, upper, lower values per time point
 func (f *Forecaster) Predict(t []time.Time) (*Results, error) {
-	seriesRes, seriesComp, err := f.seriesForecast.Predict(t)
+	return f.predict(context.Background(), t, nil)
+}
+
+// PredictCtx generates a forecast like Predict returning the context error if the context is done
+// before the series or uncertainty bands are predicted
+func (f *Forecaster) PredictCtx(ctx context.Context, t []time.Time) (*Results, error) {
+	return f.predict(ctx, t, nil)
+}
+
+// PredictWithRegressors generates a forecast with the values of the external regressors aligned with
+// the input times. Every regressor the model was fit with must be provided unless its missing policy
+// fills it.
+func (f *Forecaster) PredictWithRegressors(t []time.Time, x forecast.Regressors) (*Results, error) {
+	return f.predict(context.Background(), t, x)
+}
+
+func (f *Forecaster) predict(ctx context.Context, t []time.Time, x forecast.Regressors) (*Results, error) {
+	if err := f.checkStaleness(t); err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, errs.NewPredictError(errs.CodePredictFailed, "unable to predict series forecasts", err)
+	}
+	seriesRes, seriesComp, err := f.seriesForecast.PredictWithRegressors(t, x)
 	if err != nil {
-		return nil, fmt.Errorf("unable to predict series forecasts, %w", err)
+		return nil, errs.NewPredictError(errs.CodePredictFailed, "unable to predict series forecasts", err)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, errs.NewPredictError(errs.CodePredictFailed, "unable to predict uncertainty forecasts", err)
 	}
 	uncertaintyRes, uncertaintyComp, err := f.uncertaintyForecast.Predict(t)
 	if err != nil {
-		return nil, fmt.Errorf("unable to predict uncertainty forecasts, %w", err)
+		return nil, errs.NewPredictError(errs.CodePredictFailed, "unable to predict uncertainty forecasts", err)
 	}
 
-	// cap uncertainty predictions to be greater than or equal to 0
+	r := &Results{
+		T:                     t,
+		SeriesComponents:      seriesComp,
+		UncertaintyComponents: uncertaintyComp,
+		NaNReasons:            mergeNaNReasons(len(t), seriesComp.NaNReasons, uncertaintyComp.NaNReasons),
+		MissingFeatures:       mergeMissingFeatures(seriesComp.MissingFeatures, uncertaintyComp.MissingFeatures),
+	}
+	if err := f.finishPrediction(r, seriesRes, uncertaintyRes, x); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// finishPrediction sets the forecast and uncertainty bands of the results from the point predictions
+// of the series and uncertainty models. The bands, autoregressive correction, inverse transform and
+// growth, and clipping are applied in the original space of the series.
+func (f *Forecaster) finishPrediction(r *Results, seriesRes, uncertaintyRes []float64, x forecast.Regressors) error {
+	t := r.T
+	r.Forecast = seriesRes
+
+	// cap uncertainty predictions to be greater than or equal to 0 and collapse the bands of
+	// structural zeros onto the exact zero forecast
+	structuralZeros := f.seriesForecast.StructuralZeros(t)
+	loc := f.opt.SeriesOptions.ForecastOptions.LocalLocation()
 	for i := 0; i < len(uncertaintyRes); i++ {
-		if uncertaintyRes[i] < 0.0 {
+		if uncertaintyRes[i] < 0.0 || (structuralZeros != nil && structuralZeros[i]) {
 			uncertaintyRes[i] = 0.0
 		}
+		uncertaintyRes[i] *= f.opt.UncertaintyOptions.hourlyMultiplier(t[i], loc)
 	}
 
-	r := &Results{
-		T:                     t,
-		Forecast:              seriesRes,
-		SeriesComponents:      seriesComp,
-		UncertaintyComponents: uncertaintyComp,
+	upperDev, lowerDev := uncertaintyRes, uncertaintyRes
+	if method := f.opt.UncertaintyOptions.Method; method == UncertaintyMethodBootstrap || method == UncertaintyMethodQuantile {
+		var err error
+		if method == UncertaintyMethodBootstrap {
+			upperDev, lowerDev, err = f.bootstrapBands(t, seriesRes, x)
+		} else {
+			upperDev, lowerDev, err = f.quantileBands(t)
+		}
+		if err != nil {
+			return errs.NewPredictError(errs.CodePredictFailed, fmt.Sprintf("unable to predict %s bands", method), err)
+		}
+		for i := range upperDev {
+			if structuralZeros != nil && structuralZeros[i] {
+				upperDev[i] = 0
+				lowerDev[i] = 0
+			}
+		}
+	} else if f.opt.UncertaintyOptions.trendUncertaintyEnabled() {
+		// combine the short-term residual noise with the long-term trend uncertainty in quadrature
+		trainEnd := f.seriesForecast.TrainEndTime()
+		r.ShortTermUncertainty = make([]float64, len(t))
+		r.LongTermUncertainty = make([]float64, len(t))
+		copy(r.ShortTermUncertainty, uncertaintyRes)
+		for i := range uncertaintyRes {
+			if structuralZeros != nil && structuralZeros[i] {
+				continue
+			}
+			longTerm := f.opt.UncertaintyOptions.longTermUncertainty(t[i], trainEnd)
+			r.LongTermUncertainty[i] = longTerm
+			uncertaintyRes[i] = math.Hypot(uncertaintyRes[i], longTerm)
+		}
 	}
+	if f.opt.Autoregression != nil {
+		r.Autoregressive = f.opt.Autoregression.correction(t)
+		for i := range r.Autoregressive {
+			if structuralZeros != nil && structuralZeros[i] {
+				r.Autoregressive[i] = 0
+			}
+		}
+		floats.Add(seriesRes, r.Autoregressive)
+	}
+
 	upper := make([]float64, len(seriesRes))
 	lower := make([]float64, len(seriesRes))
 
 	copy(upper, seriesRes)
 	copy(lower, seriesRes)
 
-	floats.Add(upper, uncertaintyRes)
-	floats.Sub(lower, uncertaintyRes)
+	floats.Add(upper, upperDev)
+	floats.Sub(lower, lowerDev)
+
+	if f.opt.Transform.enabled() {
+		f.opt.Transform.inverse(r.Forecast)
+		f.opt.Transform.inverse(upper)
+		f.opt.Transform.inverse(lower)
+		for i := range structuralZeros {
+			if structuralZeros[i] {
+				r.Forecast[i], upper[i], lower[i] = 0, 0, 0
+			}
+		}
+	}
+	if f.opt.Growth.logistic() {
+		f.opt.Growth.inverse(t, r.Forecast)
+		f.opt.Growth.inverse(t, upper)
+		f.opt.Growth.inverse(t, lower)
+		for i := range structuralZeros {
+			if structuralZeros[i] {
+				r.Forecast[i], upper[i], lower[i] = 0, 0, 0
+			}
+		}
+	}
 
 	// clip data if specified in options
 	f.clip(r.Forecast)
@@ -297,7 +816,16 @@ func (f *Forecaster) Predict(t []time.Time) (*Results, error) {
 
 	r.Upper = upper
 	r.Lower = lower
-	return r, nil
+	return nil
+}
+
+// ValidateEventHorizon returns an error if any of the input times fall in occurrences of recurring events
+// that the series or uncertainty models do not expand
+func (f *Forecaster) ValidateEventHorizon(t []time.Time) error {
+	if err := f.seriesForecast.ValidateEventHorizon(t); err != nil {
+		return err
+	}
+	return f.uncertaintyForecast.ValidateEventHorizon(t)
 }
 
 // Score computes the coefficient of determination of the prediction
@@ -329,6 +857,21 @@ func (f *Forecaster) Residuals() []float64 {
 	return f.residual
 }
 
+// Influence returns the leverage of each training point on the series model along with the effective
+// degrees of freedom of the fit. Points removed as outliers or missing have NaN leverage.
+func (f *Forecaster) Influence() (*forecast.Influence, error) {
+	if f.fitTrainingData == nil {
+		return nil, ErrEmptyTimeDataset
+	}
+	return f.seriesForecast.Influence(f.fitTrainingData.T, f.residual)
+}
+
+// EventEffect compares the realized lift of the named event in newly observed actuals against the
+// effect predicted by the series model
+func (f *Forecaster) EventEffect(name string, t []time.Time, y []float64) (*forecast.EventEffect, error) {
+	return f.seriesForecast.EventEffect(name, t, y)
+}
+
 // Uncertainty returns the uncertainty series used to forecast the upper lower bounds
 func (f *Forecaster) Uncertainty() []float64 {
 	return f.uncertainty
@@ -336,17 +879,48 @@ func (f *Forecaster) Uncertainty() []float64 {
 
 // TrendComponent returns the trend component created by changepoints after fitting
 func (f *Forecaster) TrendComponent() []float64 {
-	return f.seriesForecast.TrendComponent()
+	return f.fitComponent(f.seriesForecast.TrendComponent(), func(c forecast.Components) []float64 { return c.Trend })
 }
 
 // SeasonalityComponent returns the seasonality component after fitting the fourier series
 func (f *Forecaster) SeasonalityComponent() []float64 {
-	return f.seriesForecast.SeasonalityComponent()
+	return f.fitComponent(f.seriesForecast.SeasonalityComponent(), func(c forecast.Components) []float64 { return c.Seasonality })
 }
 
 // EventComponent returns the event component after fitting the fourier series
 func (f *Forecaster) EventComponent() []float64 {
-	return f.seriesForecast.EventComponent()
+	return f.fitComponent(f.seriesForecast.EventComponent(), func(c forecast.Components) []float64 { return c.Event })
+}
+
+// CustomComponent returns the custom time feature component after fitting the fourier series
+func (f *Forecaster) CustomComponent() []float64 {
+	return f.fitComponent(f.seriesForecast.CustomComponent(), func(c forecast.Components) []float64 { return c.Custom })
+}
+
+// RegressorComponent returns the external regressor component after fitting
+func (f *Forecaster) RegressorComponent() []float64 {
+	return f.seriesForecast.RegressorComponent()
+}
+
+// fitComponent returns the component of the series fit. Models loaded with their training series
+// fall back to the component of the fit results predicted at every training time.
+func (f *Forecaster) fitComponent(comp []float64, fromResults func(forecast.Components) []float64) []float64 {
+	if len(comp) > 0 || f.residualSummary == nil || f.fitResults == nil {
+		return comp
+	}
+	return fromResults(f.fitResults.SeriesComponents)
+}
+
+// ExportTrainingMatrix writes the design matrix and target the series model was trained on after outlier
+// removal in the input format. RetainTrainingMatrix must be set in the series forecast options.
+func (f *Forecaster) ExportTrainingMatrix(w io.Writer, format forecast.ExportFormat) error {
+	return f.seriesForecast.ExportTrainingMatrix(w, format)
+}
+
+// ExportTrainingFeatureMap writes the libsvm index and name of every column of the exported series
+// training matrix
+func (f *Forecaster) ExportTrainingFeatureMap(w io.Writer) error {
+	return f.seriesForecast.ExportTrainingFeatureMap(w)
 }
 
 // SeriesIntercept returns the intercept of the series fit
@@ -380,10 +954,17 @@ func (f *Forecaster) Model() (Model, error) {
 	if err != nil {
 		return Model{}, fmt.Errorf("unable to fetch uncertainty moodel, %w", err)
 	}
+	residuals, err := f.persistedResiduals()
+	if err != nil {
+		return Model{}, fmt.Errorf("unable to summarize residuals, %w", err)
+	}
 	m := Model{
-		Options:     f.opt,
-		Series:      seriesModel,
-		Uncertainty: uncertaintyModel,
+		SchemaVersion: SchemaVersion,
+		Options:       f.opt,
+		Series:        seriesModel,
+		Uncertainty:   uncertaintyModel,
+		Scores:        f.scores,
+		Residuals:     residuals,
 	}
 	return m, nil
 }
@@ -405,13 +986,34 @@ func (f *Forecaster) TrainingData() *timedataset.TimeDataset {
 	return f.fitTrainingData
 }
 
+// FitDiagnostics returns the diagnostics gathered during the last fit
+func (f *Forecaster) FitDiagnostics() *Diagnostics {
+	return f.diagnostics
+}
+
 // FitResults returns the results of the fit which includes the forecast, upper, and lower values
 func (f *Forecaster) FitResults() *Results {
 	return f.fitResults
 }
 
+// Scores returns the scores of the fit forecast and uncertainty bands against the training data. This
+// includes the coverage of the bands and their pinball loss at the interval level of the residual
+// z-score.
+func (f *Forecaster) Scores() *forecast.Scores {
+	return f.scores
+}
+
+// intervalLevel returns the fraction of a normal distribution within the residual z-score of the
+// uncertainty bands
+func (f *Forecaster) intervalLevel() float64 {
+	if f.opt.UncertaintyOptions == nil || f.opt.UncertaintyOptions.ResidualZscore <= 0 {
+		return 0
+	}
+	return math.Erf(f.opt.UncertaintyOptions.ResidualZscore / math.Sqrt2)
+}
+
 // MakeFuturePeriods generates a slice of time after the last point in the training data. By default
-// a zero freq will be inferred from the training data.
+// a zero freq will be inferred from the training data with the configured FreqOptions.
 func (f *Forecaster) MakeFuturePeriods(periods int, freq time.Duration) ([]time.Time, error) {
 	td := f.TrainingData()
 	t := timedataset.TimeSlice(td.T)
@@ -419,7 +1021,7 @@ func (f *Forecaster) MakeFuturePeriods(periods int, freq time.Duration) ([]time.
 
 	if freq == 0 {
 		var err error
-		freq, err = t.EstimateFreq()
+		freq, err = inferFreq(t, f.opt.FreqOptions)
 		if err != nil {
 			return nil, err
 		}
@@ -432,89 +1034,6 @@ func (f *Forecaster) MakeFuturePeriods(periods int, freq time.Duration) ([]time.
 	return horizon, nil
 }
 
-// PlotOpts sets the horizon to forecast out. By default will use 10% of the training size assuming
-// even intervals between points and the first two points are used to infer the horizon interval.
-type PlotOpts struct {
-	HorizonCnt      int
-	HorizonInterval time.Duration
-}
-
-// PlotFit uses the Apache Echarts library to generate an html file showing the resulting fit,
-// model components, and fit residual
-func (f *Forecaster) PlotFit(w io.Writer, opt *PlotOpts) error {
-	td := f.TrainingData()
-
-	horizonCnt := len(td.T) / 10
-	var horizonInterval time.Duration
-	if opt != nil {
-		horizonCnt = opt.HorizonCnt
-		horizonInterval = opt.HorizonInterval
-	}
-	if horizonCnt < 1 {
-		horizonCnt = 1
-	}
-	horizon, err := f.MakeFuturePeriods(horizonCnt, horizonInterval)
-	if err != nil {
-		return err
-	}
-
-	t := make([]time.Time, len(td.T), len(td.T)+horizonCnt)
-	copy(t, td.T)
-	t = append(t, horizon...)
-
-	zpad := make([]float64, 0, horizonCnt)
-	for i := 0; i < horizonCnt; i++ {
-		zpad = append(zpad, math.NaN())
-	}
-
-	forecastRes, err := f.Predict(horizon)
-	if err != nil {
-		return fmt.Errorf("unable to predict with horizon, %w", err)
-	}
-
-	residuals := f.Residuals()
-	residuals = append(residuals, zpad...)
-
-	uncertainty := f.Uncertainty()
-	uncertainty = append(uncertainty, zpad...)
-
-	trendComp := f.TrendComponent()
-	trendComp = append(trendComp, forecastRes.SeriesComponents.Trend...)
-
-	seasonComp := f.SeasonalityComponent()
-	seasonComp = append(seasonComp, forecastRes.SeriesComponents.Seasonality...)
-
-	eventComp := f.EventComponent()
-	eventComp = append(eventComp, forecastRes.SeriesComponents.Event...)
-
-	page := components.NewPage()
-	page.AddCharts(
-		LineForecaster(td, f.fitResults, forecastRes),
-		LineTSeries(
-			"Forecast Components",
-			[]string{"Trend", "Seasonality", "Event"},
-			t,
-			[][]float64{
-				trendComp,
-				seasonComp,
-				eventComp,
-			},
-			len(td.T),
-		),
-		LineTSeries(
-			"Forecast Residual",
-			[]string{"Residual", "Uncertainty"},
-			t,
-			[][]float64{
-				residuals,
-				uncertainty,
-			},
-			len(td.T),
-		),
-	)
-	return page.Render(w)
-}
-
 func (f *Forecaster) clip(series []float64) {
 	var clipMin, clipMax bool
 	var minVal, maxVal float64
@@ -526,6 +1045,19 @@ func (f *Forecaster) clip(series []float64) {
 		clipMax = true
 		maxVal = *f.opt.MaxValue
 	}
+	if f.opt.Counter != nil && (!clipMin || minVal < 0) {
+		clipMin = true
+		minVal = 0
+	}
+	if c := f.opt.ContextualClip; c != nil && c.Max >= c.Min {
+		if !clipMin || c.Min > minVal {
+			minVal = c.Min
+		}
+		if !clipMax || c.Max < maxVal {
+			maxVal = c.Max
+		}
+		clipMin, clipMax = true, true
+	}
 	if !clipMin && !clipMax {
 		return
 	}
@@ -1,21 +1,26 @@
 package forecaster
 
 import (
+	"context"
 	"fmt"
 	"math"
+	"math/rand"
 	"os"
 	"runtime/debug"
 	"testing"
 	"time"
 
 	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat"
 
+	"github.com/aouyang1/go-forecaster/errs"
 	"github.com/aouyang1/go-forecaster/feature"
 	"github.com/aouyang1/go-forecaster/forecast"
 	"github.com/aouyang1/go-forecaster/forecast/options"
 	"github.com/aouyang1/go-forecaster/timedataset"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"gonum.org/v1/gonum/floats"
 )
 
 func compareScores(t *testing.T, expected, actual *forecast.Scores, msg string) {
@@ -348,6 +353,8 @@ func TestForecaster(t *testing.T) {
 			err = f.Fit(td.t, td.y)
 			if td.expectedErr != nil {
 				require.ErrorAs(t, err, &td.expectedErr)
+				assert.Equal(t, errs.CodeOf(td.expectedErr), errs.CodeOf(err))
+				assert.Equal(t, errs.KindData, errs.KindOf(err))
 				return
 			}
 			require.Nil(t, err)
@@ -419,12 +426,7 @@ func runForecastExample(opt *Options, t []time.Time, y []float64, filename strin
 		return err
 	}
 
-	file, err := os.Create(filename)
-	if err != nil {
-		return err
-	}
-
-	return f.PlotFit(file, nil)
+	return writeExamplePlot(f, filename)
 }
 
 func recoverForecastPanic() {
@@ -434,7 +436,7 @@ func recoverForecastPanic() {
 	}
 }
 
-func ExampleForecasterWithOutliers() {
+func ExampleForecaster_withOutliers() {
 	t, y := generateExampleSeries()
 
 	changepoints := []options.Changepoint{
@@ -521,7 +523,7 @@ func generateExampleSeriesWithTrend() ([]time.Time, []float64) {
 	return t, y
 }
 
-func ExampleForecasterAutoChangepoint() {
+func ExampleForecaster_autoChangepoint() {
 	t, y := generateExampleSeries()
 
 	regularization := []float64{0.0, 1.0, 10.0, 100.0, 1000.0, 10000.0}
@@ -576,7 +578,7 @@ func ExampleForecasterAutoChangepoint() {
 	// Output:
 }
 
-func ExampleForecasterWithTrend() {
+func ExampleForecaster_withTrend() {
 	t, y := generateExampleSeriesWithTrend()
 
 	changepoints := []options.Changepoint{
@@ -648,3 +650,934 @@ func TestMatrixMulWithNaN(t *testing.T) {
 	fmt.Printf("c = %v", fc)
 	// Output: c = [16  0  12  NaN]
 }
+
+func TestMergeNaNReasons(t *testing.T) {
+	testData := map[string]struct {
+		series      []string
+		uncertainty []string
+		expected    []string
+	}{
+		"none": {},
+		"series only": {
+			series:   []string{"", "nan feature a"},
+			expected: []string{"", "series nan feature a"},
+		},
+		"both": {
+			series:      []string{"nan feature a", ""},
+			uncertainty: []string{"nan feature b", ""},
+			expected:    []string{"series nan feature a; uncertainty nan feature b", ""},
+		},
+	}
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, td.expected, mergeNaNReasons(2, td.series, td.uncertainty))
+		})
+	}
+}
+
+func TestMergeMissingFeatures(t *testing.T) {
+	testData := map[string]struct {
+		series      []string
+		uncertainty []string
+		expected    []string
+	}{
+		"none":        {},
+		"series only": {series: []string{"temp"}, expected: []string{"temp"}},
+		"both": {
+			series:      []string{"temp", "promo"},
+			uncertainty: []string{"promo"},
+			expected:    []string{"promo", "temp"},
+		},
+	}
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, td.expected, mergeMissingFeatures(td.series, td.uncertainty))
+		})
+	}
+}
+
+func TestFitResidualFilter(t *testing.T) {
+	// series model without seasonality leaves the daily wave in the residual
+	n := 4 * 24 * 6
+	tWin := timedataset.GenerateT(n, 10*time.Minute, time.Now)
+	rng := rand.New(rand.NewSource(1))
+	y := timedataset.GenerateWaveY(tWin, 20.0, 86400.0, 1.0, 0.0)
+	for i := range y {
+		y[i] += rng.NormFloat64()
+	}
+
+	// mean rolling standard deviation of the residual which is 1.0 if only the noise is captured
+	meanUncertainty := func(opt *UncertaintyOptions) (float64, error) {
+		f, err := New(&Options{
+			SeriesOptions: &SeriesOptions{
+				ForecastOptions: &options.Options{},
+				OutlierOptions:  &OutlierOptions{},
+			},
+			UncertaintyOptions: opt,
+		})
+		if err != nil {
+			return 0, err
+		}
+		yCopy := make([]float64, n)
+		copy(yCopy, y)
+		if err := f.Fit(tWin, yCopy); err != nil {
+			return 0, err
+		}
+		uncertainty := make([]float64, 0, n)
+		for _, u := range f.Uncertainty() {
+			if !math.IsNaN(u) {
+				uncertainty = append(uncertainty, u)
+			}
+		}
+		return stat.Mean(uncertainty, nil), nil
+	}
+
+	testData := map[string]struct {
+		filter ResidualFilter
+		window int
+		lag    int
+		err    error
+	}{
+		"median":          {filter: ResidualFilterMedian, window: 7},
+		"seasonal diff":   {filter: ResidualFilterSeasonalDiff, lag: 24 * 6},
+		"unknown":         {filter: "wavelet", err: ErrUnknownResidualFilter},
+		"small window":    {filter: ResidualFilterMedian, window: 1, err: ErrInvalidFilterWindow},
+		"lag beyond data": {filter: ResidualFilterSeasonalDiff, lag: n, err: ErrInvalidSeasonalLag},
+	}
+
+	raw, err := meanUncertainty(&UncertaintyOptions{
+		ForecastOptions: &options.Options{},
+		ResidualWindow:  12,
+		ResidualZscore:  1.0,
+	})
+	require.Nil(t, err)
+	assert.Greater(t, raw, 2.0)
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			filtered, err := meanUncertainty(&UncertaintyOptions{
+				ForecastOptions: &options.Options{},
+				ResidualWindow:  12,
+				ResidualZscore:  1.0,
+				ResidualFilter:  td.filter,
+				FilterWindow:    td.window,
+				SeasonalLag:     td.lag,
+			})
+			if td.err != nil {
+				assert.ErrorIs(t, err, td.err)
+				return
+			}
+			require.Nil(t, err)
+			assert.InDelta(t, 1.0, filtered, 0.2)
+		})
+	}
+}
+
+func TestFitResidualWindowDuration(t *testing.T) {
+	testData := map[string]struct {
+		freq     time.Duration
+		expected int
+	}{
+		"10 minute": {freq: 10 * time.Minute, expected: 36},
+		"30 minute": {freq: 30 * time.Minute, expected: 12},
+		"hourly":    {freq: time.Hour, expected: 6},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			n := int(4 * 24 * time.Hour / td.freq)
+			tWin := timedataset.GenerateT(n, td.freq, time.Now)
+			y := timedataset.GenerateWaveY(tWin, 2.0, 86400.0, 1.0, 0.0)
+
+			opt := &Options{
+				SeriesOptions: &SeriesOptions{
+					ForecastOptions: &options.Options{},
+					OutlierOptions:  &OutlierOptions{},
+				},
+				UncertaintyOptions: &UncertaintyOptions{
+					ForecastOptions:        &options.Options{},
+					ResidualWindowDuration: 6 * time.Hour,
+					ResidualZscore:         1.0,
+				},
+			}
+			f, err := New(opt)
+			require.Nil(t, err)
+			require.Nil(t, f.Fit(tWin, y))
+			assert.Equal(t, td.expected, opt.UncertaintyOptions.ResidualWindow)
+		})
+	}
+}
+
+func TestGenerateUncertaintySeriesCenters(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	minutes := func(m ...float64) []time.Time {
+		res := make([]time.Time, 0, len(m))
+		for _, v := range m {
+			res = append(res, start.Add(time.Duration(v*float64(time.Minute))))
+		}
+		return res
+	}
+	regular := minutes(0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11)
+	observed := []float64{1, -1, 1, -1, 1, -1, 1, -1, 1, -1, 1, -1}
+	nan := math.NaN()
+
+	testData := map[string]struct {
+		t        []time.Time
+		residual []float64
+		window   int
+		expected []time.Time
+	}{
+		"odd window": {
+			t:        regular,
+			residual: observed,
+			window:   3,
+			expected: minutes(1, 2, 3, 4, 5, 6, 7, 8, 9, 10),
+		},
+		"even window": {
+			t:        regular,
+			residual: observed,
+			window:   2,
+			expected: minutes(0.5, 1.5, 2.5, 3.5, 4.5, 5.5, 6.5, 7.5, 8.5, 9.5, 10.5),
+		},
+		"gap": {
+			t:        minutes(0, 1, 2, 3, 4, 5, 20, 21, 22, 23, 24, 25),
+			residual: observed,
+			window:   3,
+			expected: minutes(1, 2, 3, 4, 29.0/3.0, 46.0/3.0, 21, 22, 23, 24),
+		},
+		"removed points": {
+			t:        regular,
+			residual: []float64{1, -1, 1, nan, nan, nan, 1, -1, 1, -1, 1, -1},
+			window:   3,
+			expected: minutes(1, 1.5, 2, 6, 6.5, 7, 8, 9, 10),
+		},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			f := &Forecaster{
+				opt: &Options{
+					UncertaintyOptions: &UncertaintyOptions{
+						ResidualWindow: td.window,
+						ResidualZscore: 1.0,
+					},
+				},
+			}
+			centers, series, err := f.generateUncertaintySeries(td.t, td.residual, nil)
+			require.Nil(t, err)
+			require.Len(t, series, len(centers))
+			require.Len(t, centers, len(td.expected))
+			for i, center := range centers {
+				assert.InDelta(t, 0, center.Sub(td.expected[i]).Seconds(), 1e-6, "center %d", i)
+			}
+		})
+	}
+}
+
+func TestAlignWindowCenters(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tWin := timedataset.GenerateT(6, time.Minute, func() time.Time { return start.Add(6 * time.Minute) })
+	centers := []time.Time{start.Add(time.Minute), start.Add(90 * time.Second), start.Add(4 * time.Minute)}
+	vals := []float64{2.0, 3.0, 6.0}
+
+	res := alignWindowCenters(tWin, centers, vals)
+	require.Len(t, res, len(tWin))
+	assert.True(t, math.IsNaN(res[0]))
+	assert.InDeltaSlice(t, []float64{2.0, 3.6, 4.8, 6.0}, res[1:5], 1e-9)
+	assert.True(t, math.IsNaN(res[5]))
+}
+
+func TestFitWithVariance(t *testing.T) {
+	n := 4 * 24 * 6
+	tWin := timedataset.GenerateT(n, 10*time.Minute, func() time.Time {
+		return time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC)
+	})
+	r := rand.New(rand.NewSource(7))
+
+	// every other point is aggregated from far fewer samples and is much noisier
+	variance := make([]float64, n)
+	y := timedataset.GenerateWaveY(tWin, 2.0, 86400.0, 1.0, 0.0)
+	for i := range y {
+		variance[i] = 0.01
+		if i%2 == 1 {
+			variance[i] = 4.0
+		}
+		y[i] += 5.0 + math.Sqrt(variance[i])*r.NormFloat64()
+	}
+
+	newForecaster := func() *Forecaster {
+		opt := &Options{
+			SeriesOptions: &SeriesOptions{
+				ForecastOptions: &options.Options{
+					SeasonalityOptions: options.SeasonalityOptions{
+						SeasonalityConfigs: []options.SeasonalityConfig{options.NewDailySeasonalityConfig(1)},
+					},
+				},
+			},
+			UncertaintyOptions: &UncertaintyOptions{
+				ForecastOptions: &options.Options{},
+				ResidualWindow:  36,
+				ResidualZscore:  1.0,
+			},
+		}
+		f, err := New(opt)
+		require.Nil(t, err)
+		return f
+	}
+
+	unweighted := newForecaster()
+	require.Nil(t, unweighted.Fit(tWin, append([]float64(nil), y...)))
+	weighted := newForecaster()
+	require.Nil(t, weighted.FitWithVariance(tWin, append([]float64(nil), y...), variance))
+
+	// the inverse variance weighted rolling deviation is dominated by the precise points
+	nanMean := func(vals []float64) float64 {
+		var sum float64
+		var cnt int
+		for _, v := range vals {
+			if !math.IsNaN(v) {
+				sum += v
+				cnt++
+			}
+		}
+		return sum / float64(cnt)
+	}
+	assert.Less(t, nanMean(weighted.Uncertainty()), nanMean(unweighted.Uncertainty())/2)
+
+	f := newForecaster()
+	assert.ErrorIs(t, f.FitWithVariance(tWin, y, variance[1:]), ErrMismatchedVarianceLen)
+	variance[3] = 0
+	assert.ErrorIs(t, f.FitWithVariance(tWin, y, variance), ErrInvalidVariance)
+}
+
+func TestFitExcludeRecent(t *testing.T) {
+	// linear trend where the trailing 6 hours have only been partially ingested
+	n := 4 * 24 * 4
+	tWin := timedataset.GenerateT(n, 15*time.Minute, func() time.Time {
+		return time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC)
+	})
+	trend := func(i int) float64 { return 100.0 + 0.1*float64(i) }
+	incomplete := 6 * 4
+	y := make([]float64, n)
+	for i := range y {
+		y[i] = trend(i)
+		if i >= n-incomplete {
+			y[i] *= 0.2
+		}
+	}
+
+	testData := map[string]struct {
+		excludeRecent time.Duration
+		withinTrend   bool
+	}{
+		"disabled": {0, false},
+		"excluded": {6 * time.Hour, true},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			opt := &Options{
+				SeriesOptions: &SeriesOptions{
+					ForecastOptions: &options.Options{
+						ChangepointOptions: options.ChangepointOptions{
+							Changepoints: []options.Changepoint{
+								options.NewChangepoint("trendstart", tWin[0]),
+							},
+							EnableGrowth: true,
+						},
+						Regularization: []float64{0.0},
+						Iterations:     500,
+						Tolerance:      1e-6,
+					},
+					OutlierOptions: &OutlierOptions{},
+				},
+				UncertaintyOptions: &UncertaintyOptions{
+					ForecastOptions: &options.Options{},
+					ResidualWindow:  10,
+					ResidualZscore:  1.0,
+				},
+				ExcludeRecent: td.excludeRecent,
+			}
+			f, err := New(opt)
+			require.Nil(t, err)
+			require.Nil(t, f.Fit(tWin, y))
+
+			res := f.FitResults()
+			require.Len(t, res.Forecast, n)
+			last := res.Forecast[n-1]
+			if td.withinTrend {
+				assert.InDelta(t, trend(n-1), last, 1e-3)
+				assert.True(t, math.IsNaN(f.Residuals()[n-1]))
+				return
+			}
+			assert.Greater(t, math.Abs(trend(n-1)-last), 1.0)
+		})
+	}
+}
+
+func TestContextualClip(t *testing.T) {
+	n := 4 * 24
+	tWin := timedataset.GenerateT(n, time.Hour, func() time.Time {
+		return time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC)
+	})
+	y := make([]float64, n)
+	for i := range y {
+		y[i] = 100.0 + float64(i) + float64(i%2)
+	}
+	horizon := timedataset.GenerateT(n, time.Hour, func() time.Time {
+		return tWin[n-1].Add(time.Duration(n+1) * time.Hour)
+	})
+
+	testData := map[string]struct {
+		clip *ContextualClipOptions
+		err  error
+	}{
+		"disabled": {},
+		"history range": {
+			clip: NewContextualClipOptions(),
+		},
+		"invalid quantiles": {
+			clip: &ContextualClipOptions{LowerQuantile: 0.9, UpperQuantile: 0.1},
+			err:  ErrInvalidClipQuantiles,
+		},
+		"invalid multiplier": {
+			clip: &ContextualClipOptions{UpperQuantile: 1.0, UncertaintyMultiplier: -1.0},
+			err:  ErrInvalidClipMultiplier,
+		},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			opt := &Options{
+				SeriesOptions: &SeriesOptions{
+					ForecastOptions: &options.Options{
+						ChangepointOptions: options.ChangepointOptions{
+							Changepoints: []options.Changepoint{
+								options.NewChangepoint("trendstart", tWin[0]),
+							},
+							EnableGrowth: true,
+						},
+						Regularization: []float64{0.0},
+						Iterations:     500,
+						Tolerance:      1e-6,
+					},
+					OutlierOptions: nil,
+				},
+				UncertaintyOptions: &UncertaintyOptions{
+					ForecastOptions: &options.Options{},
+					ResidualWindow:  10,
+					ResidualZscore:  2.0,
+				},
+				ContextualClip: td.clip,
+			}
+			yCopy := make([]float64, n)
+			copy(yCopy, y)
+
+			f, err := New(opt)
+			require.Nil(t, err)
+			err = f.Fit(tWin, yCopy)
+			if td.err != nil {
+				assert.ErrorIs(t, err, td.err)
+				return
+			}
+			require.Nil(t, err)
+
+			res, err := f.Predict(horizon)
+			require.Nil(t, err)
+			if td.clip == nil {
+				assert.Greater(t, floats.Max(res.Upper), 1.4*floats.Max(y))
+				return
+			}
+
+			assert.Less(t, td.clip.Min, floats.Min(y))
+			assert.Greater(t, td.clip.Max, floats.Max(y))
+			assert.Less(t, td.clip.Max, floats.Max(y)+5.0)
+			for i := range res.Forecast {
+				assert.LessOrEqual(t, res.Upper[i], td.clip.Max)
+				assert.LessOrEqual(t, res.Forecast[i], td.clip.Max)
+				assert.GreaterOrEqual(t, res.Lower[i], td.clip.Min)
+			}
+			assert.Equal(t, td.clip.Max, res.Forecast[n-1])
+		})
+	}
+}
+
+func TestFitHourlyCalibration(t *testing.T) {
+	n := 14 * 24
+	tWin := timedataset.GenerateT(n, time.Hour, func() time.Time {
+		return time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	})
+
+	testData := map[string]struct {
+		calibrate bool
+		quantile  float64
+		timezone  string
+		err       error
+	}{
+		"uncalibrated":          {},
+		"calibrated":            {calibrate: true, quantile: 0.8},
+		"calibrated local hour": {calibrate: true, quantile: 0.8, timezone: "Asia/Tokyo"},
+		"invalid quantile":      {calibrate: true, quantile: 1.5, err: ErrInvalidCalibrationQuantile},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			loc := time.UTC
+			if td.timezone != "" {
+				var err error
+				loc, err = time.LoadLocation(td.timezone)
+				require.Nil(t, err)
+			}
+
+			// noise and the indexes of the quiet and noisy hours follow the local hour of day
+			rng := rand.New(rand.NewSource(7))
+			y := make([]float64, n)
+			quietIdx, noisyIdx := -1, -1
+			for i, tPnt := range tWin {
+				hour := tPnt.In(loc).Hour()
+				noise := 0.1
+				if hour >= 12 {
+					noise = 2.0
+				}
+				y[i] = 10.0 + noise*rng.NormFloat64()
+				if hour == 3 && quietIdx < 0 {
+					quietIdx = i
+				}
+				if hour == 15 && noisyIdx < 0 {
+					noisyIdx = i
+				}
+			}
+
+			opt := &Options{
+				SeriesOptions: &SeriesOptions{
+					ForecastOptions: &options.Options{
+						WeekendOptions: options.WeekendOptions{TimezoneOverride: td.timezone},
+					},
+				},
+				UncertaintyOptions: &UncertaintyOptions{
+					ForecastOptions:     &options.Options{},
+					ResidualWindow:      24,
+					ResidualZscore:      2.0,
+					HourlyCalibration:   td.calibrate,
+					CalibrationQuantile: td.quantile,
+				},
+			}
+			yCopy := make([]float64, n)
+			copy(yCopy, y)
+
+			f, err := New(opt)
+			require.Nil(t, err)
+			err = f.Fit(tWin, yCopy)
+			if td.err != nil {
+				assert.ErrorIs(t, err, td.err)
+				return
+			}
+			require.Nil(t, err)
+
+			mults := opt.UncertaintyOptions.HourlyMultipliers
+			res := f.FitResults()
+			quietWidth := res.Upper[quietIdx] - res.Lower[quietIdx]
+			noisyWidth := res.Upper[noisyIdx] - res.Lower[noisyIdx]
+			if !td.calibrate {
+				assert.Nil(t, mults)
+				assert.InDelta(t, quietWidth, noisyWidth, 1e-6)
+				return
+			}
+
+			require.Len(t, mults, 24)
+			assert.Less(t, mults[3], mults[15])
+			assert.Less(t, 5.0*quietWidth, noisyWidth)
+
+			eval, err := res.ScoreAgainst(y)
+			require.Nil(t, err)
+			assert.GreaterOrEqual(t, eval.Coverage, td.quantile)
+			assert.Less(t, eval.Coverage, 0.9)
+		})
+	}
+}
+
+func TestFitTrendUncertainty(t *testing.T) {
+	n := 7 * 24
+	tWin := timedataset.GenerateT(n, time.Hour, func() time.Time {
+		return time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC)
+	})
+	rng := rand.New(rand.NewSource(3))
+	y := make([]float64, n)
+	for i := range y {
+		y[i] = 10.0 + 0.05*float64(i) + rng.NormFloat64()
+	}
+	trainEnd := tWin[n-1]
+	horizon := []time.Time{trainEnd.Add(time.Hour), trainEnd.Add(14 * 24 * time.Hour)}
+
+	testData := map[string]struct {
+		bootstraps int
+		err        error
+	}{
+		"disabled":            {},
+		"bootstrapped":        {bootstraps: 20},
+		"negative bootstraps": {bootstraps: -1, err: ErrNegativeTrendBootstraps},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			opt := &Options{
+				SeriesOptions: &SeriesOptions{
+					ForecastOptions: &options.Options{
+						ChangepointOptions: options.ChangepointOptions{
+							Changepoints: []options.Changepoint{
+								options.NewChangepoint("trendstart", tWin[0]),
+							},
+							EnableGrowth: true,
+						},
+						Regularization: []float64{0.0},
+						Iterations:     500,
+						Tolerance:      1e-6,
+					},
+				},
+				UncertaintyOptions: &UncertaintyOptions{
+					ForecastOptions:    &options.Options{},
+					ResidualWindow:     24,
+					ResidualZscore:     2.0,
+					TrendBootstraps:    td.bootstraps,
+					TrendBootstrapSeed: 1,
+				},
+			}
+			f, err := New(opt)
+			require.Nil(t, err)
+			err = f.Fit(tWin, y)
+			if td.err != nil {
+				assert.ErrorIs(t, err, td.err)
+				return
+			}
+			require.Nil(t, err)
+
+			res, err := f.Predict(horizon)
+			require.Nil(t, err)
+			nearWidth := res.Upper[0] - res.Lower[0]
+			farWidth := res.Upper[1] - res.Lower[1]
+			if td.bootstraps == 0 {
+				assert.Nil(t, res.ShortTermUncertainty)
+				assert.Nil(t, res.LongTermUncertainty)
+				assert.InDelta(t, nearWidth, farWidth, 1e-6)
+				return
+			}
+
+			u := opt.UncertaintyOptions
+			assert.Greater(t, u.TrendLevelStd, 0.0)
+			assert.Greater(t, u.TrendSlopeStd, 0.0)
+			require.Len(t, res.LongTermUncertainty, len(horizon))
+			assert.Less(t, res.LongTermUncertainty[0], res.ShortTermUncertainty[0])
+			assert.Less(t, 3.0*res.LongTermUncertainty[0], res.LongTermUncertainty[1])
+			assert.Less(t, nearWidth, farWidth)
+			for i := range horizon {
+				expected := math.Hypot(res.ShortTermUncertainty[i], res.LongTermUncertainty[i])
+				assert.InDelta(t, expected, res.Upper[i]-res.Forecast[i], 1e-9)
+			}
+
+			// the trend spread is persisted with the model
+			model, err := f.Model()
+			require.Nil(t, err)
+			loaded, err := NewFromModel(model)
+			require.Nil(t, err)
+			loadedRes, err := loaded.Predict(horizon)
+			require.Nil(t, err)
+			assert.InDeltaSlice(t, res.Upper, loadedRes.Upper, 1e-9)
+		})
+	}
+}
+
+func TestNewFromModelMaxAge(t *testing.T) {
+	n := 4 * 24
+	tWin := timedataset.GenerateT(n, 15*time.Minute, func() time.Time {
+		return time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC)
+	})
+	y := make([]float64, n)
+	for i := range y {
+		y[i] = 10.0 + float64(i%4)
+	}
+	opt := &Options{
+		SeriesOptions: &SeriesOptions{
+			ForecastOptions: &options.Options{},
+			OutlierOptions:  &OutlierOptions{},
+		},
+		UncertaintyOptions: &UncertaintyOptions{
+			ForecastOptions: &options.Options{},
+			ResidualWindow:  10,
+			ResidualZscore:  1.0,
+		},
+	}
+	f, err := New(opt)
+	require.Nil(t, err)
+	require.Nil(t, f.Fit(tWin, y))
+	model, err := f.Model()
+	require.Nil(t, err)
+
+	trainEnd := tWin[n-1]
+	testData := map[string]struct {
+		modelOpts []ModelOption
+		t         []time.Time
+		stale     bool
+		err       error
+	}{
+		"no guard": {
+			t: []time.Time{trainEnd.Add(365 * 24 * time.Hour)},
+		},
+		"within max age": {
+			modelOpts: []ModelOption{WithMaxAge(24 * time.Hour)},
+			t:         []time.Time{trainEnd.Add(time.Hour), trainEnd.Add(24 * time.Hour)},
+		},
+		"past max age": {
+			modelOpts: []ModelOption{WithMaxAge(24 * time.Hour)},
+			t:         []time.Time{trainEnd.Add(time.Hour), trainEnd.Add(25 * time.Hour)},
+			err:       ErrStaleModel,
+		},
+		"past max age warning": {
+			modelOpts: []ModelOption{WithMaxAge(24 * time.Hour), WithStaleWarning()},
+			t:         []time.Time{trainEnd.Add(25 * time.Hour)},
+			stale:     true,
+		},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			loaded, err := NewFromModel(model, td.modelOpts...)
+			require.Nil(t, err)
+
+			res, err := loaded.Predict(td.t)
+			if td.err != nil {
+				assert.ErrorIs(t, err, td.err)
+				assert.Equal(t, errs.CodeStaleModel, errs.CodeOf(err))
+				return
+			}
+			require.Nil(t, err)
+			assert.Len(t, res.Forecast, len(td.t))
+			assert.Equal(t, td.stale, loaded.Stale())
+		})
+	}
+}
+
+func TestFitWithRegressors(t *testing.T) {
+	n := 2 * 24 * 6
+	tWin := timedataset.GenerateT(n, 10*time.Minute, func() time.Time {
+		return time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC)
+	})
+	temp := make([]float64, n)
+	y := make([]float64, n)
+	for i := range y {
+		temp[i] = math.Mod(float64(i)*0.37, 5.0)
+		y[i] = 5.0 + 2.0*temp[i]
+	}
+
+	opt := &Options{
+		SeriesOptions: &SeriesOptions{
+			ForecastOptions: &options.Options{
+				Regularization: []float64{0.0},
+			},
+		},
+		UncertaintyOptions: &UncertaintyOptions{
+			ForecastOptions: &options.Options{},
+			ResidualWindow:  36,
+			ResidualZscore:  1.0,
+		},
+	}
+	f, err := New(opt)
+	require.Nil(t, err)
+	require.Nil(t, f.FitWithRegressors(tWin, y, forecast.Regressors{"temp": temp}))
+
+	coef, err := f.SeriesCoefficients()
+	require.Nil(t, err)
+	assert.InDelta(t, 2.0, coef["reg_temp"], 1e-2)
+	assert.InDeltaSlice(t, y, f.FitResults().Forecast, 1e-2)
+
+	model, err := f.Model()
+	require.Nil(t, err)
+	loaded, err := NewFromModel(model)
+	require.Nil(t, err)
+
+	tPred := []time.Time{tWin[n-1].Add(10 * time.Minute)}
+	res, err := loaded.PredictWithRegressors(tPred, forecast.Regressors{"temp": {3.0}})
+	require.Nil(t, err)
+	assert.InDelta(t, 11.0, res.Forecast[0], 1e-2)
+	assert.InDelta(t, 6.0, res.SeriesComponents.Regressor[0], 1e-2)
+
+	_, err = loaded.Predict(tPred)
+	assert.ErrorIs(t, err, forecast.ErrMissingRegressor)
+}
+
+func TestMakeFuturePeriodsFreqOptions(t *testing.T) {
+	ct := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	// one minute sampling with a few seconds of jitter
+	tWin := []time.Time{ct, ct.Add(61 * time.Second), ct.Add(120 * time.Second), ct.Add(179 * time.Second), ct.Add(240 * time.Second), ct.Add(300 * time.Second)}
+	ds, err := timedataset.NewUnivariateDataset(tWin, make([]float64, len(tWin)))
+	require.Nil(t, err)
+
+	withTolerance := func(tol float64) *timedataset.FreqOptions {
+		freqOpt := &timedataset.FreqOptions{}
+		freqOpt.SetJitterTolerance(tol)
+		return freqOpt
+	}
+
+	testData := map[string]struct {
+		freqOpt  *timedataset.FreqOptions
+		expected time.Duration
+	}{
+		"default jitter tolerance": {expected: 60 * time.Second},
+		"exact mode":               {freqOpt: withTolerance(0), expected: 59 * time.Second},
+		"with jitter tolerance":    {freqOpt: withTolerance(0.1), expected: 60 * time.Second},
+	}
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			f := &Forecaster{opt: &Options{FreqOptions: td.freqOpt}, fitTrainingData: ds}
+			horizon, err := f.MakeFuturePeriods(2, 0)
+			require.Nil(t, err)
+			assert.Equal(t, []time.Time{tWin[5].Add(td.expected), tWin[5].Add(2 * td.expected)}, horizon)
+		})
+	}
+}
+
+func TestFitResample(t *testing.T) {
+	// daily wave sampled every 15 minutes with up to 30s of jitter and a dropped sample
+	n := 4 * 24 * 4
+	interval := 15 * time.Minute
+	grid := timedataset.GenerateT(n, interval, func() time.Time {
+		return time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC)
+	})
+	wave := func(tPnt time.Time) float64 {
+		return 10 + 3*math.Sin(2*math.Pi*float64(tPnt.Hour()*60+tPnt.Minute())/1440)
+	}
+	rng := rand.New(rand.NewSource(11))
+	var tWin []time.Time
+	var y []float64
+	for i, tPnt := range grid {
+		if i == n/2 {
+			continue
+		}
+		tWin = append(tWin, tPnt.Add(time.Duration(rng.Intn(61)-30)*time.Second))
+		y = append(y, wave(tPnt))
+	}
+
+	newOpt := func() *Options {
+		opt := &Options{
+			SeriesOptions: &SeriesOptions{
+				ForecastOptions: &options.Options{
+					SeasonalityOptions: options.SeasonalityOptions{
+						SeasonalityConfigs: []options.SeasonalityConfig{options.NewDailySeasonalityConfig(2)},
+					},
+				},
+				OutlierOptions: &OutlierOptions{},
+			},
+			UncertaintyOptions: &UncertaintyOptions{
+				ForecastOptions: &options.Options{},
+				ResidualWindow:  10,
+				ResidualZscore:  1.0,
+			},
+			Resample: &timedataset.ResampleOptions{},
+		}
+		opt.Resample.FreqOptions.SetJitterTolerance(0.1)
+		return opt
+	}
+
+	f, err := New(newOpt())
+	require.Nil(t, err)
+	require.Nil(t, f.Fit(tWin, y))
+
+	res := f.FitResults()
+	require.Len(t, res.T, n)
+	for i := range grid {
+		assert.True(t, grid[i].Equal(res.T[i]), "index %d, expected %s, got %s", i, grid[i], res.T[i])
+		assert.InDelta(t, wave(grid[i]), res.Forecast[i], 1e-2)
+	}
+	assert.True(t, math.IsNaN(f.Residuals()[n/2]))
+
+	f, err = New(newOpt())
+	require.Nil(t, err)
+	x := forecast.Regressors{"x": make([]float64, len(tWin))}
+	assert.ErrorIs(t, f.FitWithRegressors(tWin, y, x), ErrResampleUnaligned)
+}
+
+func TestForecasterScores(t *testing.T) {
+	n := 7 * 24
+	tWin := timedataset.GenerateT(n, time.Hour, func() time.Time {
+		return time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC)
+	})
+	rng := rand.New(rand.NewSource(5))
+	y := make([]float64, n)
+	for i := range y {
+		y[i] = 20 + 5*math.Sin(2*math.Pi*float64(i)/24) + rng.NormFloat64()
+	}
+
+	opt := NewDefaultOptions()
+	opt.SeriesOptions.ForecastOptions.SeasonalityOptions.SeasonalityConfigs = []options.SeasonalityConfig{
+		options.NewDailySeasonalityConfig(2),
+	}
+	opt.UncertaintyOptions.ForecastOptions.SeasonalityOptions.SeasonalityConfigs = []options.SeasonalityConfig{
+		options.NewDailySeasonalityConfig(2),
+	}
+	opt.UncertaintyOptions.ResidualWindow = 24
+	f, err := New(opt)
+	require.Nil(t, err)
+	assert.Nil(t, f.Scores())
+	require.Nil(t, f.Fit(tWin, y))
+
+	// the model beats a naive forecast of the same hour of the previous day with bands covering most
+	// of the training data at the interval level of the residual z-score
+	scores := f.Scores()
+	require.NotNil(t, scores)
+	assert.Less(t, scores.MASE, 1.0)
+	assert.Less(t, scores.SMAPE, 0.1)
+	assert.Greater(t, scores.MAE, 0.0)
+	assert.Greater(t, scores.Coverage, 0.9)
+	assert.Greater(t, scores.Pinball, 0.0)
+
+	model, err := f.Model()
+	require.Nil(t, err)
+	fLoaded, err := NewFromModel(model)
+	require.Nil(t, err)
+	assert.Equal(t, scores, fLoaded.Scores())
+}
+
+func TestForecasterFitCtx(t *testing.T) {
+	n := 7 * 24
+	tWin := timedataset.GenerateT(n, time.Hour, func() time.Time {
+		return time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC)
+	})
+	y := timedataset.GenerateConstY(n, 10.0).
+		Add(timedataset.GenerateWaveY(tWin, 3.0, 86400.0, 1.0, 0.0))
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	expired, cancelExpired := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancelExpired()
+
+	testData := map[string]struct {
+		ctx context.Context
+		err error
+	}{
+		"background": {ctx: context.Background()},
+		"canceled":   {ctx: canceled, err: context.Canceled},
+		"expired":    {ctx: expired, err: context.DeadlineExceeded},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			f, err := New(nil)
+			require.Nil(t, err)
+
+			err = f.FitCtx(td.ctx, tWin, y)
+			if td.err != nil {
+				assert.ErrorIs(t, err, td.err)
+				assert.Equal(t, errs.KindFit, errs.KindOf(err))
+				return
+			}
+			require.Nil(t, err)
+
+			res, err := f.PredictCtx(td.ctx, tWin)
+			require.Nil(t, err)
+			assert.InDeltaSlice(t, f.FitResults().Forecast, res.Forecast, 1e-9)
+
+			_, err = f.PredictCtx(canceled, tWin)
+			assert.ErrorIs(t, err, context.Canceled)
+			assert.Equal(t, errs.KindPredict, errs.KindOf(err))
+		})
+	}
+}
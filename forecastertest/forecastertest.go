@@ -0,0 +1,305 @@
+// Package forecastertest provides a golden model regression harness. Canonical synthetic series are fit
+// and the resulting coefficients and predictions are compared against golden files with tolerances so
+// that users embedding the library can detect behavioral changes when upgrading versions.
+package forecastertest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	forecaster "github.com/aouyang1/go-forecaster"
+	"github.com/aouyang1/go-forecaster/errs"
+	"github.com/aouyang1/go-forecaster/forecast"
+	"github.com/aouyang1/go-forecaster/forecast/options"
+	"github.com/aouyang1/go-forecaster/timedataset"
+)
+
+var ErrNoOptions = errs.NewConfigError(errs.CodeMissingOption, "no options constructor for golden case", nil)
+
+// Case is a named series to fit and the horizon to predict. Options constructs a fresh set of options
+// for every run since fitting updates them in place.
+type Case struct {
+	Name    string
+	T       []time.Time
+	Y       []float64
+	Horizon []time.Time
+	Options func() *forecaster.Options
+}
+
+// Tolerance is the allowed difference between a golden and an actual value as an absolute term plus a
+// term relative to the magnitude of the golden value
+type Tolerance struct {
+	Abs float64 `json:"abs"`
+	Rel float64 `json:"rel"`
+}
+
+// DefaultTolerance allows for floating point differences across platforms while still flagging any
+// behavioral change in the fit
+func DefaultTolerance() Tolerance {
+	return Tolerance{Abs: 1e-6, Rel: 1e-6}
+}
+
+func (tol Tolerance) within(expected, actual float64) bool {
+	if math.IsNaN(expected) || math.IsNaN(actual) {
+		return math.IsNaN(expected) && math.IsNaN(actual)
+	}
+	return math.Abs(expected-actual) <= tol.Abs+tol.Rel*math.Abs(expected)
+}
+
+// Golden is the serialized outcome of fitting a case. It holds the intercept and coefficients of the
+// series and uncertainty models keyed by feature label along with the predictions over the training
+// times followed by the horizon.
+type Golden struct {
+	SeriesIntercept         float64            `json:"series_intercept"`
+	SeriesCoefficients      map[string]float64 `json:"series_coefficients"`
+	UncertaintyIntercept    float64            `json:"uncertainty_intercept"`
+	UncertaintyCoefficients map[string]float64 `json:"uncertainty_coefficients"`
+
+	T        []time.Time `json:"time"`
+	Forecast []float64   `json:"forecast"`
+	Upper    []float64   `json:"upper"`
+	Lower    []float64   `json:"lower"`
+}
+
+// Run fits the case and returns its golden outcome
+func Run(c Case) (*Golden, error) {
+	if c.Options == nil {
+		return nil, fmt.Errorf("case %q, %w", c.Name, ErrNoOptions)
+	}
+	f, err := forecaster.New(c.Options())
+	if err != nil {
+		return nil, err
+	}
+
+	// fitting replaces outliers in the training values so train on a copy
+	y := make([]float64, len(c.Y))
+	copy(y, c.Y)
+	if err := f.Fit(c.T, y); err != nil {
+		return nil, fmt.Errorf("unable to fit case %q, %w", c.Name, err)
+	}
+
+	g := &Golden{
+		SeriesIntercept:      f.SeriesIntercept(),
+		UncertaintyIntercept: f.UncertaintyIntercept(),
+	}
+	if g.SeriesCoefficients, err = coefficients(f.SeriesCoefficients()); err != nil {
+		return nil, err
+	}
+	if g.UncertaintyCoefficients, err = coefficients(f.UncertaintyCoefficients()); err != nil {
+		return nil, err
+	}
+
+	t := make([]time.Time, 0, len(c.T)+len(c.Horizon))
+	t = append(t, c.T...)
+	t = append(t, c.Horizon...)
+	res, err := f.Predict(t)
+	if err != nil {
+		return nil, fmt.Errorf("unable to predict case %q, %w", c.Name, err)
+	}
+	g.T = res.T
+	g.Forecast = res.Forecast
+	g.Upper = res.Upper
+	g.Lower = res.Lower
+	return g, nil
+}
+
+// coefficients treats a model without coefficients as an empty set of coefficients
+func coefficients(coef map[string]float64, err error) (map[string]float64, error) {
+	if errors.Is(err, forecast.ErrNoModelCoefficients) {
+		return map[string]float64{}, nil
+	}
+	return coef, err
+}
+
+// Compare returns a description of every value of the actual outcome differing from the golden outcome
+// beyond the tolerance. Coefficients missing from either outcome are compared as zero.
+func (g *Golden) Compare(actual *Golden, tol Tolerance) []string {
+	var diffs []string
+	if !tol.within(g.SeriesIntercept, actual.SeriesIntercept) {
+		diffs = append(diffs, fmt.Sprintf("series intercept: golden %g, actual %g", g.SeriesIntercept, actual.SeriesIntercept))
+	}
+	diffs = append(diffs, compareCoefficients("series", g.SeriesCoefficients, actual.SeriesCoefficients, tol)...)
+	if !tol.within(g.UncertaintyIntercept, actual.UncertaintyIntercept) {
+		diffs = append(diffs, fmt.Sprintf("uncertainty intercept: golden %g, actual %g", g.UncertaintyIntercept, actual.UncertaintyIntercept))
+	}
+	diffs = append(diffs, compareCoefficients("uncertainty", g.UncertaintyCoefficients, actual.UncertaintyCoefficients, tol)...)
+
+	if len(g.T) != len(actual.T) {
+		return append(diffs, fmt.Sprintf("prediction length: golden %d, actual %d", len(g.T), len(actual.T)))
+	}
+	for i := range g.T {
+		if !g.T[i].Equal(actual.T[i]) {
+			diffs = append(diffs, fmt.Sprintf("time %d: golden %s, actual %s", i, g.T[i], actual.T[i]))
+			continue
+		}
+		diffs = append(diffs, compareSeries("forecast", i, g.T[i], g.Forecast, actual.Forecast, tol)...)
+		diffs = append(diffs, compareSeries("upper", i, g.T[i], g.Upper, actual.Upper, tol)...)
+		diffs = append(diffs, compareSeries("lower", i, g.T[i], g.Lower, actual.Lower, tol)...)
+	}
+	return diffs
+}
+
+func compareCoefficients(model string, expected, actual map[string]float64, tol Tolerance) []string {
+	labels := make(map[string]struct{}, len(expected)+len(actual))
+	for label := range expected {
+		labels[label] = struct{}{}
+	}
+	for label := range actual {
+		labels[label] = struct{}{}
+	}
+	sorted := make([]string, 0, len(labels))
+	for label := range labels {
+		sorted = append(sorted, label)
+	}
+	sort.Strings(sorted)
+
+	var diffs []string
+	for _, label := range sorted {
+		if !tol.within(expected[label], actual[label]) {
+			diffs = append(diffs, fmt.Sprintf("%s coefficient %s: golden %g, actual %g", model, label, expected[label], actual[label]))
+		}
+	}
+	return diffs
+}
+
+func compareSeries(name string, i int, t time.Time, expected, actual []float64, tol Tolerance) []string {
+	if i >= len(expected) || i >= len(actual) {
+		if len(expected) != len(actual) {
+			return []string{fmt.Sprintf("%s length: golden %d, actual %d", name, len(expected), len(actual))}
+		}
+		return nil
+	}
+	if tol.within(expected[i], actual[i]) {
+		return nil
+	}
+	return []string{fmt.Sprintf("%s at %s: golden %g, actual %g", name, t.Format(time.RFC3339), expected[i], actual[i])}
+}
+
+// ReadGolden loads a golden outcome from a JSON file
+func ReadGolden(path string) (*Golden, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var g Golden
+	if err := json.Unmarshal(data, &g); err != nil {
+		return nil, fmt.Errorf("unable to decode golden file %s, %w", path, err)
+	}
+	return &g, nil
+}
+
+// WriteGolden stores a golden outcome as a JSON file
+func WriteGolden(path string, g *Golden) error {
+	data, err := json.MarshalIndent(g, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// Check fits the case and compares it against the golden file named after the case in dir, failing the
+// test on any difference beyond the tolerance. Setting update rewrites the golden file instead.
+func Check(tb testing.TB, dir string, c Case, tol Tolerance, update bool) {
+	tb.Helper()
+
+	actual, err := Run(c)
+	if err != nil {
+		tb.Fatalf("unable to run golden case %q, %v", c.Name, err)
+	}
+	path := filepath.Join(dir, c.Name+".json")
+	if update {
+		if err := WriteGolden(path, actual); err != nil {
+			tb.Fatalf("unable to write golden file %s, %v", path, err)
+		}
+		return
+	}
+
+	golden, err := ReadGolden(path)
+	if err != nil {
+		tb.Fatalf("unable to read golden file %s, %v", path, err)
+	}
+	for _, diff := range golden.Compare(actual, tol) {
+		tb.Errorf("case %q, %s", c.Name, diff)
+	}
+}
+
+// CanonicalCases returns noise free synthetic series covering seasonality, trend changes, and weekend
+// behavior. The series start at a fixed time so the golden outcomes are reproducible.
+func CanonicalCases() []Case {
+	nowFunc := func() time.Time {
+		return time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	}
+	interval := time.Hour
+	n := 14 * 24
+	t := timedataset.GenerateT(n, interval, nowFunc)
+	horizon := timedataset.GenerateT(24, interval, func() time.Time {
+		return t[n-1].Add(25 * interval)
+	})
+
+	seasonal := timedataset.GenerateConstY(n, 10.0).
+		Add(timedataset.GenerateWaveY(t, 3.0, 86400.0, 1.0, 0.0)).
+		Add(timedataset.GenerateWaveY(t, 1.0, 86400.0, 2.0, 3600.0))
+
+	chpt := t[n/2]
+	trend := timedataset.GenerateConstY(n, 10.0).
+		Add(timedataset.GenerateWaveY(t, 2.0, 86400.0, 1.0, 0.0)).
+		Add(timedataset.GenerateChange(t, chpt, 5.0, 0.01))
+
+	weekend := timedataset.GenerateConstY(n, 10.0).
+		Add(timedataset.GenerateBusinessHoursY(t, 4.0, 9, 17, time.UTC))
+
+	return []Case{
+		{
+			Name:    "daily_seasonality",
+			T:       t,
+			Y:       seasonal,
+			Horizon: horizon,
+			Options: func() *forecaster.Options {
+				return newOptions(options.NewDailySeasonalityConfig(4))
+			},
+		},
+		{
+			Name:    "trend_changepoint",
+			T:       t,
+			Y:       trend,
+			Horizon: horizon,
+			Options: func() *forecaster.Options {
+				opt := newOptions(options.NewDailySeasonalityConfig(2))
+				opt.SeriesOptions.ForecastOptions.ChangepointOptions.Changepoints = []options.Changepoint{
+					options.NewChangepoint("shift", chpt),
+				}
+				opt.SeriesOptions.ForecastOptions.ChangepointOptions.EnableGrowth = true
+				return opt
+			},
+		},
+		{
+			Name:    "weekend_business_hours",
+			T:       t,
+			Y:       weekend,
+			Horizon: horizon,
+			Options: func() *forecaster.Options {
+				opt := newOptions(options.NewDailySeasonalityConfig(6))
+				opt.SeriesOptions.ForecastOptions.WeekendOptions.Enabled = true
+				return opt
+			},
+		},
+	}
+}
+
+func newOptions(seasCfgs ...options.SeasonalityConfig) *forecaster.Options {
+	opt := forecaster.NewDefaultOptions()
+	opt.SeriesOptions.ForecastOptions.SeasonalityOptions.SeasonalityConfigs = seasCfgs
+	opt.SeriesOptions.ForecastOptions.Iterations = 500
+	opt.SeriesOptions.ForecastOptions.Tolerance = 1e-6
+	opt.SeriesOptions.ForecastOptions.ChangepointOptions.Auto = false
+	opt.UncertaintyOptions.ForecastOptions.SeasonalityOptions.SeasonalityConfigs = seasCfgs
+	opt.UncertaintyOptions.ForecastOptions.ChangepointOptions.Auto = false
+	return opt
+}
@@ -0,0 +1,44 @@
+package forecastertest
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files")
+
+func TestCanonicalCases(t *testing.T) {
+	for _, c := range CanonicalCases() {
+		t.Run(c.Name, func(t *testing.T) {
+			Check(t, "testdata", c, DefaultTolerance(), *update)
+		})
+	}
+}
+
+func TestGoldenCompare(t *testing.T) {
+	c := CanonicalCases()[0]
+	golden, err := Run(c)
+	require.Nil(t, err)
+
+	actual, err := Run(c)
+	require.Nil(t, err)
+	assert.Empty(t, golden.Compare(actual, DefaultTolerance()))
+
+	actual.SeriesIntercept += 1.0
+	actual.SeriesCoefficients["new_feature"] = 0.5
+	actual.Forecast[0] += 1e-3
+	diffs := golden.Compare(actual, DefaultTolerance())
+	assert.Len(t, diffs, 3)
+	assert.Empty(t, golden.Compare(actual, Tolerance{Abs: 1.0}))
+
+	actual.T = actual.T[1:]
+	assert.Contains(t, golden.Compare(actual, DefaultTolerance()), "prediction length: golden 360, actual 359")
+}
+
+func TestRunNoOptions(t *testing.T) {
+	_, err := Run(Case{Name: "empty"})
+	assert.ErrorIs(t, err, ErrNoOptions)
+}
@@ -0,0 +1,132 @@
+package forecaster
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/errs"
+)
+
+var (
+	ErrUnknownGrowth       = errs.NewConfigError(errs.CodeInvalidOption, "unknown growth method", nil)
+	ErrInvalidCapacity     = errs.NewConfigError(errs.CodeInvalidOption, "growth cap must be greater than the floor", nil)
+	ErrUnsortedCapacity    = errs.NewConfigError(errs.CodeInvalidOption, "growth capacity schedule must be in ascending time order", nil)
+	ErrGrowthWithTransform = errs.NewConfigError(errs.CodeInvalidOption, "logistic growth cannot be combined with a transform", nil)
+	ErrGrowthDomain        = errs.NewDataError(errs.CodeInvalidValue, "value is outside of the growth floor and cap", nil)
+)
+
+// GrowthEpsilon bounds the scaled observations away from the floor and cap where the logit is infinite
+const GrowthEpsilon = 1e-3
+
+// GrowthMethod is the shape of the trend
+type GrowthMethod string
+
+const (
+	// GrowthLinear models a piecewise linear trend and is the default if unset
+	GrowthLinear GrowthMethod = "linear"
+
+	// GrowthLogistic models a trend that saturates at the cap and floor
+	GrowthLogistic GrowthMethod = "logistic"
+)
+
+// GrowthCapacity is the cap and floor starting at a point in time
+type GrowthCapacity struct {
+	T     time.Time `json:"time"`
+	Cap   float64   `json:"cap"`
+	Floor float64   `json:"floor"`
+}
+
+// GrowthOptions configures a logistic growth trend that saturates at the floor and cap like Prophet's
+// saturating growth. The series is fit in the logit space of its position between the floor and cap at
+// each time so a linear trend becomes a logistic curve and the forecast and its bands approach but
+// never cross the capacity, including after the end of training. Observations must lie between the
+// floor and cap and those at the boundary are moved inside by GrowthEpsilon of the capacity.
+//
+// Cap and Floor are constant unless a Schedule is set which varies the capacity over time by linearly
+// interpolating between the scheduled capacities. Times before the first or after the last scheduled
+// capacity use the first or last so a forecast beyond the schedule saturates at the last capacity.
+type GrowthOptions struct {
+	Method   GrowthMethod     `json:"method"`
+	Cap      float64          `json:"cap,omitempty"`
+	Floor    float64          `json:"floor,omitempty"`
+	Schedule []GrowthCapacity `json:"schedule,omitempty"`
+}
+
+// logistic returns true if the trend saturates
+func (o *GrowthOptions) logistic() bool {
+	return o != nil && o.Method == GrowthLogistic
+}
+
+// Validate returns an error if the method is unknown, any cap is not above its floor, or the schedule
+// is out of order
+func (o *GrowthOptions) Validate() error {
+	switch o.Method {
+	case "", GrowthLinear:
+		return nil
+	case GrowthLogistic:
+	default:
+		return fmt.Errorf("%q, %w", o.Method, ErrUnknownGrowth)
+	}
+	if len(o.Schedule) == 0 && !(o.Cap > o.Floor) {
+		return fmt.Errorf("cap of %.3f and floor of %.3f, %w", o.Cap, o.Floor, ErrInvalidCapacity)
+	}
+	for i, c := range o.Schedule {
+		if !(c.Cap > c.Floor) {
+			return fmt.Errorf("cap of %.3f and floor of %.3f at %s, %w", c.Cap, c.Floor, c.T, ErrInvalidCapacity)
+		}
+		if i > 0 && !c.T.After(o.Schedule[i-1].T) {
+			return fmt.Errorf("capacity at %s after %s, %w", c.T, o.Schedule[i-1].T, ErrUnsortedCapacity)
+		}
+	}
+	return nil
+}
+
+// capacity returns the cap and floor at the time
+func (o *GrowthOptions) capacity(t time.Time) (float64, float64) {
+	sched := o.Schedule
+	if len(sched) == 0 {
+		return o.Cap, o.Floor
+	}
+	if !t.After(sched[0].T) {
+		return sched[0].Cap, sched[0].Floor
+	}
+	for i := 1; i < len(sched); i++ {
+		if t.After(sched[i].T) {
+			continue
+		}
+		frac := float64(t.Sub(sched[i-1].T)) / float64(sched[i].T.Sub(sched[i-1].T))
+		return sched[i-1].Cap + frac*(sched[i].Cap-sched[i-1].Cap),
+			sched[i-1].Floor + frac*(sched[i].Floor-sched[i-1].Floor)
+	}
+	last := sched[len(sched)-1]
+	return last.Cap, last.Floor
+}
+
+// forward returns a copy of the values in the logit space of their position between the floor and cap.
+// NaN values are kept.
+func (o *GrowthOptions) forward(t []time.Time, y []float64) ([]float64, error) {
+	res := make([]float64, len(y))
+	for i, v := range y {
+		if math.IsNaN(v) {
+			res[i] = v
+			continue
+		}
+		ceiling, floor := o.capacity(t[i])
+		p := (v - floor) / (ceiling - floor)
+		if p < 0 || p > 1 {
+			return nil, fmt.Errorf("%.3f at index %d with floor of %.3f and cap of %.3f, %w", v, i, floor, ceiling, ErrGrowthDomain)
+		}
+		p = math.Min(math.Max(p, GrowthEpsilon), 1-GrowthEpsilon)
+		res[i] = math.Log(p / (1 - p))
+	}
+	return res, nil
+}
+
+// inverse maps the logit values back between the floor and cap at each time in place
+func (o *GrowthOptions) inverse(t []time.Time, y []float64) {
+	for i, v := range y {
+		ceiling, floor := o.capacity(t[i])
+		y[i] = floor + (ceiling-floor)/(1+math.Exp(-v))
+	}
+}
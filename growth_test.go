@@ -0,0 +1,155 @@
+package forecaster
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/forecast/options"
+	"github.com/aouyang1/go-forecaster/timedataset"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGrowthOptions(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tWin := []time.Time{t0.Add(-time.Hour), t0, t0.Add(time.Hour), t0.Add(2 * time.Hour), t0.Add(3 * time.Hour)}
+	schedule := []GrowthCapacity{
+		{T: t0, Cap: 10, Floor: 0},
+		{T: t0.Add(2 * time.Hour), Cap: 20, Floor: 4},
+	}
+
+	testData := map[string]struct {
+		opt      GrowthOptions
+		y        []float64
+		expected [][2]float64
+		err      error
+	}{
+		"constant": {
+			opt:      GrowthOptions{Method: GrowthLogistic, Cap: 10, Floor: 2},
+			y:        []float64{2, 3, math.NaN(), 9, 10},
+			expected: [][2]float64{{10, 2}, {10, 2}, {10, 2}, {10, 2}, {10, 2}},
+		},
+		"schedule": {
+			opt:      GrowthOptions{Method: GrowthLogistic, Schedule: schedule},
+			y:        []float64{1, 5, 8, 12, 19},
+			expected: [][2]float64{{10, 0}, {10, 0}, {15, 2}, {20, 4}, {20, 4}},
+		},
+		"linear": {
+			opt: GrowthOptions{Method: GrowthLinear},
+		},
+		"unknown method": {
+			opt: GrowthOptions{Method: "exponential"},
+			err: ErrUnknownGrowth,
+		},
+		"cap below floor": {
+			opt: GrowthOptions{Method: GrowthLogistic, Cap: 1, Floor: 2},
+			err: ErrInvalidCapacity,
+		},
+		"unsorted schedule": {
+			opt: GrowthOptions{Method: GrowthLogistic, Schedule: []GrowthCapacity{schedule[1], schedule[0]}},
+			err: ErrUnsortedCapacity,
+		},
+		"outside capacity": {
+			opt: GrowthOptions{Method: GrowthLogistic, Cap: 10, Floor: 2},
+			y:   []float64{2, 11},
+			err: ErrGrowthDomain,
+		},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			err := td.opt.Validate()
+			if err == nil && td.y != nil {
+				_, err = td.opt.forward(tWin, td.y)
+			}
+			if td.err != nil {
+				assert.ErrorIs(t, err, td.err)
+				return
+			}
+			require.Nil(t, err)
+
+			for i, bounds := range td.expected {
+				ceiling, floor := td.opt.capacity(tWin[i])
+				assert.InDelta(t, bounds[0], ceiling, 1e-9)
+				assert.InDelta(t, bounds[1], floor, 1e-9)
+			}
+			if td.y == nil {
+				return
+			}
+
+			// the inverse recovers the values within the epsilon at the floor and cap
+			res, err := td.opt.forward(tWin, td.y)
+			require.Nil(t, err)
+			td.opt.inverse(tWin, res)
+			for i, v := range td.y {
+				if math.IsNaN(v) {
+					continue
+				}
+				assert.InDelta(t, v, res[i], 0.01*td.expected[i][0])
+			}
+		})
+	}
+}
+
+func TestFitLogisticGrowth(t *testing.T) {
+	// a saturating trend approaching a cap of 100 with daily seasonality
+	n := 14 * 24
+	tWin := timedataset.GenerateT(n, time.Hour, func() time.Time {
+		return time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	})
+	y := make([]float64, n)
+	for i := range y {
+		logit := -3 + 6*float64(i)/float64(n) + 0.3*math.Sin(2*math.Pi*float64(i)/24)
+		y[i] = 100 / (1 + math.Exp(-logit))
+	}
+
+	opt := &Options{
+		SeriesOptions: &SeriesOptions{
+			ForecastOptions: &options.Options{
+				ChangepointOptions: options.ChangepointOptions{
+					Changepoints: []options.Changepoint{
+						options.NewChangepoint("trendstart", tWin[0]),
+					},
+					EnableGrowth: true,
+				},
+				SeasonalityOptions: options.SeasonalityOptions{
+					SeasonalityConfigs: []options.SeasonalityConfig{options.NewDailySeasonalityConfig(2)},
+				},
+				Regularization: []float64{0.0},
+			},
+		},
+		UncertaintyOptions: &UncertaintyOptions{
+			ForecastOptions: &options.Options{},
+			ResidualWindow:  24,
+			ResidualZscore:  2.0,
+		},
+		Growth: &GrowthOptions{Method: GrowthLogistic, Cap: 100},
+	}
+	f, err := New(opt)
+	require.Nil(t, err)
+	require.Nil(t, f.Fit(tWin, y))
+
+	res := f.FitResults()
+	assert.InDeltaSlice(t, y, res.Forecast, 1.0)
+
+	// far beyond the end of training the forecast and bands saturate below the cap
+	horizon := timedataset.GenerateT(24, time.Hour, func() time.Time {
+		return tWin[len(tWin)-1].Add(60 * 24 * time.Hour)
+	})
+	predRes, err := f.Predict(horizon)
+	require.Nil(t, err)
+	for i := range horizon {
+		assert.Greater(t, predRes.Forecast[i], 99.0)
+		assert.LessOrEqual(t, predRes.Upper[i], 100.0)
+		assert.LessOrEqual(t, predRes.Forecast[i], predRes.Upper[i])
+	}
+
+	t.Run("with transform", func(t *testing.T) {
+		opt.Transform = &TransformOptions{Method: TransformLog}
+		defer func() { opt.Transform = nil }()
+		f, err := New(opt)
+		require.Nil(t, err)
+		assert.ErrorIs(t, f.Fit(tWin, y), ErrGrowthWithTransform)
+	})
+}
@@ -0,0 +1,67 @@
+package forecaster
+
+import (
+	"github.com/aouyang1/go-forecaster/forecast"
+	"github.com/aouyang1/go-forecaster/forecast/options"
+	"github.com/aouyang1/go-forecaster/models"
+)
+
+// TrainingStage is the model being fit when a training hook is called
+type TrainingStage string
+
+const (
+	TrainingStageSeries      TrainingStage = "series"
+	TrainingStageUncertainty TrainingStage = "uncertainty"
+	TrainingStageBootstrap   TrainingStage = "bootstrap"
+	TrainingStageQuantile    TrainingStage = "quantile"
+)
+
+// TrainingHooks are optional callbacks reporting the progress of a fit for logging or metrics.
+// OnIterationComplete is called after every coordinate descent iteration and OnLambdaComplete after
+// the fit of every regularization lambda of the lasso regression of each stage. Lambdas fit in
+// parallel call these concurrently. OnOutlierPass is called after every fit of the series model while
+// removing outliers. Hooks are not serialized with the model.
+type TrainingHooks struct {
+	OnIterationComplete func(TrainingStage, models.IterationEvent)
+	OnLambdaComplete    func(TrainingStage, models.LambdaEvent)
+	OnOutlierPass       func(OutlierPassEvent)
+}
+
+// OutlierPassEvent reports a fit of the series model during outlier removal. Pass starts at 1 and
+// RemainingObs is the number of observations the pass was fit on. Outliers is the number of outliers
+// detected in the residual which are removed before the next pass unless removal halted because too
+// little training data would remain.
+type OutlierPassEvent struct {
+	Pass         int
+	RemainingObs int
+	Outliers     int
+	Halted       bool
+	Scores       forecast.Scores
+}
+
+// forStage returns the forecast hooks reporting the lasso progress of the stage or nil if unset
+func (h *TrainingHooks) forStage(stage TrainingStage) *options.TrainingHooks {
+	if h == nil {
+		return nil
+	}
+	res := &options.TrainingHooks{}
+	if h.OnIterationComplete != nil {
+		res.OnIterationComplete = func(e models.IterationEvent) {
+			h.OnIterationComplete(stage, e)
+		}
+	}
+	if h.OnLambdaComplete != nil {
+		res.OnLambdaComplete = func(e models.LambdaEvent) {
+			h.OnLambdaComplete(stage, e)
+		}
+	}
+	return res
+}
+
+// outlierPass reports the outlier pass if the hook is set
+func (h *TrainingHooks) outlierPass(e OutlierPassEvent) {
+	if h == nil || h.OnOutlierPass == nil {
+		return
+	}
+	h.OnOutlierPass(e)
+}
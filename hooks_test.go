@@ -0,0 +1,129 @@
+package forecaster
+
+import (
+	"encoding/json"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/forecast/options"
+	"github.com/aouyang1/go-forecaster/models"
+	"github.com/aouyang1/go-forecaster/timedataset"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrainingHooks(t *testing.T) {
+	n := 7 * 24
+	tWin := timedataset.GenerateT(n, time.Hour, func() time.Time {
+		return time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC)
+	})
+	y := timedataset.GenerateConstY(n, 10.0).
+		Add(timedataset.GenerateWaveY(tWin, 3.0, 86400.0, 1.0, 0.0))
+	rng := rand.New(rand.NewSource(9))
+	for i := range y {
+		y[i] += 0.2 * rng.NormFloat64()
+	}
+	// a few spikes for the outlier passes to remove
+	for _, i := range []int{20, 70, 130} {
+		y[i] += 20
+	}
+
+	var mu sync.Mutex
+	iterations := make(map[TrainingStage]int)
+	lambdas := make(map[TrainingStage][]models.LambdaEvent)
+	var passes []OutlierPassEvent
+
+	opt := NewDefaultOptions()
+	opt.SeriesOptions.ForecastOptions.Regularization = []float64{0.0, 1.0}
+	opt.SeriesOptions.ForecastOptions.SeasonalityOptions.SeasonalityConfigs = []options.SeasonalityConfig{
+		options.NewDailySeasonalityConfig(2),
+	}
+	opt.UncertaintyOptions.Method = UncertaintyMethodBootstrap
+	opt.UncertaintyOptions.Bootstraps = 2
+	opt.UncertaintyOptions.ResidualWindow = 24
+	opt.TrainingHooks = &TrainingHooks{
+		OnIterationComplete: func(stage TrainingStage, e models.IterationEvent) {
+			mu.Lock()
+			defer mu.Unlock()
+			iterations[stage]++
+		},
+		OnLambdaComplete: func(stage TrainingStage, e models.LambdaEvent) {
+			mu.Lock()
+			defer mu.Unlock()
+			lambdas[stage] = append(lambdas[stage], e)
+		},
+		OnOutlierPass: func(e OutlierPassEvent) {
+			passes = append(passes, e)
+		},
+	}
+
+	f, err := New(opt)
+	require.Nil(t, err)
+	require.Nil(t, f.Fit(tWin, y))
+
+	// every series fit sweeps both lambdas
+	d := f.FitDiagnostics()
+	require.Len(t, passes, d.OutlierPasses+1)
+	assert.Len(t, lambdas[TrainingStageSeries], 2*len(passes))
+	for i, pass := range passes {
+		assert.Equal(t, i+1, pass.Pass)
+		assert.Greater(t, pass.Scores.R2, 0.0)
+		if i < len(passes)-1 {
+			assert.Greater(t, pass.Outliers, 0)
+			assert.Equal(t, pass.RemainingObs-pass.Outliers, passes[i+1].RemainingObs)
+		}
+	}
+	assert.Equal(t, n, passes[0].RemainingObs)
+	assert.GreaterOrEqual(t, d.OutliersRemoved, 3)
+
+	assert.NotEmpty(t, lambdas[TrainingStageUncertainty])
+	assert.Len(t, lambdas[TrainingStageBootstrap], 2*opt.UncertaintyOptions.Bootstraps)
+	for _, stage := range []TrainingStage{TrainingStageSeries, TrainingStageUncertainty, TrainingStageBootstrap} {
+		var total int
+		for _, e := range lambdas[stage] {
+			assert.Greater(t, e.Iterations, 0)
+			total += e.Iterations
+		}
+		assert.Equal(t, total, iterations[stage], stage)
+	}
+
+	// hooks are not part of the serialized model
+	model, err := f.Model()
+	require.Nil(t, err)
+	out, err := json.Marshal(model)
+	require.Nil(t, err)
+	var decoded Model
+	require.Nil(t, json.Unmarshal(out, &decoded))
+	assert.Nil(t, decoded.Options.TrainingHooks)
+	assert.Nil(t, decoded.Series.Options.TrainingHooks)
+	loaded, err := NewFromModel(decoded)
+	require.Nil(t, err)
+	_, err = loaded.Predict(tWin)
+	require.Nil(t, err)
+}
+
+func TestOptionsCopy(t *testing.T) {
+	var called bool
+	opt := NewDefaultOptions()
+	opt.TrainingHooks = &TrainingHooks{
+		OnOutlierPass: func(OutlierPassEvent) { called = true },
+	}
+	opt.SeriesOptions.ForecastOptions.TrainingHooks = &options.TrainingHooks{}
+
+	res := opt.Copy()
+	require.NotNil(t, res.TrainingHooks)
+	res.TrainingHooks.OnOutlierPass(OutlierPassEvent{})
+	assert.True(t, called)
+	assert.NotNil(t, res.SeriesOptions.ForecastOptions.TrainingHooks)
+
+	// the copy shares nothing mutable with the options
+	res.SeriesOptions.ForecastOptions.SeasonalityOptions.SeasonalityConfigs[0].Orders = 100
+	res.SeriesOptions.ForecastOptions.Regularization[0] = 100
+	assert.NotEqual(t, 100, opt.SeriesOptions.ForecastOptions.SeasonalityOptions.SeasonalityConfigs[0].Orders)
+	assert.NotEqual(t, 100.0, opt.SeriesOptions.ForecastOptions.Regularization[0])
+
+	var nilOpt *Options
+	assert.Nil(t, nilOpt.Copy())
+}
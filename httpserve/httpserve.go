@@ -0,0 +1,238 @@
+// Package httpserve provides http handlers to fit forecasters and predict from serialized models so
+// that the package can be served from a microservice without glue code. Request bodies are limited in
+// size and decoded whole into memory. Predictions are computed in full and then written back in chunks,
+// flushing after each, so clients can start reading a large response before it is complete.
+package httpserve
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	forecaster "github.com/aouyang1/go-forecaster"
+	"github.com/aouyang1/go-forecaster/errs"
+)
+
+const (
+	// DefaultMaxBodyBytes is the largest request body accepted by default
+	DefaultMaxBodyBytes = 64 << 20
+
+	// DefaultChunkSize is the number of points written between flushes of a streamed prediction
+	DefaultChunkSize = 4096
+
+	// ContentTypeBinary requests a fit model in the binary encoding of forecaster.Model.MarshalBinary
+	ContentTypeBinary = "application/octet-stream"
+)
+
+var (
+	ErrInvalidServeOptions = errs.NewConfigError(errs.CodeInvalidOption, "max body bytes and chunk size must be non-negative", nil)
+	ErrInvalidRequest      = errs.NewDataError(errs.CodeInvalidValue, "invalid request body", nil)
+)
+
+// Options configures the handlers. Forecaster are the options of every fit request that does not
+// provide its own and default to the forecaster defaults. MaxBodyBytes limits the size of a request
+// body and ChunkSize is the number of points of a prediction written before the response is flushed.
+// Zero values use DefaultMaxBodyBytes and DefaultChunkSize.
+type Options struct {
+	Forecaster   *forecaster.Options
+	MaxBodyBytes int64
+	ChunkSize    int
+}
+
+// FitRequest is the body of a fit request. Null values are decoded as NaN so they are treated as
+// missing by the fit.
+type FitRequest struct {
+	Options *forecaster.Options  `json:"options,omitempty"`
+	T       []time.Time          `json:"time"`
+	Y       forecaster.NaNFloats `json:"values"`
+}
+
+// PredictRequest is the body of a predict request
+type PredictRequest struct {
+	Model forecaster.Model `json:"model"`
+	T     []time.Time      `json:"time"`
+}
+
+// PredictResponse is the body of a predict response. NaN values are written as null.
+type PredictResponse struct {
+	T        []time.Time          `json:"time"`
+	Forecast forecaster.NaNFloats `json:"forecast"`
+	Upper    forecaster.NaNFloats `json:"upper"`
+	Lower    forecaster.NaNFloats `json:"lower"`
+}
+
+// ErrorResponse is the body of every failed request with the code and kind of the error from the errs
+// taxonomy
+type ErrorResponse struct {
+	Error string    `json:"error"`
+	Code  errs.Code `json:"code"`
+	Kind  errs.Kind `json:"kind"`
+}
+
+// Handler serves fit and predict requests
+type Handler struct {
+	opt Options
+}
+
+// New initializes the handlers with the options
+func New(opt Options) (*Handler, error) {
+	if opt.MaxBodyBytes < 0 || opt.ChunkSize < 0 {
+		return nil, fmt.Errorf("max body bytes %d and chunk size %d, %w", opt.MaxBodyBytes, opt.ChunkSize, ErrInvalidServeOptions)
+	}
+	if opt.MaxBodyBytes == 0 {
+		opt.MaxBodyBytes = DefaultMaxBodyBytes
+	}
+	if opt.ChunkSize == 0 {
+		opt.ChunkSize = DefaultChunkSize
+	}
+	if opt.Forecaster == nil {
+		opt.Forecaster = forecaster.NewDefaultOptions()
+	}
+	return &Handler{opt: opt}, nil
+}
+
+// ServeMux returns a mux routing POST /fit and POST /predict to the handlers
+func (h *Handler) ServeMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.Handle("/fit", http.HandlerFunc(h.Fit))
+	mux.Handle("/predict", http.HandlerFunc(h.Predict))
+	return mux
+}
+
+// Fit fits a forecaster to the time and values of a FitRequest and responds with the json model or the
+// binary model if the request accepts ContentTypeBinary. The fit stops if the request is canceled.
+func (h *Handler) Fit(w http.ResponseWriter, r *http.Request) {
+	if !h.allowPost(w, r) {
+		return
+	}
+	var req FitRequest
+	if err := h.decode(w, r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	opt := req.Options
+	if opt == nil {
+		// every fit gets its own copy of the default options since fitting stores results in them
+		opt = h.opt.Forecaster.Copy()
+	}
+	f, err := forecaster.New(opt)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if err := f.FitCtx(r.Context(), req.T, req.Y); err != nil {
+		writeError(w, err)
+		return
+	}
+	model, err := f.Model()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if r.Header.Get("Accept") == ContentTypeBinary {
+		out, err := model.MarshalBinary()
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", ContentTypeBinary)
+		if _, err := w.Write(out); err != nil {
+			slog.Warn("unable to write binary model response", "error", err.Error())
+		}
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(model); err != nil {
+		slog.Warn("unable to write model response", "error", err.Error())
+	}
+}
+
+// Predict loads the model of a PredictRequest and responds with the PredictResponse of the requested
+// times. The response is streamed in chunks of points flushing after each so clients can start
+// reading large predictions before they are fully written.
+func (h *Handler) Predict(w http.ResponseWriter, r *http.Request) {
+	if !h.allowPost(w, r) {
+		return
+	}
+	var req PredictRequest
+	if err := h.decode(w, r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	f, err := forecaster.NewFromModel(req.Model)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	res, err := f.PredictCtx(r.Context(), req.T)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	// the status is sent with the first chunk so a failed write can only end the response early
+	w.Header().Set("Content-Type", "application/json")
+	if err := writeResults(newChunkWriter(w, h.opt.ChunkSize), res); err != nil {
+		slog.Warn("unable to write prediction response", "error", err.Error())
+	}
+}
+
+func (h *Handler) allowPost(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method == http.MethodPost {
+		return true
+	}
+	w.Header().Set("Allow", http.MethodPost)
+	writeErrorStatus(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+	return false
+}
+
+// decode reads the json request body as it arrives up to the maximum body size
+func (h *Handler) decode(w http.ResponseWriter, r *http.Request, v any) error {
+	body := http.MaxBytesReader(w, r.Body, h.opt.MaxBodyBytes)
+	if err := json.NewDecoder(body).Decode(v); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return err
+		}
+		return fmt.Errorf("%s, %w", err.Error(), ErrInvalidRequest)
+	}
+	return nil
+}
+
+// writeError responds with the status of the error kind. Configuration and data errors are the fault
+// of the request while fit and predict errors mean the request could not be served as given.
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		status = http.StatusRequestEntityTooLarge
+	default:
+		switch errs.KindOf(err) {
+		case errs.KindConfig, errs.KindData:
+			status = http.StatusBadRequest
+		case errs.KindFit, errs.KindPredict:
+			status = http.StatusUnprocessableEntity
+		}
+	}
+	writeErrorStatus(w, status, err)
+}
+
+func writeErrorStatus(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	encErr := json.NewEncoder(w).Encode(ErrorResponse{
+		Error: err.Error(),
+		Code:  errs.CodeOf(err),
+		Kind:  errs.KindOf(err),
+	})
+	if encErr != nil {
+		slog.Warn("unable to write error response", "error", encErr.Error(), "status", status)
+	}
+}
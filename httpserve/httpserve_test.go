@@ -0,0 +1,200 @@
+package httpserve
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	forecaster "github.com/aouyang1/go-forecaster"
+	"github.com/aouyang1/go-forecaster/errs"
+	"github.com/aouyang1/go-forecaster/forecast/options"
+	"github.com/aouyang1/go-forecaster/timedataset"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testOptions() *forecaster.Options {
+	opt := forecaster.NewDefaultOptions()
+	opt.SeriesOptions.ForecastOptions.SeasonalityOptions.SeasonalityConfigs = []options.SeasonalityConfig{
+		options.NewDailySeasonalityConfig(2),
+	}
+	opt.UncertaintyOptions.ForecastOptions.SeasonalityOptions.SeasonalityConfigs = []options.SeasonalityConfig{
+		options.NewDailySeasonalityConfig(2),
+	}
+	opt.UncertaintyOptions.ResidualWindow = 24
+	return opt
+}
+
+func testSeries() ([]time.Time, []float64) {
+	n := 7 * 24
+	tWin := timedataset.GenerateT(n, time.Hour, func() time.Time {
+		return time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC)
+	})
+	rng := rand.New(rand.NewSource(3))
+	y := make([]float64, n)
+	for i := range y {
+		y[i] = 10 + 3*math.Sin(2*math.Pi*float64(i)/24) + 0.1*rng.NormFloat64()
+	}
+	return tWin, y
+}
+
+func post(t *testing.T, h http.Handler, path string, body any, header http.Header) *httptest.ResponseRecorder {
+	var buf bytes.Buffer
+	switch b := body.(type) {
+	case string:
+		buf.WriteString(b)
+	default:
+		require.Nil(t, json.NewEncoder(&buf).Encode(body))
+	}
+	req := httptest.NewRequest(http.MethodPost, path, &buf)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestFitPredict(t *testing.T) {
+	tWin, y := testSeries()
+	h, err := New(Options{Forecaster: testOptions(), ChunkSize: 10})
+	require.Nil(t, err)
+	mux := h.ServeMux()
+
+	rec := post(t, mux, "/fit", FitRequest{T: tWin, Y: y}, nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var model forecaster.Model
+	require.Nil(t, json.NewDecoder(rec.Body).Decode(&model))
+
+	// the binary model is the same model
+	rec = post(t, mux, "/fit", FitRequest{T: tWin, Y: y}, http.Header{"Accept": {ContentTypeBinary}})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, ContentTypeBinary, rec.Header().Get("Content-Type"))
+	var binModel forecaster.Model
+	require.Nil(t, binModel.UnmarshalBinary(rec.Body.Bytes()))
+	assert.Equal(t, model.Series.Weights, binModel.Series.Weights)
+
+	horizon := timedataset.GenerateT(48, time.Hour, func() time.Time {
+		return tWin[len(tWin)-1].Add(time.Hour)
+	})
+	rec = post(t, mux, "/predict", PredictRequest{Model: model, T: horizon}, nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var res PredictResponse
+	require.Nil(t, json.NewDecoder(rec.Body).Decode(&res))
+	require.Len(t, res.T, len(horizon))
+	for i := range horizon {
+		assert.True(t, horizon[i].Equal(res.T[i]))
+	}
+
+	f, err := forecaster.NewFromModel(model)
+	require.Nil(t, err)
+	expected, err := f.Predict(horizon)
+	require.Nil(t, err)
+	assert.InDeltaSlice(t, expected.Forecast, res.Forecast, 1e-9)
+	assert.InDeltaSlice(t, expected.Upper, res.Upper, 1e-9)
+	assert.InDeltaSlice(t, expected.Lower, res.Lower, 1e-9)
+}
+
+func TestFitNullValues(t *testing.T) {
+	tWin, y := testSeries()
+	y[5] = math.NaN()
+	h, err := New(Options{Forecaster: testOptions()})
+	require.Nil(t, err)
+
+	// the missing value is sent as null
+	body, err := json.Marshal(FitRequest{T: tWin, Y: y})
+	require.Nil(t, err)
+	require.Contains(t, string(body), ",null,")
+
+	rec := post(t, h.ServeMux(), "/fit", string(body), nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var model forecaster.Model
+	require.Nil(t, json.NewDecoder(rec.Body).Decode(&model))
+
+	// the fit matches a fit with the value missing instead of zero
+	f, err := forecaster.New(testOptions())
+	require.Nil(t, err)
+	require.Nil(t, f.Fit(tWin, y))
+	expected, err := f.Model()
+	require.Nil(t, err)
+	assert.Equal(t, expected.Series.Weights, model.Series.Weights)
+}
+
+func TestHandlerErrors(t *testing.T) {
+	tWin, y := testSeries()
+	h, err := New(Options{Forecaster: testOptions(), MaxBodyBytes: 1 << 20})
+	require.Nil(t, err)
+	mux := h.ServeMux()
+
+	testData := map[string]struct {
+		path   string
+		body   any
+		status int
+		kind   errs.Kind
+	}{
+		"malformed": {
+			path:   "/fit",
+			body:   "{",
+			status: http.StatusBadRequest,
+			kind:   errs.KindData,
+		},
+		"mismatched values": {
+			path:   "/fit",
+			body:   FitRequest{T: tWin, Y: y[:10]},
+			status: http.StatusBadRequest,
+			kind:   errs.KindData,
+		},
+		"too large": {
+			path:   "/fit",
+			body:   FitRequest{T: make([]time.Time, 1<<16)},
+			status: http.StatusRequestEntityTooLarge,
+			kind:   errs.KindUnknown,
+		},
+		"no model": {
+			path:   "/predict",
+			body:   PredictRequest{T: tWin},
+			status: http.StatusBadRequest,
+			kind:   errs.KindConfig,
+		},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			rec := post(t, mux, td.path, td.body, nil)
+			assert.Equal(t, td.status, rec.Code, rec.Body.String())
+			var res ErrorResponse
+			require.Nil(t, json.NewDecoder(rec.Body).Decode(&res))
+			assert.NotEmpty(t, res.Error)
+			assert.Equal(t, td.kind, res.Kind)
+		})
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/predict", nil)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	assert.Equal(t, http.MethodPost, rec.Header().Get("Allow"))
+
+	_, err = New(Options{ChunkSize: -1})
+	assert.ErrorIs(t, err, ErrInvalidServeOptions)
+}
+
+func TestWriteResults(t *testing.T) {
+	res := &forecaster.Results{
+		T:        []time.Time{time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 1, 1, 1, 0, 0, 0, time.UTC)},
+		Forecast: []float64{1.5, math.NaN()},
+		Upper:    []float64{2, math.Inf(1)},
+		Lower:    []float64{1, 0.25},
+	}
+	var buf bytes.Buffer
+	require.Nil(t, writeResults(newChunkWriter(&buf, 1), res))
+	assert.Equal(t,
+		`{"time":["2024-01-01T00:00:00Z","2024-01-01T01:00:00Z"],"forecast":[1.5,null],"upper":[2,null],"lower":[1,0.25]}`+"\n",
+		buf.String(),
+	)
+}
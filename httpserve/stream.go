@@ -0,0 +1,116 @@
+package httpserve
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	forecaster "github.com/aouyang1/go-forecaster"
+)
+
+// chunkWriter buffers a streamed response flushing it to the client after every chunk of points
+type chunkWriter struct {
+	buf     *bufio.Writer
+	flusher http.Flusher
+	chunk   int
+	points  int
+	scratch []byte
+}
+
+func newChunkWriter(w io.Writer, chunk int) *chunkWriter {
+	cw := &chunkWriter{
+		buf:   bufio.NewWriter(w),
+		chunk: chunk,
+	}
+	if flusher, ok := w.(http.Flusher); ok {
+		cw.flusher = flusher
+	}
+	return cw
+}
+
+// point records a written point flushing the response at the end of every chunk
+func (c *chunkWriter) point() error {
+	c.points++
+	if c.points%c.chunk != 0 {
+		return nil
+	}
+	return c.flush()
+}
+
+func (c *chunkWriter) flush() error {
+	if err := c.buf.Flush(); err != nil {
+		return err
+	}
+	if c.flusher != nil {
+		c.flusher.Flush()
+	}
+	return nil
+}
+
+// writeResults streams the results as a PredictResponse. Values that are not finite cannot be
+// represented in json and are written as null.
+func writeResults(c *chunkWriter, res *forecaster.Results) error {
+	if _, err := c.buf.WriteString(`{"time":[`); err != nil {
+		return err
+	}
+	for i, t := range res.T {
+		c.scratch = c.scratch[:0]
+		if i > 0 {
+			c.scratch = append(c.scratch, ',')
+		}
+		c.scratch = append(c.scratch, '"')
+		c.scratch = t.AppendFormat(c.scratch, time.RFC3339Nano)
+		c.scratch = append(c.scratch, '"')
+		if _, err := c.buf.Write(c.scratch); err != nil {
+			return err
+		}
+		if err := c.point(); err != nil {
+			return err
+		}
+	}
+
+	for _, field := range []struct {
+		name string
+		vals []float64
+	}{
+		{"forecast", res.Forecast},
+		{"upper", res.Upper},
+		{"lower", res.Lower},
+	} {
+		if _, err := c.buf.WriteString(`],"` + field.name + `":[`); err != nil {
+			return err
+		}
+		if err := writeFloats(c, field.vals); err != nil {
+			return err
+		}
+	}
+
+	if _, err := c.buf.WriteString("]}\n"); err != nil {
+		return err
+	}
+	return c.flush()
+}
+
+func writeFloats(c *chunkWriter, vals []float64) error {
+	for i, v := range vals {
+		c.scratch = c.scratch[:0]
+		if i > 0 {
+			c.scratch = append(c.scratch, ',')
+		}
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			c.scratch = append(c.scratch, "null"...)
+		} else {
+			c.scratch = strconv.AppendFloat(c.scratch, v, 'g', -1, 64)
+		}
+		if _, err := c.buf.Write(c.scratch); err != nil {
+			return err
+		}
+		if err := c.point(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
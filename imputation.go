@@ -0,0 +1,142 @@
+package forecaster
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/errs"
+)
+
+var (
+	ErrUnknownImputation   = errs.NewConfigError(errs.CodeInvalidOption, "unknown imputation method", nil)
+	ErrInvalidImputeMaxGap = errs.NewConfigError(errs.CodeInvalidOption, "imputation max gap must be non-negative", nil)
+	ErrInvalidImputePeriod = errs.NewConfigError(errs.CodeInvalidOption, "imputation seasonal period must be non-negative", nil)
+)
+
+// DefaultImputationPeriod is the seasonal period of seasonal naive imputation if none is configured
+const DefaultImputationPeriod = 24 * time.Hour
+
+// ImputationMethod is how missing training values are filled before fitting
+type ImputationMethod string
+
+const (
+	// ImputationNone leaves missing values out of the fit
+	ImputationNone ImputationMethod = ""
+
+	// ImputationLinear interpolates linearly in time between the observations bounding a gap
+	ImputationLinear ImputationMethod = "linear"
+
+	// ImputationSeasonalNaive fills a missing value with the value one seasonal period earlier
+	ImputationSeasonalNaive ImputationMethod = "seasonal_naive"
+
+	// ImputationForwardFill fills a missing value with the last observation before it
+	ImputationForwardFill ImputationMethod = "forward_fill"
+)
+
+// ImputationOptions fills short gaps of missing training values before fitting so they are not lost
+// from the fit. A gap spans from the observation before it to the observation after it, or to its last
+// point if the series ends missing, and gaps longer than MaxGap are left missing. A zero MaxGap fills
+// every gap. Seasonal naive imputation looks up the value at the same time one SeasonalPeriod earlier,
+// including earlier imputed values, and leaves the point missing if there is none. Gaps at the start of
+// the series are never filled.
+type ImputationOptions struct {
+	Method         ImputationMethod `json:"method"`
+	MaxGap         time.Duration    `json:"max_gap,omitempty"`
+	SeasonalPeriod time.Duration    `json:"seasonal_period,omitempty"`
+}
+
+// enabled returns true if missing values are imputed
+func (o *ImputationOptions) enabled() bool {
+	return o != nil && o.Method != ImputationNone
+}
+
+// Validate returns an error if the method is unknown or the gap or period is negative
+func (o *ImputationOptions) Validate() error {
+	switch o.Method {
+	case ImputationNone, ImputationLinear, ImputationSeasonalNaive, ImputationForwardFill:
+	default:
+		return fmt.Errorf("%q, %w", o.Method, ErrUnknownImputation)
+	}
+	if o.MaxGap < 0 {
+		return fmt.Errorf("max gap of %s, %w", o.MaxGap, ErrInvalidImputeMaxGap)
+	}
+	if o.SeasonalPeriod < 0 {
+		return fmt.Errorf("seasonal period of %s, %w", o.SeasonalPeriod, ErrInvalidImputePeriod)
+	}
+	return nil
+}
+
+// impute fills the missing values of y in place returning the imputed indexes in ascending order
+func (o *ImputationOptions) impute(t []time.Time, y []float64) ([]int, error) {
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
+	if !o.enabled() {
+		return nil, nil
+	}
+
+	var lookup map[int64]float64
+	period := o.SeasonalPeriod
+	if o.Method == ImputationSeasonalNaive {
+		if period == 0 {
+			period = DefaultImputationPeriod
+		}
+		lookup = make(map[int64]float64, len(t))
+		for i, tPnt := range t {
+			if !math.IsNaN(y[i]) {
+				lookup[tPnt.UnixNano()] = y[i]
+			}
+		}
+	}
+
+	var imputed []int
+	prev := -1
+	for i := 0; i < len(y); i++ {
+		if !math.IsNaN(y[i]) {
+			prev = i
+			continue
+		}
+		end := i
+		for end < len(y) && math.IsNaN(y[end]) {
+			end++
+		}
+		// leading gaps have no observation to fill from
+		if prev == -1 {
+			i = end - 1
+			continue
+		}
+
+		gapEnd := t[end-1]
+		if end < len(y) {
+			gapEnd = t[end]
+		}
+		if o.MaxGap > 0 && gapEnd.Sub(t[prev]) > o.MaxGap {
+			i = end - 1
+			continue
+		}
+
+		for j := i; j < end; j++ {
+			switch o.Method {
+			case ImputationLinear:
+				if end == len(y) {
+					continue
+				}
+				frac := float64(t[j].Sub(t[prev])) / float64(t[end].Sub(t[prev]))
+				y[j] = y[prev] + frac*(y[end]-y[prev])
+			case ImputationForwardFill:
+				y[j] = y[prev]
+			case ImputationSeasonalNaive:
+				val, exists := lookup[t[j].Add(-period).UnixNano()]
+				if !exists {
+					continue
+				}
+				y[j] = val
+				lookup[t[j].UnixNano()] = val
+			}
+			imputed = append(imputed, j)
+		}
+		i = end - 1
+	}
+	return imputed, nil
+}
@@ -0,0 +1,138 @@
+package forecaster
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/forecast/options"
+	"github.com/aouyang1/go-forecaster/timedataset"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImpute(t *testing.T) {
+	nan := math.NaN()
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tWin := make([]time.Time, 8)
+	for i := range tWin {
+		tWin[i] = t0.Add(time.Duration(i) * time.Minute)
+	}
+
+	testData := map[string]struct {
+		opt      ImputationOptions
+		y        []float64
+		expected []float64
+		imputed  []int
+		err      error
+	}{
+		"linear": {
+			opt:      ImputationOptions{Method: ImputationLinear},
+			y:        []float64{nan, 1, nan, nan, 4, 5, nan, nan},
+			expected: []float64{nan, 1, 2, 3, 4, 5, nan, nan},
+			imputed:  []int{2, 3},
+		},
+		"forward fill": {
+			opt:      ImputationOptions{Method: ImputationForwardFill},
+			y:        []float64{nan, 1, nan, nan, 4, 5, nan, nan},
+			expected: []float64{nan, 1, 1, 1, 4, 5, 5, 5},
+			imputed:  []int{2, 3, 6, 7},
+		},
+		"max gap": {
+			opt:      ImputationOptions{Method: ImputationForwardFill, MaxGap: 2 * time.Minute},
+			y:        []float64{1, nan, 3, nan, nan, 6, nan, 8},
+			expected: []float64{1, 1, 3, nan, nan, 6, 6, 8},
+			imputed:  []int{1, 6},
+		},
+		"seasonal naive": {
+			opt:      ImputationOptions{Method: ImputationSeasonalNaive, SeasonalPeriod: 3 * time.Minute},
+			y:        []float64{1, nan, 3, nan, 5, nan, nan, nan},
+			expected: []float64{1, nan, 3, 1, 5, 3, 1, 5},
+			imputed:  []int{3, 5, 6, 7},
+		},
+		"none": {
+			y:        []float64{1, nan, 3, nan, 5, nan, 7, 8},
+			expected: []float64{1, nan, 3, nan, 5, nan, 7, 8},
+		},
+		"unknown method": {
+			opt: ImputationOptions{Method: "spline"},
+			y:   []float64{1, nan, 3, nan, 5, nan, 7, 8},
+			err: ErrUnknownImputation,
+		},
+		"negative max gap": {
+			opt: ImputationOptions{Method: ImputationLinear, MaxGap: -time.Minute},
+			y:   []float64{1, nan, 3, nan, 5, nan, 7, 8},
+			err: ErrInvalidImputeMaxGap,
+		},
+		"negative period": {
+			opt: ImputationOptions{Method: ImputationSeasonalNaive, SeasonalPeriod: -time.Minute},
+			y:   []float64{1, nan, 3, nan, 5, nan, 7, 8},
+			err: ErrInvalidImputePeriod,
+		},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			imputed, err := td.opt.impute(tWin, td.y)
+			if td.err != nil {
+				assert.ErrorIs(t, err, td.err)
+				return
+			}
+			require.Nil(t, err)
+			assert.Equal(t, td.imputed, imputed)
+			for i := range td.expected {
+				if math.IsNaN(td.expected[i]) {
+					assert.True(t, math.IsNaN(td.y[i]), "index %d, got %.3f", i, td.y[i])
+					continue
+				}
+				assert.InDelta(t, td.expected[i], td.y[i], 1e-9, "index %d", i)
+			}
+		})
+	}
+}
+
+func TestFitImputation(t *testing.T) {
+	n := 2 * 24 * 4
+	tWin := timedataset.GenerateT(n, 15*time.Minute, func() time.Time {
+		return time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)
+	})
+	y := make([]float64, n)
+	for i := range y {
+		y[i] = 10 + 0.1*float64(i)
+	}
+	gap := []int{50, 51, 52}
+	for _, i := range gap {
+		y[i] = math.NaN()
+	}
+	y[n-1] = math.NaN()
+
+	opt := &Options{
+		SeriesOptions: &SeriesOptions{
+			ForecastOptions: &options.Options{
+				ChangepointOptions: options.ChangepointOptions{
+					Changepoints: []options.Changepoint{options.NewChangepoint("trendstart", tWin[0])},
+					EnableGrowth: true,
+				},
+				Regularization: []float64{0.0},
+			},
+			ImputationOptions: &ImputationOptions{Method: ImputationLinear, MaxGap: time.Hour},
+		},
+		UncertaintyOptions: &UncertaintyOptions{
+			ForecastOptions: &options.Options{},
+			ResidualWindow:  10,
+			ResidualZscore:  1.0,
+		},
+	}
+	f, err := New(opt)
+	require.Nil(t, err)
+	require.Nil(t, f.Fit(tWin, y))
+
+	diag := f.FitDiagnostics()
+	assert.Equal(t, gap, diag.ImputedIndexes)
+	assert.Equal(t, []int{n - 1}, diag.MissingIndexes)
+	residual := f.Residuals()
+	for _, i := range gap {
+		assert.False(t, math.IsNaN(residual[i]), "index %d", i)
+	}
+	assert.True(t, math.IsNaN(residual[n-1]))
+}
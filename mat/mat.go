@@ -1,13 +1,13 @@
 package mat
 
 import (
-	"errors"
 	"fmt"
 
+	"github.com/aouyang1/go-forecaster/errs"
 	"gonum.org/v1/gonum/mat"
 )
 
-var ErrColMismatch = errors.New("column size mismatch")
+var ErrColMismatch = errs.NewDataError(errs.CodeDimensionMismatch, "column size mismatch", nil)
 
 func NewDenseFromArray(x [][]float64) (*mat.Dense, error) {
 	m := len(x)
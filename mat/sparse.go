@@ -0,0 +1,282 @@
+package mat
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/aouyang1/go-forecaster/errs"
+	"gonum.org/v1/gonum/mat"
+)
+
+var (
+	ErrRowMismatch      = errs.NewDataError(errs.CodeDimensionMismatch, "row size mismatch", nil)
+	ErrInvalidSparse    = errs.NewDataError(errs.CodeInvalidValue, "invalid compressed sparse row structure", nil)
+	ErrScaleLenMismatch = errs.NewDataError(errs.CodeLengthMismatch, "row scale does not have a value for every row", nil)
+)
+
+// SparseVector is a vector of length Len storing only the non zero values at increasing indices
+type SparseVector struct {
+	Len     int
+	Indices []int
+	Data    []float64
+}
+
+// Dot returns the dot product with a dense vector of the same length
+func (v SparseVector) Dot(x []float64) float64 {
+	var res float64
+	for k, i := range v.Indices {
+		res += v.Data[k] * x[i]
+	}
+	return res
+}
+
+// DotSparse returns the dot product with another sparse vector of the same length
+func (v SparseVector) DotSparse(u SparseVector) float64 {
+	var res float64
+	for a, b := 0, 0; a < len(v.Indices) && b < len(u.Indices); {
+		switch {
+		case v.Indices[a] < u.Indices[b]:
+			a++
+		case v.Indices[a] > u.Indices[b]:
+			b++
+		default:
+			res += v.Data[a] * u.Data[b]
+			a++
+			b++
+		}
+	}
+	return res
+}
+
+// AddScaledTo adds alpha times the vector to the dense destination of the same length
+func (v SparseVector) AddScaledTo(dst []float64, alpha float64) {
+	for k, i := range v.Indices {
+		dst[i] += alpha * v.Data[k]
+	}
+}
+
+// CSR is a compressed sparse row matrix storing only the non zero values of each row. The values of
+// row i are data[indptr[i]:indptr[i+1]] at the columns indices[indptr[i]:indptr[i+1]] in increasing
+// order. CSR implements the gonum Matrix interface so it can be used wherever a dense matrix is
+// accepted although element access is a binary search over the row.
+type CSR struct {
+	rows, cols int
+	indptr     []int
+	indices    []int
+	data       []float64
+}
+
+// NewCSR initializes a compressed sparse row matrix validating the structure of the row pointers and
+// column indices
+func NewCSR(rows, cols int, indptr, indices []int, data []float64) (*CSR, error) {
+	if len(indptr) != rows+1 || indptr[0] != 0 || indptr[rows] != len(indices) || len(indices) != len(data) {
+		return nil, fmt.Errorf("%d rows with %d row pointers and %d indices, %w", rows, len(indptr), len(indices), ErrInvalidSparse)
+	}
+	for i := 0; i < rows; i++ {
+		if indptr[i+1] < indptr[i] {
+			return nil, fmt.Errorf("row %d has decreasing row pointers, %w", i, ErrInvalidSparse)
+		}
+		for k := indptr[i]; k < indptr[i+1]; k++ {
+			if indices[k] < 0 || indices[k] >= cols || (k > indptr[i] && indices[k] <= indices[k-1]) {
+				return nil, fmt.Errorf("row %d has unordered or out of range columns, %w", i, ErrInvalidSparse)
+			}
+		}
+	}
+	return &CSR{
+		rows:    rows,
+		cols:    cols,
+		indptr:  indptr,
+		indices: indices,
+		data:    data,
+	}, nil
+}
+
+// NewCSRFromColumns builds a compressed sparse row matrix from dense columns of the same length
+// skipping zeros
+func NewCSRFromColumns(rows int, cols [][]float64) (*CSR, error) {
+	for j, col := range cols {
+		if len(col) != rows {
+			return nil, fmt.Errorf("column %d has %d rows instead of %d, %w", j, len(col), rows, ErrRowMismatch)
+		}
+	}
+
+	indptr := make([]int, rows+1)
+	for _, col := range cols {
+		for i, v := range col {
+			if v != 0 {
+				indptr[i+1]++
+			}
+		}
+	}
+	for i := 0; i < rows; i++ {
+		indptr[i+1] += indptr[i]
+	}
+
+	indices := make([]int, indptr[rows])
+	data := make([]float64, indptr[rows])
+	next := make([]int, rows)
+	copy(next, indptr[:rows])
+	for j, col := range cols {
+		for i, v := range col {
+			if v == 0 {
+				continue
+			}
+			indices[next[i]] = j
+			data[next[i]] = v
+			next[i]++
+		}
+	}
+	return &CSR{
+		rows:    rows,
+		cols:    len(cols),
+		indptr:  indptr,
+		indices: indices,
+		data:    data,
+	}, nil
+}
+
+// Dims returns the number of rows and columns of the matrix
+func (c *CSR) Dims() (int, int) {
+	return c.rows, c.cols
+}
+
+// At returns the value of the element at row i and column j
+func (c *CSR) At(i, j int) float64 {
+	if i < 0 || i >= c.rows {
+		panic(mat.ErrRowAccess)
+	}
+	if j < 0 || j >= c.cols {
+		panic(mat.ErrColAccess)
+	}
+	row := c.indices[c.indptr[i]:c.indptr[i+1]]
+	k := sort.SearchInts(row, j)
+	if k < len(row) && row[k] == j {
+		return c.data[c.indptr[i]+k]
+	}
+	return 0
+}
+
+// T returns the transpose of the matrix
+func (c *CSR) T() mat.Matrix {
+	return mat.Transpose{Matrix: c}
+}
+
+// NNZ returns the number of stored non zero values
+func (c *CSR) NNZ() int {
+	return len(c.data)
+}
+
+// Density returns the fraction of non zero values of the matrix
+func (c *CSR) Density() float64 {
+	if c.rows == 0 || c.cols == 0 {
+		return 0
+	}
+	return float64(len(c.data)) / float64(c.rows*c.cols)
+}
+
+// MulVec returns the product of the matrix with a dense vector with a value per column
+func (c *CSR) MulVec(x []float64) ([]float64, error) {
+	if len(x) != c.cols {
+		return nil, fmt.Errorf("vector has %d values for %d columns, %w", len(x), c.cols, ErrColMismatch)
+	}
+	res := make([]float64, c.rows)
+	for i := 0; i < c.rows; i++ {
+		var v float64
+		for k := c.indptr[i]; k < c.indptr[i+1]; k++ {
+			v += c.data[k] * x[c.indices[k]]
+		}
+		res[i] = v
+	}
+	return res, nil
+}
+
+// ScaleRows returns a copy of the matrix with every row multiplied by its scale
+func (c *CSR) ScaleRows(scale []float64) (*CSR, error) {
+	if len(scale) != c.rows {
+		return nil, fmt.Errorf("%d scales for %d rows, %w", len(scale), c.rows, ErrScaleLenMismatch)
+	}
+	data := make([]float64, len(c.data))
+	for i := 0; i < c.rows; i++ {
+		for k := c.indptr[i]; k < c.indptr[i+1]; k++ {
+			data[k] = c.data[k] * scale[i]
+		}
+	}
+	return &CSR{
+		rows:    c.rows,
+		cols:    c.cols,
+		indptr:  c.indptr,
+		indices: c.indices,
+		data:    data,
+	}, nil
+}
+
+// Stack returns the rows of the matrix followed by the rows of b with the same number of columns
+func (c *CSR) Stack(b *CSR) (*CSR, error) {
+	if b.cols != c.cols {
+		return nil, fmt.Errorf("stacking %d columns on %d columns, %w", b.cols, c.cols, ErrColMismatch)
+	}
+	indptr := make([]int, 0, c.rows+b.rows+1)
+	indptr = append(indptr, c.indptr...)
+	for _, p := range b.indptr[1:] {
+		indptr = append(indptr, p+len(c.data))
+	}
+	indices := make([]int, 0, len(c.indices)+len(b.indices))
+	indices = append(append(indices, c.indices...), b.indices...)
+	data := make([]float64, 0, len(c.data)+len(b.data))
+	data = append(append(data, c.data...), b.data...)
+	return &CSR{
+		rows:    c.rows + b.rows,
+		cols:    c.cols,
+		indptr:  indptr,
+		indices: indices,
+		data:    data,
+	}, nil
+}
+
+// PrependOnes returns a copy of the matrix with a constant column of ones before the first column
+func (c *CSR) PrependOnes() *CSR {
+	indptr := make([]int, c.rows+1)
+	indices := make([]int, 0, len(c.indices)+c.rows)
+	data := make([]float64, 0, len(c.data)+c.rows)
+	for i := 0; i < c.rows; i++ {
+		indices = append(indices, 0)
+		data = append(data, 1.0)
+		for k := c.indptr[i]; k < c.indptr[i+1]; k++ {
+			indices = append(indices, c.indices[k]+1)
+			data = append(data, c.data[k])
+		}
+		indptr[i+1] = len(data)
+	}
+	return &CSR{
+		rows:    c.rows,
+		cols:    c.cols + 1,
+		indptr:  indptr,
+		indices: indices,
+		data:    data,
+	}
+}
+
+// Columns returns the sparse columns of the matrix for column wise algorithms such as coordinate
+// descent
+func (c *CSR) Columns() []SparseVector {
+	counts := make([]int, c.cols)
+	for _, j := range c.indices {
+		counts[j]++
+	}
+	cols := make([]SparseVector, c.cols)
+	for j := range cols {
+		cols[j] = SparseVector{
+			Len:     c.rows,
+			Indices: make([]int, 0, counts[j]),
+			Data:    make([]float64, 0, counts[j]),
+		}
+	}
+	for i := 0; i < c.rows; i++ {
+		for k := c.indptr[i]; k < c.indptr[i+1]; k++ {
+			col := &cols[c.indices[k]]
+			col.Indices = append(col.Indices, i)
+			col.Data = append(col.Data, c.data[k])
+		}
+	}
+	return cols
+}
@@ -0,0 +1,114 @@
+package mat
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestNewCSR(t *testing.T) {
+	testData := map[string]struct {
+		rows, cols int
+		indptr     []int
+		indices    []int
+		data       []float64
+		err        error
+	}{
+		"valid":              {2, 3, []int{0, 2, 3}, []int{0, 2, 1}, []float64{1, 2, 3}, nil},
+		"empty rows":         {2, 3, []int{0, 0, 0}, []int{}, []float64{}, nil},
+		"short row pointers": {2, 3, []int{0, 2}, []int{0, 2}, []float64{1, 2}, ErrInvalidSparse},
+		"missing data":       {2, 3, []int{0, 2, 3}, []int{0, 2, 1}, []float64{1, 2}, ErrInvalidSparse},
+		"decreasing pointer": {2, 3, []int{0, 2, 1}, []int{0, 2}, []float64{1, 2}, ErrInvalidSparse},
+		"unordered columns":  {1, 3, []int{0, 2}, []int{2, 0}, []float64{1, 2}, ErrInvalidSparse},
+		"column out of range": {
+			1, 3, []int{0, 1}, []int{3}, []float64{1}, ErrInvalidSparse,
+		},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			c, err := NewCSR(td.rows, td.cols, td.indptr, td.indices, td.data)
+			if td.err != nil {
+				assert.ErrorIs(t, err, td.err)
+				return
+			}
+			require.Nil(t, err)
+			m, n := c.Dims()
+			assert.Equal(t, td.rows, m)
+			assert.Equal(t, td.cols, n)
+			assert.Equal(t, len(td.data), c.NNZ())
+		})
+	}
+}
+
+func TestCSR(t *testing.T) {
+	x := [][]float64{
+		{1, 0, 0},
+		{0, 0, 2},
+		{0, 0, 0},
+		{3, 4, 0},
+	}
+	dense, err := NewDenseFromArray(x)
+	require.Nil(t, err)
+
+	cols := make([][]float64, 3)
+	for j := range cols {
+		cols[j] = mat.Col(nil, j, dense)
+	}
+	c, err := NewCSRFromColumns(4, cols)
+	require.Nil(t, err)
+
+	assert.Equal(t, 4, c.NNZ())
+	assert.InDelta(t, 1.0/3.0, c.Density(), 1e-12)
+	assert.True(t, mat.Equal(dense, c))
+	assert.True(t, mat.Equal(dense.T(), c.T()))
+
+	res, err := c.MulVec([]float64{1, 2, 3})
+	require.Nil(t, err)
+	assert.Equal(t, []float64{1, 6, 0, 11}, res)
+	_, err = c.MulVec([]float64{1, 2})
+	assert.ErrorIs(t, err, ErrColMismatch)
+
+	scaled, err := c.ScaleRows([]float64{2, 1, 5, -1})
+	require.Nil(t, err)
+	expected, err := NewDenseFromArray([][]float64{{2, 0, 0}, {0, 0, 2}, {0, 0, 0}, {-3, -4, 0}})
+	require.Nil(t, err)
+	assert.True(t, mat.Equal(expected, scaled))
+	_, err = c.ScaleRows([]float64{1})
+	assert.ErrorIs(t, err, ErrScaleLenMismatch)
+
+	ones := c.PrependOnes()
+	expected, err = NewDenseFromArray([][]float64{{1, 1, 0, 0}, {1, 0, 0, 2}, {1, 0, 0, 0}, {1, 3, 4, 0}})
+	require.Nil(t, err)
+	assert.True(t, mat.Equal(expected, ones))
+
+	ridge, err := NewCSR(1, 3, []int{0, 1}, []int{1}, []float64{7})
+	require.Nil(t, err)
+	stacked, err := c.Stack(ridge)
+	require.Nil(t, err)
+	var expectedStack mat.Dense
+	expectedStack.Stack(dense, mat.NewDense(1, 3, []float64{0, 7, 0}))
+	assert.True(t, mat.Equal(&expectedStack, stacked))
+	_, err = c.Stack(ones)
+	assert.ErrorIs(t, err, ErrColMismatch)
+
+	y := []float64{1, -1, 2, 0.5}
+	for j, col := range c.Columns() {
+		assert.Equal(t, 4, col.Len)
+		assert.InDelta(t, floats.Dot(cols[j], y), col.Dot(y), 1e-12)
+		for k, other := range c.Columns() {
+			assert.InDelta(t, floats.Dot(cols[j], cols[k]), col.DotSparse(other), 1e-12)
+		}
+		dst := make([]float64, 4)
+		col.AddScaledTo(dst, 2)
+		for i := range dst {
+			assert.Equal(t, 2*cols[j][i], dst[i])
+		}
+	}
+
+	_, err = NewCSRFromColumns(3, cols)
+	assert.ErrorIs(t, err, ErrRowMismatch)
+}
@@ -2,18 +2,76 @@ package forecaster
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"time"
 
 	"github.com/aouyang1/go-forecaster/forecast"
+	"github.com/aouyang1/go-forecaster/forecast/options"
+	"github.com/aouyang1/go-forecaster/timedataset"
 )
 
 // Model is a serializeable representation of the forecaster's configurations and models for the
 // forecast and uncertainty.
 type Model struct {
-	Options     *Options       `json:"options"`
-	Series      forecast.Model `json:"series_model"`
-	Uncertainty forecast.Model `json:"uncertainty_model"`
+	SchemaVersion int            `json:"schema_version"`
+	Options       *Options       `json:"options"`
+	Series        forecast.Model `json:"series_model"`
+	Uncertainty   forecast.Model `json:"uncertainty_model"`
+
+	// Scores are the scores of the forecast and uncertainty bands against the training data
+	Scores *forecast.Scores `json:"scores,omitempty"`
+
+	// Residuals summarizes the training residual and uncertainty if Options.ResidualPersistence is set
+	Residuals *ResidualSummary `json:"residuals,omitempty"`
+}
+
+// SchemaVersion is the version of the serialized forecaster model written by Model. Models serialized
+// before versioning have a schema version of 0. The series and uncertainty models carry their own
+// forecast.SchemaVersion.
+const SchemaVersion = 1
+
+// migrations upgrade a model from the schema version of its index to the next version
+var migrations = []func(m *Model) error{
+	migrateMissingOptions,
+}
+
+// Migrate upgrades a model serialized by an older version of this package to the current schema
+// version in place along with its series and uncertainty models. Models newer than the current schema
+// version cannot be loaded since their options may not be representable.
+func (m *Model) Migrate() error {
+	if m.SchemaVersion < 0 || m.SchemaVersion > SchemaVersion {
+		return fmt.Errorf("schema version %d with latest %d, %w", m.SchemaVersion, SchemaVersion, forecast.ErrUnsupportedSchemaVersion)
+	}
+	if m.Options == nil {
+		return ErrNoOptionsInModel
+	}
+	for version := m.SchemaVersion; version < SchemaVersion; version++ {
+		if err := migrations[version](m); err != nil {
+			return fmt.Errorf("unable to migrate from schema version %d, %w", version, err)
+		}
+		m.SchemaVersion = version + 1
+	}
+	if err := m.Series.Migrate(); err != nil {
+		return fmt.Errorf("unable to migrate series model, %w", err)
+	}
+	if err := m.Uncertainty.Migrate(); err != nil {
+		return fmt.Errorf("unable to migrate uncertainty model, %w", err)
+	}
+	return nil
+}
+
+// migrateMissingOptions upgrades unversioned models saved before the series and uncertainty options
+// were always serialized. Their settings only apply at fit time so empty options predict the same.
+func migrateMissingOptions(m *Model) error {
+	if m.Options.SeriesOptions == nil {
+		m.Options.SeriesOptions = &SeriesOptions{}
+	}
+	if m.Options.UncertaintyOptions == nil {
+		m.Options.UncertaintyOptions = &UncertaintyOptions{}
+	}
+	return nil
 }
 
 func (m Model) JSONPrettyPrint(w io.Writer) error {
@@ -41,7 +99,51 @@ func (m Model) TablePrint(w io.Writer) error {
 					m.Options.SeriesOptions.OutlierOptions.LowerPercentile*100.0,
 					m.Options.SeriesOptions.OutlierOptions.UpperPercentile*100.0,
 				)
+				if m.Options.SeriesOptions.OutlierOptions.seasonal() {
+					fmt.Fprintf(w, "      Seasonal Period: %s    Seasonal Buckets: %d\n",
+						m.Options.SeriesOptions.OutlierOptions.SeasonalPeriod,
+						m.Options.SeriesOptions.OutlierOptions.SeasonalBuckets,
+					)
+				}
+			}
+		}
+		if m.Options.SeriesOptions != nil && m.Options.SeriesOptions.ImputationOptions.enabled() {
+			imp := m.Options.SeriesOptions.ImputationOptions
+			fmt.Fprintf(w, "    Imputation: %s    Max Gap: %s\n", imp.Method, imp.MaxGap)
+		}
+		if m.Options.Resample != nil {
+			interval := "inferred"
+			if m.Options.Resample.Interval > 0 {
+				interval = m.Options.Resample.Interval.String()
+			}
+			aggregation := m.Options.Resample.Aggregation
+			if aggregation == "" {
+				aggregation = timedataset.AggregationMean
+			}
+			fmt.Fprintf(w, "    Resample Interval: %s    Aggregation: %s\n", interval, aggregation)
+		}
+		if m.Options.Autoregression != nil {
+			fmt.Fprintf(w, "    Autoregression Order: %d    Coefficients: %.3f\n",
+				m.Options.Autoregression.Order,
+				m.Options.Autoregression.Coefficients,
+			)
+		}
+		if m.Options.Counter != nil {
+			unit := m.Options.Counter.Unit
+			if unit == 0 {
+				unit = DefaultRateUnit
 			}
+			fmt.Fprintf(w, "    Counter Rate Unit: %s\n", unit)
+		}
+		if m.Options.Transform.enabled() {
+			fmt.Fprintf(w, "    Transform: %s    Lambda: %.3f\n",
+				m.Options.Transform.Method,
+				m.Options.Transform.Lambda,
+			)
+		}
+		if m.Options.Growth.logistic() {
+			ceiling, floor := m.Options.Growth.capacity(m.Series.TrainEndTime)
+			fmt.Fprintf(w, "    Growth: %s    Cap: %.3f    Floor: %.3f\n", m.Options.Growth.Method, ceiling, floor)
 		}
 	}
 
@@ -58,6 +160,41 @@ func (m Model) TablePrint(w io.Writer) error {
 				m.Options.UncertaintyOptions.ResidualWindow,
 				m.Options.UncertaintyOptions.ResidualZscore,
 			)
+			if m.Options.UncertaintyOptions.ResidualWindowDuration > 0 {
+				fmt.Fprintf(w, "    Residual Window Duration: %s\n",
+					m.Options.UncertaintyOptions.ResidualWindowDuration,
+				)
+			}
+			switch m.Options.UncertaintyOptions.ResidualFilter {
+			case ResidualFilterMedian:
+				fmt.Fprintf(w, "    Residual Filter: %s    Window: %d samples\n",
+					m.Options.UncertaintyOptions.ResidualFilter,
+					m.Options.UncertaintyOptions.FilterWindow,
+				)
+			case ResidualFilterSeasonalDiff:
+				fmt.Fprintf(w, "    Residual Filter: %s    Lag: %d samples\n",
+					m.Options.UncertaintyOptions.ResidualFilter,
+					m.Options.UncertaintyOptions.SeasonalLag,
+				)
+			}
+			if m.Options.UncertaintyOptions.Method == UncertaintyMethodBootstrap {
+				fmt.Fprintf(w, "    Method: %s    Bootstraps: %d\n",
+					m.Options.UncertaintyOptions.Method,
+					len(m.Options.UncertaintyOptions.BootstrapModels),
+				)
+			}
+			if m.Options.UncertaintyOptions.Method == UncertaintyMethodQuantile {
+				lowerQ, upperQ, _ := m.Options.UncertaintyOptions.bandQuantiles()
+				fmt.Fprintf(w, "    Method: %s    Lower: %.3f    Upper: %.3f\n",
+					m.Options.UncertaintyOptions.Method, lowerQ, upperQ,
+				)
+			}
+			if mults := m.Options.UncertaintyOptions.HourlyMultipliers; len(mults) > 0 {
+				fmt.Fprintln(w, "    Hourly Multipliers (UTC):")
+				for hour, mult := range mults {
+					fmt.Fprintf(w, "      %02d: %.3f\n", hour, mult)
+				}
+			}
 		}
 	}
 
@@ -65,6 +202,122 @@ func (m Model) TablePrint(w io.Writer) error {
 		return err
 	}
 
+	if m.Scores != nil {
+		fmt.Fprintln(w, "Scores:")
+		fmt.Fprintf(w, "  MAE: %.3f    sMAPE: %.3f    MASE: %.3f    Coverage: %.3f    Pinball: %.3f\n",
+			m.Scores.MAE,
+			m.Scores.SMAPE,
+			m.Scores.MASE,
+			m.Scores.Coverage,
+			m.Scores.Pinball,
+		)
+	}
+
+	if m.Residuals != nil {
+		fmt.Fprintln(w, "Residuals:")
+		fmt.Fprintf(w, "  Count: %d    Missing: %d    Mean: %.3f    Std Dev: %.3f    MAD: %.3f\n",
+			m.Residuals.Residual.Count,
+			m.Residuals.Residual.Missing,
+			m.Residuals.Residual.Mean,
+			m.Residuals.Residual.StdDev,
+			m.Residuals.Residual.MAD,
+		)
+		fmt.Fprintf(w, "  P01: %.3f    P50: %.3f    P99: %.3f\n",
+			m.Residuals.Residual.Quantile(0.01),
+			m.Residuals.Residual.Quantile(0.5),
+			m.Residuals.Residual.Quantile(0.99),
+		)
+	}
+
 	fmt.Fprintln(w, "")
 	return nil
 }
+
+// ModelStats summarizes the size and inference cost of the series and uncertainty models
+type ModelStats struct {
+	Series          forecast.ModelStats `json:"series"`
+	Uncertainty     forecast.ModelStats `json:"uncertainty"`
+	SerializedBytes int                 `json:"serialized_bytes"`
+	FLOPsPerPoint   int                 `json:"flops_per_point"`
+}
+
+// Stats reports the active features of each model, the size of the JSON serialized forecaster model,
+// and an estimate of the floating point operations needed to predict a single point with its bands.
+func (m Model) Stats() (ModelStats, error) {
+	seriesStats, err := m.Series.Stats()
+	if err != nil {
+		return ModelStats{}, fmt.Errorf("unable to compute series model stats, %w", err)
+	}
+	uncertaintyStats, err := m.Uncertainty.Stats()
+	if err != nil {
+		return ModelStats{}, fmt.Errorf("unable to compute uncertainty model stats, %w", err)
+	}
+	out, err := json.Marshal(m)
+	if err != nil {
+		return ModelStats{}, fmt.Errorf("unable to serialize model for stats, %w", err)
+	}
+
+	return ModelStats{
+		Series:          seriesStats,
+		Uncertainty:     uncertaintyStats,
+		SerializedBytes: len(out),
+		// upper and lower bands add and subtract the uncertainty from the series
+		FLOPsPerPoint: seriesStats.FLOPsPerPoint + uncertaintyStats.FLOPsPerPoint + 2,
+	}, nil
+}
+
+// AddEvent adds the event to both the series and uncertainty models without retraining. The
+// coefficients stay fixed so see forecast.Model.AddEvent for how the event affects predictions.
+func (m *Model) AddEvent(ev options.Event) error {
+	series := m.Series
+	if err := series.AddEvent(ev); err != nil {
+		return fmt.Errorf("unable to add event to series model, %w", err)
+	}
+	uncertainty := m.Uncertainty
+	if err := uncertainty.AddEvent(ev); err != nil {
+		return fmt.Errorf("unable to add event to uncertainty model, %w", err)
+	}
+	m.Series = series
+	m.Uncertainty = uncertainty
+	return nil
+}
+
+// RemoveEvent removes the named event from the series and uncertainty models configuring it without
+// retraining
+func (m *Model) RemoveEvent(name string) error {
+	return m.editEither(
+		func(fm *forecast.Model) error { return fm.RemoveEvent(name) },
+		forecast.ErrUnknownEvent,
+	)
+}
+
+// ShiftChangepoint moves the named changepoint by the input duration in the series and uncertainty
+// models configuring it without retraining
+func (m *Model) ShiftChangepoint(name string, shift time.Duration) error {
+	return m.editEither(
+		func(fm *forecast.Model) error { return fm.ShiftChangepoint(name, shift) },
+		forecast.ErrUnknownChangepoint,
+	)
+}
+
+// editEither applies the edit to the series and uncertainty models skipping a model if the edit
+// returns the not found error. The models are only replaced if at least one was edited and neither
+// returned another error.
+func (m *Model) editEither(edit func(*forecast.Model) error, errNotFound error) error {
+	series := m.Series
+	seriesErr := edit(&series)
+	if seriesErr != nil && !errors.Is(seriesErr, errNotFound) {
+		return fmt.Errorf("unable to edit series model, %w", seriesErr)
+	}
+	uncertainty := m.Uncertainty
+	uncertaintyErr := edit(&uncertainty)
+	if uncertaintyErr != nil && !errors.Is(uncertaintyErr, errNotFound) {
+		return fmt.Errorf("unable to edit uncertainty model, %w", uncertaintyErr)
+	}
+	if seriesErr != nil && uncertaintyErr != nil {
+		return seriesErr
+	}
+	m.Series = series
+	m.Uncertainty = uncertainty
+	return nil
+}
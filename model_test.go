@@ -2,12 +2,15 @@ package forecaster
 
 import (
 	"bytes"
+	"encoding/json"
+	"math"
 	"testing"
 	"time"
 
 	"github.com/aouyang1/go-forecaster/feature"
 	"github.com/aouyang1/go-forecaster/forecast"
 	"github.com/aouyang1/go-forecaster/forecast/options"
+	"github.com/aouyang1/go-forecaster/timedataset"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -60,6 +63,7 @@ Uncertainty:
     Training End Time: 1970-01-01 00:00:00 +0000 UTC
   Scores:
     MAPE: 0.123    MSE: 1.234    R2: 0.012
+    MAE: 0.000    sMAPE: 0.000    MASE: 0.000
   Weights:
           Type Labels Value
      Intercept        0.000
@@ -69,6 +73,7 @@ Uncertainty:
     Training End Time: 1970-01-01 00:00:00 +0000 UTC
   Scores:
     MAPE: 0.223    MSE: 1.335    R2: 0.412
+    MAE: 0.000    sMAPE: 0.000    MASE: 0.000
   Weights:
           Type Labels Value
      Intercept        0.000
@@ -172,6 +177,7 @@ Uncertainty:
          e0 1970-01-01 00:00:00 +0000 UTC 1970-01-02 00:00:00 +0000 UTC
   Scores:
     MAPE: 0.123    MSE: 1.234    R2: 0.012
+    MAE: 0.000    sMAPE: 0.000    MASE: 0.000
   Weights:
             Type                                              Labels Value
        Intercept                                                     1.100
@@ -222,6 +228,7 @@ Uncertainty:
     Events: None
   Scores:
     MAPE: 0.123    MSE: 1.234    R2: 0.012
+    MAE: 0.000    sMAPE: 0.000    MASE: 0.000
   Weights:
           Type Labels Value
      Intercept        1.100
@@ -234,6 +241,31 @@ Uncertainty:
           Type Labels Value
      Intercept        0.000
 
+`,
+		},
+		"with residuals": {
+			m: Model{
+				Residuals: &ResidualSummary{
+					Residual: summarizeSeries([]float64{-2, -1, 0, 1, 2, math.NaN()}),
+				},
+			},
+			expected: `Series:
+  Forecast:
+    Training End Time: 0001-01-01 00:00:00 +0000 UTC
+  Weights:
+          Type Labels Value
+     Intercept        0.000
+
+Uncertainty:
+  Forecast:
+    Training End Time: 0001-01-01 00:00:00 +0000 UTC
+  Weights:
+          Type Labels Value
+     Intercept        0.000
+Residuals:
+  Count: 5    Missing: 1    Mean: 0.000    Std Dev: 1.581    MAD: 1.000
+  P01: -1.960    P50: 0.000    P99: 1.960
+
 `,
 		},
 	}
@@ -247,3 +279,81 @@ Uncertainty:
 		})
 	}
 }
+
+func TestModelEditEvents(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	promo := options.NewEvent("promo", start, start.Add(time.Hour))
+
+	newModel := func() Model {
+		seriesOpt := options.NewDefaultOptions()
+		seriesOpt.EventOptions.Events = []options.Event{promo}
+		return Model{
+			Options:     NewDefaultOptions(),
+			Series:      forecast.Model{TrainEndTime: start, Options: seriesOpt},
+			Uncertainty: forecast.Model{TrainEndTime: start, Options: options.NewDefaultOptions()},
+		}
+	}
+
+	m := newModel()
+	require.Nil(t, m.RemoveEvent("promo"))
+	assert.Empty(t, m.Series.Events())
+	assert.Empty(t, m.Uncertainty.Events())
+	assert.ErrorIs(t, m.RemoveEvent("promo"), forecast.ErrUnknownEvent)
+
+	require.Nil(t, m.AddEvent(promo))
+	assert.Equal(t, []options.Event{promo}, m.Series.Events())
+	assert.Equal(t, []options.Event{promo}, m.Uncertainty.Events())
+
+	m = newModel()
+	assert.ErrorIs(t, m.AddEvent(promo), forecast.ErrDuplicateEvent)
+	assert.Empty(t, m.Uncertainty.Events())
+	assert.ErrorIs(t, m.ShiftChangepoint("release", time.Hour), forecast.ErrUnknownChangepoint)
+}
+
+func TestNewFromModelLegacySchema(t *testing.T) {
+	tWin := timedataset.GenerateT(4*24, time.Hour, func() time.Time {
+		return time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC)
+	})
+	y := make([]float64, len(tWin))
+	for i := range y {
+		y[i] = float64(i%24) + float64(i%5)
+	}
+	f, err := New(nil)
+	require.Nil(t, err)
+	require.Nil(t, f.Fit(tWin, y))
+	expected, err := f.Predict(tWin)
+	require.Nil(t, err)
+
+	model, err := f.Model()
+	require.Nil(t, err)
+	assert.Equal(t, SchemaVersion, model.SchemaVersion)
+	assert.Equal(t, forecast.SchemaVersion, model.Series.SchemaVersion)
+
+	// strip the schema versions and the options only used at fit time as serialized before versioning
+	out, err := json.Marshal(model)
+	require.Nil(t, err)
+	var raw map[string]any
+	require.Nil(t, json.Unmarshal(out, &raw))
+	delete(raw, "schema_version")
+	delete(raw["series_model"].(map[string]any), "schema_version")
+	delete(raw["uncertainty_model"].(map[string]any), "schema_version")
+	rawOpt := raw["options"].(map[string]any)
+	delete(rawOpt, "series_options")
+	delete(rawOpt, "uncertainty_options")
+	out, err = json.Marshal(raw)
+	require.Nil(t, err)
+
+	var legacy Model
+	require.Nil(t, json.Unmarshal(out, &legacy))
+	loaded, err := NewFromModel(legacy)
+	require.Nil(t, err)
+	res, err := loaded.Predict(tWin)
+	require.Nil(t, err)
+	assert.InDeltaSlice(t, expected.Forecast, res.Forecast, 1e-9)
+	assert.InDeltaSlice(t, expected.Upper, res.Upper, 1e-9)
+
+	// models from a newer version are rejected
+	model.SchemaVersion = SchemaVersion + 1
+	_, err = NewFromModel(model)
+	assert.ErrorIs(t, err, forecast.ErrUnsupportedSchemaVersion)
+}
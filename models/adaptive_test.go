@@ -0,0 +1,79 @@
+package models
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestAdaptiveLasso(t *testing.T) {
+	// y = 3*x0 + 4*x1 where x2 is correlated with x0 and x3 is noise
+	rng := rand.New(rand.NewSource(1))
+	m := 200
+	x := mat.NewDense(m, 4, nil)
+	y := mat.NewDense(m, 1, nil)
+	for i := 0; i < m; i++ {
+		x0 := rng.NormFloat64()
+		x1 := rng.NormFloat64()
+		x.SetRow(i, []float64{x0, x1, x0 + 0.3*rng.NormFloat64(), rng.NormFloat64()})
+		y.Set(i, 0, 3*x0+4*x1+0.1*rng.NormFloat64())
+	}
+
+	g := NewGram(4)
+	require.Nil(t, g.Add(x, y))
+	weights, err := AdaptiveWeights(g, 0, 0)
+	require.Nil(t, err)
+	require.Len(t, weights, 4)
+	assert.Less(t, weights[0], weights[2])
+	assert.Less(t, weights[1], weights[3])
+
+	lambda := 20.0
+	testData := map[string]struct {
+		weights []float64
+	}{
+		"uniform":  {nil},
+		"adaptive": {weights},
+	}
+
+	coefs := make(map[string][]float64)
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			model, err := NewLassoRegression(&LassoOptions{
+				Lambda:         lambda,
+				Iterations:     DefaultIterations,
+				Tolerance:      1e-6,
+				PenaltyWeights: td.weights,
+			})
+			require.Nil(t, err)
+			require.Nil(t, model.Fit(x, y))
+			coefs[name] = model.Coef()
+		})
+	}
+
+	// the adaptive penalty removes the spurious features while shrinking the true coefficients less
+	assert.Equal(t, 0.0, coefs["adaptive"][2])
+	assert.Equal(t, 0.0, coefs["adaptive"][3])
+	assert.InDelta(t, 3.0, coefs["adaptive"][0], 0.05)
+	assert.InDelta(t, 4.0, coefs["adaptive"][1], 0.05)
+	assert.Greater(t, coefs["adaptive"][0], coefs["uniform"][0])
+	assert.Greater(t, coefs["adaptive"][1], coefs["uniform"][1])
+}
+
+func TestLassoPenaltyWeightsSize(t *testing.T) {
+	x := mat.NewDense(3, 2, []float64{1, 2, 3, 4, 5, 6})
+	y := mat.NewDense(3, 1, []float64{1, 2, 3})
+
+	model, err := NewLassoRegression(&LassoOptions{
+		Lambda:         1.0,
+		Iterations:     10,
+		PenaltyWeights: []float64{1.0},
+	})
+	require.Nil(t, err)
+	assert.ErrorIs(t, model.Fit(x, y), ErrPenaltyWeightsSize)
+
+	_, err = NewLassoRegression(&LassoOptions{PenaltyWeights: []float64{-1.0}})
+	assert.ErrorIs(t, err, ErrNegativeWeight)
+}
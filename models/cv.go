@@ -0,0 +1,135 @@
+package models
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/aouyang1/go-forecaster/errs"
+	"gonum.org/v1/gonum/stat"
+)
+
+var (
+	ErrInsufficientFoldData = errs.NewDataError(errs.CodeInsufficientData, "not enough observations for the number of folds", nil)
+	ErrNegativeFolds        = errs.NewConfigError(errs.CodeInvalidOption, "negative number of cross validation folds", nil)
+	ErrUnknownCVScoring     = errs.NewConfigError(errs.CodeInvalidOption, "unknown cross validation scoring metric", nil)
+)
+
+// MinFoldSize is the minimum number of observations in each block of a time series split
+const MinFoldSize = 2
+
+// Split is a pair of contiguous index ranges of ordered observations where the model is trained on
+// [TrainStart, TrainEnd) and evaluated on [TestStart, TestEnd).
+type Split struct {
+	TrainStart int `json:"train_start"`
+	TrainEnd   int `json:"train_end"`
+	TestStart  int `json:"test_start"`
+	TestEnd    int `json:"test_end"`
+}
+
+// TimeSeriesCVSplit generates k expanding window splits of n ordered observations. The observations are
+// divided into k+1 contiguous blocks and the i-th split trains on the first i+1 blocks and tests on the
+// following block so no split is evaluated on data preceding its training data. Any remainder is added
+// to the last test block.
+func TimeSeriesCVSplit(n, k int) ([]Split, error) {
+	if k < 1 {
+		return nil, fmt.Errorf("got %d folds, %w", k, ErrInsufficientFoldData)
+	}
+	blockSize := n / (k + 1)
+	if blockSize < MinFoldSize {
+		return nil, fmt.Errorf("%d observations for %d folds, %w", n, k, ErrInsufficientFoldData)
+	}
+
+	splits := make([]Split, 0, k)
+	for i := 0; i < k; i++ {
+		testEnd := (i + 2) * blockSize
+		if i == k-1 {
+			testEnd = n
+		}
+		splits = append(splits, Split{
+			TrainStart: 0,
+			TrainEnd:   (i + 1) * blockSize,
+			TestStart:  (i + 1) * blockSize,
+			TestEnd:    testEnd,
+		})
+	}
+	return splits, nil
+}
+
+// RollingOriginSplit generates k rolling origin splits of n ordered observations each testing on the
+// horizon observations following its origin. The last split tests on the final horizon observations and
+// earlier origins are spaced step observations apart. A positive window trains each split on the window
+// observations preceding its origin while a zero window expands the training range back to the first
+// observation.
+func RollingOriginSplit(n, k, horizon, step, window int) ([]Split, error) {
+	if k < 1 || horizon < 1 || step < 1 || window < 0 {
+		return nil, fmt.Errorf("got %d folds with horizon %d, step %d, and window %d, %w", k, horizon, step, window, ErrInsufficientFoldData)
+	}
+	firstOrigin := n - horizon - (k-1)*step
+	trainSize := firstOrigin
+	if window > 0 {
+		trainSize = window
+	}
+	if trainSize < MinFoldSize || firstOrigin < trainSize {
+		return nil, fmt.Errorf("%d observations for %d folds with horizon %d, step %d, and window %d, %w", n, k, horizon, step, window, ErrInsufficientFoldData)
+	}
+
+	splits := make([]Split, 0, k)
+	for i := 0; i < k; i++ {
+		origin := firstOrigin + i*step
+		trainStart := 0
+		if window > 0 {
+			trainStart = origin - window
+		}
+		splits = append(splits, Split{
+			TrainStart: trainStart,
+			TrainEnd:   origin,
+			TestStart:  origin,
+			TestEnd:    origin + horizon,
+		})
+	}
+	return splits, nil
+}
+
+// CVScoring is the metric used to score the out of sample predictions of each cross validation split
+type CVScoring string
+
+const (
+	// CVScoringR2 scores by the coefficient of determination. This is the default if unset.
+	CVScoringR2 CVScoring = "r2"
+
+	// CVScoringMSE scores by the mean squared error
+	CVScoringMSE CVScoring = "mse"
+
+	// CVScoringMAE scores by the mean absolute error
+	CVScoringMAE CVScoring = "mae"
+)
+
+// Validate returns an error if the scoring metric is unknown
+func (s CVScoring) Validate() error {
+	switch s {
+	case "", CVScoringR2, CVScoringMSE, CVScoringMAE:
+		return nil
+	}
+	return fmt.Errorf("%q, %w", s, ErrUnknownCVScoring)
+}
+
+// Score computes the score of the predictions where a higher score is better. Errors are negated so
+// that every metric is maximized.
+func (s CVScoring) Score(y, pred []float64) float64 {
+	switch s {
+	case CVScoringMSE:
+		var sse float64
+		for i := range y {
+			diff := y[i] - pred[i]
+			sse += diff * diff
+		}
+		return -sse / float64(len(y))
+	case CVScoringMAE:
+		var sae float64
+		for i := range y {
+			sae += math.Abs(y[i] - pred[i])
+		}
+		return -sae / float64(len(y))
+	}
+	return stat.RSquaredFrom(pred, y, nil)
+}
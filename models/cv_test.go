@@ -0,0 +1,134 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeSeriesCVSplit(t *testing.T) {
+	testData := map[string]struct {
+		n        int
+		k        int
+		expected []Split
+		err      error
+	}{
+		"no folds": {
+			n: 10, k: 0,
+			err: ErrInsufficientFoldData,
+		},
+		"too few observations": {
+			n: 5, k: 3,
+			err: ErrInsufficientFoldData,
+		},
+		"even": {
+			n: 9, k: 2,
+			expected: []Split{
+				{TrainStart: 0, TrainEnd: 3, TestStart: 3, TestEnd: 6},
+				{TrainStart: 0, TrainEnd: 6, TestStart: 6, TestEnd: 9},
+			},
+		},
+		"remainder": {
+			n: 11, k: 2,
+			expected: []Split{
+				{TrainStart: 0, TrainEnd: 3, TestStart: 3, TestEnd: 6},
+				{TrainStart: 0, TrainEnd: 6, TestStart: 6, TestEnd: 11},
+			},
+		},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			res, err := TimeSeriesCVSplit(td.n, td.k)
+			if td.err != nil {
+				assert.ErrorIs(t, err, td.err)
+				return
+			}
+			require.Nil(t, err)
+			assert.Equal(t, td.expected, res)
+		})
+	}
+}
+
+func TestRollingOriginSplit(t *testing.T) {
+	testData := map[string]struct {
+		n, k, horizon, step, window int
+		expected                    []Split
+		err                         error
+	}{
+		"expanding": {
+			n: 10, k: 3, horizon: 2, step: 2,
+			expected: []Split{
+				{TrainStart: 0, TrainEnd: 4, TestStart: 4, TestEnd: 6},
+				{TrainStart: 0, TrainEnd: 6, TestStart: 6, TestEnd: 8},
+				{TrainStart: 0, TrainEnd: 8, TestStart: 8, TestEnd: 10},
+			},
+		},
+		"sliding overlapping tests": {
+			n: 10, k: 3, horizon: 3, step: 1, window: 4,
+			expected: []Split{
+				{TrainStart: 1, TrainEnd: 5, TestStart: 5, TestEnd: 8},
+				{TrainStart: 2, TrainEnd: 6, TestStart: 6, TestEnd: 9},
+				{TrainStart: 3, TrainEnd: 7, TestStart: 7, TestEnd: 10},
+			},
+		},
+		"no folds": {
+			n: 10, k: 0, horizon: 2, step: 2,
+			err: ErrInsufficientFoldData,
+		},
+		"no horizon": {
+			n: 10, k: 2, step: 2,
+			err: ErrInsufficientFoldData,
+		},
+		"too few observations": {
+			n: 6, k: 3, horizon: 2, step: 2,
+			err: ErrInsufficientFoldData,
+		},
+		"window too long": {
+			n: 10, k: 3, horizon: 2, step: 2, window: 5,
+			err: ErrInsufficientFoldData,
+		},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			res, err := RollingOriginSplit(td.n, td.k, td.horizon, td.step, td.window)
+			if td.err != nil {
+				assert.ErrorIs(t, err, td.err)
+				return
+			}
+			require.Nil(t, err)
+			assert.Equal(t, td.expected, res)
+		})
+	}
+}
+
+func TestCVScoring(t *testing.T) {
+	y := []float64{1, 2, 3, 4}
+	pred := []float64{1, 2, 3, 6}
+
+	testData := map[string]struct {
+		scoring  CVScoring
+		expected float64
+		err      error
+	}{
+		"default r2": {scoring: "", expected: 1.0 - 4.0/5.0},
+		"r2":         {scoring: CVScoringR2, expected: 1.0 - 4.0/5.0},
+		"mse":        {scoring: CVScoringMSE, expected: -1.0},
+		"mae":        {scoring: CVScoringMAE, expected: -0.5},
+		"unknown":    {scoring: "rmsle", err: ErrUnknownCVScoring},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			err := td.scoring.Validate()
+			if td.err != nil {
+				assert.ErrorIs(t, err, td.err)
+				return
+			}
+			require.Nil(t, err)
+			assert.InDelta(t, td.expected, td.scoring.Score(y, pred), 1e-9)
+		})
+	}
+}
@@ -0,0 +1,356 @@
+package models
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/aouyang1/go-forecaster/errs"
+	"gonum.org/v1/gonum/mat"
+)
+
+const DefaultL1Ratio = 0.5
+
+var ErrInvalidL1Ratio = errs.NewConfigError(errs.CodeInvalidOption, "l1 ratio must be between 0 and 1 inclusive", nil)
+
+// ElasticNetOptions represents input options to run the Elastic Net Regression
+type ElasticNetOptions struct {
+	// WarmStartBeta is used to prime the coordinate descent to reduce the training time if a previous
+	// fit has been performed.
+	WarmStartBeta []float64
+
+	// Lambda represents the overall penalty multiplier, controlling the regularization. Must be non-negative.
+	Lambda float64
+
+	// L1Ratio mixes the penalty between L1 and L2 where 1.0 is the Lasso and 0.0 is Ridge.
+	L1Ratio float64
+
+	// Iterations is the maximum number of times the fit loops through training all coefficients.
+	Iterations int
+
+	// Tolerance is the smallest coefficient channge on each iteration to determine when to stop iterating.
+	Tolerance float64
+
+	// FitIntercept adds a constant 1.0 feature as the first column if set to true. The intercept is not
+	// penalized.
+	FitIntercept bool
+}
+
+// Validate runs basic validation on Elastic Net options
+func (e *ElasticNetOptions) Validate() (*ElasticNetOptions, error) {
+	if e == nil {
+		e = NewDefaultElasticNetOptions()
+	}
+
+	if e.Lambda < 0 {
+		return nil, ErrNegativeLambda
+	}
+	if e.L1Ratio < 0 || e.L1Ratio > 1 {
+		return nil, fmt.Errorf("l1 ratio of %.3f, %w", e.L1Ratio, ErrInvalidL1Ratio)
+	}
+	if e.Iterations < 0 {
+		return nil, ErrNegativeIterations
+	}
+	if e.Tolerance < 0 {
+		return nil, ErrNegativeTolerance
+	}
+	return e, nil
+}
+
+// NewDefaultElasticNetOptions returns a default set of Elastic Net Regression options
+func NewDefaultElasticNetOptions() *ElasticNetOptions {
+	return &ElasticNetOptions{
+		Lambda:       DefaultLambda,
+		L1Ratio:      DefaultL1Ratio,
+		Iterations:   DefaultIterations,
+		Tolerance:    DefaultTolerance,
+		FitIntercept: true,
+	}
+}
+
+// ElasticNetRegression computes the elastic net regression using covariance update coordinate descent
+// minimizing 0.5*||y - Xb||^2 + lambda*l1Ratio*||b||_1 + 0.5*lambda*(1-l1Ratio)*||b||^2
+type ElasticNetRegression struct {
+	opt *ElasticNetOptions
+
+	coef      []float64
+	intercept float64
+}
+
+// NewElasticNetRegression initializes an Elastic Net model ready for fitting
+func NewElasticNetRegression(opt *ElasticNetOptions) (*ElasticNetRegression, error) {
+	opt, err := opt.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return &ElasticNetRegression{
+		opt: opt,
+	}, nil
+}
+
+// Fit the model according to the given training data
+func (e *ElasticNetRegression) Fit(x, y mat.Matrix) error {
+	if e.opt == nil {
+		return ErrNoOptions
+	}
+	g, err := newGramFromMatrix(x, y, e.opt.FitIntercept)
+	if err != nil {
+		return err
+	}
+	return e.FitGram(g)
+}
+
+// FitGram fits the model from precomputed sufficient statistics. The intercept is not added
+// automatically, so if FitIntercept is set the first accumulated feature is expected to be the constant
+// 1.0 column.
+func (e *ElasticNetRegression) FitGram(g *Gram) error {
+	if e.opt == nil {
+		return ErrNoOptions
+	}
+	if g == nil || g.N == 0 {
+		return ErrNoTrainingMatrix
+	}
+	n := g.Features()
+
+	if e.opt.WarmStartBeta != nil && len(e.opt.WarmStartBeta) != n {
+		return fmt.Errorf("warm start beta has %d features instead of %d, %w", len(e.opt.WarmStartBeta), n, ErrWarmStartBetaSize)
+	}
+
+	beta := make([]float64, n)
+	if e.opt.WarmStartBeta != nil {
+		copy(beta, e.opt.WarmStartBeta)
+	}
+
+	l1 := e.opt.Lambda * e.opt.L1Ratio
+	l2 := e.opt.Lambda * (1 - e.opt.L1Ratio)
+	for i := 0; i < e.opt.Iterations; i++ {
+		maxCoef := 0.0
+		maxUpdate := 0.0
+
+		for j := 0; j < n; j++ {
+			betaCurr := beta[j]
+			if i != 0 && betaCurr == 0 {
+				continue
+			}
+			xdot := g.XTX.At(j, j)
+			if xdot == 0 {
+				continue
+			}
+
+			// x_j . partial residual = x_j . y - sum_k (x_j . x_k) * beta_k + x_j . x_j * beta_j
+			num := g.XTy[j]
+			for k := 0; k < n; k++ {
+				num -= g.XTX.At(j, k) * beta[k]
+			}
+			num += xdot * betaCurr
+
+			betaNext := num / xdot
+			if !(e.opt.FitIntercept && j == 0) {
+				betaNext = SoftThreshold(num, l1) / (xdot + l2)
+			}
+
+			maxCoef = math.Max(maxCoef, math.Abs(betaNext))
+			maxUpdate = math.Max(maxUpdate, math.Abs(betaNext-betaCurr))
+			beta[j] = betaNext
+		}
+
+		if maxUpdate < e.opt.Tolerance*maxCoef {
+			break
+		}
+	}
+
+	e.intercept, e.coef = splitBeta(beta, e.opt.FitIntercept)
+	return nil
+}
+
+// Predict using the Elastic Net model
+func (e *ElasticNetRegression) Predict(x mat.Matrix) ([]float64, error) {
+	if e.opt == nil {
+		return nil, ErrNoOptions
+	}
+	return predictLinear(x, e.intercept, e.coef)
+}
+
+// Score computes the coefficient of determination of the prediction
+func (e *ElasticNetRegression) Score(x, y mat.Matrix) (float64, error) {
+	if e.opt == nil {
+		return 0.0, ErrNoOptions
+	}
+	return scoreLinear(e, x, y)
+}
+
+// Intercept returns the computed intercept if FitIntercept is set to true. Defaults to 0.0 if not set.
+func (e *ElasticNetRegression) Intercept() float64 {
+	return e.intercept
+}
+
+// Coef returns a slice of the trained coefficients in the same order of the training feature Matrix by column.
+func (e *ElasticNetRegression) Coef() []float64 {
+	return e.coef
+}
+
+// ElasticNetAutoOptions represents input options to run the Elastic Net Regression with the optimal
+// combination of regularization parameter lambda and l1 ratio
+type ElasticNetAutoOptions struct {
+	// Lambdas are the overall penalty multipliers to sweep. Must be non-negative.
+	Lambdas []float64
+
+	// L1Ratios are the mixes between L1 and L2 penalties to sweep. Must be between 0 and 1 inclusive.
+	L1Ratios []float64
+
+	// Iterations is the maximum number of times the fit loops through training all coefficients.
+	Iterations int
+
+	// Tolerance is the smallest coefficient channge on each iteration to determine when to stop iterating.
+	Tolerance float64
+
+	// FitIntercept adds a constant 1.0 feature as the first column if set to true
+	FitIntercept bool
+}
+
+// Validate runs basic validation on Elastic Net Auto options
+func (e *ElasticNetAutoOptions) Validate() (*ElasticNetAutoOptions, error) {
+	if e == nil {
+		e = NewDefaultElasticNetAutoOptions()
+	}
+
+	if len(e.Lambdas) == 0 {
+		return nil, ErrNoLambdas
+	}
+	for _, lambda := range e.Lambdas {
+		if lambda < 0.0 {
+			return nil, ErrNegativeLambda
+		}
+	}
+	if len(e.L1Ratios) == 0 {
+		e.L1Ratios = []float64{DefaultL1Ratio}
+	}
+	for _, ratio := range e.L1Ratios {
+		if ratio < 0 || ratio > 1 {
+			return nil, fmt.Errorf("l1 ratio of %.3f, %w", ratio, ErrInvalidL1Ratio)
+		}
+	}
+	if e.Iterations < 0 {
+		return nil, ErrNegativeIterations
+	}
+	if e.Tolerance < 0 {
+		return nil, ErrNegativeTolerance
+	}
+	return e, nil
+}
+
+// NewDefaultElasticNetAutoOptions returns a default set of Elastic Net Auto Regression options
+func NewDefaultElasticNetAutoOptions() *ElasticNetAutoOptions {
+	return &ElasticNetAutoOptions{
+		Lambdas:      []float64{DefaultLambda},
+		L1Ratios:     []float64{DefaultL1Ratio},
+		Iterations:   DefaultIterations,
+		Tolerance:    DefaultTolerance,
+		FitIntercept: true,
+	}
+}
+
+// ElasticNetAutoRegression computes the elastic net regression over the grid of lambdas and l1 ratios
+// and keeps the model with the best in-sample coefficient of determination
+type ElasticNetAutoRegression struct {
+	opt *ElasticNetAutoOptions
+
+	bestModel *ElasticNetRegression
+}
+
+// NewElasticNetAutoRegression initializes an Elastic Net model ready for fitting using automated lambda
+// and l1 ratio selection
+func NewElasticNetAutoRegression(opt *ElasticNetAutoOptions) (*ElasticNetAutoRegression, error) {
+	opt, err := opt.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return &ElasticNetAutoRegression{
+		opt: opt,
+	}, nil
+}
+
+// Fit the model according to the given training data
+func (e *ElasticNetAutoRegression) Fit(x, y mat.Matrix) error {
+	if e.opt == nil {
+		return ErrNoOptions
+	}
+	g, err := newGramFromMatrix(x, y, e.opt.FitIntercept)
+	if err != nil {
+		return err
+	}
+	return e.FitGram(g)
+}
+
+// FitGram fits an Elastic Net model per lambda and l1 ratio from precomputed sufficient statistics and
+// keeps the model with the best in-sample coefficient of determination. The intercept is not added
+// automatically, so if FitIntercept is set the first accumulated feature is expected to be the constant
+// 1.0 column.
+func (e *ElasticNetAutoRegression) FitGram(g *Gram) error {
+	if e.opt == nil {
+		return ErrNoOptions
+	}
+	if g == nil || g.N == 0 {
+		return ErrNoTrainingMatrix
+	}
+
+	e.bestModel = nil
+	bestScore := math.Inf(-1)
+	for _, ratio := range e.opt.L1Ratios {
+		for _, lambda := range e.opt.Lambdas {
+			reg, err := NewElasticNetRegression(&ElasticNetOptions{
+				Lambda:       lambda,
+				L1Ratio:      ratio,
+				Iterations:   e.opt.Iterations,
+				Tolerance:    e.opt.Tolerance,
+				FitIntercept: e.opt.FitIntercept,
+			})
+			if err != nil {
+				return err
+			}
+			if err := reg.FitGram(g); err != nil {
+				return fmt.Errorf("lambda of %.3f and l1 ratio of %.3f, %w", lambda, ratio, err)
+			}
+			beta := reg.Coef()
+			if e.opt.FitIntercept {
+				beta = append([]float64{reg.Intercept()}, beta...)
+			}
+			if score := g.RSquared(beta); score > bestScore {
+				bestScore = score
+				e.bestModel = reg
+			}
+		}
+	}
+	return nil
+}
+
+// Predict using the Elastic Net model
+func (e *ElasticNetAutoRegression) Predict(x mat.Matrix) ([]float64, error) {
+	if e.bestModel == nil {
+		return nil, ErrNoOptions
+	}
+	return e.bestModel.Predict(x)
+}
+
+// Score computes the coefficient of determination of the prediction
+func (e *ElasticNetAutoRegression) Score(x, y mat.Matrix) (float64, error) {
+	if e.bestModel == nil {
+		return 0.0, ErrNoOptions
+	}
+	return e.bestModel.Score(x, y)
+}
+
+// Intercept returns the computed intercept if FitIntercept is set to true. Defaults to 0.0 if not set.
+func (e *ElasticNetAutoRegression) Intercept() float64 {
+	if e == nil || e.bestModel == nil {
+		return 0.0
+	}
+	return e.bestModel.Intercept()
+}
+
+// Coef returns a slice of the trained coefficients in the same order of the training feature Matrix by column.
+func (e *ElasticNetAutoRegression) Coef() []float64 {
+	if e == nil || e.bestModel == nil {
+		return nil
+	}
+	return e.bestModel.Coef()
+}
@@ -0,0 +1,118 @@
+package models
+
+import (
+	"testing"
+
+	mat_ "github.com/aouyang1/go-forecaster/mat"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestElasticNetOptionsValidate(t *testing.T) {
+	testData := map[string]struct {
+		opt *ElasticNetOptions
+		err error
+	}{
+		"nil":                 {nil, nil},
+		"negative lambda":     {&ElasticNetOptions{Lambda: -1}, ErrNegativeLambda},
+		"negative l1 ratio":   {&ElasticNetOptions{L1Ratio: -0.1}, ErrInvalidL1Ratio},
+		"l1 ratio above one":  {&ElasticNetOptions{L1Ratio: 1.1}, ErrInvalidL1Ratio},
+		"negative iterations": {&ElasticNetOptions{Iterations: -1}, ErrNegativeIterations},
+		"negative tolerance":  {&ElasticNetOptions{Tolerance: -1}, ErrNegativeTolerance},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			_, err := td.opt.Validate()
+			if td.err != nil {
+				assert.ErrorIs(t, err, td.err)
+				return
+			}
+			assert.Nil(t, err)
+		})
+	}
+}
+
+func TestElasticNetRegression(t *testing.T) {
+	tol := 1e-4
+	x := [][]float64{
+		{0, 0},
+		{3, 5},
+		{9, 20},
+		{12, 6},
+		{15, 10},
+	}
+	y := []float64{2, 31, 109, 62, 87}
+
+	testData := map[string]struct {
+		model     Model
+		intercept float64
+		coef      []float64
+	}{
+		"no regularization": {
+			model: &ElasticNetRegression{opt: &ElasticNetOptions{
+				L1Ratio:      0.5,
+				Iterations:   100000,
+				Tolerance:    1e-9,
+				FitIntercept: true,
+			}},
+			intercept: 2.0,
+			coef:      []float64{3.0, 4.0},
+		},
+		"auto selects least regularization": {
+			model: &ElasticNetAutoRegression{opt: &ElasticNetAutoOptions{
+				Lambdas:      []float64{10.0, 0.0},
+				L1Ratios:     []float64{0.2, 0.8},
+				Iterations:   100000,
+				Tolerance:    1e-9,
+				FitIntercept: true,
+			}},
+			intercept: 2.0,
+			coef:      []float64{3.0, 4.0},
+		},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			xMx, err := mat_.NewDenseFromArray(x)
+			require.Nil(t, err)
+			testModel(t, td.model, xMx, mat.NewDense(len(y), 1, y), td.intercept, td.coef, tol)
+		})
+	}
+}
+
+func TestElasticNetRegressionPenalties(t *testing.T) {
+	xMx, err := mat_.NewDenseFromArray([][]float64{{1}, {2}, {3}, {4}})
+	require.Nil(t, err)
+	y := mat.NewDense(4, 1, []float64{2, 4, 6, 8})
+
+	// without an intercept the solution is S(x'y, lambda*l1) / (x'x + lambda*(1-l1)) with x'y = 60 and
+	// x'x = 30
+	testData := map[string]struct {
+		lambda   float64
+		l1Ratio  float64
+		expected float64
+	}{
+		"ridge":        {lambda: 30, l1Ratio: 0, expected: 1.0},
+		"lasso":        {lambda: 30, l1Ratio: 1, expected: 1.0},
+		"mixed":        {lambda: 20, l1Ratio: 0.5, expected: 50.0 / 40.0},
+		"zero penalty": {lambda: 0, l1Ratio: 0.5, expected: 2.0},
+		"lasso zeroed": {lambda: 60, l1Ratio: 1, expected: 0.0},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			model, err := NewElasticNetRegression(&ElasticNetOptions{
+				Lambda:     td.lambda,
+				L1Ratio:    td.l1Ratio,
+				Iterations: DefaultIterations,
+				Tolerance:  DefaultTolerance,
+			})
+			require.Nil(t, err)
+			require.Nil(t, model.Fit(xMx, y))
+			assert.InDeltaSlice(t, []float64{td.expected}, model.Coef(), 1e-9)
+		})
+	}
+}
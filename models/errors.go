@@ -1,14 +1,14 @@
 package models
 
 import (
-	"errors"
+	"github.com/aouyang1/go-forecaster/errs"
 )
 
 var (
-	ErrNoOptions          = errors.New("no initialized model options")
-	ErrTargetLenMismatch  = errors.New("target length does not match target rows")
-	ErrNoTrainingMatrix   = errors.New("no training matrix")
-	ErrNoTargetMatrix     = errors.New("no target matrix")
-	ErrNoDesignMatrix     = errors.New("no design matrix for inference")
-	ErrFeatureLenMismatch = errors.New("number of features does not match number of model coefficients")
+	ErrNoOptions          = errs.NewConfigError(errs.CodeMissingOption, "no initialized model options", nil)
+	ErrTargetLenMismatch  = errs.NewDataError(errs.CodeLengthMismatch, "target length does not match target rows", nil)
+	ErrNoTrainingMatrix   = errs.NewDataError(errs.CodeNoData, "no training matrix", nil)
+	ErrNoTargetMatrix     = errs.NewDataError(errs.CodeNoData, "no target matrix", nil)
+	ErrNoDesignMatrix     = errs.NewDataError(errs.CodeNoData, "no design matrix for inference", nil)
+	ErrFeatureLenMismatch = errs.NewDataError(errs.CodeDimensionMismatch, "number of features does not match number of model coefficients", nil)
 )
@@ -0,0 +1,123 @@
+package models
+
+import (
+	"fmt"
+
+	mat_ "github.com/aouyang1/go-forecaster/mat"
+	"gonum.org/v1/gonum/mat"
+)
+
+// Gram accumulates the sufficient statistics of a least squares problem, X'X, X'y, y'y, and the
+// sum of y. Linear models can be fit from these statistics alone so training data can be streamed
+// in chunks without holding the full design matrix in memory.
+type Gram struct {
+	XTX  *mat.SymDense
+	XTy  []float64
+	YTy  float64
+	YSum float64
+	N    int
+}
+
+// NewGram initializes an empty set of sufficient statistics for n features
+func NewGram(n int) *Gram {
+	return &Gram{
+		XTX: mat.NewSymDense(n, nil),
+		XTy: make([]float64, n),
+	}
+}
+
+// Features returns the number of features tracked by the statistics
+func (g *Gram) Features() int {
+	return len(g.XTy)
+}
+
+// Add accumulates a chunk of observations where x has m rows of observations and n columns of
+// features, and y has m rows and a single column.
+func (g *Gram) Add(x, y mat.Matrix) error {
+	if x == nil {
+		return ErrNoTrainingMatrix
+	}
+	if y == nil {
+		return ErrNoTargetMatrix
+	}
+	m, n := x.Dims()
+	if n != g.Features() {
+		return fmt.Errorf("got %d features in chunk, but expected %d, %w", n, g.Features(), ErrFeatureLenMismatch)
+	}
+	ym, _ := y.Dims()
+	if ym != m {
+		return fmt.Errorf("chunk has %d rows and target has %d rows, %w", m, ym, ErrTargetLenMismatch)
+	}
+
+	var xtx mat.SymDense
+	xtx.SymOuterK(1.0, x.T())
+	g.XTX.AddSym(g.XTX, &xtx)
+
+	yArr := mat.Col(nil, 0, y)
+	var xty mat.VecDense
+	xty.MulVec(x.T(), mat.NewVecDense(m, yArr))
+	for i := 0; i < n; i++ {
+		g.XTy[i] += xty.AtVec(i)
+	}
+	for _, v := range yArr {
+		g.YTy += v * v
+		g.YSum += v
+	}
+	g.N += m
+	return nil
+}
+
+// newSparseGram computes the sufficient statistics of the sparse feature columns and target
+func newSparseGram(cols []mat_.SparseVector, y []float64) *Gram {
+	g := NewGram(len(cols))
+	for i, ci := range cols {
+		g.XTy[i] = ci.Dot(y)
+		for j := i; j < len(cols); j++ {
+			g.XTX.SetSym(i, j, ci.DotSparse(cols[j]))
+		}
+	}
+	for _, v := range y {
+		g.YTy += v * v
+		g.YSum += v
+	}
+	g.N = len(y)
+	return g
+}
+
+// Subset returns a new set of statistics restricted to the input feature indexes
+func (g *Gram) Subset(idx []int) *Gram {
+	sub := NewGram(len(idx))
+	for i, gi := range idx {
+		sub.XTy[i] = g.XTy[gi]
+		for j := i; j < len(idx); j++ {
+			sub.XTX.SetSym(i, j, g.XTX.At(gi, idx[j]))
+		}
+	}
+	sub.YTy = g.YTy
+	sub.YSum = g.YSum
+	sub.N = g.N
+	return sub
+}
+
+// RSquared computes the coefficient of determination of the input coefficients from the statistics.
+// A constant target returns 1.0.
+func (g *Gram) RSquared(coef []float64) float64 {
+	sse := g.SSE(coef)
+	sst := g.YTy - g.YSum*g.YSum/float64(g.N)
+	if sst <= 0 {
+		return 1.0
+	}
+	return 1.0 - sse/sst
+}
+
+// SSE computes the sum of squared errors of the input coefficients from the statistics
+func (g *Gram) SSE(coef []float64) float64 {
+	beta := mat.NewVecDense(len(coef), coef)
+	var xtxb mat.VecDense
+	xtxb.MulVec(g.XTX, beta)
+	sse := g.YTy - 2*mat.Dot(beta, mat.NewVecDense(len(g.XTy), g.XTy)) + mat.Dot(beta, &xtxb)
+	if sse < 0 {
+		sse = 0
+	}
+	return sse
+}
@@ -0,0 +1,78 @@
+package models
+
+import (
+	"testing"
+
+	mat_ "github.com/aouyang1/go-forecaster/mat"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestGramFit(t *testing.T) {
+	// y = 2 + 3*x0 + 4*x1
+	x := [][]float64{
+		{1, 0, 0},
+		{1, 3, 5},
+		{1, 9, 20},
+		{1, 12, 6},
+		{1, 15, 10},
+	}
+	y := []float64{2, 31, 109, 62, 87}
+
+	testData := map[string]struct {
+		chunkSize int
+		lambda    float64
+	}{
+		"single chunk":        {5, 0.0},
+		"multiple chunks":     {2, 0.0},
+		"regularized chunked": {2, 10.0},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			g := NewGram(3)
+			for i := 0; i < len(x); i += td.chunkSize {
+				end := min(i+td.chunkSize, len(x))
+				xChunk, err := mat_.NewDenseFromArray(x[i:end])
+				require.Nil(t, err)
+				err = g.Add(xChunk, mat.NewDense(end-i, 1, y[i:end]))
+				require.Nil(t, err)
+			}
+			assert.Equal(t, len(x), g.N)
+
+			opt := NewDefaultLassoOptions()
+			opt.Lambda = td.lambda
+			opt.Tolerance = 1e-6
+			opt.FitIntercept = false
+
+			gramModel, err := NewLassoRegression(opt)
+			require.Nil(t, err)
+			require.Nil(t, gramModel.FitGram(g))
+
+			xMx, err := mat_.NewDenseFromArray(x)
+			require.Nil(t, err)
+			model, err := NewLassoRegression(opt)
+			require.Nil(t, err)
+			require.Nil(t, model.Fit(xMx, mat.NewDense(len(y), 1, y)))
+
+			assert.InDeltaSlice(t, model.Coef(), gramModel.Coef(), 1e-3)
+
+			score, err := model.Score(xMx, mat.NewDense(len(y), 1, y))
+			require.Nil(t, err)
+			assert.InDelta(t, score, g.RSquared(gramModel.Coef()), 1e-3)
+		})
+	}
+}
+
+func TestGramAddErrors(t *testing.T) {
+	g := NewGram(2)
+	x := mat.NewDense(2, 3, nil)
+	y := mat.NewDense(2, 1, nil)
+	assert.ErrorIs(t, g.Add(x, y), ErrFeatureLenMismatch)
+
+	x = mat.NewDense(2, 2, nil)
+	y = mat.NewDense(3, 1, nil)
+	assert.ErrorIs(t, g.Add(x, y), ErrTargetLenMismatch)
+}
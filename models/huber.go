@@ -0,0 +1,235 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/aouyang1/go-forecaster/errs"
+	"gonum.org/v1/gonum/mat"
+)
+
+const (
+	// DefaultHuberDelta is the threshold in robust standard deviations of the residual beyond which
+	// observations are downweighted giving 95% efficiency for normally distributed noise
+	DefaultHuberDelta = 1.345
+
+	// madToStd scales the median absolute deviation to the standard deviation of normal noise
+	madToStd = 1.4826
+)
+
+var (
+	ErrInvalidHuberDelta = errs.NewConfigError(errs.CodeInvalidOption, "huber delta must be positive", nil)
+	ErrHuberSolve        = errs.NewFitError(errs.CodeFitFailed, "unable to solve weighted least squares for huber regression", nil)
+)
+
+// HuberOptions represents input options to run the linear huber regression
+type HuberOptions struct {
+	// Delta is the residual threshold in robust standard deviations between the squared and absolute
+	// loss. Defaults to DefaultHuberDelta if unset.
+	Delta float64
+
+	// Iterations is the maximum number of reweighted least squares solves.
+	Iterations int
+
+	// Tolerance is the largest coefficient change on an iteration to determine when to stop iterating.
+	Tolerance float64
+
+	// FitIntercept adds a constant 1.0 feature as the first column if set to true
+	FitIntercept bool
+}
+
+// Validate runs basic validation on huber regression options
+func (h *HuberOptions) Validate() (*HuberOptions, error) {
+	if h == nil {
+		h = NewDefaultHuberOptions()
+	}
+
+	if h.Delta < 0 {
+		return nil, fmt.Errorf("delta of %.3f, %w", h.Delta, ErrInvalidHuberDelta)
+	}
+	if h.Delta == 0 {
+		h.Delta = DefaultHuberDelta
+	}
+	if h.Iterations < 0 {
+		return nil, ErrNegativeIterations
+	}
+	if h.Tolerance < 0 {
+		return nil, ErrNegativeTolerance
+	}
+	return h, nil
+}
+
+// NewDefaultHuberOptions returns a default set of huber regression options
+func NewDefaultHuberOptions() *HuberOptions {
+	return &HuberOptions{
+		Delta:        DefaultHuberDelta,
+		Iterations:   DefaultIterations,
+		Tolerance:    DefaultTolerance,
+		FitIntercept: true,
+	}
+}
+
+// HuberRegression fits a linear model minimizing the huber loss using iteratively reweighted least
+// squares. The loss is squared for residuals within Delta robust standard deviations and absolute
+// beyond, so heavy tailed noise and outliers are downweighted instead of discarded. The scale of the
+// residuals is re-estimated on every iteration from their median absolute deviation, starting from
+// the ordinary least squares fit.
+type HuberRegression struct {
+	opt *HuberOptions
+
+	coef      []float64
+	intercept float64
+	weights   []float64
+}
+
+// NewHuberRegression initializes a huber regression model ready for fitting
+func NewHuberRegression(opt *HuberOptions) (*HuberRegression, error) {
+	opt, err := opt.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return &HuberRegression{
+		opt: opt,
+	}, nil
+}
+
+// Fit the model according to the given training data
+func (h *HuberRegression) Fit(x, y mat.Matrix) error {
+	if h.opt == nil {
+		return ErrNoOptions
+	}
+	if x == nil {
+		return ErrNoTrainingMatrix
+	}
+	if y == nil {
+		return ErrNoTargetMatrix
+	}
+	m, _ := x.Dims()
+	ym, _ := y.Dims()
+	if ym != m {
+		return fmt.Errorf("training data has %d rows and target has %d row, %w", m, ym, ErrTargetLenMismatch)
+	}
+
+	cols := designColumns(x, h.opt.FitIntercept)
+	yArr := mat.Col(nil, 0, y)
+	n := len(cols)
+
+	weights := make([]float64, m)
+	for i := range weights {
+		weights[i] = 1.0
+	}
+	beta := make([]float64, n)
+	pred := make([]float64, m)
+	absRes := make([]float64, m)
+	gram := mat.NewSymDense(n, nil)
+	rhs := mat.NewVecDense(n, nil)
+
+	for iter := 0; iter <= h.opt.Iterations; iter++ {
+		// weighted normal equations of the current weights
+		for j := 0; j < n; j++ {
+			var b float64
+			for i, v := range cols[j] {
+				b += weights[i] * v * yArr[i]
+			}
+			rhs.SetVec(j, b)
+			for k := j; k < n; k++ {
+				var g float64
+				for i, v := range cols[j] {
+					g += weights[i] * v * cols[k][i]
+				}
+				gram.SetSym(j, k, g)
+			}
+		}
+		var sol mat.VecDense
+		if err := sol.SolveVec(gram, rhs); err != nil {
+			return fmt.Errorf("iteration %d, %w", iter, ErrHuberSolve)
+		}
+
+		var maxChange float64
+		for j := 0; j < n; j++ {
+			maxChange = math.Max(maxChange, math.Abs(sol.AtVec(j)-beta[j]))
+			beta[j] = sol.AtVec(j)
+		}
+		if iter > 0 && maxChange < h.opt.Tolerance {
+			break
+		}
+
+		for i := range pred {
+			pred[i] = 0
+			for j := 0; j < n; j++ {
+				pred[i] += beta[j] * cols[j][i]
+			}
+			absRes[i] = math.Abs(yArr[i] - pred[i])
+		}
+
+		// residuals within the threshold keep a unit weight and the rest are weighted by the threshold
+		// over their absolute residual
+		threshold := h.opt.Delta * madToStd * median(absRes)
+		if threshold == 0 {
+			// more than half of the observations are fit exactly
+			break
+		}
+		for i, r := range absRes {
+			weights[i] = 1.0
+			if r > threshold {
+				weights[i] = threshold / r
+			}
+		}
+	}
+
+	h.intercept, h.coef = splitBeta(beta, h.opt.FitIntercept)
+	h.weights = weights
+	return nil
+}
+
+// median returns the median of the values without modifying them
+func median(vals []float64) float64 {
+	if len(vals) == 0 {
+		return 0
+	}
+	sorted := make([]float64, len(vals))
+	copy(sorted, vals)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 1 {
+		return sorted[mid]
+	}
+	return (sorted[mid-1] + sorted[mid]) / 2
+}
+
+// Predict using the huber regression model
+func (h *HuberRegression) Predict(x mat.Matrix) ([]float64, error) {
+	if h.opt == nil {
+		return nil, ErrNoOptions
+	}
+	return predictLinear(x, h.intercept, h.coef)
+}
+
+// Score computes the coefficient of determination of the prediction
+func (h *HuberRegression) Score(x, y mat.Matrix) (float64, error) {
+	if h.opt == nil {
+		return 0.0, ErrNoOptions
+	}
+	return scoreLinear(h, x, y)
+}
+
+// Intercept returns the computed intercept if FitIntercept is set to true. Defaults to 0.0 if not set.
+func (h *HuberRegression) Intercept() float64 {
+	return h.intercept
+}
+
+// Coef returns a slice of the trained coefficients in the same order of the training feature Matrix by column.
+func (h *HuberRegression) Coef() []float64 {
+	c := make([]float64, len(h.coef))
+	copy(c, h.coef)
+	return c
+}
+
+// Weights returns the final weight of every training observation where downweighted outliers have a
+// weight less than one
+func (h *HuberRegression) Weights() []float64 {
+	w := make([]float64, len(h.weights))
+	copy(w, h.weights)
+	return w
+}
@@ -0,0 +1,149 @@
+package models
+
+import (
+	"math/rand"
+	"testing"
+
+	mat_ "github.com/aouyang1/go-forecaster/mat"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestHuberOptionsValidate(t *testing.T) {
+	testData := map[string]struct {
+		opt      *HuberOptions
+		err      error
+		expected *HuberOptions
+	}{
+		"nil": {nil, nil, NewDefaultHuberOptions()},
+		"default delta": {
+			&HuberOptions{Iterations: 10},
+			nil,
+			&HuberOptions{Delta: DefaultHuberDelta, Iterations: 10},
+		},
+		"negative delta":      {&HuberOptions{Delta: -1}, ErrInvalidHuberDelta, nil},
+		"negative iterations": {&HuberOptions{Iterations: -1}, ErrNegativeIterations, nil},
+		"negative tolerance":  {&HuberOptions{Tolerance: -1}, ErrNegativeTolerance, nil},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			opt, err := td.opt.Validate()
+			if td.err != nil {
+				assert.ErrorIs(t, err, td.err)
+				return
+			}
+			require.Nil(t, err)
+			assert.Equal(t, td.expected, opt)
+		})
+	}
+}
+
+func TestHuberRegression(t *testing.T) {
+	tol := 1e-5
+	testData := map[string]struct {
+		x         [][]float64
+		y         []float64
+		opt       *HuberOptions
+		intercept float64
+		coef      []float64
+	}{
+		"exact fit with intercept": {
+			x: [][]float64{
+				{0, 0},
+				{3, 5},
+				{9, 20},
+				{12, 6},
+				{15, 10},
+			},
+			y:         []float64{2, 31, 109, 62, 87},
+			intercept: 2.0,
+			coef:      []float64{3.0, 4.0},
+		},
+		"exact fit no intercept": {
+			x: [][]float64{
+				{1, 0, 0},
+				{1, 3, 5},
+				{1, 9, 20},
+				{1, 12, 6},
+				{1, 15, 10},
+			},
+			y: []float64{2, 31, 109, 62, 87},
+			opt: &HuberOptions{
+				Iterations: DefaultIterations,
+				Tolerance:  DefaultTolerance,
+			},
+			intercept: 0.0,
+			coef:      []float64{2.0, 3.0, 4.0},
+		},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			x, err := mat_.NewDenseFromArray(td.x)
+			require.Nil(t, err)
+
+			y := mat.NewDense(len(td.y), 1, td.y)
+
+			model, err := NewHuberRegression(td.opt)
+			require.Nil(t, err)
+
+			testModel(t, model, x, y, td.intercept, td.coef, tol)
+		})
+	}
+}
+
+func TestHuberRegressionOutliers(t *testing.T) {
+	// gaussian noise with a tenth of the observations shifted far above the line
+	n := 1000
+	r := rand.New(rand.NewSource(1))
+	x := mat.NewDense(n, 1, nil)
+	yArr := make([]float64, n)
+	for i := 0; i < n; i++ {
+		xi := 10.0 * float64(i) / float64(n)
+		x.Set(i, 0, xi)
+		yArr[i] = 1.0 + 2.0*xi + 0.5*r.NormFloat64()
+		if r.Float64() < 0.1 {
+			yArr[i] += 50.0
+		}
+	}
+	y := mat.NewDense(n, 1, yArr)
+
+	ols, err := NewOLSRegression(nil)
+	require.Nil(t, err)
+	require.Nil(t, ols.Fit(x, y))
+
+	model, err := NewHuberRegression(nil)
+	require.Nil(t, err)
+	require.Nil(t, model.Fit(x, y))
+
+	assert.Greater(t, ols.Intercept(), 4.0)
+	assert.InDelta(t, 1.0, model.Intercept(), 1.0)
+	assert.InDelta(t, 2.0, model.Coef()[0], 0.1)
+
+	// outliers are downweighted but never discarded
+	weights := model.Weights()
+	require.Len(t, weights, n)
+	for i, w := range weights {
+		assert.Greater(t, w, 0.0)
+		if yArr[i]-(1.0+2.0*x.At(i, 0)) > 25 {
+			assert.Less(t, w, 0.1)
+		}
+	}
+}
+
+func TestHuberRegressionConstantTarget(t *testing.T) {
+	x := mat.NewDense(4, 1, []float64{0, 1, 2, 3})
+	y := mat.NewDense(4, 1, []float64{5, 5, 5, 5})
+
+	model, err := NewHuberRegression(nil)
+	require.Nil(t, err)
+	require.Nil(t, model.Fit(x, y))
+
+	// a perfect fit of a constant target has no variance to explain
+	score, err := model.Score(x, y)
+	require.Nil(t, err)
+	assert.Equal(t, 1.0, score)
+}
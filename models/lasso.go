@@ -1,12 +1,15 @@
 package models
 
 import (
-	"errors"
+	"context"
 	"fmt"
 	"log/slog"
 	"math"
+	"sort"
 	"sync"
 
+	"github.com/aouyang1/go-forecaster/errs"
+	mat_ "github.com/aouyang1/go-forecaster/mat"
 	"gonum.org/v1/gonum/floats"
 	"gonum.org/v1/gonum/mat"
 	"gonum.org/v1/gonum/stat"
@@ -19,11 +22,13 @@ const (
 )
 
 var (
-	ErrNegativeLambda     = errors.New("negative lambda")
-	ErrNegativeIterations = errors.New("negative iterations")
-	ErrNegativeTolerance  = errors.New("negative tolerance")
-	ErrWarmStartBetaSize  = errors.New("warm start beta does not have the same number of coefficients as training features")
-	ErrNoLambdas          = errors.New("no lambdas provided to fit with")
+	ErrNegativeLambda     = errs.NewConfigError(errs.CodeInvalidOption, "negative lambda", nil)
+	ErrNegativeIterations = errs.NewConfigError(errs.CodeInvalidOption, "negative iterations", nil)
+	ErrNegativeTolerance  = errs.NewConfigError(errs.CodeInvalidOption, "negative tolerance", nil)
+	ErrWarmStartBetaSize  = errs.NewConfigError(errs.CodeInvalidOption, "warm start beta does not have the same number of coefficients as training features", nil)
+	ErrNoLambdas          = errs.NewConfigError(errs.CodeMissingOption, "no lambdas provided to fit with", nil)
+	ErrPenaltyWeightsSize = errs.NewConfigError(errs.CodeInvalidOption, "penalty weights do not have the same number of coefficients as training features", nil)
+	ErrNegativeWeight     = errs.NewConfigError(errs.CodeInvalidOption, "negative penalty weight", nil)
 )
 
 // LassoOptions represents input options to run the Lasso Regression
@@ -44,6 +49,41 @@ type LassoOptions struct {
 
 	// FitIntercept adds a constant 1.0 feature as the first column if set to true
 	FitIntercept bool
+
+	// PenaltyWeights optionally scales the L1 penalty of each coefficient including the intercept if
+	// FitIntercept is set. Every coefficient has a weight of 1.0 if unset.
+	PenaltyWeights []float64
+
+	// OnIteration is called after every coordinate descent iteration if set
+	OnIteration func(IterationEvent)
+}
+
+// IterationEvent reports a completed coordinate descent iteration of the fit of a lambda. Iteration
+// starts at 1. MaxUpdate is the largest absolute change of a coefficient in the iteration and MaxCoef is
+// the largest coefficient whose product with the tolerance bounds MaxUpdate at convergence.
+type IterationEvent struct {
+	Lambda    float64
+	Iteration int
+	MaxUpdate float64
+	MaxCoef   float64
+}
+
+// LambdaEvent reports the completed fit of a lambda with the number of coordinate descent iterations
+// run, whether the fit converged within the maximum iterations, and its score used to select the best
+// lambda
+type LambdaEvent struct {
+	Lambda     float64
+	Iterations int
+	Converged  bool
+	Score      float64
+}
+
+// penalty returns the L1 penalty of the j-th coefficient
+func (l *LassoOptions) penalty(j int) float64 {
+	if l.PenaltyWeights == nil {
+		return l.Lambda
+	}
+	return l.Lambda * l.PenaltyWeights[j]
 }
 
 // Validate runs basic validation on Lasso options
@@ -61,6 +101,11 @@ func (l *LassoOptions) Validate() (*LassoOptions, error) {
 	if l.Tolerance < 0 {
 		return nil, ErrNegativeTolerance
 	}
+	for _, w := range l.PenaltyWeights {
+		if w < 0 {
+			return nil, ErrNegativeWeight
+		}
+	}
 	return l, nil
 }
 
@@ -79,14 +124,20 @@ func NewDefaultLassoOptions() *LassoOptions {
 type LassoRegression struct {
 	opt *LassoOptions
 
-	// serve as precomputed data structures to reduce memory allocations
-	xcols [][]float64
-	xdot  []float64
-	gamma []float64
-	yArr  []float64
+	// serve as precomputed data structures to reduce memory allocations. spcols replaces xcols if
+	// fitting a sparse design matrix.
+	xcols  [][]float64
+	spcols []mat_.SparseVector
+	xdot   []float64
+	gamma  []float64
+	yArr   []float64
 
 	coef      []float64
 	intercept float64
+
+	// iterations and convergence of the last fit
+	iterations int
+	converged  bool
 }
 
 // NewLassoRegression initializes a Lasso model ready for fitting
@@ -102,6 +153,12 @@ func NewLassoRegression(opt *LassoOptions) (*LassoRegression, error) {
 
 // Fit the model according to the given training data
 func (l *LassoRegression) Fit(x, y mat.Matrix) error {
+	return l.FitCtx(context.Background(), x, y)
+}
+
+// FitCtx fits the model according to the given training data returning the context error if the
+// context is done before the coordinate descent converges
+func (l *LassoRegression) FitCtx(ctx context.Context, x, y mat.Matrix) error {
 	if l.opt == nil {
 		return ErrNoOptions
 	}
@@ -120,20 +177,16 @@ func (l *LassoRegression) Fit(x, y mat.Matrix) error {
 	}
 
 	if l.opt.FitIntercept {
-		ones := make([]float64, m)
-		floats.AddConst(1.0, ones)
-		onesMx := mat.NewDense(1, m, ones)
-		xT := x.T()
-
-		var xWithOnes mat.Dense
-		xWithOnes.Stack(onesMx, xT)
-		x = xWithOnes.T()
+		x = withIntercept(x)
 		_, n = x.Dims()
 	}
 
 	if l.opt.WarmStartBeta != nil && len(l.opt.WarmStartBeta) != n {
 		return fmt.Errorf("warm start beta has %d features instead of %d, %w", len(l.opt.WarmStartBeta), n, ErrWarmStartBetaSize)
 	}
+	if l.opt.PenaltyWeights != nil && len(l.opt.PenaltyWeights) != n {
+		return fmt.Errorf("penalty weights have %d features instead of %d, %w", len(l.opt.PenaltyWeights), n, ErrPenaltyWeightsSize)
+	}
 
 	// tracks current betas
 	beta := make([]float64, n)
@@ -143,6 +196,21 @@ func (l *LassoRegression) Fit(x, y mat.Matrix) error {
 
 	// precompute data structures if not previously populated. This is generally only done
 	// by the auto lasso regression
+	csr, sparse := x.(*mat_.CSR)
+	if sparse && len(l.xdot) == 0 && len(l.spcols) == 0 && len(l.gamma) == 0 && len(l.yArr) == 0 {
+		l.spcols = csr.Columns()
+		l.xdot = make([]float64, n)
+		l.gamma = make([]float64, n)
+		for i, col := range l.spcols {
+			l.xdot[i] = col.DotSparse(col)
+			l.gamma[i] = l.opt.penalty(i) / l.xdot[i]
+		}
+		l.yArr = mat.Col(nil, 0, y)
+	}
+	if l.spcols != nil {
+		return l.fitSparse(ctx, beta)
+	}
+
 	if len(l.xdot) == 0 && len(l.xcols) == 0 && len(l.gamma) == 0 && len(l.yArr) == 0 {
 		l.xcols = make([][]float64, n)
 		for i := 0; i < n; i++ {
@@ -159,7 +227,7 @@ func (l *LassoRegression) Fit(x, y mat.Matrix) error {
 			}
 			l.xcols[i] = xi
 			l.xdot[i] = floats.Dot(xi, xi)
-			l.gamma[i] = l.opt.Lambda / l.xdot[i]
+			l.gamma[i] = l.opt.penalty(i) / l.xdot[i]
 		}
 
 		l.yArr = mat.Col(nil, 0, y)
@@ -171,15 +239,25 @@ func (l *LassoRegression) Fit(x, y mat.Matrix) error {
 	// tracks the per coordinate residual
 	residual := make([]float64, m)
 
-	// tracks the current beta * x by adding the deltas on each beta iteration
+	// tracks the current beta * x by adding the deltas on each beta iteration starting from the warm
+	// start beta if any
 	betaX := make([]float64, m)
+	for j, b := range beta {
+		if b != 0 {
+			floats.AddScaled(betaX, b, l.xcols[j])
+		}
+	}
 
 	// tracks the delta of the beta * x of each iteration by computing the next beta
 	// multiplied by the feature observations of that beta. will be added to betaX on
 	// the next beta iteration
 	betaXDelta := make([]float64, m)
 
+	l.iterations, l.converged = 0, false
 	for i := 0; i < l.opt.Iterations; i++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		maxCoef := 0.0
 		maxUpdate := 0.0
 		betaDiff := 0.0
@@ -207,22 +285,152 @@ func (l *LassoRegression) Fit(x, y mat.Matrix) error {
 			beta[j] = betaNext
 		}
 
-		// break early if we've achieved the desired tolerance
-		if maxUpdate < l.opt.Tolerance*maxCoef {
+		if l.iterationDone(i, maxUpdate, maxCoef) {
 			break
 		}
 	}
 
+	l.setCoef(beta)
+	return nil
+}
+
+// fitSparse runs coordinate descent over the sparse columns of the design matrix. The residual is
+// updated in place by the change of each coefficient so every coordinate costs the number of non zero
+// observations of its feature.
+func (l *LassoRegression) fitSparse(ctx context.Context, beta []float64) error {
+	residual := make([]float64, len(l.yArr))
+	copy(residual, l.yArr)
+	for j, b := range beta {
+		if b != 0 {
+			l.spcols[j].AddScaledTo(residual, -b)
+		}
+	}
+
+	l.iterations, l.converged = 0, false
+	for i := 0; i < l.opt.Iterations; i++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		maxCoef := 0.0
+		maxUpdate := 0.0
+
+		for j, col := range l.spcols {
+			betaCurr := beta[j]
+			if (i != 0 && betaCurr == 0) || l.xdot[j] == 0 {
+				continue
+			}
+
+			betaNext := SoftThreshold(col.Dot(residual)/l.xdot[j]+betaCurr, l.gamma[j])
+
+			maxCoef = math.Max(maxCoef, betaNext)
+			maxUpdate = math.Max(maxUpdate, math.Abs(betaNext-betaCurr))
+			if betaNext != betaCurr {
+				col.AddScaledTo(residual, betaCurr-betaNext)
+			}
+			beta[j] = betaNext
+		}
+
+		if l.iterationDone(i, maxUpdate, maxCoef) {
+			break
+		}
+	}
+
+	l.setCoef(beta)
+	return nil
+}
+
+// setCoef splits the fit coefficients into the intercept and coefficients if fitting the intercept
+func (l *LassoRegression) setCoef(beta []float64) {
 	if l.opt.FitIntercept {
 		l.intercept = beta[0]
 		l.coef = beta[1:]
 	} else {
 		l.coef = beta
 	}
+}
 
+// FitGram fits the model from precomputed sufficient statistics using covariance update coordinate
+// descent. This makes each iteration independent of the number of observations. The intercept is
+// not added automatically, so if FitIntercept is set the first accumulated feature is expected to
+// be the constant 1.0 column.
+func (l *LassoRegression) FitGram(g *Gram) error {
+	if l.opt == nil {
+		return ErrNoOptions
+	}
+	if g == nil || g.N == 0 {
+		return ErrNoTrainingMatrix
+	}
+	n := g.Features()
+
+	if l.opt.WarmStartBeta != nil && len(l.opt.WarmStartBeta) != n {
+		return fmt.Errorf("warm start beta has %d features instead of %d, %w", len(l.opt.WarmStartBeta), n, ErrWarmStartBetaSize)
+	}
+	if l.opt.PenaltyWeights != nil && len(l.opt.PenaltyWeights) != n {
+		return fmt.Errorf("penalty weights have %d features instead of %d, %w", len(l.opt.PenaltyWeights), n, ErrPenaltyWeightsSize)
+	}
+
+	beta := make([]float64, n)
+	if l.opt.WarmStartBeta != nil {
+		copy(beta, l.opt.WarmStartBeta)
+	}
+
+	l.iterations, l.converged = 0, false
+	for i := 0; i < l.opt.Iterations; i++ {
+		maxCoef := 0.0
+		maxUpdate := 0.0
+
+		for j := 0; j < n; j++ {
+			betaCurr := beta[j]
+			if i != 0 && betaCurr == 0 {
+				continue
+			}
+			xdot := g.XTX.At(j, j)
+			if xdot == 0 {
+				continue
+			}
+
+			// x_j . residual = x_j . y - sum_k (x_j . x_k) * beta_k
+			num := g.XTy[j]
+			for k := 0; k < n; k++ {
+				num -= g.XTX.At(j, k) * beta[k]
+			}
+			betaNext := SoftThreshold(num/xdot+betaCurr, l.opt.penalty(j)/xdot)
+
+			maxCoef = math.Max(maxCoef, betaNext)
+			maxUpdate = math.Max(maxUpdate, math.Abs(betaNext-betaCurr))
+			beta[j] = betaNext
+		}
+
+		if l.iterationDone(i, maxUpdate, maxCoef) {
+			break
+		}
+	}
+
+	if l.opt.FitIntercept {
+		l.intercept = beta[0]
+		l.coef = beta[1:]
+	} else {
+		l.coef = beta
+	}
 	return nil
 }
 
+// iterationDone records the completed iteration, reports it to the iteration callback, and returns true
+// if the fit has converged to the desired tolerance
+func (l *LassoRegression) iterationDone(i int, maxUpdate, maxCoef float64) bool {
+	l.iterations = i + 1
+	if l.opt.OnIteration != nil {
+		l.opt.OnIteration(IterationEvent{
+			Lambda:    l.opt.Lambda,
+			Iteration: l.iterations,
+			MaxUpdate: maxUpdate,
+			MaxCoef:   maxCoef,
+		})
+	}
+	l.converged = maxUpdate < l.opt.Tolerance*maxCoef
+	return l.converged
+}
+
 // Predict using the Lasso model
 func (l *LassoRegression) Predict(x mat.Matrix) ([]float64, error) {
 	if l.opt == nil {
@@ -235,16 +443,7 @@ func (l *LassoRegression) Predict(x mat.Matrix) ([]float64, error) {
 	coef := l.coef
 	if l.opt.FitIntercept {
 		coef = append([]float64{l.intercept}, l.coef...)
-
-		m, _ := x.Dims()
-		ones := make([]float64, m)
-		floats.AddConst(1.0, ones)
-		onesMx := mat.NewDense(1, m, ones)
-		xT := x.T()
-
-		var xWithOnes mat.Dense
-		xWithOnes.Stack(onesMx, xT)
-		x = xWithOnes.T()
+		x = withIntercept(x)
 	}
 	n := len(coef)
 
@@ -252,6 +451,9 @@ func (l *LassoRegression) Predict(x mat.Matrix) ([]float64, error) {
 	if xn != n {
 		return nil, fmt.Errorf("got %d features in design matrix, but expected %d, %w", xn, n, ErrFeatureLenMismatch)
 	}
+	if csr, ok := x.(*mat_.CSR); ok {
+		return csr.MulVec(coef)
+	}
 
 	xT := x.T()
 	coefMx := mat.NewDense(1, n, coef)
@@ -331,6 +533,64 @@ type LassoAutoOptions struct {
 
 	// Parallelization sets how many fits to run in parallel. More will increase memory and compute usage.
 	Parallelization int
+
+	// Adaptive runs the adaptive Lasso where each coefficient is penalized by the inverse magnitude of
+	// its coefficient from an initial ridge fit raised to AdaptiveGamma. AdaptiveRidge sets the relative
+	// ridge penalty of the initial fit. Both default if unset.
+	Adaptive      bool
+	AdaptiveGamma float64
+	AdaptiveRidge float64
+
+	// RetainPath keeps the coefficients fit for every lambda so the regularization path can be inspected
+	RetainPath bool
+
+	// PenaltyWeights optionally scales the L1 penalty of each coefficient including the intercept if
+	// FitIntercept is set. These are multiplied with the adaptive weights if running the adaptive Lasso.
+	PenaltyWeights []float64
+
+	// CVFolds selects the lambda with the best mean out of sample score over time series cross
+	// validation splits of the observations instead of the best in-sample score which always favors
+	// the smallest lambda. The selected lambda is then refit on every observation. CVScoring sets the
+	// metric of each split defaulting to the coefficient of determination. Cross validation is disabled
+	// if CVFolds is 0 and is not supported by FitGram.
+	CVFolds   int
+	CVScoring CVScoring
+
+	// WarmStartBeta primes the coordinate descent of every lambda to reduce the training time if a
+	// previous fit on similar data is available. This includes the intercept first if FitIntercept is
+	// set. The cross validation splits always start from zero.
+	WarmStartBeta []float64
+
+	// OnIteration is called after every coordinate descent iteration of every lambda and OnLambda after
+	// the fit of every lambda if set. Lambdas fit in parallel call these concurrently. The cross
+	// validation splits are not reported.
+	OnIteration func(IterationEvent)
+	OnLambda    func(LambdaEvent)
+}
+
+// warmStartBeta validates the warm start coefficients against the n coefficients of the fit
+func (l *LassoAutoOptions) warmStartBeta(n int) ([]float64, error) {
+	if l.WarmStartBeta != nil && len(l.WarmStartBeta) != n {
+		return nil, fmt.Errorf("warm start beta has %d features instead of %d, %w", len(l.WarmStartBeta), n, ErrWarmStartBetaSize)
+	}
+	return l.WarmStartBeta, nil
+}
+
+// penaltyWeights returns the L1 penalty weights of each of the n coefficients combining the configured
+// penalty weights with the adaptive weights. Returns nil if every coefficient has a weight of 1.0.
+func (l *LassoAutoOptions) penaltyWeights(adaptive []float64, n int) ([]float64, error) {
+	if l.PenaltyWeights == nil {
+		return adaptive, nil
+	}
+	if len(l.PenaltyWeights) != n {
+		return nil, fmt.Errorf("penalty weights have %d features instead of %d, %w", len(l.PenaltyWeights), n, ErrPenaltyWeightsSize)
+	}
+	weights := make([]float64, n)
+	copy(weights, l.PenaltyWeights)
+	if adaptive != nil {
+		floats.Mul(weights, adaptive)
+	}
+	return weights, nil
 }
 
 // Validate runs basic validation on Lasso Auto options
@@ -355,6 +615,17 @@ func (l *LassoAutoOptions) Validate() (*LassoAutoOptions, error) {
 	if l.Tolerance < 0 {
 		return nil, ErrNegativeTolerance
 	}
+	for _, w := range l.PenaltyWeights {
+		if w < 0 {
+			return nil, ErrNegativeWeight
+		}
+	}
+	if l.CVFolds < 0 {
+		return nil, ErrNegativeFolds
+	}
+	if err := l.CVScoring.Validate(); err != nil {
+		return nil, err
+	}
 	if l.Parallelization == 0 || l.Parallelization > len(l.Lambdas) {
 		l.Parallelization = len(l.Lambdas)
 	}
@@ -378,6 +649,57 @@ type LassoAutoRegression struct {
 	opt *LassoAutoOptions
 
 	bestModel *LassoRegression
+	path      []PathPoint
+}
+
+// PathPoint is the fit of a single lambda of the regularization path. Coef is in the same order as Coef
+// of the auto regression. Score is the mean cross validation score if cross validating.
+type PathPoint struct {
+	Lambda float64
+	Coef   []float64
+	Score  float64
+}
+
+// retain records the fit of a lambda if the path is retained. Callers must hold the score lock.
+func (l *LassoAutoRegression) retain(lambda float64, reg *LassoRegression, score float64) {
+	if !l.opt.RetainPath {
+		return
+	}
+	coef := reg.Coef()
+	if l.opt.FitIntercept {
+		coef = coef[1:]
+	}
+	coefCopy := make([]float64, len(coef))
+	copy(coefCopy, coef)
+	l.path = append(l.path, PathPoint{
+		Lambda: lambda,
+		Coef:   coefCopy,
+		Score:  score,
+	})
+}
+
+// lambdaDone reports the completed fit of a lambda to the lambda callback if set
+func (l *LassoAutoRegression) lambdaDone(lambda float64, reg *LassoRegression, score float64) {
+	if l.opt.OnLambda == nil {
+		return
+	}
+	l.opt.OnLambda(LambdaEvent{
+		Lambda:     lambda,
+		Iterations: reg.iterations,
+		Converged:  reg.converged,
+		Score:      score,
+	})
+}
+
+// Path returns the fit of every lambda in ascending lambda order if RetainPath is set
+func (l *LassoAutoRegression) Path() []PathPoint {
+	if l == nil {
+		return nil
+	}
+	sort.Slice(l.path, func(i, j int) bool {
+		return l.path[i].Lambda < l.path[j].Lambda
+	})
+	return l.path
 }
 
 // NewLassoAutoRegression initializes a Lasso model ready for fitting using automated lambad parameter selection
@@ -394,9 +716,16 @@ func NewLassoAutoRegression(opt *LassoAutoOptions) (*LassoAutoRegression, error)
 
 // Fit the model according to the given training data
 func (l *LassoAutoRegression) Fit(x, y mat.Matrix) error {
+	return l.FitCtx(context.Background(), x, y)
+}
+
+// FitCtx fits the model according to the given training data. No more lambdas are fit once the context
+// is done and the context error is returned after the lambdas in progress stop.
+func (l *LassoAutoRegression) FitCtx(ctx context.Context, x, y mat.Matrix) error {
 	if l.opt == nil {
 		return ErrNoOptions
 	}
+	l.path = nil
 	if x == nil {
 		return ErrNoTrainingMatrix
 	}
@@ -412,57 +741,85 @@ func (l *LassoAutoRegression) Fit(x, y mat.Matrix) error {
 	}
 
 	if l.opt.FitIntercept {
-		ones := make([]float64, m)
-		floats.AddConst(1.0, ones)
-		onesMx := mat.NewDense(1, m, ones)
-		xT := x.T()
-
-		var xWithOnes mat.Dense
-		xWithOnes.Stack(onesMx, xT)
-		x = xWithOnes.T()
+		x = withIntercept(x)
 		_, n = x.Dims()
 	}
 
-	lassoOpts := make([]*LassoOptions, 0, len(l.opt.Lambdas))
-	for _, lambda := range l.opt.Lambdas {
-		singleOpt := &LassoOptions{
-			Lambda:       lambda,
-			Iterations:   l.opt.Iterations,
-			Tolerance:    l.opt.Tolerance,
-			FitIntercept: false, // taken care of ahead of time
+	// precompute the per feature columns and dot product shared by every lambda
+	var xcols [][]float64
+	var spcols []mat_.SparseVector
+	xdot := make([]float64, n)
+	if csr, ok := x.(*mat_.CSR); ok {
+		spcols = csr.Columns()
+		for i, col := range spcols {
+			xdot[i] = col.DotSparse(col)
+		}
+	} else {
+		xcols = make([][]float64, n)
+		for i := 0; i < n; i++ {
+			xi := mat.Col(nil, i, x)
+			if len(xi) < m {
+				xi = append(xi, make([]float64, m-len(xi))...)
+			}
+			xcols[i] = xi
+			xdot[i] = floats.Dot(xi, xi)
 		}
-
-		lassoOpts = append(lassoOpts, singleOpt)
 	}
 
-	xcols := make([][]float64, n)
-	for i := 0; i < n; i++ {
-		xcols[i] = make([]float64, m)
+	yArr := mat.Col(nil, 0, y)
+	if len(yArr) < m {
+		yArr = append(yArr, make([]float64, m-len(yArr))...)
 	}
 
-	// precompute the per feature dot product
-	xdot := make([]float64, n)
-	for i := 0; i < n; i++ {
-		xi := mat.Col(nil, i, x)
-		if len(xi) < m {
-			xi = append(xi, make([]float64, m-len(xi))...)
+	var weights []float64
+	if l.opt.Adaptive {
+		var g *Gram
+		if spcols != nil {
+			g = newSparseGram(spcols, yArr)
+		} else {
+			g = NewGram(n)
+			if err := g.Add(x, mat.NewDense(m, 1, yArr)); err != nil {
+				return err
+			}
+		}
+		var err error
+		weights, err = AdaptiveWeights(g, l.opt.AdaptiveRidge, l.opt.AdaptiveGamma)
+		if err != nil {
+			return err
 		}
-		xcols[i] = xi
-		xdot[i] = floats.Dot(xi, xi)
 	}
 
-	yArr := mat.Col(nil, 0, y)
-	if len(yArr) < m {
-		yArr = append(yArr, make([]float64, m-len(yArr))...)
+	weights, err := l.opt.penaltyWeights(weights, n)
+	if err != nil {
+		return err
+	}
+	warmStart, err := l.opt.warmStartBeta(n)
+	if err != nil {
+		return err
 	}
 
-	var bestScore float64
+	var splits []Split
+	var xd *mat.Dense
+	if l.opt.CVFolds > 0 {
+		splits, err = TimeSeriesCVSplit(m, l.opt.CVFolds)
+		if err != nil {
+			return err
+		}
+		xd = mat.DenseCopyOf(x)
+	}
+
+	bestScore := math.Inf(-1)
 	var scoreMu sync.Mutex
 
 	sem := make(chan struct{}, l.opt.Parallelization)
 	var wg sync.WaitGroup
+sweep:
 	for _, lambda := range l.opt.Lambdas {
-		sem <- struct{}{}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			break sweep
+		}
 		wg.Add(1)
 
 		go func(lambda float64, x, y mat.Matrix) {
@@ -472,15 +829,18 @@ func (l *LassoAutoRegression) Fit(x, y mat.Matrix) error {
 			}()
 
 			opt := &LassoOptions{
-				Lambda:       lambda,
-				Iterations:   l.opt.Iterations,
-				Tolerance:    l.opt.Tolerance,
-				FitIntercept: false, // taken care of ahead of time
+				Lambda:         lambda,
+				Iterations:     l.opt.Iterations,
+				Tolerance:      l.opt.Tolerance,
+				FitIntercept:   false, // taken care of ahead of time
+				PenaltyWeights: weights,
+				WarmStartBeta:  warmStart,
+				OnIteration:    l.opt.OnIteration,
 			}
 
 			gamma := make([]float64, n)
 			for i := 0; i < n; i++ {
-				gamma[i] = lambda / xdot[i]
+				gamma[i] = opt.penalty(i) / xdot[i]
 			}
 			reg, err := NewLassoRegression(opt)
 			if err != nil {
@@ -488,23 +848,36 @@ func (l *LassoAutoRegression) Fit(x, y mat.Matrix) error {
 				return
 			}
 			reg.xcols = xcols
+			reg.spcols = spcols
 			reg.xdot = xdot
 			reg.gamma = gamma
 			reg.yArr = yArr
 
-			if err := reg.Fit(x, y); err != nil {
-				slog.Error("unable to fit lasso regression", "error", err.Error())
+			if err := reg.FitCtx(ctx, x, y); err != nil {
+				if ctx.Err() == nil {
+					slog.Error("unable to fit lasso regression", "error", err.Error())
+				}
 				return
 			}
 
-			score, err := reg.Score(x, y)
+			var score float64
+			if splits != nil {
+				score, err = l.cvScore(ctx, opt, xd, yArr, splits)
+			} else {
+				score, err = reg.Score(x, y)
+			}
 			if err != nil {
-				slog.Error("unable to compute fit score for lasso regression", "error", err.Error())
+				if ctx.Err() == nil {
+					slog.Error("unable to compute fit score for lasso regression", "error", err.Error())
+				}
 				return
 			}
 
+			l.lambdaDone(lambda, reg, score)
+
 			scoreMu.Lock()
 			defer scoreMu.Unlock()
+			l.retain(lambda, reg, score)
 			if score > bestScore {
 				bestScore = score
 				l.bestModel = reg
@@ -514,6 +887,126 @@ func (l *LassoAutoRegression) Fit(x, y mat.Matrix) error {
 	}
 	wg.Wait()
 
+	return ctx.Err()
+}
+
+// cvScore returns the mean score of the lambda of the lasso options over the cross validation splits.
+// Splits with an undefined score such as a constant test target for the coefficient of determination
+// are skipped.
+func (l *LassoAutoRegression) cvScore(ctx context.Context, opt *LassoOptions, x *mat.Dense, y []float64, splits []Split) (float64, error) {
+	_, n := x.Dims()
+	var total float64
+	var numScored int
+	for _, split := range splits {
+		reg, err := NewLassoRegression(&LassoOptions{
+			Lambda:         opt.Lambda,
+			Iterations:     opt.Iterations,
+			Tolerance:      opt.Tolerance,
+			FitIntercept:   false,
+			PenaltyWeights: opt.PenaltyWeights,
+		})
+		if err != nil {
+			return 0.0, err
+		}
+		trainY := y[split.TrainStart:split.TrainEnd]
+		trainX := x.Slice(split.TrainStart, split.TrainEnd, 0, n)
+		if err := reg.FitCtx(ctx, trainX, mat.NewDense(len(trainY), 1, trainY)); err != nil {
+			return 0.0, err
+		}
+		pred, err := reg.Predict(x.Slice(split.TestStart, split.TestEnd, 0, n))
+		if err != nil {
+			return 0.0, err
+		}
+		score := l.opt.CVScoring.Score(y[split.TestStart:split.TestEnd], pred)
+		if math.IsNaN(score) || math.IsInf(score, 0) {
+			continue
+		}
+		total += score
+		numScored++
+	}
+	if numScored == 0 {
+		return math.Inf(-1), nil
+	}
+	return total / float64(numScored), nil
+}
+
+// FitGram fits a Lasso model per lambda from precomputed sufficient statistics and keeps the model
+// with the best in-sample coefficient of determination. The intercept is not added automatically, so
+// if FitIntercept is set the first accumulated feature is expected to be the constant 1.0 column.
+func (l *LassoAutoRegression) FitGram(g *Gram) error {
+	if l.opt == nil {
+		return ErrNoOptions
+	}
+	l.path = nil
+	if g == nil || g.N == 0 {
+		return ErrNoTrainingMatrix
+	}
+
+	var weights []float64
+	if l.opt.Adaptive {
+		var err error
+		weights, err = AdaptiveWeights(g, l.opt.AdaptiveRidge, l.opt.AdaptiveGamma)
+		if err != nil {
+			return err
+		}
+	}
+
+	weights, err := l.opt.penaltyWeights(weights, g.Features())
+	if err != nil {
+		return err
+	}
+	warmStart, err := l.opt.warmStartBeta(g.Features())
+	if err != nil {
+		return err
+	}
+
+	bestScore := math.Inf(-1)
+	var scoreMu sync.Mutex
+
+	sem := make(chan struct{}, l.opt.Parallelization)
+	var wg sync.WaitGroup
+	for _, lambda := range l.opt.Lambdas {
+		sem <- struct{}{}
+		wg.Add(1)
+
+		go func(lambda float64) {
+			defer func() {
+				wg.Done()
+				<-sem
+			}()
+
+			reg, err := NewLassoRegression(&LassoOptions{
+				Lambda:         lambda,
+				Iterations:     l.opt.Iterations,
+				Tolerance:      l.opt.Tolerance,
+				FitIntercept:   false, // intercept column is part of the statistics
+				PenaltyWeights: weights,
+				WarmStartBeta:  warmStart,
+				OnIteration:    l.opt.OnIteration,
+			})
+			if err != nil {
+				slog.Error("unable to initialize lasso regression", "error", err.Error())
+				return
+			}
+			if err := reg.FitGram(g); err != nil {
+				slog.Error("unable to fit lasso regression", "error", err.Error())
+				return
+			}
+			score := g.RSquared(reg.Coef())
+
+			l.lambdaDone(lambda, reg, score)
+
+			scoreMu.Lock()
+			defer scoreMu.Unlock()
+			l.retain(lambda, reg, score)
+			if score > bestScore {
+				bestScore = score
+				l.bestModel = reg
+			}
+		}(lambda)
+	}
+	wg.Wait()
+
 	return nil
 }
 
@@ -524,15 +1017,7 @@ func (l *LassoAutoRegression) Predict(x mat.Matrix) ([]float64, error) {
 	}
 
 	if l.opt.FitIntercept {
-		m, _ := x.Dims()
-		ones := make([]float64, m)
-		floats.AddConst(1.0, ones)
-		onesMx := mat.NewDense(1, m, ones)
-		xT := x.T()
-
-		var xWithOnes mat.Dense
-		xWithOnes.Stack(onesMx, xT)
-		x = xWithOnes.T()
+		x = withIntercept(x)
 	}
 
 	return l.bestModel.Predict(x)
@@ -545,15 +1030,7 @@ func (l *LassoAutoRegression) Score(x, y mat.Matrix) (float64, error) {
 	}
 
 	if l.opt.FitIntercept {
-		m, _ := x.Dims()
-		ones := make([]float64, m)
-		floats.AddConst(1.0, ones)
-		onesMx := mat.NewDense(1, m, ones)
-		xT := x.T()
-
-		var xWithOnes mat.Dense
-		xWithOnes.Stack(onesMx, xT)
-		x = xWithOnes.T()
+		x = withIntercept(x)
 	}
 
 	return l.bestModel.Score(x, y)
@@ -580,3 +1057,11 @@ func (l *LassoAutoRegression) Coef() []float64 {
 	}
 	return l.bestModel.Coef()
 }
+
+// Lambda returns the regularization lambda of the best fit. Defaults to 0.0 if not fit.
+func (l *LassoAutoRegression) Lambda() float64 {
+	if l == nil || l.bestModel == nil {
+		return 0.0
+	}
+	return l.bestModel.opt.Lambda
+}
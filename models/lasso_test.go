@@ -1,7 +1,11 @@
 package models
 
 import (
+	"context"
+	"math/rand"
+	"sync"
 	"testing"
+	"time"
 
 	mat_ "github.com/aouyang1/go-forecaster/mat"
 
@@ -124,6 +128,25 @@ func TestLassoRegression(t *testing.T) {
 			intercept: 0.0,
 			coef:      []float64{3.0},
 		},
+		"model warm start": {
+			x: [][]float64{
+				{0, 0},
+				{3, 5},
+				{9, 20},
+				{12, 6},
+				{15, 10},
+			},
+			y: []float64{2, 31, 109, 62, 87},
+			opt: func() *LassoOptions {
+				opt := NewDefaultLassoOptions()
+				opt.Lambda = lambda
+				opt.Tolerance = desTol
+				opt.WarmStartBeta = []float64{1.5, 2.5, 4.5}
+				return opt
+			}(),
+			intercept: 2.0,
+			coef:      []float64{3.0, 4.0},
+		},
 	}
 
 	for name, td := range testData {
@@ -195,6 +218,47 @@ func TestLassoAutoRegression(t *testing.T) {
 			intercept: 0.0,
 			coef:      []float64{2.0, 3.0, 4.0},
 		},
+		"auto model adaptive": {
+			x: [][]float64{
+				{0, 0},
+				{3, 5},
+				{9, 20},
+				{12, 6},
+				{15, 10},
+			},
+			y: []float64{2, 31, 109, 62, 87},
+			opt: func() *LassoAutoOptions {
+				opt := NewDefaultLassoAutoOptions()
+				opt.Lambdas = lambdas
+				opt.Tolerance = desTol
+				opt.FitIntercept = true
+				opt.Parallelization = parallelization
+				opt.Adaptive = true
+				return opt
+			}(),
+			intercept: 2.0,
+			coef:      []float64{3.0, 4.0},
+		},
+		"auto model unpenalized": {
+			x: [][]float64{
+				{0, 0},
+				{3, 5},
+				{9, 20},
+				{12, 6},
+				{15, 10},
+			},
+			y: []float64{2, 31, 109, 62, 87},
+			opt: func() *LassoAutoOptions {
+				opt := NewDefaultLassoAutoOptions()
+				opt.Lambdas = []float64{10000.0}
+				opt.Tolerance = desTol
+				opt.FitIntercept = true
+				opt.PenaltyWeights = []float64{0, 0, 0}
+				return opt
+			}(),
+			intercept: 2.0,
+			coef:      []float64{3.0, 4.0},
+		},
 		"auto model constant": {
 			x: [][]float64{
 				{1},
@@ -215,6 +279,27 @@ func TestLassoAutoRegression(t *testing.T) {
 			intercept: 0.0,
 			coef:      []float64{3.0},
 		},
+		"auto model warm start": {
+			x: [][]float64{
+				{0, 0},
+				{3, 5},
+				{9, 20},
+				{12, 6},
+				{15, 10},
+			},
+			y: []float64{2, 31, 109, 62, 87},
+			opt: func() *LassoAutoOptions {
+				opt := NewDefaultLassoAutoOptions()
+				opt.Lambdas = lambdas
+				opt.Tolerance = desTol
+				opt.FitIntercept = true
+				opt.Parallelization = parallelization
+				opt.WarmStartBeta = []float64{1.5, 2.5, 4.5}
+				return opt
+			}(),
+			intercept: 2.0,
+			coef:      []float64{3.0, 4.0},
+		},
 	}
 
 	for name, td := range testData {
@@ -230,6 +315,152 @@ func TestLassoAutoRegression(t *testing.T) {
 			testModel(t, model, x, y, td.intercept, td.coef, tol)
 		})
 	}
+
+	t.Run("warm start size mismatch", func(t *testing.T) {
+		opt := NewDefaultLassoAutoOptions()
+		opt.WarmStartBeta = []float64{1.0}
+		model, err := NewLassoAutoRegression(opt)
+		require.Nil(t, err)
+
+		x := mat.NewDense(2, 2, []float64{0, 1, 1, 0})
+		y := mat.NewDense(2, 1, []float64{1, 2})
+		assert.ErrorIs(t, model.Fit(x, y), ErrWarmStartBetaSize)
+	})
+}
+
+func TestLassoRegressionSparse(t *testing.T) {
+	// event like features active on a few observations with a dense trend
+	n := 500
+	r := rand.New(rand.NewSource(1))
+	cols := make([][]float64, 4)
+	for j := range cols {
+		cols[j] = make([]float64, n)
+	}
+	yArr := make([]float64, n)
+	for i := 0; i < n; i++ {
+		cols[0][i] = float64(i) / float64(n)
+		if i%50 < 5 {
+			cols[1][i] = 1.0
+		}
+		if i > 400 {
+			cols[2][i] = 1.0
+		}
+		if i%97 == 0 {
+			cols[3][i] = 2.0
+		}
+		yArr[i] = 3.0 + 2.0*cols[0][i] + 5.0*cols[1][i] - 4.0*cols[2][i] + 0.1*r.NormFloat64()
+	}
+	sparse, err := mat_.NewCSRFromColumns(n, cols)
+	require.Nil(t, err)
+	dense := mat.DenseCopyOf(sparse)
+	y := mat.NewDense(n, 1, yArr)
+
+	testData := map[string]func() (Regressor, Regressor){
+		"lasso": func() (Regressor, Regressor) {
+			opt := &LassoOptions{Lambda: 0.01, Iterations: DefaultIterations, Tolerance: 1e-8, FitIntercept: true}
+			d, err := NewLassoRegression(opt)
+			require.Nil(t, err)
+			s, err := NewLassoRegression(opt)
+			require.Nil(t, err)
+			return d, s
+		},
+		"lasso auto adaptive": func() (Regressor, Regressor) {
+			opt := NewDefaultLassoAutoOptions()
+			opt.Lambdas = []float64{0.001, 0.01, 0.1}
+			opt.Tolerance = 1e-8
+			opt.Adaptive = true
+			d, err := NewLassoAutoRegression(opt)
+			require.Nil(t, err)
+			s, err := NewLassoAutoRegression(opt)
+			require.Nil(t, err)
+			return d, s
+		},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			d, s := td()
+			require.Nil(t, d.Fit(dense, y))
+			require.Nil(t, s.Fit(sparse, y))
+
+			assert.InDelta(t, d.Intercept(), s.Intercept(), 1e-6)
+			assert.InDeltaSlice(t, d.Coef(), s.Coef(), 1e-6)
+			assert.InDelta(t, 5.0, s.Coef()[len(s.Coef())-3], 0.1)
+
+			dPred, err := d.Predict(dense)
+			require.Nil(t, err)
+			sPred, err := s.Predict(sparse)
+			require.Nil(t, err)
+			assert.InDeltaSlice(t, dPred, sPred, 1e-6)
+		})
+	}
+}
+
+func TestLassoAutoRegressionPath(t *testing.T) {
+	x, err := mat_.NewDenseFromArray([][]float64{
+		{0, 0},
+		{3, 5},
+		{9, 20},
+		{12, 6},
+		{15, 10},
+	})
+	require.Nil(t, err)
+	y := mat.NewDense(5, 1, []float64{2, 31, 109, 62, 87})
+	lambdas := []float64{1e6, 0.0, 100.0, 10.0}
+
+	testData := map[string]struct {
+		retain bool
+		gram   bool
+	}{
+		"disabled":   {},
+		"dense":      {retain: true},
+		"sufficient": {retain: true, gram: true},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			opt := NewDefaultLassoAutoOptions()
+			opt.Lambdas = lambdas
+			opt.Tolerance = 1e-6
+			opt.FitIntercept = true
+			opt.Parallelization = 2
+			opt.RetainPath = td.retain
+
+			model, err := NewLassoAutoRegression(opt)
+			require.Nil(t, err)
+			if td.gram {
+				// gram fits expect the intercept column to be accumulated
+				ones, err := mat_.NewDenseFromArray([][]float64{
+					{1, 0, 0},
+					{1, 3, 5},
+					{1, 9, 20},
+					{1, 12, 6},
+					{1, 15, 10},
+				})
+				require.Nil(t, err)
+				g := NewGram(3)
+				require.Nil(t, g.Add(ones, y))
+				require.Nil(t, model.FitGram(g))
+			} else {
+				require.Nil(t, model.Fit(x, y))
+			}
+
+			path := model.Path()
+			if !td.retain {
+				assert.Empty(t, path)
+				return
+			}
+			require.Len(t, path, len(lambdas))
+			for i, pnt := range path {
+				require.Len(t, pnt.Coef, 2)
+				if i > 0 {
+					assert.Less(t, path[i-1].Lambda, pnt.Lambda)
+				}
+			}
+			assert.InDeltaSlice(t, []float64{3.0, 4.0}, path[0].Coef, 1e-3)
+			assert.Equal(t, []float64{0, 0}, path[len(path)-1].Coef)
+		})
+	}
 }
 
 func BenchmarkLassoRegression(b *testing.B) {
@@ -302,3 +533,151 @@ func BenchmarkLassoSerialRegression(b *testing.B) {
 		}
 	}
 }
+
+func TestLassoAutoRegressionCV(t *testing.T) {
+	// one informative feature among many noise features with few observations so the in-sample fit
+	// overfits with the smallest lambda
+	m, n := 60, 30
+	r := rand.New(rand.NewSource(3))
+	x := mat.NewDense(m, n, nil)
+	yArr := make([]float64, m)
+	for i := 0; i < m; i++ {
+		for j := 0; j < n; j++ {
+			x.Set(i, j, r.NormFloat64())
+		}
+		yArr[i] = 3.0*x.At(i, 0) + r.NormFloat64()
+	}
+	y := mat.NewDense(m, 1, yArr)
+	lambdas := []float64{0.0, 10.0, 1e6}
+
+	testData := map[string]struct {
+		folds    int
+		scoring  CVScoring
+		selected float64
+		err      error
+	}{
+		"in-sample":      {selected: 0.0},
+		"cv r2":          {folds: 3, selected: 10.0},
+		"cv mse":         {folds: 3, scoring: CVScoringMSE, selected: 10.0},
+		"negative folds": {folds: -1, err: ErrNegativeFolds},
+		"too many folds": {folds: 40, err: ErrInsufficientFoldData},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			opt := NewDefaultLassoAutoOptions()
+			opt.Lambdas = lambdas
+			opt.FitIntercept = true
+			opt.RetainPath = true
+			opt.CVFolds = td.folds
+			opt.CVScoring = td.scoring
+
+			model, err := NewLassoAutoRegression(opt)
+			if err == nil {
+				err = model.Fit(x, y)
+			}
+			if td.err != nil {
+				assert.ErrorIs(t, err, td.err)
+				return
+			}
+			require.Nil(t, err)
+
+			bestIdx := 0
+			path := model.Path()
+			for i, pnt := range path {
+				if pnt.Score > path[bestIdx].Score {
+					bestIdx = i
+				}
+			}
+			assert.Equal(t, td.selected, path[bestIdx].Lambda)
+			assert.Equal(t, path[bestIdx].Coef, model.Coef())
+		})
+	}
+}
+
+func TestLassoAutoRegressionFitCtx(t *testing.T) {
+	x := mat.NewDense(5, 2, []float64{0, 0, 3, 5, 9, 20, 12, 6, 15, 10})
+	y := mat.NewDense(5, 1, []float64{2, 31, 109, 62, 87})
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	expired, cancelExpired := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancelExpired()
+
+	testData := map[string]struct {
+		ctx context.Context
+		err error
+	}{
+		"background": {ctx: context.Background()},
+		"canceled":   {ctx: canceled, err: context.Canceled},
+		"expired":    {ctx: expired, err: context.DeadlineExceeded},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			opt := NewDefaultLassoAutoOptions()
+			opt.Lambdas = []float64{0.0, 1.0, 10.0, 100.0}
+			opt.Tolerance = 1e-6
+			opt.Parallelization = 2
+			reg, err := NewLassoAutoRegression(opt)
+			require.Nil(t, err)
+
+			err = reg.FitCtx(td.ctx, x, y)
+			if td.err != nil {
+				assert.ErrorIs(t, err, td.err)
+				return
+			}
+			require.Nil(t, err)
+			assert.InDelta(t, 2.0, reg.Intercept(), 1e-3)
+			assert.InDeltaSlice(t, []float64{3.0, 4.0}, reg.Coef(), 1e-3)
+
+			single, err := NewLassoRegression(NewDefaultLassoOptions())
+			require.Nil(t, err)
+			require.Nil(t, single.FitCtx(td.ctx, x, y))
+		})
+	}
+
+	// a single lasso fit stops before its first iteration
+	reg, err := NewLassoRegression(NewDefaultLassoOptions())
+	require.Nil(t, err)
+	assert.ErrorIs(t, reg.FitCtx(canceled, x, y), context.Canceled)
+}
+
+func TestLassoAutoRegressionHooks(t *testing.T) {
+	x := mat.NewDense(5, 2, []float64{0, 0, 3, 5, 9, 20, 12, 6, 15, 10})
+	y := mat.NewDense(5, 1, []float64{2, 31, 109, 62, 87})
+	lambdas := []float64{0.0, 1.0, 10.0}
+
+	var mu sync.Mutex
+	iterations := make(map[float64]int)
+	events := make(map[float64]LambdaEvent)
+
+	opt := NewDefaultLassoAutoOptions()
+	opt.Lambdas = lambdas
+	opt.Tolerance = 1e-6
+	opt.Parallelization = 2
+	opt.OnIteration = func(e IterationEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		iterations[e.Lambda]++
+		assert.Equal(t, iterations[e.Lambda], e.Iteration)
+		assert.GreaterOrEqual(t, e.MaxUpdate, 0.0)
+	}
+	opt.OnLambda = func(e LambdaEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		events[e.Lambda] = e
+	}
+	reg, err := NewLassoAutoRegression(opt)
+	require.Nil(t, err)
+	require.Nil(t, reg.Fit(x, y))
+
+	require.Len(t, events, len(lambdas))
+	for _, lambda := range lambdas {
+		e := events[lambda]
+		assert.Equal(t, iterations[lambda], e.Iterations)
+		assert.True(t, e.Converged)
+		assert.Less(t, e.Iterations, opt.Iterations)
+	}
+	assert.InDelta(t, 1.0, events[0.0].Score, 1e-6)
+}
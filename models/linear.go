@@ -0,0 +1,80 @@
+package models
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat"
+)
+
+// designColumns returns the columns of the design matrix prepending a constant column if fitting the
+// intercept
+func designColumns(x mat.Matrix, fitIntercept bool) [][]float64 {
+	m, n := x.Dims()
+	cols := make([][]float64, 0, n+1)
+	if fitIntercept {
+		ones := make([]float64, m)
+		for i := range ones {
+			ones[i] = 1.0
+		}
+		cols = append(cols, ones)
+	}
+	for j := 0; j < n; j++ {
+		cols = append(cols, mat.Col(nil, j, x))
+	}
+	return cols
+}
+
+// splitBeta splits the fit coefficients into the intercept and feature coefficients
+func splitBeta(beta []float64, fitIntercept bool) (float64, []float64) {
+	if fitIntercept {
+		return beta[0], beta[1:]
+	}
+	return 0.0, beta
+}
+
+// predictLinear computes the intercept plus the dot product of each observation with the coefficients
+func predictLinear(x mat.Matrix, intercept float64, coef []float64) ([]float64, error) {
+	if x == nil {
+		return nil, ErrNoDesignMatrix
+	}
+	m, n := x.Dims()
+	if n != len(coef) {
+		return nil, fmt.Errorf("got %d features in design matrix, but expected %d, %w", n, len(coef), ErrFeatureLenMismatch)
+	}
+	res := make([]float64, m)
+	for i := 0; i < m; i++ {
+		res[i] = intercept
+		for j, c := range coef {
+			res[i] += c * x.At(i, j)
+		}
+	}
+	return res, nil
+}
+
+// scoreLinear computes the coefficient of determination of the model predictions
+func scoreLinear(model Model, x, y mat.Matrix) (float64, error) {
+	if x == nil {
+		return 0.0, ErrNoDesignMatrix
+	}
+	if y == nil {
+		return 0.0, ErrNoTargetMatrix
+	}
+
+	m, _ := x.Dims()
+	ym, _ := y.Dims()
+	if m != ym {
+		return 0.0, fmt.Errorf("design matrix has %d rows and target has %d rows, %w", m, ym, ErrTargetLenMismatch)
+	}
+
+	res, err := model.Predict(x)
+	if err != nil {
+		return 0.0, err
+	}
+	score := stat.RSquaredFrom(res, mat.Col(nil, 0, y), nil)
+	if math.IsNaN(score) {
+		score = 1.0
+	}
+	return score, nil
+}
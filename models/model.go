@@ -1,9 +1,12 @@
 package models
 
 import (
+	"context"
+
 	"gonum.org/v1/gonum/mat"
 )
 
+// Model is a linear estimator fit on a training matrix of observations by features
 type Model interface {
 	Fit(x, y mat.Matrix) error
 	Predict(x mat.Matrix) ([]float64, error)
@@ -11,3 +14,19 @@ type Model interface {
 	Intercept() float64
 	Coef() []float64
 }
+
+// GramModel is a model which can also be fit from precomputed sufficient statistics
+type GramModel interface {
+	Model
+	FitGram(g *Gram) error
+}
+
+// ContextModel is a model whose fit stops early with the context error once the context is done
+type ContextModel interface {
+	Model
+	FitCtx(ctx context.Context, x, y mat.Matrix) error
+}
+
+// Regressor is a linear estimator which can be plugged into the forecast fit in place of the built in
+// regressions
+type Regressor = Model
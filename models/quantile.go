@@ -0,0 +1,252 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/aouyang1/go-forecaster/errs"
+	"gonum.org/v1/gonum/mat"
+)
+
+const (
+	DefaultQuantile        = 0.5
+	DefaultQuantileEpsilon = 1e-6
+)
+
+var (
+	ErrInvalidQuantile = errs.NewConfigError(errs.CodeInvalidOption, "quantile must be between 0 and 1 exclusive", nil)
+	ErrQuantileSolve   = errs.NewFitError(errs.CodeFitFailed, "unable to solve weighted least squares for quantile regression", nil)
+)
+
+// QuantileOptions represents input options to run the linear quantile regression
+type QuantileOptions struct {
+	// Quantile is the conditional quantile to fit e.g. 0.5 for the median or 0.95 for an upper band.
+	Quantile float64
+
+	// Iterations is the maximum number of reweighted least squares solves.
+	Iterations int
+
+	// Tolerance is the largest coefficient change on an iteration to determine when to stop iterating.
+	Tolerance float64
+
+	// Epsilon bounds the absolute residual from below when computing weights so points lying on the fit
+	// do not receive an infinite weight. Defaults to DefaultQuantileEpsilon if unset.
+	Epsilon float64
+
+	// FitIntercept adds a constant 1.0 feature as the first column if set to true
+	FitIntercept bool
+}
+
+// Validate runs basic validation on quantile regression options
+func (q *QuantileOptions) Validate() (*QuantileOptions, error) {
+	if q == nil {
+		q = NewDefaultQuantileOptions()
+	}
+
+	if q.Quantile <= 0 || q.Quantile >= 1 {
+		return nil, fmt.Errorf("quantile of %.3f, %w", q.Quantile, ErrInvalidQuantile)
+	}
+	if q.Iterations < 0 {
+		return nil, ErrNegativeIterations
+	}
+	if q.Tolerance < 0 {
+		return nil, ErrNegativeTolerance
+	}
+	if q.Epsilon <= 0 {
+		q.Epsilon = DefaultQuantileEpsilon
+	}
+	return q, nil
+}
+
+// NewDefaultQuantileOptions returns a default set of quantile regression options fitting the median
+func NewDefaultQuantileOptions() *QuantileOptions {
+	return &QuantileOptions{
+		Quantile:     DefaultQuantile,
+		Iterations:   DefaultIterations,
+		Tolerance:    DefaultTolerance,
+		Epsilon:      DefaultQuantileEpsilon,
+		FitIntercept: true,
+	}
+}
+
+// QuantileRegression fits a linear model minimizing the pinball loss of the configured quantile using
+// iteratively reweighted least squares. Each iteration weights the observations by the pinball slope
+// of their side of the fit over their absolute residual, starting from the ordinary least squares fit.
+type QuantileRegression struct {
+	opt *QuantileOptions
+
+	coef      []float64
+	intercept float64
+}
+
+// NewQuantileRegression initializes a quantile regression model ready for fitting
+func NewQuantileRegression(opt *QuantileOptions) (*QuantileRegression, error) {
+	opt, err := opt.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return &QuantileRegression{
+		opt: opt,
+	}, nil
+}
+
+// Fit the model according to the given training data
+func (q *QuantileRegression) Fit(x, y mat.Matrix) error {
+	if q.opt == nil {
+		return ErrNoOptions
+	}
+	if x == nil {
+		return ErrNoTrainingMatrix
+	}
+	if y == nil {
+		return ErrNoTargetMatrix
+	}
+	m, _ := x.Dims()
+	ym, _ := y.Dims()
+	if ym != m {
+		return fmt.Errorf("training data has %d rows and target has %d row, %w", m, ym, ErrTargetLenMismatch)
+	}
+
+	cols := designColumns(x, q.opt.FitIntercept)
+	yArr := mat.Col(nil, 0, y)
+	n := len(cols)
+
+	weights := make([]float64, m)
+	for i := range weights {
+		weights[i] = 1.0
+	}
+	beta := make([]float64, n)
+	pred := make([]float64, m)
+	gram := mat.NewSymDense(n, nil)
+	rhs := mat.NewVecDense(n, nil)
+
+	for iter := 0; iter <= q.opt.Iterations; iter++ {
+		// weighted normal equations of the current weights
+		for j := 0; j < n; j++ {
+			var b float64
+			for i, v := range cols[j] {
+				b += weights[i] * v * yArr[i]
+			}
+			rhs.SetVec(j, b)
+			for k := j; k < n; k++ {
+				var g float64
+				for i, v := range cols[j] {
+					g += weights[i] * v * cols[k][i]
+				}
+				gram.SetSym(j, k, g)
+			}
+		}
+		var sol mat.VecDense
+		if err := sol.SolveVec(gram, rhs); err != nil {
+			return fmt.Errorf("iteration %d, %w", iter, ErrQuantileSolve)
+		}
+
+		var maxChange float64
+		for j := 0; j < n; j++ {
+			maxChange = math.Max(maxChange, math.Abs(sol.AtVec(j)-beta[j]))
+			beta[j] = sol.AtVec(j)
+		}
+
+		for i := range pred {
+			pred[i] = 0
+			for j := 0; j < n; j++ {
+				pred[i] += beta[j] * cols[j][i]
+			}
+		}
+		for i := range weights {
+			r := yArr[i] - pred[i]
+			slope := q.opt.Quantile
+			if r < 0 {
+				slope = 1 - q.opt.Quantile
+			}
+			weights[i] = slope / math.Max(math.Abs(r), q.opt.Epsilon)
+		}
+
+		if iter > 0 && maxChange < q.opt.Tolerance {
+			break
+		}
+	}
+
+	q.intercept, q.coef = splitBeta(beta, q.opt.FitIntercept)
+	return nil
+}
+
+// Predict using the quantile regression model
+func (q *QuantileRegression) Predict(x mat.Matrix) ([]float64, error) {
+	if q.opt == nil {
+		return nil, ErrNoOptions
+	}
+	return predictLinear(x, q.intercept, q.coef)
+}
+
+// Score computes the pseudo coefficient of determination of the prediction which is one minus the
+// pinball loss of the fit over the pinball loss of the unconditional quantile of the target
+func (q *QuantileRegression) Score(x, y mat.Matrix) (float64, error) {
+	if q.opt == nil {
+		return 0.0, ErrNoOptions
+	}
+	if x == nil {
+		return 0.0, ErrNoDesignMatrix
+	}
+	if y == nil {
+		return 0.0, ErrNoTargetMatrix
+	}
+
+	m, _ := x.Dims()
+	ym, _ := y.Dims()
+	if m != ym {
+		return 0.0, fmt.Errorf("design matrix has %d rows and target has %d rows, %w", m, ym, ErrTargetLenMismatch)
+	}
+
+	res, err := q.Predict(x)
+	if err != nil {
+		return 0.0, err
+	}
+	ySlice := mat.Col(nil, 0, y)
+
+	sorted := make([]float64, len(ySlice))
+	copy(sorted, ySlice)
+	sort.Float64s(sorted)
+	idx := int(math.Ceil(q.opt.Quantile*float64(len(sorted)))) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	base := make([]float64, len(ySlice))
+	for i := range base {
+		base[i] = sorted[idx]
+	}
+
+	baseLoss := PinballLoss(ySlice, base, q.opt.Quantile)
+	if baseLoss == 0 {
+		return 1.0, nil
+	}
+	return 1.0 - PinballLoss(ySlice, res, q.opt.Quantile)/baseLoss, nil
+}
+
+// Intercept returns the computed intercept if FitIntercept is set to true. Defaults to 0.0 if not set.
+func (q *QuantileRegression) Intercept() float64 {
+	return q.intercept
+}
+
+// Coef returns a slice of the trained coefficients in the same order of the training feature Matrix by column.
+func (q *QuantileRegression) Coef() []float64 {
+	c := make([]float64, len(q.coef))
+	copy(c, q.coef)
+	return c
+}
+
+// PinballLoss returns the total quantile loss of the predictions which weights under predictions by
+// the quantile and over predictions by one minus the quantile
+func PinballLoss(y, pred []float64, quantile float64) float64 {
+	var loss float64
+	for i := range y {
+		r := y[i] - pred[i]
+		if r >= 0 {
+			loss += quantile * r
+		} else {
+			loss -= (1 - quantile) * r
+		}
+	}
+	return loss
+}
@@ -0,0 +1,143 @@
+package models
+
+import (
+	"math/rand"
+	"testing"
+
+	mat_ "github.com/aouyang1/go-forecaster/mat"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestQuantileOptionsValidate(t *testing.T) {
+	testData := map[string]struct {
+		opt      *QuantileOptions
+		err      error
+		expected *QuantileOptions
+	}{
+		"nil": {nil, nil, NewDefaultQuantileOptions()},
+		"default epsilon": {
+			&QuantileOptions{Quantile: 0.9, Iterations: 10},
+			nil,
+			&QuantileOptions{Quantile: 0.9, Iterations: 10, Epsilon: DefaultQuantileEpsilon},
+		},
+		"zero quantile":       {&QuantileOptions{Quantile: 0}, ErrInvalidQuantile, nil},
+		"one quantile":        {&QuantileOptions{Quantile: 1}, ErrInvalidQuantile, nil},
+		"negative iterations": {&QuantileOptions{Quantile: 0.5, Iterations: -1}, ErrNegativeIterations, nil},
+		"negative tolerance":  {&QuantileOptions{Quantile: 0.5, Tolerance: -1}, ErrNegativeTolerance, nil},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			opt, err := td.opt.Validate()
+			if td.err != nil {
+				assert.ErrorIs(t, err, td.err)
+				return
+			}
+			require.Nil(t, err)
+			assert.Equal(t, td.expected, opt)
+		})
+	}
+}
+
+func TestQuantileRegression(t *testing.T) {
+	tol := 1e-5
+	testData := map[string]struct {
+		x         [][]float64
+		y         []float64
+		opt       *QuantileOptions
+		intercept float64
+		coef      []float64
+	}{
+		"median intercept": {
+			x: [][]float64{
+				{0, 0},
+				{3, 5},
+				{9, 20},
+				{12, 6},
+				{15, 10},
+			},
+			y:         []float64{2, 31, 109, 62, 87},
+			intercept: 2.0,
+			coef:      []float64{3.0, 4.0},
+		},
+		"upper quantile no intercept": {
+			x: [][]float64{
+				{1, 0, 0},
+				{1, 3, 5},
+				{1, 9, 20},
+				{1, 12, 6},
+				{1, 15, 10},
+			},
+			y: []float64{2, 31, 109, 62, 87},
+			opt: &QuantileOptions{
+				Quantile:   0.9,
+				Iterations: DefaultIterations,
+				Tolerance:  DefaultTolerance,
+			},
+			intercept: 0.0,
+			coef:      []float64{2.0, 3.0, 4.0},
+		},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			x, err := mat_.NewDenseFromArray(td.x)
+			require.Nil(t, err)
+
+			y := mat.NewDense(len(td.y), 1, td.y)
+
+			model, err := NewQuantileRegression(td.opt)
+			require.Nil(t, err)
+
+			testModel(t, model, x, y, td.intercept, td.coef, tol)
+		})
+	}
+}
+
+func TestQuantileRegressionHeteroscedastic(t *testing.T) {
+	// noise grows with x so each conditional quantile has a different slope
+	n := 2000
+	r := rand.New(rand.NewSource(1))
+	x := mat.NewDense(n, 1, nil)
+	yArr := make([]float64, n)
+	for i := 0; i < n; i++ {
+		xi := 10.0 * float64(i) / float64(n)
+		x.Set(i, 0, xi)
+		yArr[i] = 1.0 + 2.0*xi + xi*(2.0*r.Float64()-1.0)
+	}
+	y := mat.NewDense(n, 1, yArr)
+
+	for _, quantile := range []float64{0.1, 0.5, 0.9} {
+		model, err := NewQuantileRegression(&QuantileOptions{
+			Quantile:     quantile,
+			Iterations:   DefaultIterations,
+			Tolerance:    DefaultTolerance,
+			FitIntercept: true,
+		})
+		require.Nil(t, err)
+		require.Nil(t, model.Fit(x, y))
+
+		assert.InDelta(t, 1.0, model.Intercept(), 0.2, "quantile %.1f", quantile)
+		assert.InDelta(t, 2.0+(2.0*quantile-1.0), model.Coef()[0], 0.1, "quantile %.1f", quantile)
+
+		pred, err := model.Predict(x)
+		require.Nil(t, err)
+		var below int
+		for i, p := range pred {
+			if yArr[i] <= p {
+				below++
+			}
+		}
+		assert.InDelta(t, quantile, float64(below)/float64(n), 0.02, "quantile %.1f", quantile)
+	}
+}
+
+func TestPinballLoss(t *testing.T) {
+	y := []float64{1, 2, 3}
+	pred := []float64{2, 2, 2}
+	assert.InDelta(t, 0.9*1+0.1*1, PinballLoss(y, pred, 0.9), 1e-9)
+	assert.InDelta(t, 1.0, PinballLoss(y, pred, 0.5), 1e-9)
+}
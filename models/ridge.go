@@ -0,0 +1,325 @@
+package models
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+
+	"github.com/aouyang1/go-forecaster/errs"
+	mat_ "github.com/aouyang1/go-forecaster/mat"
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+)
+
+var ErrRidgeSolve = errs.NewFitError(errs.CodeFitFailed, "unable to solve regularized normal equations for ridge regression", nil)
+
+// RidgeOptions represents input options to run the Ridge Regression
+type RidgeOptions struct {
+	// Lambda represents the L2 multiplier, controlling the regularization. Must be a non-negative. 0.0 results in
+	// Ordinary Least Squares (OLS).
+	Lambda float64
+
+	// FitIntercept adds a constant 1.0 feature as the first column if set to true. The intercept is not
+	// penalized.
+	FitIntercept bool
+}
+
+// Validate runs basic validation on Ridge options
+func (r *RidgeOptions) Validate() (*RidgeOptions, error) {
+	if r == nil {
+		r = NewDefaultRidgeOptions()
+	}
+
+	if r.Lambda < 0 {
+		return nil, ErrNegativeLambda
+	}
+	return r, nil
+}
+
+// NewDefaultRidgeOptions returns a default set of Ridge Regression options
+func NewDefaultRidgeOptions() *RidgeOptions {
+	return &RidgeOptions{
+		Lambda:       DefaultLambda,
+		FitIntercept: true,
+	}
+}
+
+// RidgeRegression computes the ridge regression in closed form by solving the regularized normal
+// equations with a Cholesky decomposition
+type RidgeRegression struct {
+	opt *RidgeOptions
+
+	coef      []float64
+	intercept float64
+}
+
+// NewRidgeRegression initializes a Ridge model ready for fitting
+func NewRidgeRegression(opt *RidgeOptions) (*RidgeRegression, error) {
+	opt, err := opt.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return &RidgeRegression{
+		opt: opt,
+	}, nil
+}
+
+// withIntercept returns the matrix with a constant 1.0 column prepended keeping sparse matrices sparse
+func withIntercept(x mat.Matrix) mat.Matrix {
+	if csr, ok := x.(*mat_.CSR); ok {
+		return csr.PrependOnes()
+	}
+	m, _ := x.Dims()
+	ones := make([]float64, m)
+	floats.AddConst(1.0, ones)
+	onesMx := mat.NewDense(1, m, ones)
+	xT := x.T()
+
+	var xWithOnes mat.Dense
+	xWithOnes.Stack(onesMx, xT)
+	return xWithOnes.T()
+}
+
+// newGramFromMatrix accumulates the sufficient statistics of the training data prepending the constant
+// column if fitting the intercept
+func newGramFromMatrix(x, y mat.Matrix, fitIntercept bool) (*Gram, error) {
+	if x == nil {
+		return nil, ErrNoTrainingMatrix
+	}
+	if y == nil {
+		return nil, ErrNoTargetMatrix
+	}
+	m, _ := x.Dims()
+	ym, _ := y.Dims()
+	if ym != m {
+		return nil, fmt.Errorf("training data has %d rows and target has %d row, %w", m, ym, ErrTargetLenMismatch)
+	}
+	if fitIntercept {
+		x = withIntercept(x)
+	}
+	_, n := x.Dims()
+	g := NewGram(n)
+	if err := g.Add(x, y); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// Fit the model according to the given training data
+func (r *RidgeRegression) Fit(x, y mat.Matrix) error {
+	if r.opt == nil {
+		return ErrNoOptions
+	}
+	g, err := newGramFromMatrix(x, y, r.opt.FitIntercept)
+	if err != nil {
+		return err
+	}
+	return r.FitGram(g)
+}
+
+// FitGram fits the model from precomputed sufficient statistics. The intercept is not added
+// automatically, so if FitIntercept is set the first accumulated feature is expected to be the constant
+// 1.0 column.
+func (r *RidgeRegression) FitGram(g *Gram) error {
+	if r.opt == nil {
+		return ErrNoOptions
+	}
+	if g == nil || g.N == 0 {
+		return ErrNoTrainingMatrix
+	}
+	n := g.Features()
+
+	a := mat.NewSymDense(n, nil)
+	a.CopySym(g.XTX)
+	for i := 0; i < n; i++ {
+		if r.opt.FitIntercept && i == 0 {
+			continue
+		}
+		a.SetSym(i, i, a.At(i, i)+r.opt.Lambda)
+	}
+
+	var chol mat.Cholesky
+	if ok := chol.Factorize(a); !ok {
+		return ErrRidgeSolve
+	}
+	var beta mat.VecDense
+	if err := chol.SolveVecTo(&beta, mat.NewVecDense(n, g.XTy)); err != nil {
+		return fmt.Errorf("%v, %w", err, ErrRidgeSolve)
+	}
+	r.intercept, r.coef = splitBeta(mat.Col(nil, 0, &beta), r.opt.FitIntercept)
+	return nil
+}
+
+// Predict using the Ridge model
+func (r *RidgeRegression) Predict(x mat.Matrix) ([]float64, error) {
+	if r.opt == nil {
+		return nil, ErrNoOptions
+	}
+	return predictLinear(x, r.intercept, r.coef)
+}
+
+// Score computes the coefficient of determination of the prediction
+func (r *RidgeRegression) Score(x, y mat.Matrix) (float64, error) {
+	if r.opt == nil {
+		return 0.0, ErrNoOptions
+	}
+	return scoreLinear(r, x, y)
+}
+
+// Intercept returns the computed intercept if FitIntercept is set to true. Defaults to 0.0 if not set.
+func (r *RidgeRegression) Intercept() float64 {
+	return r.intercept
+}
+
+// Coef returns a slice of the trained coefficients in the same order of the training feature Matrix by column.
+func (r *RidgeRegression) Coef() []float64 {
+	return r.coef
+}
+
+// RidgeAutoOptions represents input options to run the Ridge Regression with optimal regularization
+// parameter lambda
+type RidgeAutoOptions struct {
+	// Lambdas are the L2 multipliers to sweep. Must be non-negative.
+	Lambdas []float64
+
+	// FitIntercept adds a constant 1.0 feature as the first column if set to true
+	FitIntercept bool
+}
+
+// Validate runs basic validation on Ridge Auto options
+func (r *RidgeAutoOptions) Validate() (*RidgeAutoOptions, error) {
+	if r == nil {
+		r = NewDefaultRidgeAutoOptions()
+	}
+
+	if len(r.Lambdas) == 0 {
+		return nil, ErrNoLambdas
+	}
+	for _, lambda := range r.Lambdas {
+		if lambda < 0.0 {
+			return nil, ErrNegativeLambda
+		}
+	}
+	return r, nil
+}
+
+// NewDefaultRidgeAutoOptions returns a default set of Ridge Auto Regression options
+func NewDefaultRidgeAutoOptions() *RidgeAutoOptions {
+	return &RidgeAutoOptions{
+		Lambdas:      []float64{DefaultLambda},
+		FitIntercept: true,
+	}
+}
+
+// RidgeAutoRegression computes the ridge regression for each lambda and keeps the model with the best
+// in-sample coefficient of determination
+type RidgeAutoRegression struct {
+	opt *RidgeAutoOptions
+
+	bestModel *RidgeRegression
+}
+
+// NewRidgeAutoRegression initializes a Ridge model ready for fitting using automated lambda parameter
+// selection
+func NewRidgeAutoRegression(opt *RidgeAutoOptions) (*RidgeAutoRegression, error) {
+	opt, err := opt.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return &RidgeAutoRegression{
+		opt: opt,
+	}, nil
+}
+
+// Fit the model according to the given training data
+func (r *RidgeAutoRegression) Fit(x, y mat.Matrix) error {
+	if r.opt == nil {
+		return ErrNoOptions
+	}
+	g, err := newGramFromMatrix(x, y, r.opt.FitIntercept)
+	if err != nil {
+		return err
+	}
+	return r.FitGram(g)
+}
+
+// FitGram fits a Ridge model per lambda from precomputed sufficient statistics and keeps the model with
+// the best in-sample coefficient of determination. Lambdas which cannot be solved are skipped. The intercept is not added automatically, so if
+// FitIntercept is set the first accumulated feature is expected to be the constant 1.0 column.
+func (r *RidgeAutoRegression) FitGram(g *Gram) error {
+	if r.opt == nil {
+		return ErrNoOptions
+	}
+	if g == nil || g.N == 0 {
+		return ErrNoTrainingMatrix
+	}
+
+	r.bestModel = nil
+	bestScore := math.Inf(-1)
+	for _, lambda := range r.opt.Lambdas {
+		reg, err := NewRidgeRegression(&RidgeOptions{
+			Lambda:       lambda,
+			FitIntercept: r.opt.FitIntercept,
+		})
+		if err != nil {
+			return err
+		}
+		// singular statistics can only be solved with a positive lambda so skip unsolvable lambdas
+		if err := reg.FitGram(g); err != nil {
+			slog.Warn("unable to fit ridge regression", "lambda", lambda, "error", err.Error())
+			continue
+		}
+		beta := reg.Coef()
+		if r.opt.FitIntercept {
+			beta = append([]float64{reg.Intercept()}, beta...)
+		}
+		if score := g.RSquared(beta); score > bestScore {
+			bestScore = score
+			r.bestModel = reg
+		}
+	}
+	if r.bestModel == nil {
+		return ErrRidgeSolve
+	}
+	return nil
+}
+
+// Predict using the Ridge model
+func (r *RidgeAutoRegression) Predict(x mat.Matrix) ([]float64, error) {
+	if r.bestModel == nil {
+		return nil, ErrNoOptions
+	}
+	return r.bestModel.Predict(x)
+}
+
+// Score computes the coefficient of determination of the prediction
+func (r *RidgeAutoRegression) Score(x, y mat.Matrix) (float64, error) {
+	if r.bestModel == nil {
+		return 0.0, ErrNoOptions
+	}
+	return r.bestModel.Score(x, y)
+}
+
+// Intercept returns the computed intercept if FitIntercept is set to true. Defaults to 0.0 if not set.
+func (r *RidgeAutoRegression) Intercept() float64 {
+	if r == nil || r.bestModel == nil {
+		return 0.0
+	}
+	return r.bestModel.Intercept()
+}
+
+// Coef returns a slice of the trained coefficients in the same order of the training feature Matrix by column.
+func (r *RidgeAutoRegression) Coef() []float64 {
+	if r == nil || r.bestModel == nil {
+		return nil
+	}
+	return r.bestModel.Coef()
+}
+
+// Lambda returns the regularization lambda of the best fit. Defaults to 0.0 if not fit.
+func (r *RidgeAutoRegression) Lambda() float64 {
+	if r == nil || r.bestModel == nil {
+		return 0.0
+	}
+	return r.bestModel.opt.Lambda
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"testing"
+
+	mat_ "github.com/aouyang1/go-forecaster/mat"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestRidgeRegression(t *testing.T) {
+	tol := 1e-5
+	x := [][]float64{
+		{0, 0},
+		{3, 5},
+		{9, 20},
+		{12, 6},
+		{15, 10},
+	}
+	y := []float64{2, 31, 109, 62, 87}
+
+	testData := map[string]struct {
+		model     Model
+		intercept float64
+		coef      []float64
+	}{
+		"no regularization": {
+			model:     &RidgeRegression{opt: &RidgeOptions{FitIntercept: true}},
+			intercept: 2.0,
+			coef:      []float64{3.0, 4.0},
+		},
+		"auto selects least regularization": {
+			model: &RidgeAutoRegression{opt: &RidgeAutoOptions{
+				Lambdas:      []float64{10.0, 0.0, 1.0},
+				FitIntercept: true,
+			}},
+			intercept: 2.0,
+			coef:      []float64{3.0, 4.0},
+		},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			xMx, err := mat_.NewDenseFromArray(x)
+			require.Nil(t, err)
+			testModel(t, td.model, xMx, mat.NewDense(len(y), 1, y), td.intercept, td.coef, tol)
+		})
+	}
+}
+
+func TestRidgeRegressionShrinkage(t *testing.T) {
+	xMx, err := mat_.NewDenseFromArray([][]float64{{1}, {2}, {3}, {4}})
+	require.Nil(t, err)
+	y := mat.NewDense(4, 1, []float64{2, 4, 6, 8})
+
+	// without an intercept the closed form is x'y / (x'x + lambda) = 60 / (30 + 30)
+	model, err := NewRidgeRegression(&RidgeOptions{Lambda: 30.0})
+	require.Nil(t, err)
+	require.Nil(t, model.Fit(xMx, y))
+	assert.InDeltaSlice(t, []float64{1.0}, model.Coef(), 1e-9)
+
+	_, err = NewRidgeRegression(&RidgeOptions{Lambda: -1.0})
+	assert.ErrorIs(t, err, ErrNegativeLambda)
+	_, err = NewRidgeAutoRegression(&RidgeAutoOptions{})
+	assert.ErrorIs(t, err, ErrNoLambdas)
+}
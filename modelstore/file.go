@@ -0,0 +1,99 @@
+package modelstore
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FileStore is an ObjectStore persisting objects as files under a root directory
+type FileStore struct {
+	root string
+}
+
+// NewFileStore creates an object store rooted at the input directory creating it if necessary
+func NewFileStore(root string) (*FileStore, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("unable to create model store directory, %w", err)
+	}
+	return &FileStore{root: root}, nil
+}
+
+// NewFileModelStore creates a versioned model store on the local filesystem
+func NewFileModelStore(root string) (*VersionedStore, error) {
+	objects, err := NewFileStore(root)
+	if err != nil {
+		return nil, err
+	}
+	return NewVersionedStore(objects), nil
+}
+
+// Put writes the object to a temporary file and links it into place so partially written objects
+// are never visible and existing objects are never replaced.
+func (f *FileStore) Put(key string, data []byte) error {
+	filename := filepath.Join(f.root, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(filename), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Link(tmp.Name(), filename); err != nil {
+		if errors.Is(err, fs.ErrExist) {
+			return fmt.Errorf("%q, %w", key, ErrObjectExists)
+		}
+		return err
+	}
+	return nil
+}
+
+// Get reads the object stored at the key
+func (f *FileStore) Get(key string) ([]byte, error) {
+	data, err := os.ReadFile(filepath.Join(f.root, filepath.FromSlash(key)))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%q, %w", key, ErrObjectNotFound)
+	}
+	return data, err
+}
+
+// List returns all object keys starting with the prefix
+func (f *FileStore) List(prefix string) ([]string, error) {
+	var keys []string
+	err := filepath.WalkDir(f.root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || strings.HasPrefix(d.Name(), ".tmp-") {
+			return nil
+		}
+		rel, err := filepath.Rel(f.root, p)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+		return nil
+	})
+	return keys, err
+}
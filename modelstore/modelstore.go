@@ -0,0 +1,220 @@
+// Package modelstore keeps an append-only history of serialized forecaster models per series. Every
+// save creates a new version and rolling back re-publishes a prior version as the newest one so that
+// no history is ever overwritten.
+package modelstore
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	forecaster "github.com/aouyang1/go-forecaster"
+	"github.com/aouyang1/go-forecaster/errs"
+	"github.com/aouyang1/go-forecaster/forecast"
+)
+
+var (
+	ErrEmptySeriesID   = errs.NewConfigError(errs.CodeMissingOption, "no series id provided", nil)
+	ErrInvalidSeriesID = errs.NewConfigError(errs.CodeInvalidOption, "series id cannot contain path separators", nil)
+	ErrNoVersions      = errs.NewDataError(errs.CodeNotFound, "no model versions stored for series", nil)
+	ErrVersionNotFound = errs.NewDataError(errs.CodeNotFound, "model version not found", nil)
+	ErrObjectNotFound  = errs.NewDataError(errs.CodeNotFound, "object not found", nil)
+	ErrObjectExists    = errs.NewDataError(errs.CodeAlreadyExists, "object already exists", nil)
+)
+
+// Metadata records information about a stored model version
+type Metadata struct {
+	SeriesID     string            `json:"series_id"`
+	Version      int               `json:"version"`
+	CreatedAt    time.Time         `json:"created_at"`
+	TrainEndTime time.Time         `json:"train_end_time"`
+	Series       *forecast.Scores  `json:"series_scores"`
+	Uncertainty  *forecast.Scores  `json:"uncertainty_scores"`
+	RollbackOf   int               `json:"rollback_of,omitempty"`
+	Labels       map[string]string `json:"labels,omitempty"`
+}
+
+// ModelStore versions serialized forecaster models per series
+type ModelStore interface {
+	// Save stores the model as the next version of the series. Version, series id, creation time,
+	// training end time, and scores of the input metadata are populated by the store.
+	Save(seriesID string, model forecaster.Model, meta Metadata) (Metadata, error)
+
+	// Load retrieves a specific version of a series model
+	Load(seriesID string, version int) (forecaster.Model, Metadata, error)
+
+	// Latest retrieves the newest version of a series model
+	Latest(seriesID string) (forecaster.Model, Metadata, error)
+
+	// Versions lists the metadata of every stored version of a series from oldest to newest
+	Versions(seriesID string) ([]Metadata, error)
+
+	// Rollback stores a prior version as the newest version of the series
+	Rollback(seriesID string, version int) (Metadata, error)
+}
+
+// ObjectStore is a minimal key value blob store that a ModelStore can be built on. Put must fail with
+// ErrObjectExists if the key is already present and Get must fail with ErrObjectNotFound if the key is
+// missing. Keys are slash separated. Object storage such as S3 can be used by adapting its client to
+// this interface.
+type ObjectStore interface {
+	Put(key string, data []byte) error
+	Get(key string) ([]byte, error)
+	List(prefix string) ([]string, error)
+}
+
+type record struct {
+	Metadata Metadata         `json:"metadata"`
+	Model    forecaster.Model `json:"model"`
+}
+
+// VersionedStore implements ModelStore on top of an ObjectStore storing each version as a JSON object
+// under <series id>/<version>.json. Concurrent saves to the same series are not coordinated and the
+// losing writer receives ErrObjectExists.
+type VersionedStore struct {
+	objects ObjectStore
+	now     func() time.Time
+}
+
+// NewVersionedStore creates a model store backed by the input object store
+func NewVersionedStore(objects ObjectStore) *VersionedStore {
+	return &VersionedStore{
+		objects: objects,
+		now:     time.Now,
+	}
+}
+
+func validateSeriesID(seriesID string) error {
+	if seriesID == "" {
+		return ErrEmptySeriesID
+	}
+	if strings.ContainsAny(seriesID, `/\`) || seriesID == "." || seriesID == ".." {
+		return fmt.Errorf("%q, %w", seriesID, ErrInvalidSeriesID)
+	}
+	return nil
+}
+
+func versionKey(seriesID string, version int) string {
+	return path.Join(seriesID, fmt.Sprintf("%010d.json", version))
+}
+
+// versionNumbers returns the sorted stored versions of a series
+func (s *VersionedStore) versionNumbers(seriesID string) ([]int, error) {
+	keys, err := s.objects.List(seriesID + "/")
+	if err != nil {
+		return nil, fmt.Errorf("unable to list versions of %q, %w", seriesID, err)
+	}
+	versions := make([]int, 0, len(keys))
+	for _, key := range keys {
+		name := strings.TrimSuffix(path.Base(key), ".json")
+		version, err := strconv.Atoi(name)
+		if err != nil {
+			continue
+		}
+		versions = append(versions, version)
+	}
+	sort.Ints(versions)
+	return versions, nil
+}
+
+// Save stores the model as the next version of the series
+func (s *VersionedStore) Save(seriesID string, model forecaster.Model, meta Metadata) (Metadata, error) {
+	if err := validateSeriesID(seriesID); err != nil {
+		return Metadata{}, err
+	}
+	versions, err := s.versionNumbers(seriesID)
+	if err != nil {
+		return Metadata{}, err
+	}
+	next := 1
+	if len(versions) > 0 {
+		next = versions[len(versions)-1] + 1
+	}
+
+	meta.SeriesID = seriesID
+	meta.Version = next
+	meta.CreatedAt = s.now()
+	meta.TrainEndTime = model.Series.TrainEndTime
+	meta.Series = model.Series.Scores
+	meta.Uncertainty = model.Uncertainty.Scores
+
+	out, err := json.Marshal(record{Metadata: meta, Model: model})
+	if err != nil {
+		return Metadata{}, fmt.Errorf("unable to serialize model, %w", err)
+	}
+	if err := s.objects.Put(versionKey(seriesID, next), out); err != nil {
+		return Metadata{}, fmt.Errorf("unable to store version %d of %q, %w", next, seriesID, err)
+	}
+	return meta, nil
+}
+
+// Load retrieves a specific version of a series model
+func (s *VersionedStore) Load(seriesID string, version int) (forecaster.Model, Metadata, error) {
+	if err := validateSeriesID(seriesID); err != nil {
+		return forecaster.Model{}, Metadata{}, err
+	}
+	out, err := s.objects.Get(versionKey(seriesID, version))
+	if errors.Is(err, ErrObjectNotFound) {
+		return forecaster.Model{}, Metadata{}, fmt.Errorf("version %d of %q, %w", version, seriesID, ErrVersionNotFound)
+	}
+	if err != nil {
+		return forecaster.Model{}, Metadata{}, fmt.Errorf("unable to read version %d of %q, %w", version, seriesID, err)
+	}
+	var rec record
+	if err := json.Unmarshal(out, &rec); err != nil {
+		return forecaster.Model{}, Metadata{}, fmt.Errorf("unable to deserialize version %d of %q, %w", version, seriesID, err)
+	}
+	return rec.Model, rec.Metadata, nil
+}
+
+// Latest retrieves the newest version of a series model
+func (s *VersionedStore) Latest(seriesID string) (forecaster.Model, Metadata, error) {
+	if err := validateSeriesID(seriesID); err != nil {
+		return forecaster.Model{}, Metadata{}, err
+	}
+	versions, err := s.versionNumbers(seriesID)
+	if err != nil {
+		return forecaster.Model{}, Metadata{}, err
+	}
+	if len(versions) == 0 {
+		return forecaster.Model{}, Metadata{}, fmt.Errorf("%q, %w", seriesID, ErrNoVersions)
+	}
+	return s.Load(seriesID, versions[len(versions)-1])
+}
+
+// Versions lists the metadata of every stored version of a series from oldest to newest
+func (s *VersionedStore) Versions(seriesID string) ([]Metadata, error) {
+	if err := validateSeriesID(seriesID); err != nil {
+		return nil, err
+	}
+	versions, err := s.versionNumbers(seriesID)
+	if err != nil {
+		return nil, err
+	}
+	metas := make([]Metadata, 0, len(versions))
+	for _, version := range versions {
+		_, meta, err := s.Load(seriesID, version)
+		if err != nil {
+			return nil, err
+		}
+		metas = append(metas, meta)
+	}
+	return metas, nil
+}
+
+// Rollback stores a prior version as the newest version of the series keeping its labels
+func (s *VersionedStore) Rollback(seriesID string, version int) (Metadata, error) {
+	model, meta, err := s.Load(seriesID, version)
+	if err != nil {
+		return Metadata{}, fmt.Errorf("unable to rollback, %w", err)
+	}
+	return s.Save(seriesID, model, Metadata{
+		RollbackOf: version,
+		Labels:     meta.Labels,
+	})
+}
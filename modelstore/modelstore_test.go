@@ -0,0 +1,99 @@
+package modelstore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	forecaster "github.com/aouyang1/go-forecaster"
+	"github.com/aouyang1/go-forecaster/forecast"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testModel(intercept float64) forecaster.Model {
+	return forecaster.Model{
+		Series: forecast.Model{
+			TrainEndTime: time.Date(1970, 1, 2, 0, 0, 0, 0, time.UTC),
+			Scores:       &forecast.Scores{MSE: intercept},
+			Weights:      forecast.Weights{Intercept: intercept},
+		},
+	}
+}
+
+func TestVersionedStore(t *testing.T) {
+	store, err := NewFileModelStore(t.TempDir())
+	require.Nil(t, err)
+	store.now = func() time.Time { return time.Date(1970, 1, 3, 0, 0, 0, 0, time.UTC) }
+
+	_, _, err = store.Latest("s0")
+	assert.ErrorIs(t, err, ErrNoVersions)
+
+	_, err = store.Save("", testModel(1.0), Metadata{})
+	assert.ErrorIs(t, err, ErrEmptySeriesID)
+	_, err = store.Save("../s0", testModel(1.0), Metadata{})
+	assert.ErrorIs(t, err, ErrInvalidSeriesID)
+
+	meta, err := store.Save("s0", testModel(1.0), Metadata{Labels: map[string]string{"fit": "nightly"}})
+	require.Nil(t, err)
+	assert.Equal(t, 1, meta.Version)
+	assert.Equal(t, "s0", meta.SeriesID)
+	assert.Equal(t, time.Date(1970, 1, 2, 0, 0, 0, 0, time.UTC), meta.TrainEndTime)
+	assert.Equal(t, 1.0, meta.Series.MSE)
+
+	meta, err = store.Save("s0", testModel(2.0), Metadata{})
+	require.Nil(t, err)
+	assert.Equal(t, 2, meta.Version)
+
+	model, meta, err := store.Latest("s0")
+	require.Nil(t, err)
+	assert.Equal(t, 2, meta.Version)
+	assert.Equal(t, 2.0, model.Series.Weights.Intercept)
+
+	meta, err = store.Rollback("s0", 1)
+	require.Nil(t, err)
+	assert.Equal(t, 3, meta.Version)
+	assert.Equal(t, 1, meta.RollbackOf)
+	assert.Equal(t, map[string]string{"fit": "nightly"}, meta.Labels)
+
+	model, _, err = store.Latest("s0")
+	require.Nil(t, err)
+	assert.Equal(t, 1.0, model.Series.Weights.Intercept)
+
+	_, err = store.Rollback("s0", 10)
+	assert.ErrorIs(t, err, ErrVersionNotFound)
+
+	metas, err := store.Versions("s0")
+	require.Nil(t, err)
+	versions := make([]int, 0, len(metas))
+	for _, m := range metas {
+		versions = append(versions, m.Version)
+	}
+	assert.Equal(t, []int{1, 2, 3}, versions)
+
+	metas, err = store.Versions("s1")
+	require.Nil(t, err)
+	assert.Empty(t, metas)
+}
+
+func TestFileStorePut(t *testing.T) {
+	root := t.TempDir()
+	fs, err := NewFileStore(root)
+	require.Nil(t, err)
+
+	require.Nil(t, fs.Put("a/b.json", []byte("1")))
+	assert.ErrorIs(t, fs.Put("a/b.json", []byte("2")), ErrObjectExists)
+
+	data, err := fs.Get("a/b.json")
+	require.Nil(t, err)
+	assert.Equal(t, []byte("1"), data)
+
+	_, err = fs.Get("a/c.json")
+	assert.ErrorIs(t, err, ErrObjectNotFound)
+
+	// no temporary files are left behind
+	entries, err := os.ReadDir(filepath.Join(root, "a"))
+	require.Nil(t, err)
+	assert.Len(t, entries, 1)
+}
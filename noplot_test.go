@@ -0,0 +1,15 @@
+//go:build noplot
+
+package forecaster
+
+import (
+	"testing"
+)
+
+// writeExamplePlot skips rendering since plotting is excluded from the build
+func writeExamplePlot(f *Forecaster, filename string) error {
+	return nil
+}
+
+// assertPlotFitContains skips rendering since plotting is excluded from the build
+func assertPlotFitContains(t *testing.T, f *Forecaster, contains ...string) {}
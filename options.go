@@ -1,34 +1,82 @@
 package forecaster
 
 import (
+	"fmt"
 	"math"
+	"sort"
+	"time"
 
+	"github.com/aouyang1/go-forecaster/forecast"
 	"github.com/aouyang1/go-forecaster/forecast/options"
+	"github.com/aouyang1/go-forecaster/forecast/util"
+	"github.com/aouyang1/go-forecaster/stats"
+	"github.com/aouyang1/go-forecaster/timedataset"
+	"gonum.org/v1/gonum/stat"
 )
 
 // OutlierOptions configures the outlier removal pre-process using the Tukey Method. The outlier
 // removal process is done by multiple iterations of fitting the training data to a model and each step
 // removing outliers. For IQR set UpperPercentile too 0.75, LowerPercentile to 0.25, and TukeyFactor to 1.5.
+// Setting SeasonalPeriod and SeasonalBuckets computes the fences per seasonal bucket instead of over all
+// residuals, e.g. a 24 hour period with 24 buckets computes fences per hour of day in UTC. Outlier passes
+// stop once removing more points would leave less than MinRemainingFraction of the observed training data.
 type OutlierOptions struct {
-	NumPasses       int     `json:"num_passes"`
-	UpperPercentile float64 `json:"upper_percentile"`
-	LowerPercentile float64 `json:"lower_percentile"`
-	TukeyFactor     float64 `json:"tukey_factor"`
+	NumPasses            int           `json:"num_passes"`
+	UpperPercentile      float64       `json:"upper_percentile"`
+	LowerPercentile      float64       `json:"lower_percentile"`
+	TukeyFactor          float64       `json:"tukey_factor"`
+	SeasonalPeriod       time.Duration `json:"seasonal_period"`
+	SeasonalBuckets      int           `json:"seasonal_buckets"`
+	MinRemainingFraction float64       `json:"min_remaining_fraction"`
 }
 
+// DefaultMinRemainingFraction halts outlier removal before half of the observed training data is removed
+const DefaultMinRemainingFraction = 0.5
+
 // NewOutlierOptions generates a default set of outlier options
 func NewOutlierOptions() *OutlierOptions {
 	return &OutlierOptions{
-		NumPasses:       3,
-		UpperPercentile: 0.9,
-		LowerPercentile: 0.1,
-		TukeyFactor:     1.0,
+		NumPasses:            3,
+		UpperPercentile:      0.9,
+		LowerPercentile:      0.1,
+		TukeyFactor:          1.0,
+		MinRemainingFraction: DefaultMinRemainingFraction,
+	}
+}
+
+// seasonal returns true if Tukey fences should be computed per seasonal bucket
+func (o *OutlierOptions) seasonal() bool {
+	return o != nil && o.SeasonalPeriod > 0 && o.SeasonalBuckets > 1 &&
+		o.SeasonalPeriod.Nanoseconds() >= int64(o.SeasonalBuckets)
+}
+
+// seasonalBuckets returns the seasonal bucket of each time point or nil if seasonal fences are not configured
+func (o *OutlierOptions) seasonalBuckets(t []time.Time) []int {
+	if !o.seasonal() {
+		return nil
+	}
+	period := o.SeasonalPeriod.Nanoseconds()
+	width := period / int64(o.SeasonalBuckets)
+
+	buckets := make([]int, len(t))
+	for i, tPnt := range t {
+		phase := tPnt.UnixNano() % period
+		if phase < 0 {
+			phase += period
+		}
+		b := int(phase / width)
+		if b >= o.SeasonalBuckets {
+			b = o.SeasonalBuckets - 1
+		}
+		buckets[i] = b
 	}
+	return buckets
 }
 
 type SeriesOptions struct {
-	ForecastOptions *options.Options `json:"forecast_options"`
-	OutlierOptions  *OutlierOptions  `json:"outlier_options"`
+	ForecastOptions   *options.Options   `json:"forecast_options"`
+	OutlierOptions    *OutlierOptions    `json:"outlier_options"`
+	ImputationOptions *ImputationOptions `json:"imputation_options,omitempty"`
 }
 
 func NewSeriesOptions() *SeriesOptions {
@@ -38,10 +86,178 @@ func NewSeriesOptions() *SeriesOptions {
 	}
 }
 
+// ResidualFilter is a pre-filter applied to the series residual before computing its rolling variability
+type ResidualFilter string
+
+const (
+	// ResidualFilterNone uses the raw residual
+	ResidualFilterNone ResidualFilter = ""
+
+	// ResidualFilterMedian subtracts a centered rolling median of FilterWindow samples from the residual
+	ResidualFilterMedian ResidualFilter = "median"
+
+	// ResidualFilterSeasonalDiff differences the residual against the residual SeasonalLag samples earlier
+	ResidualFilterSeasonalDiff ResidualFilter = "seasonal_diff"
+)
+
+// UncertaintyOptions configures the uncertainty model which is fit on the rolling standard deviation of
+// the series residual. Residual that still contains seasonality missed by the series model makes the
+// rolling standard deviation pulse with that signal, so ResidualFilter can remove it beforehand and let
+// the uncertainty model capture only the noise level.
+//
+// ResidualWindowDuration sets the residual window as a duration instead which is converted to
+// ResidualWindow samples using the inferred interval of the training data so that the uncertainty
+// behaves consistently across data resolutions.
+//
+// HourlyCalibration scales the predicted uncertainty by a multiplier per hour of day so that the
+// bands cover CalibrationQuantile of the training residuals in every hour. This corrects bands that are
+// too wide during quiet hours and too narrow during noisy ones. Hours are local to the weekend timezone
+// override of the series options, else the first DST timezone location if DST is enabled, else UTC.
+// The multipliers are set by the fit and persisted with the model.
+//
+// TrendBootstraps adds a long-term uncertainty for the trend parameters on top of the short-term
+// residual noise. The series is refit on the fitted values plus block resampled residuals and the
+// standard deviation of the trend level and hourly slope at the end of training is persisted with the
+// model as TrendLevelStd and TrendSlopeStd. The long-term band grows with the horizon past the end of
+// training and is combined with the short-term band in quadrature.
+//
+// Method selects how the prediction intervals are computed. The bootstrap method refits Bootstraps
+// replicates of the series model on the fitted values plus block resampled residuals and predicts
+// each replicate perturbed by a training residual. The bands are the empirical quantiles covering the
+// same central probability as ResidualZscore standard deviations of a normal distribution. The
+// replicate models and residuals are persisted with the model so the serialized model grows with the
+// number of bootstraps. The replicates already capture the trend uncertainty so the long-term band
+// and hourly calibration only apply to the rolling standard deviation method.
+//
+// The quantile method fits the LowerQuantile and UpperQuantile of the series residual directly with
+// pinball loss quantile regressions on the features of ForecastOptions, so skewed residuals produce
+// asymmetric bands. Both models are persisted with the model as LowerQuantileModel and
+// UpperQuantileModel. If neither quantile is set the bootstrap and quantile bands cover the same
+// central probability as ResidualZscore standard deviations of a normal distribution.
 type UncertaintyOptions struct {
-	ForecastOptions *options.Options `json:"forecast_options"`
-	ResidualWindow  int              `json:"residual_window"`
-	ResidualZscore  float64          `json:"residual_zscore"`
+	ForecastOptions        *options.Options `json:"forecast_options"`
+	ResidualWindow         int              `json:"residual_window"`
+	ResidualWindowDuration time.Duration    `json:"residual_window_duration,omitempty"`
+	ResidualZscore         float64          `json:"residual_zscore"`
+	ResidualFilter         ResidualFilter   `json:"residual_filter,omitempty"`
+	FilterWindow           int              `json:"filter_window,omitempty"`
+	SeasonalLag            int              `json:"seasonal_lag,omitempty"`
+
+	HourlyCalibration   bool      `json:"hourly_calibration,omitempty"`
+	CalibrationQuantile float64   `json:"calibration_quantile,omitempty"`
+	HourlyMultipliers   []float64 `json:"hourly_multipliers,omitempty"`
+
+	TrendBootstraps    int     `json:"trend_bootstraps,omitempty"`
+	TrendBootstrapSeed int64   `json:"trend_bootstrap_seed,omitempty"`
+	TrendLevelStd      float64 `json:"trend_level_std,omitempty"`
+	TrendSlopeStd      float64 `json:"trend_slope_std,omitempty"`
+
+	Method             UncertaintyMethod `json:"method,omitempty"`
+	Bootstraps         int               `json:"bootstraps,omitempty"`
+	BootstrapSeed      int64             `json:"bootstrap_seed,omitempty"`
+	BootstrapModels    []forecast.Model  `json:"bootstrap_models,omitempty"`
+	BootstrapResiduals []float64         `json:"bootstrap_residuals,omitempty"`
+
+	LowerQuantile      float64         `json:"lower_quantile,omitempty"`
+	UpperQuantile      float64         `json:"upper_quantile,omitempty"`
+	LowerQuantileModel *forecast.Model `json:"lower_quantile_model,omitempty"`
+	UpperQuantileModel *forecast.Model `json:"upper_quantile_model,omitempty"`
+}
+
+const (
+	// DefaultCalibrationQuantile is the fraction of residuals per hour the calibrated bands cover by default
+	DefaultCalibrationQuantile = 0.95
+
+	// MinCalibrationSamples is the fewest residuals in an hour needed to calibrate its multiplier
+	MinCalibrationSamples = 5
+)
+
+// calibrateHourly sets the multiplier of each hour of day to the calibration quantile of the ratio of
+// absolute residual to predicted uncertainty in that hour. Hours are taken in the local timezone loc.
+// Hours without enough residuals keep a multiplier of 1.
+func (u *UncertaintyOptions) calibrateHourly(t []time.Time, residual, uncertainty []float64, loc *time.Location) error {
+	u.HourlyMultipliers = nil
+	if !u.HourlyCalibration {
+		return nil
+	}
+	q := u.CalibrationQuantile
+	if q == 0 {
+		q = DefaultCalibrationQuantile
+	}
+	if q <= 0 || q >= 1 {
+		return fmt.Errorf("calibration quantile of %.3f, %w", q, ErrInvalidCalibrationQuantile)
+	}
+
+	ratios := make([][]float64, 24)
+	for i, tPnt := range t {
+		if math.IsNaN(residual[i]) || math.IsNaN(uncertainty[i]) || uncertainty[i] <= 0 {
+			continue
+		}
+		hour := tPnt.In(loc).Hour()
+		ratios[hour] = append(ratios[hour], math.Abs(residual[i])/uncertainty[i])
+	}
+
+	multipliers := make([]float64, 24)
+	for hour, r := range ratios {
+		multipliers[hour] = 1.0
+		if len(r) < MinCalibrationSamples {
+			continue
+		}
+		sort.Float64s(r)
+		multipliers[hour] = stat.Quantile(q, stat.Empirical, r, nil)
+	}
+	u.HourlyMultipliers = multipliers
+	return nil
+}
+
+// hourlyMultiplier returns the calibrated multiplier of the hour of the time in the local timezone loc
+// or 1 if uncalibrated
+func (u *UncertaintyOptions) hourlyMultiplier(t time.Time, loc *time.Location) float64 {
+	if len(u.HourlyMultipliers) != 24 {
+		return 1.0
+	}
+	return u.HourlyMultipliers[t.In(loc).Hour()]
+}
+
+// windowFromDuration sets the residual window samples from the residual window duration and the
+// inferred interval of the input times. Nothing is changed if no duration is configured.
+func (u *UncertaintyOptions) windowFromDuration(t []time.Time, freqOpt *timedataset.FreqOptions) error {
+	if u.ResidualWindowDuration <= 0 {
+		return nil
+	}
+	freq, err := inferFreq(t, freqOpt)
+	if err != nil {
+		return fmt.Errorf("unable to convert residual window duration to samples, %w", err)
+	}
+	if freq <= 0 {
+		return fmt.Errorf("unable to convert residual window duration to samples, %w", ErrCannotInferInterval)
+	}
+	u.ResidualWindow = int(math.Round(float64(u.ResidualWindowDuration) / float64(freq)))
+	return nil
+}
+
+// filterResidual applies the configured residual pre-filter returning a new slice
+func (u *UncertaintyOptions) filterResidual(residual []float64) ([]float64, error) {
+	switch u.ResidualFilter {
+	case ResidualFilterNone:
+		return residual, nil
+	case ResidualFilterMedian:
+		if u.FilterWindow < 2 {
+			return nil, fmt.Errorf("filter window of %d, %w", u.FilterWindow, ErrInvalidFilterWindow)
+		}
+		median := stats.RollingMedian(residual, u.FilterWindow)
+		filtered := make([]float64, len(residual))
+		for i, r := range residual {
+			filtered[i] = r - median[i]
+		}
+		return filtered, nil
+	case ResidualFilterSeasonalDiff:
+		if u.SeasonalLag < 1 || u.SeasonalLag >= len(residual) {
+			return nil, fmt.Errorf("seasonal lag of %d with %d residuals, %w", u.SeasonalLag, len(residual), ErrInvalidSeasonalLag)
+		}
+		return stats.SeasonalDifference(residual, u.SeasonalLag), nil
+	}
+	return nil, fmt.Errorf("%q, %w", u.ResidualFilter, ErrUnknownResidualFilter)
 }
 
 func NewUncertaintyOptions() *UncertaintyOptions {
@@ -52,12 +268,139 @@ func NewUncertaintyOptions() *UncertaintyOptions {
 	}
 }
 
-// Options represents all forecaster options for outlier removal, forecast fit, and uncertainty fit
+// Options represents all forecaster options for outlier removal, forecast fit, and uncertainty fit.
+// ExcludeRecent drops the trailing window of the training data ending at the last point from the fit
+// since recently ingested points may be incomplete and would otherwise drag the trend down. The fit
+// results are still predicted over the excluded window.
 type Options struct {
 	SeriesOptions      *SeriesOptions      `json:"series_options"`
 	UncertaintyOptions *UncertaintyOptions `json:"uncertainty_options"`
 	MinValue           *float64            `json:"min_value"`
 	MaxValue           *float64            `json:"max_value"`
+	ExcludeRecent      time.Duration       `json:"exclude_recent,omitempty"`
+
+	// ContextualClip clips forecasts to the range of the training history widened by the fitted
+	// uncertainty in addition to any fixed MinValue and MaxValue
+	ContextualClip *ContextualClipOptions `json:"contextual_clip,omitempty"`
+
+	// Resample aligns irregular or jittered training times to a regular grid before fitting. The fit
+	// results are over the grid rather than the input times. Resampling is applied before any counter
+	// conversion so counters should keep the last observation of each interval.
+	Resample *timedataset.ResampleOptions `json:"resample,omitempty"`
+
+	// Autoregression corrects short horizon forecasts with an autoregressive model of the fit residual
+	Autoregression *AutoregressionOptions `json:"autoregression,omitempty"`
+
+	// Counter converts a monotonically increasing counter to a rate before fitting
+	Counter *CounterOptions `json:"counter,omitempty"`
+
+	// Transform fits the series in a transformed space and inverts the forecast and bands
+	Transform *TransformOptions `json:"transform,omitempty"`
+
+	// Growth saturates the trend at a floor and cap by fitting the series in logit space and inverts the
+	// forecast and bands
+	Growth *GrowthOptions `json:"growth,omitempty"`
+
+	// UpdateMaxHistory bounds the history refit by Update to the most recent duration. The full history
+	// is kept if zero.
+	UpdateMaxHistory time.Duration `json:"update_max_history,omitempty"`
+
+	// FreqOptions infers the sampling interval of the training data for MakeFuturePeriods and for
+	// converting duration based options to samples. The most common interval is used if unset.
+	FreqOptions *timedataset.FreqOptions `json:"freq_options,omitempty"`
+
+	// ResidualPersistence serializes a summary of the training residual and uncertainty with the model,
+	// or the full training series, so residual thresholds and diagnostics work on loaded models
+	ResidualPersistence ResidualPersistence `json:"residual_persistence,omitempty"`
+
+	// TrainingHooks reports the progress of each fit and is not serialized
+	TrainingHooks *TrainingHooks `json:"-"`
+}
+
+// Copy returns a deep copy of the options including the training hooks which are not serialized. Nil
+// options return nil.
+func (o *Options) Copy() *Options {
+	return util.DeepCopy(o)
+}
+
+// inferFreq infers the sampling interval of the times with the frequency options or the default
+// options if nil
+func inferFreq(t []time.Time, freqOpt *timedataset.FreqOptions) (time.Duration, error) {
+	var opt timedataset.FreqOptions
+	if freqOpt != nil {
+		opt = *freqOpt
+	}
+	est, err := timedataset.TimeSlice(t).InferFreq(opt)
+	if err != nil {
+		return 0, err
+	}
+	return est.Freq, nil
+}
+
+// ContextualClipOptions derives clipping bounds from the training data. The bounds are the lower and
+// upper quantiles of the observed training values after outlier removal widened by the median fitted
+// uncertainty times the uncertainty multiplier. Min and Max are set by the fit and persisted with the
+// model.
+type ContextualClipOptions struct {
+	LowerQuantile         float64 `json:"lower_quantile"`
+	UpperQuantile         float64 `json:"upper_quantile"`
+	UncertaintyMultiplier float64 `json:"uncertainty_multiplier"`
+
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+}
+
+// NewContextualClipOptions clips to the training minimum and maximum widened by one median
+// uncertainty
+func NewContextualClipOptions() *ContextualClipOptions {
+	return &ContextualClipOptions{
+		LowerQuantile:         0.0,
+		UpperQuantile:         1.0,
+		UncertaintyMultiplier: 1.0,
+	}
+}
+
+func (c *ContextualClipOptions) validate() error {
+	if c.LowerQuantile < 0 || c.UpperQuantile > 1 || c.LowerQuantile >= c.UpperQuantile {
+		return fmt.Errorf("lower quantile %.3f, upper quantile %.3f, %w", c.LowerQuantile, c.UpperQuantile, ErrInvalidClipQuantiles)
+	}
+	if c.UncertaintyMultiplier < 0 {
+		return fmt.Errorf("uncertainty multiplier %.3f, %w", c.UncertaintyMultiplier, ErrInvalidClipMultiplier)
+	}
+	return nil
+}
+
+// setBounds computes the clipping bounds from the training values and fitted uncertainty ignoring NaNs
+func (c *ContextualClipOptions) setBounds(y, uncertainty []float64) error {
+	if err := c.validate(); err != nil {
+		return err
+	}
+	observed := make([]float64, 0, len(y))
+	for _, v := range y {
+		if !math.IsNaN(v) {
+			observed = append(observed, v)
+		}
+	}
+	if len(observed) == 0 {
+		return ErrInsufficientResidual
+	}
+	sort.Float64s(observed)
+
+	unc := make([]float64, 0, len(uncertainty))
+	for _, v := range uncertainty {
+		if !math.IsNaN(v) {
+			unc = append(unc, v)
+		}
+	}
+	var margin float64
+	if len(unc) > 0 {
+		sort.Float64s(unc)
+		margin = c.UncertaintyMultiplier * stat.Quantile(0.5, stat.Empirical, unc, nil)
+	}
+
+	c.Min = stat.Quantile(c.LowerQuantile, stat.Empirical, observed, nil) - margin
+	c.Max = stat.Quantile(c.UpperQuantile, stat.Empirical, observed, nil) + margin
+	return nil
 }
 
 // NewDefaultOptions generates a default set of options for a forecaster
@@ -68,6 +411,25 @@ func NewDefaultOptions() *Options {
 	}
 }
 
+// excludeRecent marks the values within the trailing ExcludeRecent window as missing so that they are
+// not used for training returning the excluded indexes in ascending order
+func (o *Options) excludeRecent(t []time.Time, y []float64) []int {
+	if o.ExcludeRecent <= 0 || len(t) == 0 {
+		return nil
+	}
+	cutoff := t[len(t)-1].Add(-o.ExcludeRecent)
+	start := len(t)
+	for start > 0 && t[start-1].After(cutoff) {
+		start--
+	}
+	excluded := make([]int, 0, len(t)-start)
+	for i := start; i < len(t); i++ {
+		y[i] = math.NaN()
+		excluded = append(excluded, i)
+	}
+	return excluded
+}
+
 func (o *Options) SetMinValue(val float64) {
 	if math.IsNaN(val) {
 		return
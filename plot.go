@@ -0,0 +1,395 @@
+//go:build !noplot
+
+package forecaster
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/feature"
+	"github.com/aouyang1/go-forecaster/forecast"
+	"github.com/aouyang1/go-forecaster/timedataset"
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/components"
+	"github.com/go-echarts/go-echarts/v2/opts"
+)
+
+// LineTSeries generates an echart multi-line chart for some arbitrary time/value combination. The input
+// y is a slice of series that much have the same length as the input time slice.
+func LineTSeries(title string, seriesName []string, t []time.Time, y [][]float64, forecastStartIdx int) *charts.Line {
+	line := charts.NewLine()
+	line.SetGlobalOptions(
+		charts.WithTitleOpts(
+			opts.Title{
+				Title: title,
+			},
+		),
+		charts.WithDataZoomOpts(
+			opts.DataZoom{
+				Type:       "slider",
+				XAxisIndex: []int{0},
+			},
+		),
+		charts.WithTooltipOpts(
+			opts.Tooltip{
+				Trigger: "axis",
+			},
+		),
+	)
+
+	lineData := make([][]opts.LineData, len(y))
+
+	filteredT := make([]time.Time, 0, len(t))
+	for i := 0; i < len(y); i++ {
+		lineData[i] = make([]opts.LineData, 0, len(y[i]))
+		for j := 0; j < len(y[i]); j++ {
+			if i == 0 {
+				filteredT = append(filteredT, t[j])
+			}
+
+			lineData[i] = append(lineData[i], opts.LineData{Value: handleNaN(y[i][j])})
+		}
+	}
+
+	markLineOpts := []charts.SeriesOpts{
+		charts.WithMarkLineNameXAxisItemOpts(
+			opts.MarkLineNameXAxisItem{
+				XAxis: forecastStartIdx,
+			},
+		),
+		charts.WithMarkLineStyleOpts(
+			opts.MarkLineStyle{
+				Symbol:    []string{"none", "none"},
+				Label:     &opts.Label{Show: opts.Bool(false)},
+				LineStyle: &opts.LineStyle{Color: "black"},
+			},
+		),
+	}
+
+	line.SetXAxis(filteredT)
+	for i, series := range seriesName {
+		if i == 0 {
+			line.AddSeries(series, lineData[i], markLineOpts...)
+			continue
+		}
+		line.AddSeries(series, lineData[i])
+	}
+
+	return line
+}
+
+// LineForecaster generates an echart line chart for a give fit result plotting the expected values
+// along with the forecasted, upper, lower values.
+func LineForecaster(trainingData *timedataset.TimeDataset, fitRes, forecastRes *Results) *charts.Line {
+	line := charts.NewLine()
+	line.SetGlobalOptions(
+		charts.WithTitleOpts(
+			opts.Title{
+				Title: "Forecast Fit",
+			},
+		),
+		charts.WithDataZoomOpts(
+			opts.DataZoom{
+				Type:       "slider",
+				XAxisIndex: []int{0},
+			},
+		),
+		charts.WithTooltipOpts(
+			opts.Tooltip{
+				Trigger: "axis",
+			},
+		),
+	)
+
+	dataTime := make([]time.Time, 0, len(fitRes.T)+len(forecastRes.T))
+	dataTime = append(dataTime, fitRes.T...)
+	dataTime = append(dataTime, forecastRes.T...)
+
+	lineDataActual := make([]opts.LineData, 0, len(trainingData.T))
+
+	lineDataForecast := make([]opts.LineData, 0, len(fitRes.T)+len(forecastRes.T))
+	lineDataUpper := make([]opts.LineData, 0, len(fitRes.T)+len(forecastRes.T))
+	lineDataLower := make([]opts.LineData, 0, len(fitRes.T)+len(forecastRes.T))
+
+	for i := 0; i < len(fitRes.T); i++ {
+		lineDataActual = append(lineDataActual, opts.LineData{Value: handleNaN(trainingData.Y[i])})
+		lineDataForecast = append(lineDataForecast, opts.LineData{Value: handleNaN(fitRes.Forecast[i])})
+		lineDataUpper = append(lineDataUpper, opts.LineData{Value: handleNaN(fitRes.Upper[i])})
+		lineDataLower = append(lineDataLower, opts.LineData{Value: handleNaN(fitRes.Lower[i])})
+	}
+
+	for i := 0; i < len(forecastRes.T); i++ {
+		lineDataForecast = append(lineDataForecast, opts.LineData{Value: handleNaN(forecastRes.Forecast[i])})
+		lineDataUpper = append(lineDataUpper, opts.LineData{Value: handleNaN(forecastRes.Upper[i])})
+		lineDataLower = append(lineDataLower, opts.LineData{Value: handleNaN(forecastRes.Lower[i])})
+	}
+
+	markLineOpts := []charts.SeriesOpts{
+		charts.WithMarkLineNameXAxisItemOpts(
+			opts.MarkLineNameXAxisItem{
+				XAxis: len(fitRes.T),
+			},
+		),
+		charts.WithMarkLineStyleOpts(
+			opts.MarkLineStyle{
+				Symbol:    []string{"none", "none"},
+				Label:     &opts.Label{Show: opts.Bool(false)},
+				LineStyle: &opts.LineStyle{Color: "black"},
+			},
+		),
+	}
+	line.SetXAxis(dataTime).
+		AddSeries("Upper", lineDataUpper).
+		AddSeries("Actual", lineDataActual).
+		AddSeries("Forecast", lineDataForecast, markLineOpts...).
+		AddSeries("Lower", lineDataLower)
+	return line
+}
+
+// handleNaN converts nans to a string "-" to satisfy echarts requirement
+func handleNaN(val float64) interface{} {
+	if math.IsNaN(val) {
+		return "-"
+	}
+	return val
+}
+
+// ScatterAnnotations generates an echart scatter chart with one series per annotation name marking the
+// values at the annotated indexes. This is intended to be overlapped on a line chart of the same times.
+// Annotated points with NaN values are marked at zero.
+func ScatterAnnotations(t []time.Time, y []float64, names []string, idxs [][]int) *charts.Scatter {
+	scatter := charts.NewScatter()
+	scatter.SetXAxis(t)
+	for i, name := range names {
+		data := make([]opts.ScatterData, len(t))
+		for j := range data {
+			data[j] = opts.ScatterData{Value: "-"}
+		}
+		if i < len(idxs) {
+			for _, idx := range idxs[i] {
+				if idx < 0 || idx >= len(t) || idx >= len(y) {
+					continue
+				}
+				val := y[idx]
+				if math.IsNaN(val) {
+					val = 0.0
+				}
+				data[idx] = opts.ScatterData{Value: val, SymbolSize: 8}
+			}
+		}
+		scatter.AddSeries(name, data)
+	}
+	return scatter
+}
+
+// featureTypeColors colors coefficient paths by the type of their feature
+var featureTypeColors = map[feature.FeatureType]string{
+	feature.FeatureTypeChangepoint: "#5470c6",
+	feature.FeatureTypeSeasonality: "#91cc75",
+	feature.FeatureTypeEvent:       "#ee6666",
+	feature.FeatureTypeTime:        "#fac858",
+	feature.FeatureTypeRegressor:   "#9a60b4",
+}
+
+// LineCoefficientPath generates an echart line chart of the coefficient of every feature across the
+// lambdas of a Lasso fit colored by the feature type
+func LineCoefficientPath(path *forecast.CoefficientPath) *charts.Line {
+	line := charts.NewLine()
+	line.SetGlobalOptions(
+		charts.WithTitleOpts(
+			opts.Title{
+				Title:    "Coefficient Path",
+				Subtitle: "colored by feature type: changepoint (blue), seasonality (green), event (red), time (yellow)",
+			},
+		),
+		charts.WithXAxisOpts(
+			opts.XAxis{
+				Name: "lambda",
+			},
+		),
+		charts.WithTooltipOpts(
+			opts.Tooltip{
+				Trigger: "axis",
+			},
+		),
+		charts.WithLegendOpts(
+			opts.Legend{
+				Show: opts.Bool(false),
+			},
+		),
+	)
+	if path == nil {
+		return line
+	}
+
+	lambdas := make([]string, len(path.Lambdas))
+	for i, lambda := range path.Lambdas {
+		lambdas[i] = strconv.FormatFloat(lambda, 'g', 4, 64)
+	}
+	line.SetXAxis(lambdas)
+	for _, feat := range path.Features {
+		lineData := make([]opts.LineData, 0, len(feat.Coef))
+		for _, c := range feat.Coef {
+			lineData = append(lineData, opts.LineData{Value: handleNaN(c)})
+		}
+		color := featureTypeColors[feat.Type]
+		line.AddSeries(feat.Label, lineData,
+			charts.WithLineStyleOpts(opts.LineStyle{Color: color}),
+			charts.WithItemStyleOpts(opts.ItemStyle{Color: color}),
+		)
+	}
+	return line
+}
+
+// PlotOpts sets the horizon to forecast out. By default will use 10% of the training size assuming
+// even intervals between points and the first two points are used to infer the horizon interval.
+type PlotOpts struct {
+	HorizonCnt      int
+	HorizonInterval time.Duration
+
+	// Smooth optionally smooths the plotted fit and forecast along with their bands
+	Smooth *SmoothOptions
+}
+
+// PlotFit uses the Apache Echarts library to generate an html file showing the resulting fit,
+// model components, and fit residual
+func (f *Forecaster) PlotFit(w io.Writer, opt *PlotOpts) error {
+	fitChart, componentChart, residualChart, err := f.fitCharts(opt)
+	if err != nil {
+		return err
+	}
+
+	page := components.NewPage()
+	page.AddCharts(
+		fitChart,
+		componentChart,
+		residualChart,
+	)
+	return page.Render(w)
+}
+
+// fitCharts generates the fit and forecast, model component, and residual charts of the training data
+// extended by the plotted horizon
+func (f *Forecaster) fitCharts(opt *PlotOpts) (*charts.Line, *charts.Line, *charts.Line, error) {
+	td := f.TrainingData()
+	if td == nil || f.fitResults == nil {
+		return nil, nil, nil, ErrEmptyTimeDataset
+	}
+
+	horizonCnt := len(td.T) / 10
+	var horizonInterval time.Duration
+	if opt != nil {
+		horizonCnt = opt.HorizonCnt
+		horizonInterval = opt.HorizonInterval
+	}
+	if horizonCnt < 1 {
+		horizonCnt = 1
+	}
+	horizon, err := f.MakeFuturePeriods(horizonCnt, horizonInterval)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	t := make([]time.Time, len(td.T), len(td.T)+horizonCnt)
+	copy(t, td.T)
+	t = append(t, horizon...)
+
+	zpad := make([]float64, 0, horizonCnt)
+	for i := 0; i < horizonCnt; i++ {
+		zpad = append(zpad, math.NaN())
+	}
+
+	forecastRes, err := f.Predict(horizon)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("unable to predict with horizon, %w", err)
+	}
+
+	fitRes := f.fitResults
+	if opt != nil && opt.Smooth != nil {
+		fitRes, err = fitRes.Smooth(*opt.Smooth)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("unable to smooth fit results, %w", err)
+		}
+		forecastRes, err = forecastRes.Smooth(*opt.Smooth)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("unable to smooth forecast results, %w", err)
+		}
+	}
+
+	residuals := f.Residuals()
+	residuals = append(residuals, zpad...)
+
+	uncertainty := f.Uncertainty()
+	uncertainty = append(uncertainty, zpad...)
+
+	trendComp := f.TrendComponent()
+	trendComp = append(trendComp, forecastRes.SeriesComponents.Trend...)
+
+	seasonComp := f.SeasonalityComponent()
+	seasonComp = append(seasonComp, forecastRes.SeriesComponents.Seasonality...)
+
+	eventComp := f.EventComponent()
+	eventComp = append(eventComp, forecastRes.SeriesComponents.Event...)
+
+	residualChart := LineTSeries(
+		"Forecast Residual",
+		[]string{"Residual", "Uncertainty"},
+		t,
+		[][]float64{
+			residuals,
+			uncertainty,
+		},
+		len(td.T),
+	)
+
+	// mark annotated points at the residual against the original training values since removed and
+	// excluded points have no residual from the fit
+	if f.diagnostics != nil && f.fitResults != nil {
+		markers := make([]float64, len(t))
+		for i := range markers {
+			markers[i] = math.NaN()
+			if i < len(td.Y) {
+				markers[i] = td.Y[i] - f.fitResults.Forecast[i]
+			}
+		}
+		residualChart.Overlap(ScatterAnnotations(
+			t,
+			markers,
+			[]string{"Outlier", "Imputed", "Excluded"},
+			[][]int{
+				f.diagnostics.OutlierIndexes,
+				f.diagnostics.MissingIndexes,
+				f.diagnostics.ExcludedIndexes,
+			},
+		))
+	}
+
+	componentChart := LineTSeries(
+		"Forecast Components",
+		[]string{"Trend", "Seasonality", "Event"},
+		t,
+		[][]float64{
+			trendComp,
+			seasonComp,
+			eventComp,
+		},
+		len(td.T),
+	)
+	return LineForecaster(td, fitRes, forecastRes), componentChart, residualChart, nil
+}
+
+// PlotCoefficientPath uses the Apache Echarts library to generate an html file showing the coefficient
+// of every series feature across the configured lambdas. This requires RetainCoefficientPath to be set
+// in the series forecast options.
+func (f *Forecaster) PlotCoefficientPath(w io.Writer) error {
+	path := f.seriesForecast.CoefficientPath()
+	if path == nil {
+		return ErrNoCoefficientPath
+	}
+	page := components.NewPage()
+	page.AddCharts(LineCoefficientPath(path))
+	return page.Render(w)
+}
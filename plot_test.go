@@ -0,0 +1,117 @@
+//go:build !noplot
+
+package forecaster
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/forecast/options"
+	"github.com/aouyang1/go-forecaster/timedataset"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeExamplePlot renders the fit of an example to an html file
+func writeExamplePlot(f *Forecaster, filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return f.PlotFit(file, nil)
+}
+
+// assertPlotFitContains renders the fit and checks the html contains every input string
+func assertPlotFitContains(t *testing.T, f *Forecaster, contains ...string) {
+	var buf bytes.Buffer
+	require.Nil(t, f.PlotFit(&buf, nil))
+	for _, s := range contains {
+		assert.Contains(t, buf.String(), s)
+	}
+}
+
+func TestPlotCoefficientPath(t *testing.T) {
+	n := 3 * 24
+	tWin := timedataset.GenerateT(n, time.Hour, func() time.Time {
+		return time.Date(2024, 1, 4, 0, 0, 0, 0, time.UTC)
+	})
+	y := timedataset.GenerateConstY(n, 5.0).
+		Add(timedataset.GenerateWaveY(tWin, 2.0, 86400.0, 1.0, 0.0))
+
+	for _, retain := range []bool{false, true} {
+		opt := NewDefaultOptions()
+		opt.SeriesOptions.ForecastOptions.SeasonalityOptions.SeasonalityConfigs = []options.SeasonalityConfig{
+			options.NewDailySeasonalityConfig(2),
+		}
+		opt.SeriesOptions.ForecastOptions.Regularization = []float64{0.0, 1.0, 10.0}
+		opt.SeriesOptions.ForecastOptions.RetainCoefficientPath = retain
+
+		f, err := New(opt)
+		require.Nil(t, err)
+		require.Nil(t, f.Fit(tWin, y))
+
+		var buf bytes.Buffer
+		err = f.PlotCoefficientPath(&buf)
+		if !retain {
+			assert.ErrorIs(t, err, ErrNoCoefficientPath)
+			continue
+		}
+		require.Nil(t, err)
+		assert.Contains(t, buf.String(), "Coefficient Path")
+		assert.Contains(t, buf.String(), "seas_epoch_daily_01_sin")
+	}
+}
+
+func TestRenderReport(t *testing.T) {
+	n := 7 * 24
+	tWin := timedataset.GenerateT(n, time.Hour, func() time.Time {
+		return time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC)
+	})
+	y := timedataset.GenerateConstY(n, 5.0).
+		Add(timedataset.GenerateWaveY(tWin, 2.0, 86400.0, 1.0, 0.0)).
+		Add(timedataset.GenerateNoise(tWin, 0.3, 0.0, 86400.0, 1.0, 0.0))
+
+	f, err := New(nil)
+	require.Nil(t, err)
+
+	var buf bytes.Buffer
+	assert.ErrorIs(t, f.RenderReport(&buf, nil), ErrEmptyTimeDataset)
+
+	require.Nil(t, f.Fit(tWin, y))
+	require.Nil(t, f.RenderReport(&buf, &ReportOpts{Title: "Review", MaxLag: 24}))
+
+	out := buf.String()
+	for _, s := range []string{
+		"<title>Review</title>",
+		"echarts.min.js",
+		`href="#overview"`,
+		`id="scores"`,
+		"Forecast Fit",
+		"Forecast Components",
+		"Residual Autocorrelation",
+		"Residual Histogram",
+		"Residual Normal QQ",
+		"seas_epoch_daily_01_sin",
+		"Series MSE",
+	} {
+		assert.Contains(t, out, s)
+	}
+}
+
+func TestAutocorrelation(t *testing.T) {
+	alternating := make([]float64, 100)
+	for i := range alternating {
+		alternating[i] = float64(1 - 2*(i%2))
+	}
+	acf := autocorrelation(alternating, 2)
+	require.Len(t, acf, 2)
+	assert.InDelta(t, -0.99, acf[0], 1e-9)
+	assert.InDelta(t, 0.98, acf[1], 1e-9)
+
+	assert.Nil(t, autocorrelation([]float64{1}, 5))
+	assert.Equal(t, []float64{0, 0}, autocorrelation([]float64{2, 2, 2}, 5))
+}
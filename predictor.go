@@ -0,0 +1,72 @@
+package forecaster
+
+import (
+	"time"
+
+	"github.com/aouyang1/go-forecaster/errs"
+	"github.com/aouyang1/go-forecaster/forecast"
+)
+
+// Predictor is a compiled form of a trained forecaster for repeated predictions such as high QPS
+// serving. The series and uncertainty models are compiled into forecast predictors and their point
+// predictions are finished like Forecaster.Predict so the forecast and uncertainty bands are in the
+// original space of the series, i.e. the hourly calibration, trend uncertainty, bootstrap and quantile
+// bands, autoregressive correction, inverse transform and growth, and clipping are applied. The
+// results do not have the components of the series and uncertainty models.
+// A Predictor does not modify its model and is safe for concurrent use.
+type Predictor struct {
+	f           *Forecaster
+	series      *forecast.Predictor
+	uncertainty *forecast.Predictor
+}
+
+// NewPredictor compiles a trained forecaster model into a predictor
+func NewPredictor(m Model) (*Predictor, error) {
+	f, err := NewFromModel(m)
+	if err != nil {
+		return nil, err
+	}
+	series, err := forecast.NewPredictor(m.Series)
+	if err != nil {
+		return nil, errs.NewConfigError(errs.CodeInvalidModel, "unable to compile series model", err)
+	}
+	uncertainty, err := forecast.NewPredictor(m.Uncertainty)
+	if err != nil {
+		return nil, errs.NewConfigError(errs.CodeInvalidModel, "unable to compile uncertainty model", err)
+	}
+	return &Predictor{
+		f:           f,
+		series:      series,
+		uncertainty: uncertainty,
+	}, nil
+}
+
+// Compiled returns true if every feature of the series and uncertainty models is evaluated directly
+// by the predictor
+func (p *Predictor) Compiled() bool {
+	if p == nil {
+		return false
+	}
+	return p.series.Compiled() && p.uncertainty.Compiled()
+}
+
+// Predict predicts the forecast and uncertainty bands of the input times
+func (p *Predictor) Predict(t []time.Time) (*Results, error) {
+	if p == nil || p.f == nil {
+		return nil, forecast.ErrUninitializedForecast
+	}
+	seriesRes, err := p.series.Predict(t, nil)
+	if err != nil {
+		return nil, errs.NewPredictError(errs.CodePredictFailed, "unable to predict series forecasts", err)
+	}
+	uncertaintyRes, err := p.uncertainty.Predict(t, nil)
+	if err != nil {
+		return nil, errs.NewPredictError(errs.CodePredictFailed, "unable to predict uncertainty forecasts", err)
+	}
+
+	r := &Results{T: t}
+	if err := p.f.finishPrediction(r, seriesRes, uncertaintyRes, nil); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
@@ -0,0 +1,98 @@
+package forecaster
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/forecast/options"
+	"github.com/aouyang1/go-forecaster/timedataset"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPredictor(t *testing.T) {
+	n := 14 * 24
+	tWin := timedataset.GenerateT(n, time.Hour, func() time.Time {
+		return time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	})
+	y := make([]float64, n)
+	for i, tPnt := range tWin {
+		logit := -2 + 3*float64(i)/float64(n) + 0.5*math.Sin(2*math.Pi*float64(i)/24)
+		y[i] = 100 / (1 + math.Exp(-logit))
+		if wd := tPnt.Weekday(); wd == time.Saturday || wd == time.Sunday {
+			y[i] += 5
+		}
+	}
+	horizon := timedataset.GenerateT(7*24, time.Hour, func() time.Time {
+		return tWin[n-1].Add(time.Hour)
+	})
+
+	testData := map[string]struct {
+		opt func(*Options)
+	}{
+		"log transform": {
+			opt: func(opt *Options) {
+				opt.Transform = &TransformOptions{Method: TransformLog}
+			},
+		},
+		"logistic growth": {
+			opt: func(opt *Options) {
+				opt.Growth = &GrowthOptions{Method: GrowthLogistic, Cap: 110}
+			},
+		},
+		"clipped and calibrated": {
+			opt: func(opt *Options) {
+				opt.SetMaxValue(60.0)
+				opt.UncertaintyOptions.HourlyCalibration = true
+			},
+		},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			opt := &Options{
+				SeriesOptions: &SeriesOptions{
+					ForecastOptions: &options.Options{
+						ChangepointOptions: options.ChangepointOptions{
+							Changepoints: []options.Changepoint{options.NewChangepoint("trendstart", tWin[0])},
+						},
+						SeasonalityOptions: options.SeasonalityOptions{
+							SeasonalityConfigs: []options.SeasonalityConfig{options.NewDailySeasonalityConfig(2)},
+						},
+						WeekendOptions: options.WeekendOptions{Enabled: true},
+					},
+				},
+				UncertaintyOptions: &UncertaintyOptions{
+					ForecastOptions: &options.Options{},
+					ResidualWindow:  24,
+					ResidualZscore:  2.0,
+				},
+			}
+			td.opt(opt)
+			f, err := New(opt)
+			require.Nil(t, err)
+			require.Nil(t, f.Fit(tWin, y))
+			expected, err := f.Predict(horizon)
+			require.Nil(t, err)
+
+			m, err := f.Model()
+			require.Nil(t, err)
+			p, err := NewPredictor(m)
+			require.Nil(t, err)
+			assert.True(t, p.Compiled())
+
+			// the predictor finishes the predictions in the original space of the series
+			res, err := p.Predict(horizon)
+			require.Nil(t, err)
+			assert.Equal(t, horizon, res.T)
+			assert.InDeltaSlice(t, expected.Forecast, res.Forecast, 1e-6)
+			assert.InDeltaSlice(t, expected.Upper, res.Upper, 1e-6)
+			assert.InDeltaSlice(t, expected.Lower, res.Lower, 1e-6)
+		})
+	}
+
+	var p *Predictor
+	_, err := p.Predict(horizon)
+	assert.NotNil(t, err)
+}
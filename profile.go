@@ -0,0 +1,61 @@
+package forecaster
+
+import (
+	"fmt"
+	"time"
+)
+
+// WeeklyProfile is the fitted seasonal and weekend contribution over a canonical week. Values has 7 rows
+// from Monday to Sunday each with one column per resolution step starting at midnight.
+type WeeklyProfile struct {
+	Start      time.Time     `json:"start"`
+	Resolution time.Duration `json:"resolution"`
+	Values     [][]float64   `json:"values"`
+}
+
+// WeeklyProfile evaluates the fitted seasonal and weekend components of the series model over the last
+// full week, Monday through Sunday in the input location, before the training end time. Intercept, trend,
+// and other events are excluded so the profile can be used directly for capacity planning heatmaps. The
+// location defaults to UTC.
+func (f *Forecaster) WeeklyProfile(resolution time.Duration, loc *time.Location) (*WeeklyProfile, error) {
+	if resolution <= 0 || (24*time.Hour)%resolution != 0 {
+		return nil, fmt.Errorf("got resolution of %s, %w", resolution, ErrInvalidResolution)
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	model, err := f.seriesForecast.Model()
+	if err != nil {
+		return nil, fmt.Errorf("unable to get series model for weekly profile, %w", err)
+	}
+
+	// find the monday at midnight at least one week before the training end time
+	end := model.TrainEndTime.In(loc)
+	daysSinceMonday := (int(end.Weekday()) + 6) % 7
+	start := time.Date(end.Year(), end.Month(), end.Day()-daysSinceMonday-7, 0, 0, 0, 0, loc)
+
+	steps := int(24 * time.Hour / resolution)
+	t := make([]time.Time, 0, 7*steps)
+	for d := 0; d < 7; d++ {
+		dayStart := time.Date(start.Year(), start.Month(), start.Day()+d, 0, 0, 0, 0, loc)
+		for i := 0; i < steps; i++ {
+			t = append(t, dayStart.Add(time.Duration(i)*resolution))
+		}
+	}
+
+	profile, err := f.seriesForecast.SeasonalProfile(t)
+	if err != nil {
+		return nil, fmt.Errorf("unable to evaluate weekly profile, %w", err)
+	}
+
+	values := make([][]float64, 7)
+	for d := 0; d < 7; d++ {
+		values[d] = profile[d*steps : (d+1)*steps]
+	}
+	return &WeeklyProfile{
+		Start:      start,
+		Resolution: resolution,
+		Values:     values,
+	}, nil
+}
@@ -0,0 +1,47 @@
+package forecaster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/forecast/options"
+	"github.com/aouyang1/go-forecaster/timedataset"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWeeklyProfile(t *testing.T) {
+	nowFunc := func() time.Time { return time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC) }
+	tWin := timedataset.GenerateT(14*24*4, 15*time.Minute, nowFunc)
+	y := timedataset.GenerateConstY(len(tWin), 3.0).
+		Add(timedataset.GenerateWaveY(tWin, 7.2, 86400.0, 1.0, 0.0))
+
+	opt := NewDefaultOptions()
+	opt.SeriesOptions.ForecastOptions = &options.Options{
+		SeasonalityOptions: options.SeasonalityOptions{
+			SeasonalityConfigs: []options.SeasonalityConfig{
+				options.NewDailySeasonalityConfig(2),
+			},
+		},
+	}
+	f, err := New(opt)
+	require.Nil(t, err)
+	require.Nil(t, f.Fit(tWin, y))
+
+	_, err = f.WeeklyProfile(7*time.Hour, nil)
+	assert.ErrorIs(t, err, ErrInvalidResolution)
+
+	profile, err := f.WeeklyProfile(time.Hour, nil)
+	require.Nil(t, err)
+	assert.Equal(t, time.Monday, profile.Start.Weekday())
+	assert.True(t, profile.Start.Before(tWin[len(tWin)-1]))
+	require.Len(t, profile.Values, 7)
+
+	expected := timedataset.GenerateWaveY(
+		timedataset.GenerateT(24, time.Hour, func() time.Time { return profile.Start.Add(24 * time.Hour) }),
+		7.2, 86400.0, 1.0, 0.0,
+	)
+	for _, row := range profile.Values {
+		assert.InDeltaSlice(t, []float64(expected), row, 0.05)
+	}
+}
@@ -0,0 +1,141 @@
+package forecaster
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/errs"
+	"github.com/aouyang1/go-forecaster/forecast"
+	"github.com/aouyang1/go-forecaster/forecast/options"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+var (
+	ErrInvalidBandQuantiles = errs.NewConfigError(errs.CodeInvalidOption, "band quantiles must satisfy 0 < lower < upper < 1", nil)
+	ErrNoModelQuantiles     = errs.NewConfigError(errs.CodeInvalidModel, "quantile uncertainty method has no lower and upper quantile models", nil)
+)
+
+// bandQuantiles returns the lower and upper quantiles of the bootstrap and quantile bands. If neither
+// LowerQuantile nor UpperQuantile is set the bands cover the same central probability as
+// ResidualZscore standard deviations of a normal distribution.
+func (u *UncertaintyOptions) bandQuantiles() (float64, float64, error) {
+	if u.LowerQuantile == 0 && u.UpperQuantile == 0 {
+		lower := distuv.UnitNormal.CDF(-math.Abs(u.ResidualZscore))
+		return lower, 1.0 - lower, nil
+	}
+	if !(u.LowerQuantile > 0 && u.LowerQuantile < u.UpperQuantile && u.UpperQuantile < 1) {
+		return 0, 0, fmt.Errorf("lower of %.3f and upper of %.3f, %w", u.LowerQuantile, u.UpperQuantile, ErrInvalidBandQuantiles)
+	}
+	return u.LowerQuantile, u.UpperQuantile, nil
+}
+
+// fitQuantile fits a quantile regression of the deviation from the forecast with the features of the
+// uncertainty model
+func (f *Forecaster) fitQuantile(ctx context.Context, t []time.Time, deviation, weights []float64, q float64) (*forecast.Forecast, *forecast.Model, error) {
+	opt := f.opt.UncertaintyOptions.ForecastOptions.Copy()
+	opt.Regression = options.RegressionQuantile
+	opt.Quantile = q
+	opt.TrainingHooks = f.opt.TrainingHooks.forStage(TrainingStageQuantile)
+
+	quantile, err := forecast.New(opt)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to initialize quantile %.3f, %w", q, err)
+	}
+	if weights != nil {
+		err = quantile.FitWeightedCtx(ctx, t, deviation, weights)
+	} else {
+		err = quantile.FitCtx(ctx, t, deviation)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to fit quantile %.3f, %w", q, err)
+	}
+	model, err := quantile.Model()
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to fetch model of quantile %.3f, %w", q, err)
+	}
+	return quantile, &model, nil
+}
+
+// fitQuantileUncertainty fits the lower and upper quantiles of the series residual directly with
+// quantile regressions and persists both models so the quantile bands can be predicted from a loaded
+// model
+func (f *Forecaster) fitQuantileUncertainty(ctx context.Context, t []time.Time, residual, weights []float64) error {
+	u := f.opt.UncertaintyOptions
+	u.LowerQuantileModel = nil
+	u.UpperQuantileModel = nil
+	f.lowerQuantile = nil
+	f.upperQuantile = nil
+	lowerQ, upperQ, err := u.bandQuantiles()
+	if err != nil {
+		return err
+	}
+	if u.Method != UncertaintyMethodQuantile {
+		return nil
+	}
+
+	// the series residual is the forecast minus the observation so the quantiles are fit on the
+	// deviation of the observations from the forecast instead
+	deviation := make([]float64, len(residual))
+	for i, r := range residual {
+		deviation[i] = -r
+	}
+	lower, lowerModel, err := f.fitQuantile(ctx, t, deviation, weights, lowerQ)
+	if err != nil {
+		return err
+	}
+	upper, upperModel, err := f.fitQuantile(ctx, t, deviation, weights, upperQ)
+	if err != nil {
+		return err
+	}
+	u.LowerQuantileModel, u.UpperQuantileModel = lowerModel, upperModel
+	f.lowerQuantile, f.upperQuantile = lower, upper
+	return nil
+}
+
+// loadQuantiles loads the persisted lower and upper models of the quantile method
+func (f *Forecaster) loadQuantiles() error {
+	f.lowerQuantile = nil
+	f.upperQuantile = nil
+	u := f.opt.UncertaintyOptions
+	if u.Method != UncertaintyMethodQuantile {
+		return nil
+	}
+	if u.LowerQuantileModel == nil || u.UpperQuantileModel == nil {
+		return ErrNoModelQuantiles
+	}
+	lower, err := forecast.NewFromModel(*u.LowerQuantileModel)
+	if err != nil {
+		return fmt.Errorf("unable to load lower quantile, %w", err)
+	}
+	upper, err := forecast.NewFromModel(*u.UpperQuantileModel)
+	if err != nil {
+		return fmt.Errorf("unable to load upper quantile, %w", err)
+	}
+	f.lowerQuantile, f.upperQuantile = lower, upper
+	return nil
+}
+
+// quantileBands returns the distance of the upper and lower quantile bands from the forecast. A
+// quantile predicted on the wrong side of the forecast collapses its band onto the forecast.
+func (f *Forecaster) quantileBands(t []time.Time) ([]float64, []float64, error) {
+	if f.lowerQuantile == nil || f.upperQuantile == nil {
+		return nil, nil, ErrNoModelQuantiles
+	}
+	lowerRes, _, err := f.lowerQuantile.Predict(t)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to predict lower quantile, %w", err)
+	}
+	upperRes, _, err := f.upperQuantile.Predict(t)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to predict upper quantile, %w", err)
+	}
+	upper := make([]float64, len(t))
+	lower := make([]float64, len(t))
+	for i := range t {
+		upper[i] = math.Max(upperRes[i], 0)
+		lower[i] = math.Max(-lowerRes[i], 0)
+	}
+	return upper, lower, nil
+}
@@ -0,0 +1,113 @@
+package forecaster
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/forecast/options"
+	"github.com/aouyang1/go-forecaster/timedataset"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFitQuantileUncertainty(t *testing.T) {
+	n := 8 * 24
+	tWin := timedataset.GenerateT(n, time.Hour, func() time.Time {
+		return time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC)
+	})
+	// right skewed exponential noise so the upper band should be much wider than the lower band
+	rng := rand.New(rand.NewSource(5))
+	y := make([]float64, n)
+	for i := range y {
+		y[i] = 10.0 + rng.ExpFloat64()
+	}
+
+	testData := map[string]struct {
+		lower float64
+		upper float64
+		err   error
+	}{
+		"zscore quantiles":     {},
+		"explicit quantiles":   {lower: 0.05, upper: 0.95},
+		"inverted quantiles":   {lower: 0.95, upper: 0.05, err: ErrInvalidBandQuantiles},
+		"out of range":         {lower: 0.05, upper: 1.0, err: ErrInvalidBandQuantiles},
+		"only lower quantile":  {lower: 0.05, err: ErrInvalidBandQuantiles},
+		"zero width quantiles": {lower: 0.5, upper: 0.5, err: ErrInvalidBandQuantiles},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			opt := &Options{
+				SeriesOptions: &SeriesOptions{
+					ForecastOptions: &options.Options{
+						Regularization: []float64{0.0},
+					},
+				},
+				UncertaintyOptions: &UncertaintyOptions{
+					ForecastOptions: &options.Options{},
+					ResidualWindow:  12,
+					ResidualZscore:  1.645,
+					Method:          UncertaintyMethodQuantile,
+					LowerQuantile:   td.lower,
+					UpperQuantile:   td.upper,
+				},
+			}
+			f, err := New(opt)
+			require.Nil(t, err)
+			err = f.Fit(tWin, y)
+			if td.err != nil {
+				assert.ErrorIs(t, err, td.err)
+				return
+			}
+			require.Nil(t, err)
+
+			u := opt.UncertaintyOptions
+			require.NotNil(t, u.LowerQuantileModel)
+			require.NotNil(t, u.UpperQuantileModel)
+
+			// the bands cover about the central 90% of the training points and are asymmetric
+			res := f.FitResults()
+			var covered int
+			for i := range y {
+				upperDev := res.Upper[i] - res.Forecast[i]
+				lowerDev := res.Forecast[i] - res.Lower[i]
+				assert.Greater(t, upperDev, 1.5*lowerDev)
+				if y[i] >= res.Lower[i] && y[i] <= res.Upper[i] {
+					covered++
+				}
+			}
+			assert.InDelta(t, 0.9, float64(covered)/float64(n), 0.05)
+
+			// the quantile models are persisted with the model
+			model, err := f.Model()
+			require.Nil(t, err)
+			loaded, err := NewFromModel(model)
+			require.Nil(t, err)
+			horizon := []time.Time{tWin[n-1].Add(time.Hour), tWin[n-1].Add(7 * 24 * time.Hour)}
+			expected, err := f.Predict(horizon)
+			require.Nil(t, err)
+			loadedRes, err := loaded.Predict(horizon)
+			require.Nil(t, err)
+			assert.InDeltaSlice(t, expected.Upper, loadedRes.Upper, 1e-9)
+			assert.InDeltaSlice(t, expected.Lower, loadedRes.Lower, 1e-9)
+		})
+	}
+}
+
+func TestNewFromModelMissingQuantiles(t *testing.T) {
+	f, err := New(nil)
+	require.Nil(t, err)
+	tWin := timedataset.GenerateT(48, time.Hour, time.Now)
+	y := make([]float64, len(tWin))
+	for i := range y {
+		y[i] = float64(i % 5)
+	}
+	require.Nil(t, f.Fit(tWin, y))
+
+	model, err := f.Model()
+	require.Nil(t, err)
+	model.Options.UncertaintyOptions.Method = UncertaintyMethodQuantile
+	_, err = NewFromModel(model)
+	assert.ErrorIs(t, err, ErrNoModelQuantiles)
+}
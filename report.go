@@ -0,0 +1,465 @@
+//go:build !noplot
+
+package forecaster
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/aouyang1/go-forecaster/forecast"
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/opts"
+	"github.com/go-echarts/go-echarts/v2/render"
+	"gonum.org/v1/gonum/stat"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+const (
+	DefaultReportTitle   = "Forecast Report"
+	DefaultReportMaxLag  = 48
+	DefaultReportNumBins = 30
+)
+
+// ReportOpts configures the model review report. Plot sets the forecast horizon and smoothing of the
+// overview charts, MaxLag the number of residual autocorrelation lags, and NumBins the number of
+// residual histogram bins. Every option is defaulted if unset.
+type ReportOpts struct {
+	Title   string
+	Plot    *PlotOpts
+	MaxLag  int
+	NumBins int
+}
+
+// reportChart is a rendered chart snippet embedded in a report page
+type reportChart struct {
+	Element template.HTML
+	Script  template.HTML
+}
+
+// reportCoef is a row of the coefficient table of a report
+type reportCoef struct {
+	Model string
+	Type  string
+	Label string
+	Value string
+}
+
+// reportScore is a row of the score summary of a report
+type reportScore struct {
+	Name  string
+	Value string
+}
+
+// reportSection is a page of the report listed in the navigation
+type reportSection struct {
+	ID     string
+	Title  string
+	Charts []reportChart
+	Coefs  []reportCoef
+	Scores []reportScore
+}
+
+type reportData struct {
+	Title    string
+	JSAssets []string
+	Sections []reportSection
+}
+
+var reportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="utf-8">
+    <title>{{ .Title }}</title>
+{{- range .JSAssets }}
+    <script src="{{ . }}"></script>
+{{- end }}
+    <style>
+        body { font-family: sans-serif; margin: 0; }
+        nav { position: sticky; top: 0; background: #f5f5f5; padding: 8px 16px; border-bottom: 1px solid #ddd; }
+        nav a { margin-right: 16px; }
+        section { padding: 16px; border-bottom: 1px solid #ddd; }
+        table { border-collapse: collapse; }
+        th, td { border: 1px solid #ddd; padding: 4px 8px; text-align: left; }
+        td.value { text-align: right; font-family: monospace; }
+        .item { margin: auto; }
+    </style>
+</head>
+<body>
+<nav>
+    <strong>{{ .Title }}</strong>
+{{- range .Sections }}
+    <a href="#{{ .ID }}">{{ .Title }}</a>
+{{- end }}
+</nav>
+{{- range .Sections }}
+<section id="{{ .ID }}">
+    <h2>{{ .Title }}</h2>
+{{- if .Scores }}
+    <table>
+{{- range .Scores }}
+        <tr><th>{{ .Name }}</th><td class="value">{{ .Value }}</td></tr>
+{{- end }}
+    </table>
+{{- end }}
+{{- if .Coefs }}
+    <table>
+        <tr><th>Model</th><th>Type</th><th>Feature</th><th>Value</th></tr>
+{{- range .Coefs }}
+        <tr><td>{{ .Model }}</td><td>{{ .Type }}</td><td>{{ .Label }}</td><td class="value">{{ .Value }}</td></tr>
+{{- end }}
+    </table>
+{{- end }}
+{{- range .Charts }}
+    {{ .Element }}
+    {{ .Script }}
+{{- end }}
+</section>
+{{- end }}
+</body>
+</html>
+`))
+
+// RenderReport writes a single HTML report for reviewing a fit model. The report is split into pages
+// for the fit overview, model components, residual diagnostics including the autocorrelation,
+// histogram, and normal QQ plot of the residuals, the coefficient table, and the score summary.
+func (f *Forecaster) RenderReport(w io.Writer, opt *ReportOpts) error {
+	if opt == nil {
+		opt = &ReportOpts{}
+	}
+	title := opt.Title
+	if title == "" {
+		title = DefaultReportTitle
+	}
+	maxLag := opt.MaxLag
+	if maxLag <= 0 {
+		maxLag = DefaultReportMaxLag
+	}
+	numBins := opt.NumBins
+	if numBins <= 0 {
+		numBins = DefaultReportNumBins
+	}
+
+	fitChart, componentChart, residualChart, err := f.fitCharts(opt.Plot)
+	if err != nil {
+		return err
+	}
+	model, err := f.Model()
+	if err != nil {
+		return err
+	}
+
+	residuals := make([]float64, 0, len(f.residual))
+	for _, r := range f.residual {
+		if !math.IsNaN(r) {
+			residuals = append(residuals, r)
+		}
+	}
+
+	data := reportData{Title: title}
+	assets := make(map[string]struct{})
+	snippets := func(renderers ...render.Renderer) []reportChart {
+		res := make([]reportChart, 0, len(renderers))
+		for _, r := range renderers {
+			snippet := r.RenderSnippet()
+			res = append(res, reportChart{
+				Element: template.HTML(snippet.Element),
+				Script:  template.HTML(snippet.Script),
+			})
+		}
+		return res
+	}
+	addAssets := func(chartAssets ...[]string) {
+		for _, values := range chartAssets {
+			for _, asset := range values {
+				if _, exists := assets[asset]; exists {
+					continue
+				}
+				assets[asset] = struct{}{}
+				data.JSAssets = append(data.JSAssets, asset)
+			}
+		}
+	}
+
+	acfChart := BarAutocorrelation(residuals, maxLag)
+	histChart := BarHistogram("Residual Histogram", residuals, numBins)
+	qqChart := ScatterNormalQQ(residuals)
+	data.Sections = []reportSection{
+		{ID: "overview", Title: "Fit Overview", Charts: snippets(fitChart)},
+		{ID: "components", Title: "Components", Charts: snippets(componentChart)},
+		{ID: "residuals", Title: "Residual Diagnostics", Charts: snippets(residualChart, acfChart, histChart, qqChart)},
+		{ID: "coefficients", Title: "Coefficients", Coefs: reportCoefficients(model)},
+		{ID: "scores", Title: "Scores", Scores: f.reportScores(model, residuals)},
+	}
+	addAssets(
+		fitChart.JSAssets.Values, componentChart.JSAssets.Values, residualChart.JSAssets.Values,
+		acfChart.JSAssets.Values, histChart.JSAssets.Values, qqChart.JSAssets.Values,
+	)
+	return reportTemplate.Execute(w, data)
+}
+
+// reportCoefficients lists the intercept and coefficients of the series and uncertainty models
+func reportCoefficients(model Model) []reportCoef {
+	var rows []reportCoef
+	for _, m := range []struct {
+		name  string
+		model forecast.Model
+	}{
+		{"series", model.Series},
+		{"uncertainty", model.Uncertainty},
+	} {
+		rows = append(rows, reportCoef{
+			Model: m.name,
+			Type:  "intercept",
+			Value: strconv.FormatFloat(m.model.Weights.Intercept, 'g', 6, 64),
+		})
+		for _, fw := range m.model.Weights.Coef {
+			label := fmt.Sprint(fw.Labels)
+			if feat, err := fw.ToFeature(); err == nil {
+				label = feat.String()
+			}
+			rows = append(rows, reportCoef{
+				Model: m.name,
+				Type:  string(fw.Type),
+				Label: label,
+				Value: strconv.FormatFloat(fw.Value, 'g', 6, 64),
+			})
+		}
+	}
+	return rows
+}
+
+// reportScores summarizes the training data, fit scores, residuals, and fit diagnostics
+func (f *Forecaster) reportScores(model Model, residuals []float64) []reportScore {
+	td := f.TrainingData()
+	scores := []reportScore{
+		{"Training Start", td.T[0].String()},
+		{"Training End", td.T[len(td.T)-1].String()},
+		{"Training Points", strconv.Itoa(len(td.T))},
+	}
+	for _, m := range []struct {
+		name   string
+		scores *forecast.Scores
+	}{
+		{"Series", model.Series.Scores},
+		{"Uncertainty", model.Uncertainty.Scores},
+	} {
+		if m.scores == nil {
+			continue
+		}
+		scores = append(scores,
+			reportScore{m.name + " MAPE", strconv.FormatFloat(m.scores.MAPE, 'f', 4, 64)},
+			reportScore{m.name + " MSE", strconv.FormatFloat(m.scores.MSE, 'f', 4, 64)},
+			reportScore{m.name + " R2", strconv.FormatFloat(m.scores.R2, 'f', 4, 64)},
+			reportScore{m.name + " MAE", strconv.FormatFloat(m.scores.MAE, 'f', 4, 64)},
+			reportScore{m.name + " sMAPE", strconv.FormatFloat(m.scores.SMAPE, 'f', 4, 64)},
+			reportScore{m.name + " MASE", strconv.FormatFloat(m.scores.MASE, 'f', 4, 64)},
+		)
+	}
+	if s := model.Scores; s != nil {
+		scores = append(scores,
+			reportScore{"Coverage", strconv.FormatFloat(s.Coverage, 'f', 4, 64)},
+			reportScore{"Pinball Loss", strconv.FormatFloat(s.Pinball, 'f', 4, 64)},
+		)
+	}
+	if len(residuals) > 0 {
+		mean, std := stat.MeanStdDev(residuals, nil)
+		scores = append(scores,
+			reportScore{"Residual Mean", strconv.FormatFloat(mean, 'f', 4, 64)},
+			reportScore{"Residual Std Dev", strconv.FormatFloat(std, 'f', 4, 64)},
+		)
+	}
+	if d := f.diagnostics; d != nil {
+		scores = append(scores,
+			reportScore{"Outliers Removed", strconv.Itoa(d.OutliersRemoved)},
+			reportScore{"Missing Points", strconv.Itoa(len(d.MissingIndexes))},
+			reportScore{"Excluded Points", strconv.Itoa(len(d.ExcludedIndexes))},
+		)
+	}
+	return scores
+}
+
+// autocorrelation returns the sample autocorrelation of the series for lags 1 through maxLag
+func autocorrelation(y []float64, maxLag int) []float64 {
+	if maxLag >= len(y) {
+		maxLag = len(y) - 1
+	}
+	if maxLag < 1 {
+		return nil
+	}
+	mean := stat.Mean(y, nil)
+	var denom float64
+	for _, v := range y {
+		denom += (v - mean) * (v - mean)
+	}
+	acf := make([]float64, maxLag)
+	if denom == 0 {
+		return acf
+	}
+	for lag := 1; lag <= maxLag; lag++ {
+		var num float64
+		for i := lag; i < len(y); i++ {
+			num += (y[i] - mean) * (y[i-lag] - mean)
+		}
+		acf[lag-1] = num / denom
+	}
+	return acf
+}
+
+// BarAutocorrelation generates an echart bar chart of the autocorrelation of the series for each lag up
+// to maxLag. Bars beyond the 95% band of white noise indicate structure left in the residual.
+func BarAutocorrelation(y []float64, maxLag int) *charts.Bar {
+	acf := autocorrelation(y, maxLag)
+	var band float64
+	if len(y) > 0 {
+		band = 1.96 / math.Sqrt(float64(len(y)))
+	}
+
+	bar := charts.NewBar()
+	bar.SetGlobalOptions(
+		charts.WithTitleOpts(
+			opts.Title{
+				Title:    "Residual Autocorrelation",
+				Subtitle: fmt.Sprintf("95%% white noise band: +/-%.3f", band),
+			},
+		),
+		charts.WithXAxisOpts(
+			opts.XAxis{
+				Name: "lag",
+			},
+		),
+		charts.WithTooltipOpts(
+			opts.Tooltip{
+				Trigger: "axis",
+			},
+		),
+	)
+
+	lags := make([]int, len(acf))
+	barData := make([]opts.BarData, len(acf))
+	upper := make([]opts.LineData, len(acf))
+	lower := make([]opts.LineData, len(acf))
+	for i, v := range acf {
+		lags[i] = i + 1
+		barData[i] = opts.BarData{Value: v}
+		upper[i] = opts.LineData{Value: band}
+		lower[i] = opts.LineData{Value: -band}
+	}
+	bar.SetXAxis(lags).AddSeries("ACF", barData)
+
+	bandLine := charts.NewLine()
+	bandLine.SetXAxis(lags).
+		AddSeries("Upper Band", upper, charts.WithLineStyleOpts(opts.LineStyle{Color: "gray", Type: "dashed"})).
+		AddSeries("Lower Band", lower, charts.WithLineStyleOpts(opts.LineStyle{Color: "gray", Type: "dashed"}))
+	bar.Overlap(bandLine)
+	return bar
+}
+
+// BarHistogram generates an echart bar chart of the counts of the non-NaN values in numBins evenly
+// sized bins between the smallest and largest value
+func BarHistogram(title string, y []float64, numBins int) *charts.Bar {
+	bar := charts.NewBar()
+	bar.SetGlobalOptions(
+		charts.WithTitleOpts(
+			opts.Title{
+				Title: title,
+			},
+		),
+		charts.WithTooltipOpts(
+			opts.Tooltip{
+				Trigger: "axis",
+			},
+		),
+	)
+
+	vals := make([]float64, 0, len(y))
+	for _, v := range y {
+		if !math.IsNaN(v) {
+			vals = append(vals, v)
+		}
+	}
+	if len(vals) == 0 || numBins < 1 {
+		return bar
+	}
+
+	lo, hi := vals[0], vals[0]
+	for _, v := range vals {
+		lo = math.Min(lo, v)
+		hi = math.Max(hi, v)
+	}
+	width := (hi - lo) / float64(numBins)
+	counts := make([]int, numBins)
+	for _, v := range vals {
+		idx := numBins - 1
+		if width > 0 {
+			idx = int((v - lo) / width)
+		}
+		if idx >= numBins {
+			idx = numBins - 1
+		}
+		counts[idx]++
+	}
+
+	centers := make([]string, numBins)
+	barData := make([]opts.BarData, numBins)
+	for i, c := range counts {
+		centers[i] = strconv.FormatFloat(lo+(float64(i)+0.5)*width, 'g', 4, 64)
+		barData[i] = opts.BarData{Value: c}
+	}
+	bar.SetXAxis(centers).AddSeries("Count", barData)
+	return bar
+}
+
+// ScatterNormalQQ generates an echart scatter chart of the standardized sorted values against the
+// quantiles of the standard normal distribution. Points along the diagonal indicate normally distributed
+// values.
+func ScatterNormalQQ(y []float64) *charts.Scatter {
+	scatter := charts.NewScatter()
+	scatter.SetGlobalOptions(
+		charts.WithTitleOpts(
+			opts.Title{
+				Title:    "Residual Normal QQ",
+				Subtitle: "standardized residual quantiles against standard normal quantiles",
+			},
+		),
+		charts.WithXAxisOpts(
+			opts.XAxis{
+				Name: "normal",
+				Type: "value",
+			},
+		),
+		charts.WithYAxisOpts(
+			opts.YAxis{
+				Name: "residual",
+				Type: "value",
+			},
+		),
+	)
+
+	vals := make([]float64, 0, len(y))
+	for _, v := range y {
+		if !math.IsNaN(v) {
+			vals = append(vals, v)
+		}
+	}
+	if len(vals) < 2 {
+		return scatter
+	}
+	sort.Float64s(vals)
+	mean, std := stat.MeanStdDev(vals, nil)
+	if std == 0 {
+		std = 1.0
+	}
+
+	n := float64(len(vals))
+	points := make([]opts.ScatterData, len(vals))
+	for i, v := range vals {
+		q := distuv.UnitNormal.Quantile((float64(i) + 0.5) / n)
+		points[i] = opts.ScatterData{Value: []float64{q, (v - mean) / std}, SymbolSize: 4}
+	}
+	scatter.AddSeries("Residual", points)
+	return scatter
+}
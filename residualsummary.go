@@ -0,0 +1,281 @@
+package forecaster
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/errs"
+	"github.com/aouyang1/go-forecaster/forecast"
+	"github.com/aouyang1/go-forecaster/timedataset"
+	"gonum.org/v1/gonum/stat"
+)
+
+var (
+	ErrUnknownResidualPersistence = errs.NewConfigError(errs.CodeInvalidOption, "unknown residual persistence", nil)
+	ErrInvalidResidualLevel       = errs.NewConfigError(errs.CodeInvalidOption, "residual threshold level must be in the range [0, 1]", nil)
+	ErrMismatchedTrainingSeries   = errs.NewDataError(errs.CodeLengthMismatch, "persisted training series have different lengths", nil)
+)
+
+// ResidualPersistence selects how much of the training residuals is serialized with the model
+type ResidualPersistence string
+
+const (
+	// ResidualPersistenceNone does not serialize the training residuals. This is the default if unset.
+	ResidualPersistenceNone ResidualPersistence = "none"
+
+	// ResidualPersistenceSummary serializes the summary statistics and quantile sketch of the training
+	// residuals and uncertainty
+	ResidualPersistenceSummary ResidualPersistence = "summary"
+
+	// ResidualPersistenceSeries serializes the summary along with the training times, observations,
+	// residuals, and uncertainty so Residuals, Uncertainty, TrainingData, FitResults, and the fit
+	// components are available on a loaded model
+	ResidualPersistenceSeries ResidualPersistence = "series"
+)
+
+// ResidualSummaryLevels are the quantile levels of the sketch of every summarized series. The first and
+// last levels are the minimum and maximum.
+var ResidualSummaryLevels = []float64{
+	0, 0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 0.75, 0.9, 0.95, 0.975, 0.99, 0.995, 0.999, 1,
+}
+
+// NaNFloats is a series of values which encodes NaN values as null in json
+type NaNFloats []float64
+
+// MarshalJSON encodes the series writing NaN and infinite values as null
+func (s NaNFloats) MarshalJSON() ([]byte, error) {
+	if s == nil {
+		return []byte("null"), nil
+	}
+	out := make([]*float64, len(s))
+	for i := range s {
+		if !math.IsNaN(s[i]) && !math.IsInf(s[i], 0) {
+			out[i] = &s[i]
+		}
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON decodes the series reading null values as NaN
+func (s *NaNFloats) UnmarshalJSON(data []byte) error {
+	var in []*float64
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	if in == nil {
+		*s = nil
+		return nil
+	}
+	res := make(NaNFloats, len(in))
+	for i, v := range in {
+		res[i] = math.NaN()
+		if v != nil {
+			res[i] = *v
+		}
+	}
+	*s = res
+	return nil
+}
+
+// SeriesSummary is a compact sketch of the distribution of a series. Missing counts the NaN values
+// which are excluded from the statistics. Quantiles are the values at each of the Levels.
+type SeriesSummary struct {
+	Count     int       `json:"count"`
+	Missing   int       `json:"missing"`
+	Mean      float64   `json:"mean"`
+	StdDev    float64   `json:"std_dev"`
+	MAD       float64   `json:"median_absolute_deviation"`
+	Levels    []float64 `json:"levels,omitempty"`
+	Quantiles []float64 `json:"quantiles,omitempty"`
+}
+
+// summarizeSeries computes the summary statistics and quantile sketch of the non NaN values
+func summarizeSeries(x []float64) SeriesSummary {
+	obs := make([]float64, 0, len(x))
+	for _, v := range x {
+		if !math.IsNaN(v) {
+			obs = append(obs, v)
+		}
+	}
+	s := SeriesSummary{
+		Count:   len(obs),
+		Missing: len(x) - len(obs),
+	}
+	if len(obs) == 0 {
+		return s
+	}
+	slices.Sort(obs)
+	s.Mean = stat.Mean(obs, nil)
+	if len(obs) > 1 {
+		s.StdDev = stat.StdDev(obs, nil)
+	}
+
+	s.Levels = make([]float64, len(ResidualSummaryLevels))
+	copy(s.Levels, ResidualSummaryLevels)
+	s.Quantiles = make([]float64, len(s.Levels))
+	for i, p := range s.Levels {
+		s.Quantiles[i] = sortedQuantile(obs, p)
+	}
+
+	median := sortedQuantile(obs, 0.5)
+	dev := make([]float64, len(obs))
+	for i, v := range obs {
+		dev[i] = math.Abs(v - median)
+	}
+	slices.Sort(dev)
+	s.MAD = sortedQuantile(dev, 0.5)
+	return s
+}
+
+// sortedQuantile returns the linearly interpolated quantile of the sorted values
+func sortedQuantile(sorted []float64, p float64) float64 {
+	pos := p * float64(len(sorted)-1)
+	lo := int(math.Floor(pos))
+	if lo >= len(sorted)-1 {
+		return sorted[len(sorted)-1]
+	}
+	frac := pos - float64(lo)
+	return sorted[lo] + frac*(sorted[lo+1]-sorted[lo])
+}
+
+// Quantile returns the value at the level interpolating linearly between the levels of the sketch. NaN
+// is returned if the series had no observations or the level is outside [0, 1].
+func (s SeriesSummary) Quantile(p float64) float64 {
+	if len(s.Levels) == 0 || len(s.Levels) != len(s.Quantiles) || p < s.Levels[0] || p > s.Levels[len(s.Levels)-1] {
+		return math.NaN()
+	}
+	for i := 1; i < len(s.Levels); i++ {
+		if p > s.Levels[i] {
+			continue
+		}
+		span := s.Levels[i] - s.Levels[i-1]
+		if span <= 0 {
+			return s.Quantiles[i]
+		}
+		frac := (p - s.Levels[i-1]) / span
+		return s.Quantiles[i-1] + frac*(s.Quantiles[i]-s.Quantiles[i-1])
+	}
+	return s.Quantiles[0]
+}
+
+// TrainingSeries is the training data of the fit with the residual of the series fit and the
+// uncertainty series aligned with the training times. Removed outliers and missing points have NaN
+// residuals.
+type TrainingSeries struct {
+	T           []time.Time `json:"time"`
+	Y           NaNFloats   `json:"y"`
+	Residual    NaNFloats   `json:"residual"`
+	Uncertainty NaNFloats   `json:"uncertainty"`
+}
+
+// ResidualSummary summarizes the residual of the series fit and the uncertainty series of the
+// training data. Series is only set if persisting the full series.
+type ResidualSummary struct {
+	Residual    SeriesSummary   `json:"residual"`
+	Uncertainty SeriesSummary   `json:"uncertainty"`
+	Series      *TrainingSeries `json:"series,omitempty"`
+}
+
+// Threshold returns the lower and upper residual bounds containing the central level fraction of the
+// training residuals. Observations whose deviation from the forecast is outside the bounds are as
+// extreme as the tails of the training residuals.
+func (s *ResidualSummary) Threshold(level float64) (float64, float64, error) {
+	if level < 0 || level > 1 || math.IsNaN(level) {
+		return 0, 0, fmt.Errorf("level of %.3f, %w", level, ErrInvalidResidualLevel)
+	}
+	if s == nil || s.Residual.Count == 0 {
+		return 0, 0, ErrInsufficientResidual
+	}
+	return s.Residual.Quantile((1 - level) / 2), s.Residual.Quantile((1 + level) / 2), nil
+}
+
+// newResidualSummary summarizes the training residual and uncertainty keeping the training series if
+// persisting the full series
+func newResidualSummary(persistence ResidualPersistence, td *timedataset.TimeDataset, residual, uncertainty []float64) (*ResidualSummary, error) {
+	switch persistence {
+	case "", ResidualPersistenceNone:
+		return nil, nil
+	case ResidualPersistenceSummary, ResidualPersistenceSeries:
+	default:
+		return nil, fmt.Errorf("%q, %w", persistence, ErrUnknownResidualPersistence)
+	}
+	if residual == nil {
+		return nil, nil
+	}
+
+	s := &ResidualSummary{
+		Residual:    summarizeSeries(residual),
+		Uncertainty: summarizeSeries(uncertainty),
+	}
+	if persistence == ResidualPersistenceSeries && td != nil {
+		s.Series = &TrainingSeries{
+			T:           slices.Clone(td.T),
+			Y:           slices.Clone(td.Y),
+			Residual:    slices.Clone(residual),
+			Uncertainty: slices.Clone(uncertainty),
+		}
+	}
+	return s, nil
+}
+
+// ResidualSummary returns the summary of the training residual and uncertainty. This is computed from
+// the last fit or loaded from a model persisting its residuals and is nil otherwise. The training
+// series is only included if persisting the full series.
+func (f *Forecaster) ResidualSummary() (*ResidualSummary, error) {
+	if f.residual == nil {
+		return f.residualSummary, nil
+	}
+	persistence := f.opt.ResidualPersistence
+	if persistence == "" || persistence == ResidualPersistenceNone {
+		persistence = ResidualPersistenceSummary
+	}
+	return newResidualSummary(persistence, f.fitTrainingData, f.residual, f.uncertainty)
+}
+
+// persistedResiduals returns the residual summary serialized with the model. Models loaded without
+// their training series keep the summary they were loaded with.
+func (f *Forecaster) persistedResiduals() (*ResidualSummary, error) {
+	if f.residual == nil {
+		if f.opt.ResidualPersistence == "" || f.opt.ResidualPersistence == ResidualPersistenceNone {
+			return nil, nil
+		}
+		return f.residualSummary, nil
+	}
+	return newResidualSummary(f.opt.ResidualPersistence, f.fitTrainingData, f.residual, f.uncertainty)
+}
+
+// loadResidualSummary restores the training series of a persisted residual summary and predicts the
+// fit results over the training times. The fit results are left unset if the model cannot predict
+// without its regressors.
+func (f *Forecaster) loadResidualSummary(s *ResidualSummary) error {
+	f.residualSummary = s
+	if s == nil || s.Series == nil {
+		return nil
+	}
+	series := s.Series
+	if len(series.Y) != len(series.T) || len(series.Residual) != len(series.T) || len(series.Uncertainty) != len(series.T) {
+		return fmt.Errorf("training series has %d times, %d values, %d residuals, and %d uncertainty, %w",
+			len(series.T), len(series.Y), len(series.Residual), len(series.Uncertainty), ErrMismatchedTrainingSeries)
+	}
+	f.fitTrainingData = &timedataset.TimeDataset{
+		T: slices.Clone(series.T),
+		Y: slices.Clone(series.Y),
+	}
+	f.residual = slices.Clone(series.Residual)
+	f.uncertainty = slices.Clone(series.Uncertainty)
+
+	res, err := f.predict(context.Background(), series.T, nil)
+	if errors.Is(err, forecast.ErrMissingRegressor) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("unable to predict training series, %w", err)
+	}
+	f.fitResults = res
+	return nil
+}
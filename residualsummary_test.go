@@ -0,0 +1,169 @@
+package forecaster
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/forecast/options"
+	"github.com/aouyang1/go-forecaster/timedataset"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeriesSummary(t *testing.T) {
+	s := summarizeSeries([]float64{4, math.NaN(), 1, 3, 2, 5})
+	assert.Equal(t, 5, s.Count)
+	assert.Equal(t, 1, s.Missing)
+	assert.Equal(t, 3.0, s.Mean)
+	assert.InDelta(t, math.Sqrt(2.5), s.StdDev, 1e-12)
+	assert.Equal(t, 1.0, s.MAD)
+	assert.Equal(t, ResidualSummaryLevels, s.Levels)
+
+	assert.Equal(t, 1.0, s.Quantile(0))
+	assert.Equal(t, 3.0, s.Quantile(0.5))
+	assert.Equal(t, 5.0, s.Quantile(1))
+	assert.InDelta(t, 3.5, s.Quantile(0.625), 1e-12)
+	assert.True(t, math.IsNaN(s.Quantile(1.5)))
+
+	empty := summarizeSeries([]float64{math.NaN()})
+	assert.Equal(t, SeriesSummary{Missing: 1}, empty)
+	assert.True(t, math.IsNaN(empty.Quantile(0.5)))
+}
+
+func TestNaNFloatsJSON(t *testing.T) {
+	out, err := json.Marshal(NaNFloats{1, math.NaN(), math.Inf(1), 2})
+	require.Nil(t, err)
+	assert.Equal(t, "[1,null,null,2]", string(out))
+
+	var in NaNFloats
+	require.Nil(t, json.Unmarshal(out, &in))
+	require.Len(t, in, 4)
+	assert.Equal(t, 1.0, in[0])
+	assert.True(t, math.IsNaN(in[1]))
+	assert.True(t, math.IsNaN(in[2]))
+	assert.Equal(t, 2.0, in[3])
+}
+
+func TestResidualPersistence(t *testing.T) {
+	n := 4 * 24
+	tWin := timedataset.GenerateT(n, time.Hour, func() time.Time {
+		return time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC)
+	})
+	rng := rand.New(rand.NewSource(3))
+	y := make([]float64, n)
+	for i := range y {
+		y[i] = 10.0 + 2.0*math.Sin(2.0*math.Pi*float64(i)/24.0) + rng.NormFloat64()
+	}
+	y[10] = math.NaN()
+
+	testData := map[string]struct {
+		persistence ResidualPersistence
+		err         error
+	}{
+		"none":    {persistence: ResidualPersistenceNone},
+		"default": {},
+		"summary": {persistence: ResidualPersistenceSummary},
+		"series":  {persistence: ResidualPersistenceSeries},
+		"unknown": {persistence: "sketch", err: ErrUnknownResidualPersistence},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			opt := NewDefaultOptions()
+			opt.SeriesOptions.ForecastOptions.SeasonalityOptions.SeasonalityConfigs = []options.SeasonalityConfig{
+				options.NewDailySeasonalityConfig(2),
+			}
+			opt.UncertaintyOptions.ResidualWindow = 12
+			opt.ResidualPersistence = td.persistence
+
+			f, err := New(opt)
+			require.Nil(t, err)
+			require.Nil(t, f.Fit(tWin, y))
+			model, err := f.Model()
+			if td.err != nil {
+				assert.ErrorIs(t, err, td.err)
+				return
+			}
+			require.Nil(t, err)
+
+			summary, err := f.ResidualSummary()
+			require.Nil(t, err)
+			assert.Equal(t, n-1, summary.Residual.Count)
+			assert.Equal(t, 1, summary.Residual.Missing)
+			lower, upper, err := summary.Threshold(0.98)
+			require.Nil(t, err)
+			assert.Less(t, lower, 0.0)
+			assert.Greater(t, upper, 0.0)
+
+			// round trip through json and the binary encoding
+			var buf bytes.Buffer
+			require.Nil(t, json.NewEncoder(&buf).Encode(model))
+			var jsonModel Model
+			require.Nil(t, json.NewDecoder(&buf).Decode(&jsonModel))
+			enc, err := model.MarshalBinary()
+			require.Nil(t, err)
+			var binModel Model
+			require.Nil(t, binModel.UnmarshalBinary(enc))
+
+			for _, m := range []Model{jsonModel, binModel} {
+				loaded, err := NewFromModel(m)
+				require.Nil(t, err)
+				loadedSummary, err := loaded.ResidualSummary()
+				require.Nil(t, err)
+
+				switch td.persistence {
+				case "", ResidualPersistenceNone:
+					assert.Nil(t, m.Residuals)
+					assert.Nil(t, loadedSummary)
+					assert.Nil(t, loaded.Residuals())
+					continue
+				case ResidualPersistenceSummary:
+					assert.Nil(t, loadedSummary.Series)
+					assert.Nil(t, loaded.Residuals())
+					assert.Nil(t, loaded.FitResults())
+				case ResidualPersistenceSeries:
+					require.NotNil(t, loadedSummary.Series)
+					assert.Equal(t, len(f.Residuals()), len(loaded.Residuals()))
+					assert.True(t, math.IsNaN(loaded.Residuals()[10]))
+					assert.InDeltaSlice(t, f.Uncertainty(), loaded.Uncertainty(), 1e-9)
+					assert.Equal(t, n, len(loaded.TrainingData().T))
+					require.NotNil(t, loaded.FitResults())
+					assert.InDeltaSlice(t, f.FitResults().Forecast, loaded.FitResults().Forecast, 1e-9)
+					assert.Len(t, loaded.TrendComponent(), n)
+					assert.Len(t, loaded.SeasonalityComponent(), n)
+				}
+
+				assert.Equal(t, summary.Residual, loadedSummary.Residual)
+				assert.Equal(t, summary.Uncertainty, loadedSummary.Uncertainty)
+				loadedLower, loadedUpper, err := loadedSummary.Threshold(0.98)
+				require.Nil(t, err)
+				assert.Equal(t, lower, loadedLower)
+				assert.Equal(t, upper, loadedUpper)
+
+				// a loaded model serializes the summary it was loaded with
+				reserialized, err := loaded.Model()
+				require.Nil(t, err)
+				assert.Equal(t, summary.Residual, reserialized.Residuals.Residual)
+			}
+		})
+	}
+}
+
+func TestResidualSummaryThreshold(t *testing.T) {
+	var s *ResidualSummary
+	_, _, err := s.Threshold(0.9)
+	assert.ErrorIs(t, err, ErrInsufficientResidual)
+
+	s = &ResidualSummary{Residual: summarizeSeries([]float64{-2, -1, 0, 1, 2})}
+	_, _, err = s.Threshold(1.5)
+	assert.ErrorIs(t, err, ErrInvalidResidualLevel)
+
+	lower, upper, err := s.Threshold(1)
+	require.Nil(t, err)
+	assert.Equal(t, -2.0, lower)
+	assert.Equal(t, 2.0, upper)
+}
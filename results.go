@@ -1,6 +1,8 @@
 package forecaster
 
 import (
+	"fmt"
+	"math"
 	"time"
 
 	"github.com/aouyang1/go-forecaster/forecast"
@@ -16,4 +18,100 @@ type Results struct {
 
 	SeriesComponents      forecast.Components `json:"series_components"`
 	UncertaintyComponents forecast.Components `json:"uncertainty_components"`
+
+	// NaNReasons explains each time point with a NaN forecast or band caused by NaN features in the
+	// series or uncertainty model. Unaffected time points have an empty reason and this is nil if no
+	// time point is affected.
+	NaNReasons []string `json:"nan_reasons,omitempty"`
+
+	// MissingFeatures flags the custom time features and regressors that were unavailable at prediction
+	// and filled according to their missing policy. This is nil if no feature is missing.
+	MissingFeatures []string `json:"missing_features,omitempty"`
+
+	// ShortTermUncertainty is the residual noise band width and LongTermUncertainty is the trend
+	// parameter band width at each time point. The upper and lower bands are offset by their quadrature
+	// sum. Both are nil unless trend bootstraps are configured.
+	ShortTermUncertainty []float64 `json:"short_term_uncertainty,omitempty"`
+	LongTermUncertainty  []float64 `json:"long_term_uncertainty,omitempty"`
+
+	// Autoregressive is the predicted error of the series fit added to the forecast and bands at each
+	// time point. This is nil unless the autoregressive residual is configured.
+	Autoregressive []float64 `json:"autoregressive,omitempty"`
+}
+
+// Evaluation is the comparison of forecast results against the observed actuals. Per point slices
+// are aligned with the results time points where errors are NaN if either value is missing. Missing
+// errors are written as null in json.
+type Evaluation struct {
+	T         []time.Time `json:"time"`
+	Errors    NaNFloats   `json:"errors"`
+	InBand    []bool      `json:"in_band"`
+	NumPoints int         `json:"num_points"`
+	NumScored int         `json:"num_scored"`
+
+	Scores   forecast.Scores `json:"scores"`
+	MAE      float64         `json:"mean_absolute_error"`
+	RMSE     float64         `json:"root_mean_squared_error"`
+	Bias     float64         `json:"bias"`
+	Coverage float64         `json:"coverage"`
+}
+
+// ScoreAgainst compares the results against the actual values observed at the same time points. This
+// computes the fit scores along with the mean absolute error, root mean squared error, mean bias of
+// actual minus forecast, and the fraction of actuals within the upper and lower bands. The scaled error
+// is relative to a naive forecast of the previous actual. NaN actuals are skipped.
+func (r *Results) ScoreAgainst(actual []float64) (*Evaluation, error) {
+	if r == nil {
+		return nil, ErrEmptyResults
+	}
+	if len(actual) != len(r.Forecast) {
+		return nil, fmt.Errorf("got %d actuals for %d forecasts, %w", len(actual), len(r.Forecast), forecast.ErrResLenMismatch)
+	}
+
+	var baseline forecast.ScoreBaseline
+	if len(r.Upper) == len(actual) && len(r.Lower) == len(actual) {
+		baseline.Upper, baseline.Lower = r.Upper, r.Lower
+	}
+	scores, err := forecast.NewScoresWithBaseline(r.Forecast, actual, baseline)
+	if err != nil {
+		return nil, fmt.Errorf("unable to compute scores against actuals, %w", err)
+	}
+
+	eval := &Evaluation{
+		T:         r.T,
+		Errors:    make([]float64, len(actual)),
+		InBand:    make([]bool, len(actual)),
+		NumPoints: len(actual),
+		Scores:    *scores,
+	}
+
+	var absErr, sqErr, sumErr float64
+	var inBand int
+	for i, a := range actual {
+		if math.IsNaN(a) || math.IsNaN(r.Forecast[i]) {
+			eval.Errors[i] = math.NaN()
+			continue
+		}
+		e := a - r.Forecast[i]
+		eval.Errors[i] = e
+		eval.NumScored++
+
+		absErr += math.Abs(e)
+		sqErr += e * e
+		sumErr += e
+
+		if i < len(r.Upper) && i < len(r.Lower) && a >= r.Lower[i] && a <= r.Upper[i] {
+			eval.InBand[i] = true
+			inBand++
+		}
+	}
+
+	if eval.NumScored > 0 {
+		n := float64(eval.NumScored)
+		eval.MAE = absErr / n
+		eval.RMSE = math.Sqrt(sqErr / n)
+		eval.Bias = sumErr / n
+		eval.Coverage = float64(inBand) / n
+	}
+	return eval, nil
 }
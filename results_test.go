@@ -0,0 +1,151 @@
+package forecaster
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/aouyang1/go-forecaster/forecast"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResultsScoreAgainst(t *testing.T) {
+	res := &Results{
+		Forecast: []float64{1, 2, 3, 4},
+		Upper:    []float64{2, 3, 4, 5},
+		Lower:    []float64{0, 1, 2, 3},
+	}
+
+	testData := map[string]struct {
+		actual   []float64
+		expected *Evaluation
+		err      error
+	}{
+		"length mismatch": {
+			actual: []float64{1},
+			err:    forecast.ErrResLenMismatch,
+		},
+		"exact": {
+			actual: []float64{1, 2, 3, 4},
+			expected: &Evaluation{
+				Errors:    []float64{0, 0, 0, 0},
+				InBand:    []bool{true, true, true, true},
+				NumPoints: 4,
+				NumScored: 4,
+				Coverage:  1.0,
+			},
+		},
+		"with errors and nan": {
+			actual: []float64{2, math.NaN(), 6, 3},
+			expected: &Evaluation{
+				Errors:    []float64{1, math.NaN(), 3, -1},
+				InBand:    []bool{true, false, false, true},
+				NumPoints: 4,
+				NumScored: 3,
+				MAE:       5.0 / 3.0,
+				RMSE:      math.Sqrt(11.0 / 3.0),
+				Bias:      1.0,
+				Coverage:  2.0 / 3.0,
+			},
+		},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			eval, err := res.ScoreAgainst(td.actual)
+			if td.err != nil {
+				assert.ErrorIs(t, err, td.err)
+				return
+			}
+			require.Nil(t, err)
+
+			assert.Equal(t, td.expected.InBand, eval.InBand)
+			assert.Equal(t, td.expected.NumPoints, eval.NumPoints)
+			assert.Equal(t, td.expected.NumScored, eval.NumScored)
+			for i, e := range td.expected.Errors {
+				if math.IsNaN(e) {
+					assert.True(t, math.IsNaN(eval.Errors[i]))
+					continue
+				}
+				assert.InDelta(t, e, eval.Errors[i], 1e-9)
+			}
+			assert.InDelta(t, td.expected.MAE, eval.MAE, 1e-9)
+			assert.InDelta(t, td.expected.RMSE, eval.RMSE, 1e-9)
+			assert.InDelta(t, td.expected.Bias, eval.Bias, 1e-9)
+			assert.InDelta(t, td.expected.Coverage, eval.Coverage, 1e-9)
+
+			// fit scores are averaged over all points including NaNs
+			expectedMSE := td.expected.RMSE * td.expected.RMSE * float64(td.expected.NumScored) / float64(td.expected.NumPoints)
+			assert.InDelta(t, expectedMSE, eval.Scores.MSE, 1e-9)
+			assert.InDelta(t, td.expected.MAE, eval.Scores.MAE, 1e-9)
+			assert.InDelta(t, td.expected.Coverage, eval.Scores.Coverage, 1e-9)
+
+			// missing points are written as null
+			out, err := json.Marshal(eval)
+			require.Nil(t, err)
+			var decoded Evaluation
+			require.Nil(t, json.Unmarshal(out, &decoded))
+			require.Len(t, decoded.Errors, len(td.expected.Errors))
+			for i, e := range td.expected.Errors {
+				if math.IsNaN(e) {
+					assert.True(t, math.IsNaN(decoded.Errors[i]))
+					continue
+				}
+				assert.InDelta(t, e, decoded.Errors[i], 1e-9)
+			}
+		})
+	}
+}
+
+func TestResultsSmooth(t *testing.T) {
+	res := &Results{
+		Forecast: []float64{1, 5, 1, 5, 1},
+		Upper:    []float64{2, 6, 2, 6, 2},
+		Lower:    []float64{0, 4, 0, 4, 0},
+	}
+
+	testData := map[string]struct {
+		opt      SmoothOptions
+		expected []float64
+		err      error
+	}{
+		"none": {
+			opt:      SmoothOptions{},
+			expected: []float64{1, 5, 1, 5, 1},
+		},
+		"moving average": {
+			opt:      SmoothOptions{Method: SmoothMovingAverage, Window: 3},
+			expected: []float64{3, 7.0 / 3.0, 11.0 / 3.0, 7.0 / 3.0, 3},
+		},
+		"savitzky golay": {
+			opt:      SmoothOptions{Method: SmoothSavitzkyGolay, Window: 5, PolyOrder: 0},
+			expected: []float64{2.6, 2.6, 2.6, 2.6, 2.6},
+		},
+		"unknown method": {
+			opt: SmoothOptions{Method: "unknown", Window: 3},
+			err: ErrUnknownSmoothMethod,
+		},
+		"window too small": {
+			opt: SmoothOptions{Method: SmoothSavitzkyGolay, Window: 3, PolyOrder: 3},
+			err: ErrInvalidSmoothWindow,
+		},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			smoothed, err := res.Smooth(td.opt)
+			if td.err != nil {
+				assert.ErrorIs(t, err, td.err)
+				return
+			}
+			require.Nil(t, err)
+			assert.InDeltaSlice(t, td.expected, smoothed.Forecast, 1e-9)
+			for i := range td.expected {
+				assert.InDelta(t, smoothed.Forecast[i]+1, smoothed.Upper[i], 1e-9)
+				assert.InDelta(t, smoothed.Forecast[i]-1, smoothed.Lower[i], 1e-9)
+			}
+			assert.Equal(t, []float64{1, 5, 1, 5, 1}, res.Forecast)
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package forecaster
+
+import (
+	"math"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/forecast"
+)
+
+// RetrainReason describes why a retrain policy recommended retraining
+type RetrainReason string
+
+const (
+	RetrainReasonAge      RetrainReason = "age"
+	RetrainReasonScore    RetrainReason = "score"
+	RetrainReasonCoverage RetrainReason = "coverage"
+	RetrainReasonDrift    RetrainReason = "drift"
+)
+
+// RetrainHook is invoked with the decision whenever a retrain policy recommends retraining
+type RetrainHook func(d RetrainDecision)
+
+// RetrainPolicy recommends retraining a model based on its age and the evaluation of its recent
+// forecasts against actuals. Each threshold is disabled if zero.
+type RetrainPolicy struct {
+	// MaxAge is the longest time after the training end time of the model before retraining
+	MaxAge time.Duration `json:"max_age"`
+
+	// MaxMSERatio is the largest ratio of the evaluated mean squared error over the training mean
+	// squared error of the model before retraining. The training scores of the forecaster are in the
+	// original space of the series falling back to the scores of the series model if unset.
+	MaxMSERatio float64 `json:"max_mse_ratio"`
+
+	// MaxMAPE is the largest evaluated mean absolute percentage error before retraining
+	MaxMAPE float64 `json:"max_mape"`
+
+	// MinCoverage is the smallest evaluated fraction of actuals within the uncertainty bands before
+	// retraining
+	MinCoverage float64 `json:"min_coverage"`
+
+	// MaxDrift is the largest evaluated absolute bias relative to the evaluated root mean squared
+	// error before retraining. A value close to 1 means the errors are dominated by a level shift.
+	MaxDrift float64 `json:"max_drift"`
+
+	// Hooks are invoked in order by Apply when retraining is recommended
+	Hooks []RetrainHook `json:"-"`
+}
+
+// RetrainDecision is the outcome of a retrain policy along with the metrics it was based on
+type RetrainDecision struct {
+	Retrain  bool            `json:"retrain"`
+	Reasons  []RetrainReason `json:"reasons"`
+	Age      time.Duration   `json:"age"`
+	MSERatio float64         `json:"mse_ratio"`
+	Drift    float64         `json:"drift"`
+
+	Evaluation *Evaluation `json:"evaluation"`
+}
+
+// Decide evaluates the policy for the model at the input time. The evaluation is typically the score
+// of the most recent forecasts of the model against the observed actuals and may be nil to only
+// consider the age of the model.
+func (p RetrainPolicy) Decide(now time.Time, model Model, eval *Evaluation) RetrainDecision {
+	d := RetrainDecision{
+		Age:        now.Sub(model.Series.TrainEndTime),
+		MSERatio:   math.NaN(),
+		Drift:      math.NaN(),
+		Evaluation: eval,
+	}
+	if p.MaxAge > 0 && d.Age > p.MaxAge {
+		d.Reasons = append(d.Reasons, RetrainReasonAge)
+	}
+
+	if eval != nil && eval.NumScored > 0 {
+		if trainScores := model.trainingScores(); trainScores != nil && trainScores.MSE > 0 {
+			d.MSERatio = eval.Scores.MSE / trainScores.MSE
+		}
+		if eval.RMSE > 0 {
+			d.Drift = math.Abs(eval.Bias) / eval.RMSE
+		}
+
+		scoreDegraded := p.MaxMSERatio > 0 && d.MSERatio > p.MaxMSERatio
+		scoreDegraded = scoreDegraded || (p.MaxMAPE > 0 && eval.Scores.MAPE > p.MaxMAPE)
+		if scoreDegraded {
+			d.Reasons = append(d.Reasons, RetrainReasonScore)
+		}
+		if p.MinCoverage > 0 && eval.Coverage < p.MinCoverage {
+			d.Reasons = append(d.Reasons, RetrainReasonCoverage)
+		}
+		if p.MaxDrift > 0 && d.Drift > p.MaxDrift {
+			d.Reasons = append(d.Reasons, RetrainReasonDrift)
+		}
+	}
+	d.Retrain = len(d.Reasons) > 0
+	return d
+}
+
+// trainingScores returns the scores of the forecaster against the training data in the original space
+// of the series or the scores of the series model for models without them. The series model scores
+// are in the transformed space if a transform or logistic growth is configured.
+func (m Model) trainingScores() *forecast.Scores {
+	if m.Scores != nil {
+		return m.Scores
+	}
+	return m.Series.Scores
+}
+
+// Apply decides whether to retrain the model and invokes every hook if retraining is recommended
+func (p RetrainPolicy) Apply(now time.Time, model Model, eval *Evaluation) RetrainDecision {
+	d := p.Decide(now, model, eval)
+	if !d.Retrain {
+		return d
+	}
+	for _, hook := range p.Hooks {
+		hook(d)
+	}
+	return d
+}
@@ -0,0 +1,128 @@
+package forecaster
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/forecast"
+	"github.com/aouyang1/go-forecaster/timedataset"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetrainPolicy(t *testing.T) {
+	trainEnd := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	model := Model{
+		Series: forecast.Model{
+			TrainEndTime: trainEnd,
+			Scores:       &forecast.Scores{MSE: 1.0},
+		},
+	}
+	healthy := &Evaluation{
+		NumScored: 10,
+		Scores:    forecast.Scores{MSE: 1.2, MAPE: 0.05},
+		RMSE:      1.1,
+		Bias:      0.1,
+		Coverage:  0.95,
+	}
+	policy := RetrainPolicy{
+		MaxAge:      7 * 24 * time.Hour,
+		MaxMSERatio: 2.0,
+		MaxMAPE:     0.2,
+		MinCoverage: 0.8,
+		MaxDrift:    0.5,
+	}
+
+	testData := map[string]struct {
+		now      time.Time
+		eval     *Evaluation
+		expected []RetrainReason
+	}{
+		"healthy": {
+			now:  trainEnd.Add(24 * time.Hour),
+			eval: healthy,
+		},
+		"no evaluation": {
+			now: trainEnd.Add(24 * time.Hour),
+		},
+		"stale": {
+			now:      trainEnd.Add(8 * 24 * time.Hour),
+			expected: []RetrainReason{RetrainReasonAge},
+		},
+		"mse degraded": {
+			now: trainEnd.Add(24 * time.Hour),
+			eval: &Evaluation{
+				NumScored: 10, Scores: forecast.Scores{MSE: 3.0, MAPE: 0.05},
+				RMSE: 1.7, Bias: 0.1, Coverage: 0.95,
+			},
+			expected: []RetrainReason{RetrainReasonScore},
+		},
+		"low coverage and drift": {
+			now: trainEnd.Add(24 * time.Hour),
+			eval: &Evaluation{
+				NumScored: 10, Scores: forecast.Scores{MSE: 1.5, MAPE: 0.05},
+				RMSE: 1.2, Bias: -1.0, Coverage: 0.4,
+			},
+			expected: []RetrainReason{RetrainReasonCoverage, RetrainReasonDrift},
+		},
+		"nothing scored": {
+			now:  trainEnd.Add(24 * time.Hour),
+			eval: &Evaluation{Coverage: 0},
+		},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			var hooked []RetrainDecision
+			p := policy
+			p.Hooks = []RetrainHook{func(d RetrainDecision) { hooked = append(hooked, d) }}
+
+			d := p.Apply(td.now, model, td.eval)
+			assert.Equal(t, td.expected, d.Reasons)
+			assert.Equal(t, len(td.expected) > 0, d.Retrain)
+			assert.Equal(t, td.now.Sub(trainEnd), d.Age)
+			if d.Retrain {
+				assert.Len(t, hooked, 1)
+			} else {
+				assert.Empty(t, hooked)
+			}
+		})
+	}
+}
+
+func TestRetrainPolicyTransform(t *testing.T) {
+	n := 14 * 24
+	tWin := timedataset.GenerateT(n, time.Hour, func() time.Time {
+		return time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	})
+	rng := rand.New(rand.NewSource(3))
+	y := make([]float64, n)
+	for i := range y {
+		y[i] = math.Exp(5.0+0.5*math.Sin(2*math.Pi*float64(i)/24)) * (1 + 0.05*rng.NormFloat64())
+	}
+
+	opt := NewDefaultOptions()
+	opt.Transform = &TransformOptions{Method: TransformLog}
+	f, err := New(opt)
+	require.Nil(t, err)
+	require.Nil(t, f.Fit(tWin, y))
+	model, err := f.Model()
+	require.Nil(t, err)
+
+	// the evaluation of the fit against its own training data is in the original space of the series
+	eval, err := f.FitResults().ScoreAgainst(y)
+	require.Nil(t, err)
+
+	policy := RetrainPolicy{MaxMSERatio: 2.0}
+	d := policy.Decide(tWin[n-1], model, eval)
+	assert.InDelta(t, 1.0, d.MSERatio, 1e-6)
+	assert.False(t, d.Retrain)
+
+	// models without forecaster scores fall back to the transformed space scores of the series model
+	model.Scores = nil
+	d = policy.Decide(tWin[n-1], model, eval)
+	assert.Greater(t, d.MSERatio, 100.0)
+	assert.True(t, d.Retrain)
+}
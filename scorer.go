@@ -0,0 +1,301 @@
+package forecaster
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/errs"
+	"github.com/aouyang1/go-forecaster/timedataset"
+)
+
+var (
+	ErrNilScorerForecaster     = errs.NewConfigError(errs.CodeMissingOption, "online scorer requires a forecaster", nil)
+	ErrInvalidScorerHalfLife   = errs.NewConfigError(errs.CodeInvalidOption, "online scorer half life must be positive", nil)
+	ErrInvalidScorerHysteresis = errs.NewConfigError(errs.CodeInvalidOption, "online scorer thresholds must satisfy 0 <= close <= open", nil)
+)
+
+const (
+	// DefaultScorerHalfLife is the number of points after which an error has half of its initial weight
+	// in the error statistics of the online scorer
+	DefaultScorerHalfLife = 100.0
+
+	// DefaultScorerEpisodeHalfLife is the half life of the error statistics while an anomaly episode is
+	// open which defaults to episodeSlowdown times the half life if unset
+	DefaultScorerEpisodeHalfLife = episodeSlowdown * DefaultScorerHalfLife
+
+	episodeSlowdown = 10.0
+
+	// DefaultOpenThreshold is the anomaly score at or above which an anomaly episode opens
+	DefaultOpenThreshold = 4.0
+
+	// DefaultCloseThreshold is the anomaly score below which an open anomaly episode closes
+	DefaultCloseThreshold = 2.0
+)
+
+// OnlineScorerOptions configures the exponential forgetting and the hysteresis of an online scorer.
+// Episodes open when the score reaches OpenThreshold and close once it falls below CloseThreshold so
+// scores hovering around a single threshold do not flap. EpisodeHalfLife is the slower half life of
+// the error statistics while an episode is open. Zero values use the defaults.
+type OnlineScorerOptions struct {
+	HalfLife        float64 `json:"half_life"`
+	EpisodeHalfLife float64 `json:"episode_half_life"`
+	OpenThreshold   float64 `json:"open_threshold"`
+	CloseThreshold  float64 `json:"close_threshold"`
+}
+
+// NewDefaultOnlineScorerOptions returns the default online scorer options
+func NewDefaultOnlineScorerOptions() *OnlineScorerOptions {
+	return &OnlineScorerOptions{
+		HalfLife:        DefaultScorerHalfLife,
+		EpisodeHalfLife: DefaultScorerEpisodeHalfLife,
+		OpenThreshold:   DefaultOpenThreshold,
+		CloseThreshold:  DefaultCloseThreshold,
+	}
+}
+
+// Validate fills in the defaults of unset options and returns an error if the options are invalid
+func (o *OnlineScorerOptions) Validate() (*OnlineScorerOptions, error) {
+	if o == nil {
+		return NewDefaultOnlineScorerOptions(), nil
+	}
+	res := *o
+	if res.HalfLife == 0 {
+		res.HalfLife = DefaultScorerHalfLife
+	}
+	if res.EpisodeHalfLife == 0 {
+		res.EpisodeHalfLife = episodeSlowdown * res.HalfLife
+	}
+	if res.OpenThreshold == 0 {
+		res.OpenThreshold = DefaultOpenThreshold
+	}
+	if res.CloseThreshold == 0 {
+		res.CloseThreshold = DefaultCloseThreshold
+	}
+	if res.HalfLife < 0 || math.IsNaN(res.HalfLife) || math.IsInf(res.HalfLife, 0) {
+		return nil, fmt.Errorf("half life of %.3f, %w", res.HalfLife, ErrInvalidScorerHalfLife)
+	}
+	if res.EpisodeHalfLife < 0 || math.IsNaN(res.EpisodeHalfLife) || math.IsInf(res.EpisodeHalfLife, 0) {
+		return nil, fmt.Errorf("episode half life of %.3f, %w", res.EpisodeHalfLife, ErrInvalidScorerHalfLife)
+	}
+	if res.CloseThreshold < 0 || res.CloseThreshold > res.OpenThreshold {
+		return nil, fmt.Errorf("close of %.3f and open of %.3f, %w", res.CloseThreshold, res.OpenThreshold, ErrInvalidScorerHysteresis)
+	}
+	return &res, nil
+}
+
+// AnomalyScore is the outcome of scoring a single observation. Error is the observation minus the
+// forecast and Score is its absolute deviation from the exponentially weighted mean error in
+// exponentially weighted standard deviations. Anomalous is set while an episode is open and Closed is
+// the episode that this point closed if any. NaN observations have a NaN score and leave the state
+// unchanged. NaN values are encoded as null in json.
+type AnomalyScore struct {
+	T         time.Time `json:"time"`
+	Value     float64   `json:"value"`
+	Forecast  float64   `json:"forecast"`
+	Upper     float64   `json:"upper"`
+	Lower     float64   `json:"lower"`
+	Error     float64   `json:"error"`
+	Score     float64   `json:"score"`
+	Anomalous bool      `json:"anomalous"`
+
+	Closed *Anomaly `json:"closed,omitempty"`
+}
+
+// anomalyScoreJSON shadows the values of an anomaly score which may be NaN
+type anomalyScoreJSON struct {
+	anomalyScoreAlias
+	Value    *float64 `json:"value"`
+	Forecast *float64 `json:"forecast"`
+	Upper    *float64 `json:"upper"`
+	Lower    *float64 `json:"lower"`
+	Error    *float64 `json:"error"`
+	Score    *float64 `json:"score"`
+}
+
+type anomalyScoreAlias AnomalyScore
+
+// nullable returns nil for NaN and infinite values
+func nullable(v float64) *float64 {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return nil
+	}
+	return &v
+}
+
+// fromNullable returns NaN for a nil value
+func fromNullable(v *float64) float64 {
+	if v == nil {
+		return math.NaN()
+	}
+	return *v
+}
+
+// MarshalJSON encodes the anomaly score writing NaN values as null
+func (a AnomalyScore) MarshalJSON() ([]byte, error) {
+	return json.Marshal(anomalyScoreJSON{
+		anomalyScoreAlias: anomalyScoreAlias(a),
+		Value:             nullable(a.Value),
+		Forecast:          nullable(a.Forecast),
+		Upper:             nullable(a.Upper),
+		Lower:             nullable(a.Lower),
+		Error:             nullable(a.Error),
+		Score:             nullable(a.Score),
+	})
+}
+
+// UnmarshalJSON decodes the anomaly score reading null values as NaN
+func (a *AnomalyScore) UnmarshalJSON(data []byte) error {
+	var in anomalyScoreJSON
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	*a = AnomalyScore(in.anomalyScoreAlias)
+	a.Value = fromNullable(in.Value)
+	a.Forecast = fromNullable(in.Forecast)
+	a.Upper = fromNullable(in.Upper)
+	a.Lower = fromNullable(in.Lower)
+	a.Error = fromNullable(in.Error)
+	a.Score = fromNullable(in.Score)
+	return nil
+}
+
+// OnlineScorer scores observations one at a time against a trained or loaded forecaster without
+// storing any history. It keeps the exponentially weighted mean and variance of the forecast errors
+// which adapt to a persistent bias or a change in noise level since the model was trained. The
+// variance starts from the uncertainty band of the model at the first observation. The statistics
+// adapt with the slower episode half life while an episode is open so a short anomaly is not absorbed
+// into its own baseline while a persistent level shift still closes once the statistics catch up.
+// Episodes are anomalies on one side of the mean error like the intervals of DetectAnomalies with the
+// peak z-score signed by the side.
+type OnlineScorer struct {
+	f            *Forecaster
+	opt          *OnlineScorerOptions
+	alpha        float64
+	episodeAlpha float64
+
+	initialized bool
+	lastT       time.Time
+	mean        float64
+	variance    float64
+
+	episode *Anomaly
+}
+
+// NewOnlineScorer creates an online scorer of the forecaster with the options or the defaults if nil
+func NewOnlineScorer(f *Forecaster, opt *OnlineScorerOptions) (*OnlineScorer, error) {
+	if f == nil {
+		return nil, ErrNilScorerForecaster
+	}
+	opt, err := opt.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return &OnlineScorer{
+		f:            f,
+		opt:          opt,
+		alpha:        1 - math.Pow(0.5, 1/opt.HalfLife),
+		episodeAlpha: 1 - math.Pow(0.5, 1/opt.EpisodeHalfLife),
+	}, nil
+}
+
+// Score predicts the time with the forecaster and scores the observation updating the error
+// statistics and the open episode. Times must be strictly increasing across calls.
+func (s *OnlineScorer) Score(t time.Time, y float64) (AnomalyScore, error) {
+	if s.initialized && !t.After(s.lastT) {
+		return AnomalyScore{}, fmt.Errorf("time %s is not after %s, %w", t, s.lastT, timedataset.ErrNonMontonic)
+	}
+	res, err := s.f.Predict([]time.Time{t})
+	if err != nil {
+		return AnomalyScore{}, fmt.Errorf("unable to predict %s, %w", t, err)
+	}
+	score := AnomalyScore{
+		T:        t,
+		Value:    y,
+		Forecast: res.Forecast[0],
+		Upper:    res.Upper[0],
+		Lower:    res.Lower[0],
+		Error:    y - res.Forecast[0],
+		Score:    math.NaN(),
+	}
+	if math.IsNaN(score.Error) {
+		score.Anomalous = s.episode != nil
+		return score, nil
+	}
+
+	if !s.initialized {
+		s.variance = s.bandVariance(score)
+		s.initialized = true
+	}
+	s.lastT = t
+
+	dev := score.Error - s.mean
+	z := anomalyZscore(dev, math.Sqrt(s.variance))
+	score.Score = math.Abs(z)
+	direction := AnomalyAbove
+	if dev < 0 {
+		direction = AnomalyBelow
+	}
+
+	if s.episode != nil && (score.Score < s.opt.CloseThreshold || s.episode.Direction != direction) {
+		score.Closed = s.episode
+		s.episode = nil
+	}
+	if s.episode == nil && score.Score >= s.opt.OpenThreshold {
+		s.episode = &Anomaly{Start: t, Direction: direction}
+	}
+	alpha := s.alpha
+	if s.episode != nil {
+		s.episode.End = t
+		s.episode.NumPoints++
+		if score.Score > math.Abs(s.episode.PeakZscore) {
+			s.episode.PeakTime = t
+			s.episode.PeakValue = y
+			s.episode.PeakDeviation = score.Error
+			s.episode.PeakZscore = z
+		}
+		score.Anomalous = true
+
+		// a zero variance has no baseline to protect so it keeps learning until errors have spread
+		if s.variance > 0 {
+			alpha = s.episodeAlpha
+		}
+	}
+
+	// exponentially weighted incremental mean and variance
+	s.mean += alpha * dev
+	s.variance = (1 - alpha) * (s.variance + alpha*dev*dev)
+	return score, nil
+}
+
+// bandVariance returns the variance implied by the half width of the uncertainty band of the model
+// at the observation
+func (s *OnlineScorer) bandVariance(score AnomalyScore) float64 {
+	halfWidth := (score.Upper - score.Lower) / 2
+	if z := s.f.opt.UncertaintyOptions.ResidualZscore; z > 0 {
+		halfWidth /= z
+	}
+	if math.IsNaN(halfWidth) || halfWidth < 0 {
+		return 0
+	}
+	return halfWidth * halfWidth
+}
+
+// Episode returns a copy of the open anomaly episode or nil if none is open
+func (s *OnlineScorer) Episode() *Anomaly {
+	if s.episode == nil {
+		return nil
+	}
+	episode := *s.episode
+	return &episode
+}
+
+// Reset clears the error statistics and any open episode
+func (s *OnlineScorer) Reset() {
+	s.initialized = false
+	s.lastT = time.Time{}
+	s.mean = 0
+	s.variance = 0
+	s.episode = nil
+}
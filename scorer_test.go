@@ -0,0 +1,238 @@
+package forecaster
+
+import (
+	"encoding/json"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/forecast/options"
+	"github.com/aouyang1/go-forecaster/timedataset"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOnlineScorerOptionsValidate(t *testing.T) {
+	testData := map[string]struct {
+		opt      *OnlineScorerOptions
+		expected *OnlineScorerOptions
+		err      error
+	}{
+		"nil":              {expected: NewDefaultOnlineScorerOptions()},
+		"defaults":         {opt: &OnlineScorerOptions{}, expected: NewDefaultOnlineScorerOptions()},
+		"custom":           {opt: &OnlineScorerOptions{HalfLife: 10, OpenThreshold: 3, CloseThreshold: 3}, expected: &OnlineScorerOptions{HalfLife: 10, EpisodeHalfLife: 100, OpenThreshold: 3, CloseThreshold: 3}},
+		"negative half":    {opt: &OnlineScorerOptions{HalfLife: -1}, err: ErrInvalidScorerHalfLife},
+		"negative episode": {opt: &OnlineScorerOptions{EpisodeHalfLife: -1}, err: ErrInvalidScorerHalfLife},
+		"close above open": {opt: &OnlineScorerOptions{OpenThreshold: 2, CloseThreshold: 3}, err: ErrInvalidScorerHysteresis},
+		"negative close":   {opt: &OnlineScorerOptions{CloseThreshold: -1}, err: ErrInvalidScorerHysteresis},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			res, err := td.opt.Validate()
+			if td.err != nil {
+				assert.ErrorIs(t, err, td.err)
+				return
+			}
+			require.Nil(t, err)
+			assert.Equal(t, td.expected, res)
+		})
+	}
+}
+
+func TestOnlineScorer(t *testing.T) {
+	n := 7 * 24 * 4
+	interval := 15 * time.Minute
+	tWin := timedataset.GenerateT(2*n, interval, func() time.Time {
+		return time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC)
+	})
+	rng := rand.New(rand.NewSource(11))
+	y := make([]float64, len(tWin))
+	for i := range y {
+		y[i] = 50 + 10*math.Sin(2*math.Pi*float64(i)/96) + rng.NormFloat64()
+	}
+	// anomalous spike of five points in the scored half
+	spikeStart := n + 200
+	for i := spikeStart; i < spikeStart+5; i++ {
+		y[i] += 15
+	}
+	y[n+300] = math.NaN()
+
+	opt := &Options{
+		SeriesOptions: &SeriesOptions{
+			ForecastOptions: &options.Options{
+				SeasonalityOptions: options.SeasonalityOptions{
+					SeasonalityConfigs: []options.SeasonalityConfig{options.NewDailySeasonalityConfig(2)},
+				},
+			},
+		},
+		UncertaintyOptions: &UncertaintyOptions{
+			ForecastOptions: &options.Options{},
+			ResidualWindow:  24,
+			ResidualZscore:  3.0,
+		},
+	}
+	f, err := New(opt)
+	require.Nil(t, err)
+	require.Nil(t, f.Fit(tWin[:n], y[:n]))
+
+	_, err = NewOnlineScorer(nil, nil)
+	assert.ErrorIs(t, err, ErrNilScorerForecaster)
+
+	scorer, err := NewOnlineScorer(f, nil)
+	require.Nil(t, err)
+
+	var closed []*Anomaly
+	var anomalous int
+	for i := n; i < len(tWin); i++ {
+		score, err := scorer.Score(tWin[i], y[i])
+		require.Nil(t, err)
+		if math.IsNaN(y[i]) {
+			assert.True(t, math.IsNaN(score.Score))
+			continue
+		}
+		if i == spikeStart {
+			assert.True(t, score.Anomalous)
+			assert.NotNil(t, scorer.Episode())
+		}
+		if score.Anomalous {
+			anomalous++
+		}
+		if score.Closed != nil {
+			closed = append(closed, score.Closed)
+		}
+	}
+
+	// the spike is the only episode and the slowly adapting statistics keep every spike point anomalous
+	require.Len(t, closed, 1)
+	assert.Equal(t, tWin[spikeStart], closed[0].Start)
+	assert.Equal(t, tWin[spikeStart+4], closed[0].End)
+	assert.Equal(t, 5, closed[0].NumPoints)
+	assert.Equal(t, AnomalyAbove, closed[0].Direction)
+	assert.Greater(t, closed[0].PeakZscore, 10.0)
+	assert.Greater(t, closed[0].PeakDeviation, 10.0)
+	assert.Equal(t, 5, anomalous)
+	assert.Nil(t, scorer.Episode())
+
+	// times must keep increasing until the scorer is reset
+	_, err = scorer.Score(tWin[n], y[n])
+	assert.ErrorIs(t, err, timedataset.ErrNonMontonic)
+	scorer.Reset()
+	_, err = scorer.Score(tWin[n], y[n])
+	assert.Nil(t, err)
+}
+
+func TestOnlineScorerLevelShift(t *testing.T) {
+	n := 7 * 24 * 4
+	interval := 15 * time.Minute
+	tWin := timedataset.GenerateT(2*n, interval, func() time.Time {
+		return time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC)
+	})
+	rng := rand.New(rand.NewSource(11))
+	y := make([]float64, len(tWin))
+	for i := range y {
+		y[i] = 50 + 10*math.Sin(2*math.Pi*float64(i)/96) + rng.NormFloat64()
+	}
+	// persistent level shift through the end of the scored half
+	shiftStart := n + 100
+	for i := shiftStart; i < len(y); i++ {
+		y[i] += 15
+	}
+	y[shiftStart+1] = math.NaN()
+
+	opt := &Options{
+		SeriesOptions: &SeriesOptions{
+			ForecastOptions: &options.Options{
+				SeasonalityOptions: options.SeasonalityOptions{
+					SeasonalityConfigs: []options.SeasonalityConfig{options.NewDailySeasonalityConfig(2)},
+				},
+			},
+		},
+		UncertaintyOptions: &UncertaintyOptions{
+			ForecastOptions: &options.Options{},
+			ResidualWindow:  24,
+			ResidualZscore:  3.0,
+		},
+	}
+	f, err := New(opt)
+	require.Nil(t, err)
+	require.Nil(t, f.Fit(tWin[:n], y[:n]))
+
+	scorer, err := NewOnlineScorer(f, nil)
+	require.Nil(t, err)
+
+	var closed []*Anomaly
+	for i := n; i < len(tWin); i++ {
+		score, err := scorer.Score(tWin[i], y[i])
+		require.Nil(t, err)
+		if score.Closed != nil {
+			closed = append(closed, score.Closed)
+		}
+
+		// scores of missing observations and open episodes round trip through json
+		out, err := json.Marshal(score)
+		require.Nil(t, err)
+		var decoded AnomalyScore
+		require.Nil(t, json.Unmarshal(out, &decoded))
+		assert.Equal(t, score.T.Unix(), decoded.T.Unix())
+		assert.Equal(t, score.Anomalous, decoded.Anomalous)
+		if math.IsNaN(score.Score) {
+			assert.True(t, math.IsNaN(decoded.Score))
+		} else {
+			assert.InDelta(t, score.Score, decoded.Score, 1e-9)
+		}
+	}
+
+	// the statistics catch up with the shift so the episode closes before the end of the series
+	require.Len(t, closed, 1)
+	assert.Equal(t, tWin[shiftStart], closed[0].Start)
+	assert.Equal(t, AnomalyAbove, closed[0].Direction)
+	assert.Less(t, closed[0].End, tWin[len(tWin)-1])
+	assert.Nil(t, scorer.Episode())
+}
+
+func TestOnlineScorerWeekend(t *testing.T) {
+	n := 3 * 7 * 24
+	tWin := timedataset.GenerateT(n+7*24, time.Hour, func() time.Time {
+		return time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC)
+	})
+	rng := rand.New(rand.NewSource(11))
+	level := func(tPnt time.Time) float64 {
+		if wkday := tPnt.Weekday(); wkday == time.Saturday || wkday == time.Sunday {
+			return 30
+		}
+		return 10
+	}
+	y := make([]float64, len(tWin))
+	for i, tPnt := range tWin {
+		y[i] = level(tPnt) + 0.1*rng.NormFloat64()
+	}
+
+	opt := &Options{
+		SeriesOptions: &SeriesOptions{
+			ForecastOptions: &options.Options{
+				WeekendOptions: options.WeekendOptions{Enabled: true},
+			},
+		},
+		UncertaintyOptions: &UncertaintyOptions{
+			ForecastOptions: &options.Options{},
+			ResidualWindow:  24,
+			ResidualZscore:  3.0,
+		},
+	}
+	f, err := New(opt)
+	require.Nil(t, err)
+	require.Nil(t, f.Fit(tWin[:n], y[:n]))
+
+	scorer, err := NewOnlineScorer(f, nil)
+	require.Nil(t, err)
+
+	// every point is scored alone so the weekend effect must not depend on the number of times predicted
+	for i := n; i < len(tWin); i++ {
+		score, err := scorer.Score(tWin[i], y[i])
+		require.Nil(t, err)
+		assert.InDelta(t, level(tWin[i]), score.Forecast, 1.0, tWin[i].String())
+		assert.False(t, score.Anomalous, tWin[i].String())
+	}
+}
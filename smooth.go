@@ -0,0 +1,82 @@
+package forecaster
+
+import (
+	"fmt"
+
+	"github.com/aouyang1/go-forecaster/errs"
+	"github.com/aouyang1/go-forecaster/stats"
+)
+
+var (
+	ErrUnknownSmoothMethod = errs.NewConfigError(errs.CodeInvalidOption, "unknown smoothing method", nil)
+	ErrInvalidSmoothWindow = errs.NewConfigError(errs.CodeInvalidOption, "smoothing window must be at least 2 and greater than the polynomial order", nil)
+)
+
+// SmoothMethod is the post-processing smoother applied to forecast results
+type SmoothMethod string
+
+const (
+	SmoothNone          SmoothMethod = ""
+	SmoothMovingAverage SmoothMethod = "moving_average"
+	SmoothSavitzkyGolay SmoothMethod = "savitzky_golay"
+)
+
+// SmoothOptions configures smoothing of forecast results for display. The moving average truncates its
+// window at the ends of the series while Savitzky-Golay fits a polynomial of PolyOrder over the window
+// and evaluates the edge points from the first and last full windows.
+type SmoothOptions struct {
+	Method    SmoothMethod `json:"method"`
+	Window    int          `json:"window"`
+	PolyOrder int          `json:"poly_order"`
+}
+
+func (s SmoothOptions) smooth(y []float64) []float64 {
+	if len(y) == 0 {
+		return y
+	}
+	switch s.Method {
+	case SmoothMovingAverage:
+		return stats.MovingAverage(y, s.Window)
+	case SmoothSavitzkyGolay:
+		return stats.SavitzkyGolay(y, s.Window, s.PolyOrder)
+	}
+	res := make([]float64, len(y))
+	copy(res, y)
+	return res
+}
+
+func (s SmoothOptions) validate() error {
+	switch s.Method {
+	case SmoothNone:
+		return nil
+	case SmoothMovingAverage, SmoothSavitzkyGolay:
+	default:
+		return fmt.Errorf("%q, %w", s.Method, ErrUnknownSmoothMethod)
+	}
+	if s.Window < 2 || s.PolyOrder < 0 || (s.Method == SmoothSavitzkyGolay && s.Window <= s.PolyOrder) {
+		return fmt.Errorf("window of %d with polynomial order %d, %w", s.Window, s.PolyOrder, ErrInvalidSmoothWindow)
+	}
+	return nil
+}
+
+// Smooth returns a copy of the results with the forecast, upper, and lower series smoothed for display.
+// The components and the model which produced the results are left unchanged.
+func (r *Results) Smooth(opt SmoothOptions) (*Results, error) {
+	if r == nil {
+		return nil, ErrEmptyResults
+	}
+	if err := opt.validate(); err != nil {
+		return nil, err
+	}
+	return &Results{
+		T:                     r.T,
+		Forecast:              opt.smooth(r.Forecast),
+		Upper:                 opt.smooth(r.Upper),
+		Lower:                 opt.smooth(r.Lower),
+		SeriesComponents:      r.SeriesComponents,
+		UncertaintyComponents: r.UncertaintyComponents,
+		NaNReasons:            r.NaNReasons,
+		ShortTermUncertainty:  r.ShortTermUncertainty,
+		LongTermUncertainty:   r.LongTermUncertainty,
+	}, nil
+}
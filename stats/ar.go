@@ -0,0 +1,81 @@
+package stats
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/aouyang1/go-forecaster/errs"
+)
+
+var (
+	ErrInvalidAROrder   = errs.NewConfigError(errs.CodeInvalidOption, "autoregressive order must be positive and less than the number of observations", nil)
+	ErrSingularAutocorr = errs.NewDataError(errs.CodeInsufficientData, "autocovariance is singular", nil)
+)
+
+// Autocovariance returns the autocovariance of the zero mean series at lags 0 through maxLag. Pairs
+// with a NaN value are skipped and every lag is normalized by the number of observed values rather than
+// the number of pairs so the autocovariances remain positive semi-definite.
+func Autocovariance(y []float64, maxLag int) []float64 {
+	res := make([]float64, maxLag+1)
+	var numObs int
+	for _, v := range y {
+		if !math.IsNaN(v) {
+			numObs++
+		}
+	}
+	if numObs == 0 {
+		return res
+	}
+	for lag := 0; lag <= maxLag; lag++ {
+		var sum float64
+		for i := lag; i < len(y); i++ {
+			if math.IsNaN(y[i]) || math.IsNaN(y[i-lag]) {
+				continue
+			}
+			sum += y[i] * y[i-lag]
+		}
+		res[lag] = sum / float64(numObs)
+	}
+	return res
+}
+
+// YuleWalker estimates the coefficients of an autoregressive model of the order on the zero mean series
+// by solving the Yule-Walker equations with the Levinson-Durbin recursion. The coefficient at index i is
+// applied to the value i+1 steps earlier. NaN values are ignored.
+func YuleWalker(y []float64, order int) ([]float64, error) {
+	var numObs int
+	for _, v := range y {
+		if !math.IsNaN(v) {
+			numObs++
+		}
+	}
+	if order < 1 || order >= numObs {
+		return nil, fmt.Errorf("order of %d with %d observations, %w", order, numObs, ErrInvalidAROrder)
+	}
+
+	acov := Autocovariance(y, order)
+	if acov[0] <= 0 {
+		return nil, ErrSingularAutocorr
+	}
+
+	coef := make([]float64, order)
+	prev := make([]float64, order)
+	variance := acov[0]
+	for k := 1; k <= order; k++ {
+		acc := acov[k]
+		for j := 1; j < k; j++ {
+			acc -= prev[j-1] * acov[k-j]
+		}
+		reflection := acc / variance
+		coef[k-1] = reflection
+		for j := 1; j < k; j++ {
+			coef[j-1] = prev[j-1] - reflection*prev[k-j-1]
+		}
+		variance *= 1 - reflection*reflection
+		if variance <= 0 {
+			return nil, ErrSingularAutocorr
+		}
+		copy(prev, coef)
+	}
+	return coef, nil
+}
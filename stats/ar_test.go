@@ -0,0 +1,82 @@
+package stats
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestYuleWalker(t *testing.T) {
+	rng := rand.New(rand.NewSource(5))
+	ar := func(coef []float64, n int) []float64 {
+		y := make([]float64, n)
+		for i := range y {
+			y[i] = rng.NormFloat64()
+			for j, c := range coef {
+				if i-j-1 >= 0 {
+					y[i] += c * y[i-j-1]
+				}
+			}
+		}
+		return y
+	}
+
+	testData := map[string]struct {
+		y        []float64
+		order    int
+		expected []float64
+		err      error
+	}{
+		"ar1": {
+			y:        ar([]float64{0.7}, 5000),
+			order:    1,
+			expected: []float64{0.7},
+		},
+		"ar2": {
+			y:        ar([]float64{0.5, -0.3}, 5000),
+			order:    2,
+			expected: []float64{0.5, -0.3},
+		},
+		"ar1 with nans": {
+			y: func() []float64 {
+				y := ar([]float64{0.7}, 5000)
+				for i := 0; i < len(y); i += 50 {
+					y[i] = math.NaN()
+				}
+				return y
+			}(),
+			order:    1,
+			expected: []float64{0.7},
+		},
+		"zero order": {
+			y:     []float64{1, 2, 3},
+			order: 0,
+			err:   ErrInvalidAROrder,
+		},
+		"order too large": {
+			y:     []float64{1, math.NaN(), 3},
+			order: 2,
+			err:   ErrInvalidAROrder,
+		},
+		"zero series": {
+			y:     []float64{0, 0, 0, 0},
+			order: 1,
+			err:   ErrSingularAutocorr,
+		},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			coef, err := YuleWalker(td.y, td.order)
+			if td.err != nil {
+				assert.ErrorIs(t, err, td.err)
+				return
+			}
+			require.Nil(t, err)
+			assert.InDeltaSlice(t, td.expected, coef, 0.05)
+		})
+	}
+}
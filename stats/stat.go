@@ -1,28 +1,46 @@
 package stats
 
 import (
-	"errors"
 	"math"
 	"sort"
+
+	"github.com/aouyang1/go-forecaster/errs"
+	"gonum.org/v1/gonum/mat"
 )
 
 var (
-	ErrMinimumFeatures    = errors.New("need at least 2 features to compute VIF")
-	ErrFeatureLenMismatch = errors.New("some feature length is not consistent")
-	ErrFeatureLen         = errors.New("must have at least 2 points per feature")
+	ErrMinimumFeatures    = errs.NewDataError(errs.CodeInsufficientData, "need at least 2 features to compute VIF", nil)
+	ErrFeatureLenMismatch = errs.NewDataError(errs.CodeDimensionMismatch, "some feature length is not consistent", nil)
+	ErrFeatureLen         = errs.NewDataError(errs.CodeInsufficientData, "must have at least 2 points per feature", nil)
+	ErrBucketLenMismatch  = errs.NewDataError(errs.CodeLengthMismatch, "seasonal buckets have a different length than values", nil)
 )
 
-// DetectOutliers uses the Tukey Method to return a slice of indexes that are classified as outliers
+// DetectOutliers uses the Tukey Method to return a slice of indexes that are classified as outliers.
+// NaN values are ignored.
 func DetectOutliers(y []float64, lowerPerc, upperPerc, tukeyFactor float64) []int {
 	lowerPerc = math.Max(lowerPerc, 0.0)
 	upperPerc = math.Min(upperPerc, 1.0)
 	tukeyFactor = math.Max(tukeyFactor, 0.0)
 
-	yCopy := make([]float64, len(y))
-	copy(yCopy, y)
+	// ignore NaNs which would otherwise sort to the front and skew the percentiles
+	yCopy := make([]float64, 0, len(y))
+	for _, v := range y {
+		if !math.IsNaN(v) {
+			yCopy = append(yCopy, v)
+		}
+	}
+	if len(yCopy) == 0 {
+		return nil
+	}
 	sort.Float64s(yCopy)
 	lowerIdx := int(math.Floor(float64(len(yCopy)) * lowerPerc))
 	upperIdx := int(math.Ceil(float64(len(yCopy)) * upperPerc))
+	if upperIdx > len(yCopy)-1 {
+		upperIdx = len(yCopy) - 1
+	}
+	if lowerIdx > upperIdx {
+		lowerIdx = upperIdx
+	}
 
 	lower := yCopy[lowerIdx]
 	upper := yCopy[upperIdx]
@@ -38,3 +56,180 @@ func DetectOutliers(y []float64, lowerPerc, upperPerc, tukeyFactor float64) []in
 	}
 	return outlierIdx
 }
+
+// DetectSeasonalOutliers applies the Tukey Method separately to the values of each seasonal bucket so
+// points are only classified as outliers relative to their seasonal context, e.g. the same hour of day.
+// NaN values are ignored and the returned indexes refer to the input slice in ascending order.
+func DetectSeasonalOutliers(y []float64, buckets []int, lowerPerc, upperPerc, tukeyFactor float64) ([]int, error) {
+	if len(y) != len(buckets) {
+		return nil, ErrBucketLenMismatch
+	}
+
+	bucketIdxs := make(map[int][]int)
+	for i, b := range buckets {
+		if math.IsNaN(y[i]) {
+			continue
+		}
+		bucketIdxs[b] = append(bucketIdxs[b], i)
+	}
+
+	var outlierIdx []int
+	for _, idxs := range bucketIdxs {
+		vals := make([]float64, len(idxs))
+		for i, idx := range idxs {
+			vals[i] = y[idx]
+		}
+		for _, i := range DetectOutliers(vals, lowerPerc, upperPerc, tukeyFactor) {
+			outlierIdx = append(outlierIdx, idxs[i])
+		}
+	}
+	sort.Ints(outlierIdx)
+	return outlierIdx, nil
+}
+
+// RollingMedian returns the median of the centered window around each point ignoring NaN values. Points
+// whose window contains only NaNs are NaN. Windows are truncated at the ends of the series.
+func RollingMedian(y []float64, window int) []float64 {
+	if window < 1 {
+		window = 1
+	}
+	half := window / 2
+	res := make([]float64, len(y))
+	vals := make([]float64, 0, window)
+	for i := range y {
+		start := i - half
+		end := start + window
+		if start < 0 {
+			start = 0
+		}
+		if end > len(y) {
+			end = len(y)
+		}
+		vals = vals[:0]
+		for _, v := range y[start:end] {
+			if !math.IsNaN(v) {
+				vals = append(vals, v)
+			}
+		}
+		if len(vals) == 0 {
+			res[i] = math.NaN()
+			continue
+		}
+		sort.Float64s(vals)
+		mid := len(vals) / 2
+		if len(vals)%2 == 0 {
+			res[i] = (vals[mid-1] + vals[mid]) / 2.0
+		} else {
+			res[i] = vals[mid]
+		}
+	}
+	return res
+}
+
+// SeasonalDifference returns the difference between each point and the point lag samples earlier scaled
+// by 1/sqrt(2) so that the standard deviation of uncorrelated noise is preserved. The first lag points
+// are NaN.
+func SeasonalDifference(y []float64, lag int) []float64 {
+	res := make([]float64, len(y))
+	for i := range y {
+		if lag < 1 || i < lag {
+			res[i] = math.NaN()
+			continue
+		}
+		res[i] = (y[i] - y[i-lag]) / math.Sqrt2
+	}
+	return res
+}
+
+// MovingAverage returns the mean of the centered window around each point ignoring NaN values. Points
+// whose window contains only NaNs are NaN. Windows are truncated at the ends of the series.
+func MovingAverage(y []float64, window int) []float64 {
+	if window < 1 {
+		window = 1
+	}
+	half := window / 2
+	res := make([]float64, len(y))
+	for i := range y {
+		start := i - half
+		end := start + window
+		if start < 0 {
+			start = 0
+		}
+		if end > len(y) {
+			end = len(y)
+		}
+		var sum float64
+		var cnt int
+		for _, v := range y[start:end] {
+			if !math.IsNaN(v) {
+				sum += v
+				cnt++
+			}
+		}
+		if cnt == 0 {
+			res[i] = math.NaN()
+			continue
+		}
+		res[i] = sum / float64(cnt)
+	}
+	return res
+}
+
+// SavitzkyGolay smooths the series by evaluating a least squares polynomial of the given order fit over
+// the window around each point ignoring NaN values. Windows are centered and shifted inward at the ends
+// of the series so that edge points are evaluated from the polynomial of the first or last full window
+// instead of being truncated. Points whose window has too few values for the polynomial are NaN.
+func SavitzkyGolay(y []float64, window, polyOrder int) []float64 {
+	if polyOrder < 0 {
+		polyOrder = 0
+	}
+	if window < polyOrder+1 {
+		window = polyOrder + 1
+	}
+	half := window / 2
+	numCoef := polyOrder + 1
+	res := make([]float64, len(y))
+
+	xs := make([]float64, 0, window*numCoef)
+	ys := make([]float64, 0, window)
+	for i := range y {
+		start := i - half
+		if start > len(y)-window {
+			start = len(y) - window
+		}
+		if start < 0 {
+			start = 0
+		}
+		end := start + window
+		if end > len(y) {
+			end = len(y)
+		}
+
+		// polynomial is centered on the evaluated point so its value is the constant coefficient
+		xs, ys = xs[:0], ys[:0]
+		for j := start; j < end; j++ {
+			if math.IsNaN(y[j]) {
+				continue
+			}
+			x := float64(j - i)
+			pow := 1.0
+			for k := 0; k < numCoef; k++ {
+				xs = append(xs, pow)
+				pow *= x
+			}
+			ys = append(ys, y[j])
+		}
+		if len(ys) < numCoef {
+			res[i] = math.NaN()
+			continue
+		}
+
+		var coef mat.VecDense
+		if err := coef.SolveVec(mat.NewDense(len(ys), numCoef, xs), mat.NewVecDense(len(ys), ys)); err != nil {
+			res[i] = math.NaN()
+			continue
+		}
+		res[i] = coef.AtVec(0)
+	}
+	return res
+}
@@ -0,0 +1,101 @@
+package stats
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectSeasonalOutliers(t *testing.T) {
+	// two buckets alternating between a low and high level. The high level values are not outliers
+	// relative to their own bucket, but index 6 is a spike in the low bucket.
+	y := make([]float64, 0, 40)
+	buckets := make([]int, 0, 40)
+	for i := 0; i < 20; i++ {
+		y = append(y, 1.0+0.01*float64(i), 10.0+0.01*float64(i))
+		buckets = append(buckets, 0, 1)
+	}
+	y[6] = 5.0
+	y[9] = math.NaN()
+
+	testData := map[string]struct {
+		buckets  []int
+		expected []int
+		err      error
+	}{
+		"bucket mismatch": {
+			buckets: []int{0},
+			err:     ErrBucketLenMismatch,
+		},
+		"seasonal": {
+			buckets:  buckets,
+			expected: []int{6},
+		},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			res, err := DetectSeasonalOutliers(y, td.buckets, 0.1, 0.9, 1.0)
+			if td.err != nil {
+				assert.ErrorIs(t, err, td.err)
+				return
+			}
+			require.Nil(t, err)
+			assert.Equal(t, td.expected, res)
+		})
+	}
+}
+
+func TestDetectOutliersSmallInput(t *testing.T) {
+	assert.Nil(t, DetectOutliers(nil, 0.1, 0.9, 1.0))
+	assert.Nil(t, DetectOutliers([]float64{1.0}, 0.1, 0.9, 1.0))
+	assert.Nil(t, DetectOutliers([]float64{1.0, 2.0}, 0.1, 0.9, 1.0))
+}
+
+func TestDetectOutliersIgnoresNaN(t *testing.T) {
+	y := []float64{math.NaN(), math.NaN(), math.NaN(), 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 100}
+	assert.Equal(t, []int{13}, DetectOutliers(y, 0.25, 0.75, 1.0))
+}
+
+func TestRollingMedian(t *testing.T) {
+	y := []float64{1, 100, 3, math.NaN(), 5, 6}
+	expected := []float64{50.5, 3, 51.5, 4, 5.5, 5.5}
+	assert.Equal(t, expected, RollingMedian(y, 3))
+
+	res := RollingMedian([]float64{math.NaN(), math.NaN()}, 1)
+	assert.True(t, math.IsNaN(res[0]))
+	assert.True(t, math.IsNaN(res[1]))
+}
+
+func TestSeasonalDifference(t *testing.T) {
+	res := SeasonalDifference([]float64{1, 2, 3, 5, 7}, 2)
+	assert.True(t, math.IsNaN(res[0]))
+	assert.True(t, math.IsNaN(res[1]))
+	assert.InDeltaSlice(t, []float64{2 / math.Sqrt2, 3 / math.Sqrt2, 4 / math.Sqrt2}, res[2:], 1e-12)
+}
+
+func TestMovingAverage(t *testing.T) {
+	y := []float64{1, 2, 3, math.NaN(), 5, 6}
+	expected := []float64{1.5, 2, 2.5, 4, 5.5, 5.5}
+	assert.InDeltaSlice(t, expected, MovingAverage(y, 3), 1e-12)
+}
+
+func TestSavitzkyGolay(t *testing.T) {
+	// quadratic series is reproduced exactly including the edges
+	y := make([]float64, 10)
+	for i := range y {
+		x := float64(i)
+		y[i] = 2.0 + 0.5*x - 0.1*x*x
+	}
+	assert.InDeltaSlice(t, y, SavitzkyGolay(y, 5, 2), 1e-9)
+
+	// linear fit over a window with a missing value interpolates through it
+	res := SavitzkyGolay([]float64{0, 1, math.NaN(), 3, 4}, 5, 1)
+	assert.InDeltaSlice(t, []float64{0, 1, 2, 3, 4}, res, 1e-9)
+
+	// not enough values for the polynomial
+	res = SavitzkyGolay([]float64{math.NaN(), 1, math.NaN()}, 3, 1)
+	assert.True(t, math.IsNaN(res[1]))
+}
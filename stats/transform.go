@@ -0,0 +1,146 @@
+package stats
+
+import (
+	"math"
+)
+
+const (
+	// MinTransformLambda is the smallest power searched when estimating the lambda of a power transform
+	MinTransformLambda = -5.0
+
+	// MaxTransformLambda is the largest power searched when estimating the lambda of a power transform
+	MaxTransformLambda = 5.0
+
+	lambdaTolerance = 1e-6
+)
+
+// BoxCox applies the Box-Cox power transform of the lambda to a positive value
+func BoxCox(v, lambda float64) float64 {
+	if lambda == 0 {
+		return math.Log(v)
+	}
+	return (math.Pow(v, lambda) - 1) / lambda
+}
+
+// InvBoxCox inverts the Box-Cox power transform of the lambda. Values outside the range of the
+// transform are clamped to its boundary.
+func InvBoxCox(v, lambda float64) float64 {
+	if lambda == 0 {
+		return math.Exp(v)
+	}
+	return math.Pow(math.Max(lambda*v+1, 0), 1/lambda)
+}
+
+// YeoJohnson applies the Yeo-Johnson power transform of the lambda which is defined for any value
+func YeoJohnson(v, lambda float64) float64 {
+	if v >= 0 {
+		if lambda == 0 {
+			return math.Log1p(v)
+		}
+		return (math.Pow(v+1, lambda) - 1) / lambda
+	}
+	if lambda == 2 {
+		return -math.Log1p(-v)
+	}
+	return -(math.Pow(1-v, 2-lambda) - 1) / (2 - lambda)
+}
+
+// InvYeoJohnson inverts the Yeo-Johnson power transform of the lambda. Values outside the range of
+// the transform are clamped to its boundary.
+func InvYeoJohnson(v, lambda float64) float64 {
+	if v >= 0 {
+		if lambda == 0 {
+			return math.Expm1(v)
+		}
+		return math.Pow(math.Max(lambda*v+1, 0), 1/lambda) - 1
+	}
+	if lambda == 2 {
+		return -math.Expm1(-v)
+	}
+	return 1 - math.Pow(math.Max(1-(2-lambda)*v, 0), 1/(2-lambda))
+}
+
+// BoxCoxLambda estimates the lambda of the Box-Cox transform maximizing the profile log likelihood of
+// the positive values assuming the transformed values are normally distributed. NaN values are ignored.
+func BoxCoxLambda(y []float64) float64 {
+	obs := observed(y)
+	var logSum float64
+	for _, v := range obs {
+		logSum += math.Log(v)
+	}
+	return maximizeLambda(func(lambda float64) float64 {
+		return profileLogLikelihood(obs, lambda, BoxCox) + (lambda-1)*logSum
+	})
+}
+
+// YeoJohnsonLambda estimates the lambda of the Yeo-Johnson transform maximizing the profile log
+// likelihood of the values assuming the transformed values are normally distributed. NaN values are
+// ignored.
+func YeoJohnsonLambda(y []float64) float64 {
+	obs := observed(y)
+	var logSum float64
+	for _, v := range obs {
+		if v >= 0 {
+			logSum += math.Log1p(v)
+		} else {
+			logSum -= math.Log1p(-v)
+		}
+	}
+	return maximizeLambda(func(lambda float64) float64 {
+		return profileLogLikelihood(obs, lambda, YeoJohnson) + (lambda-1)*logSum
+	})
+}
+
+// observed returns the non-NaN values
+func observed(y []float64) []float64 {
+	obs := make([]float64, 0, len(y))
+	for _, v := range y {
+		if !math.IsNaN(v) {
+			obs = append(obs, v)
+		}
+	}
+	return obs
+}
+
+// profileLogLikelihood returns the normal log likelihood of the transformed values at their maximum
+// likelihood variance excluding the jacobian of the transform
+func profileLogLikelihood(obs []float64, lambda float64, transform func(v, lambda float64) float64) float64 {
+	n := float64(len(obs))
+	var mean float64
+	z := make([]float64, len(obs))
+	for i, v := range obs {
+		z[i] = transform(v, lambda)
+		mean += z[i]
+	}
+	mean /= n
+	var variance float64
+	for _, v := range z {
+		variance += (v - mean) * (v - mean)
+	}
+	variance /= n
+	if variance <= 0 || math.IsNaN(variance) || math.IsInf(variance, 0) {
+		return math.Inf(-1)
+	}
+	return -n / 2 * math.Log(variance)
+}
+
+// maximizeLambda finds the lambda maximizing the unimodal log likelihood with a golden section search
+func maximizeLambda(llf func(lambda float64) float64) float64 {
+	invPhi := (math.Sqrt(5) - 1) / 2
+	lo, hi := MinTransformLambda, MaxTransformLambda
+	a := hi - invPhi*(hi-lo)
+	b := lo + invPhi*(hi-lo)
+	fa, fb := llf(a), llf(b)
+	for hi-lo > lambdaTolerance {
+		if fa >= fb {
+			hi, b, fb = b, a, fa
+			a = hi - invPhi*(hi-lo)
+			fa = llf(a)
+		} else {
+			lo, a, fa = a, b, fb
+			b = lo + invPhi*(hi-lo)
+			fb = llf(b)
+		}
+	}
+	return (lo + hi) / 2
+}
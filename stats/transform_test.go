@@ -0,0 +1,50 @@
+package stats
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPowerTransformInverse(t *testing.T) {
+	testData := map[string]struct {
+		vals      []float64
+		transform func(v, lambda float64) float64
+		inverse   func(v, lambda float64) float64
+	}{
+		"box-cox":     {[]float64{0.1, 1, 5, 100}, BoxCox, InvBoxCox},
+		"yeo-johnson": {[]float64{-100, -5, -0.1, 0, 0.1, 5, 100}, YeoJohnson, InvYeoJohnson},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			for _, lambda := range []float64{-1, 0, 0.5, 1, 2, 3} {
+				for _, v := range td.vals {
+					assert.InDelta(t, v, td.inverse(td.transform(v, lambda), lambda), 1e-6*math.Max(1, math.Abs(v)), "lambda %.1f value %.1f", lambda, v)
+				}
+			}
+		})
+	}
+
+	// values past the range of the transform are clamped to its boundary
+	assert.Equal(t, 0.0, InvBoxCox(-3, 0.5))
+	assert.True(t, math.IsInf(InvYeoJohnson(3, -1), 1))
+}
+
+func TestPowerTransformLambda(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	n := 2000
+	logNormal := make([]float64, n)
+	normal := make([]float64, n)
+	for i := 0; i < n; i++ {
+		logNormal[i] = math.Exp(r.NormFloat64())
+		normal[i] = 10 * r.NormFloat64()
+	}
+	logNormal[5] = math.NaN()
+
+	// a log normal series is normalized by the log transform and a normal series needs no transform
+	assert.InDelta(t, 0.0, BoxCoxLambda(logNormal), 0.1)
+	assert.InDelta(t, 1.0, YeoJohnsonLambda(normal), 0.1)
+}
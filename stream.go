@@ -0,0 +1,47 @@
+package forecaster
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/errs"
+)
+
+var ErrInvalidChunkSize = errs.NewConfigError(errs.CodeInvalidOption, "prediction chunk size must be positive", nil)
+
+// DefaultPredictChunkSize is the number of points predicted at a time by PredictStream if the chunk
+// size is unset
+const DefaultPredictChunkSize = 10000
+
+// PredictStream predicts the input times in consecutive chunks of at most chunkSize points calling fn
+// with the results of every chunk in order. Features are only generated for one chunk at a time, so
+// the memory of a prediction is bounded by the chunk size instead of the horizon, e.g. a year of
+// minutely predictions. Streaming stops at the first error from predicting a chunk or from fn and that
+// error is returned. A zero chunk size defaults to DefaultPredictChunkSize. Bootstrapped uncertainty
+// bands draw residuals per chunk so they differ from a single Predict of every time.
+func (f *Forecaster) PredictStream(t []time.Time, chunkSize int, fn func(*Results) error) error {
+	return f.PredictStreamCtx(context.Background(), t, chunkSize, fn)
+}
+
+// PredictStreamCtx predicts the input times in chunks like PredictStream returning the context error
+// if the context is done before every chunk is predicted
+func (f *Forecaster) PredictStreamCtx(ctx context.Context, t []time.Time, chunkSize int, fn func(*Results) error) error {
+	if chunkSize < 0 {
+		return fmt.Errorf("chunk size of %d, %w", chunkSize, ErrInvalidChunkSize)
+	}
+	if chunkSize == 0 {
+		chunkSize = DefaultPredictChunkSize
+	}
+	for start := 0; start < len(t); start += chunkSize {
+		end := min(start+chunkSize, len(t))
+		res, err := f.PredictCtx(ctx, t[start:end])
+		if err != nil {
+			return fmt.Errorf("unable to predict chunk [%d, %d), %w", start, end, err)
+		}
+		if err := fn(res); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package forecaster
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/forecast/options"
+	"github.com/aouyang1/go-forecaster/timedataset"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPredictStream(t *testing.T) {
+	n := 7 * 24
+	tWin := timedataset.GenerateT(n, time.Hour, func() time.Time {
+		return time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC)
+	})
+	y := timedataset.GenerateConstY(n, 10.0).
+		Add(timedataset.GenerateWaveY(tWin, 3.0, 86400.0, 1.0, 0.0))
+
+	f, err := New(nil)
+	require.Nil(t, err)
+	require.Nil(t, f.Fit(tWin, y))
+
+	horizon, err := f.MakeFuturePeriods(30*24, time.Hour)
+	require.Nil(t, err)
+	expected, err := f.Predict(horizon)
+	require.Nil(t, err)
+
+	testData := map[string]struct {
+		chunkSize int
+		numChunks int
+	}{
+		"default chunk size": {chunkSize: 0, numChunks: 1},
+		"uneven chunks":      {chunkSize: 100, numChunks: 8},
+		"single points":      {chunkSize: 1, numChunks: len(horizon)},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			var numChunks int
+			var res Results
+			err := f.PredictStream(horizon, td.chunkSize, func(r *Results) error {
+				numChunks++
+				res.T = append(res.T, r.T...)
+				res.Forecast = append(res.Forecast, r.Forecast...)
+				res.Upper = append(res.Upper, r.Upper...)
+				res.Lower = append(res.Lower, r.Lower...)
+				return nil
+			})
+			require.Nil(t, err)
+			assert.Equal(t, td.numChunks, numChunks)
+			assert.Equal(t, expected.T, res.T)
+			assert.InDeltaSlice(t, expected.Forecast, res.Forecast, 1e-9)
+			assert.InDeltaSlice(t, expected.Upper, res.Upper, 1e-9)
+			assert.InDeltaSlice(t, expected.Lower, res.Lower, 1e-9)
+		})
+	}
+
+	noop := func(r *Results) error { return nil }
+	assert.ErrorIs(t, f.PredictStream(horizon, -1, noop), ErrInvalidChunkSize)
+
+	// streaming stops at the first error of the callback
+	errStop := errors.New("stop")
+	var numChunks int
+	err = f.PredictStream(horizon, 100, func(r *Results) error {
+		numChunks++
+		return errStop
+	})
+	assert.ErrorIs(t, err, errStop)
+	assert.Equal(t, 1, numChunks)
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, f.PredictStreamCtx(canceled, horizon, 100, noop), context.Canceled)
+}
+
+func TestPredictStreamSinglePointTail(t *testing.T) {
+	n := 3 * 7 * 24
+	tWin := timedataset.GenerateT(n, time.Hour, func() time.Time {
+		return time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC)
+	})
+	y := make([]float64, n)
+	for i, tPnt := range tWin {
+		y[i] = 10.0
+		if wkday := tPnt.Weekday(); wkday == time.Saturday || wkday == time.Sunday {
+			y[i] += 20.0
+		}
+	}
+
+	opt := NewDefaultOptions()
+	opt.SeriesOptions.ForecastOptions.WeekendOptions.Enabled = true
+	opt.SeriesOptions.ForecastOptions.EventOptions.Events = []options.Event{
+		options.NewEvent("promo", tWin[0].Add(24*time.Hour), tWin[0].Add(48*time.Hour)),
+	}
+	f, err := New(opt)
+	require.Nil(t, err)
+	require.Nil(t, f.Fit(tWin, y))
+
+	// the horizon ends on a sunday in a tail chunk of a single point
+	horizon, err := f.MakeFuturePeriods(6*24+1, time.Hour)
+	require.Nil(t, err)
+	require.Equal(t, time.Sunday, horizon[len(horizon)-1].Weekday())
+	expected, err := f.Predict(horizon)
+	require.Nil(t, err)
+	assert.InDelta(t, 30.0, expected.Forecast[len(horizon)-1], 0.5)
+
+	var numChunks int
+	var forecast []float64
+	err = f.PredictStream(horizon, 6*24, func(r *Results) error {
+		numChunks++
+		forecast = append(forecast, r.Forecast...)
+		return nil
+	})
+	require.Nil(t, err)
+	assert.Equal(t, 2, numChunks)
+	assert.InDeltaSlice(t, expected.Forecast, forecast, 1e-9)
+}
@@ -0,0 +1,137 @@
+package timedataset
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/errs"
+	"golang.org/x/exp/mmap"
+)
+
+// RecordSize is the number of bytes per observation in the binary record format. Each record is
+// the unix nanosecond timestamp as a little endian int64 followed by the value as a little endian
+// IEEE 754 float64.
+const RecordSize = 16
+
+var ErrInvalidRecordSize = errs.NewDataError(errs.CodeLengthMismatch, "record data is not a multiple of the record size", nil)
+
+// ChunkReader iterates over a time dataset in chunks. Next returns io.EOF once all chunks have
+// been read.
+type ChunkReader interface {
+	Next() (*TimeDataset, error)
+}
+
+// ChunkSource opens a new ChunkReader positioned at the beginning of the dataset. Streamed fitting
+// makes multiple passes over the data so a source must be able to restart.
+type ChunkSource func() (ChunkReader, error)
+
+type sliceChunkReader struct {
+	td        *TimeDataset
+	chunkSize int
+	pos       int
+}
+
+// NewSliceChunkReader iterates over an in memory dataset in chunks of at most chunkSize observations
+func NewSliceChunkReader(td *TimeDataset, chunkSize int) ChunkReader {
+	if chunkSize <= 0 {
+		chunkSize = td.Len()
+	}
+	return &sliceChunkReader{td: td, chunkSize: chunkSize}
+}
+
+// Next returns the next chunk of the dataset
+func (s *sliceChunkReader) Next() (*TimeDataset, error) {
+	if s.pos >= s.td.Len() {
+		return nil, io.EOF
+	}
+	end := s.pos + s.chunkSize
+	if end > s.td.Len() {
+		end = s.td.Len()
+	}
+	chunk := &TimeDataset{
+		T: s.td.T[s.pos:end],
+		Y: s.td.Y[s.pos:end],
+	}
+	s.pos = end
+	return chunk, nil
+}
+
+// WriteRecords writes the dataset in the binary record format to the input writer
+func WriteRecords(w io.Writer, td *TimeDataset) error {
+	buf := make([]byte, RecordSize)
+	for i := 0; i < td.Len(); i++ {
+		binary.LittleEndian.PutUint64(buf[:8], uint64(td.T[i].UnixNano()))
+		binary.LittleEndian.PutUint64(buf[8:], math.Float64bits(td.Y[i]))
+		if _, err := w.Write(buf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type recordChunkReader struct {
+	r         io.ReaderAt
+	numRecs   int64
+	chunkSize int
+	pos       int64
+	buf       []byte
+}
+
+// NewRecordChunkReader reads observations in the binary record format from the input reader in chunks
+// of at most chunkSize records. Times are returned in UTC.
+func NewRecordChunkReader(r io.ReaderAt, size int64, chunkSize int) (ChunkReader, error) {
+	if size%RecordSize != 0 {
+		return nil, fmt.Errorf("size of %d bytes, %w", size, ErrInvalidRecordSize)
+	}
+	if chunkSize <= 0 {
+		chunkSize = int(size / RecordSize)
+	}
+	return &recordChunkReader{
+		r:         r,
+		numRecs:   size / RecordSize,
+		chunkSize: chunkSize,
+		buf:       make([]byte, chunkSize*RecordSize),
+	}, nil
+}
+
+// Next returns the next chunk of records
+func (r *recordChunkReader) Next() (*TimeDataset, error) {
+	if r.pos >= r.numRecs {
+		return nil, io.EOF
+	}
+	n := int64(r.chunkSize)
+	if r.pos+n > r.numRecs {
+		n = r.numRecs - r.pos
+	}
+	buf := r.buf[:n*RecordSize]
+	if _, err := r.r.ReadAt(buf, r.pos*RecordSize); err != nil && err != io.EOF {
+		return nil, err
+	}
+	td := &TimeDataset{
+		T: make([]time.Time, n),
+		Y: make([]float64, n),
+	}
+	for i := int64(0); i < n; i++ {
+		rec := buf[i*RecordSize : (i+1)*RecordSize]
+		td.T[i] = time.Unix(0, int64(binary.LittleEndian.Uint64(rec[:8]))).UTC()
+		td.Y[i] = math.Float64frombits(binary.LittleEndian.Uint64(rec[8:]))
+	}
+	r.pos += n
+	return td, nil
+}
+
+// MmapRecordSource memory maps a file in the binary record format and returns a source reading it
+// in chunks of at most chunkSize records along with a function to unmap the file once done.
+func MmapRecordSource(filename string, chunkSize int) (ChunkSource, func() error, error) {
+	ra, err := mmap.Open(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+	src := func() (ChunkReader, error) {
+		return NewRecordChunkReader(ra, int64(ra.Len()), chunkSize)
+	}
+	return src, ra.Close, nil
+}
@@ -0,0 +1,91 @@
+package timedataset
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readAllChunks(t *testing.T, r ChunkReader) []*TimeDataset {
+	var chunks []*TimeDataset
+	for {
+		chunk, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			return chunks
+		}
+		require.Nil(t, err)
+		chunks = append(chunks, chunk)
+	}
+}
+
+func TestChunkReaders(t *testing.T) {
+	td := &TimeDataset{
+		T: []time.Time{
+			time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(1970, 1, 1, 0, 1, 0, 0, time.UTC),
+			time.Date(1970, 1, 1, 0, 2, 0, 0, time.UTC),
+			time.Date(1970, 1, 1, 0, 3, 0, 0, time.UTC),
+			time.Date(1970, 1, 1, 0, 4, 0, 0, time.UTC),
+		},
+		Y: []float64{1, math.NaN(), 3, 4, 5},
+	}
+
+	var buf bytes.Buffer
+	require.Nil(t, WriteRecords(&buf, td))
+	assert.Equal(t, td.Len()*RecordSize, buf.Len())
+
+	filename := filepath.Join(t.TempDir(), "records.bin")
+	require.Nil(t, os.WriteFile(filename, buf.Bytes(), 0o644))
+	mmapSrc, closer, err := MmapRecordSource(filename, 2)
+	require.Nil(t, err)
+	defer closer()
+
+	testData := map[string]struct {
+		open func() (ChunkReader, error)
+	}{
+		"slice": {
+			func() (ChunkReader, error) { return NewSliceChunkReader(td, 2), nil },
+		},
+		"record": {
+			func() (ChunkReader, error) {
+				return NewRecordChunkReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()), 2)
+			},
+		},
+		"mmap": {mmapSrc},
+	}
+
+	for name, tc := range testData {
+		t.Run(name, func(t *testing.T) {
+			r, err := tc.open()
+			require.Nil(t, err)
+
+			chunks := readAllChunks(t, r)
+			require.Len(t, chunks, 3)
+			assert.Equal(t, []int{2, 2, 1}, []int{chunks[0].Len(), chunks[1].Len(), chunks[2].Len()})
+
+			var tRes []time.Time
+			var yRes []float64
+			for _, chunk := range chunks {
+				tRes = append(tRes, chunk.T...)
+				yRes = append(yRes, chunk.Y...)
+			}
+			assert.Equal(t, td.T, tRes)
+			assert.Equal(t, td.Y[0], yRes[0])
+			assert.True(t, math.IsNaN(yRes[1]))
+			assert.Equal(t, td.Y[2:], yRes[2:])
+		})
+	}
+}
+
+func TestNewRecordChunkReaderInvalidSize(t *testing.T) {
+	_, err := NewRecordChunkReader(bytes.NewReader(make([]byte, RecordSize+1)), RecordSize+1, 1)
+	assert.ErrorIs(t, err, ErrInvalidRecordSize)
+}
@@ -0,0 +1,165 @@
+package timedataset
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/errs"
+)
+
+var (
+	ErrUnknownFreqMode        = errs.NewConfigError(errs.CodeInvalidOption, "unknown frequency inference mode", nil)
+	ErrInvalidJitterTolerance = errs.NewConfigError(errs.CodeInvalidOption, "jitter tolerance must be in [0, 1)", nil)
+)
+
+// FreqMode is the statistic of the intervals between consecutive times used to infer the frequency
+type FreqMode string
+
+const (
+	// FreqModeMode infers the most common interval which is robust to gaps and is the default if unset
+	FreqModeMode FreqMode = "mode"
+
+	// FreqModeMin infers the smallest interval which suits series with frequent gaps
+	FreqModeMin FreqMode = "min"
+
+	// FreqModeMedian infers the median interval
+	FreqModeMedian FreqMode = "median"
+)
+
+// DefaultJitterTolerance is the jitter tolerance used if FreqOptions leaves it unset
+const DefaultJitterTolerance = 0.05
+
+// FreqOptions configures the inference of the sampling interval of a time slice. Intervals within
+// JitterTolerance of the inferred interval as a fraction of it are treated as the same interval e.g.
+// 0.1 groups 59s and 61s intervals into a 60s interval. The inferred interval is the median of the
+// intervals grouped with it so small timing jitter does not bias the result. JitterTolerance defaults
+// to DefaultJitterTolerance if nil and a tolerance of 0 only groups identical intervals.
+type FreqOptions struct {
+	Mode            FreqMode `json:"mode,omitempty"`
+	JitterTolerance *float64 `json:"jitter_tolerance,omitempty"`
+}
+
+// SetJitterTolerance sets the jitter tolerance ignoring NaN
+func (o *FreqOptions) SetJitterTolerance(tol float64) {
+	if math.IsNaN(tol) {
+		return
+	}
+	o.JitterTolerance = &tol
+}
+
+// jitterTolerance returns the jitter tolerance defaulting to DefaultJitterTolerance
+func (o FreqOptions) jitterTolerance() float64 {
+	if o.JitterTolerance == nil {
+		return DefaultJitterTolerance
+	}
+	return *o.JitterTolerance
+}
+
+// Validate returns an error if the mode is unknown or the jitter tolerance is out of range
+func (o FreqOptions) Validate() error {
+	switch o.Mode {
+	case "", FreqModeMode, FreqModeMin, FreqModeMedian:
+	default:
+		return fmt.Errorf("%q, %w", o.Mode, ErrUnknownFreqMode)
+	}
+	if tol := o.jitterTolerance(); !(tol >= 0 && tol < 1) {
+		return fmt.Errorf("jitter tolerance of %.3f, %w", tol, ErrInvalidJitterTolerance)
+	}
+	return nil
+}
+
+// FreqEstimate is an inferred sampling interval along with the fraction of the intervals between
+// consecutive times consistent with it. An interval is consistent if it is within the jitter tolerance
+// of a whole multiple of the inferred interval so gaps of missing samples do not lower the confidence
+// while irregular sampling does.
+type FreqEstimate struct {
+	Freq       time.Duration `json:"freq"`
+	Confidence float64       `json:"confidence"`
+}
+
+// InferFreq infers the sampling interval of the times from the positive intervals between consecutive
+// times. An error is returned if there are fewer than two times or no positive interval.
+func (t TimeSlice) InferFreq(opt FreqOptions) (FreqEstimate, error) {
+	if err := opt.Validate(); err != nil {
+		return FreqEstimate{}, err
+	}
+	if len(t) < 2 {
+		return FreqEstimate{}, ErrCannotInferFreq
+	}
+
+	deltas := make([]time.Duration, 0, len(t)-1)
+	for i := 1; i < len(t); i++ {
+		if delta := t[i].Sub(t[i-1]); delta > 0 {
+			deltas = append(deltas, delta)
+		}
+	}
+	if len(deltas) == 0 {
+		return FreqEstimate{}, ErrCannotInferFreq
+	}
+	slices.Sort(deltas)
+
+	tol := opt.jitterTolerance()
+	var freq time.Duration
+	switch opt.Mode {
+	case FreqModeMin:
+		freq = medianWithin(deltas, deltas[0], tol)
+	case FreqModeMedian:
+		freq = deltas[len(deltas)/2]
+	default:
+		// the most grouped interval preferring the smaller interval on ties. The bounds of the group of
+		// each interval only increase with the interval so they are advanced over the sorted intervals.
+		var maxCnt, lo, hi int
+		for i, delta := range deltas {
+			if i > 0 && delta == deltas[i-1] {
+				continue
+			}
+			for lo < len(deltas) && !within(deltas[lo], delta, tol) && deltas[lo] < delta {
+				lo++
+			}
+			if hi < lo {
+				hi = lo
+			}
+			for hi < len(deltas) && within(deltas[hi], delta, tol) {
+				hi++
+			}
+			if cnt := hi - lo; cnt > maxCnt {
+				maxCnt = cnt
+				freq = delta
+			}
+		}
+		freq = medianWithin(deltas, freq, tol)
+	}
+
+	var consistent int
+	for _, delta := range deltas {
+		multiple := math.Max(math.Round(float64(delta)/float64(freq)), 1)
+		if within(delta, time.Duration(multiple*float64(freq)), tol) {
+			consistent++
+		}
+	}
+	return FreqEstimate{
+		Freq:       freq,
+		Confidence: float64(consistent) / float64(len(deltas)),
+	}, nil
+}
+
+// within returns true if the interval is within the tolerance of the reference interval
+func within(delta, ref time.Duration, tol float64) bool {
+	return math.Abs(float64(delta-ref)) <= tol*float64(ref)
+}
+
+// medianWithin returns the median of the sorted intervals within the tolerance of the reference interval
+func medianWithin(deltas []time.Duration, ref time.Duration, tol float64) time.Duration {
+	var group []time.Duration
+	for _, delta := range deltas {
+		if within(delta, ref, tol) {
+			group = append(group, delta)
+		}
+	}
+	if len(group) == 0 {
+		return ref
+	}
+	return group[len(group)/2]
+}
@@ -0,0 +1,223 @@
+package timedataset
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/errs"
+)
+
+var (
+	ErrMissingColumn      = errs.NewDataError(errs.CodeNotFound, "column not found in header", nil)
+	ErrInvalidTimeValue   = errs.NewDataError(errs.CodeInvalidValue, "unable to parse time", nil)
+	ErrInvalidObservation = errs.NewDataError(errs.CodeInvalidValue, "unable to parse observation", nil)
+)
+
+const (
+	// LayoutUnix parses times as integer or fractional seconds since the unix epoch
+	LayoutUnix = "unix"
+
+	// LayoutUnixMilli parses times as integer milliseconds since the unix epoch
+	LayoutUnixMilli = "unix_ms"
+)
+
+// DefaultMissingValues are the cell values treated as a missing observation
+var DefaultMissingValues = []string{"", "na", "nan", "null", "none"}
+
+type loadOptions struct {
+	loc           *time.Location
+	resample      *ResampleOptions
+	dropNaN       bool
+	missingValues []string
+}
+
+// LoadOption configures how a dataset is parsed from a file
+type LoadOption func(o *loadOptions)
+
+// WithLocation parses times without a zone offset in the location instead of UTC
+func WithLocation(loc *time.Location) LoadOption {
+	return func(o *loadOptions) {
+		o.loc = loc
+	}
+}
+
+// WithResample aligns the observations to a regular grid with the resample options
+func WithResample(opt ResampleOptions) LoadOption {
+	return func(o *loadOptions) {
+		o.resample = &opt
+	}
+}
+
+// WithDropNaN removes every missing observation from the dataset after any resampling
+func WithDropNaN() LoadOption {
+	return func(o *loadOptions) {
+		o.dropNaN = true
+	}
+}
+
+// WithMissingValues overrides the case insensitive cell values treated as a missing observation
+func WithMissingValues(vals ...string) LoadOption {
+	return func(o *loadOptions) {
+		o.missingValues = vals
+	}
+}
+
+// FromCSV loads a dataset from CSV with a header row reading the times and observations from the named
+// columns. Times are parsed with the layout which is either a time.Parse layout, LayoutUnix, or
+// LayoutUnixMilli. Missing observations are NaN and rows are sorted by time so the input does not need
+// to be ordered. Repeated times are an error unless the dataset is resampled.
+func FromCSV(r io.Reader, timeCol, valueCol string, layout string, opts ...LoadOption) (*TimeDataset, error) {
+	o, err := newLoadOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, ErrNoTrainingData
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read csv header, %w", err)
+	}
+	timeIdx := slices.Index(header, timeCol)
+	if timeIdx == -1 {
+		return nil, fmt.Errorf("time column %q, %w", timeCol, ErrMissingColumn)
+	}
+	valueIdx := slices.Index(header, valueCol)
+	if valueIdx == -1 {
+		return nil, fmt.Errorf("value column %q, %w", valueCol, ErrMissingColumn)
+	}
+
+	td := &TimeDataset{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to read csv row %d, %w", len(td.T)+1, err)
+		}
+		t, err := parseTime(record[timeIdx], layout, o.loc)
+		if err != nil {
+			return nil, fmt.Errorf("row %d, %w", len(td.T)+1, err)
+		}
+		y, err := o.parseObservation(record[valueIdx])
+		if err != nil {
+			return nil, fmt.Errorf("row %d, %w", len(td.T)+1, err)
+		}
+		td.T = append(td.T, t)
+		td.Y = append(td.Y, y)
+	}
+	return o.build(td)
+}
+
+// FromColumns loads a dataset from times and observations already decoded from a columnar source such
+// as a Parquet or Arrow file read with a library of the caller's choice, applying the same ordering,
+// resampling, and NaN handling as FromCSV. NaN observations are missing. WithLocation and
+// WithMissingValues only apply to parsing text and are ignored. The input slices are not modified.
+func FromColumns(t []time.Time, y []float64, opts ...LoadOption) (*TimeDataset, error) {
+	if len(t) != len(y) {
+		return nil, fmt.Errorf("got %d times and %d observations, %w", len(t), len(y), ErrDatasetLenMismatch)
+	}
+	o, err := newLoadOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	return o.build(&TimeDataset{T: t, Y: y})
+}
+
+// newLoadOptions applies the load options on top of the defaults
+func newLoadOptions(opts []LoadOption) (*loadOptions, error) {
+	o := &loadOptions{
+		loc:           time.UTC,
+		missingValues: DefaultMissingValues,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.resample != nil {
+		if err := o.resample.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	return o, nil
+}
+
+// build orders the parsed dataset by time, resamples it, and drops missing observations if configured
+func (o *loadOptions) build(td *TimeDataset) (*TimeDataset, error) {
+	idx := make([]int, len(td.T))
+	for i := range idx {
+		idx[i] = i
+	}
+	slices.SortStableFunc(idx, func(a, b int) int {
+		return td.T[a].Compare(td.T[b])
+	})
+	sorted := &TimeDataset{
+		T: make([]time.Time, len(idx)),
+		Y: make([]float64, len(idx)),
+	}
+	for i, j := range idx {
+		sorted.T[i] = td.T[j]
+		sorted.Y[i] = td.Y[j]
+	}
+
+	if o.resample != nil && len(sorted.T) > 0 {
+		var err error
+		sorted, err = sorted.Resample(*o.resample)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if o.dropNaN {
+		sorted = sorted.DropNan()
+	}
+	return NewUnivariateDataset(sorted.T, sorted.Y)
+}
+
+// parseTime parses the time value with the layout in the location
+func parseTime(val, layout string, loc *time.Location) (time.Time, error) {
+	val = strings.TrimSpace(val)
+	switch layout {
+	case LayoutUnix:
+		sec, err := strconv.ParseFloat(val, 64)
+		if err != nil || math.IsNaN(sec) || math.IsInf(sec, 0) {
+			return time.Time{}, fmt.Errorf("%q as unix seconds, %w", val, ErrInvalidTimeValue)
+		}
+		whole, frac := math.Modf(sec)
+		return time.Unix(int64(whole), int64(frac*1e9)).In(loc), nil
+	case LayoutUnixMilli:
+		ms, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%q as unix milliseconds, %w", val, ErrInvalidTimeValue)
+		}
+		return time.UnixMilli(ms).In(loc), nil
+	}
+	t, err := time.ParseInLocation(layout, val, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q with layout %q, %w", val, layout, ErrInvalidTimeValue)
+	}
+	return t, nil
+}
+
+// parseObservation parses the observation returning NaN for any missing value
+func (o *loadOptions) parseObservation(val string) (float64, error) {
+	val = strings.TrimSpace(val)
+	for _, missing := range o.missingValues {
+		if strings.EqualFold(val, missing) {
+			return math.NaN(), nil
+		}
+	}
+	y, err := strconv.ParseFloat(val, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%q, %w", val, ErrInvalidObservation)
+	}
+	return y, nil
+}
@@ -0,0 +1,207 @@
+package timedataset
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromCSV(t *testing.T) {
+	nyc, err := time.LoadLocation("America/New_York")
+	require.Nil(t, err)
+
+	nan := math.NaN()
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	testData := map[string]struct {
+		input    string
+		layout   string
+		opts     []LoadOption
+		expected *TimeDataset
+		err      error
+	}{
+		"layout with missing and unordered rows": {
+			input:  "value,ts\n2,2024-01-01 00:01:00\nNaN,2024-01-01 00:02:00\n1,2024-01-01 00:00:00\n,2024-01-01 00:03:00\n",
+			layout: time.DateTime,
+			expected: &TimeDataset{
+				T: []time.Time{t0, t0.Add(time.Minute), t0.Add(2 * time.Minute), t0.Add(3 * time.Minute)},
+				Y: []float64{1, 2, nan, nan},
+			},
+		},
+		"unix seconds drop nan": {
+			input:  "ts,value,other\n1704067200,1,a\n1704067260,null,b\n1704067320,3,c\n",
+			layout: LayoutUnix,
+			opts:   []LoadOption{WithDropNaN()},
+			expected: &TimeDataset{
+				T: []time.Time{t0, t0.Add(2 * time.Minute)},
+				Y: []float64{1, 3},
+			},
+		},
+		"unix milliseconds": {
+			input:  "ts,value\n1704067200000,1\n1704067200500,2\n",
+			layout: LayoutUnixMilli,
+			expected: &TimeDataset{
+				T: []time.Time{t0, t0.Add(500 * time.Millisecond)},
+				Y: []float64{1, 2},
+			},
+		},
+		"location": {
+			input:  "ts,value\n2024-01-01 00:00:00,1\n",
+			layout: time.DateTime,
+			opts:   []LoadOption{WithLocation(nyc)},
+			expected: &TimeDataset{
+				T: []time.Time{t0.Add(5 * time.Hour)},
+				Y: []float64{1},
+			},
+		},
+		"offset overrides location": {
+			input:  "ts,value\n2024-01-01T00:00:00Z,1\n",
+			layout: time.RFC3339,
+			opts:   []LoadOption{WithLocation(nyc)},
+			expected: &TimeDataset{
+				T: []time.Time{t0},
+				Y: []float64{1},
+			},
+		},
+		"resample": {
+			input:  "ts,value\n2024-01-01T00:00:10Z,1\n2024-01-01T00:00:20Z,3\n2024-01-01T00:02:50Z,5\n2024-01-01T00:03:20Z,nan\n",
+			layout: time.RFC3339,
+			opts:   []LoadOption{WithResample(ResampleOptions{Interval: time.Minute})},
+			expected: &TimeDataset{
+				T: []time.Time{t0, t0.Add(time.Minute), t0.Add(2 * time.Minute), t0.Add(3 * time.Minute)},
+				Y: []float64{2, nan, nan, 5},
+			},
+		},
+		"custom missing values": {
+			input:  "ts,value\n1704067200,-\n",
+			layout: LayoutUnix,
+			opts:   []LoadOption{WithMissingValues("-")},
+			expected: &TimeDataset{
+				T: []time.Time{t0},
+				Y: []float64{nan},
+			},
+		},
+		"repeated time": {
+			input:  "ts,value\n1704067200,1\n1704067200,2\n",
+			layout: LayoutUnix,
+			err:    ErrNonMontonic,
+		},
+		"missing time column": {
+			input:  "time,value\n1704067200,1\n",
+			layout: LayoutUnix,
+			err:    ErrMissingColumn,
+		},
+		"missing value column": {
+			input:  "ts,val\n1704067200,1\n",
+			layout: LayoutUnix,
+			err:    ErrMissingColumn,
+		},
+		"invalid time": {
+			input:  "ts,value\nyesterday,1\n",
+			layout: time.RFC3339,
+			err:    ErrInvalidTimeValue,
+		},
+		"invalid observation": {
+			input:  "ts,value\n1704067200,abc\n",
+			layout: LayoutUnix,
+			err:    ErrInvalidObservation,
+		},
+		"invalid resample": {
+			input:  "ts,value\n1704067200,1\n",
+			layout: LayoutUnix,
+			opts:   []LoadOption{WithResample(ResampleOptions{Interval: -time.Minute})},
+			err:    ErrInvalidResampleInterval,
+		},
+		"no header": {
+			layout: LayoutUnix,
+			err:    ErrNoTrainingData,
+		},
+		"no rows": {
+			input:  "ts,value\n",
+			layout: LayoutUnix,
+			err:    ErrNoTrainingData,
+		},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			res, err := FromCSV(strings.NewReader(td.input), "ts", "value", td.layout, td.opts...)
+			if td.err != nil {
+				assert.ErrorIs(t, err, td.err)
+				return
+			}
+			require.Nil(t, err)
+			require.Len(t, res.T, len(td.expected.T))
+			for i := range td.expected.T {
+				assert.True(t, td.expected.T[i].Equal(res.T[i]), "index %d, expected %s, got %s", i, td.expected.T[i], res.T[i])
+			}
+			assert.InDeltaSlice(t, td.expected.Y, res.Y, 1e-9)
+		})
+	}
+}
+
+func TestFromColumns(t *testing.T) {
+	nan := math.NaN()
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	testData := map[string]struct {
+		t        []time.Time
+		y        []float64
+		opts     []LoadOption
+		expected *TimeDataset
+		err      error
+	}{
+		"unordered with missing": {
+			t: []time.Time{t0.Add(2 * time.Minute), t0, t0.Add(time.Minute)},
+			y: []float64{3, 1, nan},
+			expected: &TimeDataset{
+				T: []time.Time{t0, t0.Add(time.Minute), t0.Add(2 * time.Minute)},
+				Y: []float64{1, nan, 3},
+			},
+		},
+		"resample and drop nan": {
+			t:    []time.Time{t0.Add(10 * time.Second), t0.Add(20 * time.Second), t0.Add(2*time.Minute + 50*time.Second)},
+			y:    []float64{1, 3, 5},
+			opts: []LoadOption{WithResample(ResampleOptions{Interval: time.Minute}), WithDropNaN()},
+			expected: &TimeDataset{
+				T: []time.Time{t0, t0.Add(3 * time.Minute)},
+				Y: []float64{2, 5},
+			},
+		},
+		"mismatched length": {
+			t:   []time.Time{t0},
+			y:   []float64{1, 2},
+			err: ErrDatasetLenMismatch,
+		},
+		"repeated time": {
+			t:   []time.Time{t0, t0},
+			y:   []float64{1, 2},
+			err: ErrNonMontonic,
+		},
+		"no rows": {
+			err: ErrNoTrainingData,
+		},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			tIn := append([]time.Time(nil), td.t...)
+			res, err := FromColumns(td.t, td.y, td.opts...)
+			if td.err != nil {
+				assert.ErrorIs(t, err, td.err)
+				return
+			}
+			require.Nil(t, err)
+			assert.Equal(t, tIn, td.t)
+			require.Len(t, res.T, len(td.expected.T))
+			for i := range td.expected.T {
+				assert.True(t, td.expected.T[i].Equal(res.T[i]), "index %d, expected %s, got %s", i, td.expected.T[i], res.T[i])
+			}
+			assert.InDeltaSlice(t, td.expected.Y, res.Y, 1e-9)
+		})
+	}
+}
@@ -0,0 +1,735 @@
+package timedataset
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/errs"
+)
+
+var (
+	ErrInvalidParquet     = errs.NewDataError(errs.CodeInvalidValue, "invalid parquet file", nil)
+	ErrUnsupportedParquet = errs.NewDataError(errs.CodeInvalidValue, "unsupported parquet column", nil)
+)
+
+// parquetMagic starts and ends every parquet file
+var parquetMagic = []byte("PAR1")
+
+// parquet physical types
+const (
+	parquetInt32  = 1
+	parquetInt64  = 2
+	parquetInt96  = 3
+	parquetFloat  = 4
+	parquetDouble = 5
+)
+
+// parquet converted types of the time column
+const (
+	parquetConvertedDate            = 6
+	parquetConvertedTimestampMillis = 9
+	parquetConvertedTimestampMicros = 10
+)
+
+// parquet repetition types
+const (
+	parquetRequired = 0
+	parquetOptional = 1
+)
+
+// parquet compression codecs
+const (
+	parquetUncompressed = 0
+	parquetSnappy       = 1
+	parquetGzip         = 2
+)
+
+// parquet page types
+const (
+	parquetDataPage       = 0
+	parquetDictionaryPage = 2
+	parquetDataPageV2     = 3
+)
+
+// parquet value encodings
+const (
+	parquetPlain           = 0
+	parquetPlainDictionary = 2
+	parquetRLEDictionary   = 8
+)
+
+// julianUnixEpoch is the julian day of the unix epoch used by INT96 timestamps
+const julianUnixEpoch = 2440588
+
+// FromParquet loads a dataset from the Parquet file of the size reading the times and observations
+// from the named top level columns. The time column is an INT64 timestamp of any unit, a legacy INT96
+// timestamp, or an INT32 date. The value column is a DOUBLE, FLOAT, INT32, or INT64 where nulls are
+// missing observations. Timestamps not adjusted to UTC and dates are wall times in the WithLocation
+// location and other timestamps are converted to it. Uncompressed, snappy, and gzip pages of plain or
+// dictionary encoded values are supported. Rows are ordered, resampled, and NaN handled like FromCSV.
+// WithMissingValues only applies to parsing text and is ignored.
+func FromParquet(r io.ReaderAt, size int64, timeCol, valueCol string, opts ...LoadOption) (*TimeDataset, error) {
+	o, err := newLoadOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	meta, err := readParquetMetadata(r, size)
+	if err != nil {
+		return nil, err
+	}
+	timeIdx, err := meta.columnIndex(timeCol)
+	if err != nil {
+		return nil, fmt.Errorf("time column %q, %w", timeCol, err)
+	}
+	valueIdx, err := meta.columnIndex(valueCol)
+	if err != nil {
+		return nil, fmt.Errorf("value column %q, %w", valueCol, err)
+	}
+
+	timeLeaf := meta.leaves[timeIdx]
+	valueLeaf := meta.leaves[valueIdx]
+	if !timeLeaf.isTime() {
+		return nil, fmt.Errorf("time column %q is not a timestamp or date, %w", timeCol, ErrUnsupportedParquet)
+	}
+	switch valueLeaf.typ {
+	case parquetInt32, parquetInt64, parquetFloat, parquetDouble:
+	default:
+		return nil, fmt.Errorf("value column %q of physical type %d, %w", valueCol, valueLeaf.typ, ErrUnsupportedParquet)
+	}
+
+	td := &TimeDataset{}
+	for i, rg := range meta.rowGroups {
+		if len(rg) != len(meta.leaves) {
+			return nil, fmt.Errorf("row group %d has %d of %d columns, %w", i, len(rg), len(meta.leaves), ErrInvalidParquet)
+		}
+		times, err := readParquetColumn(r, size, rg[timeIdx], timeLeaf)
+		if err != nil {
+			return nil, fmt.Errorf("time column %q of row group %d, %w", timeCol, i, err)
+		}
+		values, err := readParquetColumn(r, size, rg[valueIdx], valueLeaf)
+		if err != nil {
+			return nil, fmt.Errorf("value column %q of row group %d, %w", valueCol, i, err)
+		}
+		if len(times.valid) != len(values.valid) {
+			return nil, fmt.Errorf("row group %d has %d times and %d values, %w", i, len(times.valid), len(values.valid), ErrInvalidParquet)
+		}
+		for j := range times.valid {
+			if !times.valid[j] {
+				return nil, fmt.Errorf("row %d has a null time, %w", len(td.T)+1, ErrInvalidTimeValue)
+			}
+			td.T = append(td.T, timeLeaf.toTime(times.ints[j], o.loc))
+			td.Y = append(td.Y, values.float(j))
+		}
+	}
+	return o.build(td)
+}
+
+// parquetLeaf is a primitive column of the parquet schema
+type parquetLeaf struct {
+	path          []string
+	typ           int32
+	repetition    int32
+	convertedType int32
+
+	// timestampUnit is the unit of a timestamp column or zero if the column is not a timestamp
+	timestampUnit time.Duration
+	adjustedToUTC bool
+	date          bool
+}
+
+// isTime returns true if the leaf is a timestamp or date
+func (l parquetLeaf) isTime() bool {
+	switch l.typ {
+	case parquetInt96:
+		return true
+	case parquetInt64:
+		return l.timestampUnit > 0
+	case parquetInt32:
+		return l.date
+	}
+	return false
+}
+
+// toTime converts a decoded time value to a time in the location. INT96 values are decoded to
+// nanoseconds.
+func (l parquetLeaf) toTime(v int64, loc *time.Location) time.Time {
+	switch {
+	case l.typ == parquetInt32:
+		t := time.Unix(v*86400, 0).UTC()
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+	case l.typ == parquetInt96:
+		return time.Unix(0, v).In(loc)
+	case !l.adjustedToUTC:
+		t := time.Unix(0, v*int64(l.timestampUnit)).UTC()
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc)
+	}
+	return time.Unix(0, v*int64(l.timestampUnit)).In(loc)
+}
+
+// parquetColumnChunk is the location of the pages of a column in a row group
+type parquetColumnChunk struct {
+	codec          int32
+	numValues      int64
+	dataOffset     int64
+	dictOffset     int64
+	compressedSize int64
+}
+
+// parquetMetadata is the schema and column chunks of a parquet file
+type parquetMetadata struct {
+	leaves    []parquetLeaf
+	rowGroups [][]parquetColumnChunk
+}
+
+// columnIndex returns the index of the top level primitive column of the name
+func (m *parquetMetadata) columnIndex(name string) (int, error) {
+	for i, leaf := range m.leaves {
+		if len(leaf.path) != 1 || leaf.path[0] != name {
+			continue
+		}
+		if leaf.repetition != parquetRequired && leaf.repetition != parquetOptional {
+			return 0, fmt.Errorf("repeated column, %w", ErrUnsupportedParquet)
+		}
+		return i, nil
+	}
+	return 0, ErrMissingColumn
+}
+
+// readParquetMetadata reads the file metadata from the footer of the parquet file
+func readParquetMetadata(r io.ReaderAt, size int64) (*parquetMetadata, error) {
+	if size < 12 {
+		return nil, fmt.Errorf("file of %d bytes, %w", size, ErrInvalidParquet)
+	}
+	footer := make([]byte, 8)
+	if _, err := r.ReadAt(footer, size-8); err != nil {
+		return nil, fmt.Errorf("unable to read parquet footer, %w", err)
+	}
+	if !bytes.Equal(footer[4:], parquetMagic) {
+		return nil, fmt.Errorf("missing footer magic, %w", ErrInvalidParquet)
+	}
+	metaLen := int64(binary.LittleEndian.Uint32(footer))
+	if metaLen > size-12 {
+		return nil, fmt.Errorf("metadata of %d bytes, %w", metaLen, ErrInvalidParquet)
+	}
+	b := make([]byte, metaLen)
+	if _, err := r.ReadAt(b, size-8-metaLen); err != nil {
+		return nil, fmt.Errorf("unable to read parquet metadata, %w", err)
+	}
+
+	var elems []parquetLeaf
+	var numChildren []int32
+	meta := &parquetMetadata{}
+	tr := &thriftReader{b: b}
+	err := tr.readStruct(func(id int16, typ byte) error {
+		switch {
+		case id == 2 && typ == thriftList:
+			return tr.readList(func(byte) error {
+				elem, children, err := readSchemaElement(tr)
+				elems = append(elems, elem)
+				numChildren = append(numChildren, children)
+				return err
+			})
+		case id == 4 && typ == thriftList:
+			return tr.readList(func(byte) error {
+				rg, err := readRowGroup(tr)
+				meta.rowGroups = append(meta.rowGroups, rg)
+				return err
+			})
+		}
+		return tr.skip(typ)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode parquet metadata, %w", err)
+	}
+	if len(elems) == 0 {
+		return nil, fmt.Errorf("empty schema, %w", ErrInvalidParquet)
+	}
+
+	// the schema is a depth first flattening of the tree below the root element
+	var walk func(i int, path []string) (int, error)
+	walk = func(i int, path []string) (int, error) {
+		if i >= len(elems) {
+			return 0, fmt.Errorf("schema ends before its children, %w", ErrInvalidParquet)
+		}
+		elem := elems[i]
+		path = append(path[:len(path):len(path)], elem.path[0])
+		if numChildren[i] <= 0 {
+			elem.path = path
+			meta.leaves = append(meta.leaves, elem)
+			return i + 1, nil
+		}
+		next := i + 1
+		for c := int32(0); c < numChildren[i]; c++ {
+			var err error
+			if next, err = walk(next, path); err != nil {
+				return 0, err
+			}
+		}
+		return next, nil
+	}
+	next := 1
+	for c := int32(0); c < numChildren[0]; c++ {
+		if next, err = walk(next, nil); err != nil {
+			return nil, err
+		}
+	}
+	return meta, nil
+}
+
+// readSchemaElement reads a schema element returning it as a leaf with its name as the path along with
+// its number of children
+func readSchemaElement(tr *thriftReader) (parquetLeaf, int32, error) {
+	leaf := parquetLeaf{convertedType: -1, adjustedToUTC: true}
+	var numChildren int32
+	err := tr.readStruct(func(id int16, typ byte) error {
+		var err error
+		switch id {
+		case 1:
+			leaf.typ, err = tr.readI32(typ)
+		case 3:
+			leaf.repetition, err = tr.readI32(typ)
+		case 4:
+			if typ != thriftBinary {
+				return tr.skip(typ)
+			}
+			var name []byte
+			name, err = tr.readBinary()
+			leaf.path = []string{string(name)}
+		case 5:
+			numChildren, err = tr.readI32(typ)
+		case 6:
+			leaf.convertedType, err = tr.readI32(typ)
+			switch leaf.convertedType {
+			case parquetConvertedDate:
+				leaf.date = true
+			case parquetConvertedTimestampMillis:
+				leaf.timestampUnit = time.Millisecond
+			case parquetConvertedTimestampMicros:
+				leaf.timestampUnit = time.Microsecond
+			}
+		case 10:
+			if typ != thriftStruct {
+				return tr.skip(typ)
+			}
+			err = readLogicalType(tr, &leaf)
+		default:
+			err = tr.skip(typ)
+		}
+		return err
+	})
+	if err == nil && len(leaf.path) == 0 {
+		err = fmt.Errorf("schema element without a name, %w", ErrInvalidParquet)
+	}
+	return leaf, numChildren, err
+}
+
+// readLogicalType sets the timestamp unit and UTC adjustment or date of the leaf from its logical type
+func readLogicalType(tr *thriftReader, leaf *parquetLeaf) error {
+	return tr.readStruct(func(id int16, typ byte) error {
+		switch {
+		case id == 6 && typ == thriftStruct:
+			leaf.date = true
+			return tr.skip(typ)
+		case id == 8 && typ == thriftStruct:
+			return tr.readStruct(func(id int16, typ byte) error {
+				switch {
+				case id == 1 && (typ == thriftBoolTrue || typ == thriftBoolFalse):
+					leaf.adjustedToUTC = typ == thriftBoolTrue
+					return nil
+				case id == 2 && typ == thriftStruct:
+					return tr.readStruct(func(id int16, typ byte) error {
+						switch id {
+						case 1:
+							leaf.timestampUnit = time.Millisecond
+						case 2:
+							leaf.timestampUnit = time.Microsecond
+						case 3:
+							leaf.timestampUnit = time.Nanosecond
+						}
+						return tr.skip(typ)
+					})
+				}
+				return tr.skip(typ)
+			})
+		}
+		return tr.skip(typ)
+	})
+}
+
+// readRowGroup reads the column chunks of a row group
+func readRowGroup(tr *thriftReader) ([]parquetColumnChunk, error) {
+	var chunks []parquetColumnChunk
+	err := tr.readStruct(func(id int16, typ byte) error {
+		if id != 1 || typ != thriftList {
+			return tr.skip(typ)
+		}
+		return tr.readList(func(byte) error {
+			var chunk parquetColumnChunk
+			err := tr.readStruct(func(id int16, typ byte) error {
+				switch {
+				case id == 1 && typ == thriftBinary:
+					return fmt.Errorf("column chunk in an external file, %w", ErrUnsupportedParquet)
+				case id == 3 && typ == thriftStruct:
+					return readColumnMetadata(tr, &chunk)
+				}
+				return tr.skip(typ)
+			})
+			chunks = append(chunks, chunk)
+			return err
+		})
+	})
+	return chunks, err
+}
+
+// readColumnMetadata reads the codec, number of values, and page offsets of a column chunk
+func readColumnMetadata(tr *thriftReader, chunk *parquetColumnChunk) error {
+	return tr.readStruct(func(id int16, typ byte) error {
+		var err error
+		switch id {
+		case 4:
+			chunk.codec, err = tr.readI32(typ)
+		case 5:
+			chunk.numValues, err = tr.readI64(typ)
+		case 7:
+			chunk.compressedSize, err = tr.readI64(typ)
+		case 9:
+			chunk.dataOffset, err = tr.readI64(typ)
+		case 11:
+			chunk.dictOffset, err = tr.readI64(typ)
+		default:
+			err = tr.skip(typ)
+		}
+		return err
+	})
+}
+
+// parquetValues are the decoded values of a column where integer and timestamp types are in ints and
+// floating point types are in floats. Null values are not valid.
+type parquetValues struct {
+	ints   []int64
+	floats []float64
+	valid  []bool
+}
+
+// float returns the value at the index as a float or NaN if null
+func (v *parquetValues) float(i int) float64 {
+	switch {
+	case !v.valid[i]:
+		return math.NaN()
+	case v.floats != nil:
+		return v.floats[i]
+	}
+	return float64(v.ints[i])
+}
+
+// append appends the value at the index of the source values
+func (v *parquetValues) append(src *parquetValues, i int) {
+	if src.floats != nil {
+		v.floats = append(v.floats, src.floats[i])
+	} else {
+		v.ints = append(v.ints, src.ints[i])
+	}
+	v.valid = append(v.valid, true)
+}
+
+// appendNull appends a null value
+func (v *parquetValues) appendNull(isFloat bool) {
+	if isFloat {
+		v.floats = append(v.floats, math.NaN())
+	} else {
+		v.ints = append(v.ints, 0)
+	}
+	v.valid = append(v.valid, false)
+}
+
+// parquetPageHeader is the header of a page of a column chunk
+type parquetPageHeader struct {
+	typ              int32
+	uncompressedSize int32
+	compressedSize   int32
+
+	numValues      int32
+	encoding       int32
+	defLevelsLen   int32
+	repLevelsLen   int32
+	levelsInHeader bool
+	compressed     bool
+}
+
+// readPageHeader reads a data, dictionary, or v2 data page header
+func readPageHeader(tr *thriftReader) (parquetPageHeader, error) {
+	h := parquetPageHeader{compressed: true}
+	err := tr.readStruct(func(id int16, typ byte) error {
+		var err error
+		switch {
+		case id == 1:
+			h.typ, err = tr.readI32(typ)
+		case id == 2:
+			h.uncompressedSize, err = tr.readI32(typ)
+		case id == 3:
+			h.compressedSize, err = tr.readI32(typ)
+		case (id == 5 || id == 7) && typ == thriftStruct:
+			// data page and dictionary page headers start with the number of values and encoding
+			err = tr.readStruct(func(id int16, typ byte) error {
+				var err error
+				switch id {
+				case 1:
+					h.numValues, err = tr.readI32(typ)
+				case 2:
+					h.encoding, err = tr.readI32(typ)
+				default:
+					err = tr.skip(typ)
+				}
+				return err
+			})
+		case id == 8 && typ == thriftStruct:
+			h.levelsInHeader = true
+			err = tr.readStruct(func(id int16, typ byte) error {
+				var err error
+				switch id {
+				case 1:
+					h.numValues, err = tr.readI32(typ)
+				case 4:
+					h.encoding, err = tr.readI32(typ)
+				case 5:
+					h.defLevelsLen, err = tr.readI32(typ)
+				case 6:
+					h.repLevelsLen, err = tr.readI32(typ)
+				case 7:
+					h.compressed = typ == thriftBoolTrue
+				default:
+					err = tr.skip(typ)
+				}
+				return err
+			})
+		default:
+			err = tr.skip(typ)
+		}
+		return err
+	})
+	if err == nil && (h.compressedSize < 0 || h.uncompressedSize < 0 || h.numValues < 0 || h.defLevelsLen < 0 || h.repLevelsLen < 0) {
+		err = fmt.Errorf("negative page size, %w", ErrInvalidParquet)
+	}
+	return h, err
+}
+
+// readParquetColumn reads and decodes every page of a column chunk
+func readParquetColumn(r io.ReaderAt, size int64, chunk parquetColumnChunk, leaf parquetLeaf) (*parquetValues, error) {
+	start := chunk.dataOffset
+	if chunk.dictOffset > 0 && chunk.dictOffset < start {
+		start = chunk.dictOffset
+	}
+	if start < 4 || chunk.compressedSize < 0 || chunk.compressedSize > size-start || chunk.numValues < 0 {
+		return nil, fmt.Errorf("column chunk out of the file bounds, %w", ErrInvalidParquet)
+	}
+	b := make([]byte, chunk.compressedSize)
+	if _, err := r.ReadAt(b, start); err != nil {
+		return nil, fmt.Errorf("unable to read column chunk, %w", err)
+	}
+
+	isFloat := leaf.typ == parquetFloat || leaf.typ == parquetDouble
+	vals := &parquetValues{}
+	if isFloat {
+		vals.floats = make([]float64, 0, min(chunk.numValues, int64(len(b))))
+	}
+	var dict *parquetValues
+	tr := &thriftReader{b: b}
+	for int64(len(vals.valid)) < chunk.numValues {
+		h, err := readPageHeader(tr)
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode page header, %w", err)
+		}
+		if int(h.compressedSize) > len(b)-tr.pos {
+			return nil, fmt.Errorf("page past the end of the column chunk, %w", ErrInvalidParquet)
+		}
+		page := b[tr.pos : tr.pos+int(h.compressedSize)]
+		tr.pos += int(h.compressedSize)
+
+		switch h.typ {
+		case parquetDictionaryPage:
+			data, err := decompressPage(page, chunk.codec, h.uncompressedSize)
+			if err != nil {
+				return nil, err
+			}
+			if dict, err = decodePlain(data, leaf.typ, int(h.numValues)); err != nil {
+				return nil, err
+			}
+		case parquetDataPage, parquetDataPageV2:
+			if int64(h.numValues) > chunk.numValues-int64(len(vals.valid)) {
+				return nil, fmt.Errorf("page of %d values past the %d values of the column chunk, %w", h.numValues, chunk.numValues, ErrInvalidParquet)
+			}
+			if err := decodeDataPage(vals, page, h, chunk.codec, leaf, dict); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return vals, nil
+}
+
+// decodeDataPage appends the values of a data page to the column values
+func decodeDataPage(vals *parquetValues, page []byte, h parquetPageHeader, codec int32, leaf parquetLeaf, dict *parquetValues) error {
+	var defLevels, data []byte
+	if h.levelsInHeader {
+		// the levels of v2 pages are never compressed
+		levelsLen := int(h.repLevelsLen) + int(h.defLevelsLen)
+		if levelsLen > len(page) {
+			return fmt.Errorf("page levels past the end of the page, %w", ErrInvalidParquet)
+		}
+		defLevels = page[h.repLevelsLen:levelsLen]
+		data = page[levelsLen:]
+		if h.compressed {
+			var err error
+			if data, err = decompressPage(data, codec, h.uncompressedSize-int32(levelsLen)); err != nil {
+				return err
+			}
+		}
+	} else {
+		var err error
+		if data, err = decompressPage(page, codec, h.uncompressedSize); err != nil {
+			return err
+		}
+		if leaf.repetition == parquetOptional {
+			if len(data) < 4 {
+				return fmt.Errorf("page definition levels past the end of the page, %w", ErrInvalidParquet)
+			}
+			n := binary.LittleEndian.Uint32(data)
+			if uint64(n) > uint64(len(data)-4) {
+				return fmt.Errorf("page definition levels past the end of the page, %w", ErrInvalidParquet)
+			}
+			defLevels = data[4 : 4+n]
+			data = data[4+n:]
+		}
+	}
+
+	numValues := int(h.numValues)
+	numPresent := numValues
+	var levels []uint32
+	if leaf.repetition == parquetOptional {
+		var err error
+		if levels, err = decodeHybrid(defLevels, bitWidth(1), numValues); err != nil {
+			return err
+		}
+		numPresent = 0
+		for _, l := range levels {
+			if l == 1 {
+				numPresent++
+			}
+		}
+	}
+
+	var present *parquetValues
+	switch h.encoding {
+	case parquetPlain:
+		var err error
+		if present, err = decodePlain(data, leaf.typ, numPresent); err != nil {
+			return err
+		}
+	case parquetPlainDictionary, parquetRLEDictionary:
+		if dict == nil {
+			return fmt.Errorf("dictionary encoded page without a dictionary, %w", ErrInvalidParquet)
+		}
+		if len(data) == 0 && numPresent > 0 {
+			return fmt.Errorf("dictionary encoded page without a bit width, %w", ErrInvalidParquet)
+		}
+		present = &parquetValues{}
+		if numPresent > 0 {
+			idx, err := decodeHybrid(data[1:], int(data[0]), numPresent)
+			if err != nil {
+				return err
+			}
+			for _, i := range idx {
+				if int(i) >= len(dict.valid) {
+					return fmt.Errorf("dictionary index %d of %d entries, %w", i, len(dict.valid), ErrInvalidParquet)
+				}
+				present.append(dict, int(i))
+			}
+		}
+	default:
+		return fmt.Errorf("page encoding %d, %w", h.encoding, ErrUnsupportedParquet)
+	}
+
+	isFloat := leaf.typ == parquetFloat || leaf.typ == parquetDouble
+	next := 0
+	for i := 0; i < numValues; i++ {
+		if levels != nil && levels[i] == 0 {
+			vals.appendNull(isFloat)
+			continue
+		}
+		vals.append(present, next)
+		next++
+	}
+	return nil
+}
+
+// decompressPage decompresses the page with the codec checking its uncompressed size
+func decompressPage(page []byte, codec, uncompressedSize int32) ([]byte, error) {
+	var data []byte
+	switch codec {
+	case parquetUncompressed:
+		data = page
+	case parquetSnappy:
+		var err error
+		if data, err = snappyDecode(page, int(uncompressedSize)); err != nil {
+			return nil, err
+		}
+	case parquetGzip:
+		zr, err := gzip.NewReader(bytes.NewReader(page))
+		if err != nil {
+			return nil, fmt.Errorf("unable to read gzip page, %w", err)
+		}
+		data, err = io.ReadAll(io.LimitReader(zr, int64(uncompressedSize)+1))
+		if err != nil {
+			return nil, fmt.Errorf("unable to read gzip page, %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("compression codec %d, %w", codec, ErrUnsupportedParquet)
+	}
+	if len(data) != int(uncompressedSize) {
+		return nil, fmt.Errorf("page of %d bytes expected %d, %w", len(data), uncompressedSize, ErrInvalidParquet)
+	}
+	return data, nil
+}
+
+// decodePlain decodes n plain encoded values of the physical type. INT96 timestamps are decoded to
+// nanoseconds since the unix epoch.
+func decodePlain(data []byte, typ int32, n int) (*parquetValues, error) {
+	width := map[int32]int{parquetInt32: 4, parquetInt64: 8, parquetInt96: 12, parquetFloat: 4, parquetDouble: 8}[typ]
+	if width == 0 {
+		return nil, fmt.Errorf("physical type %d, %w", typ, ErrUnsupportedParquet)
+	}
+	if n < 0 || n > len(data)/width {
+		return nil, fmt.Errorf("%d plain values past the end of the page, %w", n, ErrInvalidParquet)
+	}
+
+	vals := &parquetValues{valid: make([]bool, n)}
+	if typ == parquetFloat || typ == parquetDouble {
+		vals.floats = make([]float64, n)
+	} else {
+		vals.ints = make([]int64, n)
+	}
+	for i := 0; i < n; i++ {
+		b := data[i*width : (i+1)*width]
+		vals.valid[i] = true
+		switch typ {
+		case parquetInt32:
+			vals.ints[i] = int64(int32(binary.LittleEndian.Uint32(b)))
+		case parquetInt64:
+			vals.ints[i] = int64(binary.LittleEndian.Uint64(b))
+		case parquetInt96:
+			nanos := int64(binary.LittleEndian.Uint64(b))
+			day := int64(int32(binary.LittleEndian.Uint32(b[8:])))
+			vals.ints[i] = (day-julianUnixEpoch)*int64(24*time.Hour) + nanos
+		case parquetFloat:
+			vals.floats[i] = float64(math.Float32frombits(binary.LittleEndian.Uint32(b)))
+		case parquetDouble:
+			vals.floats[i] = math.Float64frombits(binary.LittleEndian.Uint64(b))
+		}
+	}
+	return vals, nil
+}
@@ -0,0 +1,595 @@
+package timedataset
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/hex"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// thriftWriter encodes the thrift compact protocol to write parquet test files
+type thriftWriter struct {
+	buf    bytes.Buffer
+	lastID []int16
+}
+
+func (w *thriftWriter) varint(v uint64) {
+	w.buf.Write(binary.AppendUvarint(nil, v))
+}
+
+func (w *thriftWriter) zigzag(v int64) {
+	w.varint(uint64(v<<1) ^ uint64(v>>63))
+}
+
+func (w *thriftWriter) field(id int16, typ byte) {
+	top := len(w.lastID) - 1
+	if delta := id - w.lastID[top]; delta > 0 && delta <= 15 {
+		w.buf.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		w.buf.WriteByte(typ)
+		w.zigzag(int64(id))
+	}
+	w.lastID[top] = id
+}
+
+func (w *thriftWriter) begin() { w.lastID = append(w.lastID, 0) }
+
+func (w *thriftWriter) end() {
+	w.buf.WriteByte(0)
+	w.lastID = w.lastID[:len(w.lastID)-1]
+}
+
+func (w *thriftWriter) i32(id int16, v int32) {
+	w.field(id, thriftI32)
+	w.zigzag(int64(v))
+}
+
+func (w *thriftWriter) i64(id int16, v int64) {
+	w.field(id, thriftI64)
+	w.zigzag(v)
+}
+
+func (w *thriftWriter) str(id int16, s string) {
+	w.field(id, thriftBinary)
+	w.varint(uint64(len(s)))
+	w.buf.WriteString(s)
+}
+
+func (w *thriftWriter) boolean(id int16, v bool) {
+	typ := byte(thriftBoolFalse)
+	if v {
+		typ = thriftBoolTrue
+	}
+	w.field(id, typ)
+}
+
+func (w *thriftWriter) list(id int16, elemType byte, size int) {
+	w.field(id, thriftList)
+	if size < 15 {
+		w.buf.WriteByte(byte(size)<<4 | elemType)
+		return
+	}
+	w.buf.WriteByte(0xf0 | elemType)
+	w.varint(uint64(size))
+}
+
+// structField starts a struct field which is ended by end
+func (w *thriftWriter) structField(id int16) {
+	w.field(id, thriftStruct)
+	w.begin()
+}
+
+// testParquetColumn is a flat column of a parquet test file. Null values are not valid.
+type testParquetColumn struct {
+	name          string
+	typ           int32
+	optional      bool
+	convertedType int32
+	logical       func(w *thriftWriter)
+	ints          []int64
+	floats        []float64
+	valid         []bool
+}
+
+func (c testParquetColumn) len() int {
+	if c.floats != nil {
+		return len(c.floats)
+	}
+	return len(c.ints)
+}
+
+func (c testParquetColumn) isValid(i int) bool {
+	return c.valid == nil || c.valid[i]
+}
+
+// plain encodes the value at the index
+func (c testParquetColumn) plain(buf *bytes.Buffer, i int) {
+	switch c.typ {
+	case parquetInt32:
+		buf.Write(binary.LittleEndian.AppendUint32(nil, uint32(c.ints[i])))
+	case parquetInt64:
+		buf.Write(binary.LittleEndian.AppendUint64(nil, uint64(c.ints[i])))
+	case parquetInt96:
+		day := c.ints[i]/int64(24*time.Hour) + julianUnixEpoch
+		buf.Write(binary.LittleEndian.AppendUint64(nil, uint64(c.ints[i]%int64(24*time.Hour))))
+		buf.Write(binary.LittleEndian.AppendUint32(nil, uint32(day)))
+	case parquetFloat:
+		buf.Write(binary.LittleEndian.AppendUint32(nil, math.Float32bits(float32(c.floats[i]))))
+	case parquetDouble:
+		buf.Write(binary.LittleEndian.AppendUint64(nil, math.Float64bits(c.floats[i])))
+	}
+}
+
+type testParquetOptions struct {
+	codec        int32
+	dictionary   bool
+	v2           bool
+	rowGroupSize int
+}
+
+// compressTestPage compresses a page with gzip or as snappy literals
+func compressTestPage(t *testing.T, codec int32, data []byte) []byte {
+	switch codec {
+	case parquetGzip:
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		_, err := zw.Write(data)
+		require.Nil(t, err)
+		require.Nil(t, zw.Close())
+		return buf.Bytes()
+	case parquetSnappy:
+		out := binary.AppendUvarint(nil, uint64(len(data)))
+		for len(data) > 0 {
+			n := min(len(data), 1<<16)
+			out = append(out, 61<<2, byte(n-1), byte((n-1)>>8))
+			out = append(out, data[:n]...)
+			data = data[n:]
+		}
+		return out
+	}
+	return data
+}
+
+// hybridRuns encodes the values as repeated runs of the parquet hybrid encoding
+func hybridRuns(vals []uint32, width int) []byte {
+	var out []byte
+	for _, v := range vals {
+		out = binary.AppendUvarint(out, 1<<1)
+		for i := 0; i < (width+7)/8; i++ {
+			out = append(out, byte(v>>(8*i)))
+		}
+	}
+	return out
+}
+
+// hybridBitPacked encodes the values as a single bit packed run of the parquet hybrid encoding
+func hybridBitPacked(vals []uint32, width int) []byte {
+	groups := (len(vals) + 7) / 8
+	out := binary.AppendUvarint(nil, uint64(groups)<<1|1)
+	packed := make([]byte, groups*width)
+	for i, v := range vals {
+		for b := 0; b < width; b++ {
+			if v>>b&1 == 1 {
+				bit := i*width + b
+				packed[bit/8] |= 1 << (bit % 8)
+			}
+		}
+	}
+	return append(out, packed...)
+}
+
+// writeTestPage writes the dictionary page if dictionary encoded and the data page of the values of the
+// column in [start, end) returning the offset of the data page
+func writeTestPage(t *testing.T, file *bytes.Buffer, c testParquetColumn, start, end int, opt testParquetOptions) int64 {
+	var levels []byte
+	var levelsLen int
+	if c.optional {
+		defs := make([]uint32, 0, end-start)
+		for i := start; i < end; i++ {
+			var def uint32
+			if c.isValid(i) {
+				def = 1
+			}
+			defs = append(defs, def)
+		}
+		levels = hybridRuns(defs, 1)
+		levelsLen = len(levels)
+		if !opt.v2 {
+			levels = append(binary.LittleEndian.AppendUint32(nil, uint32(len(levels))), levels...)
+		}
+	}
+
+	var values bytes.Buffer
+	encoding := int32(parquetPlain)
+	var dictPage []byte
+	var dictLen int
+	if opt.dictionary {
+		encoding = parquetRLEDictionary
+		var dict bytes.Buffer
+		index := make(map[int]uint32)
+		var idx []uint32
+		for i := start; i < end; i++ {
+			if !c.isValid(i) {
+				continue
+			}
+			var key int
+			for j := start; j <= i; j++ {
+				if c.isValid(j) && ((c.floats != nil && c.floats[j] == c.floats[i]) || (c.floats == nil && c.ints[j] == c.ints[i])) {
+					key = j
+					break
+				}
+			}
+			if _, exists := index[key]; !exists {
+				index[key] = uint32(len(index))
+				c.plain(&dict, key)
+			}
+			idx = append(idx, index[key])
+		}
+		width := max(bitWidth(len(index)-1), 1)
+		values.WriteByte(byte(width))
+		values.Write(hybridBitPacked(idx, width))
+		dictPage = dict.Bytes()
+		dictLen = len(index)
+	} else {
+		for i := start; i < end; i++ {
+			if c.isValid(i) {
+				c.plain(&values, i)
+			}
+		}
+	}
+
+	if dictPage != nil {
+		compressed := compressTestPage(t, opt.codec, dictPage)
+		w := &thriftWriter{}
+		w.begin()
+		w.i32(1, parquetDictionaryPage)
+		w.i32(2, int32(len(dictPage)))
+		w.i32(3, int32(len(compressed)))
+		w.structField(7)
+		w.i32(1, int32(dictLen))
+		w.i32(2, parquetPlain)
+		w.end()
+		w.end()
+		file.Write(w.buf.Bytes())
+		file.Write(compressed)
+	}
+
+	dataOffset := int64(file.Len())
+	w := &thriftWriter{}
+	w.begin()
+	if opt.v2 {
+		compressed := compressTestPage(t, opt.codec, values.Bytes())
+		w.i32(1, parquetDataPageV2)
+		w.i32(2, int32(len(levels)+values.Len()))
+		w.i32(3, int32(len(levels)+len(compressed)))
+		w.structField(8)
+		w.i32(1, int32(end-start))
+		w.i32(2, 0)
+		w.i32(3, int32(end-start))
+		w.i32(4, encoding)
+		w.i32(5, int32(levelsLen))
+		w.i32(6, 0)
+		w.boolean(7, opt.codec != parquetUncompressed)
+		w.end()
+		w.end()
+		file.Write(w.buf.Bytes())
+		file.Write(levels)
+		file.Write(compressed)
+		return dataOffset
+	}
+	data := append(levels, values.Bytes()...)
+	compressed := compressTestPage(t, opt.codec, data)
+	w.i32(1, parquetDataPage)
+	w.i32(2, int32(len(data)))
+	w.i32(3, int32(len(compressed)))
+	w.structField(5)
+	w.i32(1, int32(end-start))
+	w.i32(2, encoding)
+	w.i32(3, 3)
+	w.i32(4, 3)
+	w.end()
+	w.end()
+	file.Write(w.buf.Bytes())
+	file.Write(compressed)
+	return dataOffset
+}
+
+// writeTestParquet writes the columns to a parquet file with a page per column of every row group
+func writeTestParquet(t *testing.T, cols []testParquetColumn, opt testParquetOptions) []byte {
+	numRows := cols[0].len()
+	rowGroupSize := opt.rowGroupSize
+	if rowGroupSize == 0 {
+		rowGroupSize = max(numRows, 1)
+	}
+
+	type chunk struct {
+		offset, size, dataOffset int64
+		numValues                int
+	}
+	var file bytes.Buffer
+	file.Write(parquetMagic)
+	var rowGroups [][]chunk
+	for start := 0; start < numRows; start += rowGroupSize {
+		end := min(start+rowGroupSize, numRows)
+		var rg []chunk
+		for _, c := range cols {
+			offset := int64(file.Len())
+			dataOffset := writeTestPage(t, &file, c, start, end, opt)
+			rg = append(rg, chunk{offset: offset, size: int64(file.Len()) - offset, dataOffset: dataOffset, numValues: end - start})
+		}
+		rowGroups = append(rowGroups, rg)
+	}
+
+	w := &thriftWriter{}
+	w.begin()
+	w.i32(1, 1)
+	w.list(2, thriftStruct, len(cols)+1)
+	w.begin()
+	w.str(4, "schema")
+	w.i32(5, int32(len(cols)))
+	w.end()
+	for _, c := range cols {
+		w.begin()
+		w.i32(1, c.typ)
+		repetition := int32(parquetRequired)
+		if c.optional {
+			repetition = parquetOptional
+		}
+		w.i32(3, repetition)
+		w.str(4, c.name)
+		if c.convertedType != 0 {
+			w.i32(6, c.convertedType)
+		}
+		if c.logical != nil {
+			w.structField(10)
+			c.logical(w)
+			w.end()
+		}
+		w.end()
+	}
+	w.i64(3, int64(numRows))
+	w.list(4, thriftStruct, len(rowGroups))
+	for _, rg := range rowGroups {
+		w.begin()
+		w.list(1, thriftStruct, len(rg))
+		for i, ch := range rg {
+			w.begin()
+			w.i64(2, ch.offset)
+			w.structField(3)
+			w.i32(1, cols[i].typ)
+			w.list(2, thriftI32, 1)
+			w.zigzag(parquetPlain)
+			w.list(3, thriftBinary, 1)
+			w.varint(uint64(len(cols[i].name)))
+			w.buf.WriteString(cols[i].name)
+			w.i32(4, opt.codec)
+			w.i64(5, int64(ch.numValues))
+			w.i64(6, ch.size)
+			w.i64(7, ch.size)
+			w.i64(9, ch.dataOffset)
+			if opt.dictionary {
+				w.i64(11, ch.offset)
+			}
+			w.end()
+			w.end()
+		}
+		w.i64(2, 0)
+		w.i64(3, int64(rg[0].numValues))
+		w.end()
+	}
+	w.str(6, "go-forecaster test")
+	w.end()
+
+	file.Write(w.buf.Bytes())
+	file.Write(binary.LittleEndian.AppendUint32(nil, uint32(w.buf.Len())))
+	file.Write(parquetMagic)
+	return file.Bytes()
+}
+
+// timestampLogical writes a timestamp logical type of the unit, 1 millis, 2 micros, or 3 nanos
+func timestampLogical(adjustedToUTC bool, unit int16) func(w *thriftWriter) {
+	return func(w *thriftWriter) {
+		w.structField(8)
+		w.boolean(1, adjustedToUTC)
+		w.structField(2)
+		w.structField(unit)
+		w.end()
+		w.end()
+		w.end()
+	}
+}
+
+func TestFromParquet(t *testing.T) {
+	nyc, err := time.LoadLocation("America/New_York")
+	require.Nil(t, err)
+
+	nan := math.NaN()
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// unordered times with a null value
+	times := []time.Time{t0.Add(2 * time.Minute), t0, t0.Add(time.Minute), t0.Add(3 * time.Minute)}
+	millis := make([]int64, len(times))
+	nanos := make([]int64, len(times))
+	for i, tPnt := range times {
+		millis[i] = tPnt.UnixMilli()
+		nanos[i] = tPnt.UnixNano()
+	}
+	timeCol := testParquetColumn{name: "ts", typ: parquetInt64, logical: timestampLogical(true, 1), ints: millis}
+	valueCol := testParquetColumn{name: "value", typ: parquetDouble, optional: true, floats: []float64{3, 1, 0, 1}, valid: []bool{true, true, false, true}}
+	expected := &TimeDataset{
+		T: []time.Time{t0, t0.Add(time.Minute), t0.Add(2 * time.Minute), t0.Add(3 * time.Minute)},
+		Y: []float64{1, nan, 3, 1},
+	}
+
+	testData := map[string]struct {
+		timeCol  string
+		cols     []testParquetColumn
+		opt      testParquetOptions
+		loadOpts []LoadOption
+		expected *TimeDataset
+		err      error
+	}{
+		"plain uncompressed":  {cols: []testParquetColumn{timeCol, valueCol}, expected: expected},
+		"dictionary snappy":   {cols: []testParquetColumn{timeCol, valueCol}, opt: testParquetOptions{codec: parquetSnappy, dictionary: true}, expected: expected},
+		"v2 gzip row groups":  {cols: []testParquetColumn{valueCol, timeCol}, opt: testParquetOptions{codec: parquetGzip, v2: true, rowGroupSize: 3}, expected: expected},
+		"v2 dictionary pages": {cols: []testParquetColumn{timeCol, valueCol}, opt: testParquetOptions{dictionary: true, v2: true, rowGroupSize: 2}, expected: expected},
+		"converted micros and int values": {
+			cols: []testParquetColumn{
+				{name: "ts", typ: parquetInt64, convertedType: parquetConvertedTimestampMicros, ints: []int64{t0.UnixMicro(), t0.Add(time.Minute).UnixMicro()}},
+				{name: "value", typ: parquetInt32, ints: []int64{-2, 7}},
+			},
+			expected: &TimeDataset{T: []time.Time{t0, t0.Add(time.Minute)}, Y: []float64{-2, 7}},
+		},
+		"int96 float values": {
+			cols: []testParquetColumn{
+				{name: "ts", typ: parquetInt96, ints: nanos[1:3]},
+				{name: "value", typ: parquetFloat, floats: []float64{0.5, 1.5}},
+			},
+			expected: &TimeDataset{T: []time.Time{t0, t0.Add(time.Minute)}, Y: []float64{0.5, 1.5}},
+		},
+		"local timestamps in location": {
+			cols: []testParquetColumn{
+				{name: "ts", typ: parquetInt64, logical: timestampLogical(false, 3), ints: nanos[1:2]},
+				{name: "value", typ: parquetInt64, ints: []int64{4}},
+			},
+			loadOpts: []LoadOption{WithLocation(nyc)},
+			expected: &TimeDataset{T: []time.Time{t0.Add(5 * time.Hour)}, Y: []float64{4}},
+		},
+		"dates resampled and dropped": {
+			timeCol: "day",
+			cols: []testParquetColumn{
+				{name: "day", typ: parquetInt32, convertedType: parquetConvertedDate, ints: []int64{19723, 19725}},
+				{name: "value", typ: parquetDouble, floats: []float64{1, 2}},
+			},
+			loadOpts: []LoadOption{WithResample(ResampleOptions{Interval: 24 * time.Hour}), WithDropNaN()},
+			expected: &TimeDataset{T: []time.Time{t0, t0.Add(48 * time.Hour)}, Y: []float64{1, 2}},
+		},
+		"missing column": {
+			cols: []testParquetColumn{timeCol},
+			err:  ErrMissingColumn,
+		},
+		"unannotated time": {
+			cols: []testParquetColumn{{name: "ts", typ: parquetInt64, ints: millis}, valueCol},
+			err:  ErrUnsupportedParquet,
+		},
+		"null time": {
+			cols: []testParquetColumn{{name: "ts", typ: parquetInt64, optional: true, logical: timestampLogical(true, 1), ints: millis, valid: []bool{true, false, true, true}}, valueCol},
+			err:  ErrInvalidTimeValue,
+		},
+		"repeated time": {
+			cols: []testParquetColumn{{name: "ts", typ: parquetInt64, logical: timestampLogical(true, 1), ints: []int64{millis[0], millis[0]}}, {name: "value", typ: parquetDouble, floats: []float64{1, 2}}},
+			err:  ErrNonMontonic,
+		},
+		"unsupported codec": {
+			cols: []testParquetColumn{timeCol, valueCol},
+			opt:  testParquetOptions{codec: 6},
+			err:  ErrUnsupportedParquet,
+		},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			timeCol := td.timeCol
+			if timeCol == "" {
+				timeCol = "ts"
+			}
+			file := writeTestParquet(t, td.cols, td.opt)
+			res, err := FromParquet(bytes.NewReader(file), int64(len(file)), timeCol, "value", td.loadOpts...)
+			if td.err != nil {
+				assert.ErrorIs(t, err, td.err)
+				return
+			}
+			require.Nil(t, err)
+			require.Len(t, res.T, len(td.expected.T))
+			for i := range td.expected.T {
+				assert.True(t, td.expected.T[i].Equal(res.T[i]), "index %d, expected %s, got %s", i, td.expected.T[i], res.T[i])
+			}
+			assert.InDeltaSlice(t, td.expected.Y, res.Y, 1e-9)
+		})
+	}
+}
+
+func TestFromParquetInvalid(t *testing.T) {
+	cols := []testParquetColumn{
+		{name: "ts", typ: parquetInt64, logical: timestampLogical(true, 1), ints: []int64{0, 1000}},
+		{name: "value", typ: parquetDouble, floats: []float64{1, 2}},
+	}
+	file := writeTestParquet(t, cols, testParquetOptions{})
+
+	testData := map[string][]byte{
+		"empty":         nil,
+		"missing magic": append(append([]byte(nil), file[:len(file)-4]...), "PAR0"...),
+		"metadata size": append(append(append([]byte(nil), file[:len(file)-8]...), 0xff, 0xff, 0xff, 0x0f), parquetMagic...),
+	}
+	for name, b := range testData {
+		t.Run(name, func(t *testing.T) {
+			_, err := FromParquet(bytes.NewReader(b), int64(len(b)), "ts", "value")
+			assert.NotNil(t, err)
+		})
+	}
+
+	// corrupting any byte of the file never panics
+	for i := range file {
+		corrupt := append([]byte(nil), file...)
+		corrupt[i] ^= 0xff
+		assert.NotPanics(t, func() {
+			_, _ = FromParquet(bytes.NewReader(corrupt), int64(len(corrupt)), "ts", "value")
+		}, "byte %d", i)
+	}
+}
+
+func TestSnappyDecode(t *testing.T) {
+	testData := map[string]struct {
+		input    string
+		expected string
+		err      error
+	}{
+		"one byte offset copy":   {input: "1e086162636a0300", expected: "abcabcabcabcabcabcabcabcabcabc"},
+		"overlapping copy":       {input: "640061fe01008a0100", expected: string(bytes.Repeat([]byte("a"), 100))},
+		"two byte offset copies": {input: "8b0e20666f72656361737420fe0900fe0900fe0900fe0900fe0900fe0900fe0900fe0900fe0900fe0900fe0900fe0900fe0900fe0900fe0900fe0900fe0900fe0900fe0900fe0900fe0900fe0900fe0900fe0900fe0900fe0900fe0900fa090008656e64", expected: string(bytes.Repeat([]byte("forecast "), 200)) + "end"},
+		"four byte offset copy":  {input: "080c616263640f04000000", expected: "abcdabcd"},
+		"offset before start":    {input: "080c616263640f05000000", err: ErrInvalidParquet},
+		"short output":           {input: "090c616263640f04000000", err: ErrInvalidParquet},
+		"truncated literal":      {input: "080c6162", err: ErrInvalidParquet},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			src, err := hex.DecodeString(td.input)
+			require.Nil(t, err)
+			res, err := snappyDecode(src, 1<<20)
+			if td.err != nil {
+				assert.ErrorIs(t, err, td.err)
+				return
+			}
+			require.Nil(t, err)
+			assert.Equal(t, td.expected, string(res))
+		})
+	}
+}
+
+func TestDecodeHybrid(t *testing.T) {
+	vals := []uint32{0, 5, 3, 7, 1, 2, 6, 4, 5, 5}
+	res, err := decodeHybrid(hybridBitPacked(vals, 3), 3, len(vals))
+	require.Nil(t, err)
+	assert.Equal(t, vals, res)
+
+	res, err = decodeHybrid(hybridRuns(vals, 3), 3, len(vals))
+	require.Nil(t, err)
+	assert.Equal(t, vals, res)
+
+	// a repeated run of 300 values of 258 with a two byte value
+	res, err = decodeHybrid([]byte{0xd8, 0x04, 0x02, 0x01}, 9, 300)
+	require.Nil(t, err)
+	require.Len(t, res, 300)
+	assert.Equal(t, uint32(258), res[299])
+
+	_, err = decodeHybrid([]byte{0x03}, 3, 8)
+	assert.ErrorIs(t, err, ErrInvalidParquet)
+}
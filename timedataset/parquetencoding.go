@@ -0,0 +1,354 @@
+package timedataset
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/bits"
+)
+
+// thrift compact protocol types of the parquet file metadata
+const (
+	thriftBoolTrue  = 1
+	thriftBoolFalse = 2
+	thriftByte      = 3
+	thriftI16       = 4
+	thriftI32       = 5
+	thriftI64       = 6
+	thriftDouble    = 7
+	thriftBinary    = 8
+	thriftList      = 9
+	thriftSet       = 10
+	thriftMap       = 11
+	thriftStruct    = 12
+)
+
+// maxThriftDepth bounds the nesting of skipped structs so a malformed footer cannot recurse forever
+const maxThriftDepth = 64
+
+// thriftReader decodes the thrift compact protocol which serializes the parquet file metadata and
+// page headers
+type thriftReader struct {
+	b     []byte
+	pos   int
+	depth int
+}
+
+func (r *thriftReader) readByte() (byte, error) {
+	if r.pos >= len(r.b) {
+		return 0, fmt.Errorf("unexpected end of thrift data, %w", ErrInvalidParquet)
+	}
+	b := r.b[r.pos]
+	r.pos++
+	return b, nil
+}
+
+func (r *thriftReader) readVarint() (uint64, error) {
+	v, n := binary.Uvarint(r.b[r.pos:])
+	if n <= 0 {
+		return 0, fmt.Errorf("invalid thrift varint, %w", ErrInvalidParquet)
+	}
+	r.pos += n
+	return v, nil
+}
+
+// readInt reads a zigzag encoded i16, i32, or i64
+func (r *thriftReader) readInt() (int64, error) {
+	v, err := r.readVarint()
+	if err != nil {
+		return 0, err
+	}
+	return int64(v>>1) ^ -int64(v&1), nil
+}
+
+func (r *thriftReader) readBinary() ([]byte, error) {
+	n, err := r.readVarint()
+	if err != nil {
+		return nil, err
+	}
+	if n > uint64(len(r.b)-r.pos) {
+		return nil, fmt.Errorf("thrift binary of %d bytes past the end of the data, %w", n, ErrInvalidParquet)
+	}
+	b := r.b[r.pos : r.pos+int(n)]
+	r.pos += int(n)
+	return b, nil
+}
+
+// readListHeader reads the size and element type of a list or set
+func (r *thriftReader) readListHeader() (int, byte, error) {
+	b, err := r.readByte()
+	if err != nil {
+		return 0, 0, err
+	}
+	size := uint64(b >> 4)
+	if size == 15 {
+		if size, err = r.readVarint(); err != nil {
+			return 0, 0, err
+		}
+	}
+	// every element takes at least a byte
+	if size > uint64(len(r.b)-r.pos) {
+		return 0, 0, fmt.Errorf("thrift list of %d elements past the end of the data, %w", size, ErrInvalidParquet)
+	}
+	return int(size), b & 0x0f, nil
+}
+
+// readStruct calls fn with the id and type of every field of a struct until its stop field. fn must
+// read or skip the value of the field. Boolean fields carry their value in the type.
+func (r *thriftReader) readStruct(fn func(id int16, typ byte) error) error {
+	r.depth++
+	defer func() { r.depth-- }()
+	if r.depth > maxThriftDepth {
+		return fmt.Errorf("thrift structs nested deeper than %d, %w", maxThriftDepth, ErrInvalidParquet)
+	}
+
+	var lastID int16
+	for {
+		b, err := r.readByte()
+		if err != nil {
+			return err
+		}
+		if b == 0 {
+			return nil
+		}
+		typ := b & 0x0f
+		id := lastID + int16(b>>4)
+		if b>>4 == 0 {
+			v, err := r.readInt()
+			if err != nil {
+				return err
+			}
+			id = int16(v)
+		}
+		lastID = id
+		if err := fn(id, typ); err != nil {
+			return err
+		}
+	}
+}
+
+// readList calls fn for every element of a list
+func (r *thriftReader) readList(fn func(elemType byte) error) error {
+	size, elemType, err := r.readListHeader()
+	if err != nil {
+		return err
+	}
+	for i := 0; i < size; i++ {
+		if err := fn(elemType); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// skip skips a field value of the type
+func (r *thriftReader) skip(typ byte) error {
+	switch typ {
+	case thriftBoolTrue, thriftBoolFalse:
+		return nil
+	case thriftByte:
+		_, err := r.readByte()
+		return err
+	case thriftI16, thriftI32, thriftI64:
+		_, err := r.readVarint()
+		return err
+	case thriftDouble:
+		if len(r.b)-r.pos < 8 {
+			return fmt.Errorf("unexpected end of thrift data, %w", ErrInvalidParquet)
+		}
+		r.pos += 8
+		return nil
+	case thriftBinary:
+		_, err := r.readBinary()
+		return err
+	case thriftList, thriftSet:
+		return r.readList(r.skipElem)
+	case thriftMap:
+		size, err := r.readVarint()
+		if err != nil {
+			return err
+		}
+		if size == 0 {
+			return nil
+		}
+		if size > uint64(len(r.b)-r.pos) {
+			return fmt.Errorf("thrift map of %d entries past the end of the data, %w", size, ErrInvalidParquet)
+		}
+		kv, err := r.readByte()
+		if err != nil {
+			return err
+		}
+		for i := uint64(0); i < size; i++ {
+			if err := r.skipElem(kv >> 4); err != nil {
+				return err
+			}
+			if err := r.skipElem(kv & 0x0f); err != nil {
+				return err
+			}
+		}
+		return nil
+	case thriftStruct:
+		return r.readStruct(func(_ int16, typ byte) error {
+			return r.skip(typ)
+		})
+	}
+	return fmt.Errorf("unknown thrift type %d, %w", typ, ErrInvalidParquet)
+}
+
+// skipElem skips an element of a list, set, or map where booleans take a byte
+func (r *thriftReader) skipElem(typ byte) error {
+	if typ == thriftBoolTrue || typ == thriftBoolFalse {
+		_, err := r.readByte()
+		return err
+	}
+	return r.skip(typ)
+}
+
+// readI32 reads an i32 field or skips a field of an unexpected type
+func (r *thriftReader) readI32(typ byte) (int32, error) {
+	if typ != thriftI32 {
+		return 0, r.skip(typ)
+	}
+	v, err := r.readInt()
+	return int32(v), err
+}
+
+// readI64 reads an i64 field or skips a field of an unexpected type
+func (r *thriftReader) readI64(typ byte) (int64, error) {
+	if typ != thriftI64 {
+		return 0, r.skip(typ)
+	}
+	return r.readInt()
+}
+
+// decodeHybrid decodes n values of the bit width from the parquet RLE and bit packed hybrid encoding
+func decodeHybrid(b []byte, width, n int) ([]uint32, error) {
+	if width < 0 || width > 32 {
+		return nil, fmt.Errorf("bit width of %d, %w", width, ErrInvalidParquet)
+	}
+	vals := make([]uint32, 0, min(n, len(b)*8))
+	byteWidth := (width + 7) / 8
+	pos := 0
+	for len(vals) < n {
+		header, m := binary.Uvarint(b[pos:])
+		if m <= 0 {
+			return nil, fmt.Errorf("invalid hybrid run header, %w", ErrInvalidParquet)
+		}
+		pos += m
+
+		if header&1 == 0 {
+			// repeated run of a single value
+			count := header >> 1
+			if pos+byteWidth > len(b) {
+				return nil, fmt.Errorf("hybrid repeated run past the end of the data, %w", ErrInvalidParquet)
+			}
+			var v uint32
+			for i := 0; i < byteWidth; i++ {
+				v |= uint32(b[pos+i]) << (8 * i)
+			}
+			pos += byteWidth
+			for i := uint64(0); i < count && len(vals) < n; i++ {
+				vals = append(vals, v)
+			}
+			continue
+		}
+
+		// bit packed run of groups of 8 values packed from the least significant bit
+		groups := header >> 1
+		numBytes := groups * uint64(width)
+		if numBytes > uint64(len(b)-pos) {
+			return nil, fmt.Errorf("hybrid bit packed run past the end of the data, %w", ErrInvalidParquet)
+		}
+		packed := b[pos : pos+int(numBytes)]
+		pos += int(numBytes)
+		mask := uint64(1)<<width - 1
+		for i := uint64(0); i < groups*8 && len(vals) < n; i++ {
+			bit := i * uint64(width)
+			var v uint64
+			for read := uint64(0); read < uint64(width); {
+				byteIdx, offset := (bit+read)/8, (bit+read)%8
+				v |= uint64(packed[byteIdx]>>offset) << read
+				read += 8 - offset
+			}
+			vals = append(vals, uint32(v&mask))
+		}
+	}
+	return vals, nil
+}
+
+// bitWidth returns the number of bits needed to encode the max value
+func bitWidth(max int) int {
+	return bits.Len(uint(max))
+}
+
+// snappyDecode decodes a block of the raw snappy format which compresses parquet pages. The decoded
+// length must not exceed maxLen.
+func snappyDecode(src []byte, maxLen int) ([]byte, error) {
+	n, m := binary.Uvarint(src)
+	if m <= 0 || n > uint64(maxLen) {
+		return nil, fmt.Errorf("invalid snappy length, %w", ErrInvalidParquet)
+	}
+	dst := make([]byte, 0, n)
+	pos := m
+	for pos < len(src) {
+		tag := src[pos]
+		pos++
+
+		var length, offset int
+		switch tag & 0x03 {
+		case 0x00:
+			// literal whose length minus one is in the tag or the 1 to 4 bytes after it
+			length = int(tag >> 2)
+			if length >= 60 {
+				numBytes := length - 59
+				if pos+numBytes > len(src) {
+					return nil, fmt.Errorf("snappy literal length past the end of the data, %w", ErrInvalidParquet)
+				}
+				length = 0
+				for i := 0; i < numBytes; i++ {
+					length |= int(src[pos+i]) << (8 * i)
+				}
+				pos += numBytes
+			}
+			length++
+			if length <= 0 || length > len(src)-pos || len(dst)+length > int(n) {
+				return nil, fmt.Errorf("snappy literal past the end of the data, %w", ErrInvalidParquet)
+			}
+			dst = append(dst, src[pos:pos+length]...)
+			pos += length
+			continue
+		case 0x01:
+			if pos >= len(src) {
+				return nil, fmt.Errorf("snappy copy past the end of the data, %w", ErrInvalidParquet)
+			}
+			length = 4 + int(tag>>2)&0x07
+			offset = int(tag>>5)<<8 | int(src[pos])
+			pos++
+		case 0x02:
+			if pos+2 > len(src) {
+				return nil, fmt.Errorf("snappy copy past the end of the data, %w", ErrInvalidParquet)
+			}
+			length = 1 + int(tag>>2)
+			offset = int(binary.LittleEndian.Uint16(src[pos:]))
+			pos += 2
+		case 0x03:
+			if pos+4 > len(src) {
+				return nil, fmt.Errorf("snappy copy past the end of the data, %w", ErrInvalidParquet)
+			}
+			length = 1 + int(tag>>2)
+			offset = int(binary.LittleEndian.Uint32(src[pos:]))
+			pos += 4
+		}
+		if offset <= 0 || offset > len(dst) || len(dst)+length > int(n) {
+			return nil, fmt.Errorf("invalid snappy copy, %w", ErrInvalidParquet)
+		}
+		// copies may overlap the bytes they produce
+		start := len(dst) - offset
+		for i := 0; i < length; i++ {
+			dst = append(dst, dst[start+i])
+		}
+	}
+	if len(dst) != int(n) {
+		return nil, fmt.Errorf("snappy decoded %d of %d bytes, %w", len(dst), n, ErrInvalidParquet)
+	}
+	return dst, nil
+}
@@ -0,0 +1,144 @@
+package timedataset
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/errs"
+)
+
+var (
+	ErrUnknownAggregation      = errs.NewConfigError(errs.CodeInvalidOption, "unknown resample aggregation", nil)
+	ErrInvalidResampleInterval = errs.NewConfigError(errs.CodeInvalidOption, "resample interval must be positive", nil)
+)
+
+// Aggregation combines the observations assigned to the same grid point when resampling
+type Aggregation string
+
+const (
+	// AggregationMean averages the observations and is the default if unset
+	AggregationMean Aggregation = "mean"
+
+	// AggregationSum adds the observations which suits counts per interval
+	AggregationSum Aggregation = "sum"
+
+	// AggregationLast keeps the latest observation which suits gauges and counters
+	AggregationLast Aggregation = "last"
+)
+
+// ResampleOptions configures the alignment of a dataset to a regular grid. If Interval is zero the
+// dominant sampling interval is inferred with FreqOptions and refined over the whole span of the times.
+type ResampleOptions struct {
+	Interval    time.Duration `json:"interval,omitempty"`
+	FreqOptions FreqOptions   `json:"freq_options,omitempty"`
+	Aggregation Aggregation   `json:"aggregation,omitempty"`
+}
+
+// Validate returns an error if the interval is negative or the aggregation or frequency options are
+// unknown
+func (o ResampleOptions) Validate() error {
+	if o.Interval < 0 {
+		return fmt.Errorf("interval of %s, %w", o.Interval, ErrInvalidResampleInterval)
+	}
+	switch o.Aggregation {
+	case "", AggregationMean, AggregationSum, AggregationLast:
+	default:
+		return fmt.Errorf("%q, %w", o.Aggregation, ErrUnknownAggregation)
+	}
+	return o.FreqOptions.Validate()
+}
+
+// Resample aligns the time ordered dataset to a regular grid of the interval anchored at the unix
+// epoch. Each time is assigned to its nearest grid point so jittered timestamps land on their intended
+// slot, observations sharing a grid point are aggregated, and grid points without an observation
+// between the first and last are filled with NaN. NaN observations are ignored.
+func (td *TimeDataset) Resample(opt ResampleOptions) (*TimeDataset, error) {
+	if td.Len() == 0 {
+		return nil, ErrNoTrainingData
+	}
+	if err := opt.Validate(); err != nil {
+		return nil, err
+	}
+	interval := opt.Interval
+	if interval == 0 {
+		est, err := TimeSlice(td.T).InferFreq(opt.FreqOptions)
+		if err != nil {
+			return nil, fmt.Errorf("unable to infer resample interval, %w", err)
+		}
+		interval = refineInterval(td.T, est.Freq)
+	}
+
+	start := gridPoint(td.T[0], interval)
+	end := gridPoint(td.T[len(td.T)-1], interval)
+	n := int(end.Sub(start)/interval) + 1
+
+	res := &TimeDataset{
+		T: make([]time.Time, n),
+		Y: make([]float64, n),
+	}
+	counts := make([]int, n)
+	for i := range res.T {
+		res.T[i] = start.Add(time.Duration(i) * interval)
+	}
+	for i, t := range td.T {
+		y := td.Y[i]
+		if math.IsNaN(y) {
+			continue
+		}
+		idx := int(gridPoint(t, interval).Sub(start) / interval)
+		if idx < 0 || idx >= n {
+			return nil, fmt.Errorf("time %s at index %d, %w", t, i, ErrNonMontonic)
+		}
+		switch opt.Aggregation {
+		case AggregationLast:
+			res.Y[idx] = y
+		default:
+			res.Y[idx] += y
+		}
+		counts[idx]++
+	}
+	for i, cnt := range counts {
+		switch {
+		case cnt == 0:
+			res.Y[i] = math.NaN()
+		case opt.Aggregation == "" || opt.Aggregation == AggregationMean:
+			res.Y[i] /= float64(cnt)
+		}
+	}
+	return res, nil
+}
+
+// refineInterval refines the inferred interval as the span of the times over the number of whole
+// intervals between consecutive times which averages out jitter across the whole series. The result is
+// rounded to the second when at least a second so it lands on a conventional interval.
+func refineInterval(t []time.Time, interval time.Duration) time.Duration {
+	var steps float64
+	for i := 1; i < len(t); i++ {
+		steps += math.Round(float64(t[i].Sub(t[i-1])) / float64(interval))
+	}
+	if steps < 1 {
+		return interval
+	}
+	span := t[len(t)-1].Sub(t[0])
+	refined := time.Duration(float64(span) / steps)
+	if refined >= time.Second {
+		refined = refined.Round(time.Second)
+	}
+	return refined
+}
+
+// gridPoint returns the nearest multiple of the interval since the unix epoch in the location of the
+// time
+func gridPoint(t time.Time, interval time.Duration) time.Time {
+	nanos := t.UnixNano()
+	offset := nanos % int64(interval)
+	if offset < 0 {
+		offset += int64(interval)
+	}
+	nanos -= offset
+	if offset*2 >= int64(interval) {
+		nanos += int64(interval)
+	}
+	return time.Unix(0, nanos).In(t.Location())
+}
@@ -0,0 +1,107 @@
+package timedataset
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResample(t *testing.T) {
+	nan := math.NaN()
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	jittered := []time.Time{
+		t0.Add(-2 * time.Second),
+		t0.Add(time.Minute + time.Second),
+		t0.Add(2*time.Minute - 3*time.Second),
+		t0.Add(2*time.Minute + 20*time.Second),
+		t0.Add(4*time.Minute + 2*time.Second),
+	}
+	tolerance := 0.1
+	grid := []time.Time{t0, t0.Add(time.Minute), t0.Add(2 * time.Minute), t0.Add(3 * time.Minute), t0.Add(4 * time.Minute)}
+
+	testData := map[string]struct {
+		td       *TimeDataset
+		opt      ResampleOptions
+		expected *TimeDataset
+		err      error
+	}{
+		"inferred interval": {
+			td: &TimeDataset{
+				T: []time.Time{
+					t0.Add(time.Second),
+					t0.Add(time.Minute),
+					t0.Add(2*time.Minute + time.Second),
+					t0.Add(3*time.Minute + time.Second),
+					t0.Add(5*time.Minute + time.Second),
+				},
+				Y: []float64{1, 2, 3, 4, 6},
+			},
+			opt:      ResampleOptions{FreqOptions: FreqOptions{JitterTolerance: &tolerance}},
+			expected: &TimeDataset{T: append(grid, t0.Add(5*time.Minute)), Y: []float64{1, 2, 3, 4, nan, 6}},
+		},
+		"mean": {
+			td:       &TimeDataset{T: jittered, Y: []float64{1, 2, 3, 5, 6}},
+			opt:      ResampleOptions{Interval: time.Minute},
+			expected: &TimeDataset{T: grid, Y: []float64{1, 2, 4, nan, 6}},
+		},
+		"sum": {
+			td:       &TimeDataset{T: jittered, Y: []float64{1, 2, 3, 5, 6}},
+			opt:      ResampleOptions{Interval: time.Minute, Aggregation: AggregationSum},
+			expected: &TimeDataset{T: grid, Y: []float64{1, 2, 8, nan, 6}},
+		},
+		"last": {
+			td:       &TimeDataset{T: jittered, Y: []float64{1, 2, 3, 5, 6}},
+			opt:      ResampleOptions{Interval: time.Minute, Aggregation: AggregationLast},
+			expected: &TimeDataset{T: grid, Y: []float64{1, 2, 5, nan, 6}},
+		},
+		"nan observations ignored": {
+			td:       &TimeDataset{T: jittered, Y: []float64{1, nan, 3, nan, 6}},
+			opt:      ResampleOptions{Interval: time.Minute},
+			expected: &TimeDataset{T: grid, Y: []float64{1, nan, 3, nan, 6}},
+		},
+		"coarser interval": {
+			td:       &TimeDataset{T: grid, Y: []float64{1, 2, 3, 4, 5}},
+			opt:      ResampleOptions{Interval: 2 * time.Minute, Aggregation: AggregationSum},
+			expected: &TimeDataset{T: []time.Time{t0, t0.Add(2 * time.Minute), t0.Add(4 * time.Minute)}, Y: []float64{1, 5, 9}},
+		},
+		"no data": {
+			td:  &TimeDataset{},
+			err: ErrNoTrainingData,
+		},
+		"cannot infer": {
+			td:  &TimeDataset{T: []time.Time{t0}, Y: []float64{1}},
+			err: ErrCannotInferFreq,
+		},
+		"invalid interval": {
+			td:  &TimeDataset{T: grid, Y: []float64{1, 2, 3, 4, 5}},
+			opt: ResampleOptions{Interval: -time.Minute},
+			err: ErrInvalidResampleInterval,
+		},
+		"unknown aggregation": {
+			td:  &TimeDataset{T: grid, Y: []float64{1, 2, 3, 4, 5}},
+			opt: ResampleOptions{Interval: time.Minute, Aggregation: "median"},
+			err: ErrUnknownAggregation,
+		},
+		"non monotonic": {
+			td:  &TimeDataset{T: []time.Time{t0.Add(time.Minute), t0, t0.Add(time.Minute)}, Y: []float64{1, 2, 3}},
+			opt: ResampleOptions{Interval: time.Minute},
+			err: ErrNonMontonic,
+		},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			res, err := td.td.Resample(td.opt)
+			if td.err != nil {
+				assert.ErrorIs(t, err, td.err)
+				return
+			}
+			require.Nil(t, err)
+			assert.Equal(t, td.expected.T, res.T)
+			assert.InDeltaSlice(t, td.expected.Y, res.Y, 1e-9)
+		})
+	}
+}
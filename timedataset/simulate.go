@@ -96,3 +96,103 @@ func GenerateChange(t []time.Time, chpt time.Time, bias, slope float64) Series {
 	}
 	return Series(y)
 }
+
+// GenerateRampPlateau ramps linearly from zero to height over the ramp duration starting at start, holds
+// the height for the plateau duration, and then ramps back down to zero over the ramp duration
+func GenerateRampPlateau(t []time.Time, start time.Time, ramp, plateau time.Duration, height float64) Series {
+	n := len(t)
+	y := make([]float64, n)
+	plateauStart := start.Add(ramp)
+	plateauEnd := plateauStart.Add(plateau)
+	end := plateauEnd.Add(ramp)
+	for i := 0; i < n; i++ {
+		switch {
+		case t[i].Before(start) || !t[i].Before(end):
+			continue
+		case t[i].Before(plateauStart):
+			y[i] = height * t[i].Sub(start).Seconds() / ramp.Seconds()
+		case t[i].Before(plateauEnd):
+			y[i] = height
+		default:
+			y[i] = height * end.Sub(t[i]).Seconds() / ramp.Seconds()
+		}
+	}
+	return Series(y)
+}
+
+// GenerateBusinessHoursY sets the value on weekdays between the start hour inclusive and end hour
+// exclusive in the local time of the location and zero otherwise
+func GenerateBusinessHoursY(t []time.Time, val float64, startHour, endHour int, loc *time.Location) Series {
+	if loc == nil {
+		loc = time.UTC
+	}
+	n := len(t)
+	y := make([]float64, n)
+	for i := 0; i < n; i++ {
+		lt := t[i].In(loc)
+		switch lt.Weekday() {
+		case time.Saturday, time.Sunday:
+			continue
+		}
+		if lt.Hour() >= startHour && lt.Hour() < endHour {
+			y[i] = val
+		}
+	}
+	return Series(y)
+}
+
+// GenerateHeteroscedasticNoise generates gaussian noise whose standard deviation is the noise scale
+// times the absolute level at each point
+func GenerateHeteroscedasticNoise(level Series, noiseScale float64) Series {
+	n := len(level)
+	y := make([]float64, 0, n)
+	for i := 0; i < n; i++ {
+		y = append(y, rand.NormFloat64()*noiseScale*math.Abs(level[i]))
+	}
+	return Series(y)
+}
+
+// Regime is a state of a regime switching series with a constant level and gaussian noise. The time
+// spent in the regime is exponentially distributed with a mean of MeanDuration.
+type Regime struct {
+	Level        float64
+	NoiseScale   float64
+	MeanDuration time.Duration
+}
+
+// GenerateRegimeSwitchY generates a series switching between the regimes starting from the first regime.
+// On leaving a regime the next one is chosen uniformly from the other regimes. The regime index of each
+// point is returned along with the series.
+func GenerateRegimeSwitchY(t []time.Time, regimes []Regime) (Series, []int) {
+	n := len(t)
+	y := make([]float64, n)
+	states := make([]int, n)
+	if n == 0 || len(regimes) == 0 {
+		return Series(y), states
+	}
+
+	var curr int
+	nextSwitch := t[0].Add(sampleRegimeDuration(regimes[curr]))
+	for i := 0; i < n; i++ {
+		for len(regimes) > 1 && !t[i].Before(nextSwitch) {
+			next := rand.IntN(len(regimes) - 1)
+			if next >= curr {
+				next++
+			}
+			curr = next
+			nextSwitch = nextSwitch.Add(sampleRegimeDuration(regimes[curr]))
+		}
+		states[i] = curr
+		y[i] = regimes[curr].Level + rand.NormFloat64()*regimes[curr].NoiseScale
+	}
+	return Series(y), states
+}
+
+func sampleRegimeDuration(r Regime) time.Duration {
+	// avoid zero length regimes which would never advance the switch time
+	d := time.Duration(rand.ExpFloat64() * float64(r.MeanDuration))
+	if d < time.Nanosecond {
+		d = time.Nanosecond
+	}
+	return d
+}
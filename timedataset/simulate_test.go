@@ -1,6 +1,7 @@
 package timedataset
 
 import (
+	"math"
 	"testing"
 	"time"
 
@@ -50,3 +51,80 @@ func TestSeries(t *testing.T) {
 	)
 	assert.Equal(t, Series([]float64{0, 0, 3, 3, 1, 0, 0}), s)
 }
+
+func TestGenerateRampPlateau(t *testing.T) {
+	nowFunc := func() time.Time {
+		return time.Date(1970, 1, 1, 10, 0, 0, 0, time.UTC)
+	}
+	tSeries := GenerateT(10, time.Hour, nowFunc)
+	res := GenerateRampPlateau(tSeries, tSeries[1], 2*time.Hour, 3*time.Hour, 4.0)
+	assert.Equal(t, Series([]float64{0, 0, 2, 4, 4, 4, 4, 2, 0, 0}), res)
+}
+
+func TestGenerateBusinessHoursY(t *testing.T) {
+	nowFunc := func() time.Time {
+		return time.Date(1970, 1, 5, 0, 0, 0, 0, time.UTC)
+	}
+	// Thursday 1970-01-01 through Sunday 1970-01-04 UTC
+	tSeries := GenerateT(96, time.Hour, nowFunc)
+	res := GenerateBusinessHoursY(tSeries, 1.0, 9, 17, nil)
+	var total float64
+	for i, v := range res {
+		hour := tSeries[i].Hour()
+		if v == 1.0 {
+			assert.True(t, hour >= 9 && hour < 17)
+		}
+		total += v
+	}
+	assert.Equal(t, 16.0, total)
+
+	loc, err := time.LoadLocation("America/New_York")
+	require.Nil(t, err)
+	res = GenerateBusinessHoursY(tSeries, 1.0, 9, 17, loc)
+	for i, v := range res {
+		lt := tSeries[i].In(loc)
+		weekday := lt.Weekday() != time.Saturday && lt.Weekday() != time.Sunday
+		assert.Equal(t, lt.Hour() >= 9 && lt.Hour() < 17 && weekday, v == 1.0)
+	}
+}
+
+func TestGenerateHeteroscedasticNoise(t *testing.T) {
+	level := Series([]float64{0, 0, 0})
+	assert.Equal(t, Series([]float64{0, 0, 0}), GenerateHeteroscedasticNoise(level, 1.0))
+
+	n := 10000
+	low := GenerateHeteroscedasticNoise(GenerateConstY(n, 1.0), 0.1)
+	high := GenerateHeteroscedasticNoise(GenerateConstY(n, 100.0), 0.1)
+	var lowSq, highSq float64
+	for i := 0; i < n; i++ {
+		lowSq += low[i] * low[i]
+		highSq += high[i] * high[i]
+	}
+	assert.InDelta(t, 0.1, math.Sqrt(lowSq/float64(n)), 0.01)
+	assert.InDelta(t, 10.0, math.Sqrt(highSq/float64(n)), 1.0)
+}
+
+func TestGenerateRegimeSwitchY(t *testing.T) {
+	nowFunc := func() time.Time {
+		return time.Date(1970, 1, 8, 0, 0, 0, 0, time.UTC)
+	}
+	tSeries := GenerateT(7*24, time.Hour, nowFunc)
+	regimes := []Regime{
+		{Level: 10, MeanDuration: 12 * time.Hour},
+		{Level: 20, MeanDuration: 12 * time.Hour},
+		{Level: 30, MeanDuration: 12 * time.Hour},
+	}
+	y, states := GenerateRegimeSwitchY(tSeries, regimes)
+	require.Len(t, y, len(tSeries))
+	require.Len(t, states, len(tSeries))
+	assert.Equal(t, 0, states[0])
+	for i, state := range states {
+		assert.Equal(t, regimes[state].Level, y[i])
+	}
+
+	y, states = GenerateRegimeSwitchY(tSeries, regimes[:1])
+	for i := range y {
+		assert.Equal(t, 0, states[i])
+		assert.Equal(t, 10.0, y[i])
+	}
+}
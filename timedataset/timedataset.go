@@ -1,17 +1,19 @@
 package timedataset
 
 import (
-	"errors"
 	"fmt"
 	"math"
 	"time"
+
+	"github.com/aouyang1/go-forecaster/errs"
 )
 
 var (
-	ErrNoTrainingData     = errors.New("no training data")
-	ErrNonMontonic        = errors.New("time feature is not monotonic")
-	ErrDatasetLenMismatch = errors.New("time feature has a different length than observations")
-	ErrCannotInferFreq    = errors.New("cannot infer frequency from time data")
+	ErrNoTrainingData     = errs.NewDataError(errs.CodeNoData, "no training data", nil)
+	ErrNonMontonic        = errs.NewDataError(errs.CodeNonMonotonic, "time feature is not monotonic", nil)
+	ErrDatasetLenMismatch = errs.NewDataError(errs.CodeLengthMismatch, "time feature has a different length than observations", nil)
+	ErrCannotInferFreq    = errs.NewDataError(errs.CodeCannotInferFreq, "cannot infer frequency from time data", nil)
+	ErrInvalidRateUnit    = errs.NewConfigError(errs.CodeInvalidOption, "rate unit must be positive", nil)
 )
 
 // TimeDataset represents a time series storing a slice of time points and values.
@@ -95,3 +97,36 @@ func (td *TimeDataset) DropNan() *TimeDataset {
 	tdCopy.Y = tdCopy.Y[:ptr]
 	return tdCopy
 }
+
+// CounterRate converts a monotonically increasing counter to its rate of increase per unit of time.
+// Any decrease is treated as a counter reset so the increase is the value after the reset as if the
+// counter restarted from zero. Each rate is taken from the previous observed value so gaps of NaNs
+// spread the increase over the elapsed time. The first observed value and NaNs have no rate and are
+// NaN. This assumes the data is in time sorted order already. This creates a new TimeDataset.
+func (td *TimeDataset) CounterRate(unit time.Duration) (*TimeDataset, error) {
+	if td == nil {
+		return nil, nil
+	}
+	if unit <= 0 {
+		return nil, fmt.Errorf("unit of %s, %w", unit, ErrInvalidRateUnit)
+	}
+
+	tdCopy := td.Copy()
+	var prevT time.Time
+	prevY := math.NaN()
+	for i, v := range td.Y {
+		if math.IsNaN(v) {
+			continue
+		}
+		tdCopy.Y[i] = math.NaN()
+		if !math.IsNaN(prevY) {
+			increase := v - prevY
+			if v < prevY {
+				increase = v
+			}
+			tdCopy.Y[i] = increase / float64(td.T[i].Sub(prevT)) * float64(unit)
+		}
+		prevT, prevY = td.T[i], v
+	}
+	return tdCopy, nil
+}
@@ -144,3 +144,67 @@ func TestDropNan(t *testing.T) {
 		})
 	}
 }
+
+func TestCounterRate(t *testing.T) {
+	tWin := GenerateT(6, 10*time.Second, func() time.Time {
+		return time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC)
+	})
+	nan := math.NaN()
+
+	testData := map[string]struct {
+		tdset    *TimeDataset
+		unit     time.Duration
+		expected []float64
+		err      error
+	}{
+		"nil input": {tdset: nil, unit: time.Second},
+		"steady increase": {
+			tdset:    &TimeDataset{T: tWin, Y: []float64{0, 10, 20, 30, 40, 50}},
+			unit:     time.Second,
+			expected: []float64{nan, 1, 1, 1, 1, 1},
+		},
+		"per minute": {
+			tdset:    &TimeDataset{T: tWin, Y: []float64{0, 10, 20, 30, 40, 50}},
+			unit:     time.Minute,
+			expected: []float64{nan, 60, 60, 60, 60, 60},
+		},
+		"counter reset": {
+			tdset:    &TimeDataset{T: tWin, Y: []float64{100, 120, 5, 25, 45, 65}},
+			unit:     time.Second,
+			expected: []float64{nan, 2, 0.5, 2, 2, 2},
+		},
+		"missing values": {
+			tdset:    &TimeDataset{T: tWin, Y: []float64{nan, 10, nan, 50, 60, nan}},
+			unit:     time.Second,
+			expected: []float64{nan, nan, nan, 2, 1, nan},
+		},
+		"invalid unit": {
+			tdset: &TimeDataset{T: tWin, Y: []float64{0, 10, 20, 30, 40, 50}},
+			err:   ErrInvalidRateUnit,
+		},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			res, err := td.tdset.CounterRate(td.unit)
+			if td.err != nil {
+				assert.ErrorIs(t, err, td.err)
+				return
+			}
+			require.Nil(t, err)
+			if td.tdset == nil {
+				assert.Nil(t, res)
+				return
+			}
+			assert.Equal(t, td.tdset.T, res.T)
+			require.Len(t, res.Y, len(td.expected))
+			for i, v := range td.expected {
+				if math.IsNaN(v) {
+					assert.True(t, math.IsNaN(res.Y[i]), "index %d", i)
+					continue
+				}
+				assert.InDelta(t, v, res.Y[i], 1e-9, "index %d", i)
+			}
+		})
+	}
+}
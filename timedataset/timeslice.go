@@ -25,6 +25,8 @@ func (t TimeSlice) EndTime() time.Time {
 	return lastTime
 }
 
+// EstimateFreq returns the most common exact interval between consecutive times preferring the
+// smaller interval on ties. Intervals are not grouped so use InferFreq for jittered times.
 func (t TimeSlice) EstimateFreq() (time.Duration, error) {
 	if len(t) < 2 {
 		return 0, ErrCannotInferFreq
@@ -1,6 +1,7 @@
 package timedataset
 
 import (
+	"math/rand"
 	"testing"
 	"time"
 
@@ -99,6 +100,17 @@ func TestEstimateFreq(t *testing.T) {
 			}),
 			expected: time.Hour,
 		},
+		"jittered frequencies": {
+			tSlice: TimeSlice([]time.Time{
+				time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC),
+				time.Date(1970, 1, 1, 0, 1, 1, 0, time.UTC),
+				time.Date(1970, 1, 1, 0, 2, 0, 0, time.UTC),
+				time.Date(1970, 1, 1, 0, 3, 1, 0, time.UTC),
+				time.Date(1970, 1, 1, 0, 4, 0, 0, time.UTC),
+				time.Date(1970, 1, 1, 0, 5, 0, 0, time.UTC),
+			}),
+			expected: 59 * time.Second,
+		},
 	}
 
 	for name, td := range testData {
@@ -113,3 +125,105 @@ func TestEstimateFreq(t *testing.T) {
 		})
 	}
 }
+
+func TestInferFreq(t *testing.T) {
+	ct := time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC)
+	fromOffsets := func(offsets ...time.Duration) TimeSlice {
+		res := make(TimeSlice, 0, len(offsets))
+		for _, o := range offsets {
+			res = append(res, ct.Add(o))
+		}
+		return res
+	}
+	minute := time.Minute
+	sec := time.Second
+
+	tolerance := func(tol float64) *float64 {
+		return &tol
+	}
+
+	// one minute sampling with a gap and a few seconds of jitter
+	jittered := fromOffsets(0, minute+sec, 2*minute-sec, 3*minute, 6*minute+sec, 7*minute, 8*minute)
+
+	testData := map[string]struct {
+		tSlice   TimeSlice
+		opt      FreqOptions
+		expected FreqEstimate
+		err      error
+	}{
+		"too few times": {
+			tSlice: fromOffsets(0),
+			err:    ErrCannotInferFreq,
+		},
+		"no positive interval": {
+			tSlice: fromOffsets(0, 0),
+			err:    ErrCannotInferFreq,
+		},
+		"unknown mode": {
+			tSlice: jittered,
+			opt:    FreqOptions{Mode: "max"},
+			err:    ErrUnknownFreqMode,
+		},
+		"invalid jitter tolerance": {
+			tSlice: jittered,
+			opt:    FreqOptions{JitterTolerance: tolerance(1)},
+			err:    ErrInvalidJitterTolerance,
+		},
+		"regular with gap": {
+			tSlice:   fromOffsets(0, minute, 2*minute, 5*minute, 6*minute),
+			expected: FreqEstimate{Freq: minute, Confidence: 1},
+		},
+		"exact mode with jitter": {
+			tSlice:   jittered,
+			opt:      FreqOptions{JitterTolerance: tolerance(0)},
+			expected: FreqEstimate{Freq: 61 * sec, Confidence: 2.0 / 6.0},
+		},
+		"default jitter tolerance": {
+			tSlice:   jittered,
+			expected: FreqEstimate{Freq: minute, Confidence: 1},
+		},
+		"mode with jitter tolerance": {
+			tSlice:   jittered,
+			opt:      FreqOptions{JitterTolerance: tolerance(0.1)},
+			expected: FreqEstimate{Freq: minute, Confidence: 1},
+		},
+		"min with jitter tolerance": {
+			tSlice:   jittered,
+			opt:      FreqOptions{Mode: FreqModeMin, JitterTolerance: tolerance(0.1)},
+			expected: FreqEstimate{Freq: minute, Confidence: 1},
+		},
+		"median": {
+			tSlice:   fromOffsets(0, minute, 3*minute, 6*minute),
+			opt:      FreqOptions{Mode: FreqModeMedian},
+			expected: FreqEstimate{Freq: 2 * minute, Confidence: 1.0 / 3.0},
+		},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			est, err := td.tSlice.InferFreq(td.opt)
+			if td.err != nil {
+				assert.ErrorIs(t, err, td.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, td.expected.Freq, est.Freq)
+			assert.InDelta(t, td.expected.Confidence, est.Confidence, 1e-9)
+		})
+	}
+}
+
+func TestInferFreqLongJittered(t *testing.T) {
+	// every interval is distinct so grouping each interval must not rescan all of them
+	n := 200000
+	rng := rand.New(rand.NewSource(1))
+	tSlice := make(TimeSlice, n)
+	ct := time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := range tSlice {
+		tSlice[i] = ct.Add(time.Duration(i)*time.Minute + time.Duration(rng.Intn(1000))*time.Millisecond)
+	}
+	est, err := tSlice.InferFreq(FreqOptions{})
+	require.NoError(t, err)
+	assert.InDelta(t, float64(time.Minute), float64(est.Freq), float64(time.Second))
+	assert.Equal(t, 1.0, est.Confidence)
+}
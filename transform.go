@@ -0,0 +1,114 @@
+package forecaster
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/aouyang1/go-forecaster/errs"
+	"github.com/aouyang1/go-forecaster/stats"
+)
+
+var (
+	ErrUnknownTransform       = errs.NewConfigError(errs.CodeInvalidOption, "unknown transform method", nil)
+	ErrTransformDomain        = errs.NewDataError(errs.CodeInvalidValue, "value is outside the domain of the transform", nil)
+	ErrInvalidTransformLambda = errs.NewConfigError(errs.CodeInvalidOption, "transform lambda must be finite", nil)
+)
+
+// TransformMethod is the variance stabilizing transform applied to the series before fitting
+type TransformMethod string
+
+const (
+	// TransformNone fits the series as is
+	TransformNone TransformMethod = ""
+
+	// TransformLog fits log(1+y) which requires non-negative values
+	TransformLog TransformMethod = "log"
+
+	// TransformBoxCox fits the Box-Cox power transform which requires positive values
+	TransformBoxCox TransformMethod = "box_cox"
+
+	// TransformYeoJohnson fits the Yeo-Johnson power transform which supports any value
+	TransformYeoJohnson TransformMethod = "yeo_johnson"
+)
+
+// TransformOptions configures a transform of the series applied at fit and inverted at predict so
+// that multiplicative or skewed series can be modeled additively. The series, uncertainty, and
+// residuals are modeled in the transformed space while the forecast and its bands are returned in
+// the original space which makes the bands asymmetric. MinValue, MaxValue, and the contextual clip
+// bounds are in the original space.
+//
+// Lambda is the power of the Box-Cox and Yeo-Johnson transforms. If AutoLambda is set the fit
+// estimates Lambda by maximum likelihood and persists it with the model.
+type TransformOptions struct {
+	Method     TransformMethod `json:"method"`
+	AutoLambda bool            `json:"auto_lambda,omitempty"`
+	Lambda     float64         `json:"lambda,omitempty"`
+}
+
+// enabled returns true if the series is transformed
+func (o *TransformOptions) enabled() bool {
+	return o != nil && o.Method != TransformNone
+}
+
+// fit validates the values are in the domain of the transform and estimates lambda if configured.
+// NaN values are ignored.
+func (o *TransformOptions) fit(y []float64) error {
+	var inDomain func(v float64) bool
+	var estimate func(y []float64) float64
+	switch o.Method {
+	case TransformLog:
+		inDomain = func(v float64) bool { return v >= 0 }
+	case TransformBoxCox:
+		inDomain = func(v float64) bool { return v > 0 }
+		estimate = stats.BoxCoxLambda
+	case TransformYeoJohnson:
+		inDomain = func(v float64) bool { return !math.IsInf(v, 0) }
+		estimate = stats.YeoJohnsonLambda
+	default:
+		return fmt.Errorf("%q, %w", o.Method, ErrUnknownTransform)
+	}
+	for i, v := range y {
+		if !math.IsNaN(v) && !inDomain(v) {
+			return fmt.Errorf("%s transform of %.3f at index %d, %w", o.Method, v, i, ErrTransformDomain)
+		}
+	}
+	if o.AutoLambda && estimate != nil {
+		o.Lambda = estimate(y)
+	}
+	if math.IsNaN(o.Lambda) || math.IsInf(o.Lambda, 0) {
+		return fmt.Errorf("lambda of %.3f, %w", o.Lambda, ErrInvalidTransformLambda)
+	}
+	return nil
+}
+
+// forward returns a copy of the values in the transformed space
+func (o *TransformOptions) forward(y []float64) []float64 {
+	res := make([]float64, len(y))
+	for i, v := range y {
+		switch o.Method {
+		case TransformLog:
+			res[i] = math.Log1p(v)
+		case TransformBoxCox:
+			res[i] = stats.BoxCox(v, o.Lambda)
+		case TransformYeoJohnson:
+			res[i] = stats.YeoJohnson(v, o.Lambda)
+		default:
+			res[i] = v
+		}
+	}
+	return res
+}
+
+// inverse maps the transformed values back to the original space in place
+func (o *TransformOptions) inverse(y []float64) {
+	for i, v := range y {
+		switch o.Method {
+		case TransformLog:
+			y[i] = math.Expm1(v)
+		case TransformBoxCox:
+			y[i] = stats.InvBoxCox(v, o.Lambda)
+		case TransformYeoJohnson:
+			y[i] = stats.InvYeoJohnson(v, o.Lambda)
+		}
+	}
+}
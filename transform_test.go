@@ -0,0 +1,107 @@
+package forecaster
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/forecast/options"
+	"github.com/aouyang1/go-forecaster/timedataset"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransformOptionsFit(t *testing.T) {
+	testData := map[string]struct {
+		opt    TransformOptions
+		y      []float64
+		err    error
+		lambda float64
+	}{
+		"log":                    {opt: TransformOptions{Method: TransformLog}, y: []float64{0, 1, math.NaN()}},
+		"log negative":           {opt: TransformOptions{Method: TransformLog}, y: []float64{0, -1}, err: ErrTransformDomain},
+		"box-cox fixed lambda":   {opt: TransformOptions{Method: TransformBoxCox, Lambda: 0.5}, y: []float64{1, 2}, lambda: 0.5},
+		"box-cox zero":           {opt: TransformOptions{Method: TransformBoxCox}, y: []float64{0, 1}, err: ErrTransformDomain},
+		"yeo-johnson negative":   {opt: TransformOptions{Method: TransformYeoJohnson, Lambda: 1.5}, y: []float64{-3, 2}, lambda: 1.5},
+		"yeo-johnson infinite":   {opt: TransformOptions{Method: TransformYeoJohnson}, y: []float64{math.Inf(1)}, err: ErrTransformDomain},
+		"unknown method":         {opt: TransformOptions{Method: "sqrt"}, err: ErrUnknownTransform},
+		"infinite lambda":        {opt: TransformOptions{Method: TransformBoxCox, Lambda: math.Inf(1)}, y: []float64{1}, err: ErrInvalidTransformLambda},
+		"auto lambda estimation": {opt: TransformOptions{Method: TransformBoxCox, AutoLambda: true}, y: []float64{1, 2.7, 7.4, 20.1, 54.6}, lambda: 0.0},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			err := td.opt.fit(td.y)
+			if td.err != nil {
+				assert.ErrorIs(t, err, td.err)
+				return
+			}
+			require.Nil(t, err)
+			assert.InDelta(t, td.lambda, td.opt.Lambda, 0.1)
+
+			// the inverse recovers the values
+			res := td.opt.forward(td.y)
+			td.opt.inverse(res)
+			assert.InDeltaSlice(t, td.y, res, 1e-9)
+		})
+	}
+}
+
+func TestFitTransform(t *testing.T) {
+	// multiplicative daily seasonality with noise proportional to the level
+	n := 7 * 24
+	tWin := timedataset.GenerateT(n, time.Hour, func() time.Time {
+		return time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC)
+	})
+	rng := rand.New(rand.NewSource(2))
+	y := make([]float64, n)
+	for i := range y {
+		daily := math.Sin(2 * math.Pi * float64(i) / 24)
+		y[i] = 100 * math.Exp(0.5*daily+0.05*rng.NormFloat64())
+	}
+
+	for _, method := range []TransformMethod{TransformLog, TransformBoxCox, TransformYeoJohnson} {
+		t.Run(string(method), func(t *testing.T) {
+			opt := &Options{
+				SeriesOptions: &SeriesOptions{
+					ForecastOptions: &options.Options{
+						SeasonalityOptions: options.SeasonalityOptions{
+							SeasonalityConfigs: []options.SeasonalityConfig{options.NewDailySeasonalityConfig(4)},
+						},
+					},
+				},
+				UncertaintyOptions: &UncertaintyOptions{
+					ForecastOptions: &options.Options{},
+					ResidualWindow:  24,
+					ResidualZscore:  2.0,
+				},
+				Transform: &TransformOptions{Method: method, AutoLambda: true},
+			}
+			f, err := New(opt)
+			require.Nil(t, err)
+			require.Nil(t, f.Fit(tWin, y))
+			if method == TransformBoxCox {
+				assert.InDelta(t, 0.0, opt.Transform.Lambda, 0.2)
+			}
+
+			// the forecast is in the original space and the bands are wider above the peaks
+			res := f.FitResults()
+			for i := range y {
+				assert.InDelta(t, y[i], res.Forecast[i], 0.2*y[i])
+			}
+			peak, trough := 6, 18
+			assert.Greater(t, res.Upper[peak]-res.Forecast[peak], res.Upper[trough]-res.Forecast[trough])
+			assert.Greater(t, res.Upper[peak]-res.Forecast[peak], res.Forecast[peak]-res.Lower[peak])
+
+			// the estimated lambda is persisted with the model
+			model, err := f.Model()
+			require.Nil(t, err)
+			loaded, err := NewFromModel(model)
+			require.Nil(t, err)
+			loadedRes, err := loaded.Predict(tWin)
+			require.Nil(t, err)
+			assert.InDeltaSlice(t, res.Forecast, loadedRes.Forecast, 1e-9)
+		})
+	}
+}
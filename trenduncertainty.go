@@ -0,0 +1,127 @@
+package forecaster
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/errs"
+	"github.com/aouyang1/go-forecaster/forecast"
+	"gonum.org/v1/gonum/stat"
+)
+
+var ErrNegativeTrendBootstraps = errs.NewConfigError(errs.CodeInvalidOption, "number of trend bootstraps must be non-negative", nil)
+
+// trendUncertaintyEnabled returns true if the long-term trend uncertainty is fit and predicted
+func (u *UncertaintyOptions) trendUncertaintyEnabled() bool {
+	return u.TrendBootstraps > 0
+}
+
+// longTermUncertainty returns the long-term trend uncertainty at the time given the end of training
+// which grows with the number of hours past the end of training
+func (u *UncertaintyOptions) longTermUncertainty(t, trainEnd time.Time) float64 {
+	var hours float64
+	if t.After(trainEnd) {
+		hours = t.Sub(trainEnd).Hours()
+	}
+	slope := u.TrendSlopeStd * hours
+	return u.ResidualZscore * math.Sqrt(u.TrendLevelStd*u.TrendLevelStd+slope*slope)
+}
+
+// blockResample returns the observed values of the input resampled with a moving block bootstrap of the
+// block length so that autocorrelated residuals keep their structure. NaNs are kept in place.
+func blockResample(r *rand.Rand, vals []float64, block int) []float64 {
+	obs := make([]float64, 0, len(vals))
+	for _, v := range vals {
+		if !math.IsNaN(v) {
+			obs = append(obs, v)
+		}
+	}
+	if block < 1 {
+		block = 1
+	}
+	if block > len(obs) {
+		block = len(obs)
+	}
+
+	sampled := make([]float64, 0, len(obs))
+	for len(sampled) < len(obs) && block > 0 {
+		start := r.Intn(len(obs) - block + 1)
+		for _, v := range obs[start : start+block] {
+			if len(sampled) == len(obs) {
+				break
+			}
+			sampled = append(sampled, v)
+		}
+	}
+
+	res := make([]float64, len(vals))
+	var j int
+	for i, v := range vals {
+		if math.IsNaN(v) {
+			res[i] = math.NaN()
+			continue
+		}
+		res[i] = sampled[j]
+		j++
+	}
+	return res
+}
+
+// fitTrendUncertainty refits the series on the fitted values plus block resampled residuals for each
+// bootstrap and sets the standard deviation of the trend level and hourly slope at the end of training
+func (f *Forecaster) fitTrendUncertainty(ctx context.Context, t []time.Time, residual, weights []float64, x forecast.Regressors) error {
+	u := f.opt.UncertaintyOptions
+	u.TrendLevelStd = 0
+	u.TrendSlopeStd = 0
+	if u.TrendBootstraps < 0 {
+		return fmt.Errorf("got %d bootstraps, %w", u.TrendBootstraps, ErrNegativeTrendBootstraps)
+	}
+	if !u.trendUncertaintyEnabled() {
+		return nil
+	}
+
+	freq, err := inferFreq(t, f.opt.FreqOptions)
+	if err != nil || freq <= 0 {
+		return fmt.Errorf("unable to estimate trend slope, %w", ErrCannotInferInterval)
+	}
+	fitted, _, err := f.seriesForecast.PredictWithRegressors(t, x)
+	if err != nil {
+		return fmt.Errorf("unable to predict fitted series, %w", err)
+	}
+	trainEnd := f.seriesForecast.TrainEndTime()
+	tEnd := []time.Time{trainEnd.Add(-freq), trainEnd}
+
+	// the trend does not depend on the regressors which only need to be present at the end of training
+	var xEnd forecast.Regressors
+	if x != nil {
+		xEnd = make(forecast.Regressors, len(x))
+		for name := range x {
+			xEnd[name] = make([]float64, len(tEnd))
+		}
+	}
+
+	r := rand.New(rand.NewSource(u.TrendBootstrapSeed))
+	levels := make([]float64, 0, u.TrendBootstraps)
+	slopes := make([]float64, 0, u.TrendBootstraps)
+	for i := 0; i < u.TrendBootstraps; i++ {
+		bootstrap, err := f.fitBootstrap(ctx, i, t, fitted, blockResample(r, residual, u.ResidualWindow), weights, x)
+		if err != nil {
+			return err
+		}
+
+		_, comp, err := bootstrap.PredictWithRegressors(tEnd, xEnd)
+		if err != nil {
+			return fmt.Errorf("unable to predict trend of bootstrap %d, %w", i, err)
+		}
+		levels = append(levels, comp.Trend[1])
+		slopes = append(slopes, (comp.Trend[1]-comp.Trend[0])/freq.Hours())
+	}
+	if len(levels) > 1 {
+		u.TrendLevelStd = stat.StdDev(levels, nil)
+		u.TrendSlopeStd = stat.StdDev(slopes, nil)
+	}
+	return nil
+}
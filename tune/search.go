@@ -0,0 +1,266 @@
+package tune
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"sort"
+	"time"
+
+	forecaster "github.com/aouyang1/go-forecaster"
+	"github.com/aouyang1/go-forecaster/errs"
+	"github.com/aouyang1/go-forecaster/forecast/options"
+	"gonum.org/v1/gonum/stat"
+)
+
+var (
+	ErrUnknownStrategy    = errs.NewConfigError(errs.CodeInvalidOption, "unknown search strategy", nil)
+	ErrUnknownSeasonality = errs.NewConfigError(errs.CodeNotFound, "seasonality is not configured in the base options", nil)
+	ErrEmptySearchSpace   = errs.NewConfigError(errs.CodeMissingOption, "search space has no values to search", nil)
+)
+
+// Strategy is how configurations are drawn from the search space
+type Strategy string
+
+const (
+	// StrategyGrid evaluates every combination of the search space. This is the default if unset.
+	StrategyGrid Strategy = "grid"
+
+	// StrategyRandom evaluates NumSamples distinct combinations drawn uniformly from the search space
+	StrategyRandom Strategy = "random"
+)
+
+// SearchSpace lists the candidate values of each searched option. Dimensions without values are left
+// as configured in the base options. SeasonalityOrders is keyed by the name of a seasonality config of
+// the base series options. Each regularization value is searched as the only lasso lambda and a zero
+// changepoint count disables auto changepoints.
+type SearchSpace struct {
+	SeasonalityOrders   map[string][]int `json:"seasonality_orders,omitempty"`
+	Regularization      []float64        `json:"regularization,omitempty"`
+	AutoNumChangepoints []int            `json:"auto_num_changepoints,omitempty"`
+	MaskWindows         []string         `json:"mask_windows,omitempty"`
+}
+
+// SearchParams is a single combination of the search space
+type SearchParams struct {
+	SeasonalityOrders   map[string]int `json:"seasonality_orders,omitempty"`
+	Regularization      *float64       `json:"regularization,omitempty"`
+	AutoNumChangepoints *int           `json:"auto_num_changepoints,omitempty"`
+	MaskWindow          *string        `json:"mask_window,omitempty"`
+}
+
+// seasonalityNames returns the searched seasonality names in sorted order so combinations enumerate
+// deterministically
+func (s SearchSpace) seasonalityNames() []string {
+	names := make([]string, 0, len(s.SeasonalityOrders))
+	for name, orders := range s.SeasonalityOrders {
+		if len(orders) > 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// dims returns the number of values of each searched dimension in the order seasonality orders,
+// regularization, changepoints, and mask windows
+func (s SearchSpace) dims() []int {
+	var dims []int
+	for _, name := range s.seasonalityNames() {
+		dims = append(dims, len(s.SeasonalityOrders[name]))
+	}
+	for _, n := range []int{len(s.Regularization), len(s.AutoNumChangepoints), len(s.MaskWindows)} {
+		if n > 0 {
+			dims = append(dims, n)
+		}
+	}
+	return dims
+}
+
+// size returns the number of combinations of the search space
+func (s SearchSpace) size() int {
+	dims := s.dims()
+	if len(dims) == 0 {
+		return 0
+	}
+	size := 1
+	for _, n := range dims {
+		size *= n
+	}
+	return size
+}
+
+// params decodes the combination at the index of the search space
+func (s SearchSpace) params(idx int) SearchParams {
+	var p SearchParams
+	next := func(n int) int {
+		v := idx % n
+		idx /= n
+		return v
+	}
+	for _, name := range s.seasonalityNames() {
+		if p.SeasonalityOrders == nil {
+			p.SeasonalityOrders = make(map[string]int)
+		}
+		orders := s.SeasonalityOrders[name]
+		p.SeasonalityOrders[name] = orders[next(len(orders))]
+	}
+	if n := len(s.Regularization); n > 0 {
+		p.Regularization = &s.Regularization[next(n)]
+	}
+	if n := len(s.AutoNumChangepoints); n > 0 {
+		p.AutoNumChangepoints = &s.AutoNumChangepoints[next(n)]
+	}
+	if n := len(s.MaskWindows); n > 0 {
+		p.MaskWindow = &s.MaskWindows[next(n)]
+	}
+	return p
+}
+
+// apply sets the parameters on the series options
+func (p SearchParams) apply(opt *forecaster.Options) error {
+	fOpt := opt.SeriesOptions.ForecastOptions
+	for name, orders := range p.SeasonalityOrders {
+		idx := slices.IndexFunc(fOpt.SeasonalityOptions.SeasonalityConfigs, func(cfg options.SeasonalityConfig) bool {
+			return cfg.Name == name
+		})
+		if idx == -1 {
+			return fmt.Errorf("%q, %w", name, ErrUnknownSeasonality)
+		}
+		fOpt.SeasonalityOptions.SeasonalityConfigs[idx].Orders = orders
+	}
+	if p.Regularization != nil {
+		fOpt.Regularization = []float64{*p.Regularization}
+	}
+	if p.AutoNumChangepoints != nil {
+		fOpt.ChangepointOptions.Auto = *p.AutoNumChangepoints > 0
+		fOpt.ChangepointOptions.AutoNumChangepoints = *p.AutoNumChangepoints
+	}
+	if p.MaskWindow != nil {
+		fOpt.MaskWindow = *p.MaskWindow
+	}
+	return nil
+}
+
+// SearchConfig configures the search strategy and the backtest scoring each configuration by the mean
+// metric across its folds
+type SearchConfig struct {
+	Strategy   Strategy                  `json:"strategy,omitempty"`
+	NumSamples int                       `json:"num_samples,omitempty"`
+	Seed       int64                     `json:"seed,omitempty"`
+	Metric     Metric                    `json:"metric,omitempty"`
+	Backtest   forecaster.BacktestConfig `json:"backtest"`
+}
+
+// SearchResult is the score of a single configuration. Configurations failing to fit have the error
+// instead of a score and are ranked last.
+type SearchResult struct {
+	Params  SearchParams        `json:"params"`
+	Options *forecaster.Options `json:"options"`
+	Score   float64             `json:"score"`
+	Err     string              `json:"error,omitempty"`
+}
+
+// SearchReport is the best configuration and the leaderboard of every evaluated configuration from
+// best to worst
+type SearchReport struct {
+	Best        *forecaster.Options `json:"best"`
+	BestScore   float64             `json:"best_score"`
+	Leaderboard []SearchResult      `json:"leaderboard"`
+}
+
+// Search evaluates configurations of the search space applied to copies of the base options with a
+// backtest and returns the configuration with the lowest mean metric. The base options default to the
+// forecaster defaults if nil and are not modified.
+func Search(t []time.Time, y []float64, base *forecaster.Options, space SearchSpace, cfg SearchConfig) (*SearchReport, error) {
+	if len(t) != len(y) {
+		return nil, fmt.Errorf("got %d times and %d values, %w", len(t), len(y), ErrMismatchedDataLen)
+	}
+	if _, err := cfg.Metric.Score(&forecaster.Evaluation{}); err != nil {
+		return nil, err
+	}
+	if base == nil {
+		base = forecaster.NewDefaultOptions()
+	}
+
+	size := space.size()
+	if size == 0 {
+		return nil, ErrEmptySearchSpace
+	}
+	var indexes []int
+	switch cfg.Strategy {
+	case "", StrategyGrid:
+		indexes = make([]int, size)
+		for i := range indexes {
+			indexes[i] = i
+		}
+	case StrategyRandom:
+		numSamples := cfg.NumSamples
+		if numSamples <= 0 || numSamples > size {
+			numSamples = size
+		}
+		rng := rand.New(rand.NewSource(cfg.Seed))
+		seen := make(map[int]struct{}, numSamples)
+		for len(indexes) < numSamples {
+			idx := rng.Intn(size)
+			if _, exists := seen[idx]; exists {
+				continue
+			}
+			seen[idx] = struct{}{}
+			indexes = append(indexes, idx)
+		}
+	default:
+		return nil, fmt.Errorf("%q, %w", cfg.Strategy, ErrUnknownStrategy)
+	}
+
+	report := &SearchReport{
+		Leaderboard: make([]SearchResult, 0, len(indexes)),
+	}
+	for _, idx := range indexes {
+		params := space.params(idx)
+		opt := base.Copy()
+		if err := params.apply(opt); err != nil {
+			return nil, err
+		}
+
+		res := SearchResult{Params: params, Options: opt}
+		score, err := backtestScore(t, y, opt, cfg)
+		if err != nil {
+			res.Err = err.Error()
+		} else {
+			res.Score = score
+		}
+		report.Leaderboard = append(report.Leaderboard, res)
+	}
+	sort.SliceStable(report.Leaderboard, func(i, j int) bool {
+		a, b := report.Leaderboard[i], report.Leaderboard[j]
+		if (a.Err == "") != (b.Err == "") {
+			return a.Err == ""
+		}
+		return a.Score < b.Score
+	})
+	best := report.Leaderboard[0]
+	if best.Err != "" {
+		return nil, fmt.Errorf("%s, %w", best.Err, ErrNoValidCandidates)
+	}
+	report.Best = best.Options
+	report.BestScore = best.Score
+	return report, nil
+}
+
+// backtestScore returns the mean metric of the backtest folds of the options
+func backtestScore(t []time.Time, y []float64, opt *forecaster.Options, cfg SearchConfig) (float64, error) {
+	report, err := forecaster.Backtest(t, y, opt, cfg.Backtest)
+	if err != nil {
+		return 0, err
+	}
+	scores := make([]float64, len(report.Folds))
+	for i, fold := range report.Folds {
+		score, err := cfg.Metric.Score(fold.Evaluation)
+		if err != nil {
+			return 0, err
+		}
+		scores[i] = score
+	}
+	return stat.Mean(scores, nil), nil
+}
@@ -0,0 +1,122 @@
+package tune
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	forecaster "github.com/aouyang1/go-forecaster"
+	"github.com/aouyang1/go-forecaster/forecast/options"
+	"github.com/aouyang1/go-forecaster/timedataset"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearch(t *testing.T) {
+	n := 8 * 24 * 4
+	tTrain := timedataset.GenerateT(n, 15*time.Minute, func() time.Time {
+		return time.Date(1970, 1, 9, 0, 0, 0, 0, time.UTC)
+	})
+	y := timedataset.GenerateConstY(n, 5.0).
+		Add(timedataset.GenerateWaveY(tTrain, 3.0, 86400.0, 1.0, 0.0)).
+		Add(timedataset.GenerateWaveY(tTrain, 1.0, 86400.0, 2.0, 0.0))
+
+	space := SearchSpace{
+		SeasonalityOrders: map[string][]int{options.LabelSeasDaily: {0, 1, 2}},
+		Regularization:    []float64{0.0, 1000.0},
+	}
+	backtest := forecaster.BacktestConfig{Folds: 2, Horizon: 96}
+
+	testData := map[string]struct {
+		space    SearchSpace
+		cfg      SearchConfig
+		expected int
+		err      error
+	}{
+		"grid": {
+			space:    space,
+			cfg:      SearchConfig{Metric: MetricMAE, Backtest: backtest},
+			expected: 6,
+		},
+		"random": {
+			space:    space,
+			cfg:      SearchConfig{Strategy: StrategyRandom, NumSamples: 4, Seed: 1, Metric: MetricMAE, Backtest: backtest},
+			expected: 4,
+		},
+		"random more samples than combinations": {
+			space: SearchSpace{
+				SeasonalityOrders: map[string][]int{options.LabelSeasDaily: {0, 2}},
+			},
+			cfg:      SearchConfig{Strategy: StrategyRandom, NumSamples: 10, Backtest: backtest},
+			expected: 2,
+		},
+		"empty space": {
+			cfg: SearchConfig{Backtest: backtest},
+			err: ErrEmptySearchSpace,
+		},
+		"unknown seasonality": {
+			space: SearchSpace{SeasonalityOrders: map[string][]int{"hourly": {1}}},
+			cfg:   SearchConfig{Backtest: backtest},
+			err:   ErrUnknownSeasonality,
+		},
+		"unknown strategy": {
+			space: space,
+			cfg:   SearchConfig{Strategy: "bayesian", Backtest: backtest},
+			err:   ErrUnknownStrategy,
+		},
+		"unknown metric": {
+			space: space,
+			cfg:   SearchConfig{Metric: "unknown", Backtest: backtest},
+			err:   ErrUnknownMetric,
+		},
+		"no valid candidates": {
+			space: space,
+			cfg:   SearchConfig{Backtest: forecaster.BacktestConfig{Folds: n}},
+			err:   ErrNoValidCandidates,
+		},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			base := newCandidate(options.NewDailySeasonalityConfig(4))
+			before, err := json.Marshal(base)
+			require.Nil(t, err)
+
+			report, err := Search(tTrain, y, base, td.space, td.cfg)
+			if td.err != nil {
+				assert.ErrorIs(t, err, td.err)
+				return
+			}
+			require.Nil(t, err)
+
+			// the base options are left as configured
+			after, err := json.Marshal(base)
+			require.Nil(t, err)
+			assert.JSONEq(t, string(before), string(after))
+
+			// every combination is evaluated at most once and ranked from best to worst
+			require.Len(t, report.Leaderboard, td.expected)
+			seen := make(map[string]struct{})
+			for i, res := range report.Leaderboard {
+				assert.Empty(t, res.Err)
+				if i > 0 {
+					assert.LessOrEqual(t, report.Leaderboard[i-1].Score, res.Score)
+				}
+				key, err := json.Marshal(res.Params)
+				require.Nil(t, err)
+				assert.NotContains(t, seen, string(key))
+				seen[string(key)] = struct{}{}
+			}
+			assert.Equal(t, report.Leaderboard[0].Options, report.Best)
+			assert.Equal(t, report.Leaderboard[0].Score, report.BestScore)
+
+			// the daily seasonality needs two orders to fit the signal
+			if td.expected == len(space.SeasonalityOrders[options.LabelSeasDaily])*len(space.Regularization) {
+				best := report.Best.SeriesOptions.ForecastOptions
+				assert.Equal(t, 2, best.SeasonalityOptions.SeasonalityConfigs[0].Orders)
+				assert.Equal(t, []float64{0.0}, best.Regularization)
+				assert.Less(t, report.BestScore, 0.1)
+			}
+		})
+	}
+}
@@ -0,0 +1,182 @@
+// Package tune evaluates, searches, and selects forecaster configurations using time series cross
+// validation and backtests.
+package tune
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	forecaster "github.com/aouyang1/go-forecaster"
+	"github.com/aouyang1/go-forecaster/errs"
+	"github.com/aouyang1/go-forecaster/models"
+	"gonum.org/v1/gonum/stat"
+)
+
+var (
+	ErrNoCandidates      = errs.NewConfigError(errs.CodeMissingOption, "no candidate options to evaluate", nil)
+	ErrUnknownMetric     = errs.NewConfigError(errs.CodeInvalidOption, "unknown scoring metric", nil)
+	ErrNoValidCandidates = errs.NewFitError(errs.CodeFitFailed, "no candidate options could be fit on every fold", nil)
+	ErrMismatchedDataLen = errs.NewDataError(errs.CodeLengthMismatch, "input data has different length than time", nil)
+)
+
+// Metric is the score used to compare configurations where lower is better
+type Metric string
+
+const (
+	MetricMSE  Metric = "mse"
+	MetricMAPE Metric = "mape"
+	MetricMAE  Metric = "mae"
+	MetricRMSE Metric = "rmse"
+)
+
+// Score extracts the metric from an evaluation
+func (m Metric) Score(eval *forecaster.Evaluation) (float64, error) {
+	switch m {
+	case MetricMSE, "":
+		return eval.Scores.MSE, nil
+	case MetricMAPE:
+		return eval.Scores.MAPE, nil
+	case MetricMAE:
+		return eval.MAE, nil
+	case MetricRMSE:
+		return eval.RMSE, nil
+	}
+	return 0, fmt.Errorf("%q, %w", m, ErrUnknownMetric)
+}
+
+// evaluate fits a copy of the options on the training range of the split and scores it against the
+// test range
+func evaluate(t []time.Time, y []float64, opt *forecaster.Options, split models.Split, metric Metric) (*forecaster.Evaluation, float64, error) {
+	f, err := forecaster.New(opt.Copy())
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// fitting replaces outliers in the training values so train on a copy
+	yTrain := make([]float64, split.TrainEnd-split.TrainStart)
+	copy(yTrain, y[split.TrainStart:split.TrainEnd])
+	if err := f.Fit(t[split.TrainStart:split.TrainEnd], yTrain); err != nil {
+		return nil, 0, err
+	}
+
+	res, err := f.Predict(t[split.TestStart:split.TestEnd])
+	if err != nil {
+		return nil, 0, err
+	}
+	eval, err := res.ScoreAgainst(y[split.TestStart:split.TestEnd])
+	if err != nil {
+		return nil, 0, err
+	}
+	score, err := metric.Score(eval)
+	if err != nil {
+		return nil, 0, err
+	}
+	return eval, score, nil
+}
+
+// CrossValidate returns the mean metric of the options across expanding window splits of the data
+func CrossValidate(t []time.Time, y []float64, opt *forecaster.Options, folds int, metric Metric) (float64, error) {
+	splits, err := models.TimeSeriesCVSplit(len(t), folds)
+	if err != nil {
+		return 0, err
+	}
+	scores := make([]float64, 0, len(splits))
+	for _, split := range splits {
+		_, score, err := evaluate(t, y, opt, split, metric)
+		if err != nil {
+			return 0, err
+		}
+		scores = append(scores, score)
+	}
+	return stat.Mean(scores, nil), nil
+}
+
+// NestedCVConfig configures the outer folds used to estimate generalization and the inner folds used
+// to select a configuration within each outer training range.
+type NestedCVConfig struct {
+	OuterFolds int    `json:"outer_folds"`
+	InnerFolds int    `json:"inner_folds"`
+	Metric     Metric `json:"metric"`
+}
+
+// NestedCVFold is the outcome of a single outer fold
+type NestedCVFold struct {
+	Split      models.Split           `json:"split"`
+	Selected   int                    `json:"selected"`
+	InnerScore float64                `json:"inner_score"`
+	Score      float64                `json:"score"`
+	Evaluation *forecaster.Evaluation `json:"evaluation"`
+}
+
+// NestedCVReport summarizes the outer fold scores of the configurations selected by the inner folds
+type NestedCVReport struct {
+	Folds     []NestedCVFold `json:"folds"`
+	MeanScore float64        `json:"mean_score"`
+	StdScore  float64        `json:"std_score"`
+}
+
+// NestedCV estimates the generalization of tuning over the candidate options. For each outer fold the
+// candidates are compared with cross validation restricted to the outer training range, the best one is
+// refit on the full outer training range, and it is scored on the outer test range which was never
+// seen during selection. The outer scores are therefore not optimistically biased by the selection.
+// Candidates failing to fit on an inner fold are skipped.
+func NestedCV(t []time.Time, y []float64, candidates []*forecaster.Options, cfg NestedCVConfig) (*NestedCVReport, error) {
+	if len(candidates) == 0 {
+		return nil, ErrNoCandidates
+	}
+	if len(t) != len(y) {
+		return nil, fmt.Errorf("got %d times and %d values, %w", len(t), len(y), ErrMismatchedDataLen)
+	}
+	if _, err := cfg.Metric.Score(&forecaster.Evaluation{}); err != nil {
+		return nil, err
+	}
+
+	outer, err := models.TimeSeriesCVSplit(len(t), cfg.OuterFolds)
+	if err != nil {
+		return nil, fmt.Errorf("unable to split outer folds, %w", err)
+	}
+
+	report := &NestedCVReport{
+		Folds: make([]NestedCVFold, 0, len(outer)),
+	}
+	scores := make([]float64, 0, len(outer))
+	for i, split := range outer {
+		tTrain := t[split.TrainStart:split.TrainEnd]
+		yTrain := y[split.TrainStart:split.TrainEnd]
+
+		selected := -1
+		bestInner := math.Inf(1)
+		for c, opt := range candidates {
+			score, err := CrossValidate(tTrain, yTrain, opt, cfg.InnerFolds, cfg.Metric)
+			if err != nil {
+				continue
+			}
+			if score < bestInner {
+				bestInner = score
+				selected = c
+			}
+		}
+		if selected < 0 {
+			return nil, fmt.Errorf("outer fold %d, %w", i, ErrNoValidCandidates)
+		}
+
+		eval, score, err := evaluate(t, y, candidates[selected], split, cfg.Metric)
+		if err != nil {
+			return nil, fmt.Errorf("unable to evaluate outer fold %d, %w", i, err)
+		}
+		report.Folds = append(report.Folds, NestedCVFold{
+			Split:      split,
+			Selected:   selected,
+			InnerScore: bestInner,
+			Score:      score,
+			Evaluation: eval,
+		})
+		scores = append(scores, score)
+	}
+	report.MeanScore, report.StdScore = stat.MeanStdDev(scores, nil)
+	if len(scores) == 1 {
+		report.StdScore = 0
+	}
+	return report, nil
+}
@@ -0,0 +1,109 @@
+package tune
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	forecaster "github.com/aouyang1/go-forecaster"
+	"github.com/aouyang1/go-forecaster/forecast/options"
+	"github.com/aouyang1/go-forecaster/models"
+	"github.com/aouyang1/go-forecaster/timedataset"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCandidate(seasCfgs ...options.SeasonalityConfig) *forecaster.Options {
+	opt := forecaster.NewDefaultOptions()
+	opt.SeriesOptions.ForecastOptions.SeasonalityOptions.SeasonalityConfigs = seasCfgs
+	opt.SeriesOptions.ForecastOptions.ChangepointOptions.Auto = false
+	opt.UncertaintyOptions.ForecastOptions.SeasonalityOptions.SeasonalityConfigs = seasCfgs
+	opt.UncertaintyOptions.ForecastOptions.ChangepointOptions.Auto = false
+	return opt
+}
+
+func TestNestedCV(t *testing.T) {
+	n := 8 * 24 * 4
+	tTrain := timedataset.GenerateT(n, 15*time.Minute, func() time.Time {
+		return time.Date(1970, 1, 9, 0, 0, 0, 0, time.UTC)
+	})
+	y := timedataset.GenerateConstY(n, 5.0).
+		Add(timedataset.GenerateWaveY(tTrain, 3.0, 86400.0, 1.0, 0.0))
+
+	candidates := []*forecaster.Options{
+		newCandidate(),
+		newCandidate(options.NewDailySeasonalityConfig(2)),
+	}
+
+	testData := map[string]struct {
+		cfg NestedCVConfig
+		err error
+	}{
+		"valid": {
+			cfg: NestedCVConfig{OuterFolds: 2, InnerFolds: 2, Metric: MetricMAE},
+		},
+		"unknown metric": {
+			cfg: NestedCVConfig{OuterFolds: 2, InnerFolds: 2, Metric: "unknown"},
+			err: ErrUnknownMetric,
+		},
+		"too many outer folds": {
+			cfg: NestedCVConfig{OuterFolds: n, InnerFolds: 2},
+			err: models.ErrInsufficientFoldData,
+		},
+		"too many inner folds": {
+			cfg: NestedCVConfig{OuterFolds: 2, InnerFolds: n},
+			err: ErrNoValidCandidates,
+		},
+	}
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			report, err := NestedCV(tTrain, y, candidates, td.cfg)
+			if td.err != nil {
+				assert.ErrorIs(t, err, td.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, report.Folds, td.cfg.OuterFolds)
+			for _, fold := range report.Folds {
+				assert.Equal(t, 1, fold.Selected)
+				assert.Less(t, fold.Score, 0.1)
+				assert.Equal(t, fold.Split.TestEnd-fold.Split.TestStart, fold.Evaluation.NumPoints)
+			}
+			assert.Less(t, report.MeanScore, 0.1)
+		})
+	}
+}
+
+func TestNestedCVNoCandidates(t *testing.T) {
+	_, err := NestedCV(nil, nil, nil, NestedCVConfig{})
+	assert.ErrorIs(t, err, ErrNoCandidates)
+}
+
+func TestTuneTrainingHooks(t *testing.T) {
+	n := 8 * 24 * 4
+	tTrain := timedataset.GenerateT(n, 15*time.Minute, func() time.Time {
+		return time.Date(1970, 1, 9, 0, 0, 0, 0, time.UTC)
+	})
+	y := timedataset.GenerateConstY(n, 5.0).
+		Add(timedataset.GenerateWaveY(tTrain, 3.0, 86400.0, 1.0, 0.0))
+
+	var passes atomic.Int64
+	candidate := newCandidate(options.NewDailySeasonalityConfig(2))
+	candidate.TrainingHooks = &forecaster.TrainingHooks{
+		OnOutlierPass: func(forecaster.OutlierPassEvent) {
+			passes.Add(1)
+		},
+	}
+
+	// the hooks are kept on the copies of the options fit by every fold
+	_, err := NestedCV(tTrain, y, []*forecaster.Options{candidate}, NestedCVConfig{OuterFolds: 2, InnerFolds: 2})
+	require.NoError(t, err)
+	assert.Greater(t, passes.Load(), int64(0))
+
+	passes.Store(0)
+	space := SearchSpace{SeasonalityOrders: map[string][]int{options.LabelSeasDaily: {1, 2}}}
+	report, err := Search(tTrain, y, candidate, space, SearchConfig{Backtest: forecaster.BacktestConfig{Folds: 2, Horizon: 96}})
+	require.NoError(t, err)
+	assert.Greater(t, passes.Load(), int64(0))
+	assert.NotNil(t, report.Best.TrainingHooks)
+}
@@ -0,0 +1,53 @@
+package forecaster
+
+import (
+	"context"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/errs"
+	"github.com/aouyang1/go-forecaster/timedataset"
+)
+
+var ErrNoUpdateData = errs.NewFitError(errs.CodeNoData, "no training data retained to update, fit with Fit first", nil)
+
+// Update refits the forecaster on the training data of the previous fit appended with the new
+// observations so that long running services can keep a model fresh without retraining from scratch.
+// The lasso fits of the series and uncertainty models are warm started from their current
+// coefficients which converge in a few iterations when the new observations are a small fraction of
+// the history. Features are regenerated over the full history keeping the changepoints of the current
+// fit.
+//
+// The new observations must come after the training data. If UpdateMaxHistory is set only that
+// duration of the most recent history is kept for the refit. Update is only supported after Fit since
+// weighted and regressor fits and models loaded with NewFromModel do not retain their training data.
+func (f *Forecaster) Update(t []time.Time, y []float64) error {
+	if f.updateData == nil {
+		return ErrNoUpdateData
+	}
+	if len(t) != len(y) {
+		return errs.NewFitError(errs.CodeFitFailed, "unable to update", timedataset.ErrDatasetLenMismatch)
+	}
+
+	prev := f.updateData
+	start := 0
+	if f.opt.UpdateMaxHistory > 0 && len(t) > 0 {
+		cutoff := t[len(t)-1].Add(-f.opt.UpdateMaxHistory)
+		for start < prev.Len() && !prev.T[start].After(cutoff) {
+			start++
+		}
+	}
+	updateT := make([]time.Time, 0, prev.Len()-start+len(t))
+	updateT = append(updateT, prev.T[start:]...)
+	updateT = append(updateT, t...)
+	updateY := make([]float64, 0, len(updateT))
+	updateY = append(updateY, prev.Y[start:]...)
+	updateY = append(updateY, y...)
+
+	f.seriesForecast.SetWarmStart(true)
+	f.uncertaintyForecast.SetWarmStart(true)
+	defer func() {
+		f.seriesForecast.SetWarmStart(false)
+		f.uncertaintyForecast.SetWarmStart(false)
+	}()
+	return f.fit(context.Background(), updateT, updateY, nil, nil)
+}
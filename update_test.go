@@ -0,0 +1,108 @@
+package forecaster
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/aouyang1/go-forecaster/forecast"
+	"github.com/aouyang1/go-forecaster/forecast/options"
+	"github.com/aouyang1/go-forecaster/timedataset"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdate(t *testing.T) {
+	n := 8 * 24 * 6
+	tWin := timedataset.GenerateT(n, 10*time.Minute, func() time.Time {
+		return time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC)
+	})
+	rng := rand.New(rand.NewSource(7))
+	y := make([]float64, n)
+	for i := range y {
+		y[i] = 20 + 5*math.Sin(2*math.Pi*float64(i)/144) + 0.5*rng.NormFloat64()
+	}
+	split := n - 24*6
+
+	newOpt := func(maxHistory time.Duration) *Options {
+		return &Options{
+			SeriesOptions: &SeriesOptions{
+				ForecastOptions: &options.Options{
+					SeasonalityOptions: options.SeasonalityOptions{
+						SeasonalityConfigs: []options.SeasonalityConfig{options.NewDailySeasonalityConfig(2)},
+					},
+				},
+			},
+			UncertaintyOptions: &UncertaintyOptions{
+				ForecastOptions: &options.Options{},
+				ResidualWindow:  12,
+				ResidualZscore:  2.0,
+			},
+			UpdateMaxHistory: maxHistory,
+		}
+	}
+
+	testData := map[string]struct {
+		maxHistory time.Duration
+		start      time.Time
+	}{
+		"full history":    {start: tWin[0]},
+		"bounded history": {maxHistory: 4 * 24 * time.Hour, start: tWin[n-1].Add(-4*24*time.Hour + 10*time.Minute)},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			f, err := New(newOpt(td.maxHistory))
+			require.Nil(t, err)
+			require.Nil(t, f.Fit(tWin[:split], y[:split]))
+			require.Nil(t, f.Update(tWin[split:], y[split:]))
+
+			trainingData := f.TrainingData()
+			assert.Equal(t, td.start, trainingData.T[0])
+			assert.Equal(t, tWin[n-1], trainingData.T[trainingData.Len()-1])
+
+			// the warm started update matches a fit from scratch on the same history
+			expected, err := New(newOpt(0))
+			require.Nil(t, err)
+			require.Nil(t, expected.Fit(trainingData.T, trainingData.Y))
+
+			horizon := timedataset.GenerateT(24*6, 10*time.Minute, func() time.Time {
+				return tWin[n-1].Add(10 * time.Minute)
+			})
+			res, err := f.Predict(horizon)
+			require.Nil(t, err)
+			expectedRes, err := expected.Predict(horizon)
+			require.Nil(t, err)
+			assert.InDeltaSlice(t, expectedRes.Forecast, res.Forecast, 1e-2)
+			assert.InDeltaSlice(t, expectedRes.Upper, res.Upper, 1e-2)
+		})
+	}
+}
+
+func TestUpdateErrors(t *testing.T) {
+	tWin := timedataset.GenerateT(48, time.Hour, func() time.Time {
+		return time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC)
+	})
+	y := make([]float64, len(tWin))
+	for i := range y {
+		y[i] = float64(i % 5)
+	}
+
+	f, err := New(nil)
+	require.Nil(t, err)
+	assert.ErrorIs(t, f.Update(tWin, y), ErrNoUpdateData)
+
+	require.Nil(t, f.Fit(tWin, y))
+	assert.ErrorIs(t, f.Update(tWin[:1], y[:1]), timedataset.ErrNonMontonic)
+	assert.ErrorIs(t, f.Update(tWin[:1], y), timedataset.ErrDatasetLenMismatch)
+
+	model, err := f.Model()
+	require.Nil(t, err)
+	loaded, err := NewFromModel(model)
+	require.Nil(t, err)
+	assert.ErrorIs(t, loaded.Update(tWin, y), ErrNoUpdateData)
+
+	require.Nil(t, f.FitWithRegressors(tWin, y, forecast.Regressors{"x": y}))
+	assert.ErrorIs(t, f.Update(tWin, y), ErrNoUpdateData)
+}